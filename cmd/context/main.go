@@ -16,6 +16,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"tradegravity/internal/buildinfo"
+	"tradegravity/internal/cli"
 )
 
 const defaultWorldBankURL = "https://api.worldbank.org/v2"
@@ -76,7 +79,12 @@ func main() {
 	outPath := flag.String("out", "site/data/context.json", "output JSON path")
 	baseURL := flag.String("base-url", defaultWorldBankURL, "World Bank API base URL")
 	timeout := flag.Duration("timeout", 2*time.Minute, "overall HTTP request timeout")
+	showVersion := flag.Bool("version", false, "print the build version and exit")
 	flag.Parse()
+	if *showVersion {
+		buildinfo.Print(os.Stdout, "context")
+		return
+	}
 
 	if err := run(*countriesPath, *outPath, *baseURL, *timeout); err != nil {
 		fmt.Fprintln(os.Stderr, "context build failed:", err)
@@ -92,13 +100,19 @@ func run(countriesPath, outPath, baseURL string, timeout time.Duration) error {
 	if timeout <= 0 {
 		return errors.New("timeout must be positive")
 	}
+	generatedAt := time.Now().UTC()
+	if pinned, ok, err := cli.SourceDate(); err != nil {
+		return err
+	} else if ok {
+		generatedAt = pinned
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	client := &http.Client{Timeout: timeout}
 
 	output := contextFile{
 		SchemaVersion: "1.0",
-		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		GeneratedAt:   generatedAt.Format(time.RFC3339),
 		Source:        "World Bank Open Data",
 		Status:        "success",
 		Countries:     make([]countryContext, 0, len(configs)),
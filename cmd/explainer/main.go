@@ -16,6 +16,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"tradegravity/internal/buildinfo"
+	"tradegravity/internal/secrets"
 )
 
 const schemaVersion = "2.0"
@@ -47,6 +50,7 @@ type latestEntry struct {
 	ShareCN          float64      `json:"share_cn"`
 	SamePeriod       bool         `json:"same_period"`
 	ComparisonPeriod string       `json:"comparison_period"`
+	Incomparable     bool         `json:"incomparable"`
 }
 
 type latestFile struct {
@@ -162,7 +166,12 @@ func main() {
 	model := flag.String("model", envOr("OPENAI_MODEL", "gpt-5.6-luna"), "OpenAI model")
 	maxAI := flag.Int("max-ai-reporters", 10, "maximum reporters sent to the API; remaining reporters use deterministic output")
 	timeout := flag.Duration("timeout", 45*time.Second, "timeout per API request")
+	showVersion := flag.Bool("version", false, "print the build version and exit")
 	flag.Parse()
+	if *showVersion {
+		buildinfo.Print(os.Stdout, "explainer")
+		return
+	}
 
 	if *outDir == "" {
 		*outDir = filepath.Join(*dataDir, "explanations")
@@ -181,7 +190,10 @@ func main() {
 		seriesByISO[item.ISO3] = item
 	}
 
-	apiKey := strings.TrimSpace(os.Getenv("OPENAI_API_KEY"))
+	apiKey, err := secrets.Lookup("OPENAI_API_KEY")
+	if err != nil {
+		fatalf("read OPENAI_API_KEY: %v", err)
+	}
 	client := &http.Client{Timeout: *timeout}
 	index := explanationIndex{SchemaVersion: schemaVersion, GeneratedAt: latest.GeneratedAt, Model: "none", Reporters: []string{}}
 	if *useAI && apiKey != "" {
@@ -224,7 +236,9 @@ func buildEvidence(row latestEntry, provider string, series reporterSeries, prod
 	items := []evidence{
 		{ID: "TOTAL-USA", Label: "Headline trade with USA", Value: row.USA.Trade, DisplayValue: formatUSD(row.USA.Trade), Unit: "current USD", Period: row.USA.Period, Source: strings.ToUpper(provider), SourceJSON: "../latest.json"},
 		{ID: "TOTAL-CHN", Label: "Headline trade with China", Value: row.CHN.Trade, DisplayValue: formatUSD(row.CHN.Trade), Unit: "current USD", Period: row.CHN.Period, Source: strings.ToUpper(provider), SourceJSON: "../latest.json"},
-		{ID: "SHARE-CHN", Label: "China share of USA-plus-China trade", Value: row.ShareCN, DisplayValue: fmt.Sprintf("%.1f%%", row.ShareCN*100), Unit: "share", Period: row.ComparisonPeriod, Source: "TradeGravity calculation", SourceJSON: "../latest.json"},
+	}
+	if !row.Incomparable {
+		items = append(items, evidence{ID: "SHARE-CHN", Label: "China share of USA-plus-China trade", Value: row.ShareCN, DisplayValue: fmt.Sprintf("%.1f%%", row.ShareCN*100), Unit: "share", Period: row.ComparisonPeriod, Source: "TradeGravity calculation", SourceJSON: "../latest.json"})
 	}
 	qualityLabel := "Partner periods differ or one is missing"
 	qualityValue := "not comparable"
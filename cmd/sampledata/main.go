@@ -1,5 +1,7 @@
 // Command sampledata creates a deterministic, offline SQLite fixture and
-// country-context file used to regenerate examples/sample-data.
+// country-context file used to regenerate examples/sample-data. With
+// -synthetic it instead fills the database with that many synthetic
+// observations for measuring the publisher at scale.
 package main
 
 import (
@@ -11,8 +13,10 @@ import (
 	"path/filepath"
 	"time"
 
+	"tradegravity/internal/buildinfo"
 	"tradegravity/internal/model"
 	"tradegravity/internal/store/sqlite"
+	"tradegravity/internal/synthetic"
 )
 
 type contextMetric struct {
@@ -43,7 +47,15 @@ type contextFile struct {
 func main() {
 	dbPath := flag.String("db", "sample-fixture.db", "new SQLite fixture path (must not already exist)")
 	contextPath := flag.String("context", "examples/sample-data/context.json", "context JSON output path")
+	syntheticCount := flag.Int("synthetic", 0, "fill -db with this many synthetic total-trade observations instead of the sample fixture (0 = sample fixture)")
+	syntheticPartners := flag.Int("synthetic-partners", 3, "partners of every -synthetic reporter: USA, CHN, WLD, then other countries")
+	seed := flag.Uint64("seed", 1, "seed of the -synthetic values")
+	showVersion := flag.Bool("version", false, "print the build version and exit")
 	flag.Parse()
+	if *showVersion {
+		buildinfo.Print(os.Stdout, "sampledata")
+		return
+	}
 	if _, err := os.Stat(*dbPath); err == nil {
 		fmt.Fprintf(os.Stderr, "refusing to overwrite existing fixture %s\n", *dbPath)
 		os.Exit(1)
@@ -59,6 +71,16 @@ func main() {
 	}
 	defer store.Close()
 	ctx := context.Background()
+	if *syntheticCount > 0 {
+		started := time.Now()
+		stored, err := synthetic.Fill(ctx, store, synthetic.Config{Observations: *syntheticCount, Partners: *syntheticPartners, Seed: *seed})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("synthetic fixture created (db=%s observations=%d elapsed=%s)\n", *dbPath, stored, time.Since(started).Round(time.Millisecond))
+		return
+	}
 	observations := totalObservations()
 	observations = append(observations, productObservations()...)
 	strategic := strategicObservations()
@@ -71,6 +93,29 @@ func main() {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	if err := store.UpsertFXRates(ctx, []model.FXRate{
+		{Currency: "KRW", PeriodType: model.PeriodYear, Period: "2023", RatePerUSD: 1305.41, Source: "sample"},
+		{Currency: "EUR", PeriodType: model.PeriodYear, Period: "2023", RatePerUSD: 0.9248, Source: "sample"},
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := store.UpsertReporters(ctx, []model.Reporter{
+		{ISO3: "DEU", NameEN: "Germany", NameKO: "독일", Region: "Europe & Central Asia", IsActive: true},
+		{ISO3: "JPN", NameEN: "Japan", NameKO: "일본", Region: "East Asia & Pacific", IsActive: true},
+		{ISO3: "KOR", NameEN: "Korea, Rep.", NameKO: "대한민국", Region: "East Asia & Pacific", IsActive: true},
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if _, err := store.RefreshProviderDiscrepancies(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if _, err := store.RefreshSeriesQuality(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	tariffs := tariffObservations()
 	if err := store.UpsertTariffObservations(ctx, tariffs); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -161,16 +206,25 @@ func totalObservations() []model.Observation {
 			for _, partner := range []struct {
 				iso3 string
 				base float64
-			}{{"USA", reporter.usa}, {"CHN", reporter.chn}} {
+			}{{"USA", reporter.usa}, {"CHN", reporter.chn}, {"WLD", (reporter.usa + reporter.chn) * 3.2}} {
 				for _, flow := range []struct {
-					name  model.Flow
-					share float64
-				}{{model.FlowExport, 0.58}, {model.FlowImport, 0.42}} {
-					observations = append(observations, model.Observation{
+					name      model.Flow
+					share     float64
+					indicator string
+				}{{model.FlowExport, 0.58, "XPRT-TRD-VL"}, {model.FlowImport, 0.42, "MPRT-TRD-VL"}} {
+					// Germany's latest trade with the USA stands in for a
+					// provisional release the provider flags as estimated.
+					estimated := reporter.iso3 == "DEU" && partner.iso3 == "USA" && year == 2023
+					observation := model.Observation{
 						Provider: "wits", ReporterISO3: reporter.iso3, PartnerISO3: partner.iso3,
 						Flow: flow.name, PeriodType: model.PeriodYear, Period: fmt.Sprintf("%d", year),
-						ValueUSD: partner.base * factor * flow.share,
-					})
+						ValueUSD: partner.base * factor * flow.share, Estimated: estimated,
+						DatasetID: "tradestats-trade", Indicator: flow.indicator,
+					}
+					if estimated {
+						observation.QualityNote = model.QualityProviderEstimate
+					}
+					observations = append(observations, observation)
 				}
 			}
 		}
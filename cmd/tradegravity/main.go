@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"os/exec"
+	"sort"
+	"time"
+
+	"tradegravity/internal/buildinfo"
+	"tradegravity/internal/cli"
+	"tradegravity/internal/collector"
+	"tradegravity/internal/config"
+	"tradegravity/internal/grpcapi"
+	"tradegravity/internal/logfile"
+	"tradegravity/internal/publisher"
+	"tradegravity/internal/sdnotify"
+	"tradegravity/internal/secrets"
+	"tradegravity/internal/server"
+	"tradegravity/internal/store/sqlite"
+)
+
+const program = "tradegravity"
+
+func main() {
+	fs := flag.NewFlagSet(program, flag.ExitOnError)
+	logFile := fs.String("log-file", "", "write the command's output to this file with timestamps, rotating it by size and age (optional)")
+	errorLog := fs.String("error-log", "", "with -log-file, also write stderr alone to this file (optional)")
+	logMaxSize := fs.Int("log-max-size-mb", 100, "rotate a log once it would grow past this many MiB (0 = no size limit)")
+	logMaxAge := fs.Duration("log-max-age", 24*time.Hour, "rotate a log once it has been open this long (0 = no age limit)")
+	logKeep := fs.Int("log-keep", 7, "rotated files to keep per log (0 = all)")
+	showVersion := fs.Bool("version", false, "print the build version and exit")
+	config.Parse(fs, os.Args[1:])
+	if *showVersion {
+		buildinfo.Print(os.Stdout, program)
+		return
+	}
+	if path := fs.Lookup("config").Value.String(); path != "" {
+		// Commands read the same config file as the global flags.
+		os.Setenv(config.EnvPrefix+"CONFIG", path)
+	}
+
+	if *logFile == "" || fs.NArg() == 0 {
+		cli.Dispatch(program, commands(), fs.Args())
+		return
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		cli.Fatal("log-file failed", err)
+	}
+	cmd := exec.Command(executable, fs.Args()...)
+	// The child runs the command itself rather than logging again, and it
+	// is the process that pings the systemd watchdog.
+	cmd.Env = append(os.Environ(), config.EnvPrefix+"LOG_FILE=", "WATCHDOG_PID=")
+	code, err := logfile.Run(cmd, logfile.Options{
+		Path:      *logFile,
+		ErrorPath: *errorLog,
+		MaxSize:   int64(*logMaxSize) << 20,
+		MaxAge:    *logMaxAge,
+		Keep:      *logKeep,
+	})
+	if err != nil {
+		cli.Fatal("log-file failed", err)
+	}
+	os.Exit(code)
+}
+
+func commands() []cli.Command {
+	return []cli.Command{
+		{Name: "collect", Summary: "fetch provider data into the store", Run: func(args []string) {
+			collector.Main(program+" collect", args)
+		}},
+		{Name: "publish", Summary: "build static JSON artifacts from the store", Run: func(args []string) {
+			publisher.Main(program+" publish", args)
+		}},
+		{Name: "analytics", Summary: "run analytics modules over the store into the published data", Run: func(args []string) {
+			publisher.Analytics(program+" analytics", args)
+		}},
+		{Name: "all", Summary: "collect totals, then publish on success", Run: runAll},
+		{Name: "db", Summary: "migrate or inspect the sqlite store", Run: runDB},
+		{Name: "serve", Summary: "serve published artifacts over HTTP", Run: runServe},
+		{Name: "config", Summary: "print the resolved configuration of a command", Run: runConfig},
+	}
+}
+
+func runConfig(args []string) {
+	configCommands := []cli.Command{
+		{Name: "print", Summary: "print each flag of the command that follows with its value and source", Run: runConfigPrint},
+	}
+	cli.Dispatch(program+" config", configCommands, args)
+}
+
+// runConfigPrint resolves the flags of a command, such as "collect run
+// -partners USA", and prints them instead of running it.
+func runConfigPrint(args []string) {
+	config.PrintOnly()
+	cli.Dispatch(program, commands(), args)
+}
+
+func runDB(args []string) {
+	dbCommands := []cli.Command{
+		{Name: "migrate", Summary: "create or upgrade the store schema", Run: runDBMigrate},
+		{Name: "stats", Summary: "print row counts per table", Run: runDBStats},
+	}
+	cli.Dispatch(program+" db", dbCommands, args)
+}
+
+func runDBMigrate(args []string) {
+	fs := flag.NewFlagSet("db migrate", flag.ExitOnError)
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	config.Parse(fs, args)
+
+	db, err := sqlite.New(*dbPath)
+	if err != nil {
+		cli.Fatal("db migrate failed", err)
+	}
+	if err := db.Close(); err != nil {
+		cli.Fatal("db migrate failed", err)
+	}
+	fmt.Printf("migrated %s\n", *dbPath)
+}
+
+func runDBStats(args []string) {
+	fs := flag.NewFlagSet("db stats", flag.ExitOnError)
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	config.Parse(fs, args)
+
+	db, err := sqlite.New(*dbPath)
+	if err != nil {
+		cli.Fatal("db stats failed", err)
+	}
+	defer db.Close()
+
+	counts, err := db.TableCounts(context.Background())
+	if err != nil {
+		cli.Fatal("db stats failed", err)
+	}
+	tables := make([]string, 0, len(counts))
+	for table := range counts {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		fmt.Printf("%s=%d\n", table, counts[table])
+	}
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "listen address")
+	dataDir := fs.String("data", "site/data", "published data directory")
+	corsOrigins := fs.String("cors-origins", "", "comma-separated origins allowed to read artifacts cross-origin, or * for any (optional)")
+	corsMethods := fs.String("cors-methods", server.DefaultCORSMethods, "comma-separated methods allowed cross-origin")
+	rateLimitValue := fs.String("rate-limit", "", "requests allowed per client IP, or per bearer token listed in RATE_LIMIT_TOKENS, e.g. 120/m (optional)")
+	trustedProxies := fs.String("trusted-proxies", "", "comma-separated CIDRs of proxies whose X-Forwarded-For names the client IP for -rate-limit, e.g. 10.0.0.0/8 (optional)")
+	dbPath := fs.String("db", "", "sqlite database for /v1/series, /v1/aggregate, the gRPC API, /readyz, and /events ingest notices (optional)")
+	grpcAddr := fs.String("grpc-addr", "", "also serve the gRPC API over cleartext HTTP/2 on this address, e.g. 127.0.0.1:9090; requires -db (optional)")
+	siteDir := fs.String("site", "", "also serve this static site on / with the data under /data/, or \"embedded\" for a minimal built-in viewer (optional)")
+	maxDataAge := fs.Duration("max-data-age", 0, "report unready once meta.json is older than this, e.g. 48h (optional)")
+	metricsEnabled := fs.Bool("metrics", false, "serve Prometheus request and store query metrics on /metrics")
+	statsdAddr := fs.String("statsd", "", "also send request and store query metrics to a StatsD or Datadog agent at host:port, e.g. 127.0.0.1:8125 (optional)")
+	statsdTags := fs.String("statsd-tags", "", "comma-separated key:value tags added to every StatsD metric, e.g. env:prod (optional)")
+	config.Parse(fs, args)
+	if *grpcAddr != "" && *dbPath == "" {
+		cli.Fatal("invalid grpc-addr", errors.New("-grpc-addr requires -db"))
+	}
+
+	cors, err := server.ParseCORS(*corsOrigins, *corsMethods)
+	if err != nil {
+		cli.Fatal("invalid cors", err)
+	}
+	rateLimit, err := server.ParseRateLimit(*rateLimitValue)
+	if err != nil {
+		cli.Fatal("invalid rate-limit", err)
+	}
+	rateLimitTokens, err := secrets.Lookup("RATE_LIMIT_TOKENS")
+	if err != nil {
+		cli.Fatal("invalid rate-limit tokens", err)
+	}
+	proxies, err := server.ParseTrustedProxies(*trustedProxies)
+	if err != nil {
+		cli.Fatal("invalid trusted-proxies", err)
+	}
+	metrics := server.NewMetrics()
+	var backend server.MetricsBackend = metrics
+	var statsd *server.StatsD
+	if *statsdAddr != "" {
+		tags, err := server.ParseStatsDTags(*statsdTags)
+		if err != nil {
+			cli.Fatal("invalid statsd-tags", err)
+		}
+		statsd, err = server.NewStatsD(*statsdAddr, tags)
+		if err != nil {
+			cli.Fatal("invalid statsd", err)
+		}
+		defer statsd.Close()
+		backend = server.MultiMetrics{metrics, statsd}
+	}
+	probes := server.Probes{DataDir: *dataDir, MaxAge: *maxDataAge}
+	events := server.Events{DataDir: *dataDir}
+	if *dbPath != "" {
+		// sqlite.New creates missing databases, which a probe must not do.
+		if _, err := os.Stat(*dbPath); err != nil {
+			cli.Fatal("serve failed", err)
+		}
+		db, err := sqlite.New(*dbPath)
+		if err != nil {
+			cli.Fatal("serve failed", err)
+		}
+		defer db.Close()
+		probes.Store = func(ctx context.Context) error {
+			defer observeSince(backend, "ping", time.Now())
+			return db.Ping(ctx)
+		}
+		events.LatestIngest = func(ctx context.Context) (string, error) {
+			defer observeSince(backend, "latest_ingested_at", time.Now())
+			return db.LatestIngestedAt(ctx)
+		}
+		if *grpcAddr != "" {
+			serveGRPC(*grpcAddr, grpcapi.Service{Reporters: db, DBPath: *dbPath, ObserveQuery: backend.ObserveQuery}, rateLimit, server.ParseRateLimitTokens(rateLimitTokens), proxies)
+		}
+	}
+	handler, err := server.New(*dataDir)
+	if err != nil {
+		cli.Fatal("serve failed", err)
+	}
+	if *siteDir != "" {
+		handler, err = server.WithSite(handler, *siteDir)
+		if err != nil {
+			cli.Fatal("serve failed", err)
+		}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	api := publisher.APIHandler(publisher.API{DataDir: *dataDir, DBPath: *dbPath, ObserveQuery: backend.ObserveQuery, LatestIngest: events.LatestIngest})
+	for _, version := range publisher.APIVersions {
+		mux.Handle("/"+version+"/", api)
+	}
+	handler = mux
+	// CORS wraps the limiter so browsers can read 429 responses, and probes
+	// are answered before either.
+	handler = server.WithProbes(server.WithCORS(server.WithRateLimit(server.WithEvents(handler, events), rateLimit, server.ParseRateLimitTokens(rateLimitTokens), proxies), cors), probes)
+	if statsd != nil {
+		handler = server.Instrument(handler, statsd)
+	}
+	if *metricsEnabled {
+		handler = metrics.Wrap(handler)
+	}
+	srv := &http.Server{Addr: *addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		cli.Fatal("serve failed", err)
+	}
+	fmt.Fprintf(os.Stderr, "serving %s on http://%s\n", *dataDir, *addr)
+	// Under systemd with Type=notify, the service is ready once the port
+	// accepts connections, and the watchdog is pinged while /healthz answers.
+	if _, err := sdnotify.Notify(sdnotify.Ready); err != nil {
+		fmt.Fprintln(os.Stderr, "sd_notify failed:", err)
+	}
+	go sdnotify.RunWatchdog(context.Background(), func(ctx context.Context) error {
+		return checkHealth(ctx, "http://"+listener.Addr().String()+server.HealthPath)
+	})
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		cli.Fatal("serve failed", err)
+	}
+}
+
+// checkHealth fetches url, the server's own /healthz, through its listener
+// so a server that stopped accepting or answering fails the check.
+func checkHealth(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// serveGRPC serves the gRPC API on its own listener, which speaks only
+// cleartext HTTP/2 as gRPC clients expect, under the same rate limit as the
+// HTTP API.
+func serveGRPC(addr string, service grpcapi.Service, limit server.RateLimit, tokens []string, proxies []netip.Prefix) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		cli.Fatal("serve failed", err)
+	}
+	srv := &http.Server{Handler: server.WithRateLimit(grpcapi.NewServer(service), limit, tokens, proxies), ReadHeaderTimeout: 10 * time.Second}
+	srv.Protocols = new(http.Protocols)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	fmt.Fprintf(os.Stderr, "serving gRPC on %s\n", addr)
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cli.Fatal("grpc serve failed", err)
+		}
+	}()
+}
+
+// observeSince records the time since start as the named store query.
+func observeSince(metrics server.MetricsBackend, query string, start time.Time) {
+	metrics.ObserveQuery(query, time.Since(start))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"tradegravity/internal/cli"
+	"tradegravity/internal/collector"
+	"tradegravity/internal/config"
+	"tradegravity/internal/publisher"
+)
+
+// runAll collects totals and then publishes the site data in one process.
+func runAll(args []string) {
+	fs := flag.NewFlagSet("all", flag.ExitOnError)
+	provider := fs.String("provider", "wits", "provider id")
+	partners := fs.String("partners", "USA,CHN", "comma-separated partner ISO3 list")
+	flows := fs.String("flows", "export,import", "comma-separated flows")
+	limit := fs.Int("limit", 0, "limit number of reporters (0 = all)")
+	allowlist := fs.String("allowlist", "configs/allowlist.csv", "path to allowlist file (empty = no filter)")
+	countries := fs.String("countries", "configs/countries.csv", "country metadata CSV with English and Korean reporter names (optional)")
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without collecting (0 = exit at once)")
+	historyYears := fs.Int("history-years", 1, "number of previous years to fetch for growth (0 = latest only)")
+	concurrency := fs.Int("concurrency", 6, "maximum reporters collected concurrently")
+	outDir := fs.String("out", "site/data", "publish output directory")
+	seriesYears := fs.Int("series-years", 10, "maximum number of annual periods per reporter")
+	world := fs.Bool("world", true, "also collect the WLD (world) partner used as the share-of-total denominator")
+	skipUnchanged := fs.Bool("skip-unchanged", false, "skip publish when collection stored no new observations")
+	verbose := fs.Bool("verbose", false, "print each observation")
+	config.Parse(fs, args)
+	// The lock covers both steps; the publish step shares it.
+	defer cli.LockStore(*dbPath, *lockWait, "pipeline")()
+
+	collectPartners := *partners
+	if *world {
+		collectPartners = collector.WithWorld(collectPartners)
+	}
+	started := time.Now()
+	run, err := collector.CollectTotals(*provider, collectPartners, *flows, *limit, *allowlist, *countries, *dbPath, *historyYears, *concurrency, *verbose)
+	if err != nil {
+		cli.Fatal("pipeline collect failed", err)
+	}
+
+	publish := publishDecision(run.StoredCount, *skipUnchanged)
+	if publish == "published" {
+		publisher.Build(pipelinePublishArgs(*outDir, *dbPath, *provider, *partners, *seriesYears))
+	}
+	fmt.Printf("pipeline complete (run_id=%s provider=%s status=%s stored=%d failed=%d publish=%s elapsed=%s)\n",
+		run.RunID, run.Provider, run.Status, run.StoredCount, run.FailureCount, publish, time.Since(started).Round(time.Millisecond),
+	)
+}
+
+// publishDecision reports whether the publish step runs after a collection
+// that stored the given number of observations.
+func publishDecision(stored int, skipUnchanged bool) string {
+	if skipUnchanged && stored == 0 {
+		return "skipped"
+	}
+	return "published"
+}
+
+// pipelinePublishArgs forwards the flags shared with collection to the
+// publisher so both steps read the same store, provider, and partners.
+func pipelinePublishArgs(outDir, dbPath, provider, partners string, seriesYears int) []string {
+	return []string{
+		"-out", outDir,
+		"-db", dbPath,
+		"-provider", provider,
+		"-partners", partners,
+		"-series-years", strconv.Itoa(seriesYears),
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPublishDecisionSkipsOnlyUnchangedRunsWhenRequested(t *testing.T) {
+	cases := []struct {
+		stored        int
+		skipUnchanged bool
+		want          string
+	}{
+		{stored: 0, skipUnchanged: false, want: "published"},
+		{stored: 0, skipUnchanged: true, want: "skipped"},
+		{stored: 3, skipUnchanged: true, want: "published"},
+	}
+	for _, tc := range cases {
+		if got := publishDecision(tc.stored, tc.skipUnchanged); got != tc.want {
+			t.Fatalf("publishDecision(%d, %v) = %q, want %q", tc.stored, tc.skipUnchanged, got, tc.want)
+		}
+	}
+}
+
+func TestPipelinePublishArgsShareCollectionFlags(t *testing.T) {
+	got := pipelinePublishArgs("out", "data.db", "comtrade", "USA,CHN", 5)
+	want := []string{"-out", "out", "-db", "data.db", "-provider", "comtrade", "-partners", "USA,CHN", "-series-years", "5"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("args = %#v", got)
+	}
+}
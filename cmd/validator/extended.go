@@ -1,9 +1,12 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
+	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -41,13 +44,92 @@ type validationSeriesPoint struct {
 	Total      float64               `json:"total"`
 	ShareCN    float64               `json:"share_cn"`
 	Comparable bool                  `json:"comparable"`
+	ShareCNTTM *float64              `json:"share_cn_ttm,omitempty"`
 }
 
 type validationSeriesBlock struct {
-	Available bool    `json:"available"`
-	Export    float64 `json:"export"`
-	Import    float64 `json:"import"`
-	Trade     float64 `json:"trade"`
+	Available   bool           `json:"available"`
+	Export      float64        `json:"export"`
+	Import      float64        `json:"import"`
+	Trade       float64        `json:"trade"`
+	TTM         *trailingBlock `json:"ttm,omitempty"`
+	Estimated   bool           `json:"estimated,omitempty"`
+	QualityNote string         `json:"quality_note,omitempty"`
+}
+
+type validationTilt struct {
+	SchemaVersion string `json:"schema_version"`
+	GeneratedAt   string `json:"generated_at"`
+	Provider      string `json:"provider"`
+	Formula       string `json:"formula"`
+	Rows          []struct {
+		ISO3   string `json:"iso3"`
+		Name   string `json:"name,omitempty"`
+		Points []struct {
+			PeriodType string  `json:"period_type"`
+			Period     string  `json:"period"`
+			USATrade   float64 `json:"usa_trade"`
+			CHNTrade   float64 `json:"chn_trade"`
+			Tilt       float64 `json:"tilt"`
+		} `json:"points"`
+	} `json:"rows"`
+}
+
+type validationForecast struct {
+	SchemaVersion string `json:"schema_version"`
+	GeneratedAt   string `json:"generated_at"`
+	Provider      string `json:"provider"`
+	Method        string `json:"method"`
+	Horizon       int    `json:"horizon"`
+	Rows          []struct {
+		ISO3        string `json:"iso3"`
+		Name        string `json:"name,omitempty"`
+		PeriodType  string `json:"period_type"`
+		FirstPeriod string `json:"first_period"`
+		LastPeriod  string `json:"last_period"`
+		Points      int    `json:"points"`
+		Metrics     []struct {
+			Metric   string   `json:"metric"`
+			Model    string   `json:"model"`
+			Alpha    float64  `json:"alpha"`
+			Beta     float64  `json:"beta"`
+			Gamma    *float64 `json:"gamma,omitempty"`
+			RMSE     float64  `json:"rmse"`
+			Forecast []struct {
+				Period  string  `json:"period"`
+				Value   float64 `json:"value"`
+				Lower80 float64 `json:"lower_80"`
+				Upper80 float64 `json:"upper_80"`
+				Lower95 float64 `json:"lower_95"`
+				Upper95 float64 `json:"upper_95"`
+			} `json:"forecast"`
+		} `json:"metrics"`
+	} `json:"rows"`
+}
+
+type validationMovers struct {
+	SchemaVersion string `json:"schema_version"`
+	GeneratedAt   string `json:"generated_at"`
+	Provider      string `json:"provider"`
+	PeriodType    string `json:"period_type"`
+	Period        string `json:"period"`
+	Limit         int    `json:"limit"`
+	Windows       []struct {
+		ID         string `json:"id"`
+		BasePeriod string `json:"base_period"`
+		Lists      []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+			Rows  []struct {
+				Rank      int     `json:"rank"`
+				ISO3      string  `json:"iso3"`
+				Name      string  `json:"name,omitempty"`
+				BaseValue float64 `json:"base_value"`
+				Value     float64 `json:"value"`
+				Change    float64 `json:"change"`
+			} `json:"rows"`
+		} `json:"lists"`
+	} `json:"windows"`
 }
 
 type validationProductIndex struct {
@@ -61,6 +143,160 @@ type validationProductIndex struct {
 	Reporters      []string `json:"reporters"`
 }
 
+type validationVolatilityBlock struct {
+	Through string  `json:"through"`
+	Mean    float64 `json:"mean"`
+	StdDev  float64 `json:"std_dev"`
+	CV      float64 `json:"cv"`
+	Class   string  `json:"class"`
+}
+
+type validationVolatility struct {
+	SchemaVersion string             `json:"schema_version"`
+	GeneratedAt   string             `json:"generated_at"`
+	Provider      string             `json:"provider"`
+	Formula       string             `json:"formula"`
+	WindowMonths  int                `json:"window_months"`
+	Thresholds    map[string]float64 `json:"thresholds"`
+	Rows          []struct {
+		ISO3   string                     `json:"iso3"`
+		Name   string                     `json:"name,omitempty"`
+		USA    *validationVolatilityBlock `json:"usa,omitempty"`
+		CHN    *validationVolatilityBlock `json:"chn,omitempty"`
+		Points []struct {
+			Period string   `json:"period"`
+			USACV  *float64 `json:"usa_cv,omitempty"`
+			CHNCV  *float64 `json:"chn_cv,omitempty"`
+		} `json:"points"`
+	} `json:"rows"`
+}
+
+type validationDiversion struct {
+	SchemaVersion string `json:"schema_version"`
+	GeneratedAt   string `json:"generated_at"`
+	Provider      string `json:"provider"`
+	Method        string `json:"method"`
+	Limit         int    `json:"limit"`
+	Lists         []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		Rows  []struct {
+			Rank            int     `json:"rank"`
+			ISO3            string  `json:"iso3"`
+			Name            string  `json:"name,omitempty"`
+			PeriodType      string  `json:"period_type"`
+			BasePeriod      string  `json:"base_period"`
+			LastPeriod      string  `json:"last_period"`
+			Changes         int     `json:"changes"`
+			OpposingPeriods int     `json:"opposing_periods"`
+			Correlation     float64 `json:"correlation"`
+			USAChange       float64 `json:"usa_change"`
+			CHNChange       float64 `json:"chn_change"`
+			TiltChange      float64 `json:"tilt_change"`
+			Score           float64 `json:"score"`
+		} `json:"rows"`
+	} `json:"lists"`
+}
+
+type validationCorrelation struct {
+	SchemaVersion string  `json:"schema_version"`
+	GeneratedAt   string  `json:"generated_at"`
+	Provider      string  `json:"provider"`
+	Method        string  `json:"method"`
+	WindowYears   int     `json:"window_years"`
+	MinOverlap    int     `json:"min_overlap"`
+	BlocThreshold float64 `json:"bloc_threshold"`
+	Regions       []struct {
+		Region      string `json:"region"`
+		PeriodType  string `json:"period_type"`
+		FirstPeriod string `json:"first_period"`
+		LastPeriod  string `json:"last_period"`
+		Members     []struct {
+			ISO3 string `json:"iso3"`
+			Name string `json:"name,omitempty"`
+		} `json:"members"`
+		Matrix   [][]*float64 `json:"matrix"`
+		Overlaps [][]int      `json:"overlaps"`
+		Blocs    []struct {
+			Members         []string `json:"members"`
+			MeanCorrelation float64  `json:"mean_correlation"`
+			MinCorrelation  float64  `json:"min_correlation"`
+		} `json:"blocs"`
+	} `json:"regions"`
+}
+
+type validationDiscrepancies struct {
+	SchemaVersion string             `json:"schema_version"`
+	GeneratedAt   string             `json:"generated_at"`
+	Providers     []string           `json:"providers"`
+	Formula       string             `json:"formula"`
+	Thresholds    map[string]float64 `json:"thresholds"`
+	Summary       struct {
+		Pairs        int      `json:"pairs"`
+		Reporters    int      `json:"reporters"`
+		MedianAbsPct *float64 `json:"median_abs_pct,omitempty"`
+	} `json:"summary"`
+	Rows []struct {
+		ISO3         string  `json:"iso3"`
+		Name         string  `json:"name,omitempty"`
+		Pairs        int     `json:"pairs"`
+		MedianAbsPct float64 `json:"median_abs_pct"`
+		MeanAbsPct   float64 `json:"mean_abs_pct"`
+		Trust        string  `json:"trust"`
+		Largest      struct {
+			Partner        string  `json:"partner"`
+			Flow           string  `json:"flow"`
+			PeriodType     string  `json:"period_type"`
+			Period         string  `json:"period"`
+			WITSUSD        float64 `json:"wits_usd"`
+			ComtradeUSD    float64 `json:"comtrade_usd"`
+			DiscrepancyPct float64 `json:"discrepancy_pct"`
+		} `json:"largest"`
+	} `json:"rows"`
+}
+
+type validationRCAIndex struct {
+	SchemaVersion  string `json:"schema_version"`
+	GeneratedAt    string `json:"generated_at"`
+	Provider       string `json:"provider"`
+	Classification string `json:"classification"`
+	Level          int    `json:"level"`
+	Formula        string `json:"formula"`
+	Reporters      []struct {
+		ISO3   string `json:"iso3"`
+		Period string `json:"period"`
+	} `json:"reporters"`
+}
+
+type validationRCABlock struct {
+	Export         float64 `json:"export"`
+	Share          float64 `json:"share"`
+	ReferenceShare float64 `json:"reference_share"`
+	RCA            float64 `json:"rca"`
+}
+
+type validationRCAFile struct {
+	SchemaVersion      string         `json:"schema_version"`
+	GeneratedAt        string         `json:"generated_at"`
+	Provider           string         `json:"provider"`
+	Classification     string         `json:"classification"`
+	Level              int            `json:"level"`
+	ReporterISO3       string         `json:"reporter_iso3"`
+	Name               string         `json:"name,omitempty"`
+	PeriodType         string         `json:"period_type"`
+	Period             string         `json:"period"`
+	Formula            string         `json:"formula"`
+	ReferenceReporters map[string]int `json:"reference_reporters"`
+	Rows               []struct {
+		Code  string              `json:"code"`
+		Name  string              `json:"name"`
+		USA   *validationRCABlock `json:"usa,omitempty"`
+		CHN   *validationRCABlock `json:"chn,omitempty"`
+		World *validationRCABlock `json:"world,omitempty"`
+		Lean  *float64            `json:"lean,omitempty"`
+	} `json:"rows"`
+}
+
 type validationProductFile struct {
 	SchemaVersion  string                   `json:"schema_version"`
 	GeneratedAt    string                   `json:"generated_at"`
@@ -72,6 +308,22 @@ type validationProductFile struct {
 	Rows           []validationProductEntry `json:"rows"`
 }
 
+type validationArtifactIndex struct {
+	SchemaVersion string                    `json:"schema_version"`
+	GeneratedAt   string                    `json:"generated_at"`
+	FileCount     int                       `json:"file_count"`
+	TotalBytes    int64                     `json:"total_bytes"`
+	Files         []validationArtifactEntry `json:"files"`
+}
+
+type validationArtifactEntry struct {
+	Path          string `json:"path"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+	GeneratedAt   string `json:"generated_at,omitempty"`
+}
+
 type validationCatalog struct {
 	SchemaVersion string                      `json:"schema_version"`
 	GeneratedAt   string                      `json:"generated_at"`
@@ -237,11 +489,13 @@ type validationMatrixFile struct {
 	ProductLevel  int                       `json:"product_level"`
 	ReporterISO3  string                    `json:"reporter_iso3"`
 	Period        string                    `json:"period"`
+	Concentration *concentration            `json:"concentration,omitempty"`
 	Rows          []validationMatrixPartner `json:"rows"`
 }
 
 type validationMatrixPartner struct {
 	PartnerISO3     string  `json:"partner_iso3"`
+	Label           string  `json:"label,omitempty"`
 	ExportAvailable bool    `json:"export_available"`
 	ImportAvailable bool    `json:"import_available"`
 	ExportUSD       float64 `json:"export_usd"`
@@ -388,6 +642,10 @@ type validationQuality struct {
 	ReporterIssues     []validationReporterIssue      `json:"reporter_issues"`
 	CollectionRuns     []validationRun                `json:"collection_runs"`
 	ProviderComparison []validationProviderComparison `json:"provider_comparison"`
+	// Older builds have no consistency check; the tolerance and list are
+	// then absent.
+	ConsistencyTolerance float64                       `json:"consistency_tolerance"`
+	PeriodConsistency    []validationPeriodConsistency `json:"period_consistency"`
 }
 
 type validationQualitySummary struct {
@@ -397,6 +655,20 @@ type validationQualitySummary struct {
 	MissingPartnerBlocks  int `json:"missing_partner_blocks"`
 	StalePartnerBlocks    int `json:"stale_partner_blocks"`
 	ComparisonCount       int `json:"provider_comparison_count"`
+	ConsistencyChecks     int `json:"consistency_check_count"`
+	ConsistencyFlags      int `json:"consistency_flag_count"`
+	EstimatedObservations int `json:"estimated_observation_count"`
+	EstimatedBlocks       int `json:"estimated_partner_blocks"`
+}
+
+type validationPeriodConsistency struct {
+	ISO3          string  `json:"iso3"`
+	Partner       string  `json:"partner"`
+	Flow          string  `json:"flow"`
+	Year          string  `json:"year"`
+	AnnualUSD     float64 `json:"annual_usd"`
+	MonthlySumUSD float64 `json:"monthly_sum_usd"`
+	DeltaRatio    float64 `json:"delta_ratio"`
 }
 
 type validationReporterIssue struct {
@@ -420,6 +692,8 @@ type validationRun struct {
 	SkippedCount  int      `json:"skipped_count"`
 	StoredCount   int      `json:"stored_count"`
 	Errors        []string `json:"errors"`
+	CodeVersion   string   `json:"code_version,omitempty"`
+	CodeCommit    string   `json:"code_commit,omitempty"`
 }
 
 type validationProviderComparison struct {
@@ -578,6 +852,33 @@ func validateExtendedDataset(dataDir string, metadata datasetMeta, latest datase
 	if err := validateCatalog(metadata, catalog, publicationChanges); err != nil {
 		return err
 	}
+	if err := validateArtifactIndex(dataDir, metadata); err != nil {
+		return err
+	}
+	if err := validateTilt(dataDir, metadata); err != nil {
+		return err
+	}
+	if err := validateMovers(dataDir, metadata); err != nil {
+		return err
+	}
+	if err := validateForecast(dataDir, metadata); err != nil {
+		return err
+	}
+	if err := validateVolatility(dataDir, metadata); err != nil {
+		return err
+	}
+	if err := validateDiversion(dataDir, metadata); err != nil {
+		return err
+	}
+	if err := validateCorrelation(dataDir, metadata); err != nil {
+		return err
+	}
+	if err := validateDiscrepancies(dataDir, metadata); err != nil {
+		return err
+	}
+	if err := validateRCA(dataDir, metadata); err != nil {
+		return err
+	}
 	if err := validateExplanations(dataDir, metadata, latest); err != nil {
 		return err
 	}
@@ -671,6 +972,459 @@ func validateCatalog(metadata datasetMeta, catalog validationCatalog, publicatio
 	return nil
 }
 
+// validateTilt checks tilt.json, when present: every point needs positive
+// USA+CHN trade and a tilt equal to (chn - usa) / (chn + usa).
+func validateTilt(dataDir string, metadata datasetMeta) error {
+	var tilt validationTilt
+	err := readJSON(filepath.Join(dataDir, "tilt.json"), &tilt)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read tilt.json: %w", err)
+	}
+	if tilt.SchemaVersion != metadata.SchemaVersion || tilt.GeneratedAt != metadata.GeneratedAt || tilt.Provider != metadata.Provider || tilt.Formula == "" {
+		return errorsForExtended("tilt provenance does not match metadata")
+	}
+	seen := make(map[string]struct{}, len(tilt.Rows))
+	for _, row := range tilt.Rows {
+		if !iso3Pattern.MatchString(row.ISO3) || len(row.Points) == 0 {
+			return fmt.Errorf("tilt has invalid reporter row %q", row.ISO3)
+		}
+		if _, exists := seen[row.ISO3]; exists {
+			return fmt.Errorf("tilt has duplicate reporter %q", row.ISO3)
+		}
+		seen[row.ISO3] = struct{}{}
+		for _, point := range row.Points {
+			if !validPeriod(point.PeriodType, point.Period) {
+				return fmt.Errorf("tilt %s has invalid period %s/%s", row.ISO3, point.PeriodType, point.Period)
+			}
+			total := point.USATrade + point.CHNTrade
+			if !isFinite(point.USATrade) || !isFinite(point.CHNTrade) || point.USATrade < 0 || point.CHNTrade < 0 || total <= 0 {
+				return fmt.Errorf("tilt %s %s has invalid trade values", row.ISO3, point.Period)
+			}
+			if !isFinite(point.Tilt) || !approximatelyEqual(point.Tilt, (point.CHNTrade-point.USATrade)/total) {
+				return fmt.Errorf("tilt %s %s value %v does not equal calculated value %v", row.ISO3, point.Period, point.Tilt, (point.CHNTrade-point.USATrade)/total)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMovers checks movers.json, when present: each change must follow
+// from its base and current values, and rows must be ranked by the size of
+// the change.
+func validateMovers(dataDir string, metadata datasetMeta) error {
+	var movers validationMovers
+	err := readJSON(filepath.Join(dataDir, "movers.json"), &movers)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read movers.json: %w", err)
+	}
+	if movers.SchemaVersion != metadata.SchemaVersion || movers.GeneratedAt != metadata.GeneratedAt || movers.Provider != metadata.Provider || movers.Limit < 0 {
+		return errorsForExtended("movers provenance does not match metadata")
+	}
+	for _, window := range movers.Windows {
+		if !validPeriod(movers.PeriodType, window.BasePeriod) || window.BasePeriod >= movers.Period {
+			return fmt.Errorf("movers window %s has invalid base period %q", window.ID, window.BasePeriod)
+		}
+		for _, list := range window.Lists {
+			relative := strings.HasSuffix(list.ID, "_rel")
+			if movers.Limit > 0 && len(list.Rows) > movers.Limit {
+				return fmt.Errorf("movers %s/%s has more rows than its limit", window.ID, list.ID)
+			}
+			previous := math.Inf(1)
+			for index, row := range list.Rows {
+				want := row.Value - row.BaseValue
+				if relative {
+					want /= row.BaseValue
+				}
+				if row.Rank != index+1 || !iso3Pattern.MatchString(row.ISO3) || !isFinite(row.Change) || !approximatelyEqual(row.Change, want) {
+					return fmt.Errorf("movers %s/%s has inconsistent row %+v", window.ID, list.ID, row)
+				}
+				if math.Abs(row.Change) > previous {
+					return fmt.Errorf("movers %s/%s rows are not sorted by change", window.ID, list.ID)
+				}
+				previous = math.Abs(row.Change)
+			}
+		}
+	}
+	return nil
+}
+
+// validateForecast checks forecast.json, when present: every metric must
+// project horizon periods after the series' last period, with the 95% band
+// around the 80% band around the value, and shares within [0, 1].
+func validateForecast(dataDir string, metadata datasetMeta) error {
+	var forecast validationForecast
+	err := readJSON(filepath.Join(dataDir, "forecast.json"), &forecast)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read forecast.json: %w", err)
+	}
+	if forecast.SchemaVersion != metadata.SchemaVersion || forecast.GeneratedAt != metadata.GeneratedAt || forecast.Provider != metadata.Provider || forecast.Method == "" || forecast.Horizon < 1 || forecast.Horizon > 4 {
+		return errorsForExtended("forecast provenance does not match metadata")
+	}
+	for _, row := range forecast.Rows {
+		if !iso3Pattern.MatchString(row.ISO3) || !validPeriod(row.PeriodType, row.LastPeriod) || len(row.Metrics) == 0 {
+			return fmt.Errorf("forecast has invalid reporter row %q", row.ISO3)
+		}
+		for _, metric := range row.Metrics {
+			if len(metric.Forecast) != forecast.Horizon {
+				return fmt.Errorf("forecast %s %s has %d periods, not %d", row.ISO3, metric.Metric, len(metric.Forecast), forecast.Horizon)
+			}
+			previous := row.LastPeriod
+			for _, point := range metric.Forecast {
+				if !validPeriod(row.PeriodType, point.Period) || point.Period <= previous {
+					return fmt.Errorf("forecast %s %s has invalid period %q", row.ISO3, metric.Metric, point.Period)
+				}
+				previous = point.Period
+				values := []float64{point.Lower95, point.Lower80, point.Value, point.Upper80, point.Upper95}
+				for index, value := range values {
+					if !isFinite(value) || value < 0 || (metric.Metric == "share_cn" && value > 1) || (index > 0 && value < values[index-1]) {
+						return fmt.Errorf("forecast %s %s %s has inconsistent bands", row.ISO3, metric.Metric, point.Period)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateRCA checks rca/, when present: every index must equal its share
+// over its reference share, and lean must follow from the USA and China
+// indexes.
+func validateRCA(dataDir string, metadata datasetMeta) error {
+	var index validationRCAIndex
+	err := readJSON(filepath.Join(dataDir, "rca", "index.json"), &index)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read rca/index.json: %w", err)
+	}
+	if index.SchemaVersion != metadata.SchemaVersion || index.GeneratedAt != metadata.GeneratedAt || index.Provider != metadata.ProductProvider || index.Level != metadata.ProductLevel || index.Formula == "" {
+		return errorsForExtended("rca index does not match metadata")
+	}
+	for _, record := range index.Reporters {
+		if !iso3Pattern.MatchString(record.ISO3) || !yearPattern.MatchString(record.Period) {
+			return fmt.Errorf("rca index has invalid reporter %q", record.ISO3)
+		}
+		var file validationRCAFile
+		if err := readJSON(filepath.Join(dataDir, "rca", record.ISO3+".json"), &file); err != nil {
+			return fmt.Errorf("read rca for %s: %w", record.ISO3, err)
+		}
+		if file.SchemaVersion != index.SchemaVersion || file.GeneratedAt != index.GeneratedAt || file.Provider != index.Provider || file.Classification != index.Classification || file.Level != index.Level || file.ReporterISO3 != record.ISO3 || file.Period != record.Period || file.PeriodType != "Y" {
+			return fmt.Errorf("rca file for %s does not match index", record.ISO3)
+		}
+		for _, row := range file.Rows {
+			if !hs2Pattern.MatchString(row.Code) || strings.TrimSpace(row.Name) == "" {
+				return fmt.Errorf("rca file %s has invalid sector %q", record.ISO3, row.Code)
+			}
+			for _, block := range []*validationRCABlock{row.USA, row.CHN, row.World} {
+				if block == nil {
+					continue
+				}
+				if !isFinite(block.RCA) || block.Export <= 0 || block.Share <= 0 || block.Share > 1 || block.ReferenceShare <= 0 || block.ReferenceShare > 1 || !approximatelyEqual(block.RCA, block.Share/block.ReferenceShare) {
+					return fmt.Errorf("rca %s %s has an inconsistent index", record.ISO3, row.Code)
+				}
+			}
+			if (row.Lean != nil) != (row.USA != nil && row.CHN != nil) {
+				return fmt.Errorf("rca %s %s lean needs both USA and CHN indexes", record.ISO3, row.Code)
+			}
+			if row.Lean != nil && !approximatelyEqual(*row.Lean, (row.CHN.RCA-row.USA.RCA)/(row.CHN.RCA+row.USA.RCA)) {
+				return fmt.Errorf("rca %s %s lean does not follow from its indexes", record.ISO3, row.Code)
+			}
+		}
+	}
+	return nil
+}
+
+// validateVolatility checks volatility.json, when present: each partner
+// summary must be the reporter's latest point for that partner, with a cv of
+// std_dev / mean and the class its thresholds give.
+func validateVolatility(dataDir string, metadata datasetMeta) error {
+	var volatility validationVolatility
+	err := readJSON(filepath.Join(dataDir, "volatility.json"), &volatility)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read volatility.json: %w", err)
+	}
+	stable, noisy := volatility.Thresholds["stable"], volatility.Thresholds["noisy"]
+	if volatility.SchemaVersion != metadata.SchemaVersion || volatility.GeneratedAt != metadata.GeneratedAt || volatility.Provider != metadata.Provider || volatility.Formula == "" || volatility.WindowMonths < 2 || stable <= 0 || noisy <= stable {
+		return errorsForExtended("volatility provenance does not match metadata")
+	}
+	seen := make(map[string]struct{}, len(volatility.Rows))
+	for _, row := range volatility.Rows {
+		if !iso3Pattern.MatchString(row.ISO3) || len(row.Points) == 0 || (row.USA == nil && row.CHN == nil) {
+			return fmt.Errorf("volatility has invalid reporter row %q", row.ISO3)
+		}
+		if _, exists := seen[row.ISO3]; exists {
+			return fmt.Errorf("volatility has duplicate reporter %q", row.ISO3)
+		}
+		seen[row.ISO3] = struct{}{}
+		latest := map[string]string{}
+		previous := ""
+		for _, point := range row.Points {
+			if !validPeriod("M", point.Period) || point.Period <= previous || (point.USACV == nil && point.CHNCV == nil) {
+				return fmt.Errorf("volatility %s has invalid period %q", row.ISO3, point.Period)
+			}
+			previous = point.Period
+			for partner, cv := range map[string]*float64{"usa": point.USACV, "chn": point.CHNCV} {
+				if cv == nil {
+					continue
+				}
+				if !isFinite(*cv) || *cv < 0 {
+					return fmt.Errorf("volatility %s %s has invalid %s cv", row.ISO3, point.Period, partner)
+				}
+				latest[partner] = point.Period
+			}
+		}
+		for partner, block := range map[string]*validationVolatilityBlock{"usa": row.USA, "chn": row.CHN} {
+			if block == nil {
+				if latest[partner] != "" {
+					return fmt.Errorf("volatility %s is missing its %s summary", row.ISO3, partner)
+				}
+				continue
+			}
+			if block.Through != latest[partner] || !isFinite(block.Mean) || block.Mean <= 0 || !isFinite(block.StdDev) || block.StdDev < 0 || !approximatelyEqual(block.CV, block.StdDev/block.Mean) {
+				return fmt.Errorf("volatility %s %s summary is inconsistent", row.ISO3, partner)
+			}
+			wantClass := "noisy"
+			if block.CV < stable {
+				wantClass = "stable"
+			} else if block.CV < noisy {
+				wantClass = "moderate"
+			}
+			if block.Class != wantClass {
+				return fmt.Errorf("volatility %s %s class %q does not match cv %v", row.ISO3, partner, block.Class, block.CV)
+			}
+		}
+	}
+	return nil
+}
+
+// validateDiversion checks diversion.json, when present: each score must be
+// -correlation × |tilt_change| with a negative correlation, the tilt must
+// move in the list's direction, and rows must be ranked by score.
+func validateDiversion(dataDir string, metadata datasetMeta) error {
+	var diversion validationDiversion
+	err := readJSON(filepath.Join(dataDir, "diversion.json"), &diversion)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read diversion.json: %w", err)
+	}
+	if diversion.SchemaVersion != metadata.SchemaVersion || diversion.GeneratedAt != metadata.GeneratedAt || diversion.Provider != metadata.Provider || diversion.Method == "" || diversion.Limit < 0 {
+		return errorsForExtended("diversion provenance does not match metadata")
+	}
+	seenLists := make(map[string]struct{}, len(diversion.Lists))
+	for _, list := range diversion.Lists {
+		if list.ID != "usa_to_chn" && list.ID != "chn_to_usa" {
+			return fmt.Errorf("diversion has unknown list %q", list.ID)
+		}
+		if _, exists := seenLists[list.ID]; exists {
+			return fmt.Errorf("diversion has duplicate list %q", list.ID)
+		}
+		seenLists[list.ID] = struct{}{}
+		if diversion.Limit > 0 && len(list.Rows) > diversion.Limit {
+			return fmt.Errorf("diversion %s has %d rows over the limit of %d", list.ID, len(list.Rows), diversion.Limit)
+		}
+		for index, row := range list.Rows {
+			if row.Rank != index+1 || !iso3Pattern.MatchString(row.ISO3) || !validPeriod(row.PeriodType, row.BasePeriod) || !validPeriod(row.PeriodType, row.LastPeriod) || row.BasePeriod >= row.LastPeriod {
+				return fmt.Errorf("diversion %s has invalid row %d", list.ID, index+1)
+			}
+			if row.Changes < 1 || row.OpposingPeriods < 1 || row.OpposingPeriods > row.Changes || !isFinite(row.Correlation) || row.Correlation >= 0 || row.Correlation < -1 {
+				return fmt.Errorf("diversion %s %s has invalid counts or correlation", list.ID, row.ISO3)
+			}
+			if (list.ID == "usa_to_chn") != (row.TiltChange > 0) || row.TiltChange == 0 || !approximatelyEqual(row.Score, -row.Correlation*math.Abs(row.TiltChange)) {
+				return fmt.Errorf("diversion %s %s score %v does not follow its correlation and tilt change", list.ID, row.ISO3, row.Score)
+			}
+			if index > 0 && row.Score > list.Rows[index-1].Score {
+				return fmt.Errorf("diversion %s is not ranked by score at row %d", list.ID, index+1)
+			}
+		}
+	}
+	return nil
+}
+
+// validateCorrelation checks correlation.json, when present: each matrix
+// must be square over its members and symmetric, correlations need at least
+// min_overlap shared changes, and every bloc member must be linked to
+// another by a pair at or above the bloc threshold.
+func validateCorrelation(dataDir string, metadata datasetMeta) error {
+	var correlation validationCorrelation
+	err := readJSON(filepath.Join(dataDir, "correlation.json"), &correlation)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read correlation.json: %w", err)
+	}
+	if correlation.SchemaVersion != metadata.SchemaVersion || correlation.GeneratedAt != metadata.GeneratedAt || correlation.Provider != metadata.Provider || correlation.Method == "" || correlation.WindowYears < 1 || correlation.MinOverlap < 2 {
+		return errorsForExtended("correlation provenance does not match metadata")
+	}
+	seenRegions := make(map[string]struct{}, len(correlation.Regions))
+	for _, region := range correlation.Regions {
+		if _, exists := seenRegions[region.Region]; exists || region.Region == "" {
+			return fmt.Errorf("correlation has duplicate or empty region %q", region.Region)
+		}
+		seenRegions[region.Region] = struct{}{}
+		if !validPeriod(region.PeriodType, region.FirstPeriod) || !validPeriod(region.PeriodType, region.LastPeriod) || region.FirstPeriod > region.LastPeriod {
+			return fmt.Errorf("correlation %s has an invalid window", region.Region)
+		}
+		size := len(region.Members)
+		if size < 2 || len(region.Matrix) != size || len(region.Overlaps) != size {
+			return fmt.Errorf("correlation %s matrix does not match its %d members", region.Region, size)
+		}
+		positions := make(map[string]int, size)
+		for index, member := range region.Members {
+			if _, exists := positions[member.ISO3]; exists || !iso3Pattern.MatchString(member.ISO3) {
+				return fmt.Errorf("correlation %s has invalid member %q", region.Region, member.ISO3)
+			}
+			positions[member.ISO3] = index
+		}
+		for i := range size {
+			if len(region.Matrix[i]) != size || len(region.Overlaps[i]) != size {
+				return fmt.Errorf("correlation %s row %d is not %d wide", region.Region, i+1, size)
+			}
+			for j := range size {
+				value, mirrored := region.Matrix[i][j], region.Matrix[j][i]
+				if region.Overlaps[i][j] != region.Overlaps[j][i] || (value == nil) != (mirrored == nil) || (value != nil && *value != *mirrored) {
+					return fmt.Errorf("correlation %s is not symmetric at %s/%s", region.Region, region.Members[i].ISO3, region.Members[j].ISO3)
+				}
+				if value != nil && (!isFinite(*value) || *value < -1 || *value > 1 || region.Overlaps[i][j] < correlation.MinOverlap) {
+					return fmt.Errorf("correlation %s %s/%s has an invalid value", region.Region, region.Members[i].ISO3, region.Members[j].ISO3)
+				}
+			}
+		}
+		inBloc := make(map[string]struct{}, size)
+		for _, bloc := range region.Blocs {
+			if len(bloc.Members) < 2 || !isFinite(bloc.MeanCorrelation) || bloc.MinCorrelation > bloc.MeanCorrelation+1e-9 {
+				return fmt.Errorf("correlation %s has an invalid bloc", region.Region)
+			}
+			for _, iso3 := range bloc.Members {
+				i, ok := positions[iso3]
+				if _, exists := inBloc[iso3]; exists || !ok {
+					return fmt.Errorf("correlation %s bloc member %q is unknown or repeated", region.Region, iso3)
+				}
+				inBloc[iso3] = struct{}{}
+				linked := false
+				for _, other := range bloc.Members {
+					if value := region.Matrix[i][positions[other]]; other != iso3 && value != nil && *value >= correlation.BlocThreshold {
+						linked = true
+					}
+				}
+				if !linked {
+					return fmt.Errorf("correlation %s bloc member %s is not linked above the threshold", region.Region, iso3)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateDiscrepancies checks discrepancies.json, when present: reporter
+// pair counts must add up to the summary, each largest pair's percentage
+// must follow its two values, and the trust class must follow the median.
+func validateDiscrepancies(dataDir string, metadata datasetMeta) error {
+	var discrepancies validationDiscrepancies
+	err := readJSON(filepath.Join(dataDir, "discrepancies.json"), &discrepancies)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read discrepancies.json: %w", err)
+	}
+	if discrepancies.SchemaVersion != metadata.SchemaVersion || discrepancies.GeneratedAt != metadata.GeneratedAt || discrepancies.Formula == "" {
+		return errorsForExtended("discrepancies provenance does not match metadata")
+	}
+	high, medium := discrepancies.Thresholds["high"], discrepancies.Thresholds["medium"]
+	if high < 0 || medium < high {
+		return errorsForExtended("discrepancies thresholds are invalid")
+	}
+	pairs := 0
+	for index, row := range discrepancies.Rows {
+		if !iso3Pattern.MatchString(row.ISO3) || (index > 0 && row.ISO3 <= discrepancies.Rows[index-1].ISO3) {
+			return fmt.Errorf("discrepancies row %d is invalid or out of order", index+1)
+		}
+		if row.Pairs < 1 || !isFinite(row.MedianAbsPct) || row.MedianAbsPct < 0 || !isFinite(row.MeanAbsPct) || row.MeanAbsPct < 0 {
+			return fmt.Errorf("discrepancies %s has invalid counts or percentages", row.ISO3)
+		}
+		pairs += row.Pairs
+		largest := row.Largest
+		if !iso3Pattern.MatchString(largest.Partner) || !validFlow(largest.Flow) || !validPeriod(largest.PeriodType, largest.Period) {
+			return fmt.Errorf("discrepancies %s has an invalid largest pair", row.ISO3)
+		}
+		if largest.WITSUSD <= 0 || largest.ComtradeUSD < 0 || !approximatelyEqual(largest.DiscrepancyPct, (largest.ComtradeUSD-largest.WITSUSD)/largest.WITSUSD*100) || math.Abs(largest.DiscrepancyPct) < row.MedianAbsPct-1e-9 {
+			return fmt.Errorf("discrepancies %s largest pair does not follow its values", row.ISO3)
+		}
+		want := "low"
+		switch {
+		case row.MedianAbsPct <= high:
+			want = "high"
+		case row.MedianAbsPct <= medium:
+			want = "medium"
+		}
+		if row.Trust != want {
+			return fmt.Errorf("discrepancies %s trust %q does not match median %v", row.ISO3, row.Trust, row.MedianAbsPct)
+		}
+	}
+	if pairs != discrepancies.Summary.Pairs || len(discrepancies.Rows) != discrepancies.Summary.Reporters || (pairs > 0) != (discrepancies.Summary.MedianAbsPct != nil) {
+		return fmt.Errorf("discrepancies summary does not match its %d rows", len(discrepancies.Rows))
+	}
+	return nil
+}
+
+// validateArtifactIndex checks index.json, when present, against the files on
+// disk: each listed file must exist with the recorded size and sha256, and
+// JSON artifacts must carry the index's generated_at.
+func validateArtifactIndex(dataDir string, metadata datasetMeta) error {
+	var index validationArtifactIndex
+	err := readJSON(filepath.Join(dataDir, "index.json"), &index)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read index.json: %w", err)
+	}
+	if index.SchemaVersion != metadata.SchemaVersion || index.GeneratedAt != metadata.GeneratedAt {
+		return errorsForExtended("artifact index provenance does not match metadata")
+	}
+	if index.FileCount != len(index.Files) {
+		return fmt.Errorf("artifact index file_count %d does not match %d files", index.FileCount, len(index.Files))
+	}
+	var totalBytes int64
+	for _, file := range index.Files {
+		if file.Path == "" || strings.Contains(file.Path, "..") || strings.HasPrefix(file.Path, "/") {
+			return fmt.Errorf("artifact index has invalid path %q", file.Path)
+		}
+		body, err := os.ReadFile(filepath.Join(dataDir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			return fmt.Errorf("artifact index lists %s: %w", file.Path, err)
+		}
+		sum := sha256.Sum256(body)
+		if int64(len(body)) != file.Size || hex.EncodeToString(sum[:]) != file.SHA256 {
+			return fmt.Errorf("artifact %s does not match its index entry", file.Path)
+		}
+		if file.GeneratedAt != "" && file.GeneratedAt != index.GeneratedAt {
+			return fmt.Errorf("artifact %s is from another publish (%s)", file.Path, file.GeneratedAt)
+		}
+		totalBytes += file.Size
+	}
+	if totalBytes != index.TotalBytes {
+		return fmt.Errorf("artifact index total_bytes %d does not match %d", index.TotalBytes, totalBytes)
+	}
+	return nil
+}
+
 func validatePublicationChanges(metadata datasetMeta, monthly validationSemiconductorMonthlyIndex, changes validationPublicationChanges) error {
 	if changes.SchemaVersion != "1.0" || changes.GeneratedAt != metadata.GeneratedAt || strings.TrimSpace(changes.Scope) == "" {
 		return errorsForExtended("publication change provenance does not match metadata")
@@ -1127,6 +1881,7 @@ func validateMatrix(dataDir string, metadata datasetMeta, index validationMatrix
 			return fmt.Errorf("bilateral matrix partition %s does not match its index", key)
 		}
 		seenPartners := make(map[string]struct{}, len(file.Rows))
+		partnerTrade := make(map[string]float64, len(file.Rows))
 		previousTrade := math.Inf(1)
 		for _, row := range file.Rows {
 			if !iso3Pattern.MatchString(row.PartnerISO3) || row.PartnerISO3 == partition.ReporterISO3 || row.PartnerISO3 == "WLD" || (!row.ExportAvailable && !row.ImportAvailable) {
@@ -1151,6 +1906,7 @@ func validateMatrix(dataDir string, metadata datasetMeta, index validationMatrix
 				return fmt.Errorf("bilateral matrix partition %s rows are not sorted by trade", key)
 			}
 			previousTrade = row.TradeUSD
+			partnerTrade[row.PartnerISO3] = row.TradeUSD
 			partnerSet[row.PartnerISO3] = struct{}{}
 			partnerRowCount++
 			if row.ExportAvailable {
@@ -1160,6 +1916,9 @@ func validateMatrix(dataDir string, metadata datasetMeta, index validationMatrix
 				observationCount++
 			}
 		}
+		if err := validateConcentration("bilateral matrix partition "+key, file.Concentration, partnerTrade); err != nil {
+			return err
+		}
 	}
 	if partnerRowCount != index.PartnerRowCount || observationCount != index.ObservationCount || !sameStringSet(index.Reporters, reporterSet) || !sameStringSet(index.Partners, partnerSet) || !sameStringSet(index.Periods, periodSet) {
 		return errorsForExtended("bilateral matrix partition discovery does not match index dimensions")
@@ -1387,6 +2146,13 @@ func validateSeries(metadata datasetMeta, latest datasetLatest, series validatio
 			if !approximatelyEqual(point.ShareCN, wantShare) {
 				return fmt.Errorf("series %s %s has inconsistent China share", reporter.ISO3, point.Period)
 			}
+			for partner, block := range map[string]validationSeriesBlock{"USA": point.USA, "CHN": point.CHN} {
+				if block.TTM != nil {
+					if err := validateTrailing(reporter.ISO3, partner, point.PeriodType, point.Period, *block.TTM); err != nil {
+						return fmt.Errorf("series: %w", err)
+					}
+				}
+			}
 		}
 	}
 	if len(series.Rows) != metadata.SeriesReporterCount || pointCount != metadata.SeriesPointCount {
@@ -1407,6 +2173,9 @@ func validateSeriesBlock(reporter, partner string, block validationSeriesBlock)
 	if !block.Available && block.Trade != 0 {
 		return fmt.Errorf("series %s %s has values while unavailable", reporter, partner)
 	}
+	if (block.Estimated && !block.Available) || (block.QualityNote != "" && !block.Estimated) {
+		return fmt.Errorf("series %s %s has inconsistent estimate flags", reporter, partner)
+	}
 	return nil
 }
 
@@ -1418,6 +2187,8 @@ func validateQuality(metadata datasetMeta, quality validationQuality) error {
 		ReporterCount: metadata.ReporterCount, ComparableReporters: metadata.ComparableReporters,
 		IncomparableReporters: metadata.IncomparableReporters, MissingPartnerBlocks: metadata.MissingPartnerBlocks,
 		StalePartnerBlocks: metadata.StalePartnerBlocks, ComparisonCount: len(quality.ProviderComparison),
+		ConsistencyChecks: quality.Summary.ConsistencyChecks, ConsistencyFlags: len(quality.PeriodConsistency),
+		EstimatedObservations: quality.Summary.EstimatedObservations, EstimatedBlocks: quality.Summary.EstimatedBlocks,
 	}
 	if !reflect.DeepEqual(quality.Summary, want) {
 		return fmt.Errorf("quality summary mismatch: got=%+v want=%+v", quality.Summary, want)
@@ -1449,6 +2220,23 @@ func validateQuality(metadata datasetMeta, quality validationQuality) error {
 			return fmt.Errorf("provider comparison for %s has invalid values", comparison.ISO3)
 		}
 	}
+	if quality.ConsistencyTolerance < 0 || !isFinite(quality.ConsistencyTolerance) || quality.Summary.ConsistencyFlags > quality.Summary.ConsistencyChecks {
+		return errorsForExtended("quality consistency tolerance or counts are invalid")
+	}
+	if quality.Summary.EstimatedObservations < 0 || quality.Summary.EstimatedObservations > metadata.ObservationCount || quality.Summary.EstimatedBlocks < 0 || quality.Summary.EstimatedBlocks > 2*metadata.ReporterCount {
+		return errorsForExtended("quality estimate counts are invalid")
+	}
+	for _, check := range quality.PeriodConsistency {
+		if !iso3Pattern.MatchString(check.ISO3) || !iso3Pattern.MatchString(check.Partner) || !validFlow(check.Flow) || !validPeriod("Y", check.Year) {
+			return fmt.Errorf("invalid period consistency identity: %+v", check)
+		}
+		if !isFinite(check.AnnualUSD) || check.AnnualUSD <= 0 || !isFinite(check.MonthlySumUSD) || check.MonthlySumUSD < 0 || !approximatelyEqual(check.DeltaRatio, (check.MonthlySumUSD-check.AnnualUSD)/check.AnnualUSD) {
+			return fmt.Errorf("period consistency for %s %s %s has invalid values", check.ISO3, check.Partner, check.Year)
+		}
+		if math.Abs(check.DeltaRatio) <= quality.ConsistencyTolerance {
+			return fmt.Errorf("period consistency for %s %s %s is within the tolerance", check.ISO3, check.Partner, check.Year)
+		}
+	}
 	return nil
 }
 
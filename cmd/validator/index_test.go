@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateArtifactIndexDetectsPartialDeploy(t *testing.T) {
+	dir := t.TempDir()
+	metadata, _ := validDataset()
+	body := fmt.Sprintf(`{"schema_version":"2.0","generated_at":%q}`, metadata.GeneratedAt)
+	if err := os.WriteFile(filepath.Join(dir, "a.json"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateArtifactIndex(dir, metadata); err != nil {
+		t.Fatalf("missing index.json should be allowed: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte(body))
+	entry := fmt.Sprintf(`"size":%d,"sha256":%q,"generated_at":%q`, len(body), hex.EncodeToString(sum[:]), metadata.GeneratedAt)
+	index := fmt.Sprintf(`{"schema_version":%q,"generated_at":%q,"file_count":2,"total_bytes":%d,"files":[{"path":"a.json",%s},{"path":"b.json",%s}]}`,
+		metadata.SchemaVersion, metadata.GeneratedAt, 2*len(body), entry, entry)
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateArtifactIndex(dir, metadata); err == nil || !strings.Contains(err.Error(), "b.json") {
+		t.Fatalf("expected missing b.json to be reported, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "b.json"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateArtifactIndex(dir, metadata); err != nil {
+		t.Fatalf("complete deploy rejected: %v", err)
+	}
+}
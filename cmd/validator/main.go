@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -11,9 +12,12 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
+
+	"tradegravity/internal/buildinfo"
 )
 
 var (
@@ -23,9 +27,19 @@ var (
 	monthPattern   = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
 )
 
+// datasetBuild is the code that produced the dataset.
+type datasetBuild struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	CommitTime string `json:"commit_time,omitempty"`
+	Modified   bool   `json:"modified,omitempty"`
+	Date       string `json:"date,omitempty"`
+}
+
 type datasetMeta struct {
 	SchemaVersion                        string         `json:"schema_version"`
 	GeneratedAt                          string         `json:"generated_at"`
+	Build                                *datasetBuild  `json:"build,omitempty"`
 	Provider                             string         `json:"provider"`
 	Partners                             []string       `json:"partners"`
 	ReporterCount                        int            `json:"reporter_count"`
@@ -35,11 +49,25 @@ type datasetMeta struct {
 	MissingPartnerBlocks                 int            `json:"missing_partner_blocks"`
 	PeriodCounts                         map[string]int `json:"period_counts"`
 	DominantPeriod                       string         `json:"dominant_period"`
+	ShareAlignment                       string         `json:"share_alignment,omitempty"`
+	MixedPeriods                         string         `json:"mixed_periods,omitempty"`
+	ProviderMerge                        string         `json:"provider_merge,omitempty"`
+	Currencies                           []string       `json:"currencies,omitempty"`
+	MaxStaleness                         string         `json:"max_staleness,omitempty"`
+	StalePolicy                          string         `json:"stale_policy,omitempty"`
+	StaleReporters                       []string       `json:"stale_reporters,omitempty"`
 	ComparableReporters                  int            `json:"comparable_reporters"`
 	IncomparableReporters                int            `json:"incomparable_reporters"`
 	StalePartnerBlocks                   int            `json:"stale_partner_blocks"`
 	SeriesReporterCount                  int            `json:"series_reporter_count"`
 	SeriesPointCount                     int            `json:"series_point_count"`
+	HistoryReporterCount                 int            `json:"history_reporter_count"`
+	HistoryPointCount                    int            `json:"history_point_count"`
+	HistoryFirstPeriod                   string         `json:"history_first_period,omitempty"`
+	HistoryLastPeriod                    string         `json:"history_last_period,omitempty"`
+	CountryFileCount                     int            `json:"country_file_count"`
+	AggregateCount                       int            `json:"aggregate_count"`
+	CoverageReporterCount                int            `json:"coverage_reporter_count"`
 	ProductProvider                      string         `json:"product_provider,omitempty"`
 	ProductClassification                string         `json:"product_classification,omitempty"`
 	ProductLevel                         int            `json:"product_level,omitempty"`
@@ -84,20 +112,71 @@ type datasetLatest struct {
 }
 
 type datasetRow struct {
-	ISO3             string        `json:"iso3"`
-	ISO2             string        `json:"iso2,omitempty"`
-	Name             string        `json:"name,omitempty"`
-	Region           string        `json:"region,omitempty"`
-	IncomeGroup      string        `json:"income_group,omitempty"`
-	Groups           []string      `json:"groups,omitempty"`
-	Population       contextMetric `json:"population"`
-	GDP              contextMetric `json:"gdp"`
-	USA              partnerBlock  `json:"usa"`
-	CHN              partnerBlock  `json:"chn"`
-	Total            float64       `json:"total"`
-	ShareCN          float64       `json:"share_cn"`
-	SamePeriod       bool          `json:"same_period"`
-	ComparisonPeriod string        `json:"comparison_period,omitempty"`
+	ISO3                 string                  `json:"iso3"`
+	ISO2                 string                  `json:"iso2,omitempty"`
+	Name                 string                  `json:"name,omitempty"`
+	NameKO               string                  `json:"name_ko,omitempty"`
+	Region               string                  `json:"region,omitempty"`
+	Subregion            string                  `json:"subregion,omitempty"`
+	IncomeGroup          string                  `json:"income_group,omitempty"`
+	Groups               []string                `json:"groups,omitempty"`
+	Population           contextMetric           `json:"population"`
+	GDP                  contextMetric           `json:"gdp"`
+	USA                  partnerBlock            `json:"usa"`
+	CHN                  partnerBlock            `json:"chn"`
+	Total                float64                 `json:"total"`
+	ShareCN              float64                 `json:"share_cn"`
+	SamePeriod           bool                    `json:"same_period"`
+	ComparisonPeriod     string                  `json:"comparison_period,omitempty"`
+	ComparisonPeriodType string                  `json:"comparison_period_type,omitempty"`
+	Partners             map[string]partnerBlock `json:"partners"`
+	TrackedTotal         float64                 `json:"tracked_total"`
+	Shares               map[string]float64      `json:"shares"`
+	ShareCNTTM           *float64                `json:"share_cn_ttm,omitempty"`
+	Incomparable         bool                    `json:"incomparable,omitempty"`
+	Currencies           map[string]currencyRow  `json:"currencies,omitempty"`
+	Stale                bool                    `json:"stale,omitempty"`
+	Concentration        *concentration          `json:"concentration,omitempty"`
+	Percentiles          *rowPercentiles         `json:"percentiles,omitempty"`
+}
+
+type rowPercentiles struct {
+	Region      *metricPercentiles `json:"region,omitempty"`
+	IncomeGroup *metricPercentiles `json:"income_group,omitempty"`
+}
+
+type metricPercentiles struct {
+	ShareCN *peerPercentile `json:"share_cn,omitempty"`
+	Growth  *peerPercentile `json:"growth,omitempty"`
+	Tilt    *peerPercentile `json:"tilt,omitempty"`
+}
+
+type peerPercentile struct {
+	Percentile float64 `json:"percentile"`
+	Peers      int     `json:"peers"`
+}
+
+type concentration struct {
+	HHI               float64 `json:"hhi"`
+	EffectivePartners float64 `json:"effective_partners"`
+	PartnerCount      int     `json:"partner_count"`
+	TopPartner        string  `json:"top_partner"`
+	TopShare          float64 `json:"top_share"`
+}
+
+type currencyRow struct {
+	USA      *convertedBlock            `json:"usa,omitempty"`
+	CHN      *convertedBlock            `json:"chn,omitempty"`
+	Total    *float64                   `json:"total,omitempty"`
+	Partners map[string]*convertedBlock `json:"partners,omitempty"`
+}
+
+type convertedBlock struct {
+	Rate       float64 `json:"rate"`
+	RatePeriod string  `json:"rate_period"`
+	Export     float64 `json:"export"`
+	Import     float64 `json:"import"`
+	Trade      float64 `json:"trade"`
 }
 
 type contextMetric struct {
@@ -106,14 +185,28 @@ type contextMetric struct {
 }
 
 type partnerBlock struct {
-	Period      string       `json:"period"`
-	PeriodType  string       `json:"period_type"`
-	PrevPeriod  string       `json:"prev_period,omitempty"`
-	Export      float64      `json:"export"`
-	Import      float64      `json:"import"`
-	Trade       float64      `json:"trade"`
-	Growth      *growthBlock `json:"growth,omitempty"`
-	GrowthBasis string       `json:"growth_basis,omitempty"`
+	Period       string         `json:"period"`
+	PeriodType   string         `json:"period_type"`
+	PrevPeriod   string         `json:"prev_period,omitempty"`
+	Export       float64        `json:"export"`
+	Import       float64        `json:"import"`
+	Trade        float64        `json:"trade"`
+	Growth       *growthBlock   `json:"growth,omitempty"`
+	GrowthBasis  string         `json:"growth_basis,omitempty"`
+	TTM          *trailingBlock `json:"ttm,omitempty"`
+	ShareOfTotal *float64       `json:"share_of_total,omitempty"`
+	// Per-capita and GDP-share values are set only on annual blocks.
+	TradePerCapita  *float64 `json:"trade_per_capita,omitempty"`
+	TradeShareOfGDP *float64 `json:"trade_share_of_gdp,omitempty"`
+	Estimated       bool     `json:"estimated,omitempty"`
+	QualityNote     string   `json:"quality_note,omitempty"`
+}
+
+type trailingBlock struct {
+	Through string  `json:"through"`
+	Export  float64 `json:"export"`
+	Import  float64 `json:"import"`
+	Trade   float64 `json:"trade"`
 }
 
 type growthBlock struct {
@@ -125,7 +218,12 @@ type growthBlock struct {
 func main() {
 	dataDir := flag.String("dir", "site/data", "directory containing meta.json and latest.json")
 	minReporters := flag.Int("min-reporters", 1, "minimum expected number of reporter rows")
+	showVersion := flag.Bool("version", false, "print the build version and exit")
 	flag.Parse()
+	if *showVersion {
+		buildinfo.Print(os.Stdout, "validator")
+		return
+	}
 
 	metadata, latest, err := loadDataset(*dataDir)
 	if err != nil {
@@ -174,8 +272,8 @@ func readJSON(path string, value any) error {
 	defer file.Close()
 
 	decoder := json.NewDecoder(file)
-	decoder.DisallowUnknownFields()
-	if err := decoder.Decode(value); err != nil {
+	var body json.RawMessage
+	if err := decoder.Decode(&body); err != nil {
 		return err
 	}
 	var trailing any
@@ -185,6 +283,50 @@ func readJSON(path string, value any) error {
 		}
 		return fmt.Errorf("unexpected trailing JSON content: %w", err)
 	}
+	// Every artifact may credit its providers; no artifact type declares it.
+	var members map[string]json.RawMessage
+	if json.Unmarshal(body, &members) == nil && members["attribution"] != nil {
+		if err := validateAttribution(members["attribution"]); err != nil {
+			return err
+		}
+		delete(members, "attribution")
+		var err error
+		if body, err = json.Marshal(members); err != nil {
+			return err
+		}
+	}
+	strict := json.NewDecoder(bytes.NewReader(body))
+	strict.DisallowUnknownFields()
+	return strict.Decode(value)
+}
+
+// validateAttribution checks the provider credits of an artifact: each
+// names its provider, the source, and the credit text, once per provider.
+func validateAttribution(raw json.RawMessage) error {
+	var credits []struct {
+		Provider string `json:"provider"`
+		Name     string `json:"name"`
+		Text     string `json:"text"`
+		Terms    string `json:"terms"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&credits); err != nil {
+		return fmt.Errorf("attribution: %w", err)
+	}
+	if len(credits) == 0 {
+		return errors.New("attribution is empty")
+	}
+	seen := map[string]bool{}
+	for _, credit := range credits {
+		if strings.TrimSpace(credit.Provider) == "" || strings.TrimSpace(credit.Name) == "" || strings.TrimSpace(credit.Text) == "" {
+			return fmt.Errorf("attribution for %q needs a provider, a name, and a text", credit.Provider)
+		}
+		if seen[credit.Provider] {
+			return fmt.Errorf("attribution lists %s twice", credit.Provider)
+		}
+		seen[credit.Provider] = true
+	}
 	return nil
 }
 
@@ -210,6 +352,22 @@ func validateDataset(metadata datasetMeta, latest datasetLatest, minReporters in
 	if !containsAll(metadata.Partners, "USA", "CHN") {
 		return fmt.Errorf("partners must include USA and CHN: %v", metadata.Partners)
 	}
+	switch metadata.ShareAlignment {
+	case "", "latest", "common", "period-type":
+	default:
+		return fmt.Errorf("unsupported share_alignment %q", metadata.ShareAlignment)
+	}
+	switch metadata.MixedPeriods {
+	case "", "allow", "downgrade", "incomparable":
+	default:
+		return fmt.Errorf("unsupported mixed_periods %q", metadata.MixedPeriods)
+	}
+	if metadata.ProviderMerge != "" && metadata.Provider != "merged" {
+		return fmt.Errorf("provider_merge %q needs provider merged, got %q", metadata.ProviderMerge, metadata.Provider)
+	}
+	if err := validateStaleReporters(metadata, latest); err != nil {
+		return err
+	}
 	if len(latest.Rows) < minReporters {
 		return fmt.Errorf("reporter count %d is below minimum %d", len(latest.Rows), minReporters)
 	}
@@ -242,10 +400,19 @@ func validateDataset(metadata datasetMeta, latest datasetLatest, minReporters in
 			return err
 		}
 
-		for label, block := range map[string]partnerBlock{"USA": row.USA, "CHN": row.CHN} {
+		blocks := row.Partners
+		if blocks == nil {
+			blocks = map[string]partnerBlock{"USA": row.USA, "CHN": row.CHN}
+		} else if err := validateTrackedPartners(row, latest.Partners); err != nil {
+			return err
+		}
+		for label, block := range blocks {
 			if err := validateBlock(row.ISO3, label, block); err != nil {
 				return err
 			}
+			if err := validateNormalization(row, label, block); err != nil {
+				return err
+			}
 			if block.Period != "" {
 				availableBlocks++
 				periodCounts[block.PeriodType+":"+block.Period]++
@@ -262,14 +429,32 @@ func validateDataset(metadata datasetMeta, latest datasetLatest, minReporters in
 			if samePeriod && row.ComparisonPeriod != row.USA.Period {
 				return fmt.Errorf("%s comparison_period=%q, want %q", row.ISO3, row.ComparisonPeriod, row.USA.Period)
 			}
-			if !samePeriod && row.ComparisonPeriod != "" {
+			if samePeriod && row.ComparisonPeriodType != "" && row.ComparisonPeriodType != row.USA.PeriodType {
+				return fmt.Errorf("%s comparison_period_type=%q, want %q", row.ISO3, row.ComparisonPeriodType, row.USA.PeriodType)
+			}
+			if !samePeriod && (row.ComparisonPeriod != "" || row.ComparisonPeriodType != "") {
 				return fmt.Errorf("%s has comparison_period without comparable partners", row.ISO3)
 			}
 		}
 
+		if err := validateCurrencies(row, metadata.Currencies); err != nil {
+			return err
+		}
 		if err := finiteNonNegative("total", row.ISO3, row.Total); err != nil {
 			return err
 		}
+		if row.Incomparable {
+			if metadata.MixedPeriods != "incomparable" || row.USA.Period == "" || row.CHN.Period == "" || row.USA.PeriodType == row.CHN.PeriodType {
+				return fmt.Errorf("%s is marked incomparable without mixed period types", row.ISO3)
+			}
+			if row.Total != 0 || row.ShareCN != 0 || row.ShareCNTTM != nil {
+				return fmt.Errorf("%s is incomparable but has a total or share_cn", row.ISO3)
+			}
+			continue
+		}
+		if metadata.MixedPeriods == "incomparable" && row.USA.Period != "" && row.CHN.Period != "" && row.USA.PeriodType != row.CHN.PeriodType {
+			return fmt.Errorf("%s mixes %s and %s periods without being marked incomparable", row.ISO3, row.USA.PeriodType, row.CHN.PeriodType)
+		}
 		if !approximatelyEqual(row.Total, row.USA.Trade+row.CHN.Trade) {
 			return fmt.Errorf("%s total %v does not equal USA+CHN trade %v", row.ISO3, row.Total, row.USA.Trade+row.CHN.Trade)
 		}
@@ -283,6 +468,18 @@ func validateDataset(metadata datasetMeta, latest datasetLatest, minReporters in
 		if !approximatelyEqual(row.ShareCN, wantShare) {
 			return fmt.Errorf("%s share_cn %v does not equal calculated value %v", row.ISO3, row.ShareCN, wantShare)
 		}
+		if row.ShareCNTTM != nil {
+			if row.USA.TTM == nil || row.CHN.TTM == nil || row.USA.TTM.Through != row.CHN.TTM.Through {
+				return fmt.Errorf("%s share_cn_ttm needs USA and CHN trailing totals through the same month", row.ISO3)
+			}
+			if total := row.USA.TTM.Trade + row.CHN.TTM.Trade; total <= 0 || !approximatelyEqual(*row.ShareCNTTM, row.CHN.TTM.Trade/total) {
+				return fmt.Errorf("%s share_cn_ttm %v does not match trailing totals", row.ISO3, *row.ShareCNTTM)
+			}
+		}
+	}
+
+	if err := validatePercentiles(latest.Rows); err != nil {
+		return err
 	}
 
 	expectedBlocks := len(latest.Rows) * len(latest.Partners)
@@ -353,6 +550,166 @@ func dominantPeriod(counts map[string]int) string {
 	return best
 }
 
+// validateTrackedPartners checks the partner-keyed blocks against the
+// published partner list, the USA and CHN blocks, and the tracked shares.
+func validateTrackedPartners(row datasetRow, partners []string) error {
+	tracked := make(map[string]struct{}, len(partners))
+	for _, partner := range partners {
+		tracked[partner] = struct{}{}
+	}
+	total := 0.0
+	for partner, block := range row.Partners {
+		if _, ok := tracked[partner]; !ok {
+			return fmt.Errorf("%s has untracked partner block %q", row.ISO3, partner)
+		}
+		total += block.Trade
+	}
+	for partner, block := range map[string]partnerBlock{"USA": row.USA, "CHN": row.CHN} {
+		if mapped, ok := row.Partners[partner]; ok && !reflect.DeepEqual(mapped, block) {
+			return fmt.Errorf("%s partners[%s] does not match the %s block", row.ISO3, partner, strings.ToLower(partner))
+		}
+	}
+	if !approximatelyEqual(row.TrackedTotal, total) {
+		return fmt.Errorf("%s tracked_total %v does not equal partner trade %v", row.ISO3, row.TrackedTotal, total)
+	}
+	if len(row.Shares) != len(row.Partners) {
+		return fmt.Errorf("%s has %d shares for %d partner blocks", row.ISO3, len(row.Shares), len(row.Partners))
+	}
+	for partner, share := range row.Shares {
+		block, ok := row.Partners[partner]
+		if !ok {
+			return fmt.Errorf("%s has a share for missing partner block %q", row.ISO3, partner)
+		}
+		want := 0.0
+		if total > 0 {
+			want = block.Trade / total
+		}
+		if !isFinite(share) || !approximatelyEqual(share, want) {
+			return fmt.Errorf("%s %s share %v does not equal calculated value %v", row.ISO3, partner, share, want)
+		}
+	}
+	trade := make(map[string]float64, len(row.Partners))
+	samePeriod := true
+	for partner, block := range row.Partners {
+		trade[partner] = block.Trade
+		if block.PeriodType != row.USA.PeriodType || block.Period != row.USA.Period {
+			samePeriod = false
+		}
+	}
+	if !samePeriod {
+		if row.Concentration != nil {
+			return fmt.Errorf("%s has a concentration across partner blocks on different periods", row.ISO3)
+		}
+		return nil
+	}
+	return validateConcentration(row.ISO3, row.Concentration, trade)
+}
+
+// validatePercentiles checks the peer ranks of each row against the other
+// rows of its region and income group. share_cn and tilt ranks are
+// recomputed from comparable rows; growth ranks must be a whole number of
+// peers out of a group that is large enough.
+func validatePercentiles(rows []datasetRow) error {
+	groupings := []struct {
+		name  string
+		key   func(datasetRow) string
+		ranks func(*rowPercentiles) *metricPercentiles
+	}{
+		{name: "region", key: func(row datasetRow) string { return row.Region }, ranks: func(p *rowPercentiles) *metricPercentiles { return p.Region }},
+		{name: "income_group", key: func(row datasetRow) string { return row.IncomeGroup }, ranks: func(p *rowPercentiles) *metricPercentiles { return p.IncomeGroup }},
+	}
+	comparable := func(row datasetRow) bool { return !row.Incomparable && row.Total > 0 }
+	for _, grouping := range groupings {
+		members := make(map[string][]datasetRow)
+		for _, row := range rows {
+			if key := grouping.key(row); key != "" {
+				members[key] = append(members[key], row)
+			}
+		}
+		for _, row := range rows {
+			if row.Percentiles == nil || grouping.ranks(row.Percentiles) == nil {
+				continue
+			}
+			group := members[grouping.key(row)]
+			ranks := grouping.ranks(row.Percentiles)
+			if ranks.ShareCN == nil && ranks.Growth == nil && ranks.Tilt == nil {
+				return fmt.Errorf("%s has empty %s percentiles", row.ISO3, grouping.name)
+			}
+			metrics := []struct {
+				name  string
+				rank  *peerPercentile
+				value func(datasetRow) float64
+			}{
+				{name: "share_cn", rank: ranks.ShareCN, value: func(row datasetRow) float64 { return row.ShareCN }},
+				{name: "growth", rank: ranks.Growth},
+				{name: "tilt", rank: ranks.Tilt, value: func(row datasetRow) float64 { return (row.CHN.Trade - row.USA.Trade) / row.Total }},
+			}
+			for _, metric := range metrics {
+				rank := metric.rank
+				if rank == nil {
+					continue
+				}
+				lower := rank.Percentile * float64(rank.Peers)
+				if rank.Peers < 2 || rank.Peers >= len(group) || rank.Percentile < 0 || rank.Percentile > 1 || !approximatelyEqual(lower, math.Round(lower)) {
+					return fmt.Errorf("%s %s %s percentile %v of %d peers is invalid", row.ISO3, grouping.name, metric.name, rank.Percentile, rank.Peers)
+				}
+				if metric.value == nil {
+					continue
+				}
+				if !comparable(row) {
+					return fmt.Errorf("%s has a %s %s percentile without comparable partners", row.ISO3, grouping.name, metric.name)
+				}
+				peers, below := 0, 0
+				for _, other := range group {
+					if other.ISO3 == row.ISO3 || !comparable(other) {
+						continue
+					}
+					peers++
+					if metric.value(other) < metric.value(row) {
+						below++
+					}
+				}
+				if peers != rank.Peers || !approximatelyEqual(rank.Percentile, float64(below)/float64(peers)) {
+					return fmt.Errorf("%s %s %s percentile %v of %d peers, want %v of %d", row.ISO3, grouping.name, metric.name, rank.Percentile, rank.Peers, float64(below)/float64(max(peers, 1)), peers)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateConcentration checks an HHI against the partner trade it was
+// computed from. Datasets published before the index omit it.
+func validateConcentration(label string, value *concentration, trade map[string]float64) error {
+	if value == nil {
+		return nil
+	}
+	total := 0.0
+	count := 0
+	for _, amount := range trade {
+		if amount > 0 {
+			total += amount
+			count++
+		}
+	}
+	if total <= 0 {
+		return fmt.Errorf("%s has a concentration without partner trade", label)
+	}
+	hhi, topShare := 0.0, 0.0
+	for _, amount := range trade {
+		share := amount / total
+		hhi += share * share
+		topShare = max(topShare, share)
+	}
+	if value.PartnerCount != count || !approximatelyEqual(value.HHI, hhi) || !approximatelyEqual(value.EffectivePartners, 1/hhi) || !approximatelyEqual(value.TopShare, topShare) {
+		return fmt.Errorf("%s concentration %+v does not equal calculated HHI %v over %d partners", label, *value, hhi, count)
+	}
+	if amount, ok := trade[value.TopPartner]; !ok || !approximatelyEqual(amount/total, topShare) {
+		return fmt.Errorf("%s concentration top partner %q is not the largest partner", label, value.TopPartner)
+	}
+	return nil
+}
+
 func validateBlock(reporter, partner string, block partnerBlock) error {
 	for label, value := range map[string]float64{"export": block.Export, "import": block.Import, "trade": block.Trade} {
 		if err := finiteNonNegative(partner+" "+label, reporter, value); err != nil {
@@ -362,16 +719,31 @@ func validateBlock(reporter, partner string, block partnerBlock) error {
 	if !approximatelyEqual(block.Trade, block.Export+block.Import) {
 		return fmt.Errorf("%s %s trade %v does not equal export+import %v", reporter, partner, block.Trade, block.Export+block.Import)
 	}
+	if block.QualityNote != "" && !block.Estimated {
+		return fmt.Errorf("%s %s has a quality note without being estimated", reporter, partner)
+	}
 	if block.Growth != nil {
 		for label, value := range map[string]*float64{"export": block.Growth.Export, "import": block.Growth.Import, "trade": block.Growth.Trade} {
 			if value != nil && !isFinite(*value) {
 				return fmt.Errorf("%s %s growth %s must be finite, got %v", reporter, partner, label, *value)
 			}
 		}
-		if strings.ToLower(block.GrowthBasis) != "yoy" {
+		switch strings.ToLower(block.GrowthBasis) {
+		case "yoy", "mom", "qoq", "ytd":
+		default:
 			return fmt.Errorf("%s %s has unsupported growth basis %q", reporter, partner, block.GrowthBasis)
 		}
 	}
+	if block.ShareOfTotal != nil {
+		if block.Period == "" || !isFinite(*block.ShareOfTotal) || *block.ShareOfTotal < 0 {
+			return fmt.Errorf("%s %s share_of_total %v is invalid", reporter, partner, *block.ShareOfTotal)
+		}
+	}
+	if block.TTM != nil {
+		if err := validateTrailing(reporter, partner, block.PeriodType, block.Period, *block.TTM); err != nil {
+			return err
+		}
+	}
 	if block.Period == "" {
 		if block.PeriodType != "" {
 			return fmt.Errorf("%s %s has period type %q without a period", reporter, partner, block.PeriodType)
@@ -388,6 +760,27 @@ func validateBlock(reporter, partner string, block partnerBlock) error {
 	return nil
 }
 
+// validateTrailing checks a trailing twelve-month block. It must end at the
+// monthly period it is attached to, and its trade must be export plus import.
+func validateTrailing(reporter, partner, periodType, period string, block trailingBlock) error {
+	if periodType != "M" || block.Through != period {
+		return fmt.Errorf("%s %s trailing totals through %q do not match monthly period %q/%q", reporter, partner, block.Through, periodType, period)
+	}
+	for label, value := range map[string]float64{"export": block.Export, "import": block.Import, "trade": block.Trade} {
+		if err := finiteNonNegative(partner+" ttm "+label, reporter, value); err != nil {
+			return err
+		}
+	}
+	if !approximatelyEqual(block.Trade, block.Export+block.Import) {
+		return fmt.Errorf("%s %s ttm trade %v does not equal export+import %v", reporter, partner, block.Trade, block.Export+block.Import)
+	}
+	return nil
+}
+
+func validFlow(flow string) bool {
+	return flow == "export" || flow == "import" || flow == "total"
+}
+
 func validPeriod(periodType, period string) bool {
 	switch periodType {
 	case "Y":
@@ -417,6 +810,106 @@ func approximatelyEqual(a, b float64) bool {
 	return math.Abs(a-b) <= scale*1e-9
 }
 
+// validateNormalization checks per-capita and GDP-share values against the
+// row's population and GDP.
+func validateNormalization(row datasetRow, partner string, block partnerBlock) error {
+	for _, check := range []struct {
+		label       string
+		value       *float64
+		denominator contextMetric
+	}{
+		{"trade_per_capita", block.TradePerCapita, row.Population},
+		{"trade_share_of_gdp", block.TradeShareOfGDP, row.GDP},
+	} {
+		if check.value == nil {
+			continue
+		}
+		if block.PeriodType != "Y" {
+			return fmt.Errorf("%s %s has %s on a non-annual block", row.ISO3, partner, check.label)
+		}
+		if check.denominator.Value == nil || *check.denominator.Value <= 0 || !approximatelyEqual(*check.value, block.Trade / *check.denominator.Value) {
+			return fmt.Errorf("%s %s %s %v does not match trade and its denominator", row.ISO3, partner, check.label, *check.value)
+		}
+	}
+	return nil
+}
+
+// validateCurrencies checks that each converted block is its USD block times
+// the recorded rate, for a currency meta.json lists.
+func validateCurrencies(row datasetRow, currencies []string) error {
+	for currency, values := range row.Currencies {
+		if currency == "USD" || !slices.Contains(currencies, currency) {
+			return fmt.Errorf("%s has values in unlisted currency %q", row.ISO3, currency)
+		}
+		pairs := map[string]struct {
+			converted *convertedBlock
+			usd       partnerBlock
+		}{"usa": {values.USA, row.USA}, "chn": {values.CHN, row.CHN}}
+		for partner, converted := range values.Partners {
+			usd, ok := row.Partners[partner]
+			if !ok {
+				return fmt.Errorf("%s %s converts partner %s, which the row does not have", row.ISO3, currency, partner)
+			}
+			pairs["partners."+partner] = struct {
+				converted *convertedBlock
+				usd       partnerBlock
+			}{converted, usd}
+		}
+		for label, pair := range pairs {
+			if pair.converted == nil {
+				continue
+			}
+			if !isFinite(pair.converted.Rate) || pair.converted.Rate <= 0 {
+				return fmt.Errorf("%s %s %s rate %v must be positive", row.ISO3, currency, label, pair.converted.Rate)
+			}
+			if period := pair.converted.RatePeriod; !yearPattern.MatchString(period) && !quarterPattern.MatchString(period) && !monthPattern.MatchString(period) {
+				return fmt.Errorf("%s %s %s has invalid rate_period %q", row.ISO3, currency, label, pair.converted.RatePeriod)
+			}
+			if !approximatelyEqual(pair.converted.Trade, pair.usd.Trade*pair.converted.Rate) {
+				return fmt.Errorf("%s %s %s trade %v does not equal USD trade times rate", row.ISO3, currency, label, pair.converted.Trade)
+			}
+		}
+		if values.Total != nil && (values.USA == nil || values.CHN == nil || !approximatelyEqual(*values.Total, values.USA.Trade+values.CHN.Trade)) {
+			return fmt.Errorf("%s %s total does not equal converted USA+CHN trade", row.ISO3, currency)
+		}
+	}
+	return nil
+}
+
+// validateStaleReporters checks that flagged rows match meta.stale_reporters
+// and that excluded reporters are absent from latest.json.
+func validateStaleReporters(metadata datasetMeta, latest datasetLatest) error {
+	listed := make(map[string]bool, len(metadata.StaleReporters))
+	for _, iso3 := range metadata.StaleReporters {
+		listed[iso3] = true
+	}
+	switch metadata.StalePolicy {
+	case "":
+		if len(listed) > 0 {
+			return fmt.Errorf("stale_reporters without stale_policy: %v", metadata.StaleReporters)
+		}
+	case "flag", "exclude":
+	default:
+		return fmt.Errorf("unsupported stale_policy %q", metadata.StalePolicy)
+	}
+	flagged := 0
+	for _, row := range latest.Rows {
+		if row.Stale {
+			flagged++
+		}
+		if metadata.StalePolicy == "exclude" && listed[row.ISO3] {
+			return fmt.Errorf("stale reporter %s was not excluded", row.ISO3)
+		}
+		if metadata.StalePolicy == "flag" && row.Stale != listed[row.ISO3] {
+			return fmt.Errorf("stale flag mismatch for %s: row=%t meta=%t", row.ISO3, row.Stale, listed[row.ISO3])
+		}
+	}
+	if metadata.StalePolicy != "flag" && flagged > 0 {
+		return fmt.Errorf("%d rows flagged stale without the flag stale_policy", flagged)
+	}
+	return nil
+}
+
 func containsAll(values []string, required ...string) bool {
 	set := make(map[string]struct{}, len(values))
 	for _, value := range values {
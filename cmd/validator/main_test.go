@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -22,6 +24,30 @@ func TestLoadDatasetReadsValidFixture(t *testing.T) {
 	}
 }
 
+func TestReadJSONSetsAsideValidAttribution(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tilt.json")
+	var value struct {
+		GeneratedAt string `json:"generated_at"`
+	}
+	for body, wantErr := range map[string]string{
+		`{"generated_at": "x", "attribution": [{"provider": "wits", "name": "WITS", "text": "Trade data: WITS."}]}`: "",
+		`{"generated_at": "x", "attribution": []}`:                                     "attribution is empty",
+		`{"generated_at": "x", "attribution": [{"provider": "wits", "name": "WITS"}]}`: "needs a provider, a name, and a text",
+		`{"generated_at": "x", "extra": true}`:                                         "unknown field",
+	} {
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		err := readJSON(path, &value)
+		if wantErr == "" && (err != nil || value.GeneratedAt != "x") {
+			t.Fatalf("readJSON(%s) = %v", body, err)
+		}
+		if wantErr != "" && (err == nil || !strings.Contains(err.Error(), wantErr)) {
+			t.Fatalf("readJSON(%s) = %v, want %q", body, err, wantErr)
+		}
+	}
+}
+
 func TestValidateDatasetRejectsUnsafeOrInconsistentData(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -67,6 +93,106 @@ func TestValidateDatasetRejectsUnsafeOrInconsistentData(t *testing.T) {
 			},
 			message: "coverage mismatch",
 		},
+		{
+			name: "tracked share mismatch",
+			mutate: func(_ *datasetMeta, latest *datasetLatest) {
+				row := &latest.Rows[0]
+				row.Partners = map[string]partnerBlock{"USA": row.USA, "CHN": row.CHN}
+				row.TrackedTotal = 200
+				row.Shares = map[string]float64{"USA": 0.5, "CHN": 0.4}
+			},
+			message: "CHN share",
+		},
+		{
+			name: "untracked partner block",
+			mutate: func(_ *datasetMeta, latest *datasetLatest) {
+				row := &latest.Rows[0]
+				row.Partners = map[string]partnerBlock{"USA": row.USA, "CHN": row.CHN, "JPN": row.USA}
+			},
+			message: "untracked partner",
+		},
+		{
+			name: "negative share of total",
+			mutate: func(_ *datasetMeta, latest *datasetLatest) {
+				share := -0.1
+				latest.Rows[0].USA.ShareOfTotal = &share
+			},
+			message: "share_of_total",
+		},
+		{
+			name: "incomparable without mixed periods",
+			mutate: func(meta *datasetMeta, latest *datasetLatest) {
+				meta.MixedPeriods = "incomparable"
+				latest.Rows[0].Incomparable = true
+			},
+			message: "without mixed period types",
+		},
+		{
+			name: "provider merge without merged provider",
+			mutate: func(meta *datasetMeta, _ *datasetLatest) {
+				meta.ProviderMerge = "M=comtrade,Y=wits"
+			},
+			message: "needs provider merged",
+		},
+		{
+			name: "per capita without population",
+			mutate: func(_ *datasetMeta, latest *datasetLatest) {
+				value := 1.0
+				latest.Rows[0].USA.TradePerCapita = &value
+			},
+			message: "trade_per_capita",
+		},
+		{
+			name: "unlisted currency",
+			mutate: func(_ *datasetMeta, latest *datasetLatest) {
+				latest.Rows[0].Currencies = map[string]currencyRow{"KRW": {}}
+			},
+			message: "unlisted currency",
+		},
+		{
+			name: "converted partner the row does not have",
+			mutate: func(meta *datasetMeta, latest *datasetLatest) {
+				meta.Currencies = []string{"USD", "KRW"}
+				latest.Rows[0].Currencies = map[string]currencyRow{"KRW": {Partners: map[string]*convertedBlock{"JPN": {Rate: 1300, RatePeriod: "2023"}}}}
+			},
+			message: "which the row does not have",
+		},
+		{
+			name: "stale flag without meta",
+			mutate: func(meta *datasetMeta, latest *datasetLatest) {
+				meta.StalePolicy = "flag"
+				latest.Rows[0].Stale = true
+			},
+			message: "stale flag mismatch",
+		},
+		{
+			name: "stale reporter not excluded",
+			mutate: func(meta *datasetMeta, latest *datasetLatest) {
+				meta.StalePolicy = "exclude"
+				meta.StaleReporters = []string{latest.Rows[0].ISO3}
+			},
+			message: "was not excluded",
+		},
+		{
+			name: "concentration mismatch",
+			mutate: func(_ *datasetMeta, latest *datasetLatest) {
+				row := &latest.Rows[0]
+				row.Partners = map[string]partnerBlock{"USA": row.USA, "CHN": row.CHN}
+				row.TrackedTotal = 200
+				row.Shares = map[string]float64{"USA": 0.5, "CHN": 0.5}
+				row.Concentration = &concentration{HHI: 1, EffectivePartners: 1, PartnerCount: 2, TopPartner: "USA", TopShare: 0.5}
+			},
+			message: "does not equal calculated HHI",
+		},
+		{
+			name: "percentile with more peers than the region has",
+			mutate: func(_ *datasetMeta, latest *datasetLatest) {
+				row := &latest.Rows[0]
+				row.Region = "East Asia"
+				row.Percentiles = &rowPercentiles{Region: &metricPercentiles{ShareCN: &peerPercentile{Percentile: 0.5, Peers: 2}}}
+			},
+			message: "percentile 0.5 of 2 peers is invalid",
+		},
 	}
 
 	for _, tt := range tests {
@@ -81,6 +207,24 @@ func TestValidateDatasetRejectsUnsafeOrInconsistentData(t *testing.T) {
 	}
 }
 
+func TestValidateDatasetAcceptsExtraTrackedPartners(t *testing.T) {
+	metadata, latest := validDataset()
+	jpn := partnerBlock{Period: "2023", PeriodType: "Y", Export: 50, Import: 50, Trade: 100}
+	latest.Partners = []string{"USA", "CHN", "JPN"}
+	metadata.Partners = latest.Partners
+	row := &latest.Rows[0]
+	row.Partners = map[string]partnerBlock{"USA": row.USA, "CHN": row.CHN, "JPN": jpn}
+	row.TrackedTotal = 300
+	row.Shares = map[string]float64{"USA": 1.0 / 3, "CHN": 1.0 / 3, "JPN": 1.0 / 3}
+	row.Concentration = &concentration{HHI: 1.0 / 3, EffectivePartners: 3, PartnerCount: 3, TopPartner: "CHN", TopShare: 1.0 / 3}
+	metadata.ExpectedPartnerBlocks = 3
+	metadata.AvailablePartnerBlocks = 3
+	metadata.PeriodCounts["Y:2023"] = 3
+	if err := validateDataset(metadata, latest, 1); err != nil {
+		t.Fatalf("validateDataset() error = %v", err)
+	}
+}
+
 func validDataset() (datasetMeta, datasetLatest) {
 	usa := partnerBlock{Period: "2023", PeriodType: "Y", Export: 40, Import: 60, Trade: 100}
 	chn := partnerBlock{Period: "2023", PeriodType: "Y", Export: 20, Import: 80, Trade: 100}
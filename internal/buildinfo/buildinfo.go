@@ -0,0 +1,95 @@
+// Package buildinfo reports the version, commit, and build date of the
+// running binary, so published data can be traced to the code that made it.
+// Release builds set them with -ldflags:
+//
+//	go build -ldflags "-X tradegravity/internal/buildinfo.version=v1.4.0
+//	  -X tradegravity/internal/buildinfo.commit=$(git rev-parse HEAD)
+//	  -X tradegravity/internal/buildinfo.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/...
+//
+// Without them, the module version and the VCS stamp the Go toolchain embeds
+// are used.
+package buildinfo
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+)
+
+// Set by -ldflags -X.
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// Info identifies one build. CommitTime is the commit's time from the VCS
+// stamp and Date the build date set at link time.
+type Info struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	CommitTime string `json:"commit_time,omitempty"`
+	Modified   bool   `json:"modified,omitempty"`
+	Date       string `json:"date,omitempty"`
+	GoVersion  string `json:"-"`
+}
+
+// Get returns the build of the running binary. Version is "(devel)" when
+// neither -ldflags nor the module records one.
+func Get() Info {
+	info := Info{Version: "(devel)"}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = build.GoVersion
+		if build.Main.Version != "" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				info.CommitTime = setting.Value
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return apply(info, version, commit, date)
+}
+
+// apply overrides info with the values set at link time.
+func apply(info Info, version, commit, date string) Info {
+	if version = strings.TrimSpace(version); version != "" {
+		info.Version = version
+	}
+	if commit = strings.TrimSpace(commit); commit != "" {
+		info.Commit, info.CommitTime, info.Modified = commit, "", false
+	}
+	if date = strings.TrimSpace(date); date != "" {
+		info.Date = date
+	}
+	return info
+}
+
+func (i Info) String() string {
+	text := i.Version
+	if i.Commit != "" {
+		text += " commit " + i.Commit
+		if i.Modified {
+			text += " (modified)"
+		}
+	}
+	if i.Date != "" {
+		text += " built " + i.Date
+	}
+	if i.GoVersion != "" {
+		text += " " + i.GoVersion
+	}
+	return text
+}
+
+// Print writes the -version line for program.
+func Print(w io.Writer, program string) {
+	fmt.Fprintf(w, "%s %s\n", program, Get())
+}
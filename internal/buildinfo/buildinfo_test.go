@@ -0,0 +1,14 @@
+package buildinfo
+
+import "testing"
+
+func TestApplyPrefersLinkTimeValues(t *testing.T) {
+	stamped := Info{Version: "(devel)", Commit: "abc123", CommitTime: "2025-12-31T00:00:00Z", Modified: true, GoVersion: "go1.25.0"}
+	if got := apply(stamped, "", "", "").String(); got != "(devel) commit abc123 (modified) go1.25.0" {
+		t.Fatalf("String() without ldflags = %q", got)
+	}
+	info := apply(stamped, "v1.4.0", "def456", "2026-02-03T04:05:06Z")
+	if info.Version != "v1.4.0" || info.Commit != "def456" || info.CommitTime != "" || info.Modified || info.Date != "2026-02-03T04:05:06Z" {
+		t.Fatalf("apply() = %+v", info)
+	}
+}
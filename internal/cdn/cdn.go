@@ -0,0 +1,235 @@
+// Package cdn purges published paths from a CDN cache: CloudFront through
+// its invalidation API, signed with AWS Signature Version 4, and Cloudflare
+// through its purge API with an API token.
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"tradegravity/internal/awssig"
+	"tradegravity/internal/secrets"
+)
+
+const (
+	defaultCloudFrontEndpoint = "https://cloudfront.amazonaws.com"
+	defaultCloudflareEndpoint = "https://api.cloudflare.com/client/v4"
+	defaultTimeoutSeconds     = 60
+	// maxCloudFrontPaths is where an invalidation switches to one wildcard
+	// for the whole prefix. CloudFront bills paths past the first thousand a
+	// month, and a wildcard counts as one.
+	maxCloudFrontPaths = 1000
+	// cloudflareBatch is the most URLs one Cloudflare purge request accepts
+	// on every plan.
+	cloudflareBatch = 30
+)
+
+// Target is a parsed cloudfront:DISTRIBUTION_ID or cloudflare:ZONE_ID.
+type Target struct {
+	Provider string
+	ID       string
+}
+
+// ParseTarget accepts cloudfront:<distribution ID> and cloudflare:<zone ID>.
+func ParseTarget(value string) (Target, error) {
+	provider, id, ok := strings.Cut(strings.TrimSpace(value), ":")
+	provider = strings.ToLower(provider)
+	if !ok || (provider != "cloudfront" && provider != "cloudflare") {
+		return Target{}, fmt.Errorf("unsupported cdn %q (expected cloudfront:<distribution> or cloudflare:<zone>)", value)
+	}
+	if id == "" || strings.ContainsAny(id, "/?# ") {
+		return Target{}, fmt.Errorf("cdn %q has no valid %s id", value, provider)
+	}
+	return Target{Provider: provider, ID: id}, nil
+}
+
+func (t Target) String() string {
+	return t.Provider + ":" + t.ID
+}
+
+// Purger drops cached copies of published files. paths are relative to the
+// base the purger was created with, such as meta.json or countries/KOR.json.
+// Purge returns how many paths or URLs it sent.
+type Purger interface {
+	Purge(ctx context.Context, paths []string) (int, error)
+}
+
+// FromEnv returns the purger for target. base is where the files are
+// served: the path prefix on the distribution for CloudFront, which may be
+// a full URL, and the full URL for Cloudflare, which purges by URL.
+// CloudFront uses AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and
+// AWS_SESSION_TOKEN; Cloudflare uses CLOUDFLARE_API_TOKEN. CLOUDFRONT_ENDPOINT
+// and CLOUDFLARE_ENDPOINT override the API base URLs.
+func FromEnv(target Target, base string) (Purger, error) {
+	parsed, err := url.Parse(strings.TrimSpace(base))
+	if err != nil {
+		return nil, fmt.Errorf("cdn base %q: %w", base, err)
+	}
+	timeout := defaultTimeoutSeconds * time.Second
+	switch target.Provider {
+	case "cloudfront":
+		var creds awssig.Credentials
+		for key, field := range map[string]*string{"AWS_ACCESS_KEY_ID": &creds.AccessKey, "AWS_SECRET_ACCESS_KEY": &creds.SecretKey, "AWS_SESSION_TOKEN": &creds.SessionToken} {
+			if *field, err = secrets.Lookup(key); err != nil {
+				return nil, err
+			}
+		}
+		if creds.AccessKey == "" || creds.SecretKey == "" {
+			return nil, errors.New("missing AWS credentials for cloudfront invalidation")
+		}
+		return &CloudFront{
+			Endpoint: strings.TrimRight(getenv("CLOUDFRONT_ENDPOINT", defaultCloudFrontEndpoint), "/"),
+			ID:       target.ID, Prefix: strings.Trim(parsed.Path, "/"), Credentials: creds,
+			client: &http.Client{Timeout: timeout}, now: time.Now,
+		}, nil
+	case "cloudflare":
+		if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+			return nil, fmt.Errorf("cloudflare purges by URL, so the cdn base %q must be an http:// or https:// URL", base)
+		}
+		token, err := secrets.Lookup("CLOUDFLARE_API_TOKEN")
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, errors.New("missing CLOUDFLARE_API_TOKEN for cloudflare purge")
+		}
+		return &Cloudflare{
+			Endpoint: strings.TrimRight(getenv("CLOUDFLARE_ENDPOINT", defaultCloudflareEndpoint), "/"),
+			Zone:     target.ID, Base: strings.TrimRight(parsed.String(), "/"), Token: token,
+			client: &http.Client{Timeout: timeout},
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported cdn %q", target.Provider)
+}
+
+// CloudFront invalidates paths on one distribution. Prefix has no leading
+// or trailing slash.
+type CloudFront struct {
+	Endpoint    string
+	ID          string
+	Prefix      string
+	Credentials awssig.Credentials
+
+	client *http.Client
+	now    func() time.Time
+}
+
+type invalidationBatch struct {
+	XMLName         xml.Name `xml:"http://cloudfront.amazonaws.com/doc/2020-05-31/ InvalidationBatch"`
+	Quantity        int      `xml:"Paths>Quantity"`
+	Items           []string `xml:"Paths>Items>Path"`
+	CallerReference string   `xml:"CallerReference"`
+}
+
+// Purge creates one invalidation for paths, or for the whole prefix once
+// there are more than maxCloudFrontPaths.
+func (c *CloudFront) Purge(ctx context.Context, paths []string) (int, error) {
+	if len(paths) == 0 {
+		return 0, nil
+	}
+	prefix := "/"
+	if c.Prefix != "" {
+		prefix += c.Prefix + "/"
+	}
+	batch := invalidationBatch{CallerReference: fmt.Sprintf("tradegravity-%d", c.now().UnixNano())}
+	if len(paths) > maxCloudFrontPaths {
+		batch.Items = []string{prefix + "*"}
+	} else {
+		for _, path := range paths {
+			batch.Items = append(batch.Items, prefix+escapePath(path))
+		}
+	}
+	batch.Quantity = len(batch.Items)
+	body, err := xml.Marshal(batch)
+	if err != nil {
+		return 0, err
+	}
+	uri := c.Endpoint + "/2020-05-31/distribution/" + url.PathEscape(c.ID) + "/invalidation"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	// CloudFront is a global service signed in us-east-1.
+	awssig.Sign(req, "cloudfront", "us-east-1", awssig.PayloadHash(body), c.Credentials, c.now())
+	if err := do(c.client, req, "invalidate "+c.ID); err != nil {
+		return 0, err
+	}
+	return batch.Quantity, nil
+}
+
+// Cloudflare purges the URLs of paths under Base from one zone.
+type Cloudflare struct {
+	Endpoint string
+	Zone     string
+	Base     string
+	Token    string
+
+	client *http.Client
+}
+
+// Purge sends the URLs in batches of cloudflareBatch.
+func (c *Cloudflare) Purge(ctx context.Context, paths []string) (int, error) {
+	purged := 0
+	for start := 0; start < len(paths); start += cloudflareBatch {
+		var files []string
+		for _, path := range paths[start:min(start+cloudflareBatch, len(paths))] {
+			files = append(files, c.Base+"/"+escapePath(path))
+		}
+		body, err := json.Marshal(map[string][]string{"files": files})
+		if err != nil {
+			return purged, err
+		}
+		uri := c.Endpoint + "/zones/" + url.PathEscape(c.Zone) + "/purge_cache"
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, bytes.NewReader(body))
+		if err != nil {
+			return purged, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		req.Header.Set("Content-Type", "application/json")
+		if err := do(c.client, req, "purge zone "+c.Zone); err != nil {
+			return purged, err
+		}
+		purged += len(files)
+	}
+	return purged, nil
+}
+
+func do(client *http.Client, req *http.Request, action string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// escapePath percent-encodes each path segment, keeping the slashes.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		segments[index] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func getenv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
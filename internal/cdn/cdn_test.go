@@ -0,0 +1,118 @@
+package cdn
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTarget(t *testing.T) {
+	target, err := ParseTarget("CloudFront:E2EXAMPLE")
+	if err != nil || target.Provider != "cloudfront" || target.ID != "E2EXAMPLE" || target.String() != "cloudfront:E2EXAMPLE" {
+		t.Fatalf("target = %+v, %v", target, err)
+	}
+	for _, value := range []string{"", "fastly:abc", "cloudflare:", "cloudfront:a/b"} {
+		if _, err := ParseTarget(value); err == nil {
+			t.Fatalf("ParseTarget(%q) accepted", value)
+		}
+	}
+}
+
+func TestCloudFrontInvalidatesPrefixedPathsOrWildcard(t *testing.T) {
+	var batches []invalidationBatch
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2020-05-31/distribution/E2EXAMPLE/invalidation" {
+			t.Fatalf("path = %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); !strings.Contains(auth, "/us-east-1/cloudfront/aws4_request") {
+			t.Fatalf("Authorization = %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var batch invalidationBatch
+		if err := xml.Unmarshal(body, &batch); err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, batch)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("CLOUDFRONT_ENDPOINT", srv.URL)
+	purger, err := FromEnv(Target{Provider: "cloudfront", ID: "E2EXAMPLE"}, "https://d111.cloudfront.net/data/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	purger.(*CloudFront).now = func() time.Time { return time.Unix(1, 0) }
+
+	sent, err := purger.Purge(context.Background(), []string{"meta.json", "countries/KOR.json"})
+	if err != nil || sent != 2 {
+		t.Fatalf("sent = %d, %v", sent, err)
+	}
+	if got := strings.Join(batches[0].Items, ","); got != "/data/meta.json,/data/countries/KOR.json" || batches[0].Quantity != 2 || batches[0].CallerReference == "" {
+		t.Fatalf("batch = %+v", batches[0])
+	}
+
+	many := make([]string, maxCloudFrontPaths+1)
+	for index := range many {
+		many[index] = fmt.Sprintf("countries/%d.json", index)
+	}
+	if sent, err := purger.Purge(context.Background(), many); err != nil || sent != 1 {
+		t.Fatalf("sent = %d, %v", sent, err)
+	}
+	if got := strings.Join(batches[1].Items, ","); got != "/data/*" {
+		t.Fatalf("wildcard batch = %q", got)
+	}
+	if sent, err := purger.Purge(context.Background(), nil); err != nil || sent != 0 || len(batches) != 2 {
+		t.Fatalf("empty purge sent = %d, %v, requests = %d", sent, err, len(batches))
+	}
+}
+
+func TestCloudflarePurgesURLsInBatches(t *testing.T) {
+	var requests [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/zones/zone-1/purge_cache" || r.Header.Get("Authorization") != "Bearer token-1" {
+			t.Fatalf("request = %s %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var body struct {
+			Files []string `json:"files"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, body.Files)
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer srv.Close()
+	t.Setenv("CLOUDFLARE_API_TOKEN", "token-1")
+	t.Setenv("CLOUDFLARE_ENDPOINT", srv.URL)
+	if _, err := FromEnv(Target{Provider: "cloudflare", ID: "zone-1"}, "/data"); err == nil {
+		t.Fatal("want an error for a base without a host")
+	}
+	purger, err := FromEnv(Target{Provider: "cloudflare", ID: "zone-1"}, "https://example.com/data/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, cloudflareBatch+1)
+	for index := range paths {
+		paths[index] = fmt.Sprintf("countries/%d.json", index)
+	}
+	paths[0] = "rca/a b.json"
+	sent, err := purger.Purge(context.Background(), paths)
+	if err != nil || sent != len(paths) {
+		t.Fatalf("sent = %d, %v", sent, err)
+	}
+	if len(requests) != 2 || len(requests[0]) != cloudflareBatch || len(requests[1]) != 1 {
+		t.Fatalf("requests = %v", requests)
+	}
+	if requests[0][0] != "https://example.com/data/rca/a%20b.json" {
+		t.Fatalf("first URL = %q", requests[0][0])
+	}
+}
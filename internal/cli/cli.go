@@ -0,0 +1,123 @@
+// Package cli holds the command dispatch, argument parsing, and store setup
+// shared by the tradegravity binary and the legacy collector and publisher
+// entry points, so each command parses flags and opens storage the same way.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tradegravity/internal/runlock"
+	"tradegravity/internal/store"
+	"tradegravity/internal/store/sqlite"
+)
+
+// Command is one named subcommand. Run receives the arguments that follow the
+// subcommand name.
+type Command struct {
+	Name    string
+	Summary string
+	Run     func(args []string)
+}
+
+// Dispatch runs the command named by args[0]. Unknown or missing commands print
+// usage and exit with status 2.
+func Dispatch(program string, commands []Command, args []string) {
+	if len(args) < 1 {
+		Usage(os.Stderr, program, commands)
+		os.Exit(2)
+	}
+	for _, command := range commands {
+		if command.Name == args[0] {
+			command.Run(args[1:])
+			return
+		}
+	}
+	Usage(os.Stderr, program, commands)
+	os.Exit(2)
+}
+
+// Usage lists the available subcommands.
+func Usage(w io.Writer, program string, commands []Command) {
+	fmt.Fprintf(w, "usage: %s <command> [options]\n", program)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "commands:")
+	width := 0
+	for _, command := range commands {
+		width = max(width, len(command.Name))
+	}
+	for _, command := range commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, command.Name, command.Summary)
+	}
+}
+
+// Fatal prints "prefix: err" to stderr and exits with status 1.
+func Fatal(prefix string, err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", prefix, err)
+	os.Exit(1)
+}
+
+// SourceDate returns the time in SOURCE_DATE_EPOCH, the reproducible-builds
+// convention of Unix seconds, and reports whether it is set.
+func SourceDate() (time.Time, bool, error) {
+	value := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q (expected Unix seconds)", value)
+	}
+	return time.Unix(seconds, 0).UTC(), true, nil
+}
+
+// OpenStore opens the SQLite store at path. An empty path disables
+// persistence and returns a no-op store.
+func OpenStore(path string) (store.Store, error) {
+	if strings.TrimSpace(path) == "" {
+		return &store.NopStore{}, nil
+	}
+	return sqlite.New(path)
+}
+
+// LockStore takes the run lock of the store at dbPath, <dbPath>.lock, so
+// overlapping runs, such as cron jobs that outlast their interval, do not
+// write the store at once. It waits up to wait for another run to finish;
+// if the lock is still held, it prints the holder and exits with status 0,
+// leaving the store to that run. An empty path takes no lock. Call the
+// returned function when the run is done.
+func LockStore(dbPath string, wait time.Duration, command string) func() {
+	if strings.TrimSpace(dbPath) == "" {
+		return func() {}
+	}
+	lock, err := runlock.Acquire(dbPath+".lock", wait, command)
+	var held *runlock.HeldError
+	if errors.As(err, &held) {
+		fmt.Printf("%s skipped: %v\n", command, err)
+		os.Exit(0)
+	}
+	if err != nil {
+		Fatal(command+" failed", err)
+	}
+	return func() { lock.Release() }
+}
+
+// ParseList splits a comma-separated list into trimmed, upper-case items and
+// drops empty entries.
+func ParseList(value string) []string {
+	raw := strings.Split(value, ",")
+	items := make([]string, 0, len(raw))
+	for _, item := range raw {
+		trimmed := strings.TrimSpace(item)
+		if trimmed == "" {
+			continue
+		}
+		items = append(items, strings.ToUpper(trimmed))
+	}
+	return items
+}
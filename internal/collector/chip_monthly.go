@@ -1,4 +1,4 @@
-package main
+package collector
 
 import (
 	"context"
@@ -10,6 +10,9 @@ import (
 	"sync"
 	"time"
 
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
+	"tradegravity/internal/iso"
 	"tradegravity/internal/model"
 	"tradegravity/internal/providers"
 	"tradegravity/internal/providers/comtrade"
@@ -26,9 +29,11 @@ func runChipMonthly(args []string) {
 	flowsCSV := fs.String("flows", "export,import", "comma-separated flows")
 	allowlist := fs.String("allowlist", "configs/chip_connectors.csv", "focused monthly reporter allowlist")
 	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without collecting (0 = exit at once)")
 	concurrency := fs.Int("concurrency", 2, "maximum reporters collected concurrently")
 	verbose := fs.Bool("verbose", false, "print collection progress")
-	fs.Parse(args)
+	config.Parse(fs, args)
+	defer cli.LockStore(*dbPath, *lockWait, "collector chip-monthly")()
 
 	reference, err := semiconductor.Load(*referencePath)
 	if err != nil {
@@ -50,22 +55,17 @@ func monthlyWindow(through string, months int, now time.Time) ([]string, error)
 	if months < 1 || months > 36 {
 		return nil, fmt.Errorf("months must be between 1 and 36, got %d", months)
 	}
-	var end time.Time
 	if strings.EqualFold(strings.TrimSpace(through), "auto") {
-		end = time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
-	} else {
-		year, month, ok := parseYearMonth(through)
-		if !ok {
-			return nil, fmt.Errorf("through must be YYYY-MM or auto, got %q", through)
-		}
-		end = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		through = time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
 	}
-	periods := make([]string, months)
-	for index := range months {
-		period := end.AddDate(0, index-(months-1), 0)
-		periods[index] = period.Format("2006-01")
+	if _, _, ok := model.ParseYearMonth(through); !ok {
+		return nil, fmt.Errorf("through must be YYYY-MM or auto, got %q", through)
+	}
+	window, err := model.PeriodRangeEnding(model.PeriodMonth, through, months)
+	if err != nil {
+		return nil, err
 	}
-	return periods, nil
+	return window.Periods(), nil
 }
 
 func runChipMonthlyCollector(providerID string, periods, codes []string, partnersCSV, flowsCSV, allowlistPath, dbPath string, concurrency int, verbose bool) (runErr error) {
@@ -93,7 +93,10 @@ func runChipMonthlyCollector(providerID string, periods, codes []string, partner
 	if len(reporters) == 0 {
 		return errors.New("no monthly semiconductor reporters after filtering")
 	}
-	partners := parseList(partnersCSV)
+	partners, err := iso.ParseList(partnersCSV)
+	if err != nil {
+		return fmt.Errorf("invalid partners: %w", err)
+	}
 	flows, err := parseFlows(flowsCSV)
 	if err != nil {
 		return err
@@ -103,10 +106,8 @@ func runChipMonthlyCollector(providerID string, periods, codes []string, partner
 		return err
 	}
 	defer st.Close()
-	runRecord := model.IngestRun{
-		RunID: newRunID(providerID, "products-semiconductor-monthly-hs6"), Provider: providerID,
-		Mode: "products-semiconductor-monthly-hs6", StartedAt: time.Now().UTC(), ReporterCount: len(reporters),
-	}
+	runRecord := newIngestRun(providerID, "products-semiconductor-monthly-hs6")
+	runRecord.ReporterCount = len(reporters)
 	defer func() {
 		runRecord.FinishedAt = time.Now().UTC()
 		runRecord.Status = ingestStatus(runRecord, runErr)
@@ -157,17 +158,18 @@ func runChipMonthlyCollector(providerID string, periods, codes []string, partner
 	} else {
 		for _, reporter := range reporters {
 			for _, partner := range partners {
-				if strings.EqualFold(reporter.ISO3, partner) {
+				pair := model.NewPair(reporter.ISO3, partner)
+				if pair.SameCountry() {
 					runRecord.SkippedCount += len(flows)
 					continue
 				}
 				for _, flow := range flows {
 					requests = append(requests, request{
-						reporters: []string{reporter.ISO3},
-						partners:  []string{partner},
+						reporters: []string{pair.Reporter},
+						partners:  []string{pair.Partner},
 						flow:      flow,
 						periods:   periods,
-						label:     fmt.Sprintf("%s/%s/%s", reporter.ISO3, partner, flow),
+						label:     fmt.Sprintf("%s/%s", pair, flow),
 					})
 				}
 			}
@@ -193,7 +195,7 @@ func runChipMonthlyCollector(providerID string, periods, codes []string, partner
 					if fetchErr == nil {
 						filtered := rows[:0]
 						for _, row := range rows {
-							if !strings.EqualFold(row.ReporterISO3, row.PartnerISO3) {
+							if !model.NewPair(row.ReporterISO3, row.PartnerISO3).SameCountry() {
 								filtered = append(filtered, row)
 							}
 						}
@@ -235,6 +237,7 @@ func runChipMonthlyCollector(providerID string, periods, codes []string, partner
 		if persistErr != nil {
 			continue
 		}
+		item.rows = validObservations(&runRecord, item.rows)
 		if err := st.UpsertObservations(ctx, item.rows); err != nil {
 			persistErr = err
 			continue
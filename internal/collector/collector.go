@@ -0,0 +1,852 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"tradegravity/internal/buildinfo"
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
+	"tradegravity/internal/iso"
+	"tradegravity/internal/model"
+	"tradegravity/internal/providers"
+	"tradegravity/internal/providers/comtrade"
+	"tradegravity/internal/providers/wits"
+	"tradegravity/internal/store"
+)
+
+// Main runs the collector subcommand named by args[0]. program is the name
+// printed in usage text.
+func Main(program string, args []string) {
+	if len(args) < 1 {
+		usage(program)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "-version", "--version":
+		buildinfo.Print(os.Stdout, program)
+	case "run":
+		run(args[1:])
+	case "products":
+		runProducts(args[1:])
+	case "strategic":
+		runStrategic(args[1:])
+	case "tariffs":
+		runTariffs(args[1:])
+	case "matrix":
+		runMatrix(args[1:])
+	case "chip-monthly":
+		runChipMonthly(args[1:])
+	case "plan":
+		runPlan(args[1:])
+	case "fx":
+		runFX(args[1:])
+	default:
+		usage(program)
+		os.Exit(2)
+	}
+}
+
+func runProducts(args []string) {
+	fs := flag.NewFlagSet("products", flag.ExitOnError)
+	provider := fs.String("provider", "comtrade", "product data provider id")
+	primaryProvider := fs.String("primary-provider", "wits", "provider used to choose the dominant year when -year=auto")
+	year := fs.String("year", "auto", "annual product period or auto")
+	level := fs.Int("product-level", 2, "HS product level (currently 2)")
+	partners := fs.String("partners", "USA,CHN", "comma-separated partner ISO3 list")
+	flows := fs.String("flows", "export,import", "comma-separated flows")
+	limit := fs.Int("limit", 0, "limit number of reporters (0 = all)")
+	allowlist := fs.String("allowlist", "configs/allowlist.csv", "path to allowlist file")
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without collecting (0 = exit at once)")
+	concurrency := fs.Int("concurrency", 6, "maximum reporters collected concurrently")
+	verbose := fs.Bool("verbose", false, "print collection progress")
+	config.Parse(fs, args)
+	defer cli.LockStore(*dbPath, *lockWait, "collector products")()
+
+	if err := runProductCollector(*provider, *primaryProvider, *year, *level, nil, *partners, *flows, *limit, *allowlist, *dbPath, *concurrency, *verbose); err != nil {
+		fmt.Fprintln(os.Stderr, "product collector failed:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	provider := fs.String("provider", "wits", "provider id")
+	partners := fs.String("partners", "USA,CHN", "comma-separated partner ISO3 list")
+	flows := fs.String("flows", "export,import", "comma-separated flows")
+	limit := fs.Int("limit", 0, "limit number of reporters (0 = all)")
+	allowlist := fs.String("allowlist", "configs/allowlist.csv", "path to allowlist file (empty = no filter)")
+	countries := fs.String("countries", "configs/countries.csv", "country metadata CSV with English and Korean reporter names (optional)")
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path (empty disables persistence)")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without collecting (0 = exit at once)")
+	historyYears := fs.Int("history-years", 1, "number of previous years to fetch for growth (0 = latest only)")
+	concurrency := fs.Int("concurrency", 6, "maximum reporters collected concurrently")
+	world := fs.Bool("world", true, "also collect the WLD (world) partner used as the share-of-total denominator")
+	verbose := fs.Bool("verbose", false, "print each observation")
+	config.Parse(fs, args)
+	defer cli.LockStore(*dbPath, *lockWait, "collector run")()
+
+	if *world {
+		*partners = WithWorld(*partners)
+	}
+	if _, err := CollectTotals(*provider, *partners, *flows, *limit, *allowlist, *countries, *dbPath, *historyYears, *concurrency, *verbose); err != nil {
+		fmt.Fprintln(os.Stderr, "collector run failed:", err)
+		os.Exit(1)
+	}
+}
+
+func usage(program string) {
+	fmt.Fprintf(os.Stderr, "usage: %s run [options]\n", program)
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "options:")
+	fmt.Fprintln(os.Stderr, "  -provider    provider id (default: wits)")
+	fmt.Fprintln(os.Stderr, "  -partners    comma-separated partner ISO3 list (default: USA,CHN)")
+	fmt.Fprintln(os.Stderr, "  -flows       comma-separated flows (default: export,import)")
+	fmt.Fprintln(os.Stderr, "  -limit       limit number of reporters (default: 0)")
+	fmt.Fprintln(os.Stderr, "  -allowlist   path to allowlist file (default: configs/allowlist.csv)")
+	fmt.Fprintln(os.Stderr, "  -countries   reporter name CSV with name and name_ko (default: configs/countries.csv)")
+	fmt.Fprintln(os.Stderr, "  -db          sqlite database path (default: tradegravity.db)")
+	fmt.Fprintln(os.Stderr, "  -lock-wait   wait for another run holding the -db lock (default: 0, exit at once)")
+	fmt.Fprintln(os.Stderr, "  -history-years  number of previous years to fetch (default: 1)")
+	fmt.Fprintln(os.Stderr, "  -concurrency maximum concurrent reporters (default: 6)")
+	fmt.Fprintln(os.Stderr, "  -world       also collect the WLD partner (default: true)")
+	fmt.Fprintln(os.Stderr, "  -verbose     print each observation")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintf(os.Stderr, "product breakdown: %s products [options]\n", program)
+	fmt.Fprintf(os.Stderr, "strategic HS6: %s strategic [options]\n", program)
+	fmt.Fprintf(os.Stderr, "strategic HS6 tariffs: %s tariffs [options]\n", program)
+	fmt.Fprintf(os.Stderr, "multi-partner matrix: %s matrix [options]\n", program)
+	fmt.Fprintf(os.Stderr, "monthly semiconductor lens: %s chip-monthly [options]\n", program)
+	fmt.Fprintf(os.Stderr, "request budget estimate: %s plan [options]\n", program)
+	fmt.Fprintf(os.Stderr, "exchange rates: %s fx [options]\n", program)
+}
+
+// WorldPartner is the partner code for a reporter's trade with the world.
+// The publisher divides partner trade by it for share_of_total.
+const WorldPartner = iso.World
+
+// WithWorld appends WorldPartner to a comma-separated partner list unless it
+// is already there.
+func WithWorld(partnersCSV string) string {
+	for _, partner := range cli.ParseList(partnersCSV) {
+		if partner == WorldPartner {
+			return partnersCSV
+		}
+	}
+	if strings.TrimSpace(partnersCSV) == "" {
+		return WorldPartner
+	}
+	return partnersCSV + "," + WorldPartner
+}
+
+// CollectTotals fetches total-trade observations for every reporter, partner,
+// and flow and stores the ones not already present. The returned run record is
+// the one written to ingest_runs, so callers can tell whether anything new was
+// stored.
+func CollectTotals(providerID, partnersCSV, flowsCSV string, limit int, allowlistPath, countriesPath, dbPath string, historyYears, concurrency int, verbose bool) (runRecord model.IngestRun, runErr error) {
+	provider, err := buildProvider(providerID)
+	if err != nil {
+		return runRecord, err
+	}
+
+	ctx := context.Background()
+
+	st, err := openStore(dbPath)
+	if err != nil {
+		return runRecord, err
+	}
+	defer st.Close()
+	runRecord = newIngestRun(providerID, "totals")
+	defer func() {
+		runRecord.FinishedAt = time.Now().UTC()
+		runRecord.Status = ingestStatus(runRecord, runErr)
+		if runErr != nil {
+			runRecord.Errors = appendLimited(runRecord.Errors, runErr.Error())
+		}
+		if err := st.RecordIngestRun(context.Background(), runRecord); err != nil && runErr == nil {
+			runErr = err
+		}
+	}()
+
+	allowed := map[string]struct{}{}
+	if strings.TrimSpace(allowlistPath) != "" {
+		loaded, err := loadAllowlist(allowlistPath)
+		if err != nil {
+			return runRecord, err
+		}
+		allowed = loaded
+	}
+
+	reporters, err := resolveReporters(ctx, provider)
+	if err != nil {
+		if len(allowed) == 0 {
+			return runRecord, err
+		}
+		fmt.Fprintf(os.Stderr, "warning: %v (using allowlist only)\n", err)
+		reporters = reportersFromAllowlist(allowed)
+	} else if len(allowed) > 0 {
+		reporters = filterReporters(reporters, allowed)
+	}
+	if limit > 0 && len(reporters) > limit {
+		reporters = reporters[:limit]
+	}
+	if len(reporters) == 0 {
+		return runRecord, errors.New("no reporters after filtering")
+	}
+	runRecord.ReporterCount = len(reporters)
+	names, err := loadCountryNames(countriesPath)
+	if err != nil {
+		return runRecord, err
+	}
+	if err := st.UpsertReporters(ctx, applyCountryClassification(applyCountryNames(reporters, names))); err != nil {
+		return runRecord, err
+	}
+
+	partners, err := iso.ParseList(partnersCSV)
+	if err != nil {
+		return runRecord, fmt.Errorf("invalid partners: %w", err)
+	}
+	if len(partners) == 0 {
+		return runRecord, errors.New("no partners provided")
+	}
+
+	flowList, err := parseFlows(flowsCSV)
+	if err != nil {
+		return runRecord, err
+	}
+
+	type totalResult struct {
+		pair      model.Pair
+		flow      model.Flow
+		series    []model.Observation
+		err       error
+		requested bool
+	}
+	workerCount := max(1, min(concurrency, len(reporters)))
+	reporterJobs := make(chan model.Reporter)
+	results := make(chan totalResult, workerCount*2)
+	var workers sync.WaitGroup
+	for range workerCount {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for reporter := range reporterJobs {
+				for _, partner := range partners {
+					pair := model.NewPair(reporter.ISO3, partner)
+					for _, flow := range flowList {
+						if pair.SameCountry() {
+							results <- totalResult{pair: pair, flow: flow}
+							continue
+						}
+						series, fetchErr := collectObservations(ctx, provider, st, providerID, pair, flow, historyYears)
+						results <- totalResult{pair: pair, flow: flow, series: series, err: fetchErr, requested: true}
+					}
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, reporter := range reporters {
+			reporterJobs <- reporter
+		}
+		close(reporterJobs)
+		workers.Wait()
+		close(results)
+	}()
+	var quotaErr error
+	var persistErr error
+	for result := range results {
+		if !result.requested {
+			runRecord.SkippedCount++
+			if verbose {
+				fmt.Fprintf(os.Stderr, "skip same-country reporter=%s partner=%s flow=%s\n", result.pair.Reporter, result.pair.Partner, result.flow)
+			}
+			continue
+		}
+		runRecord.RequestCount++
+		if result.err != nil {
+			if errors.Is(result.err, wits.ErrNoRecords) || errors.Is(result.err, comtrade.ErrNoRecords) {
+				runRecord.SkippedCount++
+				continue
+			}
+			if errors.Is(result.err, comtrade.ErrQuotaExceeded) {
+				quotaErr = result.err
+			}
+			runRecord.FailureCount++
+			runRecord.Errors = appendLimited(runRecord.Errors, fmt.Sprintf("%s/%s: %v", result.pair, result.flow, result.err))
+			fmt.Fprintf(os.Stderr, "fetch failed reporter=%s partner=%s flow=%s: %v\n", result.pair.Reporter, result.pair.Partner, result.flow, result.err)
+			continue
+		}
+		result.series = validObservations(&runRecord, result.series)
+		if len(result.series) == 0 {
+			runRecord.SkippedCount++
+			continue
+		}
+		if persistErr != nil {
+			continue
+		}
+		if err := st.UpsertObservations(ctx, result.series); err != nil {
+			persistErr = err
+			continue
+		}
+		runRecord.SuccessCount++
+		runRecord.StoredCount += len(result.series)
+		if verbose {
+			for _, observation := range result.series {
+				fmt.Printf("%s %s %s %s %s %.2f\n", observation.ReporterISO3, observation.PartnerISO3, observation.Flow, observation.PeriodType, observation.Period, observation.ValueUSD)
+			}
+		}
+	}
+	if persistErr != nil {
+		return runRecord, persistErr
+	}
+	if quotaErr != nil {
+		return runRecord, quotaErr
+	}
+
+	if runRecord.StoredCount > 0 {
+		fmt.Printf("collector stored observations=%d\n", runRecord.StoredCount)
+		if err := refreshProviderDiscrepancies(ctx, st); err != nil {
+			return runRecord, err
+		}
+	}
+	fmt.Printf("collector run complete (provider=%s reporters=%d requests=%d success=%d failed=%d)\n",
+		providerID, len(reporters), runRecord.RequestCount, runRecord.SuccessCount, runRecord.FailureCount,
+	)
+	if runRecord.SkippedCount > 0 {
+		fmt.Printf("collector run skipped=%d\n", runRecord.SkippedCount)
+	}
+	return runRecord, nil
+}
+
+func runProductCollector(providerID, primaryProvider, year string, level int, selectedCodes []string, partnersCSV, flowsCSV string, limit int, allowlistPath, dbPath string, concurrency int, verbose bool) (runErr error) {
+	return runProductCollectorHistory(providerID, primaryProvider, year, level, selectedCodes, partnersCSV, flowsCSV, limit, allowlistPath, dbPath, concurrency, verbose, 0)
+}
+
+func runProductCollectorHistory(providerID, primaryProvider, year string, level int, selectedCodes []string, partnersCSV, flowsCSV string, limit int, allowlistPath, dbPath string, concurrency int, verbose bool, historyYears int) (runErr error) {
+	provider, err := buildProvider(providerID)
+	if err != nil {
+		return err
+	}
+	var fetchProducts func(context.Context, string, string, model.Flow, string, int) ([]model.Observation, error)
+	mode := fmt.Sprintf("products-hs%d", level)
+	if len(selectedCodes) > 0 {
+		selectedProvider, ok := provider.(providers.SelectedProductProvider)
+		if !ok {
+			return fmt.Errorf("provider %s does not support selected product codes", providerID)
+		}
+		codes := append([]string(nil), selectedCodes...)
+		fetchProducts = func(ctx context.Context, reporter, partner string, flow model.Flow, year string, level int) ([]model.Observation, error) {
+			return selectedProvider.FetchProductCodes(ctx, reporter, partner, flow, year, level, codes)
+		}
+		mode = fmt.Sprintf("products-strategic-hs%d", level)
+	} else {
+		productProvider, ok := provider.(providers.ProductProvider)
+		if !ok {
+			return fmt.Errorf("provider %s does not support product breakdowns", providerID)
+		}
+		fetchProducts = productProvider.FetchProducts
+	}
+	ctx := context.Background()
+	st, err := openStore(dbPath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	runRecord := newIngestRun(providerID, mode)
+	defer func() {
+		runRecord.FinishedAt = time.Now().UTC()
+		runRecord.Status = ingestStatus(runRecord, runErr)
+		if runErr != nil {
+			runRecord.Errors = appendLimited(runRecord.Errors, runErr.Error())
+		}
+		if err := st.RecordIngestRun(context.Background(), runRecord); err != nil && runErr == nil {
+			runErr = err
+		}
+	}()
+
+	selectedYear := strings.TrimSpace(year)
+	if strings.EqualFold(selectedYear, "auto") {
+		selectedYear, err = st.DominantAnnualPeriod(ctx, primaryProvider)
+		if err != nil {
+			return err
+		}
+	}
+	if _, ok := model.ParseYear(selectedYear); !ok {
+		return fmt.Errorf("product year must be a four-digit annual period, got %q", selectedYear)
+	}
+	if historyYears < 0 || historyYears > 20 {
+		return fmt.Errorf("product history-years must be between 0 and 20, got %d", historyYears)
+	}
+	selectedYears := annualHistory(selectedYear, historyYears)
+
+	allowed, err := loadAllowlist(allowlistPath)
+	if err != nil {
+		return err
+	}
+	reporters, err := resolveReporters(ctx, provider)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v (using allowlist only)\n", err)
+		reporters = reportersFromAllowlist(allowed)
+	} else {
+		reporters = filterReporters(reporters, allowed)
+	}
+	if limit > 0 && len(reporters) > limit {
+		reporters = reporters[:limit]
+	}
+	if len(reporters) == 0 {
+		return errors.New("no reporters after filtering")
+	}
+	runRecord.ReporterCount = len(reporters)
+	partners, err := iso.ParseList(partnersCSV)
+	if err != nil {
+		return fmt.Errorf("invalid partners: %w", err)
+	}
+	flows, err := parseFlows(flowsCSV)
+	if err != nil {
+		return err
+	}
+
+	type productResult struct {
+		pair         model.Pair
+		year         string
+		flow         model.Flow
+		observations []model.Observation
+		err          error
+		requested    bool
+	}
+	workerCount := max(1, min(concurrency, len(reporters)))
+	reporterJobs := make(chan model.Reporter)
+	results := make(chan productResult, workerCount*2)
+	var workers sync.WaitGroup
+	for range workerCount {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for reporter := range reporterJobs {
+				for _, selectedPeriod := range selectedYears {
+					for _, partner := range partners {
+						pair := model.NewPair(reporter.ISO3, partner)
+						for _, flow := range flows {
+							if pair.SameCountry() {
+								results <- productResult{pair: pair, year: selectedPeriod, flow: flow}
+								continue
+							}
+							observations, fetchErr := fetchProducts(ctx, pair.Reporter, pair.Partner, flow, selectedPeriod, level)
+							results <- productResult{pair: pair, year: selectedPeriod, flow: flow, observations: observations, err: fetchErr, requested: true}
+						}
+					}
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, reporter := range reporters {
+			reporterJobs <- reporter
+		}
+		close(reporterJobs)
+		workers.Wait()
+		close(results)
+	}()
+	var persistErr error
+	for result := range results {
+		if !result.requested {
+			runRecord.SkippedCount++
+			continue
+		}
+		runRecord.RequestCount++
+		if result.err != nil {
+			if errors.Is(result.err, wits.ErrNoRecords) || errors.Is(result.err, comtrade.ErrNoRecords) {
+				runRecord.SkippedCount++
+				continue
+			}
+			runRecord.FailureCount++
+			runRecord.Errors = appendLimited(runRecord.Errors, fmt.Sprintf("%s/%s/%s: %v", result.pair, result.flow, result.year, result.err))
+			fmt.Fprintf(os.Stderr, "product fetch failed reporter=%s partner=%s flow=%s year=%s: %v\n", result.pair.Reporter, result.pair.Partner, result.flow, result.year, result.err)
+			continue
+		}
+		if persistErr != nil {
+			continue
+		}
+		result.observations = validObservations(&runRecord, result.observations)
+		if err := st.UpsertObservations(ctx, result.observations); err != nil {
+			persistErr = err
+			continue
+		}
+		runRecord.SuccessCount++
+		runRecord.StoredCount += len(result.observations)
+		if verbose {
+			fmt.Printf("products reporter=%s partner=%s flow=%s year=%s rows=%d\n", result.pair.Reporter, result.pair.Partner, result.flow, result.year, len(result.observations))
+		}
+	}
+	if persistErr != nil {
+		return persistErr
+	}
+	if runRecord.SuccessCount == 0 {
+		return errors.New("no product observations collected")
+	}
+	fmt.Printf("product collector complete (provider=%s years=%s level=%d reporters=%d requests=%d success=%d failed=%d observations=%d)\n",
+		providerID, strings.Join(selectedYears, ","), level, len(reporters), runRecord.RequestCount, runRecord.SuccessCount, runRecord.FailureCount, runRecord.StoredCount)
+	return nil
+}
+
+func annualHistory(selectedYear string, historyYears int) []string {
+	return historyRange(selectedYear, historyYears).Periods()
+}
+
+// historyRange is the selected year and the historyYears before it, never
+// reaching back past year zero. It is empty when selectedYear is not a year.
+func historyRange(selectedYear string, historyYears int) model.PeriodRange {
+	latest, ok := model.ParseYear(selectedYear)
+	if !ok {
+		return model.PeriodRange{}
+	}
+	window, err := model.PeriodRangeEnding(model.PeriodYear, selectedYear, min(max(historyYears, 0), latest)+1)
+	if err != nil {
+		return model.PeriodRange{}
+	}
+	return window
+}
+
+func newRunID(provider, mode string) string {
+	return fmt.Sprintf("%d-%s-%s", time.Now().UTC().UnixNano(), strings.ToLower(strings.TrimSpace(provider)), mode)
+}
+
+// newIngestRun starts a run record stamped with the collector's build, so
+// the fetch log shows which code version collected each batch.
+func newIngestRun(provider, mode string) model.IngestRun {
+	build := buildinfo.Get()
+	return model.IngestRun{
+		RunID:       newRunID(provider, mode),
+		Provider:    provider,
+		Mode:        mode,
+		StartedAt:   time.Now().UTC(),
+		CodeVersion: build.Version,
+		CodeCommit:  build.Commit,
+	}
+}
+
+func ingestStatus(run model.IngestRun, runErr error) string {
+	if runErr != nil || (run.SuccessCount == 0 && run.FailureCount > 0) {
+		return "failed"
+	}
+	if run.FailureCount > 0 {
+		return "partial"
+	}
+	return "success"
+}
+
+func appendLimited(values []string, value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" || len(values) >= 50 {
+		return values
+	}
+	return append(values, value)
+}
+
+// validObservations returns the observations that pass
+// model.Observation.Validate. Each violation of the others is printed and
+// recorded in the run errors, and the rejected rows are never stored.
+func validObservations(runRecord *model.IngestRun, observations []model.Observation) []model.Observation {
+	valid := make([]model.Observation, 0, len(observations))
+	for _, observation := range observations {
+		err := observation.Validate()
+		if err == nil {
+			valid = append(valid, observation)
+			continue
+		}
+		var invalid *model.ObservationError
+		if !errors.As(err, &invalid) {
+			runRecord.Errors = appendLimited(runRecord.Errors, err.Error())
+			fmt.Fprintf(os.Stderr, "invalid observation: %v\n", err)
+			continue
+		}
+		for _, violation := range invalid.Violations {
+			runRecord.Errors = appendLimited(runRecord.Errors, invalid.Observation+": "+violation)
+			fmt.Fprintf(os.Stderr, "invalid observation %s: %s\n", invalid.Observation, violation)
+		}
+	}
+	return valid
+}
+
+// refreshProviderDiscrepancies recomputes the stored WITS/Comtrade
+// discrepancies after new totals land in the store, and then the series
+// quality grades that depend on them.
+func refreshProviderDiscrepancies(ctx context.Context, st store.Store) error {
+	count, err := st.RefreshProviderDiscrepancies(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		fmt.Printf("collector provider discrepancies=%d\n", count)
+	}
+	graded, err := st.RefreshSeriesQuality(ctx)
+	if err != nil {
+		return err
+	}
+	if graded > 0 {
+		fmt.Printf("collector series quality grades=%d\n", graded)
+	}
+	return nil
+}
+
+func collectObservations(ctx context.Context, provider providers.Provider, st store.Store, providerID string, pair model.Pair, flow model.Flow, historyYears int) ([]model.Observation, error) {
+	if err := pair.Validate(); err != nil {
+		return nil, err
+	}
+	existingKeys, err := existingObservationKeys(ctx, st, providerID, pair, flow)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := provider.FetchLatest(ctx, pair.Reporter, pair.Partner, flow)
+	if err != nil {
+		return nil, err
+	}
+	if historyYears <= 0 {
+		if _, exists := existingKeys[observationKey(latest.PeriodType, latest.Period)]; exists {
+			return nil, nil
+		}
+		return []model.Observation{latest}, nil
+	}
+
+	year, ok := yearFromPeriod(latest.PeriodType, latest.Period)
+	if !ok {
+		return []model.Observation{latest}, nil
+	}
+	window := historyRange(fmt.Sprintf("%04d", year), historyYears)
+
+	fetched, err := provider.FetchSeries(ctx, pair.Reporter, pair.Partner, flow, window.From, window.To)
+	if err != nil {
+		if !errors.Is(err, wits.ErrNoRecords) && !errors.Is(err, comtrade.ErrNoRecords) {
+			return nil, err
+		}
+		fetched = nil
+	}
+	series := make([]model.Observation, 0, len(fetched))
+	for _, observation := range fetched {
+		if _, exists := existingKeys[observationKey(observation.PeriodType, observation.Period)]; exists {
+			continue
+		}
+		series = append(series, observation)
+	}
+	if len(series) == 0 {
+		if _, exists := existingKeys[observationKey(latest.PeriodType, latest.Period)]; exists {
+			return nil, nil
+		}
+		return []model.Observation{latest}, nil
+	}
+	return series, nil
+}
+
+func existingObservationKeys(ctx context.Context, st store.Store, providerID string, pair model.Pair, flow model.Flow) (map[string]struct{}, error) {
+	keys := make(map[string]struct{})
+	if st == nil {
+		return keys, nil
+	}
+	existing, err := st.ListObservationKeys(ctx, providerID, pair, flow)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range existing {
+		keys[observationKey(key.PeriodType, key.Period)] = struct{}{}
+	}
+	return keys, nil
+}
+
+func yearFromPeriod(periodType model.PeriodType, period string) (int, bool) {
+	switch periodType {
+	case model.PeriodMonth:
+		year, _, ok := model.ParseYearMonth(period)
+		return year, ok
+	case model.PeriodQuarter:
+		year, _, ok := model.ParseYearQuarter(period)
+		return year, ok
+	case model.PeriodYear:
+		return model.ParseYear(period)
+	default:
+		return 0, false
+	}
+}
+
+func observationKey(periodType model.PeriodType, period string) string {
+	return string(periodType) + "|" + strings.TrimSpace(period)
+}
+
+// buildProvider returns the provider for a -provider id. Tests replace it
+// to collect from internal/providers/mock.
+var buildProvider = func(providerID string) (providers.Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(providerID)) {
+	case "wits":
+		return wits.New()
+	case "comtrade":
+		return comtrade.New()
+	default:
+		return nil, fmt.Errorf("unknown provider: %s", providerID)
+	}
+}
+
+func resolveReporters(ctx context.Context, provider providers.Provider) ([]model.Reporter, error) {
+	reporters, err := provider.ListReporters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterActiveReporters(reporters), nil
+}
+
+func reportersFromAllowlist(allowed map[string]struct{}) []model.Reporter {
+	reporters := make([]model.Reporter, 0, len(allowed))
+	for iso3 := range allowed {
+		country, ok := iso.Lookup(iso3)
+		if !ok {
+			continue
+		}
+		reporters = append(reporters, model.Reporter{
+			ISO3:     country.Alpha3,
+			NameEN:   country.Alpha3,
+			NameKO:   "",
+			Region:   "",
+			IsActive: true,
+		})
+	}
+	return reporters
+}
+
+func loadAllowlist(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	allowed := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		for _, token := range splitTokens(line) {
+			if strings.EqualFold(token, "ISO3") {
+				continue
+			}
+			iso3, err := iso.Normalize(token)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+			allowed[iso3] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(allowed) == 0 {
+		return nil, errors.New("allowlist is empty")
+	}
+	return allowed, nil
+}
+
+func splitTokens(line string) []string {
+	replacer := strings.NewReplacer(";", ",", "\t", ",")
+	line = replacer.Replace(line)
+	parts := strings.Split(line, ",")
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		out = append(out, part)
+	}
+	return out
+}
+
+func filterReporters(reporters []model.Reporter, allowed map[string]struct{}) []model.Reporter {
+	if len(allowed) == 0 {
+		return reporters
+	}
+	filtered := make([]model.Reporter, 0, len(reporters))
+	for _, reporter := range reporters {
+		if _, ok := allowed[strings.ToUpper(reporter.ISO3)]; ok {
+			filtered = append(filtered, reporter)
+		}
+	}
+	return filtered
+}
+
+func normalizeHeader(header []string) map[string]int {
+	result := make(map[string]int, len(header))
+	for i, value := range header {
+		key := strings.ToLower(strings.TrimSpace(value))
+		if key == "" {
+			continue
+		}
+		result[key] = i
+	}
+	return result
+}
+
+func getCell(record []string, header map[string]int, key string) string {
+	index, ok := header[key]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func parseBool(value string) bool {
+	trimmed := strings.ToLower(strings.TrimSpace(value))
+	if trimmed == "" {
+		return true
+	}
+	switch trimmed {
+	case "1", "true", "yes", "y":
+		return true
+	default:
+		return false
+	}
+}
+
+func filterActiveReporters(reporters []model.Reporter) []model.Reporter {
+	active := make([]model.Reporter, 0, len(reporters))
+	for _, reporter := range reporters {
+		if reporter.IsActive {
+			active = append(active, reporter)
+		}
+	}
+	return active
+}
+
+func parseFlows(value string) ([]model.Flow, error) {
+	raw := cli.ParseList(value)
+	if len(raw) == 0 {
+		return nil, errors.New("no flows provided")
+	}
+
+	flows := make([]model.Flow, 0, len(raw))
+	for _, item := range raw {
+		switch strings.ToLower(item) {
+		case "export", "exports":
+			flows = append(flows, model.FlowExport)
+		case "import", "imports":
+			flows = append(flows, model.FlowImport)
+		default:
+			return nil, fmt.Errorf("unknown flow: %s", item)
+		}
+	}
+	return flows, nil
+}
@@ -0,0 +1,148 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/providers"
+	"tradegravity/internal/providers/comtrade"
+	"tradegravity/internal/providers/mock"
+	"tradegravity/internal/providers/wits"
+	"tradegravity/internal/store/sqlite"
+)
+
+func TestValidObservationsReportsEachViolation(t *testing.T) {
+	valid := model.Observation{
+		Provider: "comtrade", ProductCode: "TOTAL", ReporterISO3: "KOR", PartnerISO3: "USA",
+		Flow: model.FlowExport, PeriodType: model.PeriodMonth, Period: "2024-03", ValueUSD: 125.5,
+	}
+	world := valid
+	world.PartnerISO3, world.Flow, world.PeriodType, world.Period = "WLD", model.FlowTotal, model.PeriodQuarter, "2024-Q1"
+	union := valid
+	union.PartnerISO3 = "EUU"
+	broken := valid
+	broken.ReporterISO3, broken.PartnerISO3, broken.Flow, broken.Period, broken.ValueUSD = "XYZ", "EUN", "re-export", "202403", -1
+	huge := valid
+	huge.PartnerISO3, huge.PeriodType, huge.Period, huge.ValueUSD = "KOR", model.PeriodYear, "2024", model.MaxObservationUSD*2
+	notFinite := valid
+	notFinite.ValueUSD = math.NaN()
+
+	var runRecord model.IngestRun
+	kept := validObservations(&runRecord, []model.Observation{valid, broken, world, huge, union, notFinite})
+	if len(kept) != 3 || kept[0].PartnerISO3 != "USA" || kept[1].PartnerISO3 != "WLD" || kept[2].PartnerISO3 != "EUU" {
+		t.Fatalf("kept = %+v", kept)
+	}
+	if len(runRecord.Errors) != 8 {
+		t.Fatalf("errors = %q, want one per violation", runRecord.Errors)
+	}
+	for _, want := range []string{`unknown reporter "XYZ"`, `partner "EUN" is an alias of EUU`, `unknown flow "re-export"`, `period "202403" is not a canonical M period`, "value -1.00 is negative", "reporter and partner are the same country", "exceeds", "not finite"} {
+		if !strings.Contains(strings.Join(runRecord.Errors, "\n"), want) {
+			t.Fatalf("errors = %q, missing %q", runRecord.Errors, want)
+		}
+	}
+	if !strings.HasPrefix(runRecord.Errors[0], "comtrade XYZ/EUN re-export TOTAL M 202403: ") {
+		t.Fatalf("first error = %q, want the observation identified", runRecord.Errors[0])
+	}
+}
+
+func TestNewIngestRunRecordsBuild(t *testing.T) {
+	run := newIngestRun("Comtrade", "totals")
+	if run.Provider != "Comtrade" || run.Mode != "totals" || !strings.HasSuffix(run.RunID, "-comtrade-totals") {
+		t.Fatalf("run = %+v", run)
+	}
+	if run.CodeVersion == "" {
+		t.Fatalf("code version is empty, want the build version")
+	}
+	if run.StartedAt.IsZero() {
+		t.Fatalf("started at is zero")
+	}
+}
+
+func annual(provider, reporter, partner, period string, value float64) model.Observation {
+	return model.Observation{Provider: provider, ReporterISO3: reporter, PartnerISO3: partner, Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: period, ValueUSD: value}
+}
+
+func TestCollectObservationsSkipsStoredPeriods(t *testing.T) {
+	ctx := context.Background()
+	st, err := sqlite.New(filepath.Join(t.TempDir(), "tradegravity.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	if err := st.UpsertObservations(ctx, []model.Observation{annual("wits", "KOR", "USA", "2023", 90)}); err != nil {
+		t.Fatal(err)
+	}
+	provider := mock.New("wits")
+	provider.Respond(mock.Call{Method: mock.MethodFetchLatest}, mock.Response{Observations: []model.Observation{annual("wits", "KOR", "USA", "2024", 100)}})
+	provider.Respond(mock.Call{Method: mock.MethodFetchSeries}, mock.Response{Observations: []model.Observation{annual("wits", "KOR", "USA", "2023", 90), annual("wits", "KOR", "USA", "2024", 100)}})
+
+	pair := model.NewPair("KOR", "USA")
+	series, err := collectObservations(ctx, provider, st, "wits", pair, model.FlowExport, 1)
+	if err != nil || len(series) != 1 || series[0].Period != "2024" {
+		t.Fatalf("collectObservations() = %+v, %v, want only the unstored 2024", series, err)
+	}
+	if calls := provider.Calls(); len(calls) != 2 || calls[1].From != "2023" || calls[1].To != "2024" {
+		t.Fatalf("calls = %+v, want the latest then a 2023-2024 series", calls)
+	}
+
+	if err := st.UpsertObservations(ctx, series); err != nil {
+		t.Fatal(err)
+	}
+	if series, err := collectObservations(ctx, provider, st, "wits", pair, model.FlowExport, 1); err != nil || len(series) != 0 {
+		t.Fatalf("second collectObservations() = %+v, %v, want nothing new", series, err)
+	}
+}
+
+func TestCollectObservationsFallsBackToLatestWithoutHistory(t *testing.T) {
+	provider := mock.New("wits")
+	provider.Respond(mock.Call{Method: mock.MethodFetchLatest}, mock.Response{Observations: []model.Observation{annual("wits", "KOR", "USA", "2024", 100)}})
+	provider.Fail(mock.Call{Method: mock.MethodFetchSeries}, 0, wits.ErrNoRecords)
+	series, err := collectObservations(context.Background(), provider, nil, "wits", model.NewPair("KOR", "USA"), model.FlowExport, 2)
+	if err != nil || len(series) != 1 || series[0].Period != "2024" {
+		t.Fatalf("collectObservations() = %+v, %v, want the latest observation", series, err)
+	}
+
+	timeout := errors.New("upstream timeout")
+	provider.Fail(mock.Call{Method: mock.MethodFetchSeries}, 1, timeout)
+	if _, err := collectObservations(context.Background(), provider, nil, "wits", model.NewPair("KOR", "USA"), model.FlowExport, 2); !errors.Is(err, timeout) {
+		t.Fatalf("collectObservations() error = %v, want the series failure", err)
+	}
+}
+
+func TestCollectTotalsStoresTheRestAndReportsQuotaExhaustion(t *testing.T) {
+	provider := mock.New("comtrade",
+		model.Reporter{ISO3: "KOR", IsActive: true},
+		model.Reporter{ISO3: "VNM", IsActive: true},
+		model.Reporter{ISO3: "MEX", IsActive: true},
+	)
+	provider.Respond(mock.Call{Method: mock.MethodFetchLatest, Reporter: "KOR"}, mock.Response{Observations: []model.Observation{annual("comtrade", "KOR", "USA", "2024", 100)}})
+	provider.Fail(mock.Call{Method: mock.MethodFetchLatest, Reporter: "MEX"}, 0, comtrade.ErrNoRecords)
+	provider.Fail(mock.Call{Method: mock.MethodFetchLatest, Reporter: "VNM"}, 1, comtrade.ErrQuotaExceeded)
+	provider.Respond(mock.Call{Method: mock.MethodFetchLatest, Reporter: "VNM"}, mock.Response{Observations: []model.Observation{annual("comtrade", "VNM", "USA", "2024", 50)}})
+	defer func(previous func(string) (providers.Provider, error)) { buildProvider = previous }(buildProvider)
+	buildProvider = func(string) (providers.Provider, error) { return provider, nil }
+
+	dbPath := filepath.Join(t.TempDir(), "tradegravity.db")
+	run, err := CollectTotals("comtrade", "USA", "export", 0, "", "", dbPath, 0, 2, false)
+	if !errors.Is(err, comtrade.ErrQuotaExceeded) {
+		t.Fatalf("CollectTotals() error = %v, want the quota error", err)
+	}
+	if run.RequestCount != 3 || run.SuccessCount != 1 || run.FailureCount != 1 || run.SkippedCount != 1 || run.StoredCount != 1 || run.Status != "failed" {
+		t.Fatalf("run = %+v", run)
+	}
+
+	// The retry stores VNM, and KOR is not stored twice.
+	provider.Reset()
+	run, err = CollectTotals("comtrade", "USA", "export", 0, "", "", dbPath, 0, 2, false)
+	if err != nil || run.SuccessCount != 1 || run.StoredCount != 1 || run.SkippedCount != 2 {
+		t.Fatalf("retry run = %+v, %v", run, err)
+	}
+	if count := provider.Count(mock.Call{Method: mock.MethodFetchLatest}); count != 3 {
+		t.Fatalf("retry made %d latest calls, want 3", count)
+	}
+}
@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"tradegravity/internal/cli"
+	"tradegravity/internal/events"
+	"tradegravity/internal/model"
+	"tradegravity/internal/store"
+)
+
+// eventPublisher is the part of events.Publisher the collector uses.
+type eventPublisher interface {
+	Publish(ctx context.Context, events []events.Event) error
+	Close() error
+}
+
+// eventStore publishes an event for every observation an upsert inserts or
+// revises. Events are best effort: a failed publish is reported but does not
+// fail the collection, since the observations are already stored.
+type eventStore struct {
+	store.Store
+	recorder  store.ChangeRecorder
+	publisher eventPublisher
+	published int
+	failed    int
+}
+
+func (s *eventStore) UpsertObservations(ctx context.Context, observations []model.Observation) error {
+	changes, err := s.recorder.UpsertObservationChanges(ctx, observations)
+	if err != nil {
+		return err
+	}
+	batch := make([]events.Event, len(changes))
+	for index, change := range changes {
+		batch[index] = events.FromChange(change)
+	}
+	if err := s.publisher.Publish(ctx, batch); err != nil {
+		s.failed += len(batch)
+		fmt.Fprintf(os.Stderr, "warning: publish %d observation events: %v\n", len(batch), err)
+		return nil
+	}
+	s.published += len(batch)
+	return nil
+}
+
+func (s *eventStore) Close() error {
+	fmt.Printf("collector events published=%d failed=%d\n", s.published, s.failed)
+	return errors.Join(s.publisher.Close(), s.Store.Close())
+}
+
+// openStore opens the store at dbPath. When COLLECTOR_EVENTS names a NATS
+// target, such as nats://localhost:4222/tradegravity.observations, the store
+// also publishes an event for each observation it inserts or revises.
+func openStore(dbPath string) (store.Store, error) {
+	st, err := cli.OpenStore(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	value := strings.TrimSpace(os.Getenv("COLLECTOR_EVENTS"))
+	if value == "" {
+		return st, nil
+	}
+	recorder, ok := st.(store.ChangeRecorder)
+	if !ok {
+		st.Close()
+		return nil, errors.New("COLLECTOR_EVENTS needs a -db store to tell new observations from stored ones")
+	}
+	target, err := events.ParseTarget(value)
+	if err != nil {
+		st.Close()
+		return nil, fmt.Errorf("invalid COLLECTOR_EVENTS: %w", err)
+	}
+	publisher, err := events.Dial(context.Background(), target)
+	if err != nil {
+		st.Close()
+		return nil, fmt.Errorf("connect to %s: %w", target, err)
+	}
+	return &eventStore{Store: st, recorder: recorder, publisher: publisher}, nil
+}
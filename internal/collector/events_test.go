@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tradegravity/internal/events"
+	"tradegravity/internal/model"
+	"tradegravity/internal/store"
+)
+
+type fakeRecorder struct {
+	store.NopStore
+	changes []model.ObservationChange
+}
+
+func (r *fakeRecorder) UpsertObservationChanges(context.Context, []model.Observation) ([]model.ObservationChange, error) {
+	return r.changes, nil
+}
+
+type fakePublisher struct {
+	sent []events.Event
+	err  error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, batch []events.Event) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, batch...)
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func TestEventStorePublishesChangesAndKeepsRunningOnFailure(t *testing.T) {
+	observation := model.Observation{Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", ValueUSD: 125}
+	recorder := &fakeRecorder{changes: []model.ObservationChange{{Observation: observation}, {Observation: observation, Revised: true, PreviousUSD: 100}}}
+	publisher := &fakePublisher{}
+	st := &eventStore{Store: recorder, recorder: recorder, publisher: publisher}
+
+	if err := st.UpsertObservations(context.Background(), []model.Observation{observation}); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.sent) != 2 || publisher.sent[0].Type != events.EventInserted || publisher.sent[1].Type != events.EventRevised {
+		t.Fatalf("sent = %+v", publisher.sent)
+	}
+
+	publisher.err = errors.New("connection reset")
+	if err := st.UpsertObservations(context.Background(), []model.Observation{observation}); err != nil {
+		t.Fatalf("UpsertObservations() error = %v, want the failed publish only counted", err)
+	}
+	if st.published != 2 || st.failed != 2 {
+		t.Fatalf("published=%d failed=%d", st.published, st.failed)
+	}
+}
@@ -0,0 +1,163 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
+	"tradegravity/internal/model"
+)
+
+const (
+	defaultWorldBankURL = "https://api.worldbank.org/v2"
+	fxIndicator         = "PA.NUS.FCRF"
+)
+
+// fxEconomies maps supported currencies to the World Bank economy whose
+// official exchange rate (local currency units per USD, period average)
+// prices them. EUR uses the euro area aggregate.
+var fxEconomies = map[string]string{
+	"CNY": "CHN",
+	"EUR": "EMU",
+	"GBP": "GBR",
+	"JPY": "JPN",
+	"KRW": "KOR",
+}
+
+func runFX(args []string) {
+	fs := flag.NewFlagSet("fx", flag.ExitOnError)
+	currencies := fs.String("currencies", "KRW,EUR", "comma-separated currencies: "+strings.Join(fxCurrencies(), ", "))
+	years := fs.Int("years", 12, "number of past years to fetch")
+	baseURL := fs.String("base-url", defaultWorldBankURL, "World Bank API base URL")
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without collecting (0 = exit at once)")
+	timeout := fs.Duration("timeout", 30*time.Second, "request timeout")
+	config.Parse(fs, args)
+	defer cli.LockStore(*dbPath, *lockWait, "collector fx")()
+
+	stored, err := collectFXRates(cli.ParseList(*currencies), *years, *baseURL, *dbPath, *timeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fx collector failed:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("fx collection complete (currencies=%s rates=%d)\n", strings.ToUpper(*currencies), stored)
+}
+
+func fxCurrencies() []string {
+	currencies := make([]string, 0, len(fxEconomies))
+	for currency := range fxEconomies {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// collectFXRates stores annual World Bank exchange rates for currencies.
+func collectFXRates(currencies []string, years int, baseURL, dbPath string, timeout time.Duration) (int, error) {
+	if len(currencies) == 0 {
+		return 0, errors.New("no currencies provided")
+	}
+	economies := make(map[string]string, len(currencies))
+	for _, currency := range currencies {
+		currency = strings.ToUpper(currency)
+		economy, ok := fxEconomies[currency]
+		if !ok {
+			return 0, fmt.Errorf("unsupported currency %q (expected one of %s)", currency, strings.Join(fxCurrencies(), ", "))
+		}
+		economies[economy] = currency
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	rates, err := fetchFXRates(ctx, &http.Client{Timeout: timeout}, baseURL, economies, years)
+	if err != nil {
+		return 0, err
+	}
+	st, err := cli.OpenStore(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer st.Close()
+	if err := st.UpsertFXRates(context.Background(), rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+type wbFXRow struct {
+	CountryISO3 string   `json:"countryiso3code"`
+	Date        string   `json:"date"`
+	Value       *float64 `json:"value"`
+}
+
+// fetchFXRates reads the annual PA.NUS.FCRF values for economies, which maps
+// World Bank economy codes to the currency they price.
+func fetchFXRates(ctx context.Context, client *http.Client, baseURL string, economies map[string]string, years int) ([]model.FXRate, error) {
+	codes := make([]string, 0, len(economies))
+	for code := range economies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	currentYear := time.Now().UTC().Year()
+	query := url.Values{}
+	query.Set("format", "json")
+	query.Set("per_page", "5000")
+	query.Set("date", strconv.Itoa(currentYear-max(years, 1))+":"+strconv.Itoa(currentYear))
+	endpoint := strings.TrimRight(baseURL, "/") + "/country/" + strings.Join(codes, ";") + "/indicator/" + fxIndicator + "?" + query.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", "TradeGravity/0.2")
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("exchange rate request failed: %s", response.Status)
+	}
+	var envelope []json.RawMessage
+	if err := json.NewDecoder(response.Body).Decode(&envelope); err != nil {
+		return nil, err
+	}
+	if len(envelope) < 2 || string(envelope[1]) == "null" {
+		return nil, errors.New("exchange rate response did not include data rows")
+	}
+	var rows []wbFXRow
+	if err := json.Unmarshal(envelope[1], &rows); err != nil {
+		return nil, err
+	}
+
+	rates := make([]model.FXRate, 0, len(rows))
+	for _, row := range rows {
+		currency, ok := economies[strings.ToUpper(row.CountryISO3)]
+		if !ok || row.Value == nil || *row.Value <= 0 {
+			continue
+		}
+		if _, ok := model.ParseYear(row.Date); !ok {
+			continue
+		}
+		rates = append(rates, model.FXRate{
+			Currency:   currency,
+			PeriodType: model.PeriodYear,
+			Period:     row.Date,
+			RatePerUSD: *row.Value,
+			Source:     "worldbank:" + fxIndicator,
+		})
+	}
+	return rates, nil
+}
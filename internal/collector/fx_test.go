@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestFetchFXRatesMapsEconomiesToCurrencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/country/EMU;KOR/indicator/PA.NUS.FCRF") {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		w.Write([]byte(`[{"page":1},[
+			{"countryiso3code":"KOR","date":"2023","value":1305.41},
+			{"countryiso3code":"KOR","date":"2024","value":null},
+			{"countryiso3code":"EMU","date":"2023","value":0.9248}
+		]]`))
+	}))
+	defer server.Close()
+
+	rates, err := fetchFXRates(context.Background(), server.Client(), server.URL, map[string]string{"KOR": "KRW", "EMU": "EUR"}, 2)
+	if err != nil {
+		t.Fatalf("fetchFXRates() error = %v", err)
+	}
+	if len(rates) != 2 {
+		t.Fatalf("rates = %+v, want two non-null rates", rates)
+	}
+	want := model.FXRate{Currency: "KRW", PeriodType: model.PeriodYear, Period: "2023", RatePerUSD: 1305.41, Source: "worldbank:PA.NUS.FCRF"}
+	if rates[0] != want || rates[1].Currency != "EUR" {
+		t.Fatalf("rates = %+v, want KRW then EUR", rates)
+	}
+}
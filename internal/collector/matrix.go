@@ -1,4 +1,4 @@
-package main
+package collector
 
 import (
 	"context"
@@ -10,6 +10,8 @@ import (
 	"sync"
 	"time"
 
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
 	"tradegravity/internal/model"
 	"tradegravity/internal/providers"
 	"tradegravity/internal/providers/comtrade"
@@ -24,16 +26,24 @@ func runMatrix(args []string) {
 	limit := fs.Int("limit", 0, "limit number of reporters (0 = all)")
 	allowlistPath := fs.String("allowlist", "configs/allowlist.csv", "path to reporter allowlist")
 	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without collecting (0 = exit at once)")
 	concurrency := fs.Int("concurrency", 2, "maximum reporters collected concurrently")
+	specialPartners := fs.String("special-partners", "", "handling overrides for non-country partners, e.g. EUU=publish,OTH=store (publish, store, or drop)")
 	verbose := fs.Bool("verbose", false, "print collection progress")
-	fs.Parse(args)
-	if err := runMatrixCollector(*providerID, *primaryProvider, *year, *flowsCSV, *limit, *allowlistPath, *dbPath, *concurrency, *verbose); err != nil {
+	config.Parse(fs, args)
+	defer cli.LockStore(*dbPath, *lockWait, "collector matrix")()
+	policy, err := model.ParsePartnerPolicy(*specialPartners)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "matrix collector failed:", err)
+		os.Exit(1)
+	}
+	if err := runMatrixCollector(*providerID, *primaryProvider, *year, *flowsCSV, *limit, *allowlistPath, *dbPath, *concurrency, *verbose, policy); err != nil {
 		fmt.Fprintln(os.Stderr, "matrix collector failed:", err)
 		os.Exit(1)
 	}
 }
 
-func runMatrixCollector(providerID, primaryProvider, year, flowsCSV string, limit int, allowlistPath, dbPath string, concurrency int, verbose bool) (runErr error) {
+func runMatrixCollector(providerID, primaryProvider, year, flowsCSV string, limit int, allowlistPath, dbPath string, concurrency int, verbose bool, policy model.PartnerPolicy) (runErr error) {
 	baseProvider, err := buildProvider(providerID)
 	if err != nil {
 		return err
@@ -52,10 +62,7 @@ func runMatrixCollector(providerID, primaryProvider, year, flowsCSV string, limi
 		return err
 	}
 	defer st.Close()
-	runRecord := model.IngestRun{
-		RunID: newRunID(provider.Name(), "bilateral-matrix"), Provider: provider.Name(),
-		Mode: "bilateral-matrix", StartedAt: time.Now().UTC(),
-	}
+	runRecord := newIngestRun(provider.Name(), "bilateral-matrix")
 	defer func() {
 		runRecord.FinishedAt = time.Now().UTC()
 		runRecord.Status = ingestStatus(runRecord, runErr)
@@ -74,7 +81,7 @@ func runMatrixCollector(providerID, primaryProvider, year, flowsCSV string, limi
 			return err
 		}
 	}
-	if _, ok := parseYear(selectedYear); !ok {
+	if _, ok := model.ParseYear(selectedYear); !ok {
 		return fmt.Errorf("matrix year must be auto or four digits, got %q", selectedYear)
 	}
 	allowed, err := loadAllowlist(allowlistPath)
@@ -149,14 +156,16 @@ func runMatrixCollector(providerID, primaryProvider, year, flowsCSV string, limi
 		if persistErr != nil {
 			continue
 		}
-		if err := st.UpsertObservations(ctx, result.observations); err != nil {
+		observations, dropped := policy.Filter(result.observations)
+		observations = validObservations(&runRecord, observations)
+		if err := st.UpsertObservations(ctx, observations); err != nil {
 			persistErr = err
 			continue
 		}
 		runRecord.SuccessCount++
-		runRecord.StoredCount += len(result.observations)
+		runRecord.StoredCount += len(observations)
 		if verbose {
-			fmt.Printf("matrix reporter=%s flow=%s year=%s partners=%d\n", result.reporter, result.flow, selectedYear, len(result.observations))
+			fmt.Printf("matrix reporter=%s flow=%s year=%s partners=%d dropped=%d\n", result.reporter, result.flow, selectedYear, len(observations), dropped)
 		}
 	}
 	if persistErr != nil {
@@ -168,6 +177,9 @@ func runMatrixCollector(providerID, primaryProvider, year, flowsCSV string, limi
 	if runRecord.SuccessCount == 0 {
 		return errors.New("no matrix observations collected")
 	}
+	if err := refreshProviderDiscrepancies(ctx, st); err != nil {
+		return err
+	}
 	fmt.Printf("matrix collector complete (provider=%s year=%s reporters=%d requests=%d success=%d failed=%d observations=%d)\n",
 		provider.Name(), selectedYear, len(reporters), runRecord.RequestCount, runRecord.SuccessCount, runRecord.FailureCount, runRecord.StoredCount)
 	return nil
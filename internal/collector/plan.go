@@ -0,0 +1,209 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
+	"tradegravity/internal/iso"
+	"tradegravity/internal/model"
+	"tradegravity/internal/providers/comtrade"
+	"tradegravity/internal/providers/wits"
+	"tradegravity/internal/store"
+)
+
+// defaultDailyQuota is the number of upstream data calls a provider accepts per
+// day without a subscription. Zero means the provider publishes no quota.
+var defaultDailyQuota = map[string]int{
+	"comtrade": 500,
+	"wits":     0,
+}
+
+// collectionPlan estimates the upstream cost of one `collector run` without
+// contacting a provider. Request counts mirror collectObservations: every
+// series asks for the latest point and, with history enabled, a year range.
+type collectionPlan struct {
+	Provider          string
+	Frequency         string
+	ReporterCount     int
+	SeriesCount       int
+	SkippedCount      int
+	UpToDateCount     int
+	RequestsPerSeries int
+	LookupRequests    int
+	RequestCount      int
+	DailyQuota        int
+}
+
+func (p collectionPlan) ExceedsQuota() bool {
+	return p.DailyQuota > 0 && p.RequestCount > p.DailyQuota
+}
+
+func runPlan(args []string) {
+	fs := flag.NewFlagSet("plan", flag.ExitOnError)
+	providersCSV := fs.String("provider", "wits", "comma-separated provider ids to estimate")
+	partners := fs.String("partners", "USA,CHN", "comma-separated partner ISO3 list")
+	flows := fs.String("flows", "export,import", "comma-separated flows")
+	limit := fs.Int("limit", 0, "limit number of reporters (0 = all)")
+	allowlist := fs.String("allowlist", "configs/allowlist.csv", "path to allowlist file")
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path (empty ignores stored observations)")
+	historyYears := fs.Int("history-years", 1, "number of previous years to fetch for growth (0 = latest only)")
+	dailyQuota := fs.Int("daily-quota", -1, "daily upstream request quota (-1 = provider default, 0 = unlimited)")
+	world := fs.Bool("world", true, "include the WLD (world) partner collected by run")
+	config.Parse(fs, args)
+
+	if *world {
+		*partners = WithWorld(*partners)
+	}
+
+	plans, err := buildCollectionPlans(cli.ParseList(*providersCSV), *partners, *flows, *limit, *allowlist, *dbPath, *historyYears, *dailyQuota)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "collector plan failed:", err)
+		os.Exit(1)
+	}
+	for _, plan := range plans {
+		fmt.Printf("plan provider=%s frequency=%s reporters=%d series=%d skipped=%d up_to_date=%d requests_per_series=%d lookups=%d requests=%d quota=%d\n",
+			plan.Provider, plan.Frequency, plan.ReporterCount, plan.SeriesCount, plan.SkippedCount, plan.UpToDateCount,
+			plan.RequestsPerSeries, plan.LookupRequests, plan.RequestCount, plan.DailyQuota)
+		if plan.ExceedsQuota() {
+			fmt.Fprintf(os.Stderr, "warning: provider %s plan needs %d requests, above the daily quota of %d\n", plan.Provider, plan.RequestCount, plan.DailyQuota)
+		}
+	}
+}
+
+func buildCollectionPlans(providerIDs []string, partnersCSV, flowsCSV string, limit int, allowlistPath, dbPath string, historyYears, dailyQuota int) ([]collectionPlan, error) {
+	if len(providerIDs) == 0 {
+		return nil, errors.New("no providers provided")
+	}
+	if historyYears < 0 {
+		return nil, fmt.Errorf("history-years must be non-negative, got %d", historyYears)
+	}
+	allowed, err := loadAllowlist(allowlistPath)
+	if err != nil {
+		return nil, err
+	}
+	reporters := reportersFromAllowlist(allowed)
+	sort.Slice(reporters, func(i, j int) bool { return reporters[i].ISO3 < reporters[j].ISO3 })
+	if limit > 0 && len(reporters) > limit {
+		reporters = reporters[:limit]
+	}
+	partners, err := iso.ParseList(partnersCSV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid partners: %w", err)
+	}
+	if len(partners) == 0 {
+		return nil, errors.New("no partners provided")
+	}
+	flows, err := parseFlows(flowsCSV)
+	if err != nil {
+		return nil, err
+	}
+	st, err := cli.OpenStore(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	plans := make([]collectionPlan, 0, len(providerIDs))
+	for _, providerID := range providerIDs {
+		providerID = strings.ToLower(providerID)
+		plan, err := planProvider(providerID, len(reporters), historyYears, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		plan.DailyQuota = dailyQuota
+		if dailyQuota < 0 {
+			plan.DailyQuota = defaultDailyQuota[providerID]
+		}
+		anchor, _ := st.DominantAnnualPeriod(ctx, providerID)
+		window := historyRange(anchor, historyYears)
+		for _, reporter := range reporters {
+			for _, partner := range partners {
+				pair := model.NewPair(reporter.ISO3, partner)
+				for _, flow := range flows {
+					if pair.SameCountry() {
+						plan.SkippedCount++
+						continue
+					}
+					plan.SeriesCount++
+					complete, err := storedWindowComplete(ctx, st, providerID, pair, flow, window)
+					if err != nil {
+						return nil, err
+					}
+					if complete {
+						plan.UpToDateCount++
+					}
+				}
+			}
+		}
+		plan.RequestCount = plan.SeriesCount*plan.RequestsPerSeries + plan.LookupRequests
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// planProvider returns the per-series request cost of a provider using the
+// same environment configuration as a real collection.
+func planProvider(providerID string, reporterCount, historyYears int, now time.Time) (collectionPlan, error) {
+	plan := collectionPlan{Provider: providerID, ReporterCount: reporterCount, Frequency: "A"}
+	switch providerID {
+	case "wits":
+		cfg, err := wits.ConfigFromEnv()
+		if err != nil {
+			return plan, err
+		}
+		plan.RequestsPerSeries = 1
+		if historyYears > 0 {
+			plan.RequestsPerSeries++
+		}
+		if cfg.AutoLatestYear {
+			// Availability lookups are cached per reporter and indicator, and
+			// each indicator maps to one flow.
+			plan.LookupRequests = reporterCount * 2
+		}
+	case "comtrade":
+		cfg, err := comtrade.ConfigFromEnv()
+		if err != nil {
+			return plan, err
+		}
+		plan.Frequency = strings.ToUpper(cfg.Frequency)
+		lookback := cfg.LookbackYears
+		if lookback <= 0 {
+			lookback = 5
+		}
+		// FetchLatest walks the lookback window one year per call and the
+		// history range repeats that for every requested year.
+		plan.RequestsPerSeries = min(lookback, now.Year()) + 1
+		if historyYears > 0 {
+			plan.RequestsPerSeries += historyYears + 1
+		}
+		// Reporter and partner reference files are loaded once per run.
+		plan.LookupRequests = 2
+	default:
+		return plan, fmt.Errorf("unknown provider: %s", providerID)
+	}
+	return plan, nil
+}
+
+func storedWindowComplete(ctx context.Context, st store.Store, providerID string, pair model.Pair, flow model.Flow, window model.PeriodRange) (bool, error) {
+	if window.Len() == 0 {
+		return false, nil
+	}
+	keys, err := existingObservationKeys(ctx, st, providerID, pair, flow)
+	if err != nil {
+		return false, err
+	}
+	gaps := window.Gaps(func(period string) bool {
+		_, ok := keys[observationKey(window.Type, period)]
+		return ok
+	})
+	return len(gaps) == 0, nil
+}
@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tradegravity/internal/model"
+)
+
+func TestPlanProviderCountsLatestAndHistoryRequests(t *testing.T) {
+	t.Setenv("COMTRADE_LOOKBACK_YEARS", "3")
+	t.Setenv("WITS_AUTO_LATEST_YEAR", "true")
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	comtradePlan, err := planProvider("comtrade", 4, 2, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comtradePlan.RequestsPerSeries != 4+3 || comtradePlan.LookupRequests != 2 {
+		t.Fatalf("comtrade plan = %+v", comtradePlan)
+	}
+	witsPlan, err := planProvider("wits", 4, 0, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if witsPlan.RequestsPerSeries != 1 || witsPlan.LookupRequests != 8 {
+		t.Fatalf("wits plan = %+v", witsPlan)
+	}
+	if _, err := planProvider("unknown", 1, 0, now); err == nil {
+		t.Fatal("planProvider accepted an unknown provider")
+	}
+}
+
+func TestBuildCollectionPlansWarnsAboveQuota(t *testing.T) {
+	t.Setenv("COMTRADE_LOOKBACK_YEARS", "5")
+	allowlist := filepath.Join(t.TempDir(), "allowlist.csv")
+	if err := os.WriteFile(allowlist, []byte("iso3\nKOR\nJPN\nUSA\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plans, err := buildCollectionPlans([]string{"COMTRADE"}, "USA,CHN", "export,import", 0, allowlist, "", 1, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("plans = %+v, want one provider", plans)
+	}
+	plan := plans[0]
+	if plan.Provider != "comtrade" || plan.SeriesCount != 10 || plan.SkippedCount != 2 {
+		t.Fatalf("plan series = %+v", plan)
+	}
+	if plan.RequestCount != 10*plan.RequestsPerSeries+2 || !plan.ExceedsQuota() {
+		t.Fatalf("plan budget = %+v", plan)
+	}
+}
+
+func TestPairCanonicalizesAndValidates(t *testing.T) {
+	pair := model.NewPair(" kor", "usa ")
+	if pair.Reporter != "KOR" || pair.Partner != "USA" || pair.String() != "KOR/USA" || pair.Mirror() != model.NewPair("USA", "KOR") {
+		t.Fatalf("pair = %+v (%s)", pair, pair)
+	}
+	if err := pair.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := model.NewPair("KOR", "wld").Validate(); err != nil {
+		t.Fatalf("world partner rejected: %v", err)
+	}
+	for _, invalid := range []model.Pair{model.NewPair("WLD", "KOR"), model.NewPair("KOR", "XXX"), model.NewPair("kor", "KOR")} {
+		if err := invalid.Validate(); err == nil {
+			t.Fatalf("Validate accepted %s", invalid)
+		}
+	}
+	if !model.NewPair("kor", "KOR").SameCountry() {
+		t.Fatal("SameCountry missed a reporter paired with itself")
+	}
+}
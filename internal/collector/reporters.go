@@ -0,0 +1,105 @@
+package collector
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"tradegravity/internal/iso"
+	"tradegravity/internal/model"
+)
+
+// loadCountryNames reads the iso3, name, and name_ko columns of the country
+// metadata CSV. A missing file or path yields no names.
+func loadCountryNames(path string) (map[string]model.Reporter, error) {
+	names := make(map[string]model.Reporter)
+	if strings.TrimSpace(path) == "" {
+		return names, nil
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return names, nil
+	}
+	header := make(map[string]int)
+	for index, value := range records[0] {
+		header[strings.ToLower(strings.TrimSpace(value))] = index
+	}
+	cell := func(record []string, name string) string {
+		index, ok := header[name]
+		if !ok || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+	for _, record := range records[1:] {
+		iso3 := strings.ToUpper(cell(record, "iso3"))
+		if iso3 == "" {
+			continue
+		}
+		names[iso3] = model.Reporter{ISO3: iso3, NameEN: cell(record, "name"), NameKO: cell(record, "name_ko")}
+	}
+	return names, nil
+}
+
+// applyCountryNames fills reporter names the provider left empty, or set to
+// the bare ISO3 code, from names and then from the ISO 3166-1 list. Korean
+// names always come from those two.
+func applyCountryNames(reporters []model.Reporter, names map[string]model.Reporter) []model.Reporter {
+	output := make([]model.Reporter, len(reporters))
+	for index, reporter := range reporters {
+		if name, ok := names[strings.ToUpper(reporter.ISO3)]; ok {
+			if reporter.NameEN == "" || strings.EqualFold(reporter.NameEN, reporter.ISO3) {
+				reporter.NameEN = name.NameEN
+			}
+			if reporter.NameKO == "" {
+				reporter.NameKO = name.NameKO
+			}
+		}
+		if country, ok := iso.Lookup(reporter.ISO3); ok {
+			if reporter.NameEN == "" || strings.EqualFold(reporter.NameEN, reporter.ISO3) {
+				reporter.NameEN = country.NameEN
+			}
+			if reporter.NameKO == "" {
+				reporter.NameKO = country.NameKO
+			}
+		}
+		output[index] = reporter
+	}
+	return output
+}
+
+// applyCountryClassification fills the region, subregion, and income group
+// the provider left empty from the bundled World Bank and UN M49
+// classification.
+func applyCountryClassification(reporters []model.Reporter) []model.Reporter {
+	output := make([]model.Reporter, len(reporters))
+	for index, reporter := range reporters {
+		if classification, ok := iso.Classify(reporter.ISO3); ok {
+			if reporter.Region == "" {
+				reporter.Region = classification.Region
+			}
+			if reporter.Subregion == "" {
+				reporter.Subregion = classification.Subregion
+			}
+			if reporter.IncomeGroup == "" {
+				reporter.IncomeGroup = classification.IncomeGroup
+			}
+		}
+		output[index] = reporter
+	}
+	return output
+}
@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestApplyCountryNamesFillsMissingNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "countries.csv")
+	body := "iso3,iso2,name,name_ko,groups\nKOR,KR,Korea,대한민국,\nJPN,JP,Japan,일본,\n"
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	names, err := loadCountryNames(path)
+	if err != nil {
+		t.Fatalf("loadCountryNames() error = %v", err)
+	}
+
+	reporters := applyCountryNames([]model.Reporter{
+		{ISO3: "KOR", NameEN: "Korea, Rep."},
+		{ISO3: "JPN", NameEN: "JPN"},
+		{ISO3: "DEU", NameEN: "Germany"},
+		{ISO3: "XKX", NameEN: "XKX"},
+	}, names)
+	if reporters[0].NameEN != "Korea, Rep." || reporters[0].NameKO != "대한민국" {
+		t.Fatalf("KOR = %+v, want provider English name and CSV Korean name", reporters[0])
+	}
+	if reporters[1].NameEN != "Japan" || reporters[1].NameKO != "일본" {
+		t.Fatalf("JPN = %+v, want CSV names replacing bare ISO3", reporters[1])
+	}
+	if reporters[2].NameEN != "Germany" || reporters[2].NameKO != "독일" {
+		t.Fatalf("DEU = %+v, want the ISO Korean name when the CSV has none", reporters[2])
+	}
+	if reporters[3].NameEN != "XKX" || reporters[3].NameKO != "" {
+		t.Fatalf("XKX = %+v, want no names outside ISO 3166-1", reporters[3])
+	}
+}
+
+func TestLoadCountryNamesAllowsMissingFile(t *testing.T) {
+	names, err := loadCountryNames(filepath.Join(t.TempDir(), "missing.csv"))
+	if err != nil || len(names) != 0 {
+		t.Fatalf("loadCountryNames() = %v, %v, want no names", names, err)
+	}
+}
+
+func TestLoadAllowlistNormalizesAndRejectsUnknownCodes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "allowlist.csv")
+	if err := os.WriteFile(path, []byte("iso3\nkor # Korea\nvnm;JPN\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	allowed, err := loadAllowlist(path)
+	if err != nil {
+		t.Fatalf("loadAllowlist() error = %v", err)
+	}
+	for _, iso3 := range []string{"KOR", "VNM", "JPN"} {
+		if _, ok := allowed[iso3]; !ok || len(allowed) != 3 {
+			t.Fatalf("loadAllowlist() = %v, want KOR, VNM, and JPN", allowed)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.csv")
+	if err := os.WriteFile(bad, []byte("iso3\nKOR\nKOREA\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadAllowlist(bad); err == nil || !strings.Contains(err.Error(), "bad.csv:3") {
+		t.Fatalf("loadAllowlist() error = %v, want the line of the unknown code", err)
+	}
+}
+
+func TestApplyCountryClassificationKeepsProviderRegions(t *testing.T) {
+	got := applyCountryClassification([]model.Reporter{
+		{ISO3: "KOR", Region: "Asia"},
+		{ISO3: "MEX"},
+		{ISO3: "XKX"},
+	})
+	if got[0].Region != "Asia" || got[0].Subregion != "Eastern Asia" || got[0].IncomeGroup != "High income" {
+		t.Fatalf("KOR = %+v, want the provider region with bundled subregion and income group", got[0])
+	}
+	if got[1].Region != "Latin America & Caribbean" || got[1].Subregion != "Central America" || got[1].IncomeGroup != "Upper middle income" {
+		t.Fatalf("MEX = %+v, want the bundled classification", got[1])
+	}
+	if got[2].Region != "" || got[2].Subregion != "" || got[2].IncomeGroup != "" {
+		t.Fatalf("XKX = %+v, want no classification outside ISO 3166-1", got[2])
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package collector
 
 import (
 	"flag"
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
 	"tradegravity/internal/strategic"
 )
 
@@ -22,9 +24,11 @@ func runStrategic(args []string) {
 	limit := fs.Int("limit", 0, "limit number of reporters (0 = all)")
 	allowlist := fs.String("allowlist", "configs/allowlist.csv", "path to allowlist file")
 	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without collecting (0 = exit at once)")
 	concurrency := fs.Int("concurrency", 6, "maximum reporters collected concurrently")
 	verbose := fs.Bool("verbose", false, "print collection progress")
-	fs.Parse(args)
+	config.Parse(fs, args)
+	defer cli.LockStore(*dbPath, *lockWait, "collector strategic")()
 
 	registry, err := strategic.LoadCSV(*registryPath)
 	if err != nil {
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"tradegravity/internal/model"
+)
+
+func TestAnnualHistoryIncludesSelectedYearAndRequestedWindow(t *testing.T) {
+	want := []string{"2019", "2020", "2021", "2022", "2023"}
+	if got := annualHistory("2023", 4); !reflect.DeepEqual(got, want) {
+		t.Fatalf("annualHistory() = %v, want %v", got, want)
+	}
+	if got := annualHistory("latest", 4); got != nil {
+		t.Fatalf("annualHistory invalid year = %v, want nil", got)
+	}
+}
+
+func TestHistoryRangeFindsStoredGaps(t *testing.T) {
+	window := historyRange("2023", 4)
+	if window.From != "2019" || window.To != "2023" || window.Len() != 5 {
+		t.Fatalf("historyRange() = %+v", window)
+	}
+	if got := historyRange("0002", 5); got.From != "0000" || got.Len() != 3 {
+		t.Fatalf("historyRange near year zero = %+v", got)
+	}
+	stored := map[string]bool{"2019": true, "2022": true}
+	gaps := window.Gaps(func(period string) bool { return stored[period] })
+	want := []model.PeriodRange{
+		{Type: model.PeriodYear, From: "2020", To: "2021"},
+		{Type: model.PeriodYear, From: "2023", To: "2023"},
+	}
+	if !reflect.DeepEqual(gaps, want) {
+		t.Fatalf("gaps = %+v, want %+v", gaps, want)
+	}
+
+	months, err := model.NewPeriodRange(model.PeriodMonth, "202311", "2024-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := months.Periods(); !reflect.DeepEqual(got, []string{"2023-11", "2023-12", "2024-01", "2024-02"}) {
+		t.Fatalf("months = %v", got)
+	}
+	other, _ := model.PeriodRangeEnding(model.PeriodMonth, "2024-06", 6)
+	if overlap, ok := months.Intersect(other); !ok || overlap.From != "2024-01" || overlap.To != "2024-02" {
+		t.Fatalf("intersection = %+v, %v", overlap, ok)
+	}
+	if _, ok := months.Intersect(window); ok {
+		t.Fatal("ranges of different types intersected")
+	}
+	if _, err := model.NewPeriodRange(model.PeriodYear, "2024", "2023"); err == nil {
+		t.Fatal("NewPeriodRange accepted a reversed range")
+	}
+}
+
+func TestMonthlyWindowUsesCompleteMonthsInAscendingOrder(t *testing.T) {
+	want := []string{"2025-11", "2025-12", "2026-01"}
+	got, err := monthlyWindow("auto", 3, time.Date(2026, 2, 16, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("monthlyWindow() = %v, want %v", got, want)
+	}
+	if _, err := monthlyWindow("2026-13", 3, time.Now()); err == nil {
+		t.Fatal("monthlyWindow accepted an invalid month")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package collector
 
 import (
 	"context"
@@ -10,6 +10,9 @@ import (
 	"sync"
 	"time"
 
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
+	"tradegravity/internal/iso"
 	"tradegravity/internal/model"
 	"tradegravity/internal/providers"
 	"tradegravity/internal/providers/trains"
@@ -27,9 +30,11 @@ func runTariffs(args []string) {
 	limit := fs.Int("limit", 0, "limit number of importers (0 = all)")
 	allowlistPath := fs.String("allowlist", "configs/allowlist.csv", "path to importer allowlist")
 	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without collecting (0 = exit at once)")
 	concurrency := fs.Int("concurrency", 3, "maximum importers collected concurrently")
 	verbose := fs.Bool("verbose", false, "print collection progress")
-	fs.Parse(args)
+	config.Parse(fs, args)
+	defer cli.LockStore(*dbPath, *lockWait, "collector tariffs")()
 
 	registry, err := strategic.LoadCSV(*registryPath)
 	if err != nil {
@@ -61,27 +66,27 @@ func runTariffCollector(providerID, year string, codes []string, partnersCSV str
 	if len(codes) == 0 {
 		return errors.New("no tariff product codes selected")
 	}
-	partners := parseList(partnersCSV)
+	partners, err := iso.ParseList(partnersCSV)
+	if err != nil {
+		return fmt.Errorf("invalid tariff partners: %w", err)
+	}
 	if len(partners) == 0 {
 		return errors.New("no tariff partners provided")
 	}
 	requestedYear := strings.TrimSpace(year)
 	if !strings.EqualFold(requestedYear, "auto") {
-		if _, ok := parseYear(requestedYear); !ok {
+		if _, ok := model.ParseYear(requestedYear); !ok {
 			return fmt.Errorf("tariff year must be auto or four digits, got %q", requestedYear)
 		}
 	}
 
 	ctx := context.Background()
-	st, err := openStore(dbPath)
+	st, err := cli.OpenStore(dbPath)
 	if err != nil {
 		return err
 	}
 	defer st.Close()
-	runRecord := model.IngestRun{
-		RunID: newRunID(provider.Name(), "tariffs-strategic-hs6"), Provider: provider.Name(),
-		Mode: "tariffs-strategic-hs6", StartedAt: time.Now().UTC(),
-	}
+	runRecord := newIngestRun(provider.Name(), "tariffs-strategic-hs6")
 	defer func() {
 		runRecord.FinishedAt = time.Now().UTC()
 		runRecord.Status = ingestStatus(runRecord, runErr)
@@ -140,7 +145,7 @@ func runTariffCollector(providerID, year string, codes []string, partnersCSV str
 					selectedYear = resolved
 				}
 				for _, partner := range partners {
-					if strings.EqualFold(reporter.ISO3, partner) {
+					if model.NewPair(reporter.ISO3, partner).SameCountry() {
 						results <- tariffResult{importer: reporter.ISO3, exporter: partner, year: selectedYear}
 						continue
 					}
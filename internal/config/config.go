@@ -0,0 +1,302 @@
+// Package config resolves command flags from one documented set of sources,
+// so the collector, publisher, and server commands are configured the same
+// way. A flag takes, from highest to lowest precedence:
+//
+//  1. the value given on the command line;
+//  2. the TRADEGRAVITY_<NAME> environment variable, with the flag name
+//     upper-cased and dashes turned into underscores (-history-years reads
+//     TRADEGRAVITY_HISTORY_YEARS);
+//  3. the config file, from the command's section, then its shared flags;
+//  4. the flag default.
+//
+// The config file is the -config flag, else TRADEGRAVITY_CONFIG, else
+// tradegravity.json in the working directory when it exists. It is JSON:
+//
+//	{
+//	  "flags": {"db": "data/tradegravity.db"},
+//	  "commands": {"serve": {"addr": ":8080", "metrics": true}},
+//	  "env": {"WITS_RATE_LIMIT_PER_SEC": "4"}
+//	}
+//
+// "flags" apply to every command that has the flag. "commands" sections are
+// keyed by the command's flag set name, as config print shows it, and must
+// only name flags the command has. "env" sets provider and other environment
+// variables that are not already set, so they follow the same precedence.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// EnvPrefix starts the environment variable for each flag.
+	EnvPrefix = "TRADEGRAVITY_"
+	// DefaultFile is read from the working directory when no file is named.
+	DefaultFile = "tradegravity.json"
+)
+
+// Sources of a resolved value, from highest to lowest precedence.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// Setting is one resolved flag or file environment variable.
+type Setting struct {
+	Name   string
+	Value  string
+	Source string
+	// Env marks an environment variable set from the file's "env" section.
+	Env bool
+}
+
+// File is the decoded config file.
+type File struct {
+	Flags    map[string]any            `json:"flags"`
+	Commands map[string]map[string]any `json:"commands"`
+	Env      map[string]string         `json:"env"`
+}
+
+var printOnly bool
+
+// PrintOnly makes the next Parse print the resolved settings and exit
+// instead of returning to the command.
+func PrintOnly() {
+	printOnly = true
+}
+
+// Parse adds -config to fs, parses args, and fills every flag not given on
+// the command line from the environment or the config file. An invalid
+// config file exits with status 2, as an invalid flag does.
+func Parse(fs *flag.FlagSet, args []string) {
+	path := fs.String("config", "", "JSON config file (default $"+EnvPrefix+"CONFIG, or ./"+DefaultFile+" when present)")
+	fs.Parse(args)
+	settings, err := Resolve(fs, *path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Name(), err)
+		os.Exit(2)
+	}
+	if printOnly {
+		Print(os.Stdout, fs.Name(), settings)
+		os.Exit(0)
+	}
+}
+
+// Resolve applies the environment and the config file at path to the flags
+// of a parsed fs, and returns every flag with its value and source.
+func Resolve(fs *flag.FlagSet, path string) ([]Setting, error) {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	sources := map[string]string{"config": SourceDefault}
+	if explicit["config"] {
+		sources["config"] = SourceFlag
+	} else if value, ok := lookupEnv("config"); ok {
+		path = value
+		if err := fs.Set("config", value); err != nil {
+			return nil, err
+		}
+		sources["config"] = SourceEnv
+	}
+	file, path, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	section := file.Commands[fs.Name()]
+	for name := range section {
+		if fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("config %s: command %q has no -%s flag", path, fs.Name(), name)
+		}
+	}
+
+	var failed error
+	fs.VisitAll(func(f *flag.Flag) {
+		switch {
+		case failed != nil || f.Name == "config":
+		case explicit[f.Name]:
+			sources[f.Name] = SourceFlag
+		default:
+			value, source, ok := lookup(f.Name, section, file.Flags)
+			if !ok {
+				sources[f.Name] = SourceDefault
+				return
+			}
+			if err := fs.Set(f.Name, value); err != nil {
+				failed = fmt.Errorf("invalid %s value %q for -%s: %w", source, value, f.Name, err)
+				return
+			}
+			sources[f.Name] = source
+		}
+	})
+	if failed != nil {
+		return nil, failed
+	}
+
+	var settings []Setting
+	fs.VisitAll(func(f *flag.Flag) {
+		setting := Setting{Name: f.Name, Value: f.Value.String(), Source: sources[f.Name]}
+		if f.Name == "config" {
+			setting.Value = path
+		}
+		settings = append(settings, setting)
+	})
+	names := make([]string, 0, len(file.Env))
+	for name := range file.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		setting := Setting{Name: name, Source: SourceEnv, Env: true}
+		if value, ok := os.LookupEnv(name); ok {
+			setting.Value = value
+		} else {
+			setting.Value, setting.Source = file.Env[name], SourceFile
+			if err := os.Setenv(name, setting.Value); err != nil {
+				return nil, err
+			}
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// lookup finds a flag value in the environment, then the command section,
+// then the shared flags.
+func lookup(name string, section, shared map[string]any) (string, string, bool) {
+	if value, ok := lookupEnv(name); ok {
+		return value, SourceEnv, true
+	}
+	for _, values := range []map[string]any{section, shared} {
+		if value, ok := values[name]; ok {
+			return fileValue(value), SourceFile, true
+		}
+	}
+	return "", "", false
+}
+
+func lookupEnv(name string) (string, bool) {
+	return os.LookupEnv(EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_")))
+}
+
+// fileValue formats a JSON value as flag text. Lists are joined with commas,
+// as list flags expect.
+func fileValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case []any:
+		items := make([]string, len(value))
+		for index, item := range value {
+			items[index] = fileValue(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		body, _ := json.Marshal(value)
+		return string(body)
+	}
+}
+
+// load reads the config file at path and returns it with the path it read.
+// With no path, DefaultFile is read if it exists.
+func load(path string) (File, string, error) {
+	named := path != ""
+	if !named {
+		path = DefaultFile
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if !named && errors.Is(err, fs.ErrNotExist) {
+			return File{}, "", nil
+		}
+		return File{}, "", fmt.Errorf("read config: %w", err)
+	}
+	var file File
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return File{}, "", fmt.Errorf("decode config %s: %w", path, err)
+	}
+	return file, path, nil
+}
+
+// Print writes one name=value line per setting with its source. Values that
+// look like credentials are masked: those of flags and environment variables
+// named like secrets, and the credentials in URLs, as maskURL describes.
+func Print(w io.Writer, command string, settings []Setting) {
+	fmt.Fprintf(w, "# %s\n", command)
+	for _, setting := range settings {
+		name := "-" + setting.Name
+		if setting.Env {
+			name = setting.Name
+		}
+		fmt.Fprintf(w, "%s=%s (%s)\n", name, maskValue(setting.Name, setting.Value), setting.Source)
+	}
+}
+
+const masked = "********"
+
+// maskValue masks value whole when name looks like a secret, and otherwise
+// masks each URL in the comma-separated value with maskURL.
+func maskValue(name, value string) string {
+	if value == "" {
+		return value
+	}
+	if secretName(name) {
+		return masked
+	}
+	items := strings.Split(value, ",")
+	for index, item := range items {
+		items[index] = maskURL(item)
+	}
+	return strings.Join(items, ",")
+}
+
+// maskURL keeps only the scheme and host of an http or https URL, masking
+// its user info, path, and query, since webhook URLs such as -post-to carry
+// their token in any of them. Other URLs, such as s3:// or sftp:// targets,
+// only have a password masked. Anything that is not a URL is returned as is.
+func maskURL(value string) string {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return value
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		if _, ok := parsed.User.Password(); ok {
+			return parsed.Scheme + "://" + parsed.User.Username() + ":" + masked + "@" + parsed.Host + parsed.EscapedPath()
+		}
+		return value
+	}
+	tail := strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" || parsed.Fragment != ""
+	if parsed.User == nil && !tail {
+		return value
+	}
+	result := parsed.Scheme + "://"
+	if parsed.User != nil {
+		result += masked + "@"
+	}
+	result += parsed.Host
+	if tail {
+		result += "/" + masked
+	}
+	return result
+}
+
+func secretName(name string) bool {
+	for _, suffix := range []string{"KEY", "SECRET", "TOKEN", "PASSWORD"} {
+		if strings.HasSuffix(strings.ToUpper(name), suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+// Package events publishes observation events so other systems can react to
+// fresh trade data as the collector stores it. Events go to a NATS subject
+// over the plain NATS client protocol, so no client library is needed.
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"tradegravity/internal/model"
+)
+
+// DefaultSubject is the subject events go to when the target URL names none.
+const DefaultSubject = "tradegravity.observations"
+
+const (
+	// EventInserted is an observation stored for the first time.
+	EventInserted = "inserted"
+	// EventRevised is a stored observation whose value changed.
+	EventRevised = "revised"
+)
+
+// Event is the JSON message published for one observation change.
+// PreviousValueUSD is set only for revisions.
+type Event struct {
+	Type             string           `json:"type"`
+	Provider         string           `json:"provider"`
+	Classification   string           `json:"classification,omitempty"`
+	ProductCode      string           `json:"product_code"`
+	ReporterISO3     string           `json:"reporter_iso3"`
+	PartnerISO3      string           `json:"partner_iso3"`
+	Flow             model.Flow       `json:"flow"`
+	PeriodType       model.PeriodType `json:"period_type"`
+	Period           string           `json:"period"`
+	ValueUSD         float64          `json:"value_usd"`
+	PreviousValueUSD *float64         `json:"previous_value_usd,omitempty"`
+	Estimated        bool             `json:"estimated,omitempty"`
+	IngestedAt       string           `json:"ingested_at"`
+}
+
+// FromChange builds the event for one stored change.
+func FromChange(change model.ObservationChange) Event {
+	observation := change.Observation
+	event := Event{
+		Type:           EventInserted,
+		Provider:       observation.Provider,
+		Classification: observation.Classification,
+		ProductCode:    observation.ProductCode,
+		ReporterISO3:   observation.ReporterISO3,
+		PartnerISO3:    observation.PartnerISO3,
+		Flow:           observation.Flow,
+		PeriodType:     observation.PeriodType,
+		Period:         observation.Period,
+		ValueUSD:       observation.ValueUSD,
+		Estimated:      observation.Estimated,
+		IngestedAt:     observation.IngestedAt.UTC().Format(time.RFC3339),
+	}
+	if change.Revised {
+		previous := change.PreviousUSD
+		event.Type, event.PreviousValueUSD = EventRevised, &previous
+	}
+	return event
+}
+
+// Target is a parsed nats://[user:password@]host[:port][/subject] URL. A
+// user name without a password is sent as an auth token.
+type Target struct {
+	Address  string
+	Subject  string
+	User     string
+	Password string
+	Token    string
+}
+
+// ParseTarget accepts nats:// URLs. The port defaults to 4222.
+func ParseTarget(value string) (Target, error) {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return Target{}, err
+	}
+	if parsed.Scheme != "nats" {
+		return Target{}, fmt.Errorf("unsupported event target %q (expected nats://host:port/subject)", value)
+	}
+	if parsed.Hostname() == "" {
+		return Target{}, fmt.Errorf("event target %q has no host", value)
+	}
+	target := Target{Address: parsed.Host, Subject: strings.Trim(parsed.Path, "/")}
+	if parsed.Port() == "" {
+		target.Address = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+	if target.Subject == "" {
+		target.Subject = DefaultSubject
+	}
+	if strings.ContainsAny(target.Subject, " \t\r\n/") {
+		return Target{}, fmt.Errorf("event subject %q is not a NATS subject", target.Subject)
+	}
+	if parsed.User != nil {
+		if password, ok := parsed.User.Password(); ok {
+			target.User, target.Password = parsed.User.Username(), password
+		} else {
+			target.Token = parsed.User.Username()
+		}
+	}
+	return target, nil
+}
+
+func (t Target) String() string {
+	return "nats://" + t.Address + "/" + t.Subject
+}
+
+// Publisher sends events to one NATS subject over a single connection.
+type Publisher struct {
+	subject string
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+	writer  *bufio.Writer
+}
+
+// Dial connects to target and completes the NATS handshake, upgrading to
+// TLS when the server requires it.
+func Dial(ctx context.Context, target Target) (*Publisher, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", target.Address)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("read server info: %w", err)
+	}
+	payload, ok := strings.CutPrefix(strings.TrimSpace(line), "INFO ")
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	if err := json.Unmarshal([]byte(payload), &info); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("decode server info: %w", err)
+	}
+	if info.TLSRequired {
+		host, _, _ := net.SplitHostPort(target.Address)
+		secured := tls.Client(conn, &tls.Config{ServerName: host})
+		if err := secured.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn, reader = secured, bufio.NewReader(secured)
+	}
+
+	publisher := &Publisher{subject: target.Subject, conn: conn, reader: reader, writer: bufio.NewWriter(conn)}
+	options := map[string]any{"verbose": false, "pedantic": false, "name": "tradegravity-collector", "lang": "go", "protocol": 0}
+	if target.User != "" {
+		options["user"], options["pass"] = target.User, target.Password
+	}
+	if target.Token != "" {
+		options["auth_token"] = target.Token
+	}
+	connect, err := json.Marshal(options)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	fmt.Fprintf(publisher.writer, "CONNECT %s\r\n", connect)
+	if err := publisher.flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	return publisher, nil
+}
+
+// Publish sends one message per event and waits for the server to confirm
+// it has processed them.
+func (p *Publisher) Publish(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	p.conn.SetDeadline(deadline)
+	defer p.conn.SetDeadline(time.Time{})
+	for _, event := range events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(p.writer, "PUB %s %d\r\n", p.subject, len(body))
+		p.writer.Write(body)
+		p.writer.WriteString("\r\n")
+	}
+	return p.flush()
+}
+
+// flush sends PING and reads up to the PONG, so a server error reported for
+// anything written before it surfaces here.
+func (p *Publisher) flush() error {
+	p.writer.WriteString("PING\r\n")
+	if err := p.writer.Flush(); err != nil {
+		return err
+	}
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			p.writer.WriteString("PONG\r\n")
+			if err := p.writer.Flush(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("nats: " + strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		}
+	}
+}
+
+// Close ends the connection.
+func (p *Publisher) Close() error {
+	return p.conn.Close()
+}
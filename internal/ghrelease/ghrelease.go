@@ -0,0 +1,182 @@
+// Package ghrelease creates GitHub releases and uploads their assets through
+// the REST API, authenticating with a token, so it needs no GitHub client
+// library.
+package ghrelease
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tradegravity/internal/secrets"
+)
+
+const (
+	defaultEndpoint       = "https://api.github.com"
+	defaultTimeoutSeconds = 300
+	apiVersion            = "2022-11-28"
+)
+
+// Config holds the API endpoint and the token. Timeout bounds each request,
+// including asset uploads.
+type Config struct {
+	Endpoint string
+	Token    string
+	Timeout  time.Duration
+}
+
+// ConfigFromEnv reads GITHUB_TOKEN. GITHUB_API_URL overrides the API base
+// URL, as GitHub Actions sets it on GitHub Enterprise Server.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Endpoint: getenv("GITHUB_API_URL", defaultEndpoint),
+		Timeout:  defaultTimeoutSeconds * time.Second,
+	}
+	var err error
+	if cfg.Token, err = secrets.Lookup("GITHUB_TOKEN"); err != nil {
+		return Config{}, err
+	}
+	if cfg.Token == "" {
+		return Config{}, errors.New("missing GITHUB_TOKEN for release publishing")
+	}
+	return cfg, nil
+}
+
+// Release is the part of a GitHub release the uploader needs.
+type Release struct {
+	ID        int64   `json:"id"`
+	TagName   string  `json:"tag_name"`
+	HTMLURL   string  `json:"html_url"`
+	UploadURL string  `json:"upload_url"`
+	Assets    []Asset `json:"assets"`
+}
+
+// Asset is one file attached to a release.
+type Asset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Client manages the releases of repositories the token can write to.
+type Client struct {
+	config Config
+	client *http.Client
+}
+
+func New(cfg Config) (*Client, error) {
+	if strings.TrimSpace(cfg.Endpoint) == "" {
+		cfg.Endpoint = defaultEndpoint
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("github release client needs a token")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeoutSeconds * time.Second
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &Client{config: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// EnsureRelease returns the release of repo ("owner/name") tagged tag,
+// creating it with name and body when there is none. A new release is not
+// marked as the repository's latest, so data snapshots do not displace code
+// releases; GitHub creates the tag on the default branch if it is missing.
+func (c *Client) EnsureRelease(ctx context.Context, repo, tag, name, body string) (Release, error) {
+	base := c.config.Endpoint + "/repos/" + repo + "/releases"
+	var release Release
+	err := c.call(ctx, http.MethodGet, base+"/tags/"+url.PathEscape(tag), "", nil, &release)
+	if err == nil {
+		return release, nil
+	}
+	var status *StatusError
+	if !errors.As(err, &status) || status.Code != http.StatusNotFound {
+		return Release{}, err
+	}
+	create, err := json.Marshal(map[string]any{"tag_name": tag, "name": name, "body": body, "make_latest": "false"})
+	if err != nil {
+		return Release{}, err
+	}
+	if err := c.call(ctx, http.MethodPost, base, "application/json", create, &release); err != nil {
+		return Release{}, err
+	}
+	return release, nil
+}
+
+// UploadAsset attaches body to release as name, replacing an asset of the
+// same name so a rerun for the same tag leaves one copy.
+func (c *Client) UploadAsset(ctx context.Context, repo string, release Release, name, contentType string, body []byte) error {
+	for _, asset := range release.Assets {
+		if asset.Name == name {
+			uri := c.config.Endpoint + "/repos/" + repo + "/releases/assets/" + strconv.FormatInt(asset.ID, 10)
+			if err := c.call(ctx, http.MethodDelete, uri, "", nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+	// upload_url is a URI template such as .../assets{?name,label}.
+	uploadURL, _, _ := strings.Cut(release.UploadURL, "{")
+	if uploadURL == "" {
+		return fmt.Errorf("release %s has no upload URL", release.TagName)
+	}
+	return c.call(ctx, http.MethodPost, uploadURL+"?name="+url.QueryEscape(name), contentType, body, nil)
+}
+
+// StatusError is a non-2xx API response.
+type StatusError struct {
+	Method string
+	Path   string
+	Code   int
+	Detail string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: status %d: %s", e.Method, e.Path, e.Code, e.Detail)
+}
+
+// call sends body with contentType and decodes the response into result
+// when it is not nil.
+func (c *Client) call(ctx context.Context, method, uri, contentType string, body []byte, result any) error {
+	var payload io.Reader
+	if body != nil {
+		payload = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, uri, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &StatusError{Method: method, Path: req.URL.Path, Code: resp.StatusCode, Detail: strings.TrimSpace(string(detail))}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func getenv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
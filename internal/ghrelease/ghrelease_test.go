@@ -0,0 +1,94 @@
+package ghrelease
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnsureReleaseCreatesMissingReleaseAndReplacesAssets(t *testing.T) {
+	var calls []string
+	var created map[string]any
+	var uploaded string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token-1" {
+			t.Fatalf("Authorization = %q", got)
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet:
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/owner/data/releases":
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &created); err != nil {
+				t.Fatal(err)
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": 7, "tag_name": created["tag_name"],
+				"upload_url": srv.URL + "/upload/releases/7/assets{?name,label}",
+				"assets":     []map[string]any{{"id": 3, "name": "bundle.zip"}},
+			})
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost:
+			if r.URL.Query().Get("name") != "bundle.zip" || r.Header.Get("Content-Type") != "application/zip" {
+				t.Fatalf("upload query = %s, type = %s", r.URL.RawQuery, r.Header.Get("Content-Type"))
+			}
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := New(Config{Endpoint: srv.URL + "/", Token: "token-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := client.EnsureRelease(context.Background(), "owner/data", "data-20260715T120000Z", "Data 2026-07-15", "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.ID != 7 || created["make_latest"] != "false" || created["name"] != "Data 2026-07-15" {
+		t.Fatalf("release = %+v, created = %v", release, created)
+	}
+	if err := client.UploadAsset(context.Background(), "owner/data", release, "bundle.zip", "application/zip", []byte("zip")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"GET /repos/owner/data/releases/tags/data-20260715T120000Z",
+		"POST /repos/owner/data/releases",
+		"DELETE /repos/owner/data/releases/assets/3",
+		"POST /upload/releases/7/assets",
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %q", calls)
+	}
+	for index := range want {
+		if calls[index] != want[index] {
+			t.Fatalf("calls = %q, want %q", calls, want)
+		}
+	}
+	if uploaded != "zip" {
+		t.Fatalf("uploaded = %q", uploaded)
+	}
+}
+
+func TestEnsureReleaseReturnsOtherErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	client, err := New(Config{Endpoint: srv.URL, Token: "token-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.EnsureRelease(context.Background(), "owner/data", "v1", "v1", ""); err == nil {
+		t.Fatal("want an error for a 401 response")
+	}
+}
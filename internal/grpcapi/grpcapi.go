@@ -0,0 +1,104 @@
+// Package grpcapi serves the TradeGravity gRPC service declared in
+// proto/tradegravity/v1/tradegravity.proto, for internal services that want
+// typed clients. It reads the same store as the REST API; the message and
+// service code in tradegravitypb is generated from the .proto file.
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=tradegravity --go-grpc_out=../.. --go-grpc_opt=module=tradegravity tradegravity/v1/tradegravity.proto
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"tradegravity/internal/grpcapi/tradegravitypb"
+	"tradegravity/internal/model"
+	"tradegravity/internal/publisher"
+)
+
+// ReporterLister reads the reporters table; *sqlite.Store implements it.
+type ReporterLister interface {
+	ListReporters(ctx context.Context, onlyActive bool) ([]model.Reporter, error)
+}
+
+// Service is the store the gRPC methods read: Reporters for ListReporters,
+// and the SQLite database at DBPath for ListSeries. ObserveQuery, when set,
+// receives how long each store query took.
+type Service struct {
+	Reporters    ReporterLister
+	DBPath       string
+	ObserveQuery func(name string, elapsed time.Duration)
+}
+
+// NewServer returns a gRPC server with the TradeGravity service registered.
+// A *grpc.Server is also an http.Handler, so serve mounts it on a cleartext
+// HTTP/2 listener next to the REST API.
+func NewServer(service Service) *grpc.Server {
+	server := grpc.NewServer()
+	tradegravitypb.RegisterTradeGravityServer(server, &tradeGravityServer{service: service})
+	return server
+}
+
+type tradeGravityServer struct {
+	tradegravitypb.UnimplementedTradeGravityServer
+	service Service
+}
+
+func (s *tradeGravityServer) ListReporters(ctx context.Context, request *tradegravitypb.ListReportersRequest) (*tradegravitypb.ListReportersResponse, error) {
+	start := time.Now()
+	reporters, err := s.service.Reporters.ListReporters(ctx, request.GetOnlyActive())
+	if s.service.ObserveQuery != nil {
+		s.service.ObserveQuery("list_reporters", time.Since(start))
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load reporters")
+	}
+	response := &tradegravitypb.ListReportersResponse{Reporters: make([]*tradegravitypb.Reporter, 0, len(reporters))}
+	for _, reporter := range reporters {
+		response.Reporters = append(response.Reporters, &tradegravitypb.Reporter{
+			Iso3: reporter.ISO3, NameEn: reporter.NameEN, NameKo: reporter.NameKO,
+			Region: reporter.Region, Subregion: reporter.Subregion, IncomeGroup: reporter.IncomeGroup,
+			Active: reporter.IsActive,
+		})
+	}
+	return response, nil
+}
+
+// ListSeries returns the page publisher.QuerySeries selects; page_size and
+// page_token stand for its Limit and Cursor.
+func (s *tradeGravityServer) ListSeries(ctx context.Context, request *tradegravitypb.ListSeriesRequest) (*tradegravitypb.ListSeriesResponse, error) {
+	page, err := publisher.QuerySeries(ctx, s.service.DBPath, publisher.SeriesQuery{
+		Provider:   request.GetProvider(),
+		Reporters:  request.GetReporters(),
+		Partners:   request.GetPartners(),
+		Flow:       model.Flow(request.GetFlow()),
+		PeriodType: model.PeriodType(request.GetPeriodType()),
+		From:       request.GetFrom(),
+		To:         request.GetTo(),
+		Limit:      int(request.GetPageSize()),
+		Cursor:     request.GetPageToken(),
+	}, s.service.ObserveQuery)
+	if errors.Is(err, publisher.ErrInvalidSeriesQuery) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to load observations")
+	}
+	response := &tradegravitypb.ListSeriesResponse{
+		Provider:      page.Provider,
+		Observations:  make([]*tradegravitypb.Observation, 0, len(page.Rows)),
+		NextPageToken: page.NextCursor,
+	}
+	for _, row := range page.Rows {
+		response.Observations = append(response.Observations, &tradegravitypb.Observation{
+			Provider: page.Provider, ReporterIso3: row.ReporterISO3, PartnerIso3: row.PartnerISO3,
+			Flow: string(row.Flow), PeriodType: string(row.PeriodType), Period: row.Period,
+			ValueCents: int64(row.Value),
+		})
+	}
+	return response, nil
+}
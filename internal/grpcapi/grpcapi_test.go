@@ -0,0 +1,98 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"tradegravity/internal/grpcapi/tradegravitypb"
+	"tradegravity/internal/model"
+	"tradegravity/internal/store/sqlite"
+)
+
+func TestServiceOverCleartextHTTP2(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tradegravity.db")
+	st, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+	if err := st.UpsertReporters(ctx, []model.Reporter{{ISO3: "KOR", NameEN: "Korea", Region: "Asia", Subregion: "Eastern Asia", IncomeGroup: "High income", IsActive: true}, {ISO3: "JPN", NameEN: "Japan"}}); err != nil {
+		t.Fatal(err)
+	}
+	var observations []model.Observation
+	for _, period := range []string{"2014", "2015", "2016"} {
+		observations = append(observations, model.Observation{
+			Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport,
+			PeriodType: model.PeriodYear, Period: period, ValueUSD: 10.25,
+		})
+	}
+	if err := st.UpsertObservations(ctx, observations); err != nil {
+		t.Fatal(err)
+	}
+
+	// Serve the way tradegravity serve -grpc-addr does.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: NewServer(Service{Reporters: st, DBPath: dbPath})}
+	srv.Protocols = new(http.Protocols)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := tradegravitypb.NewTradeGravityClient(conn)
+
+	reporters, err := client.ListReporters(ctx, &tradegravitypb.ListReportersRequest{OnlyActive: true})
+	if err != nil || len(reporters.GetReporters()) != 1 || reporters.GetReporters()[0].GetIso3() != "KOR" || !reporters.GetReporters()[0].GetActive() {
+		t.Fatalf("ListReporters = %v, %v", reporters, err)
+	}
+	if korea := reporters.GetReporters()[0]; korea.GetSubregion() != "Eastern Asia" || korea.GetIncomeGroup() != "High income" {
+		t.Fatalf("reporter = %v", korea)
+	}
+
+	var got []*tradegravitypb.Observation
+	request := &tradegravitypb.ListSeriesRequest{Reporters: []string{"kor"}, PageSize: 2}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not end")
+		}
+		page, err := client.ListSeries(ctx, request)
+		if err != nil || len(page.GetObservations()) > 2 || page.GetProvider() != "wits" {
+			t.Fatalf("ListSeries page %d = %v, %v", pages, page, err)
+		}
+		got = append(got, page.GetObservations()...)
+		if page.GetNextPageToken() == "" {
+			break
+		}
+		request.PageToken = page.GetNextPageToken()
+	}
+	if len(got) != 3 || got[0].GetPeriod() != "2014" || got[2].GetPeriod() != "2016" || got[1].GetValueCents() != 1025 || got[1].GetFlow() != "export" {
+		t.Fatalf("series = %v", got)
+	}
+
+	bounded, err := client.ListSeries(ctx, &tradegravitypb.ListSeriesRequest{Reporters: []string{"KOR"}, From: "2015-Q2", To: "2016"})
+	if err != nil || len(bounded.GetObservations()) != 1 || bounded.GetObservations()[0].GetPeriod() != "2016" {
+		t.Fatalf("bounded series = %v, %v", bounded, err)
+	}
+
+	for _, bad := range []*tradegravitypb.ListSeriesRequest{{Flow: "reexport"}, {Partners: []string{"U.S."}}, {PageSize: 5001}, {PageToken: "!"}, {From: "2016", To: "2015-12"}} {
+		if _, err := client.ListSeries(ctx, bad); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("%v: error = %v, want InvalidArgument", bad, err)
+		}
+	}
+}
@@ -0,0 +1,609 @@
+// The TradeGravity gRPC API, served by `tradegravity serve -grpc-addr` over
+// cleartext HTTP/2. It reads the same SQLite store as the collector writes:
+// ListReporters returns the reporters table and ListSeries pages through
+// stored total-trade observations with the filters of /v1/series. The Go
+// code in internal/grpcapi/tradegravitypb is generated from this file; run
+// `go generate ./internal/grpcapi` after changing it.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: tradegravity/v1/tradegravity.proto
+
+package tradegravitypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Reporter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Iso3          string                 `protobuf:"bytes,1,opt,name=iso3,proto3" json:"iso3,omitempty"`
+	NameEn        string                 `protobuf:"bytes,2,opt,name=name_en,json=nameEn,proto3" json:"name_en,omitempty"`
+	NameKo        string                 `protobuf:"bytes,3,opt,name=name_ko,json=nameKo,proto3" json:"name_ko,omitempty"`
+	Region        string                 `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`
+	Active        bool                   `protobuf:"varint,5,opt,name=active,proto3" json:"active,omitempty"`
+	Subregion     string                 `protobuf:"bytes,6,opt,name=subregion,proto3" json:"subregion,omitempty"`
+	IncomeGroup   string                 `protobuf:"bytes,7,opt,name=income_group,json=incomeGroup,proto3" json:"income_group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reporter) Reset() {
+	*x = Reporter{}
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reporter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reporter) ProtoMessage() {}
+
+func (x *Reporter) ProtoReflect() protoreflect.Message {
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reporter.ProtoReflect.Descriptor instead.
+func (*Reporter) Descriptor() ([]byte, []int) {
+	return file_tradegravity_v1_tradegravity_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Reporter) GetIso3() string {
+	if x != nil {
+		return x.Iso3
+	}
+	return ""
+}
+
+func (x *Reporter) GetNameEn() string {
+	if x != nil {
+		return x.NameEn
+	}
+	return ""
+}
+
+func (x *Reporter) GetNameKo() string {
+	if x != nil {
+		return x.NameKo
+	}
+	return ""
+}
+
+func (x *Reporter) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Reporter) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *Reporter) GetSubregion() string {
+	if x != nil {
+		return x.Subregion
+	}
+	return ""
+}
+
+func (x *Reporter) GetIncomeGroup() string {
+	if x != nil {
+		return x.IncomeGroup
+	}
+	return ""
+}
+
+type ListReportersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only reporters the collector still collects.
+	OnlyActive    bool `protobuf:"varint,1,opt,name=only_active,json=onlyActive,proto3" json:"only_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReportersRequest) Reset() {
+	*x = ListReportersRequest{}
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReportersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportersRequest) ProtoMessage() {}
+
+func (x *ListReportersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportersRequest.ProtoReflect.Descriptor instead.
+func (*ListReportersRequest) Descriptor() ([]byte, []int) {
+	return file_tradegravity_v1_tradegravity_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListReportersRequest) GetOnlyActive() bool {
+	if x != nil {
+		return x.OnlyActive
+	}
+	return false
+}
+
+type ListReportersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reporters     []*Reporter            `protobuf:"bytes,1,rep,name=reporters,proto3" json:"reporters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListReportersResponse) Reset() {
+	*x = ListReportersResponse{}
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReportersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReportersResponse) ProtoMessage() {}
+
+func (x *ListReportersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListReportersResponse.ProtoReflect.Descriptor instead.
+func (*ListReportersResponse) Descriptor() ([]byte, []int) {
+	return file_tradegravity_v1_tradegravity_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListReportersResponse) GetReporters() []*Reporter {
+	if x != nil {
+		return x.Reporters
+	}
+	return nil
+}
+
+type Observation struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Provider     string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	ReporterIso3 string                 `protobuf:"bytes,2,opt,name=reporter_iso3,json=reporterIso3,proto3" json:"reporter_iso3,omitempty"`
+	PartnerIso3  string                 `protobuf:"bytes,3,opt,name=partner_iso3,json=partnerIso3,proto3" json:"partner_iso3,omitempty"`
+	// export, import, or total.
+	Flow string `protobuf:"bytes,4,opt,name=flow,proto3" json:"flow,omitempty"`
+	// Y, Q, or M.
+	PeriodType string `protobuf:"bytes,5,opt,name=period_type,json=periodType,proto3" json:"period_type,omitempty"`
+	// 2024, 2024-Q1, or 2024-03.
+	Period string `protobuf:"bytes,6,opt,name=period,proto3" json:"period,omitempty"`
+	// The exact stored value in US cents.
+	ValueCents    int64  `protobuf:"varint,7,opt,name=value_cents,json=valueCents,proto3" json:"value_cents,omitempty"`
+	Estimated     bool   `protobuf:"varint,8,opt,name=estimated,proto3" json:"estimated,omitempty"`
+	QualityNote   string `protobuf:"bytes,9,opt,name=quality_note,json=qualityNote,proto3" json:"quality_note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Observation) Reset() {
+	*x = Observation{}
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Observation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Observation) ProtoMessage() {}
+
+func (x *Observation) ProtoReflect() protoreflect.Message {
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Observation.ProtoReflect.Descriptor instead.
+func (*Observation) Descriptor() ([]byte, []int) {
+	return file_tradegravity_v1_tradegravity_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Observation) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Observation) GetReporterIso3() string {
+	if x != nil {
+		return x.ReporterIso3
+	}
+	return ""
+}
+
+func (x *Observation) GetPartnerIso3() string {
+	if x != nil {
+		return x.PartnerIso3
+	}
+	return ""
+}
+
+func (x *Observation) GetFlow() string {
+	if x != nil {
+		return x.Flow
+	}
+	return ""
+}
+
+func (x *Observation) GetPeriodType() string {
+	if x != nil {
+		return x.PeriodType
+	}
+	return ""
+}
+
+func (x *Observation) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *Observation) GetValueCents() int64 {
+	if x != nil {
+		return x.ValueCents
+	}
+	return 0
+}
+
+func (x *Observation) GetEstimated() bool {
+	if x != nil {
+		return x.Estimated
+	}
+	return false
+}
+
+func (x *Observation) GetQualityNote() string {
+	if x != nil {
+		return x.QualityNote
+	}
+	return ""
+}
+
+type ListSeriesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Defaults to wits.
+	Provider string `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// ISO3 codes; empty selects every reporter or partner.
+	Reporters  []string `protobuf:"bytes,2,rep,name=reporters,proto3" json:"reporters,omitempty"`
+	Partners   []string `protobuf:"bytes,3,rep,name=partners,proto3" json:"partners,omitempty"`
+	Flow       string   `protobuf:"bytes,4,opt,name=flow,proto3" json:"flow,omitempty"`
+	PeriodType string   `protobuf:"bytes,5,opt,name=period_type,json=periodType,proto3" json:"period_type,omitempty"`
+	// 500 when unset, at most 5000.
+	PageSize int32 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page, sent with the same filters.
+	PageToken string `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Inclusive bounds such as 2015, 2015-Q1, or 2015-03. Only periods that
+	// lie wholly between them are returned, so to 2024-Q1 keeps 2024-03 but
+	// not 2024.
+	From          string `protobuf:"bytes,8,opt,name=from,proto3" json:"from,omitempty"`
+	To            string `protobuf:"bytes,9,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSeriesRequest) Reset() {
+	*x = ListSeriesRequest{}
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSeriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSeriesRequest) ProtoMessage() {}
+
+func (x *ListSeriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSeriesRequest.ProtoReflect.Descriptor instead.
+func (*ListSeriesRequest) Descriptor() ([]byte, []int) {
+	return file_tradegravity_v1_tradegravity_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListSeriesRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ListSeriesRequest) GetReporters() []string {
+	if x != nil {
+		return x.Reporters
+	}
+	return nil
+}
+
+func (x *ListSeriesRequest) GetPartners() []string {
+	if x != nil {
+		return x.Partners
+	}
+	return nil
+}
+
+func (x *ListSeriesRequest) GetFlow() string {
+	if x != nil {
+		return x.Flow
+	}
+	return ""
+}
+
+func (x *ListSeriesRequest) GetPeriodType() string {
+	if x != nil {
+		return x.PeriodType
+	}
+	return ""
+}
+
+func (x *ListSeriesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSeriesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListSeriesRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ListSeriesRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type ListSeriesResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Provider string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	// Ordered by reporter, partner, period type, period, and flow.
+	Observations []*Observation `protobuf:"bytes,2,rep,name=observations,proto3" json:"observations,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSeriesResponse) Reset() {
+	*x = ListSeriesResponse{}
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSeriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSeriesResponse) ProtoMessage() {}
+
+func (x *ListSeriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tradegravity_v1_tradegravity_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSeriesResponse.ProtoReflect.Descriptor instead.
+func (*ListSeriesResponse) Descriptor() ([]byte, []int) {
+	return file_tradegravity_v1_tradegravity_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListSeriesResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ListSeriesResponse) GetObservations() []*Observation {
+	if x != nil {
+		return x.Observations
+	}
+	return nil
+}
+
+func (x *ListSeriesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+var File_tradegravity_v1_tradegravity_proto protoreflect.FileDescriptor
+
+const file_tradegravity_v1_tradegravity_proto_rawDesc = "" +
+	"\n" +
+	"\"tradegravity/v1/tradegravity.proto\x12\x0ftradegravity.v1\"\xc1\x01\n" +
+	"\bReporter\x12\x12\n" +
+	"\x04iso3\x18\x01 \x01(\tR\x04iso3\x12\x17\n" +
+	"\aname_en\x18\x02 \x01(\tR\x06nameEn\x12\x17\n" +
+	"\aname_ko\x18\x03 \x01(\tR\x06nameKo\x12\x16\n" +
+	"\x06region\x18\x04 \x01(\tR\x06region\x12\x16\n" +
+	"\x06active\x18\x05 \x01(\bR\x06active\x12\x1c\n" +
+	"\tsubregion\x18\x06 \x01(\tR\tsubregion\x12!\n" +
+	"\fincome_group\x18\a \x01(\tR\vincomeGroup\"7\n" +
+	"\x14ListReportersRequest\x12\x1f\n" +
+	"\vonly_active\x18\x01 \x01(\bR\n" +
+	"onlyActive\"P\n" +
+	"\x15ListReportersResponse\x127\n" +
+	"\treporters\x18\x01 \x03(\v2\x19.tradegravity.v1.ReporterR\treporters\"\xa0\x02\n" +
+	"\vObservation\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12#\n" +
+	"\rreporter_iso3\x18\x02 \x01(\tR\freporterIso3\x12!\n" +
+	"\fpartner_iso3\x18\x03 \x01(\tR\vpartnerIso3\x12\x12\n" +
+	"\x04flow\x18\x04 \x01(\tR\x04flow\x12\x1f\n" +
+	"\vperiod_type\x18\x05 \x01(\tR\n" +
+	"periodType\x12\x16\n" +
+	"\x06period\x18\x06 \x01(\tR\x06period\x12\x1f\n" +
+	"\vvalue_cents\x18\a \x01(\x03R\n" +
+	"valueCents\x12\x1c\n" +
+	"\testimated\x18\b \x01(\bR\testimated\x12!\n" +
+	"\fquality_note\x18\t \x01(\tR\vqualityNote\"\xfe\x01\n" +
+	"\x11ListSeriesRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x1c\n" +
+	"\treporters\x18\x02 \x03(\tR\treporters\x12\x1a\n" +
+	"\bpartners\x18\x03 \x03(\tR\bpartners\x12\x12\n" +
+	"\x04flow\x18\x04 \x01(\tR\x04flow\x12\x1f\n" +
+	"\vperiod_type\x18\x05 \x01(\tR\n" +
+	"periodType\x12\x1b\n" +
+	"\tpage_size\x18\x06 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\a \x01(\tR\tpageToken\x12\x12\n" +
+	"\x04from\x18\b \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\t \x01(\tR\x02to\"\x9a\x01\n" +
+	"\x12ListSeriesResponse\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12@\n" +
+	"\fobservations\x18\x02 \x03(\v2\x1c.tradegravity.v1.ObservationR\fobservations\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken2\xc5\x01\n" +
+	"\fTradeGravity\x12^\n" +
+	"\rListReporters\x12%.tradegravity.v1.ListReportersRequest\x1a&.tradegravity.v1.ListReportersResponse\x12U\n" +
+	"\n" +
+	"ListSeries\x12\".tradegravity.v1.ListSeriesRequest\x1a#.tradegravity.v1.ListSeriesResponseB=Z;tradegravity/internal/grpcapi/tradegravitypb;tradegravitypbb\x06proto3"
+
+var (
+	file_tradegravity_v1_tradegravity_proto_rawDescOnce sync.Once
+	file_tradegravity_v1_tradegravity_proto_rawDescData []byte
+)
+
+func file_tradegravity_v1_tradegravity_proto_rawDescGZIP() []byte {
+	file_tradegravity_v1_tradegravity_proto_rawDescOnce.Do(func() {
+		file_tradegravity_v1_tradegravity_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tradegravity_v1_tradegravity_proto_rawDesc), len(file_tradegravity_v1_tradegravity_proto_rawDesc)))
+	})
+	return file_tradegravity_v1_tradegravity_proto_rawDescData
+}
+
+var file_tradegravity_v1_tradegravity_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_tradegravity_v1_tradegravity_proto_goTypes = []any{
+	(*Reporter)(nil),              // 0: tradegravity.v1.Reporter
+	(*ListReportersRequest)(nil),  // 1: tradegravity.v1.ListReportersRequest
+	(*ListReportersResponse)(nil), // 2: tradegravity.v1.ListReportersResponse
+	(*Observation)(nil),           // 3: tradegravity.v1.Observation
+	(*ListSeriesRequest)(nil),     // 4: tradegravity.v1.ListSeriesRequest
+	(*ListSeriesResponse)(nil),    // 5: tradegravity.v1.ListSeriesResponse
+}
+var file_tradegravity_v1_tradegravity_proto_depIdxs = []int32{
+	0, // 0: tradegravity.v1.ListReportersResponse.reporters:type_name -> tradegravity.v1.Reporter
+	3, // 1: tradegravity.v1.ListSeriesResponse.observations:type_name -> tradegravity.v1.Observation
+	1, // 2: tradegravity.v1.TradeGravity.ListReporters:input_type -> tradegravity.v1.ListReportersRequest
+	4, // 3: tradegravity.v1.TradeGravity.ListSeries:input_type -> tradegravity.v1.ListSeriesRequest
+	2, // 4: tradegravity.v1.TradeGravity.ListReporters:output_type -> tradegravity.v1.ListReportersResponse
+	5, // 5: tradegravity.v1.TradeGravity.ListSeries:output_type -> tradegravity.v1.ListSeriesResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_tradegravity_v1_tradegravity_proto_init() }
+func file_tradegravity_v1_tradegravity_proto_init() {
+	if File_tradegravity_v1_tradegravity_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tradegravity_v1_tradegravity_proto_rawDesc), len(file_tradegravity_v1_tradegravity_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tradegravity_v1_tradegravity_proto_goTypes,
+		DependencyIndexes: file_tradegravity_v1_tradegravity_proto_depIdxs,
+		MessageInfos:      file_tradegravity_v1_tradegravity_proto_msgTypes,
+	}.Build()
+	File_tradegravity_v1_tradegravity_proto = out.File
+	file_tradegravity_v1_tradegravity_proto_goTypes = nil
+	file_tradegravity_v1_tradegravity_proto_depIdxs = nil
+}
@@ -0,0 +1,170 @@
+// The TradeGravity gRPC API, served by `tradegravity serve -grpc-addr` over
+// cleartext HTTP/2. It reads the same SQLite store as the collector writes:
+// ListReporters returns the reporters table and ListSeries pages through
+// stored total-trade observations with the filters of /v1/series. The Go
+// code in internal/grpcapi/tradegravitypb is generated from this file; run
+// `go generate ./internal/grpcapi` after changing it.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: tradegravity/v1/tradegravity.proto
+
+package tradegravitypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TradeGravity_ListReporters_FullMethodName = "/tradegravity.v1.TradeGravity/ListReporters"
+	TradeGravity_ListSeries_FullMethodName    = "/tradegravity.v1.TradeGravity/ListSeries"
+)
+
+// TradeGravityClient is the client API for TradeGravity service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TradeGravityClient interface {
+	// ListReporters returns the stored reporters, ordered by ISO3 code.
+	ListReporters(ctx context.Context, in *ListReportersRequest, opts ...grpc.CallOption) (*ListReportersResponse, error)
+	// ListSeries returns one page of stored total-trade observations.
+	ListSeries(ctx context.Context, in *ListSeriesRequest, opts ...grpc.CallOption) (*ListSeriesResponse, error)
+}
+
+type tradeGravityClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTradeGravityClient(cc grpc.ClientConnInterface) TradeGravityClient {
+	return &tradeGravityClient{cc}
+}
+
+func (c *tradeGravityClient) ListReporters(ctx context.Context, in *ListReportersRequest, opts ...grpc.CallOption) (*ListReportersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReportersResponse)
+	err := c.cc.Invoke(ctx, TradeGravity_ListReporters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeGravityClient) ListSeries(ctx context.Context, in *ListSeriesRequest, opts ...grpc.CallOption) (*ListSeriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSeriesResponse)
+	err := c.cc.Invoke(ctx, TradeGravity_ListSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TradeGravityServer is the server API for TradeGravity service.
+// All implementations must embed UnimplementedTradeGravityServer
+// for forward compatibility.
+type TradeGravityServer interface {
+	// ListReporters returns the stored reporters, ordered by ISO3 code.
+	ListReporters(context.Context, *ListReportersRequest) (*ListReportersResponse, error)
+	// ListSeries returns one page of stored total-trade observations.
+	ListSeries(context.Context, *ListSeriesRequest) (*ListSeriesResponse, error)
+	mustEmbedUnimplementedTradeGravityServer()
+}
+
+// UnimplementedTradeGravityServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTradeGravityServer struct{}
+
+func (UnimplementedTradeGravityServer) ListReporters(context.Context, *ListReportersRequest) (*ListReportersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReporters not implemented")
+}
+func (UnimplementedTradeGravityServer) ListSeries(context.Context, *ListSeriesRequest) (*ListSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSeries not implemented")
+}
+func (UnimplementedTradeGravityServer) mustEmbedUnimplementedTradeGravityServer() {}
+func (UnimplementedTradeGravityServer) testEmbeddedByValue()                      {}
+
+// UnsafeTradeGravityServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TradeGravityServer will
+// result in compilation errors.
+type UnsafeTradeGravityServer interface {
+	mustEmbedUnimplementedTradeGravityServer()
+}
+
+func RegisterTradeGravityServer(s grpc.ServiceRegistrar, srv TradeGravityServer) {
+	// If the following call pancis, it indicates UnimplementedTradeGravityServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TradeGravity_ServiceDesc, srv)
+}
+
+func _TradeGravity_ListReporters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeGravityServer).ListReporters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeGravity_ListReporters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeGravityServer).ListReporters(ctx, req.(*ListReportersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeGravity_ListSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeGravityServer).ListSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeGravity_ListSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeGravityServer).ListSeries(ctx, req.(*ListSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TradeGravity_ServiceDesc is the grpc.ServiceDesc for TradeGravity service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TradeGravity_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tradegravity.v1.TradeGravity",
+	HandlerType: (*TradeGravityServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListReporters",
+			Handler:    _TradeGravity_ListReporters_Handler,
+		},
+		{
+			MethodName: "ListSeries",
+			Handler:    _TradeGravity_ListSeries_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "tradegravity/v1/tradegravity.proto",
+}
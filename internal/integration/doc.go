@@ -0,0 +1,5 @@
+// Package integration holds the end-to-end tests that run the collector, the
+// sqlite store, and the publisher against stub WITS and Comtrade servers
+// answering from canned responses in testdata, so a parser regression fails
+// a test instead of a production run. The package has no code of its own.
+package integration
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"tradegravity/internal/publisher"
+	"tradegravity/internal/server"
+)
+
+// TestLayoutBuildUploadsAndServes builds with -layout, uploads the result to
+// a stub S3 bucket, and serves it, so every reader of the output agrees on
+// where the layout put each file.
+func TestLayoutBuildUploadsAndServes(t *testing.T) {
+	stub := newStubServer(t)
+	stub.useWITS(t)
+	db := filepath.Join(t.TempDir(), "tradegravity.db")
+	collectTotals(t, "wits", db)
+
+	var mu sync.Mutex
+	uploaded := map[string]bool{}
+	bucket := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		uploaded[strings.TrimPrefix(r.URL.Path, "/bucket/site/")] = true
+		mu.Unlock()
+	}))
+	defer bucket.Close()
+	t.Setenv("S3_ENDPOINT", bucket.URL)
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	out := publish(t, db, "wits", "-layout", "v1/{artifact}/{iso3}.json,v1/{artifact}/{name}.json", "-publish-to", "s3://bucket/site")
+
+	if _, err := os.Stat(filepath.Join(out, "v1", "countries", "KOR.json")); err != nil {
+		t.Fatalf("templated country file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "countries", "KOR.json")); err == nil {
+		t.Fatal("countries/KOR.json was also written at its built-in path")
+	}
+	var latest struct {
+		Rows []latestRow `json:"rows"`
+	}
+	readArtifact(t, out, "latest.json", &latest)
+	if len(latest.Rows) != 2 {
+		t.Fatalf("latest.json rows = %+v", latest.Rows)
+	}
+
+	var countries struct {
+		Partitions []struct {
+			Href string `json:"href"`
+		} `json:"partitions"`
+	}
+	readArtifact(t, out, filepath.Join("v1", "countries", "index.json"), &countries)
+	if len(countries.Partitions) != 2 {
+		t.Fatalf("countries/index.json partitions = %+v", countries.Partitions)
+	}
+	for _, partition := range countries.Partitions {
+		if _, err := os.Stat(filepath.Join(out, "v1", "countries", filepath.FromSlash(partition.Href))); err != nil {
+			t.Fatalf("partition href %q does not resolve: %v", partition.Href, err)
+		}
+	}
+
+	var index struct {
+		Files []struct {
+			Path string `json:"path"`
+		} `json:"files"`
+	}
+	readArtifact(t, out, "index.json", &index)
+	for _, file := range index.Files {
+		if !uploaded[file.Path] {
+			t.Fatalf("%s is in index.json but was not uploaded", file.Path)
+		}
+	}
+	for _, entry := range []string{"latest.json", "meta.json", "catalog.json", "index.json", "v1/countries/KOR.json"} {
+		if !uploaded[entry] {
+			t.Fatalf("%s was not uploaded; got %v", entry, uploaded)
+		}
+	}
+
+	files, err := server.New(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := server.WithProbes(files, server.Probes{DataDir: out})
+	api := publisher.APIHandler(publisher.API{DataDir: out})
+	for _, tt := range []struct {
+		handler http.Handler
+		target  string
+	}{
+		{handler, "/latest.json"},
+		{handler, "/v1/countries/KOR.json"},
+		{handler, server.ReadyPath},
+		{api, "/v1/latest"},
+		{api, "/v2/meta"},
+	} {
+		recorder := httptest.NewRecorder()
+		tt.handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d %s", tt.target, recorder.Code, recorder.Body)
+		}
+		if path.Ext(tt.target) == ".json" && recorder.Header().Get("ETag") == "" {
+			t.Fatalf("GET %s has no ETag, want one from index.json", tt.target)
+		}
+	}
+}
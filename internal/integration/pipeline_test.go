@@ -0,0 +1,202 @@
+package integration
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tradegravity/internal/collector"
+	"tradegravity/internal/publisher"
+)
+
+// configs is the repository's configs directory, relative to this package.
+var configs = filepath.Join("..", "..", "configs")
+
+// The canned responses hold the same totals for both providers: KOR with
+// all three partners and both flows, and VNM with exports to USA and CHN
+// only, in 2022 and 2023.
+const (
+	stubbedSeries  = 8
+	stubbedSkipped = 4
+)
+
+func TestWITSCollectionPublishesTheStubbedTotals(t *testing.T) {
+	stub := newStubServer(t)
+	stub.useWITS(t)
+	db := filepath.Join(t.TempDir(), "tradegravity.db")
+
+	collectTotals(t, "wits", db)
+	if got := stub.count("/dataavailability/"); got != 8 {
+		t.Fatalf("data availability requests = %d, want one per reporter and indicator in each run", got)
+	}
+
+	out := publish(t, db, "wits")
+	latest := assertStubbedTotals(t, out, "wits")
+	if usa := latest["KOR"].USA; !usa.Estimated {
+		t.Fatalf("KOR/USA = %+v, want the OBS_STATUS=E export flagged estimated", usa)
+	}
+	if usa := latest["VNM"].USA; usa.Estimated {
+		t.Fatalf("VNM/USA = %+v, want no estimate", usa)
+	}
+}
+
+func TestComtradeCollectionPublishesTheStubbedTotals(t *testing.T) {
+	stub := newStubServer(t)
+	// Reach back to 2022 whatever the current year, so FetchLatest asks for
+	// every year the canned rows hold.
+	stub.useComtrade(t, time.Now().UTC().Year()-2022)
+	db := filepath.Join(t.TempDir(), "tradegravity.db")
+
+	collectTotals(t, "comtrade", db)
+	if got := stub.count("/files/v1/app/reference/"); got != 4 {
+		t.Fatalf("reference requests = %d, want the reporters and partners lists once in each run", got)
+	}
+
+	out := publish(t, db, "comtrade")
+	latest := assertStubbedTotals(t, out, "comtrade")
+	if usa := latest["KOR"].USA; usa.Estimated {
+		t.Fatalf("KOR/USA = %+v, want no estimate", usa)
+	}
+	if usa := latest["VNM"].USA; !usa.Estimated {
+		t.Fatalf("VNM/USA = %+v, want the isReported=false row flagged estimated", usa)
+	}
+}
+
+// collectTotals collects annual totals with one year of history from the
+// stub into db, and checks that a second run finds nothing new.
+func collectTotals(t *testing.T, provider, db string) {
+	t.Helper()
+	countries := filepath.Join(configs, "countries.csv")
+	run, err := collector.CollectTotals(provider, "USA,CHN,WLD", "export,import", 0, "", countries, db, 1, 2, false)
+	if err != nil {
+		t.Fatalf("collect: %v (errors %v)", err, run.Errors)
+	}
+	if run.ReporterCount != 2 || run.SuccessCount != stubbedSeries || run.StoredCount != 2*stubbedSeries || run.SkippedCount != stubbedSkipped || run.FailureCount != 0 {
+		t.Fatalf("run = reporters %d success %d stored %d skipped %d failed %d, errors %v",
+			run.ReporterCount, run.SuccessCount, run.StoredCount, run.SkippedCount, run.FailureCount, run.Errors)
+	}
+	again, err := collector.CollectTotals(provider, "USA,CHN,WLD", "export,import", 0, "", countries, db, 1, 2, false)
+	if err != nil || again.StoredCount != 0 {
+		t.Fatalf("second collect stored %d, %v", again.StoredCount, err)
+	}
+}
+
+// publish builds the site artifacts from db into a temporary directory and
+// returns it. extra flags are appended to the build's own.
+func publish(t *testing.T, db, provider string, extra ...string) string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "data")
+	publisher.Build(append([]string{
+		"-out", out,
+		"-db", db,
+		"-provider", provider,
+		"-partners", "USA,CHN",
+		"-context", filepath.Join(out, "context.json"),
+		"-hs2", filepath.Join(configs, "hs2.csv"),
+		"-strategic-registry", filepath.Join(configs, "strategic_hs6.csv"),
+		"-semiconductor-reference", filepath.Join(configs, "semiconductor_reference.json"),
+		"-attribution", filepath.Join(configs, "attribution.json"),
+		"-generated-at", "2026-01-01T00:00:00Z",
+	}, extra...))
+	return out
+}
+
+type partnerBlock struct {
+	Period     string  `json:"period"`
+	PrevPeriod string  `json:"prev_period"`
+	Export     float64 `json:"export"`
+	Import     float64 `json:"import"`
+	Trade      float64 `json:"trade"`
+	Share      float64 `json:"share_of_total"`
+	Estimated  bool    `json:"estimated"`
+}
+
+type latestRow struct {
+	ISO3    string        `json:"iso3"`
+	USA     *partnerBlock `json:"usa"`
+	CHN     *partnerBlock `json:"chn"`
+	Total   float64       `json:"total"`
+	ShareCN float64       `json:"share_cn"`
+}
+
+// assertStubbedTotals checks latest.json, countries/KOR.json, and meta.json
+// against the canned totals and returns the latest.json rows by reporter.
+func assertStubbedTotals(t *testing.T, out, provider string) map[string]latestRow {
+	t.Helper()
+	var latest struct {
+		Provider string      `json:"provider"`
+		Rows     []latestRow `json:"rows"`
+	}
+	readArtifact(t, out, "latest.json", &latest)
+	rows := map[string]latestRow{}
+	for _, row := range latest.Rows {
+		rows[row.ISO3] = row
+	}
+	if latest.Provider != provider || len(rows) != 2 {
+		t.Fatalf("latest.json provider %q rows %v", latest.Provider, rows)
+	}
+
+	kor := rows["KOR"]
+	if kor.USA == nil || kor.CHN == nil {
+		t.Fatalf("KOR = %+v, want USA and CHN blocks", kor)
+	}
+	if usa := *kor.USA; usa.Period != "2023" || usa.PrevPeriod != "2022" || usa.Export != 115e9 || usa.Import != 71e9 || usa.Trade != 186e9 {
+		t.Fatalf("KOR/USA = %+v", usa)
+	}
+	if chn := *kor.CHN; chn.Period != "2023" || chn.Export != 124.8e9 || chn.Import != 142.9e9 || chn.Trade != 267.7e9 {
+		t.Fatalf("KOR/CHN = %+v", chn)
+	}
+	if !near(kor.Total, 453.7e9) || !near(kor.ShareCN, 267.7/453.7) || !near(kor.USA.Share, 186/1274.8) {
+		t.Fatalf("KOR total %v share_cn %v USA share_of_total %v", kor.Total, kor.ShareCN, kor.USA.Share)
+	}
+	vnm := rows["VNM"]
+	if vnm.USA == nil || vnm.USA.Export != 97e9 || vnm.USA.Import != 0 || vnm.USA.Share != 0 {
+		t.Fatalf("VNM/USA = %+v, want exports only and no share without world totals", vnm.USA)
+	}
+
+	var country struct {
+		ReporterISO3 string `json:"reporter_iso3"`
+		Points       []struct {
+			Period string        `json:"period"`
+			USA    *partnerBlock `json:"usa"`
+		} `json:"points"`
+	}
+	readArtifact(t, out, filepath.Join("countries", "KOR.json"), &country)
+	if len(country.Points) != 2 || country.Points[0].Period != "2022" || country.Points[0].USA == nil || country.Points[0].USA.Export != 110e9 {
+		t.Fatalf("countries/KOR.json = %+v", country)
+	}
+
+	var meta struct {
+		ReporterCount int `json:"reporter_count"`
+		Attribution   []struct {
+			Provider string `json:"provider"`
+		} `json:"attribution"`
+	}
+	readArtifact(t, out, "meta.json", &meta)
+	credited := false
+	for _, credit := range meta.Attribution {
+		credited = credited || credit.Provider == provider
+	}
+	if meta.ReporterCount != 2 || !credited {
+		t.Fatalf("meta.json = %+v, want two reporters and %s credited", meta, provider)
+	}
+	return rows
+}
+
+func readArtifact(t *testing.T, out, relative string, dest any) {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join(out, relative))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(body, dest); err != nil {
+		t.Fatalf("%s: %v", relative, err)
+	}
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) <= 1e-9*math.Max(1, math.Abs(want))
+}
@@ -0,0 +1,176 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stubKey is the Comtrade subscription key the stub requires.
+const stubKey = "stub-key"
+
+// stubServer answers WITS requests under /wits/ and Comtrade requests under
+// /comtrade/ from the files in testdata, and records each request path.
+type stubServer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// newStubServer starts a stub server that is closed when the test ends.
+func newStubServer(t *testing.T) *stubServer {
+	t.Helper()
+	s := &stubServer{t: t}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /wits/wits/datasource/tradestats-trade/country/ALL", s.file("wits/reporters.xml", "application/xml"))
+	mux.HandleFunc("GET /wits/wits/datasource/tradestats-trade/dataavailability/country/{reporter}/indicator/{indicator}", s.file("wits/dataavailability.xml", "application/xml"))
+	mux.HandleFunc("GET /wits/SDMX/V21/datasource/tradestats-trade/reporter/{reporter}/year/{year}/partner/{partner}/product/{product}/indicator/{indicator}", s.witsTrade)
+	mux.HandleFunc("GET /comtrade/files/v1/app/reference/Reporters.json", s.file("comtrade/Reporters.json", "application/json"))
+	mux.HandleFunc("GET /comtrade/files/v1/app/reference/partnerAreas.json", s.file("comtrade/partnerAreas.json", "application/json"))
+	mux.HandleFunc("GET /comtrade/data/v1/get/{type}/{freq}/{cl}", s.comtradeData)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// useWITS points the WITS provider at the stub. The rate limit is lifted
+// and every setting a developer's environment might override is reset.
+func (s *stubServer) useWITS(t *testing.T) {
+	t.Setenv("WITS_BASE_URL", s.URL+"/wits/")
+	t.Setenv("WITS_API_KEY", "")
+	for _, key := range []string{"WITS_TRADE_PATH", "WITS_REPORTERS_PATH", "WITS_DATAAVAIL_PATH", "WITS_FORMAT_PARAM", "WITS_FORMAT_VALUE", "WITS_INDICATOR_EXPORT", "WITS_INDICATOR_IMPORT", "WITS_PRODUCT_CODE"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("WITS_VALUE_MULTIPLIER", "1000")
+	t.Setenv("WITS_AUTO_LATEST_YEAR", "true")
+	t.Setenv("WITS_RATE_LIMIT_PER_SEC", "1000")
+	t.Setenv("WITS_RATE_LIMIT_BURST", "1000")
+}
+
+// useComtrade points the Comtrade provider at the stub with a subscription
+// key, so requests take the data API rather than the preview one.
+// lookbackYears sets how many years before the current one FetchLatest asks
+// for.
+func (s *stubServer) useComtrade(t *testing.T, lookbackYears int) {
+	t.Setenv("COMTRADE_BASE_URL", s.URL+"/comtrade/")
+	t.Setenv("COMTRADE_REPORTERS_URL", s.URL+"/comtrade/files/v1/app/reference/Reporters.json")
+	t.Setenv("COMTRADE_PARTNERS_URL", s.URL+"/comtrade/files/v1/app/reference/partnerAreas.json")
+	t.Setenv("COMTRADE_PRIMARY_KEY", stubKey)
+	t.Setenv("COMTRADE_SECONDARY_KEY", "")
+	for _, key := range []string{"COMTRADE_DATA_PATH", "COMTRADE_DATASET", "COMTRADE_TYPE", "COMTRADE_FREQUENCY", "COMTRADE_CLASSIFICATION", "COMTRADE_COMMODITY", "COMTRADE_FLOW_EXPORT", "COMTRADE_FLOW_IMPORT", "COMTRADE_VALUE_MULTIPLIER"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("COMTRADE_LOOKBACK_YEARS", fmt.Sprint(lookbackYears))
+	t.Setenv("COMTRADE_MAX_RETRIES", "0")
+	t.Setenv("COMTRADE_RATE_LIMIT_PER_SEC", "1000")
+	t.Setenv("COMTRADE_RATE_LIMIT_BURST", "1000")
+}
+
+// count returns how many requests had a path containing fragment.
+func (s *stubServer) count(fragment string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, path := range s.requests {
+		if strings.Contains(path, fragment) {
+			count++
+		}
+	}
+	return count
+}
+
+// file serves the testdata file at name.
+func (s *stubServer) file(name, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := os.ReadFile(filepath.Join("testdata", filepath.FromSlash(name)))
+		if err != nil {
+			s.t.Errorf("stub: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}
+}
+
+// witsTrade serves testdata/wits/trade/{reporter}-{partner}-{indicator}.json
+// for any year, and the 404 WITS returns for a series it does not have when
+// there is no such file.
+func (s *stubServer) witsTrade(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("format") != "JSON" {
+		http.Error(w, "stub: want format=JSON", http.StatusBadRequest)
+		return
+	}
+	name := fmt.Sprintf("%s-%s-%s.json", r.PathValue("reporter"), r.PathValue("partner"), r.PathValue("indicator"))
+	if _, err := os.Stat(filepath.Join("testdata", "wits", "trade", name)); err != nil {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><message:Error xmlns:message="http://www.sdmx.org/resources/sdmxml/schemas/v2_1/message"><message:ErrorMessage code="100"><common:Text xmlns:common="http://www.sdmx.org/resources/sdmxml/schemas/v2_1/common">NoRecordsFound</common:Text></message:ErrorMessage></message:Error>`)
+		return
+	}
+	s.file("wits/trade/"+name, "application/json")(w, r)
+}
+
+// comtradeData answers a data API request with the rows of
+// testdata/comtrade/data.json that match its reporter, partner, flow,
+// period, and commodity, the way the API filters its store.
+func (s *stubServer) comtradeData(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Ocp-Apim-Subscription-Key") != stubKey {
+		http.Error(w, `{"statusCode": 401, "message": "Access denied due to missing subscription key."}`, http.StatusUnauthorized)
+		return
+	}
+	body, err := os.ReadFile(filepath.Join("testdata", "comtrade", "data.json"))
+	if err != nil {
+		s.t.Errorf("stub: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var canned struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &canned); err != nil {
+		s.t.Errorf("stub: comtrade/data.json: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	query := r.URL.Query()
+	rows := []map[string]any{}
+	for _, row := range canned.Data {
+		if matchesParam(row["reporterCode"], query.Get("reportercode")) &&
+			matchesParam(row["partnerCode"], query.Get("partnerCode")) &&
+			matchesParam(row["flowCode"], query.Get("flowCode")) &&
+			matchesParam(row["period"], query.Get("period")) &&
+			matchesParam(row["cmdCode"], query.Get("cmdCode")) {
+			rows = append(rows, row)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"elapsedTime": "0.01 secs", "count": len(rows), "data": rows, "error": ""})
+}
+
+// matchesParam reports whether a row value is one of the comma-separated
+// values of a query parameter. An absent parameter matches every row.
+func matchesParam(value any, param string) bool {
+	if param == "" {
+		return true
+	}
+	for _, want := range strings.Split(param, ",") {
+		if fmt.Sprint(value) == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package iso
+
+// countries is the ISO 3166-1 list, sorted by alpha-3 code. English names
+// follow the World Bank short names the providers report where they differ
+// from the ISO ones.
+var countries = []Country{
+	{"ABW", "AW", "533", "Aruba", "아루바"},
+	{"AFG", "AF", "004", "Afghanistan", "아프가니스탄"},
+	{"AGO", "AO", "024", "Angola", "앙골라"},
+	{"AIA", "AI", "660", "Anguilla", "앵귈라"},
+	{"ALA", "AX", "248", "Åland Islands", "올란드 제도"},
+	{"ALB", "AL", "008", "Albania", "알바니아"},
+	{"AND", "AD", "020", "Andorra", "안도라"},
+	{"ARE", "AE", "784", "United Arab Emirates", "아랍에미리트"},
+	{"ARG", "AR", "032", "Argentina", "아르헨티나"},
+	{"ARM", "AM", "051", "Armenia", "아르메니아"},
+	{"ASM", "AS", "016", "American Samoa", "아메리칸사모아"},
+	{"ATA", "AQ", "010", "Antarctica", "남극"},
+	{"ATF", "TF", "260", "French Southern Territories", "프랑스령 남방 및 남극 지역"},
+	{"ATG", "AG", "028", "Antigua and Barbuda", "앤티가 바부다"},
+	{"AUS", "AU", "036", "Australia", "호주"},
+	{"AUT", "AT", "040", "Austria", "오스트리아"},
+	{"AZE", "AZ", "031", "Azerbaijan", "아제르바이잔"},
+	{"BDI", "BI", "108", "Burundi", "부룬디"},
+	{"BEL", "BE", "056", "Belgium", "벨기에"},
+	{"BEN", "BJ", "204", "Benin", "베냉"},
+	{"BES", "BQ", "535", "Bonaire, Sint Eustatius and Saba", "보네르, 신트외스타티위스, 사바"},
+	{"BFA", "BF", "854", "Burkina Faso", "부르키나파소"},
+	{"BGD", "BD", "050", "Bangladesh", "방글라데시"},
+	{"BGR", "BG", "100", "Bulgaria", "불가리아"},
+	{"BHR", "BH", "048", "Bahrain", "바레인"},
+	{"BHS", "BS", "044", "Bahamas", "바하마"},
+	{"BIH", "BA", "070", "Bosnia and Herzegovina", "보스니아 헤르체고비나"},
+	{"BLM", "BL", "652", "Saint Barthélemy", "생바르텔레미"},
+	{"BLR", "BY", "112", "Belarus", "벨라루스"},
+	{"BLZ", "BZ", "084", "Belize", "벨리즈"},
+	{"BMU", "BM", "060", "Bermuda", "버뮤다"},
+	{"BOL", "BO", "068", "Bolivia", "볼리비아"},
+	{"BRA", "BR", "076", "Brazil", "브라질"},
+	{"BRB", "BB", "052", "Barbados", "바베이도스"},
+	{"BRN", "BN", "096", "Brunei Darussalam", "브루나이"},
+	{"BTN", "BT", "064", "Bhutan", "부탄"},
+	{"BVT", "BV", "074", "Bouvet Island", "부베섬"},
+	{"BWA", "BW", "072", "Botswana", "보츠와나"},
+	{"CAF", "CF", "140", "Central African Republic", "중앙아프리카공화국"},
+	{"CAN", "CA", "124", "Canada", "캐나다"},
+	{"CCK", "CC", "166", "Cocos (Keeling) Islands", "코코스 제도"},
+	{"CHE", "CH", "756", "Switzerland", "스위스"},
+	{"CHL", "CL", "152", "Chile", "칠레"},
+	{"CHN", "CN", "156", "China", "중국"},
+	{"CIV", "CI", "384", "Côte d'Ivoire", "코트디부아르"},
+	{"CMR", "CM", "120", "Cameroon", "카메룬"},
+	{"COD", "CD", "180", "Congo, Dem. Rep.", "콩고 민주 공화국"},
+	{"COG", "CG", "178", "Congo, Rep.", "콩고 공화국"},
+	{"COK", "CK", "184", "Cook Islands", "쿡 제도"},
+	{"COL", "CO", "170", "Colombia", "콜롬비아"},
+	{"COM", "KM", "174", "Comoros", "코모로"},
+	{"CPV", "CV", "132", "Cabo Verde", "카보베르데"},
+	{"CRI", "CR", "188", "Costa Rica", "코스타리카"},
+	{"CUB", "CU", "192", "Cuba", "쿠바"},
+	{"CUW", "CW", "531", "Curaçao", "퀴라소"},
+	{"CXR", "CX", "162", "Christmas Island", "크리스마스섬"},
+	{"CYM", "KY", "136", "Cayman Islands", "케이맨 제도"},
+	{"CYP", "CY", "196", "Cyprus", "키프로스"},
+	{"CZE", "CZ", "203", "Czechia", "체코"},
+	{"DEU", "DE", "276", "Germany", "독일"},
+	{"DJI", "DJ", "262", "Djibouti", "지부티"},
+	{"DMA", "DM", "212", "Dominica", "도미니카 연방"},
+	{"DNK", "DK", "208", "Denmark", "덴마크"},
+	{"DOM", "DO", "214", "Dominican Republic", "도미니카 공화국"},
+	{"DZA", "DZ", "012", "Algeria", "알제리"},
+	{"ECU", "EC", "218", "Ecuador", "에콰도르"},
+	{"EGY", "EG", "818", "Egypt", "이집트"},
+	{"ERI", "ER", "232", "Eritrea", "에리트레아"},
+	{"ESH", "EH", "732", "Western Sahara", "서사하라"},
+	{"ESP", "ES", "724", "Spain", "스페인"},
+	{"EST", "EE", "233", "Estonia", "에스토니아"},
+	{"ETH", "ET", "231", "Ethiopia", "에티오피아"},
+	{"FIN", "FI", "246", "Finland", "핀란드"},
+	{"FJI", "FJ", "242", "Fiji", "피지"},
+	{"FLK", "FK", "238", "Falkland Islands", "포클랜드 제도"},
+	{"FRA", "FR", "250", "France", "프랑스"},
+	{"FRO", "FO", "234", "Faroe Islands", "페로 제도"},
+	{"FSM", "FM", "583", "Micronesia", "미크로네시아 연방"},
+	{"GAB", "GA", "266", "Gabon", "가봉"},
+	{"GBR", "GB", "826", "United Kingdom", "영국"},
+	{"GEO", "GE", "268", "Georgia", "조지아"},
+	{"GGY", "GG", "831", "Guernsey", "건지"},
+	{"GHA", "GH", "288", "Ghana", "가나"},
+	{"GIB", "GI", "292", "Gibraltar", "지브롤터"},
+	{"GIN", "GN", "324", "Guinea", "기니"},
+	{"GLP", "GP", "312", "Guadeloupe", "과들루프"},
+	{"GMB", "GM", "270", "Gambia", "감비아"},
+	{"GNB", "GW", "624", "Guinea-Bissau", "기니비사우"},
+	{"GNQ", "GQ", "226", "Equatorial Guinea", "적도 기니"},
+	{"GRC", "GR", "300", "Greece", "그리스"},
+	{"GRD", "GD", "308", "Grenada", "그레나다"},
+	{"GRL", "GL", "304", "Greenland", "그린란드"},
+	{"GTM", "GT", "320", "Guatemala", "과테말라"},
+	{"GUF", "GF", "254", "French Guiana", "프랑스령 기아나"},
+	{"GUM", "GU", "316", "Guam", "괌"},
+	{"GUY", "GY", "328", "Guyana", "가이아나"},
+	{"HKG", "HK", "344", "Hong Kong", "홍콩"},
+	{"HMD", "HM", "334", "Heard Island and McDonald Islands", "허드 맥도널드 제도"},
+	{"HND", "HN", "340", "Honduras", "온두라스"},
+	{"HRV", "HR", "191", "Croatia", "크로아티아"},
+	{"HTI", "HT", "332", "Haiti", "아이티"},
+	{"HUN", "HU", "348", "Hungary", "헝가리"},
+	{"IDN", "ID", "360", "Indonesia", "인도네시아"},
+	{"IMN", "IM", "833", "Isle of Man", "맨섬"},
+	{"IND", "IN", "356", "India", "인도"},
+	{"IOT", "IO", "086", "British Indian Ocean Territory", "영국령 인도양 지역"},
+	{"IRL", "IE", "372", "Ireland", "아일랜드"},
+	{"IRN", "IR", "364", "Iran", "이란"},
+	{"IRQ", "IQ", "368", "Iraq", "이라크"},
+	{"ISL", "IS", "352", "Iceland", "아이슬란드"},
+	{"ISR", "IL", "376", "Israel", "이스라엘"},
+	{"ITA", "IT", "380", "Italy", "이탈리아"},
+	{"JAM", "JM", "388", "Jamaica", "자메이카"},
+	{"JEY", "JE", "832", "Jersey", "저지"},
+	{"JOR", "JO", "400", "Jordan", "요르단"},
+	{"JPN", "JP", "392", "Japan", "일본"},
+	{"KAZ", "KZ", "398", "Kazakhstan", "카자흐스탄"},
+	{"KEN", "KE", "404", "Kenya", "케냐"},
+	{"KGZ", "KG", "417", "Kyrgyzstan", "키르기스스탄"},
+	{"KHM", "KH", "116", "Cambodia", "캄보디아"},
+	{"KIR", "KI", "296", "Kiribati", "키리바시"},
+	{"KNA", "KN", "659", "Saint Kitts and Nevis", "세인트키츠 네비스"},
+	{"KOR", "KR", "410", "Korea, Rep.", "대한민국"},
+	{"KWT", "KW", "414", "Kuwait", "쿠웨이트"},
+	{"LAO", "LA", "418", "Lao PDR", "라오스"},
+	{"LBN", "LB", "422", "Lebanon", "레바논"},
+	{"LBR", "LR", "430", "Liberia", "라이베리아"},
+	{"LBY", "LY", "434", "Libya", "리비아"},
+	{"LCA", "LC", "662", "Saint Lucia", "세인트루시아"},
+	{"LIE", "LI", "438", "Liechtenstein", "리히텐슈타인"},
+	{"LKA", "LK", "144", "Sri Lanka", "스리랑카"},
+	{"LSO", "LS", "426", "Lesotho", "레소토"},
+	{"LTU", "LT", "440", "Lithuania", "리투아니아"},
+	{"LUX", "LU", "442", "Luxembourg", "룩셈부르크"},
+	{"LVA", "LV", "428", "Latvia", "라트비아"},
+	{"MAC", "MO", "446", "Macao", "마카오"},
+	{"MAF", "MF", "663", "Saint Martin (French part)", "생마르탱"},
+	{"MAR", "MA", "504", "Morocco", "모로코"},
+	{"MCO", "MC", "492", "Monaco", "모나코"},
+	{"MDA", "MD", "498", "Moldova", "몰도바"},
+	{"MDG", "MG", "450", "Madagascar", "마다가스카르"},
+	{"MDV", "MV", "462", "Maldives", "몰디브"},
+	{"MEX", "MX", "484", "Mexico", "멕시코"},
+	{"MHL", "MH", "584", "Marshall Islands", "마셜 제도"},
+	{"MKD", "MK", "807", "North Macedonia", "북마케도니아"},
+	{"MLI", "ML", "466", "Mali", "말리"},
+	{"MLT", "MT", "470", "Malta", "몰타"},
+	{"MMR", "MM", "104", "Myanmar", "미얀마"},
+	{"MNE", "ME", "499", "Montenegro", "몬테네그로"},
+	{"MNG", "MN", "496", "Mongolia", "몽골"},
+	{"MNP", "MP", "580", "Northern Mariana Islands", "북마리아나 제도"},
+	{"MOZ", "MZ", "508", "Mozambique", "모잠비크"},
+	{"MRT", "MR", "478", "Mauritania", "모리타니"},
+	{"MSR", "MS", "500", "Montserrat", "몬트세랫"},
+	{"MTQ", "MQ", "474", "Martinique", "마르티니크"},
+	{"MUS", "MU", "480", "Mauritius", "모리셔스"},
+	{"MWI", "MW", "454", "Malawi", "말라위"},
+	{"MYS", "MY", "458", "Malaysia", "말레이시아"},
+	{"MYT", "YT", "175", "Mayotte", "마요트"},
+	{"NAM", "NA", "516", "Namibia", "나미비아"},
+	{"NCL", "NC", "540", "New Caledonia", "누벨칼레도니"},
+	{"NER", "NE", "562", "Niger", "니제르"},
+	{"NFK", "NF", "574", "Norfolk Island", "노퍽섬"},
+	{"NGA", "NG", "566", "Nigeria", "나이지리아"},
+	{"NIC", "NI", "558", "Nicaragua", "니카라과"},
+	{"NIU", "NU", "570", "Niue", "니우에"},
+	{"NLD", "NL", "528", "Netherlands", "네덜란드"},
+	{"NOR", "NO", "578", "Norway", "노르웨이"},
+	{"NPL", "NP", "524", "Nepal", "네팔"},
+	{"NRU", "NR", "520", "Nauru", "나우루"},
+	{"NZL", "NZ", "554", "New Zealand", "뉴질랜드"},
+	{"OMN", "OM", "512", "Oman", "오만"},
+	{"PAK", "PK", "586", "Pakistan", "파키스탄"},
+	{"PAN", "PA", "591", "Panama", "파나마"},
+	{"PCN", "PN", "612", "Pitcairn", "핏케언 제도"},
+	{"PER", "PE", "604", "Peru", "페루"},
+	{"PHL", "PH", "608", "Philippines", "필리핀"},
+	{"PLW", "PW", "585", "Palau", "팔라우"},
+	{"PNG", "PG", "598", "Papua New Guinea", "파푸아뉴기니"},
+	{"POL", "PL", "616", "Poland", "폴란드"},
+	{"PRI", "PR", "630", "Puerto Rico", "푸에르토리코"},
+	{"PRK", "KP", "408", "Korea, Dem. People's Rep.", "조선민주주의인민공화국"},
+	{"PRT", "PT", "620", "Portugal", "포르투갈"},
+	{"PRY", "PY", "600", "Paraguay", "파라과이"},
+	{"PSE", "PS", "275", "Palestine", "팔레스타인"},
+	{"PYF", "PF", "258", "French Polynesia", "프랑스령 폴리네시아"},
+	{"QAT", "QA", "634", "Qatar", "카타르"},
+	{"REU", "RE", "638", "Réunion", "레위니옹"},
+	{"ROU", "RO", "642", "Romania", "루마니아"},
+	{"RUS", "RU", "643", "Russian Federation", "러시아"},
+	{"RWA", "RW", "646", "Rwanda", "르완다"},
+	{"SAU", "SA", "682", "Saudi Arabia", "사우디아라비아"},
+	{"SDN", "SD", "729", "Sudan", "수단"},
+	{"SEN", "SN", "686", "Senegal", "세네갈"},
+	{"SGP", "SG", "702", "Singapore", "싱가포르"},
+	{"SGS", "GS", "239", "South Georgia and the South Sandwich Islands", "사우스조지아 사우스샌드위치 제도"},
+	{"SHN", "SH", "654", "Saint Helena, Ascension and Tristan da Cunha", "세인트헬레나, 어센션, 트리스탄다쿠냐"},
+	{"SJM", "SJ", "744", "Svalbard and Jan Mayen", "스발바르 얀마옌"},
+	{"SLB", "SB", "090", "Solomon Islands", "솔로몬 제도"},
+	{"SLE", "SL", "694", "Sierra Leone", "시에라리온"},
+	{"SLV", "SV", "222", "El Salvador", "엘살바도르"},
+	{"SMR", "SM", "674", "San Marino", "산마리노"},
+	{"SOM", "SO", "706", "Somalia", "소말리아"},
+	{"SPM", "PM", "666", "Saint Pierre and Miquelon", "생피에르 미클롱"},
+	{"SRB", "RS", "688", "Serbia", "세르비아"},
+	{"SSD", "SS", "728", "South Sudan", "남수단"},
+	{"STP", "ST", "678", "Sao Tome and Principe", "상투메 프린시페"},
+	{"SUR", "SR", "740", "Suriname", "수리남"},
+	{"SVK", "SK", "703", "Slovakia", "슬로바키아"},
+	{"SVN", "SI", "705", "Slovenia", "슬로베니아"},
+	{"SWE", "SE", "752", "Sweden", "스웨덴"},
+	{"SWZ", "SZ", "748", "Eswatini", "에스와티니"},
+	{"SXM", "SX", "534", "Sint Maarten (Dutch part)", "신트마르턴"},
+	{"SYC", "SC", "690", "Seychelles", "세이셸"},
+	{"SYR", "SY", "760", "Syrian Arab Republic", "시리아"},
+	{"TCA", "TC", "796", "Turks and Caicos Islands", "터크스 케이커스 제도"},
+	{"TCD", "TD", "148", "Chad", "차드"},
+	{"TGO", "TG", "768", "Togo", "토고"},
+	{"THA", "TH", "764", "Thailand", "태국"},
+	{"TJK", "TJ", "762", "Tajikistan", "타지키스탄"},
+	{"TKL", "TK", "772", "Tokelau", "토켈라우"},
+	{"TKM", "TM", "795", "Turkmenistan", "투르크메니스탄"},
+	{"TLS", "TL", "626", "Timor-Leste", "동티모르"},
+	{"TON", "TO", "776", "Tonga", "통가"},
+	{"TTO", "TT", "780", "Trinidad and Tobago", "트리니다드 토바고"},
+	{"TUN", "TN", "788", "Tunisia", "튀니지"},
+	{"TUR", "TR", "792", "Türkiye", "튀르키예"},
+	{"TUV", "TV", "798", "Tuvalu", "투발루"},
+	{"TWN", "TW", "158", "Chinese Taipei", "대만"},
+	{"TZA", "TZ", "834", "Tanzania", "탄자니아"},
+	{"UGA", "UG", "800", "Uganda", "우간다"},
+	{"UKR", "UA", "804", "Ukraine", "우크라이나"},
+	{"UMI", "UM", "581", "United States Minor Outlying Islands", "미국령 군소 제도"},
+	{"URY", "UY", "858", "Uruguay", "우루과이"},
+	{"USA", "US", "840", "United States", "미국"},
+	{"UZB", "UZ", "860", "Uzbekistan", "우즈베키스탄"},
+	{"VAT", "VA", "336", "Holy See", "바티칸 시국"},
+	{"VCT", "VC", "670", "Saint Vincent and the Grenadines", "세인트빈센트 그레나딘"},
+	{"VEN", "VE", "862", "Venezuela", "베네수엘라"},
+	{"VGB", "VG", "092", "British Virgin Islands", "영국령 버진아일랜드"},
+	{"VIR", "VI", "850", "U.S. Virgin Islands", "미국령 버진아일랜드"},
+	{"VNM", "VN", "704", "Viet Nam", "베트남"},
+	{"VUT", "VU", "548", "Vanuatu", "바누아투"},
+	{"WLF", "WF", "876", "Wallis and Futuna", "월리스 푸투나"},
+	{"WSM", "WS", "882", "Samoa", "사모아"},
+	{"YEM", "YE", "887", "Yemen", "예멘"},
+	{"ZAF", "ZA", "710", "South Africa", "남아프리카공화국"},
+	{"ZMB", "ZM", "894", "Zambia", "잠비아"},
+	{"ZWE", "ZW", "716", "Zimbabwe", "짐바브웨"},
+}
@@ -0,0 +1,92 @@
+package iso
+
+import (
+	"sort"
+	"strings"
+)
+
+// Group is a defined set of countries that is published as one aggregate.
+// Tag is the label configs/countries.csv uses for the group, when it has one.
+type Group struct {
+	ID      string
+	Name    string
+	Tag     string
+	Members []string
+}
+
+// Contains reports whether alpha3 is a member, ignoring case and surrounding
+// space.
+func (g Group) Contains(alpha3 string) bool {
+	alpha3 = strings.ToUpper(strings.TrimSpace(alpha3))
+	index := sort.SearchStrings(g.Members, alpha3)
+	return index < len(g.Members) && g.Members[index] == alpha3
+}
+
+// groups lists membership as of 2025. The EU and OECD lists follow the
+// treaties in force; ASEAN includes Timor-Leste, admitted in October 2025.
+// The Global South has no treaty membership, so it is every country the
+// bundled World Bank classification places in a low or middle income group.
+var groups = []Group{
+	{ID: "EU27", Name: "European Union (27)", Tag: "EU", Members: []string{
+		"AUT", "BEL", "BGR", "CYP", "CZE", "DEU", "DNK", "ESP", "EST", "FIN", "FRA", "GRC", "HRV", "HUN",
+		"IRL", "ITA", "LTU", "LUX", "LVA", "MLT", "NLD", "POL", "PRT", "ROU", "SVK", "SVN", "SWE",
+	}},
+	{ID: "ASEAN", Name: "ASEAN", Tag: "ASEAN", Members: []string{
+		"BRN", "IDN", "KHM", "LAO", "MMR", "MYS", "PHL", "SGP", "THA", "TLS", "VNM",
+	}},
+	{ID: "OECD", Name: "OECD", Tag: "OECD", Members: []string{
+		"AUS", "AUT", "BEL", "CAN", "CHE", "CHL", "COL", "CRI", "CZE", "DEU", "DNK", "ESP", "EST",
+		"FIN", "FRA", "GBR", "GRC", "HUN", "IRL", "ISL", "ISR", "ITA", "JPN", "KOR", "LTU", "LUX",
+		"LVA", "MEX", "NLD", "NOR", "NZL", "POL", "PRT", "SVK", "SVN", "SWE", "TUR", "USA",
+	}},
+	{ID: "GLOBAL_SOUTH", Name: "Global South", Members: incomeGroupMembers("Low income", "Lower middle income", "Upper middle income")},
+}
+
+func incomeGroupMembers(incomeGroups ...string) []string {
+	var members []string
+	for alpha3, classification := range classifications {
+		for _, incomeGroup := range incomeGroups {
+			if classification.IncomeGroup == incomeGroup {
+				members = append(members, alpha3)
+				break
+			}
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// Groups returns every defined group in publication order. Members are
+// sorted alpha-3 codes.
+func Groups() []Group {
+	list := make([]Group, len(groups))
+	for index, group := range groups {
+		group.Members = append([]string(nil), group.Members...)
+		list[index] = group
+	}
+	return list
+}
+
+// LookupGroup finds a group by ID or tag, ignoring case, so "eu" and "EU27"
+// both find the European Union.
+func LookupGroup(id string) (Group, bool) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	for _, group := range groups {
+		if id != "" && (group.ID == id || group.Tag == id) {
+			group.Members = append([]string(nil), group.Members...)
+			return group, true
+		}
+	}
+	return Group{}, false
+}
+
+// GroupsOf returns the IDs of every group alpha3 belongs to.
+func GroupsOf(alpha3 string) []string {
+	var ids []string
+	for _, group := range groups {
+		if group.Contains(alpha3) {
+			ids = append(ids, group.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,125 @@
+// Package iso validates and maps ISO 3166-1 country codes. Reporters and
+// partners are identified by alpha-3 codes everywhere; this package is the
+// one place that decides whether a code names a country.
+package iso
+
+import (
+	"fmt"
+	"strings"
+)
+
+// World is the code the providers use for trade with all partners. It is a
+// World Bank aggregate, not an ISO 3166-1 code, so Valid rejects it and
+// ParseList accepts it only as a partner.
+const World = "WLD"
+
+// Country is one ISO 3166-1 entry. Numeric is the three-digit code with
+// leading zeros.
+type Country struct {
+	Alpha3  string
+	Alpha2  string
+	Numeric string
+	NameEN  string
+	NameKO  string
+}
+
+var (
+	byAlpha3  = make(map[string]Country, len(countries))
+	byAlpha2  = make(map[string]Country, len(countries))
+	byNumeric = make(map[string]Country, len(countries))
+)
+
+func init() {
+	for _, country := range countries {
+		byAlpha3[country.Alpha3] = country
+		byAlpha2[country.Alpha2] = country
+		byNumeric[country.Numeric] = country
+	}
+}
+
+// Countries returns every country, sorted by alpha-3 code.
+func Countries() []Country {
+	return append([]Country(nil), countries...)
+}
+
+// Lookup finds a country by alpha-3 code, ignoring case and surrounding
+// space.
+func Lookup(alpha3 string) (Country, bool) {
+	country, ok := byAlpha3[strings.ToUpper(strings.TrimSpace(alpha3))]
+	return country, ok
+}
+
+// Valid reports whether alpha3 is an assigned alpha-3 code.
+func Valid(alpha3 string) bool {
+	_, ok := Lookup(alpha3)
+	return ok
+}
+
+// Normalize returns the upper-case alpha-3 code for value, or an error when
+// it does not name a country.
+func Normalize(value string) (string, error) {
+	country, ok := Lookup(value)
+	if !ok {
+		return "", fmt.Errorf("unknown ISO 3166-1 alpha-3 code %q", strings.TrimSpace(value))
+	}
+	return country.Alpha3, nil
+}
+
+// FromAlpha2 finds a country by alpha-2 code, ignoring case.
+func FromAlpha2(alpha2 string) (Country, bool) {
+	country, ok := byAlpha2[strings.ToUpper(strings.TrimSpace(alpha2))]
+	return country, ok
+}
+
+// FromNumeric finds a country by numeric code, with or without leading
+// zeros, so "4" and "004" both find Afghanistan.
+func FromNumeric(numeric string) (Country, bool) {
+	numeric = strings.TrimSpace(numeric)
+	if numeric == "" || len(numeric) > 3 {
+		return Country{}, false
+	}
+	for _, digit := range numeric {
+		if digit < '0' || digit > '9' {
+			return Country{}, false
+		}
+	}
+	country, ok := byNumeric[strings.Repeat("0", 3-len(numeric))+numeric]
+	return country, ok
+}
+
+// Classification places a country in a World Bank region and income group
+// and a UN M49 subregion, such as "East Asia & Pacific", "High income", and
+// "Eastern Asia". Empty fields are unclassified.
+type Classification struct {
+	Region      string
+	Subregion   string
+	IncomeGroup string
+}
+
+// Classify returns the classification of an alpha-3 code, ignoring case and
+// surrounding space.
+func Classify(alpha3 string) (Classification, bool) {
+	classification, ok := classifications[strings.ToUpper(strings.TrimSpace(alpha3))]
+	return classification, ok
+}
+
+// ParseList splits a comma-separated list of alpha-3 codes, normalizing
+// each and dropping empty entries. World is accepted alongside countries.
+func ParseList(value string) ([]string, error) {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if item != World {
+			normalized, err := Normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			item = normalized
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
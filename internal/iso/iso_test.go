@@ -0,0 +1,104 @@
+package iso
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCountriesAreCompleteAndUnique(t *testing.T) {
+	list := Countries()
+	if len(list) != 249 {
+		t.Fatalf("Countries() has %d entries, want the 249 ISO 3166-1 codes", len(list))
+	}
+	if !sort.SliceIsSorted(list, func(i, j int) bool { return list[i].Alpha3 < list[j].Alpha3 }) {
+		t.Fatalf("Countries() is not sorted by alpha-3")
+	}
+	if len(byAlpha3) != len(list) || len(byAlpha2) != len(list) || len(byNumeric) != len(list) {
+		t.Fatalf("duplicate codes: %d alpha-3, %d alpha-2, %d numeric for %d countries", len(byAlpha3), len(byAlpha2), len(byNumeric), len(list))
+	}
+	for _, country := range list {
+		if len(country.Alpha3) != 3 || len(country.Alpha2) != 2 || len(country.Numeric) != 3 || country.NameEN == "" || country.NameKO == "" {
+			t.Fatalf("incomplete entry %+v", country)
+		}
+	}
+}
+
+func TestLookupAndMappings(t *testing.T) {
+	korea, ok := Lookup(" kor ")
+	if !ok || korea.Alpha2 != "KR" || korea.Numeric != "410" || korea.NameKO != "대한민국" {
+		t.Fatalf("Lookup(kor) = %+v, %t", korea, ok)
+	}
+	if country, ok := FromNumeric("4"); !ok || country.Alpha3 != "AFG" {
+		t.Fatalf("FromNumeric(4) = %+v, %t, want AFG", country, ok)
+	}
+	if country, ok := FromNumeric("842"); ok {
+		t.Fatalf("FromNumeric(842) = %+v, want no match (USA is 840)", country)
+	}
+	if country, ok := FromAlpha2("vn"); !ok || country.Alpha3 != "VNM" {
+		t.Fatalf("FromAlpha2(vn) = %+v, %t, want VNM", country, ok)
+	}
+	if Valid(World) || Valid("XKX") || Valid("KO") {
+		t.Fatalf("Valid accepted a code outside ISO 3166-1")
+	}
+	if _, err := Normalize("ABC"); err == nil {
+		t.Fatalf("Normalize(ABC) should fail")
+	}
+}
+
+func TestParseListNormalizesAndAcceptsWorld(t *testing.T) {
+	got, err := ParseList(" usa, chn,,wld ")
+	if err != nil {
+		t.Fatalf("ParseList() error = %v", err)
+	}
+	if len(got) != 3 || got[0] != "USA" || got[1] != "CHN" || got[2] != World {
+		t.Fatalf("ParseList() = %v, want [USA CHN WLD]", got)
+	}
+	if _, err := ParseList("USA,EUR"); err == nil {
+		t.Fatalf("ParseList() should reject EUR")
+	}
+}
+
+func TestClassifyCoversEveryCountry(t *testing.T) {
+	for _, country := range Countries() {
+		classification, ok := Classify(country.Alpha3)
+		if !ok || classification.Subregion == "" && country.Alpha3 != "ATA" {
+			t.Fatalf("Classify(%s) = %+v, %t, want a subregion", country.Alpha3, classification, ok)
+		}
+		if (classification.Region == "") != (classification.IncomeGroup == "") && country.Alpha3 != "VEN" {
+			t.Fatalf("Classify(%s) = %+v, want region and income group together", country.Alpha3, classification)
+		}
+	}
+	korea, ok := Classify(" kor ")
+	if !ok || korea.Region != "East Asia & Pacific" || korea.Subregion != "Eastern Asia" || korea.IncomeGroup != "High income" {
+		t.Fatalf("Classify(kor) = %+v, %t", korea, ok)
+	}
+	if _, ok := Classify(World); ok {
+		t.Fatalf("Classify(WLD) should not classify an aggregate")
+	}
+}
+
+func TestGroupsHaveValidSortedMembers(t *testing.T) {
+	sizes := map[string]int{"EU27": 27, "ASEAN": 11, "OECD": 38}
+	for _, group := range Groups() {
+		if want, ok := sizes[group.ID]; ok && len(group.Members) != want {
+			t.Fatalf("%s has %d members, want %d", group.ID, len(group.Members), want)
+		}
+		if !sort.StringsAreSorted(group.Members) || len(group.Members) == 0 {
+			t.Fatalf("%s members are empty or unsorted: %v", group.ID, group.Members)
+		}
+		for index, member := range group.Members {
+			if !Valid(member) || (index > 0 && member == group.Members[index-1]) {
+				t.Fatalf("%s has invalid or duplicate member %q", group.ID, member)
+			}
+		}
+	}
+	if eu, ok := LookupGroup("eu"); !ok || eu.ID != "EU27" || !eu.Contains("deu") || eu.Contains("GBR") {
+		t.Fatalf("LookupGroup(eu) = %+v, %t", eu, ok)
+	}
+	if south, ok := LookupGroup("GLOBAL_SOUTH"); !ok || !south.Contains("VNM") || !south.Contains("CHN") || south.Contains("KOR") || south.Contains("VEN") {
+		t.Fatalf("Global South membership = %v", south.Members)
+	}
+	if got := GroupsOf("FRA"); len(got) != 2 || got[0] != "EU27" || got[1] != "OECD" {
+		t.Fatalf("GroupsOf(FRA) = %v", got)
+	}
+}
@@ -0,0 +1,257 @@
+package iso
+
+// classifications holds the World Bank region and income group (FY2025
+// classification) and the UN M49 subregion of every country. Territories the
+// World Bank does not classify have no region or income group, and Venezuela
+// has no income group while its classification is suspended.
+var classifications = map[string]Classification{
+	"ABW": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"AFG": {"South Asia", "Southern Asia", "Low income"},
+	"AGO": {"Sub-Saharan Africa", "Middle Africa", "Lower middle income"},
+	"AIA": {"", "Caribbean", ""},
+	"ALA": {"", "Northern Europe", ""},
+	"ALB": {"Europe & Central Asia", "Southern Europe", "Upper middle income"},
+	"AND": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"ARE": {"Middle East & North Africa", "Western Asia", "High income"},
+	"ARG": {"Latin America & Caribbean", "South America", "Upper middle income"},
+	"ARM": {"Europe & Central Asia", "Western Asia", "Upper middle income"},
+	"ASM": {"East Asia & Pacific", "Polynesia", "High income"},
+	"ATA": {"", "", ""},
+	"ATF": {"", "Eastern Africa", ""},
+	"ATG": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"AUS": {"East Asia & Pacific", "Australia and New Zealand", "High income"},
+	"AUT": {"Europe & Central Asia", "Western Europe", "High income"},
+	"AZE": {"Europe & Central Asia", "Western Asia", "Upper middle income"},
+	"BDI": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"BEL": {"Europe & Central Asia", "Western Europe", "High income"},
+	"BEN": {"Sub-Saharan Africa", "Western Africa", "Lower middle income"},
+	"BES": {"", "Caribbean", ""},
+	"BFA": {"Sub-Saharan Africa", "Western Africa", "Low income"},
+	"BGD": {"South Asia", "Southern Asia", "Lower middle income"},
+	"BGR": {"Europe & Central Asia", "Eastern Europe", "High income"},
+	"BHR": {"Middle East & North Africa", "Western Asia", "High income"},
+	"BHS": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"BIH": {"Europe & Central Asia", "Southern Europe", "Upper middle income"},
+	"BLM": {"", "Caribbean", ""},
+	"BLR": {"Europe & Central Asia", "Eastern Europe", "Upper middle income"},
+	"BLZ": {"Latin America & Caribbean", "Central America", "Upper middle income"},
+	"BMU": {"North America", "Northern America", "High income"},
+	"BOL": {"Latin America & Caribbean", "South America", "Lower middle income"},
+	"BRA": {"Latin America & Caribbean", "South America", "Upper middle income"},
+	"BRB": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"BRN": {"East Asia & Pacific", "South-eastern Asia", "High income"},
+	"BTN": {"South Asia", "Southern Asia", "Lower middle income"},
+	"BVT": {"", "South America", ""},
+	"BWA": {"Sub-Saharan Africa", "Southern Africa", "Upper middle income"},
+	"CAF": {"Sub-Saharan Africa", "Middle Africa", "Low income"},
+	"CAN": {"North America", "Northern America", "High income"},
+	"CCK": {"", "Australia and New Zealand", ""},
+	"CHE": {"Europe & Central Asia", "Western Europe", "High income"},
+	"CHL": {"Latin America & Caribbean", "South America", "High income"},
+	"CHN": {"East Asia & Pacific", "Eastern Asia", "Upper middle income"},
+	"CIV": {"Sub-Saharan Africa", "Western Africa", "Lower middle income"},
+	"CMR": {"Sub-Saharan Africa", "Middle Africa", "Lower middle income"},
+	"COD": {"Sub-Saharan Africa", "Middle Africa", "Low income"},
+	"COG": {"Sub-Saharan Africa", "Middle Africa", "Lower middle income"},
+	"COK": {"", "Polynesia", ""},
+	"COL": {"Latin America & Caribbean", "South America", "Upper middle income"},
+	"COM": {"Sub-Saharan Africa", "Eastern Africa", "Lower middle income"},
+	"CPV": {"Sub-Saharan Africa", "Western Africa", "Lower middle income"},
+	"CRI": {"Latin America & Caribbean", "Central America", "Upper middle income"},
+	"CUB": {"Latin America & Caribbean", "Caribbean", "Upper middle income"},
+	"CUW": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"CXR": {"", "Australia and New Zealand", ""},
+	"CYM": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"CYP": {"Europe & Central Asia", "Western Asia", "High income"},
+	"CZE": {"Europe & Central Asia", "Eastern Europe", "High income"},
+	"DEU": {"Europe & Central Asia", "Western Europe", "High income"},
+	"DJI": {"Middle East & North Africa", "Eastern Africa", "Lower middle income"},
+	"DMA": {"Latin America & Caribbean", "Caribbean", "Upper middle income"},
+	"DNK": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"DOM": {"Latin America & Caribbean", "Caribbean", "Upper middle income"},
+	"DZA": {"Middle East & North Africa", "Northern Africa", "Upper middle income"},
+	"ECU": {"Latin America & Caribbean", "South America", "Upper middle income"},
+	"EGY": {"Middle East & North Africa", "Northern Africa", "Lower middle income"},
+	"ERI": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"ESH": {"", "Northern Africa", ""},
+	"ESP": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"EST": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"ETH": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"FIN": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"FJI": {"East Asia & Pacific", "Melanesia", "Upper middle income"},
+	"FLK": {"", "South America", ""},
+	"FRA": {"Europe & Central Asia", "Western Europe", "High income"},
+	"FRO": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"FSM": {"East Asia & Pacific", "Micronesia", "Lower middle income"},
+	"GAB": {"Sub-Saharan Africa", "Middle Africa", "Upper middle income"},
+	"GBR": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"GEO": {"Europe & Central Asia", "Western Asia", "Upper middle income"},
+	"GGY": {"", "Northern Europe", ""},
+	"GHA": {"Sub-Saharan Africa", "Western Africa", "Lower middle income"},
+	"GIB": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"GIN": {"Sub-Saharan Africa", "Western Africa", "Lower middle income"},
+	"GLP": {"", "Caribbean", ""},
+	"GMB": {"Sub-Saharan Africa", "Western Africa", "Low income"},
+	"GNB": {"Sub-Saharan Africa", "Western Africa", "Low income"},
+	"GNQ": {"Sub-Saharan Africa", "Middle Africa", "Upper middle income"},
+	"GRC": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"GRD": {"Latin America & Caribbean", "Caribbean", "Upper middle income"},
+	"GRL": {"Europe & Central Asia", "Northern America", "High income"},
+	"GTM": {"Latin America & Caribbean", "Central America", "Upper middle income"},
+	"GUF": {"", "South America", ""},
+	"GUM": {"East Asia & Pacific", "Micronesia", "High income"},
+	"GUY": {"Latin America & Caribbean", "South America", "High income"},
+	"HKG": {"East Asia & Pacific", "Eastern Asia", "High income"},
+	"HMD": {"", "Australia and New Zealand", ""},
+	"HND": {"Latin America & Caribbean", "Central America", "Lower middle income"},
+	"HRV": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"HTI": {"Latin America & Caribbean", "Caribbean", "Lower middle income"},
+	"HUN": {"Europe & Central Asia", "Eastern Europe", "High income"},
+	"IDN": {"East Asia & Pacific", "South-eastern Asia", "Upper middle income"},
+	"IMN": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"IND": {"South Asia", "Southern Asia", "Lower middle income"},
+	"IOT": {"", "Eastern Africa", ""},
+	"IRL": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"IRN": {"Middle East & North Africa", "Southern Asia", "Upper middle income"},
+	"IRQ": {"Middle East & North Africa", "Western Asia", "Upper middle income"},
+	"ISL": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"ISR": {"Middle East & North Africa", "Western Asia", "High income"},
+	"ITA": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"JAM": {"Latin America & Caribbean", "Caribbean", "Upper middle income"},
+	"JEY": {"", "Northern Europe", ""},
+	"JOR": {"Middle East & North Africa", "Western Asia", "Lower middle income"},
+	"JPN": {"East Asia & Pacific", "Eastern Asia", "High income"},
+	"KAZ": {"Europe & Central Asia", "Central Asia", "Upper middle income"},
+	"KEN": {"Sub-Saharan Africa", "Eastern Africa", "Lower middle income"},
+	"KGZ": {"Europe & Central Asia", "Central Asia", "Lower middle income"},
+	"KHM": {"East Asia & Pacific", "South-eastern Asia", "Lower middle income"},
+	"KIR": {"East Asia & Pacific", "Micronesia", "Lower middle income"},
+	"KNA": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"KOR": {"East Asia & Pacific", "Eastern Asia", "High income"},
+	"KWT": {"Middle East & North Africa", "Western Asia", "High income"},
+	"LAO": {"East Asia & Pacific", "South-eastern Asia", "Lower middle income"},
+	"LBN": {"Middle East & North Africa", "Western Asia", "Lower middle income"},
+	"LBR": {"Sub-Saharan Africa", "Western Africa", "Low income"},
+	"LBY": {"Middle East & North Africa", "Northern Africa", "Upper middle income"},
+	"LCA": {"Latin America & Caribbean", "Caribbean", "Upper middle income"},
+	"LIE": {"Europe & Central Asia", "Western Europe", "High income"},
+	"LKA": {"South Asia", "Southern Asia", "Lower middle income"},
+	"LSO": {"Sub-Saharan Africa", "Southern Africa", "Lower middle income"},
+	"LTU": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"LUX": {"Europe & Central Asia", "Western Europe", "High income"},
+	"LVA": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"MAC": {"East Asia & Pacific", "Eastern Asia", "High income"},
+	"MAF": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"MAR": {"Middle East & North Africa", "Northern Africa", "Lower middle income"},
+	"MCO": {"Europe & Central Asia", "Western Europe", "High income"},
+	"MDA": {"Europe & Central Asia", "Eastern Europe", "Upper middle income"},
+	"MDG": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"MDV": {"South Asia", "Southern Asia", "Upper middle income"},
+	"MEX": {"Latin America & Caribbean", "Central America", "Upper middle income"},
+	"MHL": {"East Asia & Pacific", "Micronesia", "Upper middle income"},
+	"MKD": {"Europe & Central Asia", "Southern Europe", "Upper middle income"},
+	"MLI": {"Sub-Saharan Africa", "Western Africa", "Low income"},
+	"MLT": {"Middle East & North Africa", "Southern Europe", "High income"},
+	"MMR": {"East Asia & Pacific", "South-eastern Asia", "Lower middle income"},
+	"MNE": {"Europe & Central Asia", "Southern Europe", "Upper middle income"},
+	"MNG": {"East Asia & Pacific", "Eastern Asia", "Upper middle income"},
+	"MNP": {"East Asia & Pacific", "Micronesia", "High income"},
+	"MOZ": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"MRT": {"Sub-Saharan Africa", "Western Africa", "Lower middle income"},
+	"MSR": {"", "Caribbean", ""},
+	"MTQ": {"", "Caribbean", ""},
+	"MUS": {"Sub-Saharan Africa", "Eastern Africa", "Upper middle income"},
+	"MWI": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"MYS": {"East Asia & Pacific", "South-eastern Asia", "Upper middle income"},
+	"MYT": {"", "Eastern Africa", ""},
+	"NAM": {"Sub-Saharan Africa", "Southern Africa", "Upper middle income"},
+	"NCL": {"East Asia & Pacific", "Melanesia", "High income"},
+	"NER": {"Sub-Saharan Africa", "Western Africa", "Low income"},
+	"NFK": {"", "Australia and New Zealand", ""},
+	"NGA": {"Sub-Saharan Africa", "Western Africa", "Lower middle income"},
+	"NIC": {"Latin America & Caribbean", "Central America", "Lower middle income"},
+	"NIU": {"", "Polynesia", ""},
+	"NLD": {"Europe & Central Asia", "Western Europe", "High income"},
+	"NOR": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"NPL": {"South Asia", "Southern Asia", "Lower middle income"},
+	"NRU": {"East Asia & Pacific", "Micronesia", "High income"},
+	"NZL": {"East Asia & Pacific", "Australia and New Zealand", "High income"},
+	"OMN": {"Middle East & North Africa", "Western Asia", "High income"},
+	"PAK": {"South Asia", "Southern Asia", "Lower middle income"},
+	"PAN": {"Latin America & Caribbean", "Central America", "High income"},
+	"PCN": {"", "Polynesia", ""},
+	"PER": {"Latin America & Caribbean", "South America", "Upper middle income"},
+	"PHL": {"East Asia & Pacific", "South-eastern Asia", "Lower middle income"},
+	"PLW": {"East Asia & Pacific", "Micronesia", "High income"},
+	"PNG": {"East Asia & Pacific", "Melanesia", "Lower middle income"},
+	"POL": {"Europe & Central Asia", "Eastern Europe", "High income"},
+	"PRI": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"PRK": {"East Asia & Pacific", "Eastern Asia", "Low income"},
+	"PRT": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"PRY": {"Latin America & Caribbean", "South America", "Upper middle income"},
+	"PSE": {"Middle East & North Africa", "Western Asia", "Lower middle income"},
+	"PYF": {"East Asia & Pacific", "Polynesia", "High income"},
+	"QAT": {"Middle East & North Africa", "Western Asia", "High income"},
+	"REU": {"", "Eastern Africa", ""},
+	"ROU": {"Europe & Central Asia", "Eastern Europe", "High income"},
+	"RUS": {"Europe & Central Asia", "Eastern Europe", "High income"},
+	"RWA": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"SAU": {"Middle East & North Africa", "Western Asia", "High income"},
+	"SDN": {"Sub-Saharan Africa", "Northern Africa", "Low income"},
+	"SEN": {"Sub-Saharan Africa", "Western Africa", "Lower middle income"},
+	"SGP": {"East Asia & Pacific", "South-eastern Asia", "High income"},
+	"SGS": {"", "South America", ""},
+	"SHN": {"", "Western Africa", ""},
+	"SJM": {"", "Northern Europe", ""},
+	"SLB": {"East Asia & Pacific", "Melanesia", "Lower middle income"},
+	"SLE": {"Sub-Saharan Africa", "Western Africa", "Low income"},
+	"SLV": {"Latin America & Caribbean", "Central America", "Upper middle income"},
+	"SMR": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"SOM": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"SPM": {"", "Northern America", ""},
+	"SRB": {"Europe & Central Asia", "Southern Europe", "Upper middle income"},
+	"SSD": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"STP": {"Sub-Saharan Africa", "Middle Africa", "Lower middle income"},
+	"SUR": {"Latin America & Caribbean", "South America", "Upper middle income"},
+	"SVK": {"Europe & Central Asia", "Eastern Europe", "High income"},
+	"SVN": {"Europe & Central Asia", "Southern Europe", "High income"},
+	"SWE": {"Europe & Central Asia", "Northern Europe", "High income"},
+	"SWZ": {"Sub-Saharan Africa", "Southern Africa", "Lower middle income"},
+	"SXM": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"SYC": {"Sub-Saharan Africa", "Eastern Africa", "High income"},
+	"SYR": {"Middle East & North Africa", "Western Asia", "Low income"},
+	"TCA": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"TCD": {"Sub-Saharan Africa", "Middle Africa", "Low income"},
+	"TGO": {"Sub-Saharan Africa", "Western Africa", "Low income"},
+	"THA": {"East Asia & Pacific", "South-eastern Asia", "Upper middle income"},
+	"TJK": {"Europe & Central Asia", "Central Asia", "Lower middle income"},
+	"TKL": {"", "Polynesia", ""},
+	"TKM": {"Europe & Central Asia", "Central Asia", "Upper middle income"},
+	"TLS": {"East Asia & Pacific", "South-eastern Asia", "Lower middle income"},
+	"TON": {"East Asia & Pacific", "Polynesia", "Upper middle income"},
+	"TTO": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"TUN": {"Middle East & North Africa", "Northern Africa", "Lower middle income"},
+	"TUR": {"Europe & Central Asia", "Western Asia", "Upper middle income"},
+	"TUV": {"East Asia & Pacific", "Polynesia", "Upper middle income"},
+	"TWN": {"East Asia & Pacific", "Eastern Asia", "High income"},
+	"TZA": {"Sub-Saharan Africa", "Eastern Africa", "Lower middle income"},
+	"UGA": {"Sub-Saharan Africa", "Eastern Africa", "Low income"},
+	"UKR": {"Europe & Central Asia", "Eastern Europe", "Upper middle income"},
+	"UMI": {"", "Micronesia", ""},
+	"URY": {"Latin America & Caribbean", "South America", "High income"},
+	"USA": {"North America", "Northern America", "High income"},
+	"UZB": {"Europe & Central Asia", "Central Asia", "Lower middle income"},
+	"VAT": {"", "Southern Europe", ""},
+	"VCT": {"Latin America & Caribbean", "Caribbean", "Upper middle income"},
+	"VEN": {"Latin America & Caribbean", "South America", ""},
+	"VGB": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"VIR": {"Latin America & Caribbean", "Caribbean", "High income"},
+	"VNM": {"East Asia & Pacific", "South-eastern Asia", "Lower middle income"},
+	"VUT": {"East Asia & Pacific", "Melanesia", "Lower middle income"},
+	"WLF": {"", "Polynesia", ""},
+	"WSM": {"East Asia & Pacific", "Polynesia", "Lower middle income"},
+	"YEM": {"Middle East & North Africa", "Western Asia", "Low income"},
+	"ZAF": {"Sub-Saharan Africa", "Southern Africa", "Upper middle income"},
+	"ZMB": {"Sub-Saharan Africa", "Eastern Africa", "Lower middle income"},
+	"ZWE": {"Sub-Saharan Africa", "Eastern Africa", "Lower middle income"},
+}
@@ -0,0 +1,225 @@
+// Package logfile keeps the output of long-running commands in log files
+// that rotate by size and age, so a daemon on a VPS keeps a bounded,
+// diagnosable history without an external log shipper.
+package logfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const stampLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Options configures the logs. Path receives stdout and stderr. ErrorPath,
+// when set, receives stderr alone as well. A file is rotated once it would
+// grow past MaxSize bytes or has been open for MaxAge; zero disables either
+// limit. Keep rotated files are kept per log, and zero keeps them all.
+type Options struct {
+	Path      string
+	ErrorPath string
+	MaxSize   int64
+	MaxAge    time.Duration
+	Keep      int
+}
+
+// Writer is a log file that rotates itself. Rotated files are renamed to
+// the path with the time the file was opened appended, such as
+// tradegravity.log.20260102T030405Z.
+type Writer struct {
+	path    string
+	maxSize int64
+	maxAge  time.Duration
+	keep    int
+	now     func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// Open opens or appends to the log at path.
+func Open(path string, opts Options) (*Writer, error) {
+	w := &Writer{path: path, maxSize: opts.MaxSize, maxAge: opts.MaxAge, keep: opts.Keep, now: time.Now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file, w.size, w.opened = file, info.Size(), w.now()
+	if info.Size() > 0 {
+		// An appended file keeps the age it already has.
+		w.opened = info.ModTime()
+	}
+	return nil
+}
+
+// Write appends p, rotating first when p would pass a limit.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size > 0 && ((w.maxSize > 0 && w.size+int64(len(p)) > w.maxSize) || (w.maxAge > 0 && w.now().Sub(w.opened) >= w.maxAge)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	rotated := w.path + "." + w.opened.UTC().Format("20060102T150405Z")
+	for index := 1; ; index++ {
+		if _, err := os.Stat(rotated); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		rotated = fmt.Sprintf("%s.%s.%d", w.path, w.opened.UTC().Format("20060102T150405Z"), index)
+	}
+	if err := os.Rename(w.path, rotated); err != nil {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.prune()
+}
+
+// prune removes the oldest rotated files beyond keep.
+func (w *Writer) prune() error {
+	if w.keep <= 0 {
+		return nil
+	}
+	rotated, err := filepath.Glob(w.path + ".[0-9][0-9][0-9][0-9][0-9][0-9][0-9][0-9]T*Z*")
+	if err != nil {
+		return err
+	}
+	// The rotation stamps sort in time order.
+	sort.Strings(rotated)
+	for len(rotated) > w.keep {
+		if err := os.Remove(rotated[0]); err != nil {
+			return err
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
+
+// Close closes the current file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// stamper prefixes each complete line with the time it was written. A
+// partial line waits for its newline or for flush.
+type stamper struct {
+	w       io.Writer
+	now     func() time.Time
+	pending []byte
+}
+
+func (s *stamper) Write(p []byte) (int, error) {
+	s.pending = append(s.pending, p...)
+	for {
+		end := bytes.IndexByte(s.pending, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+		if err := s.line(s.pending[:end+1]); err != nil {
+			return 0, err
+		}
+		s.pending = s.pending[end+1:]
+	}
+}
+
+func (s *stamper) line(line []byte) error {
+	_, err := s.w.Write(append([]byte(s.now().Format(stampLayout)+" "), line...))
+	return err
+}
+
+func (s *stamper) flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	err := s.line(append(s.pending, '\n'))
+	s.pending = nil
+	return err
+}
+
+// Run starts cmd with its stdout and stderr written, line by line with a
+// timestamp, to the logs in opts, forwards interrupt and terminate signals
+// to it, and returns its exit code once it ends. Output is captured from a
+// child process so that nothing written just before an exit is lost.
+func Run(cmd *exec.Cmd, opts Options) (int, error) {
+	log, err := Open(opts.Path, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer log.Close()
+	out := &stamper{w: log, now: time.Now}
+	errOut := &stamper{w: log, now: time.Now}
+	cmd.Stdout, cmd.Stderr = out, errOut
+	stampers := []*stamper{out, errOut}
+	if strings.TrimSpace(opts.ErrorPath) != "" {
+		errorLog, err := Open(opts.ErrorPath, opts)
+		if err != nil {
+			return 0, err
+		}
+		defer errorLog.Close()
+		errorsOnly := &stamper{w: errorLog, now: time.Now}
+		cmd.Stderr = io.MultiWriter(errOut, errorsOnly)
+		stampers = append(stampers, errorsOnly)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for sig := range signals {
+			cmd.Process.Signal(sig)
+		}
+	}()
+	err = cmd.Wait()
+	for _, s := range stampers {
+		s.flush()
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		// A child ended by a signal reports -1.
+		return max(exit.ExitCode(), 1), nil
+	}
+	return 0, err
+}
@@ -0,0 +1,68 @@
+package logfile
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterRotatesBySizeAndAgeAndKeepsTheNewest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tradegravity.log")
+	clock := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	w := &Writer{path: path, maxSize: 10, maxAge: time.Hour, keep: 2, now: func() time.Time { return clock }}
+	if err := w.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	write := func(text string) {
+		t.Helper()
+		if _, err := w.Write([]byte(text)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("12345\n")
+	write("6789\n") // 11 bytes would pass the limit
+	clock = clock.Add(30 * time.Minute)
+	write("a\n")
+	clock = clock.Add(time.Hour)
+	write("b\n") // the file has been open an hour
+	clock = clock.Add(2 * time.Hour)
+	write("c\n")
+
+	current, err := os.ReadFile(path)
+	if err != nil || string(current) != "c\n" {
+		t.Fatalf("current log = %q, %v", current, err)
+	}
+	rotated, _ := filepath.Glob(path + ".*")
+	if len(rotated) != 2 || !strings.HasSuffix(rotated[0], ".20260102T030405Z.1") || !strings.HasSuffix(rotated[1], ".20260102T043405Z") {
+		t.Fatalf("rotated = %v, want the two newest", rotated)
+	}
+	if body, _ := os.ReadFile(rotated[1]); string(body) != "b\n" {
+		t.Fatalf("newest rotated file = %q", body)
+	}
+}
+
+func TestRunStampsOutputAndSplitsErrors(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Path: filepath.Join(dir, "run.log"), ErrorPath: filepath.Join(dir, "run.log.err")}
+	code, err := Run(exec.Command("sh", "-c", "echo collected; echo failed >&2; printf partial; exit 3"), opts)
+	if err != nil || code != 3 {
+		t.Fatalf("Run() = %d, %v, want exit code 3", code, err)
+	}
+	log, _ := os.ReadFile(opts.Path)
+	errorLog, _ := os.ReadFile(opts.ErrorPath)
+	lines := strings.Split(strings.TrimSuffix(string(log), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], " collected") || !strings.HasSuffix(lines[2], " partial") {
+		t.Fatalf("log = %q", log)
+	}
+	if _, err := time.Parse(stampLayout, strings.Fields(lines[0])[0]); err != nil {
+		t.Fatalf("line without a timestamp: %q", lines[0])
+	}
+	if strings.Count(string(errorLog), "\n") != 1 || !strings.HasSuffix(string(errorLog), " failed\n") {
+		t.Fatalf("error log = %q", errorLog)
+	}
+}
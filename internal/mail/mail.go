@@ -0,0 +1,156 @@
+// Package mail sends plain-text email through an SMTP relay with net/smtp.
+// Port 465 uses implicit TLS; other ports upgrade with STARTTLS when the
+// server offers it.
+package mail
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"tradegravity/internal/secrets"
+)
+
+const (
+	defaultPort           = "587"
+	defaultTimeoutSeconds = 30
+)
+
+// Config is the relay and the account to send as. Username and Password
+// are optional for relays that accept unauthenticated mail.
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// ConfigFromEnv reads SMTP_HOST, SMTP_PORT (default 587), SMTP_USERNAME,
+// SMTP_PASSWORD, and SMTP_FROM, which defaults to the username.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Host:     strings.TrimSpace(os.Getenv("SMTP_HOST")),
+		Port:     getenv("SMTP_PORT", defaultPort),
+		Username: strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+		Timeout:  defaultTimeoutSeconds * time.Second,
+	}
+	if cfg.Host == "" {
+		return Config{}, errors.New("missing SMTP_HOST for email")
+	}
+	var err error
+	if cfg.Password, err = secrets.Lookup("SMTP_PASSWORD"); err != nil {
+		return Config{}, err
+	}
+	cfg.From = getenv("SMTP_FROM", cfg.Username)
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return Config{}, fmt.Errorf("SMTP_FROM %q: %w", cfg.From, err)
+	}
+	return cfg, nil
+}
+
+// ParseRecipients reads a comma-separated address list.
+func ParseRecipients(value string) ([]string, error) {
+	list, err := mail.ParseAddressList(value)
+	if err != nil {
+		return nil, err
+	}
+	recipients := make([]string, len(list))
+	for index, address := range list {
+		recipients[index] = address.Address
+	}
+	return recipients, nil
+}
+
+// Send mails body as UTF-8 plain text from cfg.From to every recipient in
+// one message.
+func Send(cfg Config, to []string, subject, body string) error {
+	if len(to) == 0 {
+		return errors.New("email needs at least one recipient")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("from %q: %w", cfg.From, err)
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeoutSeconds * time.Second
+	}
+	address := net.JoinHostPort(cfg.Host, cfg.Port)
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	var conn net.Conn
+	if cfg.Port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{ServerName: cfg.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(cfg.Timeout))
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok && cfg.Port != "465" {
+		if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		// PlainAuth refuses to send the password over an unencrypted
+		// connection to anything but localhost.
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s: %w", recipient, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message(from.String(), to, subject, body, time.Now())); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message is the RFC 5322 message with CRLF line endings.
+func message(from string, to []string, subject, body string, date time.Time) []byte {
+	var text strings.Builder
+	text.WriteString("From: " + from + "\r\n")
+	text.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	text.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	text.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	text.WriteString("MIME-Version: 1.0\r\n")
+	text.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	text.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	text.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(text.String())
+}
+
+func getenv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
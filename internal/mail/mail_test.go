@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRelay accepts one message without TLS or auth and returns its
+// envelope commands and data.
+func fakeRelay(t *testing.T) (string, <-chan []string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	lines := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(text string) { conn.Write([]byte(text + "\r\n")) }
+		reply("220 relay ready")
+		var got []string
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				lines <- got
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			got = append(got, line)
+			switch {
+			case inData && line == ".":
+				inData = false
+				reply("250 queued")
+			case inData:
+			case strings.HasPrefix(line, "EHLO"):
+				reply("250 relay")
+			case line == "DATA":
+				inData = true
+				reply("354 go ahead")
+			case line == "QUIT":
+				reply("221 bye")
+				lines <- got
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return listener.Addr().String(), lines
+}
+
+func TestSendDeliversOneMessageToEveryRecipient(t *testing.T) {
+	address, lines := fakeRelay(t)
+	host, port, _ := net.SplitHostPort(address)
+	cfg := Config{Host: host, Port: port, From: "TradeGravity <bot@example.com>"}
+	if err := Send(cfg, []string{"a@example.com", "b@example.com"}, "Digest – 2 new periods", "line one\n.hidden\n"); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(<-lines, "\n")
+	for _, want := range []string{
+		"MAIL FROM:<bot@example.com>", "RCPT TO:<a@example.com>", "RCPT TO:<b@example.com>",
+		"From: \"TradeGravity\" <bot@example.com>", "To: a@example.com, b@example.com",
+		"Subject: =?utf-8?q?Digest_=E2=80=93_2_new_periods?=", "Content-Type: text/plain; charset=utf-8",
+		"line one\n..hidden\n.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("session missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestParseRecipientsAndConfig(t *testing.T) {
+	recipients, err := ParseRecipients("ops@example.com, Analyst <analyst@example.org>")
+	if err != nil || strings.Join(recipients, ",") != "ops@example.com,analyst@example.org" {
+		t.Fatalf("recipients = %v, %v", recipients, err)
+	}
+	if _, err := ParseRecipients("not an address"); err == nil {
+		t.Fatal("want an error for an invalid address")
+	}
+	t.Setenv("SMTP_HOST", "")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Fatal("want an error without SMTP_HOST")
+	}
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_USERNAME", "bot@example.com")
+	cfg, err := ConfigFromEnv()
+	if err != nil || cfg.Port != "587" || cfg.From != "bot@example.com" || cfg.Timeout != 30*time.Second {
+		t.Fatalf("cfg = %+v, %v", cfg, err)
+	}
+}
@@ -1,12 +1,23 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"tradegravity/internal/iso"
+)
 
 type Flow string
 
+// FlowTotal is trade turnover, exports plus imports, for providers that
+// report it as one figure. The publisher uses it when the two flows are not
+// both available for a period.
 const (
 	FlowExport Flow = "export"
 	FlowImport Flow = "import"
+	FlowTotal  Flow = "total"
 )
 
 type PeriodType string
@@ -17,12 +28,31 @@ const (
 	PeriodYear    PeriodType = "Y"
 )
 
+// Cents is an exact USD amount in hundredths of a dollar. Trade values are
+// stored as Cents so that provider multipliers and repeated conversions do
+// not leave floating-point residue in published figures.
+type Cents int64
+
+// CentsFromUSD rounds a dollar amount to the nearest cent, halves away from
+// zero.
+func CentsFromUSD(usd float64) Cents {
+	return Cents(math.Round(usd * 100))
+}
+
+// USD returns the amount in dollars. The result is the float64 nearest the
+// exact decimal, so it encodes with at most two decimal places.
+func (c Cents) USD() float64 {
+	return float64(c) / 100
+}
+
 type Reporter struct {
-	ISO3     string
-	NameEN   string
-	NameKO   string
-	Region   string
-	IsActive bool
+	ISO3        string
+	NameEN      string
+	NameKO      string
+	Region      string
+	Subregion   string
+	IncomeGroup string
+	IsActive    bool
 }
 
 type Observation struct {
@@ -38,6 +68,104 @@ type Observation struct {
 	ValueUSD        float64
 	IngestedAt      time.Time
 	SourceUpdatedAt time.Time
+	// Estimated marks a value that is not an official reported figure, and
+	// QualityNote says how it was derived.
+	Estimated   bool
+	QualityNote string
+	// DatasetID and Indicator name the upstream dataset and indicator or
+	// flow code that produced the value, such as "tradestats-trade" and
+	// "XPRT-TRD-VL" or "C/A/HS" and "X". SourceNote holds any other request
+	// filter or conversion applied to it.
+	DatasetID  string
+	Indicator  string
+	SourceNote string
+}
+
+// Quality notes for estimated observations.
+const (
+	// QualityMirror is a value taken from the partner's report of the
+	// opposite flow.
+	QualityMirror = "mirror"
+	// QualityInterpolated is a value filled in between reported periods.
+	QualityInterpolated = "interpolated"
+	// QualityProviderEstimate is a value the provider itself flags as an
+	// estimate.
+	QualityProviderEstimate = "provider_estimate"
+)
+
+// ObservationChange is an observation an upsert stored for the first time
+// or whose value it changed. PreviousUSD is the value it replaced, zero for
+// an insert.
+type ObservationChange struct {
+	Observation Observation
+	Revised     bool
+	PreviousUSD float64
+}
+
+// MaxObservationUSD bounds a single trade value. It is several times annual
+// world merchandise trade, so anything above it is a unit or parsing error.
+const MaxObservationUSD = 1e14
+
+// ObservationError lists every rule an observation breaks. Observation
+// identifies the row as provider, pair, flow, product, and period.
+type ObservationError struct {
+	Observation string
+	Violations  []string
+}
+
+func (e *ObservationError) Error() string {
+	return e.Observation + ": " + strings.Join(e.Violations, "; ")
+}
+
+// Validate checks an observation before it is stored: the reporter must be a
+// country and the partner a country, WLD, or a canonical special partner
+// code; the flow and period type must be known and the period written in the
+// canonical form of its type; and the value must be finite, non-negative, and
+// at most MaxObservationUSD. It returns an *ObservationError listing every
+// violation, or nil.
+func (o Observation) Validate() error {
+	var violations []string
+	if !iso.Valid(o.ReporterISO3) {
+		violations = append(violations, fmt.Sprintf("unknown reporter %q", o.ReporterISO3))
+	}
+	if partner, ok := LookupSpecialPartner(o.PartnerISO3); ok {
+		if partner.Code != o.PartnerISO3 {
+			violations = append(violations, fmt.Sprintf("partner %q is an alias of %s", o.PartnerISO3, partner.Code))
+		}
+	} else if !iso.Valid(o.PartnerISO3) {
+		violations = append(violations, fmt.Sprintf("unknown partner %q", o.PartnerISO3))
+	}
+	if o.ReporterISO3 != "" && o.ReporterISO3 == o.PartnerISO3 {
+		violations = append(violations, "reporter and partner are the same country")
+	}
+	switch o.Flow {
+	case FlowExport, FlowImport, FlowTotal:
+	default:
+		violations = append(violations, fmt.Sprintf("unknown flow %q", o.Flow))
+	}
+	switch o.PeriodType {
+	case PeriodYear, PeriodQuarter, PeriodMonth:
+		if index, ok := periodIndex(o.PeriodType, o.Period); !ok || formatPeriod(o.PeriodType, index) != o.Period {
+			violations = append(violations, fmt.Sprintf("period %q is not a canonical %s period", o.Period, o.PeriodType))
+		}
+	default:
+		violations = append(violations, fmt.Sprintf("unknown period type %q", o.PeriodType))
+	}
+	switch {
+	case math.IsNaN(o.ValueUSD) || math.IsInf(o.ValueUSD, 0):
+		violations = append(violations, fmt.Sprintf("value %v is not finite", o.ValueUSD))
+	case o.ValueUSD < 0:
+		violations = append(violations, fmt.Sprintf("value %.2f is negative", o.ValueUSD))
+	case o.ValueUSD > MaxObservationUSD:
+		violations = append(violations, fmt.Sprintf("value %.2f exceeds %.0f", o.ValueUSD, MaxObservationUSD))
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ObservationError{
+		Observation: fmt.Sprintf("%s %s %s %s %s %s", o.Provider, NewPair(o.ReporterISO3, o.PartnerISO3), o.Flow, o.ProductCode, o.PeriodType, o.Period),
+		Violations:  violations,
+	}
 }
 
 type TariffRateType string
@@ -87,6 +215,16 @@ type TariffObservation struct {
 	SourceUpdatedAt   time.Time
 }
 
+// FXRate is the average number of Currency units per US dollar over one
+// period, used to publish converted copies of USD trade values.
+type FXRate struct {
+	Currency   string
+	PeriodType PeriodType
+	Period     string
+	RatePerUSD float64
+	Source     string
+}
+
 // IngestRun records one collector invocation so published quality metadata can
 // distinguish complete, partial, and failed refreshes.
 type IngestRun struct {
@@ -103,4 +241,7 @@ type IngestRun struct {
 	SkippedCount  int
 	StoredCount   int
 	Errors        []string
+	// CodeVersion and CodeCommit identify the collector build that ran.
+	CodeVersion string
+	CodeCommit  string
 }
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"tradegravity/internal/iso"
+)
+
+// Pair is one bilateral relationship: trade that Reporter reports with
+// Partner. Codes are upper-case alpha-3 once the pair is built with NewPair,
+// and the partner may also be the WLD world aggregate.
+type Pair struct {
+	Reporter string
+	Partner  string
+}
+
+// NewPair returns the canonical pair for reporter and partner, trimming and
+// upper-casing both codes.
+func NewPair(reporter, partner string) Pair {
+	return Pair{
+		Reporter: strings.ToUpper(strings.TrimSpace(reporter)),
+		Partner:  strings.ToUpper(strings.TrimSpace(partner)),
+	}
+}
+
+// Validate rejects pairs whose reporter is not a country, whose partner is
+// neither a country nor WLD, or that pair a country with itself.
+func (p Pair) Validate() error {
+	if !iso.Valid(p.Reporter) {
+		return fmt.Errorf("pair %s: unknown reporter %q", p, p.Reporter)
+	}
+	if p.Partner != iso.World && !iso.Valid(p.Partner) {
+		return fmt.Errorf("pair %s: unknown partner %q", p, p.Partner)
+	}
+	if p.SameCountry() {
+		return fmt.Errorf("pair %s: reporter and partner are the same country", p)
+	}
+	return nil
+}
+
+// SameCountry reports whether the reporter is its own partner. Such pairs
+// are never requested.
+func (p Pair) SameCountry() bool {
+	return strings.EqualFold(strings.TrimSpace(p.Reporter), strings.TrimSpace(p.Partner))
+}
+
+// Mirror is the same relationship as the partner reports it.
+func (p Pair) Mirror() Pair {
+	return Pair{Reporter: p.Partner, Partner: p.Reporter}
+}
+
+// String formats the pair as REPORTER/PARTNER, the form used in run reports.
+func (p Pair) String() string {
+	return p.Reporter + "/" + p.Partner
+}
@@ -0,0 +1,169 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/iso"
+)
+
+// PartnerHandling says what happens to observations whose partner is not a
+// country.
+type PartnerHandling string
+
+const (
+	// PartnerPublish stores the observations and publishes the partner
+	// under its label wherever countries appear as partners.
+	PartnerPublish PartnerHandling = "publish"
+	// PartnerStore stores the observations but leaves the partner out of
+	// published partner lists.
+	PartnerStore PartnerHandling = "store"
+	// PartnerDrop discards the observations before they are stored.
+	PartnerDrop PartnerHandling = "drop"
+)
+
+// SpecialPartner is a partner code that providers report but that does not
+// name an ISO 3166-1 country, such as the world or the European Union.
+// Aliases are other spellings providers use for the same partner.
+type SpecialPartner struct {
+	Code     string
+	Name     string
+	Aliases  []string
+	Handling PartnerHandling
+}
+
+// specialPartners lists the non-country partners with their default
+// handling. WLD is the share-of-total denominator and is always stored but
+// never published as a partner.
+var specialPartners = []SpecialPartner{
+	{Code: iso.World, Name: "World", Aliases: []string{"W00"}, Handling: PartnerStore},
+	{Code: "EUU", Name: "European Union", Aliases: []string{"EUN"}, Handling: PartnerStore},
+	{Code: "OTH", Name: "Other areas, not elsewhere specified", Aliases: []string{"UNS"}, Handling: PartnerDrop},
+	{Code: "FRE", Name: "Free zones", Handling: PartnerDrop},
+	{Code: "SPE", Name: "Special categories", Handling: PartnerDrop},
+	{Code: "BUN", Name: "Bunkers", Handling: PartnerDrop},
+}
+
+// LookupSpecialPartner finds a special partner by code or alias, ignoring
+// case and surrounding space.
+func LookupSpecialPartner(code string) (SpecialPartner, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, partner := range specialPartners {
+		if partner.Code == code {
+			return partner, true
+		}
+		for _, alias := range partner.Aliases {
+			if alias == code {
+				return partner, true
+			}
+		}
+	}
+	return SpecialPartner{}, false
+}
+
+// PartnerLabel names a partner: the English country name, the special
+// partner's name, or the code itself when it is neither.
+func PartnerLabel(code string) string {
+	if country, ok := iso.Lookup(code); ok {
+		return country.NameEN
+	}
+	if partner, ok := LookupSpecialPartner(code); ok {
+		return partner.Name
+	}
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// PartnerPolicy maps special partner codes to their handling. Country
+// partners are always published and unknown codes are always dropped, so
+// they never pass through as if they were ISO3 codes.
+type PartnerPolicy map[string]PartnerHandling
+
+// DefaultPartnerPolicy returns the default handling of every special
+// partner.
+func DefaultPartnerPolicy() PartnerPolicy {
+	policy := make(PartnerPolicy, len(specialPartners))
+	for _, partner := range specialPartners {
+		policy[partner.Code] = partner.Handling
+	}
+	return policy
+}
+
+// ParsePartnerPolicy overrides the defaults with comma-separated CODE=handling
+// entries such as "EUU=publish,OTH=store". WLD cannot be overridden.
+func ParsePartnerPolicy(value string) (PartnerPolicy, error) {
+	policy := DefaultPartnerPolicy()
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		code, handling, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid special partner entry %q (expected CODE=publish, store, or drop)", item)
+		}
+		partner, ok := LookupSpecialPartner(code)
+		if !ok {
+			return nil, fmt.Errorf("unknown special partner %q (expected one of %s)", strings.TrimSpace(code), strings.Join(SpecialPartnerCodes(), ", "))
+		}
+		if partner.Code == iso.World {
+			return nil, fmt.Errorf("%s is always stored as the world denominator", iso.World)
+		}
+		switch parsed := PartnerHandling(strings.ToLower(strings.TrimSpace(handling))); parsed {
+		case PartnerPublish, PartnerStore, PartnerDrop:
+			policy[partner.Code] = parsed
+		default:
+			return nil, fmt.Errorf("unsupported handling %q for %s (expected publish, store, or drop)", strings.TrimSpace(handling), partner.Code)
+		}
+	}
+	return policy, nil
+}
+
+// SpecialPartnerCodes lists the canonical special partner codes in order.
+func SpecialPartnerCodes() []string {
+	codes := make([]string, 0, len(specialPartners))
+	for _, partner := range specialPartners {
+		codes = append(codes, partner.Code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Handling returns how code is treated. It also returns the canonical code,
+// which differs from code for aliases such as EUN.
+func (p PartnerPolicy) Handling(code string) (string, PartnerHandling) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if iso.Valid(code) {
+		return code, PartnerPublish
+	}
+	partner, ok := LookupSpecialPartner(code)
+	if !ok {
+		return code, PartnerDrop
+	}
+	if handling, ok := p[partner.Code]; ok {
+		return partner.Code, handling
+	}
+	return partner.Code, partner.Handling
+}
+
+// Published reports whether code appears as a partner in published outputs.
+func (p PartnerPolicy) Published(code string) bool {
+	_, handling := p.Handling(code)
+	return handling == PartnerPublish
+}
+
+// Filter drops observations whose partner handling is drop and rewrites
+// alias partner codes to the canonical code. It returns the kept
+// observations and the number dropped.
+func (p PartnerPolicy) Filter(observations []Observation) ([]Observation, int) {
+	kept := make([]Observation, 0, len(observations))
+	for _, observation := range observations {
+		code, handling := p.Handling(observation.PartnerISO3)
+		if handling == PartnerDrop {
+			continue
+		}
+		observation.PartnerISO3 = code
+		kept = append(kept, observation)
+	}
+	return kept, len(observations) - len(kept)
+}
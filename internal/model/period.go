@@ -0,0 +1,244 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseYear parses a four-digit annual period.
+func ParseYear(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) != 4 || !isDigits(value) {
+		return 0, false
+	}
+	year, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return year, true
+}
+
+// ParseYearMonth accepts YYYYMM and YYYY-MM monthly periods.
+func ParseYearMonth(value string) (int, int, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) == 6 && isDigits(value) {
+		year, _ := strconv.Atoi(value[:4])
+		month, _ := strconv.Atoi(value[4:])
+		if month >= 1 && month <= 12 {
+			return year, month, true
+		}
+	}
+
+	parts := strings.Split(value, "-")
+	if len(parts) == 2 && len(parts[0]) == 4 {
+		year, errYear := strconv.Atoi(parts[0])
+		month, errMonth := strconv.Atoi(parts[1])
+		if errYear == nil && errMonth == nil && month >= 1 && month <= 12 {
+			return year, month, true
+		}
+	}
+	return 0, 0, false
+}
+
+// ParseYearQuarter accepts YYYY-Qn and YYYYQn quarterly periods.
+func ParseYearQuarter(value string) (int, int, bool) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if strings.Contains(value, "-Q") {
+		parts := strings.Split(value, "-Q")
+		if len(parts) == 2 {
+			year, errYear := strconv.Atoi(parts[0])
+			quarter, errQuarter := strconv.Atoi(parts[1])
+			if errYear == nil && errQuarter == nil && quarter >= 1 && quarter <= 4 {
+				return year, quarter, true
+			}
+		}
+	}
+	if strings.Contains(value, "Q") {
+		parts := strings.Split(value, "Q")
+		if len(parts) == 2 {
+			year, errYear := strconv.Atoi(parts[0])
+			quarter, errQuarter := strconv.Atoi(parts[1])
+			if errYear == nil && errQuarter == nil && quarter >= 1 && quarter <= 4 {
+				return year, quarter, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// PeriodRange is an inclusive run of consecutive periods of one type, such as
+// 2019 through 2023 or 2023-11 through 2024-02. From and To are kept in the
+// canonical forms YYYY, YYYY-Qn, and YYYY-MM. The zero value is empty.
+type PeriodRange struct {
+	Type PeriodType
+	From string
+	To   string
+}
+
+// NewPeriodRange returns the range from through to. Both ends must parse as
+// periodType and to must not come before from.
+func NewPeriodRange(periodType PeriodType, from, to string) (PeriodRange, error) {
+	first, ok := periodIndex(periodType, from)
+	if !ok {
+		return PeriodRange{}, fmt.Errorf("invalid %s period %q", periodType, from)
+	}
+	last, ok := periodIndex(periodType, to)
+	if !ok {
+		return PeriodRange{}, fmt.Errorf("invalid %s period %q", periodType, to)
+	}
+	if last < first {
+		return PeriodRange{}, fmt.Errorf("period range %s-%s ends before it starts", from, to)
+	}
+	return PeriodRange{Type: periodType, From: formatPeriod(periodType, first), To: formatPeriod(periodType, last)}, nil
+}
+
+// PeriodRangeEnding returns the count periods ending at through, so twelve
+// months ending at 2024-03 start at 2023-04.
+func PeriodRangeEnding(periodType PeriodType, through string, count int) (PeriodRange, error) {
+	if count < 1 {
+		return PeriodRange{}, fmt.Errorf("period count must be positive, got %d", count)
+	}
+	last, ok := periodIndex(periodType, through)
+	if !ok {
+		return PeriodRange{}, fmt.Errorf("invalid %s period %q", periodType, through)
+	}
+	return PeriodRange{Type: periodType, From: formatPeriod(periodType, last-count+1), To: formatPeriod(periodType, last)}, nil
+}
+
+// Len is the number of periods in the range.
+func (r PeriodRange) Len() int {
+	first, last, ok := r.bounds()
+	if !ok {
+		return 0
+	}
+	return last - first + 1
+}
+
+// Periods lists every period in the range, oldest first.
+func (r PeriodRange) Periods() []string {
+	first, last, ok := r.bounds()
+	if !ok {
+		return nil
+	}
+	periods := make([]string, 0, last-first+1)
+	for index := first; index <= last; index++ {
+		periods = append(periods, formatPeriod(r.Type, index))
+	}
+	return periods
+}
+
+// Contains reports whether period falls inside the range. Either monthly
+// spelling, YYYYMM or YYYY-MM, is accepted.
+func (r PeriodRange) Contains(period string) bool {
+	first, last, ok := r.bounds()
+	if !ok {
+		return false
+	}
+	index, ok := periodIndex(r.Type, period)
+	return ok && index >= first && index <= last
+}
+
+// Intersect returns the periods both ranges cover. It reports false when the
+// ranges differ in type or do not overlap.
+func (r PeriodRange) Intersect(other PeriodRange) (PeriodRange, bool) {
+	if r.Type != other.Type {
+		return PeriodRange{}, false
+	}
+	first, last, ok := r.bounds()
+	otherFirst, otherLast, otherOK := other.bounds()
+	if !ok || !otherOK {
+		return PeriodRange{}, false
+	}
+	first, last = max(first, otherFirst), min(last, otherLast)
+	if last < first {
+		return PeriodRange{}, false
+	}
+	return PeriodRange{Type: r.Type, From: formatPeriod(r.Type, first), To: formatPeriod(r.Type, last)}, true
+}
+
+// Gaps returns the runs of periods in the range for which present reports
+// false, oldest first. A range with nothing missing has no gaps.
+func (r PeriodRange) Gaps(present func(period string) bool) []PeriodRange {
+	var gaps []PeriodRange
+	for _, period := range r.Periods() {
+		if present(period) {
+			continue
+		}
+		if last := len(gaps) - 1; last >= 0 && NextPeriod(r.Type, gaps[last].To) == period {
+			gaps[last].To = period
+			continue
+		}
+		gaps = append(gaps, PeriodRange{Type: r.Type, From: period, To: period})
+	}
+	return gaps
+}
+
+func (r PeriodRange) bounds() (int, int, bool) {
+	first, ok := periodIndex(r.Type, r.From)
+	if !ok {
+		return 0, 0, false
+	}
+	last, ok := periodIndex(r.Type, r.To)
+	if !ok || last < first {
+		return 0, 0, false
+	}
+	return first, last, true
+}
+
+// NextPeriod is the period immediately after period: the next month, quarter,
+// or year. It is empty when period does not parse as periodType.
+func NextPeriod(periodType PeriodType, period string) string {
+	index, ok := periodIndex(periodType, period)
+	if !ok {
+		return ""
+	}
+	return formatPeriod(periodType, index+1)
+}
+
+// PreviousPeriod is the period immediately before period: the previous month,
+// quarter, or year. It is empty when period does not parse as periodType.
+func PreviousPeriod(periodType PeriodType, period string) string {
+	index, ok := periodIndex(periodType, period)
+	if !ok {
+		return ""
+	}
+	return formatPeriod(periodType, index-1)
+}
+
+// periodIndex counts periods from year zero so consecutive periods differ by
+// one.
+func periodIndex(periodType PeriodType, period string) (int, bool) {
+	switch periodType {
+	case PeriodMonth:
+		year, month, ok := ParseYearMonth(period)
+		return year*12 + month - 1, ok
+	case PeriodQuarter:
+		year, quarter, ok := ParseYearQuarter(period)
+		return year*4 + quarter - 1, ok
+	case PeriodYear:
+		return ParseYear(period)
+	default:
+		return 0, false
+	}
+}
+
+func formatPeriod(periodType PeriodType, index int) string {
+	switch periodType {
+	case PeriodMonth:
+		return fmt.Sprintf("%04d-%02d", index/12, index%12+1)
+	case PeriodQuarter:
+		return fmt.Sprintf("%04d-Q%d", index/4, index%4+1)
+	default:
+		return fmt.Sprintf("%04d", index)
+	}
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
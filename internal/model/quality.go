@@ -0,0 +1,63 @@
+package model
+
+// Series quality grades, best first.
+const (
+	GradeA = "A"
+	GradeB = "B"
+	GradeC = "C"
+	GradeD = "D"
+)
+
+// SeriesQuality summarizes how far one reporter/partner/flow series of one
+// period type can be trusted. Periods counts the stored periods and Span the
+// periods from the first of them to the last, so a gap lowers completeness.
+// RevisedPeriods counts periods whose value changed after it was first
+// stored. DiscrepancyPct is the median absolute WITS/Comtrade discrepancy
+// over the periods both providers report, nil when they never overlap.
+type SeriesQuality struct {
+	Pair           Pair
+	Flow           Flow
+	PeriodType     PeriodType
+	Periods        int
+	Span           int
+	RevisedPeriods int
+	DiscrepancyPct *float64
+}
+
+// Completeness is the share of the span's periods that are stored.
+func (q SeriesQuality) Completeness() float64 {
+	if q.Span <= 0 {
+		return 0
+	}
+	return float64(q.Periods) / float64(q.Span)
+}
+
+// RevisionRate is the share of stored periods that were revised.
+func (q SeriesQuality) RevisionRate() float64 {
+	if q.Periods <= 0 {
+		return 0
+	}
+	return float64(q.RevisedPeriods) / float64(q.Periods)
+}
+
+// Grade rates the series from A to D. A series is graded by its weakest
+// measure: A needs 95% completeness, at most 10% of periods revised, and a
+// discrepancy of at most 2%; B needs 80%, 25%, and 10%; C needs 50%
+// completeness and at most half its periods revised. An unknown discrepancy
+// does not lower the grade.
+func (q SeriesQuality) Grade() string {
+	completeness, revisions := q.Completeness(), q.RevisionRate()
+	discrepancyWithin := func(limit float64) bool {
+		return q.DiscrepancyPct == nil || *q.DiscrepancyPct <= limit
+	}
+	switch {
+	case completeness >= 0.95 && revisions <= 0.10 && discrepancyWithin(2):
+		return GradeA
+	case completeness >= 0.80 && revisions <= 0.25 && discrepancyWithin(10):
+		return GradeB
+	case completeness >= 0.50 && revisions <= 0.50:
+		return GradeC
+	default:
+		return GradeD
+	}
+}
@@ -0,0 +1,97 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAzurePutSignsWithSharedKey(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("account-key"))
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client, err := NewAzure(Config{Endpoint: srv.URL + "/", AzureAccount: "tradeacct", AzureKey: key}, "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.now = func() time.Time { return time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC) }
+	if err := client.Put(context.Background(), "data/meta.json", []byte("{}"), "application/json", "no-cache"); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.EscapedPath() != "/site/data/meta.json" || got.Header.Get("x-ms-blob-type") != "BlockBlob" {
+		t.Fatalf("request = %s %v", got.URL, got.Header)
+	}
+	if got.Header.Get("x-ms-blob-content-type") != "application/json" || got.Header.Get("x-ms-blob-cache-control") != "no-cache" {
+		t.Fatalf("blob headers = %v", got.Header)
+	}
+	stringToSign := "PUT\n\n\n2\n\n\n\n\n\n\n\n\n" +
+		"x-ms-blob-cache-control:no-cache\n" +
+		"x-ms-blob-content-type:application/json\n" +
+		"x-ms-blob-type:BlockBlob\n" +
+		"x-ms-date:Wed, 15 Jul 2026 12:00:00 GMT\n" +
+		"x-ms-version:" + azureVersion + "\n" +
+		"/tradeacct/site/data/meta.json"
+	mac := hmac.New(sha256.New, []byte("account-key"))
+	mac.Write([]byte(stringToSign))
+	want := "SharedKey tradeacct:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if auth := got.Header.Get("Authorization"); auth != want {
+		t.Fatalf("Authorization = %q\nwant %q", auth, want)
+	}
+}
+
+func TestAzurePutAppendsSASToken(t *testing.T) {
+	var query, auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, auth = r.URL.RawQuery, r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client, err := NewAzure(Config{Endpoint: srv.URL, AzureAccount: "tradeacct", AzureSAS: "?sv=2021-12-02&sig=abc"}, "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Put(context.Background(), "meta.json", []byte("{}"), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if query != "sv=2021-12-02&sig=abc" || auth != "" {
+		t.Fatalf("query = %q, Authorization = %q", query, auth)
+	}
+	if _, err := NewAzure(Config{Endpoint: srv.URL, AzureAccount: "tradeacct"}, "site"); err == nil {
+		t.Fatal("want an error without a key or SAS token")
+	}
+}
+
+func TestConfigFromEnvReadsAzureCredentials(t *testing.T) {
+	t.Setenv("AZURE_STORAGE_ACCOUNT", "tradeacct")
+	t.Setenv("AZURE_STORAGE_KEY", "")
+	t.Setenv("AZURE_STORAGE_SAS_TOKEN", "sv=2021-12-02&sig=abc")
+	t.Setenv("AZURE_STORAGE_ENDPOINT", "")
+	cfg, err := ConfigFromEnv(Target{Scheme: "az", Bucket: "site"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AzureAccount != "tradeacct" || cfg.AzureSAS != "sv=2021-12-02&sig=abc" || cfg.AzureKey != "" {
+		t.Fatalf("azure config = %+v", cfg)
+	}
+	if cfg.AccessKey != "" || cfg.SecretKey != "" || cfg.SessionToken != "" {
+		t.Fatalf("azure credentials leaked into the S3 fields: %+v", cfg)
+	}
+	if cfg.Endpoint != "https://tradeacct.blob.core.windows.net" {
+		t.Fatalf("endpoint = %q", cfg.Endpoint)
+	}
+	t.Setenv("AZURE_STORAGE_SAS_TOKEN", "")
+	if _, err := ConfigFromEnv(Target{Scheme: "az", Bucket: "site"}); err == nil {
+		t.Fatal("want an error without a key or SAS token")
+	}
+}
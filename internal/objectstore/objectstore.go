@@ -0,0 +1,268 @@
+// Package objectstore uploads objects to S3-compatible storage with AWS
+// Signature Version 4. Google Cloud Storage is reached through its XML API
+// with HMAC interoperability keys, which accepts the same signing. Azure Blob
+// Storage and SFTP servers are the other upload targets, behind the same
+// Uploader interface.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"tradegravity/internal/awssig"
+	"tradegravity/internal/secrets"
+	"tradegravity/internal/sftp"
+)
+
+const (
+	defaultRegion         = "us-east-1"
+	defaultTimeoutSeconds = 60
+	gcsEndpoint           = "https://storage.googleapis.com"
+)
+
+// Target is a parsed s3://bucket/prefix, gs://bucket/prefix,
+// az://container/prefix, or sftp://user@host:port/path URL. For SFTP, Bucket
+// is the user@host:port to log in to and Prefix the directory, where a
+// leading ~/ is relative to the login directory. Prefix has no leading or
+// trailing slash.
+type Target struct {
+	Scheme string
+	Bucket string
+	Prefix string
+}
+
+// ParseTarget accepts s3://, gs://, and az:// URLs with a bucket or
+// container and optional prefix, and sftp:// URLs with a host and path.
+func ParseTarget(value string) (Target, error) {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return Target{}, err
+	}
+	switch parsed.Scheme {
+	case "s3", "gs", "az":
+		if parsed.Host == "" {
+			return Target{}, fmt.Errorf("upload target %q has no bucket", value)
+		}
+		return Target{Scheme: parsed.Scheme, Bucket: parsed.Host, Prefix: strings.Trim(parsed.Path, "/")}, nil
+	case "sftp":
+		if parsed.Host == "" {
+			return Target{}, fmt.Errorf("upload target %q has no host", value)
+		}
+		authority := parsed.Host
+		if parsed.User != nil {
+			if _, hasPassword := parsed.User.Password(); hasPassword {
+				return Target{}, fmt.Errorf("upload target %q has a password; use a key instead", value)
+			}
+			authority = parsed.User.Username() + "@" + authority
+		}
+		return Target{Scheme: parsed.Scheme, Bucket: authority, Prefix: strings.Trim(parsed.Path, "/")}, nil
+	}
+	return Target{}, fmt.Errorf("unsupported upload target %q (expected s3://, gs://, az://, or sftp://)", value)
+}
+
+// Key joins the target prefix and a slash-separated relative path.
+func (t Target) Key(relative string) string {
+	if t.Prefix == "" {
+		return relative
+	}
+	return t.Prefix + "/" + relative
+}
+
+func (t Target) String() string {
+	return t.Scheme + "://" + t.Bucket + "/" + t.Prefix
+}
+
+// Uploader stores objects under keys. Put is safe for concurrent use. An
+// uploader may queue a Put and send it later, in order, stopping at the
+// first failure, so uploads are only known to have succeeded once Close
+// returns nil.
+type Uploader interface {
+	Put(ctx context.Context, key string, body []byte, contentType, cacheControl string) error
+	Close() error
+}
+
+// Open returns the uploader for target, with credentials from the
+// environment as ConfigFromEnv reads them. SFTP is configured by
+// sftp.ConfigFromEnv.
+func Open(target Target) (Uploader, error) {
+	if target.Scheme == "sftp" {
+		return sftp.New(sftp.ConfigFromEnv(), target.Bucket)
+	}
+	cfg, err := ConfigFromEnv(target)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme == "az" {
+		return NewAzure(cfg, target.Bucket)
+	}
+	return New(cfg, target.Bucket)
+}
+
+// Config holds the endpoint and credentials for one target.
+type Config struct {
+	// Endpoint is the service base URL. Objects are addressed path-style as
+	// Endpoint/bucket/key, which S3, GCS, R2, and MinIO all accept.
+	Endpoint     string
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// AzureAccount names the Azure storage account, which signs with
+	// AzureKey, the base64 account key, or else appends AzureSAS, a shared
+	// access signature, to each request.
+	AzureAccount string
+	AzureKey     string
+	AzureSAS     string
+	Timeout      time.Duration
+}
+
+// ConfigFromEnv reads credentials for target. s3:// uses AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION, and an optional
+// S3_ENDPOINT for S3-compatible services. gs:// uses GCS_HMAC_ACCESS_ID and
+// GCS_HMAC_SECRET. az:// uses AZURE_STORAGE_ACCOUNT with AZURE_STORAGE_KEY or
+// AZURE_STORAGE_SAS_TOKEN, and an optional AZURE_STORAGE_ENDPOINT, such as
+// Azurite's. Credentials are read with secrets.Lookup, so each may also come
+// from a _FILE.
+func ConfigFromEnv(target Target) (Config, error) {
+	cfg := Config{Timeout: defaultTimeoutSeconds * time.Second}
+	keys := []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"}
+	switch target.Scheme {
+	case "gs":
+		cfg.Endpoint = gcsEndpoint
+		cfg.Region = "auto"
+		keys = []string{"GCS_HMAC_ACCESS_ID", "GCS_HMAC_SECRET", ""}
+	case "az":
+		return azureConfigFromEnv(cfg)
+	default:
+		cfg.Region = getenv("AWS_REGION", defaultRegion)
+		cfg.Endpoint = getenv("S3_ENDPOINT", "https://s3."+cfg.Region+".amazonaws.com")
+	}
+	for index, field := range []*string{&cfg.AccessKey, &cfg.SecretKey, &cfg.SessionToken} {
+		if keys[index] == "" {
+			continue
+		}
+		value, err := secrets.Lookup(keys[index])
+		if err != nil {
+			return Config{}, err
+		}
+		*field = value
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return Config{}, fmt.Errorf("missing credentials for %s upload", target.Scheme)
+	}
+	return cfg, nil
+}
+
+// azureConfigFromEnv fills the Azure fields of cfg. The account name is not
+// a secret; the key or the SAS token is.
+func azureConfigFromEnv(cfg Config) (Config, error) {
+	cfg.AzureAccount = strings.TrimSpace(os.Getenv("AZURE_STORAGE_ACCOUNT"))
+	if cfg.AzureAccount == "" {
+		return Config{}, errors.New("missing AZURE_STORAGE_ACCOUNT for az upload")
+	}
+	cfg.Endpoint = getenv("AZURE_STORAGE_ENDPOINT", "https://"+cfg.AzureAccount+".blob.core.windows.net")
+	var err error
+	if cfg.AzureKey, err = secrets.Lookup("AZURE_STORAGE_KEY"); err != nil {
+		return Config{}, err
+	}
+	if cfg.AzureSAS, err = secrets.Lookup("AZURE_STORAGE_SAS_TOKEN"); err != nil {
+		return Config{}, err
+	}
+	if cfg.AzureKey == "" && cfg.AzureSAS == "" {
+		return Config{}, errors.New("missing credentials for az upload (set AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN)")
+	}
+	return cfg, nil
+}
+
+// Client writes objects into one bucket.
+type Client struct {
+	config Config
+	bucket string
+	client *http.Client
+	now    func() time.Time
+}
+
+func New(cfg Config, bucket string) (*Client, error) {
+	if strings.TrimSpace(cfg.Endpoint) == "" {
+		return nil, errors.New("object store endpoint is required")
+	}
+	if strings.TrimSpace(bucket) == "" {
+		return nil, errors.New("object store bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeoutSeconds * time.Second
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &Client{
+		config: cfg,
+		bucket: bucket,
+		client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+	}, nil
+}
+
+// Put uploads body to key with the given content type and cache control.
+func (c *Client) Put(ctx context.Context, key string, body []byte, contentType, cacheControl string) error {
+	uri := c.config.Endpoint + "/" + c.bucket + "/" + escapeKey(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uri, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if cacheControl != "" {
+		req.Header.Set("Cache-Control", cacheControl)
+	}
+	c.sign(req, awssig.PayloadHash(body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("put %s: status %d: %s", key, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// Close has nothing to flush; every Put is sent when it is called.
+func (c *Client) Close() error {
+	return nil
+}
+
+// sign adds a Signature Version 4 Authorization header covering host and
+// every header already set on req.
+func (c *Client) sign(req *http.Request, payloadHash string) {
+	creds := awssig.Credentials{AccessKey: c.config.AccessKey, SecretKey: c.config.SecretKey, SessionToken: c.config.SessionToken}
+	awssig.Sign(req, "s3", c.config.Region, payloadHash, creds, c.now())
+}
+
+// escapeKey percent-encodes each key segment, keeping the slashes.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for index, segment := range segments {
+		segments[index] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func getenv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
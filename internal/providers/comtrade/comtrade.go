@@ -14,8 +14,11 @@ import (
 	"sync"
 	"time"
 
+	"tradegravity/internal/iso"
 	"tradegravity/internal/model"
 	"tradegravity/internal/providers"
+	"tradegravity/internal/providers/flight"
+	"tradegravity/internal/secrets"
 )
 
 const (
@@ -79,6 +82,7 @@ type Provider struct {
 	config       Config
 	client       *http.Client
 	limiter      *rateLimiter
+	loads        flight.Group
 	mu           sync.Mutex
 	refsLoaded   bool
 	reporters    []model.Reporter
@@ -188,8 +192,6 @@ func ConfigFromEnv() (Config, error) {
 		Dataset:           strings.TrimSpace(os.Getenv("COMTRADE_DATASET")),
 		ReportersURL:      getenv("COMTRADE_REPORTERS_URL", defaultReportersURL),
 		PartnersURL:       getenv("COMTRADE_PARTNERS_URL", defaultPartnersURL),
-		APIKeyPrimary:     strings.TrimSpace(os.Getenv("COMTRADE_PRIMARY_KEY")),
-		APIKeySecondary:   strings.TrimSpace(os.Getenv("COMTRADE_SECONDARY_KEY")),
 		APIKeyParam:       getenv("COMTRADE_API_KEY_PARAM", defaultAPIKeyParam),
 		Type:              getenv("COMTRADE_TYPE", defaultType),
 		Frequency:         getenv("COMTRADE_FREQUENCY", defaultFrequency),
@@ -202,6 +204,14 @@ func ConfigFromEnv() (Config, error) {
 		AllowISO3Fallback: getenvBool("COMTRADE_ALLOW_ISO3_FALLBACK", defaultAllowISO3Fallback),
 	}
 
+	var err error
+	if cfg.APIKeyPrimary, err = secrets.Lookup("COMTRADE_PRIMARY_KEY"); err != nil {
+		return Config{}, err
+	}
+	if cfg.APIKeySecondary, err = secrets.Lookup("COMTRADE_SECONDARY_KEY"); err != nil {
+		return Config{}, err
+	}
+
 	cfg.MaxRecords = getenvInt("COMTRADE_MAX_RECORDS", defaultMaxRecords)
 	cfg.LookbackYears = getenvInt("COMTRADE_LOOKBACK_YEARS", defaultLookbackYears)
 	cfg.Timeout = time.Duration(getenvInt("COMTRADE_TIMEOUT_SECONDS", defaultTimeoutSeconds)) * time.Second
@@ -524,8 +534,7 @@ func (p *Provider) FetchProductPeriodBatch(ctx context.Context, reporterISO3s, p
 		if _, ok := wantedCodes[observation.ProductCode]; !ok {
 			continue
 		}
-		observation.Provider = p.Name()
-		filtered = append(filtered, observation)
+		filtered = append(filtered, p.annotate(observation, flow))
 	}
 	if len(filtered) == 0 {
 		return nil, ErrNoRecords
@@ -646,9 +655,11 @@ func (p *Provider) FetchPartnerMatrix(ctx context.Context, reporterISO3 string,
 			continue
 		}
 		if _, ok := knownPartners[partner]; !ok {
-			continue
+			if _, special := model.LookupSpecialPartner(partner); !special {
+				continue
+			}
 		}
-		observation.Provider = p.Name()
+		observation = p.annotate(observation, flow)
 		observation.ProductCode = "TOTAL"
 		observation.ProductLevel = 0
 		filtered = append(filtered, observation)
@@ -679,14 +690,28 @@ func normalizeProductCodes(codes []string, level int) ([]string, error) {
 	return normalized, nil
 }
 
+// ensureReferences downloads the reporter and partner references once.
+// Concurrent callers share a download in flight rather than starting their
+// own, and a failed download is retried by the next caller.
 func (p *Provider) ensureReferences(ctx context.Context) error {
-	p.mu.Lock()
-	if p.refsLoaded {
-		p.mu.Unlock()
+	if p.referencesLoaded() {
 		return nil
 	}
-	p.mu.Unlock()
+	return p.loads.Do(ctx, "references", func(ctx context.Context) error {
+		if p.referencesLoaded() {
+			return nil
+		}
+		return p.loadReferences(ctx)
+	})
+}
+
+func (p *Provider) referencesLoaded() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refsLoaded
+}
 
+func (p *Provider) loadReferences(ctx context.Context) error {
 	reporters, reporterCodes, err := p.fetchReferences(ctx, p.config.ReportersURL, true)
 	if err != nil {
 		return err
@@ -745,11 +770,11 @@ func (p *Provider) fetchReferences(ctx context.Context, endpoint string, filterR
 			codes[iso3] = code
 		}
 
-		if filterReporter {
+		if country, ok := iso.Lookup(iso3); filterReporter && ok {
 			reporters = append(reporters, model.Reporter{
 				ISO3:     iso3,
 				NameEN:   strings.TrimSpace(entry.Name),
-				NameKO:   "",
+				NameKO:   country.NameKO,
 				Region:   "",
 				IsActive: true,
 			})
@@ -766,7 +791,12 @@ func (p *Provider) resolveReporterCode(iso3 string) (string, error) {
 	return p.resolveCode("reporter", iso3, p.reporterCode)
 }
 
+// resolvePartnerCode maps WLD to partner code 0, which the reference list
+// skips as a group but the API uses for trade with the world.
 func (p *Provider) resolvePartnerCode(iso3 string) (string, error) {
+	if strings.EqualFold(strings.TrimSpace(iso3), "WLD") {
+		return "0", nil
+	}
 	return p.resolveCode("partner", iso3, p.partnerCode)
 }
 
@@ -816,11 +846,25 @@ func (p *Provider) fetchPeriods(ctx context.Context, reporterISO3, partnerISO3,
 		return nil, ErrNoRecords
 	}
 	for i := range observations {
-		observations[i].Provider = p.Name()
+		observations[i] = p.annotate(observations[i], flow)
 	}
 	return observations, nil
 }
 
+// annotate sets the provider and fills the dataset and flow code a row did
+// not report from the request: the {type}/{freq}/{cl} path segments and the
+// configured flow code.
+func (p *Provider) annotate(observation model.Observation, flow model.Flow) model.Observation {
+	observation.Provider = p.Name()
+	if observation.DatasetID == "" {
+		observation.DatasetID = strings.Join([]string{p.config.Type, p.config.Frequency, p.config.Classification}, "/")
+	}
+	if observation.Indicator == "" {
+		observation.Indicator = p.flowCode(flow)
+	}
+	return observation
+}
+
 func (p *Provider) dataURL() string {
 	return p.dataURLForPath(p.config.DataPath)
 }
@@ -1133,9 +1177,21 @@ func parseAreaCodeObservations(body []byte, fallbackFlow model.Flow, reporterISO
 	return observations, nil
 }
 
+// specialPartnerCodes maps the Comtrade numeric codes of non-country
+// partners to the codes model.LookupSpecialPartner knows. The collector's
+// partner policy decides whether they are stored.
+var specialPartnerCodes = map[string]string{
+	"97":  "EUU",
+	"837": "BUN",
+	"838": "FRE",
+	"839": "SPE",
+	"899": "OTH",
+}
+
 // parseMatrixObservations resolves numeric partner codes because the public
 // preview endpoint may omit partner ISO fields even though it returns the full
-// country breakdown. World and aggregate group codes are intentionally ignored.
+// country breakdown. The world code is ignored, non-country partners are kept
+// under their special partner code, and other group codes are ignored.
 func parseMatrixObservations(body []byte, fallbackFlow model.Flow, reporterISO3 string, partnerISOByCode map[string]string, multiplier float64) ([]model.Observation, error) {
 	var payload any
 	if err := json.Unmarshal(body, &payload); err != nil {
@@ -1150,14 +1206,23 @@ func parseMatrixObservations(body []byte, fallbackFlow model.Flow, reporterISO3
 	for _, row := range rows {
 		partnerISO, _ := getString(row, "pt3ISO", "PartnerISO3", "partnerISO3", "partnerISO")
 		partnerISO = strings.ToUpper(strings.TrimSpace(partnerISO))
+		partnerCode, _ := getString(row, "partnerCode", "PartnerCode", "ptCode")
+		partnerCode = strings.TrimSpace(partnerCode)
+		if special, ok := specialPartnerCodes[partnerCode]; ok {
+			partnerISO = special
+		}
 		if partnerISO == "" {
-			partnerCode, ok := getString(row, "partnerCode", "PartnerCode", "ptCode")
-			if !ok || strings.TrimSpace(partnerCode) == "0" {
+			if partnerCode == "" || partnerCode == "0" {
 				continue
 			}
-			partnerISO = partnerISOByCode[strings.TrimSpace(partnerCode)]
+			partnerISO = partnerISOByCode[partnerCode]
 		}
-		if !isAlphabeticISO3(partnerISO) || partnerISO == "WLD" {
+		if special, ok := model.LookupSpecialPartner(partnerISO); ok {
+			if special.Code == iso.World {
+				continue
+			}
+			partnerISO = special.Code
+		} else if !iso.Valid(partnerISO) {
 			continue
 		}
 		observation, err := rowToObservation(row, reporterISO3, partnerISO, fallbackFlow, multiplier)
@@ -1169,19 +1234,6 @@ func parseMatrixObservations(body []byte, fallbackFlow model.Flow, reporterISO3
 	return observations, nil
 }
 
-func isAlphabeticISO3(value string) bool {
-	value = strings.ToUpper(strings.TrimSpace(value))
-	if len(value) != 3 {
-		return false
-	}
-	for _, character := range value {
-		if character < 'A' || character > 'Z' {
-			return false
-		}
-	}
-	return true
-}
-
 func rowToObservation(row map[string]any, reporterISO3, partnerISO3 string, flow model.Flow, multiplier float64) (model.Observation, error) {
 	value, ok := getFloat(row, "TradeValue", "tradeValue", "TradeValueUSD", "TradeValueUS$", "Value", "value", "primaryValue")
 	if !ok {
@@ -1202,6 +1254,10 @@ func rowToObservation(row map[string]any, reporterISO3, partnerISO3 string, flow
 	if value, ok := getString(row, "pt3ISO", "PartnerISO3", "partnerISO3", "partnerISO", "Partner", "partner"); ok {
 		partner = value
 	}
+	// Rows name the world W00; store it as WLD, the code requested.
+	if special, ok := model.LookupSpecialPartner(partner); ok {
+		partner = special.Code
+	}
 	classification, _ := getString(row, "classificationSearchCode", "classificationCode", "clCode")
 	productCode, _ := getString(row, "cmdCode", "commodityCode", "productCode")
 	productCode = strings.ToUpper(strings.TrimSpace(productCode))
@@ -1212,6 +1268,11 @@ func rowToObservation(row map[string]any, reporterISO3, partnerISO3 string, flow
 	if productCode == "" {
 		productCode = "TOTAL"
 	}
+	estimated, qualityNote := isProviderEstimate(row), ""
+	if estimated {
+		qualityNote = model.QualityProviderEstimate
+	}
+	indicator, _ := getString(row, "flowCode", "FlowCode", "rgCode")
 
 	return model.Observation{
 		Classification: strings.ToUpper(strings.TrimSpace(classification)),
@@ -1223,9 +1284,52 @@ func rowToObservation(row map[string]any, reporterISO3, partnerISO3 string, flow
 		PeriodType:     periodType,
 		Period:         period,
 		ValueUSD:       value,
+		Estimated:      estimated,
+		QualityNote:    qualityNote,
+		DatasetID:      rowDataset(row),
+		Indicator:      strings.ToUpper(indicator),
+		SourceNote:     rowSourceNote(row, multiplier),
 	}, nil
 }
 
+// rowDataset returns the {type}/{freq}/{cl} dataset a row reports, such as
+// "C/A/HS", or "" when any part is missing.
+func rowDataset(row map[string]any) string {
+	typeCode, okType := getString(row, "typeCode")
+	freqCode, okFreq := getString(row, "freqCode")
+	classification, okClassification := getString(row, "classificationSearchCode", "classificationCode", "clCode")
+	if !okType || !okFreq || !okClassification {
+		return ""
+	}
+	return strings.ToUpper(typeCode + "/" + freqCode + "/" + classification)
+}
+
+// rowSourceNote records the customs procedure, transport mode, and second
+// partner filters a row reports, and any unit multiplier, as key=value pairs.
+func rowSourceNote(row map[string]any, multiplier float64) string {
+	var parts []string
+	for _, key := range []string{"customsCode", "motCode", "partner2Code"} {
+		if value, ok := getString(row, key); ok {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	if multiplier != 1 {
+		parts = append(parts, "value_multiplier="+strconv.FormatFloat(multiplier, 'g', -1, 64))
+	}
+	return strings.Join(parts, ";")
+}
+
+// isProviderEstimate reports whether Comtrade flags a record as estimated:
+// not reported by the country itself, or carrying a nonzero legacy
+// estimation flag.
+func isProviderEstimate(row map[string]any) bool {
+	if value, ok := getValue(row, "isReported"); ok && !parseBool(value) {
+		return true
+	}
+	flag, ok := getFloat(row, "legacyEstimationFlag", "estCode")
+	return ok && flag != 0
+}
+
 func periodFromRow(row map[string]any) (model.PeriodType, string, bool) {
 	if value, ok := getString(row, "Period", "period", "Time", "time"); ok {
 		if periodType, period, ok := normalizePeriod(value); ok {
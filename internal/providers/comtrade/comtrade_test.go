@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -38,6 +39,63 @@ func TestParseObservationsNormalizesProviderRows(t *testing.T) {
 	}
 }
 
+func TestParseObservationsStoresSpecialPartnersUnderTheirCode(t *testing.T) {
+	body := []byte(`{"data": [{"period": "2023", "primaryValue": 1, "reporterISO": "KOR", "partnerCode": 0, "partnerISO": "W00"}]}`)
+
+	got, err := parseObservations(body, model.FlowExport, "KOR", "WLD", 1)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("parseObservations() = %v, %v", got, err)
+	}
+	if got[0].PartnerISO3 != "WLD" || got[0].Validate() != nil {
+		t.Fatalf("partner = %q (%v), want WLD", got[0].PartnerISO3, got[0].Validate())
+	}
+}
+
+func TestParseObservationsRecordsSourceMetadata(t *testing.T) {
+	body := []byte(`{
+		"data": [
+			{"period": "2023", "primaryValue": 10, "rt3ISO": "KOR", "pt3ISO": "USA", "typeCode": "C", "freqCode": "A",
+			 "classificationSearchCode": "HS", "flowCode": "X", "customsCode": "C00", "motCode": 0, "partner2Code": 0}
+		]
+	}`)
+
+	got, err := parseObservations(body, model.FlowExport, "KOR", "USA", 1000)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("parseObservations() = %v, %v", got, err)
+	}
+	observation := got[0]
+	if observation.DatasetID != "C/A/HS" || observation.Indicator != "X" {
+		t.Fatalf("dataset/indicator = %q/%q, want C/A/HS and X", observation.DatasetID, observation.Indicator)
+	}
+	if want := "customsCode=C00;motCode=0;partner2Code=0;value_multiplier=1000"; observation.SourceNote != want {
+		t.Fatalf("SourceNote = %q, want %q", observation.SourceNote, want)
+	}
+}
+
+func TestParseObservationsMarksProviderEstimates(t *testing.T) {
+	body := []byte(`{
+		"data": [
+			{"period": "2023", "primaryValue": 10, "rt3ISO": "KOR", "pt3ISO": "USA", "isReported": true, "legacyEstimationFlag": 0},
+			{"period": "2024", "primaryValue": 11, "rt3ISO": "KOR", "pt3ISO": "USA", "isReported": false},
+			{"period": "2022", "primaryValue": 9, "rt3ISO": "KOR", "pt3ISO": "USA", "legacyEstimationFlag": 4}
+		]
+	}`)
+
+	got, err := parseObservations(body, model.FlowExport, "KOR", "USA", 1)
+	if err != nil {
+		t.Fatalf("parseObservations() error = %v", err)
+	}
+	estimated := make(map[string]string, len(got))
+	for _, observation := range got {
+		if observation.Estimated {
+			estimated[observation.Period] = observation.QualityNote
+		}
+	}
+	if len(estimated) != 2 || estimated["2024"] != model.QualityProviderEstimate || estimated["2022"] != model.QualityProviderEstimate {
+		t.Fatalf("estimated periods = %v, want 2022 and 2024 as provider estimates", estimated)
+	}
+}
+
 func TestFetchPartnerMatrixOmitsPartnerCodeAndFiltersWorldAggregate(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		switch request.URL.Path {
@@ -75,13 +133,104 @@ func TestFetchPartnerMatrixOmitsPartnerCodeAndFiltersWorldAggregate(t *testing.T
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(rows) != 2 || rows[0].PartnerISO3 != "USA" || rows[1].PartnerISO3 != "CHN" {
-		t.Fatalf("matrix rows = %#v", rows)
+	if len(rows) != 3 || rows[0].PartnerISO3 != "USA" || rows[1].PartnerISO3 != "CHN" || rows[2].PartnerISO3 != "OTH" {
+		t.Fatalf("matrix rows = %#v, want the countries and areas nes as OTH", rows)
 	}
 	for _, row := range rows {
 		if row.ProductCode != "TOTAL" || row.ProductLevel != 0 || strings.TrimSpace(row.Provider) != "comtrade" {
 			t.Fatalf("invalid matrix row = %#v", row)
 		}
+		if row.DatasetID != "C/A/HS" || row.Indicator != "X" {
+			t.Fatalf("matrix row source = %q/%q, want the requested C/A/HS and X", row.DatasetID, row.Indicator)
+		}
+	}
+}
+
+func TestEnsureReferencesSharesOneDownload(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	failReporters := true
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		requests[request.URL.Path]++
+		fail := failReporters && request.URL.Path == "/files/reporters"
+		failReporters = false
+		mu.Unlock()
+		if fail {
+			http.Error(writer, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+		<-release
+		switch request.URL.Path {
+		case "/files/reporters":
+			_, _ = writer.Write([]byte(`{"results":[{"id":"410","iso3":"KOR","text":"Korea","isReporter":true,"isGroup":false}]}`))
+		case "/files/partners":
+			_, _ = writer.Write([]byte(`{"results":[{"id":"842","iso3":"USA","text":"United States","isPartner":true,"isGroup":false}]}`))
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	defer server.Close()
+	provider, err := NewWithConfig(Config{
+		BaseURL: server.URL, ReportersURL: server.URL + "/files/reporters", PartnersURL: server.URL + "/files/partners",
+		Timeout: time.Second, RateLimitPerSec: 1000, RateLimitBurst: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := provider.ListReporters(ctx); err == nil {
+		t.Fatal("want the failed reporters download reported")
+	}
+
+	// Every caller is past the loaded check before the first response is
+	// released, so each would download the references without the shared
+	// flight.
+	reporters := make([]int, 8)
+	errs := make([]error, len(reporters))
+	started := make(chan struct{}, len(reporters))
+	var wg sync.WaitGroup
+	for index := range reporters {
+		wg.Go(func() {
+			started <- struct{}{}
+			listed, err := provider.ListReporters(ctx)
+			reporters[index], errs[index] = len(listed), err
+		})
+	}
+	for range reporters {
+		<-started
+	}
+	<-arrived
+	close(release)
+	wg.Wait()
+	for index := range reporters {
+		if errs[index] != nil || reporters[index] != 1 {
+			t.Fatalf("caller %d listed %d reporters, %v", index, reporters[index], errs[index])
+		}
+	}
+	if code, err := provider.resolvePartnerCode("USA"); err != nil || code != "842" {
+		t.Fatalf("USA partner code = %q, %v", code, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/files/reporters"] != 2 || requests["/files/partners"] != 1 {
+		t.Fatalf("requests = %v, want the failed download retried once and then shared", requests)
+	}
+}
+
+func TestResolvePartnerCodeMapsWorldToZero(t *testing.T) {
+	provider := &Provider{partnerCode: map[string]string{"USA": "842"}}
+	if code, err := provider.resolvePartnerCode(" wld "); err != nil || code != "0" {
+		t.Fatalf("resolvePartnerCode(WLD) = %q, %v", code, err)
+	}
+	if code, err := provider.resolvePartnerCode("USA"); err != nil || code != "842" {
+		t.Fatalf("resolvePartnerCode(USA) = %q, %v", code, err)
 	}
 }
 
@@ -0,0 +1,55 @@
+// Package flight shares one load per key between concurrent callers, so
+// providers asking for the same reference or availability data at once send
+// a single request.
+package flight
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs at most one load per key at a time. A caller asking for a key
+// that is already loading waits for that load and gets its error. The zero
+// Group is ready to use.
+type Group struct {
+	mu      sync.Mutex
+	flights map[string]*call
+}
+
+type call struct {
+	done chan struct{}
+	err  error
+}
+
+// Do runs load for key, or waits for the load already running for it. The
+// load runs on ctx without its cancellation, so a caller that gives up does
+// not fail the load for the others; ctx bounds only this caller's wait.
+func (g *Group) Do(ctx context.Context, key string, load func(context.Context) error) error {
+	g.mu.Lock()
+	current, ok := g.flights[key]
+	if !ok {
+		if g.flights == nil {
+			g.flights = make(map[string]*call)
+		}
+		current = &call{done: make(chan struct{})}
+		g.flights[key] = current
+		go g.run(context.WithoutCancel(ctx), key, current, load)
+	}
+	g.mu.Unlock()
+	select {
+	case <-current.done:
+		return current.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (g *Group) run(ctx context.Context, key string, current *call, load func(context.Context) error) {
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(current.done)
+	}()
+	current.err = load(ctx)
+}
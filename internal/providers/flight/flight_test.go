@@ -0,0 +1,37 @@
+package flight
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDoKeepsTheLoadRunningWhenItsCallerGivesUp(t *testing.T) {
+	var group Group
+	release := make(chan struct{})
+	started := make(chan struct{})
+	loadErr := make(chan error, 1)
+	leader, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- group.Do(leader, "key", func(ctx context.Context) error {
+			close(started)
+			<-release
+			loadErr <- ctx.Err()
+			return nil
+		})
+	}()
+	<-started
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("leader error = %v, want its own cancellation", err)
+	}
+
+	close(release)
+	if err := <-loadErr; err != nil {
+		t.Fatalf("load context = %v, want it not cancelled with the leader", err)
+	}
+	if err := group.Do(context.Background(), "key", func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("Do after the load finished = %v", err)
+	}
+}
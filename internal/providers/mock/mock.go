@@ -0,0 +1,238 @@
+// Package mock is a scriptable provider for tests. It answers from scripted
+// responses instead of the network, injects failures, and records every
+// call, so collector behaviour such as deduplication, skipped series, and
+// quota handling can be exercised offline.
+package mock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/providers"
+)
+
+// Method names recorded in Call.Method and matched by Respond and Fail.
+const (
+	MethodListReporters      = "ListReporters"
+	MethodFetchLatest        = "FetchLatest"
+	MethodFetchSeries        = "FetchSeries"
+	MethodFetchProducts      = "FetchProducts"
+	MethodFetchPartnerMatrix = "FetchPartnerMatrix"
+)
+
+// Call is one request made to the provider. As a pattern for Respond, Fail,
+// and Count, empty fields match any value and a zero Level matches any level.
+type Call struct {
+	Method   string
+	Reporter string
+	Partner  string
+	Flow     model.Flow
+	From     string
+	To       string
+	Year     string
+	Level    int
+}
+
+func (c Call) matches(call Call) bool {
+	return (c.Method == "" || c.Method == call.Method) &&
+		(c.Reporter == "" || strings.EqualFold(c.Reporter, call.Reporter)) &&
+		(c.Partner == "" || strings.EqualFold(c.Partner, call.Partner)) &&
+		(c.Flow == "" || c.Flow == call.Flow) &&
+		(c.From == "" || c.From == call.From) &&
+		(c.To == "" || c.To == call.To) &&
+		(c.Year == "" || c.Year == call.Year) &&
+		(c.Level == 0 || c.Level == call.Level)
+}
+
+func (c Call) String() string {
+	parts := []string{c.Method}
+	for _, value := range []string{c.Reporter, c.Partner, string(c.Flow), c.From, c.To, c.Year} {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	if c.Level != 0 {
+		parts = append(parts, fmt.Sprintf("level=%d", c.Level))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Response is what a scripted call returns. FetchLatest returns the first
+// observation; ListReporters ignores Observations and returns the
+// provider's Reporters.
+type Response struct {
+	Observations []model.Observation
+	Err          error
+}
+
+// script hands out responses to the calls matching pattern in order. The
+// last response repeats, unless remaining limits how many calls it answers.
+type script struct {
+	pattern   Call
+	responses []Response
+	next      int
+	remaining int
+}
+
+// Provider implements providers.Provider, providers.ProductProvider, and
+// providers.PartnerMatrixProvider. The zero value is ready to use: it lists
+// no reporters and fails every fetch that has no scripted response.
+type Provider struct {
+	// ID is returned by Name, "mock" when empty.
+	ID string
+	// Reporters is returned by ListReporters.
+	Reporters []model.Reporter
+
+	mu       sync.Mutex
+	failures []*script
+	scripts  []*script
+	calls    []Call
+}
+
+// New returns a provider named id that lists reporters.
+func New(id string, reporters ...model.Reporter) *Provider {
+	return &Provider{ID: id, Reporters: reporters}
+}
+
+// Respond scripts the responses to calls matching pattern. Each call takes
+// the next response and the last one repeats. When several scripts match a
+// call, the most recent wins.
+func (p *Provider) Respond(pattern Call, responses ...Response) {
+	if len(responses) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripts = append(p.scripts, &script{pattern: pattern, responses: responses})
+}
+
+// Fail makes the next n calls matching pattern return err ahead of any
+// scripted response, or every matching call when n is zero or less.
+func (p *Provider) Fail(pattern Call, n int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failures = append(p.failures, &script{pattern: pattern, responses: []Response{{Err: err}}, remaining: n})
+}
+
+// Calls returns the calls made so far, in the order they arrived.
+func (p *Provider) Calls() []Call {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Call(nil), p.calls...)
+}
+
+// Count returns how many calls matched pattern.
+func (p *Provider) Count(pattern Call) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	count := 0
+	for _, call := range p.calls {
+		if pattern.matches(call) {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset forgets the recorded calls. Scripts and failures are kept.
+func (p *Provider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = nil
+}
+
+// answer records call and returns its response.
+func (p *Provider) answer(ctx context.Context, call Call) Response {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, call)
+	if err := ctx.Err(); err != nil {
+		return Response{Err: err}
+	}
+	for index := len(p.failures) - 1; index >= 0; index-- {
+		failure := p.failures[index]
+		if !failure.pattern.matches(call) {
+			continue
+		}
+		if failure.remaining > 0 {
+			failure.remaining--
+			if failure.remaining == 0 {
+				p.failures = append(p.failures[:index], p.failures[index+1:]...)
+			}
+		}
+		return failure.responses[0]
+	}
+	for index := len(p.scripts) - 1; index >= 0; index-- {
+		script := p.scripts[index]
+		if !script.pattern.matches(call) {
+			continue
+		}
+		response := script.responses[script.next]
+		if script.next < len(script.responses)-1 {
+			script.next++
+		}
+		return response
+	}
+	if call.Method == MethodListReporters {
+		return Response{}
+	}
+	return Response{Err: fmt.Errorf("mock: no response scripted for %s", call)}
+}
+
+func (p *Provider) Name() string {
+	if p.ID == "" {
+		return "mock"
+	}
+	return p.ID
+}
+
+func (p *Provider) ListReporters(ctx context.Context) ([]model.Reporter, error) {
+	response := p.answer(ctx, Call{Method: MethodListReporters})
+	if response.Err != nil {
+		return nil, response.Err
+	}
+	return append([]model.Reporter(nil), p.Reporters...), nil
+}
+
+func (p *Provider) FetchLatest(ctx context.Context, reporterISO3, partnerISO3 string, flow model.Flow) (model.Observation, error) {
+	call := Call{Method: MethodFetchLatest, Reporter: reporterISO3, Partner: partnerISO3, Flow: flow}
+	response := p.answer(ctx, call)
+	if response.Err != nil {
+		return model.Observation{}, response.Err
+	}
+	if len(response.Observations) == 0 {
+		return model.Observation{}, fmt.Errorf("mock: scripted response for %s has no observation", call)
+	}
+	return response.Observations[0], nil
+}
+
+func (p *Provider) FetchSeries(ctx context.Context, reporterISO3, partnerISO3 string, flow model.Flow, from, to string) ([]model.Observation, error) {
+	response := p.answer(ctx, Call{Method: MethodFetchSeries, Reporter: reporterISO3, Partner: partnerISO3, Flow: flow, From: from, To: to})
+	return observations(response)
+}
+
+func (p *Provider) FetchProducts(ctx context.Context, reporterISO3, partnerISO3 string, flow model.Flow, year string, level int) ([]model.Observation, error) {
+	response := p.answer(ctx, Call{Method: MethodFetchProducts, Reporter: reporterISO3, Partner: partnerISO3, Flow: flow, Year: year, Level: level})
+	return observations(response)
+}
+
+func (p *Provider) FetchPartnerMatrix(ctx context.Context, reporterISO3 string, flow model.Flow, year string) ([]model.Observation, error) {
+	response := p.answer(ctx, Call{Method: MethodFetchPartnerMatrix, Reporter: reporterISO3, Flow: flow, Year: year})
+	return observations(response)
+}
+
+// observations copies a response's observations so callers cannot change
+// the script.
+func observations(response Response) ([]model.Observation, error) {
+	if response.Err != nil {
+		return nil, response.Err
+	}
+	return append([]model.Observation(nil), response.Observations...), nil
+}
+
+var _ providers.Provider = (*Provider)(nil)
+var _ providers.ProductProvider = (*Provider)(nil)
+var _ providers.PartnerMatrixProvider = (*Provider)(nil)
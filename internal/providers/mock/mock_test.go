@@ -0,0 +1,76 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestProviderScriptsResponsesInOrderAndRepeatsTheLast(t *testing.T) {
+	ctx := context.Background()
+	provider := New("wits", model.Reporter{ISO3: "KOR", IsActive: true})
+	first := model.Observation{Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 100}
+	second := first
+	second.Period, second.ValueUSD = "2024", 110
+	provider.Respond(Call{Method: MethodFetchLatest, Reporter: "KOR"}, Response{Observations: []model.Observation{first}}, Response{Observations: []model.Observation{second}})
+
+	for _, want := range []string{"2023", "2024", "2024"} {
+		got, err := provider.FetchLatest(ctx, "KOR", "USA", model.FlowExport)
+		if err != nil || got.Period != want {
+			t.Fatalf("FetchLatest() = %+v, %v, want period %s", got, err, want)
+		}
+	}
+	if _, err := provider.FetchLatest(ctx, "VNM", "USA", model.FlowExport); err == nil || !strings.Contains(err.Error(), "no response scripted for FetchLatest VNM USA export") {
+		t.Fatalf("unscripted call error = %v", err)
+	}
+	reporters, err := provider.ListReporters(ctx)
+	if err != nil || len(reporters) != 1 || reporters[0].ISO3 != "KOR" || provider.Name() != "wits" {
+		t.Fatalf("ListReporters() = %+v, %v", reporters, err)
+	}
+}
+
+func TestProviderInjectsFailuresAndRecordsCalls(t *testing.T) {
+	ctx := context.Background()
+	provider := &Provider{}
+	quota := errors.New("quota exceeded")
+	series := []model.Observation{{ReporterISO3: "KOR", PartnerISO3: "CHN", Period: "2024"}}
+	provider.Respond(Call{Method: MethodFetchSeries}, Response{Observations: series})
+	provider.Fail(Call{Method: MethodFetchSeries, Partner: "CHN"}, 2, quota)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		got, err := provider.FetchSeries(ctx, "KOR", "CHN", model.FlowImport, "2023", "2024")
+		if attempt <= 2 && !errors.Is(err, quota) {
+			t.Fatalf("attempt %d error = %v, want the injected failure", attempt, err)
+		}
+		if attempt == 3 && (err != nil || len(got) != 1) {
+			t.Fatalf("attempt 3 = %+v, %v, want the scripted series", got, err)
+		}
+	}
+	got, _ := provider.FetchSeries(ctx, "KOR", "USA", model.FlowImport, "2023", "2024")
+	got[0].Period = "changed"
+	if again, _ := provider.FetchSeries(ctx, "KOR", "USA", model.FlowImport, "2023", "2024"); again[0].Period != "2024" {
+		t.Fatal("a caller changed the scripted observations")
+	}
+
+	provider.Fail(Call{}, 0, quota)
+	if _, err := provider.ListReporters(ctx); !errors.Is(err, quota) {
+		t.Fatalf("ListReporters() error = %v, want every call failing", err)
+	}
+	if count := provider.Count(Call{Method: MethodFetchSeries, Partner: "CHN"}); count != 3 {
+		t.Fatalf("Count(CHN series) = %d, want 3", count)
+	}
+	calls := provider.Calls()
+	if len(calls) != 6 || calls[0].From != "2023" || calls[0].To != "2024" || calls[5].Method != MethodListReporters {
+		t.Fatalf("Calls() = %+v", calls)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	provider.Reset()
+	if _, err := provider.FetchPartnerMatrix(cancelled, "KOR", model.FlowExport, "2024"); !errors.Is(err, context.Canceled) || len(provider.Calls()) != 1 {
+		t.Fatalf("cancelled call error = %v, calls = %+v", err, provider.Calls())
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"sync"
 	"time"
 
+	"tradegravity/internal/iso"
 	"tradegravity/internal/model"
 	"tradegravity/internal/providers"
 )
@@ -147,7 +148,7 @@ func (p *Provider) ListTariffImporters(ctx context.Context) ([]model.Reporter, e
 	p.mu.Unlock()
 	reporters := make([]model.Reporter, 0, len(countries))
 	for _, item := range countries {
-		if !item.IsReporter || item.IsGroup || !isISO3(item.ISO3) {
+		if !item.IsReporter || item.IsGroup || !iso.Valid(item.ISO3) {
 			continue
 		}
 		reporters = append(reporters, model.Reporter{ISO3: item.ISO3, NameEN: item.Name, IsActive: true})
@@ -179,8 +180,8 @@ func (p *Provider) LatestTariffYear(ctx context.Context, importerISO3 string) (s
 func (p *Provider) FetchTariffs(ctx context.Context, importerISO3, exporterISO3, year string, codes []string, dataType model.TariffDataType) ([]model.TariffObservation, error) {
 	importerISO3 = strings.ToUpper(strings.TrimSpace(importerISO3))
 	exporterISO3 = strings.ToUpper(strings.TrimSpace(exporterISO3))
-	if !isISO3(importerISO3) || !isISO3(exporterISO3) {
-		return nil, errors.New("trains: importer and exporter must be ISO3 codes")
+	if !iso.Valid(importerISO3) || (!iso.Valid(exporterISO3) && exporterISO3 != iso.World) {
+		return nil, errors.New("trains: importer and exporter must be ISO 3166-1 alpha-3 codes")
 	}
 	if !validYear(year) {
 		return nil, fmt.Errorf("trains: invalid tariff year %q", year)
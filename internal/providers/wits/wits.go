@@ -16,8 +16,11 @@ import (
 	"sync"
 	"time"
 
+	"tradegravity/internal/iso"
 	"tradegravity/internal/model"
 	"tradegravity/internal/providers"
+	"tradegravity/internal/providers/flight"
+	"tradegravity/internal/secrets"
 )
 
 const (
@@ -67,6 +70,7 @@ type Provider struct {
 	config  Config
 	client  *http.Client
 	limiter *rateLimiter
+	loads   flight.Group
 	mu      sync.Mutex
 	yearMap map[string]string
 }
@@ -143,7 +147,6 @@ func ConfigFromEnv() (Config, error) {
 		TradePathTemplate: getenv("WITS_TRADE_PATH", defaultTradePathTemplate),
 		ReportersPath:     getenv("WITS_REPORTERS_PATH", defaultReportersPath),
 		DataAvailPath:     getenv("WITS_DATAAVAIL_PATH", defaultDataAvailPath),
-		APIKey:            strings.TrimSpace(os.Getenv("WITS_API_KEY")),
 		APIKeyParam:       getenv("WITS_API_KEY_PARAM", defaultAPIKeyParam),
 		FormatParam:       getenv("WITS_FORMAT_PARAM", defaultFormatParam),
 		FormatValue:       getenv("WITS_FORMAT_VALUE", defaultFormatValue),
@@ -156,6 +159,11 @@ func ConfigFromEnv() (Config, error) {
 		AutoLatestYear:    getenvBool("WITS_AUTO_LATEST_YEAR", defaultAutoLatestYear),
 	}
 
+	var err error
+	if cfg.APIKey, err = secrets.Lookup("WITS_API_KEY"); err != nil {
+		return Config{}, err
+	}
+
 	cfg.RateLimitPerSec = getenvInt("WITS_RATE_LIMIT_PER_SEC", defaultRateLimitPerSec)
 	cfg.RateLimitBurst = getenvInt("WITS_RATE_LIMIT_BURST", defaultRateLimitBurst)
 	cfg.Timeout = time.Duration(getenvInt("WITS_TIMEOUT_SECONDS", defaultTimeoutSeconds)) * time.Second
@@ -215,12 +223,32 @@ func (p *Provider) FetchSeries(ctx context.Context, reporterISO3, partnerISO3 st
 	if err != nil {
 		return nil, err
 	}
+	dataset := datasourceFromPath(p.config.TradePathTemplate)
 	for i := range observations {
 		observations[i].Provider = p.Name()
+		observations[i].DatasetID = dataset
+		if observations[i].Indicator == "" {
+			observations[i].Indicator = indicator
+		}
+		if p.config.ValueMultiplier != 1 {
+			observations[i].SourceNote = "value_multiplier=" + strconv.FormatFloat(p.config.ValueMultiplier, 'g', -1, 64)
+		}
 	}
 	return observations, nil
 }
 
+// datasourceFromPath returns the WITS datasource a request path names, such
+// as "tradestats-trade", or "" for a path without a datasource segment.
+func datasourceFromPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for index, segment := range segments {
+		if strings.EqualFold(segment, "datasource") && index+1 < len(segments) {
+			return segments[index+1]
+		}
+	}
+	return ""
+}
+
 func (p *Provider) tradePath(reporterISO3, partnerISO3, indicator, yearValue string) (string, url.Values) {
 	path := p.config.TradePathTemplate
 	params := url.Values{}
@@ -444,15 +472,43 @@ type dataAvailabilityReporter struct {
 	Year string `xml:"year"`
 }
 
+// latestYear returns the latest year WITS holds for a reporter and
+// indicator from its data availability, which is asked once per pair and
+// cached. Callers racing on an uncached pair wait for a single request;
+// errors are not cached.
 func (p *Provider) latestYear(ctx context.Context, reporterISO3, indicator string) (string, error) {
 	cacheKey := strings.ToUpper(strings.TrimSpace(reporterISO3)) + "|" + strings.ToUpper(strings.TrimSpace(indicator))
-	p.mu.Lock()
-	if year, ok := p.yearMap[cacheKey]; ok {
-		p.mu.Unlock()
+	if year, ok := p.cachedYear(cacheKey); ok {
 		return year, nil
 	}
-	p.mu.Unlock()
+	err := p.loads.Do(ctx, cacheKey, func(ctx context.Context) error {
+		if _, ok := p.cachedYear(cacheKey); ok {
+			return nil
+		}
+		latest, err := p.fetchLatestYear(ctx, reporterISO3, indicator)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.yearMap[cacheKey] = latest
+		p.mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	year, _ := p.cachedYear(cacheKey)
+	return year, nil
+}
 
+func (p *Provider) cachedYear(cacheKey string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	year, ok := p.yearMap[cacheKey]
+	return year, ok
+}
+
+func (p *Provider) fetchLatestYear(ctx context.Context, reporterISO3, indicator string) (string, error) {
 	path := p.dataAvailabilityPath(reporterISO3, indicator)
 	body, err := p.doRequest(ctx, path, nil, "application/xml")
 	if err != nil {
@@ -482,13 +538,7 @@ func (p *Provider) latestYear(ctx context.Context, reporterISO3, indicator strin
 	if maxYear == 0 {
 		return "", errors.New("wits: no data availability years")
 	}
-	latest := strconv.Itoa(maxYear)
-
-	p.mu.Lock()
-	p.yearMap[cacheKey] = latest
-	p.mu.Unlock()
-
-	return latest, nil
+	return strconv.Itoa(maxYear), nil
 }
 
 func (p *Provider) dataAvailabilityPath(reporterISO3, indicator string) string {
@@ -521,7 +571,8 @@ func parseReportersXML(payload []byte) ([]model.Reporter, error) {
 
 	reporters := make([]model.Reporter, 0, len(response.Countries))
 	for _, country := range response.Countries {
-		if strings.TrimSpace(country.ISO3) == "" {
+		code, ok := iso.Lookup(country.ISO3)
+		if !ok {
 			continue
 		}
 		if strings.EqualFold(strings.TrimSpace(country.IsReporter), "1") {
@@ -529,9 +580,9 @@ func parseReportersXML(payload []byte) ([]model.Reporter, error) {
 				continue
 			}
 			reporters = append(reporters, model.Reporter{
-				ISO3:     strings.ToUpper(strings.TrimSpace(country.ISO3)),
+				ISO3:     code.Alpha3,
 				NameEN:   strings.TrimSpace(country.Name),
-				NameKO:   "",
+				NameKO:   code.NameKO,
 				Region:   "",
 				IsActive: true,
 			})
@@ -557,6 +608,13 @@ type sdmxSeries struct {
 
 type sdmxStructure struct {
 	Dimensions sdmxDimensions `json:"dimensions"`
+	Attributes sdmxAttributes `json:"attributes"`
+}
+
+// sdmxAttributes lists the attributes an observation array carries after its
+// value, in order.
+type sdmxAttributes struct {
+	Observation []sdmxDimension `json:"observation"`
 }
 
 type sdmxDimensions struct {
@@ -627,7 +685,8 @@ func parseSDMXObservations(payload sdmxResponse, fallbackFlow model.Flow, report
 		}
 
 		flow := fallbackFlow
-		if indicator, ok := dimensionValues["INDICATOR"]; ok {
+		indicator, ok := dimensionValues["INDICATOR"]
+		if ok {
 			if mappedFlow, ok := flowFromIndicator(indicator); ok {
 				flow = mappedFlow
 			}
@@ -654,6 +713,10 @@ func parseSDMXObservations(payload sdmxResponse, fallbackFlow model.Flow, report
 			if !ok {
 				continue
 			}
+			estimated, qualityNote := isEstimatedStatus(payload.Structure.Attributes, obsValue), ""
+			if estimated {
+				qualityNote = model.QualityProviderEstimate
+			}
 
 			observations = append(observations, model.Observation{
 				Classification: "WITS-TRADESTATS",
@@ -665,6 +728,9 @@ func parseSDMXObservations(payload sdmxResponse, fallbackFlow model.Flow, report
 				PeriodType:     periodType,
 				Period:         period,
 				ValueUSD:       value * multiplier,
+				Estimated:      estimated,
+				QualityNote:    qualityNote,
+				Indicator:      strings.ToUpper(strings.TrimSpace(indicator)),
 			})
 		}
 	}
@@ -721,6 +787,22 @@ func parseSDMXValue(values []any) (float64, bool) {
 	}
 }
 
+// isEstimatedStatus reports whether an observation's OBS_STATUS attribute is
+// E, the SDMX code for an estimated value.
+func isEstimatedStatus(attributes sdmxAttributes, values []any) bool {
+	for position, attribute := range attributes.Observation {
+		if !strings.EqualFold(attribute.ID, "OBS_STATUS") || position+1 >= len(values) {
+			continue
+		}
+		index, ok := parseSDMXValue(values[position+1 : position+2])
+		if !ok || index < 0 || int(index) >= len(attribute.Values) {
+			return false
+		}
+		return strings.EqualFold(attribute.Values[int(index)].ID, "E")
+	}
+	return false
+}
+
 func flowFromIndicator(indicator string) (model.Flow, bool) {
 	upper := strings.ToUpper(strings.TrimSpace(indicator))
 	switch {
@@ -944,6 +1026,8 @@ func normalizeFlow(value string) (model.Flow, bool) {
 		return model.FlowExport, true
 	case "import", "imports", "imp":
 		return model.FlowImport, true
+	case "total", "trade", "turnover":
+		return model.FlowTotal, true
 	default:
 		return "", false
 	}
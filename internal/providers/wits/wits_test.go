@@ -1,6 +1,12 @@
 package wits
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
 	"tradegravity/internal/model"
@@ -44,3 +50,132 @@ func TestNormalizePeriod(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSDMXObservationsMarksEstimatedStatus(t *testing.T) {
+	var payload sdmxResponse
+	if err := json.Unmarshal([]byte(`{
+		"dataSets": [{"series": {"0:0": {"observations": {"0": [10, 0], "1": [11, 1], "2": [12]}}}}],
+		"structure": {
+			"dimensions": {
+				"series": [{"id": "REPORTER", "values": [{"id": "KOR"}]}, {"id": "PARTNER", "values": [{"id": "USA"}]}],
+				"observation": [{"id": "TIME_PERIOD", "values": [{"id": "2022"}, {"id": "2023"}, {"id": "2024"}]}]
+			},
+			"attributes": {"observation": [{"id": "OBS_STATUS", "values": [{"id": "A"}, {"id": "E"}]}]}
+		}
+	}`), &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseSDMXObservations(payload, model.FlowExport, "KOR", "USA", 1)
+	if err != nil {
+		t.Fatalf("parseSDMXObservations() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("parseSDMXObservations() returned %d rows, want 3", len(got))
+	}
+	for _, observation := range got {
+		want := observation.Period == "2023"
+		if observation.Estimated != want || (observation.QualityNote == model.QualityProviderEstimate) != want {
+			t.Fatalf("%s estimated/note = %t/%q, want estimated only for 2023", observation.Period, observation.Estimated, observation.QualityNote)
+		}
+	}
+}
+
+func TestParseSDMXObservationsRecordsIndicator(t *testing.T) {
+	var payload sdmxResponse
+	if err := json.Unmarshal([]byte(`{
+		"dataSets": [{"series": {"0:0:0": {"observations": {"0": [10]}}}}],
+		"structure": {
+			"dimensions": {
+				"series": [{"id": "REPORTER", "values": [{"id": "KOR"}]}, {"id": "PARTNER", "values": [{"id": "USA"}]}, {"id": "INDICATOR", "values": [{"id": "MPRT-TRD-VL"}]}],
+				"observation": [{"id": "TIME_PERIOD", "values": [{"id": "2023"}]}]
+			}
+		}
+	}`), &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := parseSDMXObservations(payload, model.FlowExport, "KOR", "USA", 1)
+	if err != nil || len(got) != 1 {
+		t.Fatalf("parseSDMXObservations() = %v, %v", got, err)
+	}
+	if got[0].Indicator != "MPRT-TRD-VL" || got[0].Flow != model.FlowImport {
+		t.Fatalf("indicator/flow = %q/%s, want MPRT-TRD-VL and import", got[0].Indicator, got[0].Flow)
+	}
+	if dataset := datasourceFromPath(defaultTradePathTemplate); dataset != "tradestats-trade" {
+		t.Fatalf("datasourceFromPath() = %q, want tradestats-trade", dataset)
+	}
+}
+
+func TestLatestYearSharesOneDataAvailabilityRequest(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		requests[request.URL.Path]++
+		first := requests[request.URL.Path] == 1
+		mu.Unlock()
+		if strings.Contains(request.URL.Path, "MPRT") && first {
+			http.Error(writer, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+		<-release
+		_, _ = writer.Write([]byte(`<datasource><dataavailability><reporter><year>2022</year></reporter><reporter><year>2023</year></reporter></dataavailability></datasource>`))
+	}))
+	defer server.Close()
+	provider, err := NewWithConfig(Config{BaseURL: server.URL, RateLimitPerSec: 1000, RateLimitBurst: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every caller is past the cache before the first response is released,
+	// so each would send its own request without the shared flight.
+	ctx := context.Background()
+	years := make([]string, 8)
+	errs := make([]error, len(years))
+	started := make(chan struct{}, len(years))
+	var wg sync.WaitGroup
+	for index := range years {
+		wg.Go(func() {
+			started <- struct{}{}
+			years[index], errs[index] = provider.latestYear(ctx, "kor", "XPRT-TRD-VL")
+		})
+	}
+	for range years {
+		<-started
+	}
+	<-arrived
+	close(release)
+	wg.Wait()
+	for index := range years {
+		if errs[index] != nil || years[index] != "2023" {
+			t.Fatalf("caller %d got %q, %v", index, years[index], errs[index])
+		}
+	}
+	if _, err := provider.latestYear(ctx, "KOR", "xprt-trd-vl"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.latestYear(ctx, "KOR", "MPRT-TRD-VL"); err == nil {
+		t.Fatal("want the failed data availability request reported")
+	}
+	if year, err := provider.latestYear(ctx, "KOR", "MPRT-TRD-VL"); err != nil || year != "2023" {
+		t.Fatalf("retry = %q, %v", year, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("requests = %v", requests)
+	}
+	for path, count := range requests {
+		if want := 1 + strings.Count(path, "MPRT"); count != want {
+			t.Fatalf("%s requested %d times, want %d", path, count, want)
+		}
+	}
+}
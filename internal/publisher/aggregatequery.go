@@ -0,0 +1,203 @@
+package publisher
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"tradegravity/internal/model"
+)
+
+// AggregatePath is the query-time aggregation endpoint served by
+// AggregateHandler.
+const AggregatePath = "/v1/aggregate"
+
+// aggregateMetrics are the values /v1/aggregate can report per aggregate.
+var aggregateMetrics = map[string]func(aggregateEntry) float64{
+	"share_cn":  func(entry aggregateEntry) float64 { return entry.ShareCN },
+	"total":     func(entry aggregateEntry) float64 { return entry.Total },
+	"usa_trade": func(entry aggregateEntry) float64 { return entry.USA.Trade },
+	"chn_trade": func(entry aggregateEntry) float64 { return entry.CHN.Trade },
+}
+
+var aggregatePeriodPattern = regexp.MustCompile(`^\d{4}(-(Q[1-4]|0[1-9]|1[0-2]))?$`)
+
+// aggregateQuery is a parsed /v1/aggregate request.
+type aggregateQuery struct {
+	Group    string
+	Metric   string
+	Period   string
+	Provider string
+}
+
+type aggregateResponse struct {
+	GeneratedAt string           `json:"generated_at"`
+	Provider    string           `json:"provider"`
+	Group       string           `json:"group"`
+	Metric      string           `json:"metric"`
+	Period      string           `json:"period,omitempty"`
+	Rows        []aggregateValue `json:"rows"`
+}
+
+// aggregateValue is an aggregates.json entry with the requested metric.
+type aggregateValue struct {
+	aggregateEntry
+	Value float64 `json:"value"`
+}
+
+func parseAggregateQuery(values map[string][]string) (aggregateQuery, error) {
+	get := func(key, fallback string) string {
+		if items := values[key]; len(items) > 0 && strings.TrimSpace(items[0]) != "" {
+			return strings.TrimSpace(items[0])
+		}
+		return fallback
+	}
+	query := aggregateQuery{
+		Group:    strings.ToLower(get("group", "region")),
+		Metric:   strings.ToLower(get("metric", "share_cn")),
+		Period:   strings.ToUpper(get("period", "")),
+		Provider: strings.ToLower(get("provider", "wits")),
+	}
+	switch query.Group {
+	case "world", "region", "subregion", "income_group", "group":
+	default:
+		return aggregateQuery{}, fmt.Errorf("unsupported group %q (expected world, region, subregion, income_group, or group)", query.Group)
+	}
+	if _, ok := aggregateMetrics[query.Metric]; !ok {
+		return aggregateQuery{}, fmt.Errorf("unsupported metric %q (expected share_cn, total, usa_trade, or chn_trade)", query.Metric)
+	}
+	if query.Period != "" && !aggregatePeriodPattern.MatchString(query.Period) {
+		return aggregateQuery{}, fmt.Errorf("invalid period %q (expected 2024, 2024-Q1, or 2024-03)", query.Period)
+	}
+	return query, nil
+}
+
+// AggregateHandler computes aggregates from the store at request time, for
+// groupings and periods aggregates.json does not publish. Without a period
+// each aggregate uses the latest rows, as aggregates.json does; with one,
+// only observations of exactly that period are summed. Regions and income
+// groups come from the context file at contextPath, with the reporters table
+// and bundled classification filling the gaps. Country groups are the ones
+// defined in internal/iso.
+// observeQuery, when set, receives how long the store query took.
+func AggregateHandler(dbPath, contextPath string, observeQuery func(name string, elapsed time.Duration)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		query, err := parseAggregateQuery(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		contextData, err := loadContext(contextPath)
+		if err != nil {
+			http.Error(w, "failed to load country context", http.StatusInternalServerError)
+			return
+		}
+		start := time.Now()
+		rows, err := loadAggregateObservations(r.Context(), dbPath, query.Provider, query.Period)
+		if observeQuery != nil {
+			observeQuery("aggregate_observations", time.Since(start))
+		}
+		if err != nil {
+			http.Error(w, "failed to load observations", http.StatusInternalServerError)
+			return
+		}
+		reporterNames, err := loadReporterNames(dbPath)
+		if err != nil {
+			http.Error(w, "failed to load reporters", http.StatusInternalServerError)
+			return
+		}
+		latest := buildLatest(rows, []string{"USA", "CHN"}, growthYoY, alignLatest, mixedAllow)
+		enrichLatest(latest, contextData.Countries)
+		enrichReporterNames(latest, reporterNames)
+
+		response := aggregateResponse{
+			GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+			Provider:    query.Provider,
+			Group:       query.Group,
+			Metric:      query.Metric,
+			Period:      query.Period,
+			Rows:        []aggregateValue{},
+		}
+		metric := aggregateMetrics[query.Metric]
+		for _, entry := range queryAggregates(latest, query.Group) {
+			response.Rows = append(response.Rows, aggregateValue{aggregateEntry: entry, Value: metric(entry)})
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(response)
+	})
+}
+
+func queryAggregates(latest []latestEntry, group string) []aggregateEntry {
+	switch group {
+	case "world":
+		if entry, ok := worldAggregate(latest); ok {
+			return []aggregateEntry{entry}
+		}
+		return nil
+	case "region":
+		return aggregatesBy("region", latest, func(row latestEntry) string { return row.Region })
+	case "subregion":
+		return aggregatesBy("subregion", latest, func(row latestEntry) string { return row.Subregion })
+	case "income_group":
+		return aggregatesBy("income_group", latest, func(row latestEntry) string { return row.IncomeGroup })
+	default:
+		return groupAggregates(latest)
+	}
+}
+
+// loadAggregateObservations loads the USA and CHN totals an aggregate query
+// needs: the latest window when period is empty, or that period's rows.
+func loadAggregateObservations(ctx context.Context, dbPath, provider, period string) ([]observationRow, error) {
+	partners := []string{"USA", "CHN"}
+	if period == "" {
+		return loadLatestObservations(dbPath, provider, partners, nil)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	quality, err := observationQualityColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	value, err := observationValueColumn(db)
+	if err != nil {
+		return nil, err
+	}
+	filter, args := totalsFilter(provider, partners, nil)
+	rows, err := db.QueryContext(ctx, `
+		SELECT provider, reporter_iso3, partner_iso3, flow, period_type, period, `+value+`, `+quality+`
+		FROM trade_observations
+		WHERE `+filter+` AND period_type = ? AND period = ?`, append(args, string(aggregatePeriodType(period)), period)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanObservations(rows)
+}
+
+// aggregatePeriodType is the period type of a period aggregatePeriodPattern
+// accepts, so the query can use idx_trade_observations_period.
+func aggregatePeriodType(period string) model.PeriodType {
+	switch {
+	case strings.Contains(period, "Q"):
+		return model.PeriodQuarter
+	case strings.Contains(period, "-"):
+		return model.PeriodMonth
+	default:
+		return model.PeriodYear
+	}
+}
@@ -0,0 +1,98 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/store/sqlite"
+)
+
+func TestAggregateHandlerComputesGroupsAtQueryTime(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "tradegravity.db")
+	st, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var observations []model.Observation
+	add := func(reporter, partner, period string, value float64) {
+		for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+			observations = append(observations, model.Observation{
+				Provider: "wits", ReporterISO3: reporter, PartnerISO3: partner, Flow: flow,
+				PeriodType: model.PeriodYear, Period: period, ValueUSD: value,
+			})
+		}
+	}
+	add("KOR", "USA", "2022", 10)
+	add("KOR", "CHN", "2022", 30)
+	add("KOR", "USA", "2023", 20)
+	add("KOR", "CHN", "2023", 20)
+	add("JPN", "USA", "2023", 30)
+	add("JPN", "CHN", "2023", 10)
+	add("DEU", "USA", "2023", 5)
+	add("DEU", "CHN", "2023", 5)
+	if err := st.UpsertObservations(context.Background(), observations); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	contextPath := filepath.Join(dir, "context.json")
+	if err := writeJSON(contextPath, contextDataset{Countries: []contextCountry{
+		{ISO3: "KOR", Region: "East Asia & Pacific", IncomeGroup: "High income"},
+		{ISO3: "JPN", Region: "East Asia & Pacific", IncomeGroup: "High income"},
+		{ISO3: "DEU", Region: "Europe & Central Asia", IncomeGroup: "High income", Groups: []string{"EU"}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	handler := AggregateHandler(dbPath, contextPath, nil)
+	query := func(target string) (int, aggregateResponse) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		var response aggregateResponse
+		if recorder.Code == http.StatusOK {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode %s: %v", target, err)
+			}
+		}
+		return recorder.Code, response
+	}
+
+	code, regions := query(AggregatePath + "?group=region&metric=share_cn")
+	if code != http.StatusOK || len(regions.Rows) != 2 {
+		t.Fatalf("region aggregates = %d %+v", code, regions)
+	}
+	if asia := regions.Rows[0]; asia.ID != "EAST_ASIA_PACIFIC" || asia.Period != "2023" || asia.Value != 60.0/(60+100) {
+		t.Fatalf("East Asia aggregate = %+v, want JPN and KOR at 2023", asia)
+	}
+
+	code, subregions := query(AggregatePath + "?group=subregion&metric=total")
+	if code != http.StatusOK || len(subregions.Rows) != 2 || subregions.Rows[0].ID != "EASTERN_ASIA" || subregions.Rows[1].ID != "WESTERN_EUROPE" {
+		t.Fatalf("subregion aggregates = %d %+v, want bundled subregions for reporters the context does not subdivide", code, subregions)
+	}
+
+	code, past := query(AggregatePath + "?group=world&metric=chn_trade&period=2022")
+	if code != http.StatusOK || len(past.Rows) != 1 || past.Rows[0].Value != 60 || len(past.Rows[0].Members) != 1 {
+		t.Fatalf("world 2022 aggregate = %d %+v, want only KOR's 2022 CHN trade", code, past)
+	}
+
+	code, groups := query(AggregatePath + "?group=group&metric=total")
+	if code != http.StatusOK || len(groups.Rows) != 2 || groups.Rows[0].ID != "EU27" || groups.Rows[0].Value != 20 || groups.Rows[1].ID != "OECD" || len(groups.Rows[1].Members) != 3 {
+		t.Fatalf("group aggregates = %d %+v", code, groups)
+	}
+
+	code, income := query(AggregatePath + "?group=income_group")
+	if code != http.StatusOK || len(income.Rows) != 1 || income.Rows[0].ID != "HIGH_INCOME" || len(income.Rows[0].Members) != 3 {
+		t.Fatalf("income aggregates = %d %+v", code, income)
+	}
+
+	for _, target := range []string{"?group=continent", "?metric=gdp", "?period=2023-13", "?period=FY2023"} {
+		if code, _ := query(AggregatePath + target); code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", target, code)
+		}
+	}
+}
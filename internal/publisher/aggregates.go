@@ -0,0 +1,171 @@
+package publisher
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"tradegravity/internal/iso"
+	"tradegravity/internal/model"
+)
+
+type aggregatesFile struct {
+	SchemaVersion string           `json:"schema_version"`
+	GeneratedAt   string           `json:"generated_at"`
+	Provider      string           `json:"provider"`
+	Aggregates    []aggregateEntry `json:"aggregates"`
+}
+
+// aggregateEntry sums the USA and CHN blocks of member reporters on one
+// shared period. Members whose blocks are on another period, or on different
+// periods from each other, are listed in Excluded rather than mixed in.
+type aggregateEntry struct {
+	ID         string           `json:"id"`
+	Kind       string           `json:"kind"`
+	Name       string           `json:"name"`
+	PeriodType model.PeriodType `json:"period_type"`
+	Period     string           `json:"period"`
+	Members    []string         `json:"members"`
+	Excluded   []string         `json:"excluded,omitempty"`
+	USA        aggregateBlock   `json:"usa"`
+	CHN        aggregateBlock   `json:"chn"`
+	Total      float64          `json:"total"`
+	ShareCN    float64          `json:"share_cn"`
+}
+
+type aggregateBlock struct {
+	Export float64 `json:"export"`
+	Import float64 `json:"import"`
+	Trade  float64 `json:"trade"`
+}
+
+// buildAggregates emits a WORLD entry over every reporter, one entry per
+// region, subregion, and income group, and one per known country group.
+func buildAggregates(generatedAt, provider string, latest []latestEntry) aggregatesFile {
+	output := aggregatesFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Aggregates:    []aggregateEntry{},
+	}
+
+	if entry, ok := worldAggregate(latest); ok {
+		output.Aggregates = append(output.Aggregates, entry)
+	}
+	output.Aggregates = append(output.Aggregates, aggregatesBy("region", latest, func(row latestEntry) string { return row.Region })...)
+	output.Aggregates = append(output.Aggregates, aggregatesBy("subregion", latest, func(row latestEntry) string { return row.Subregion })...)
+	output.Aggregates = append(output.Aggregates, aggregatesBy("income_group", latest, func(row latestEntry) string { return row.IncomeGroup })...)
+	output.Aggregates = append(output.Aggregates, groupAggregates(latest)...)
+	return output
+}
+
+func worldAggregate(latest []latestEntry) (aggregateEntry, bool) {
+	return aggregateMembers("WORLD", "world", "World", latest)
+}
+
+// aggregatesBy emits one entry of the given kind per non-empty key, such as
+// a region or income group, in name order.
+func aggregatesBy(kind string, latest []latestEntry, key func(latestEntry) string) []aggregateEntry {
+	members := make(map[string][]latestEntry)
+	for _, row := range latest {
+		if name := key(row); name != "" {
+			members[name] = append(members[name], row)
+		}
+	}
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var entries []aggregateEntry
+	for _, name := range names {
+		if entry, ok := aggregateMembers(regionID(name), kind, name, members[name]); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// groupAggregates emits one entry per country group defined in internal/iso.
+func groupAggregates(latest []latestEntry) []aggregateEntry {
+	var entries []aggregateEntry
+	for _, group := range iso.Groups() {
+		var members []latestEntry
+		for _, row := range latest {
+			if group.Contains(row.ISO3) {
+				members = append(members, row)
+			}
+		}
+		if entry, ok := aggregateMembers(group.ID, "group", group.Name, members); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// aggregateMembers sums members on the period most of them share, preferring
+// the later period on a tie.
+func aggregateMembers(id, kind, name string, rows []latestEntry) (aggregateEntry, bool) {
+	counts := make(map[periodRef]int)
+	for _, row := range rows {
+		if row.SamePeriod {
+			counts[periodRef{PeriodType: row.USA.PeriodType, Period: row.USA.Period}]++
+		}
+	}
+	var best periodRef
+	for ref, count := range counts {
+		if best.Period == "" || count > counts[best] || (count == counts[best] && comparePeriods(ref.PeriodType, ref.Period, best.PeriodType, best.Period) > 0) {
+			best = ref
+		}
+	}
+	if best.Period == "" {
+		return aggregateEntry{}, false
+	}
+
+	entry := aggregateEntry{
+		ID:         id,
+		Kind:       kind,
+		Name:       name,
+		PeriodType: best.PeriodType,
+		Period:     best.Period,
+		Members:    []string{},
+	}
+	for _, row := range rows {
+		if !row.SamePeriod || row.USA.PeriodType != best.PeriodType || row.USA.Period != best.Period {
+			entry.Excluded = append(entry.Excluded, row.ISO3)
+			continue
+		}
+		entry.Members = append(entry.Members, row.ISO3)
+		entry.USA.add(row.USA)
+		entry.CHN.add(row.CHN)
+	}
+	sort.Strings(entry.Members)
+	sort.Strings(entry.Excluded)
+	entry.Total = roundCents(entry.USA.Trade + entry.CHN.Trade)
+	if entry.Total > 0 {
+		entry.ShareCN = entry.CHN.Trade / entry.Total
+	}
+	return entry, true
+}
+
+func (f *aggregateBlock) add(block partnerBlock) {
+	f.Export += block.Export
+	f.Import += block.Import
+	f.Trade += block.Trade
+}
+
+// regionID turns a region name such as "East Asia & Pacific" into
+// "EAST_ASIA_PACIFIC".
+func regionID(name string) string {
+	words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "_")
+}
+
+func augmentAggregateMeta(meta *metaFile, aggregates aggregatesFile) {
+	if meta == nil {
+		return
+	}
+	meta.AggregateCount = len(aggregates.Aggregates)
+}
@@ -0,0 +1,63 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildAggregatesSumsMembersOnSharedPeriod(t *testing.T) {
+	annual := func(iso3, region string, groups []string, period string, usa, chn float64) latestEntry {
+		return latestEntry{
+			ISO3: iso3, Region: region, Groups: groups, SamePeriod: true,
+			USA: partnerBlock{PeriodType: model.PeriodYear, Period: period, Export: usa, Trade: usa},
+			CHN: partnerBlock{PeriodType: model.PeriodYear, Period: period, Import: chn, Trade: chn},
+		}
+	}
+	latest := []latestEntry{
+		annual("DEU", "Europe & Central Asia", []string{"EU"}, "2023", 60, 40),
+		annual("FRA", "Europe & Central Asia", []string{"EU"}, "2023", 30, 10),
+		annual("VNM", "East Asia & Pacific", []string{"ASEAN"}, "2023", 20, 80),
+		annual("NOR", "Europe & Central Asia", nil, "2021", 10, 10),
+	}
+	latest[0].Subregion, latest[1].Subregion = "Western Europe", "Western Europe"
+	latest[2].IncomeGroup = "Lower middle income"
+
+	output := buildAggregates("2026-01-01T00:00:00Z", "WITS", latest)
+	byID := make(map[string]aggregateEntry)
+	for _, entry := range output.Aggregates {
+		byID[entry.ID] = entry
+	}
+	if len(output.Aggregates) != 9 || output.Aggregates[0].ID != "WORLD" {
+		t.Fatalf("unexpected aggregates: %+v", output.Aggregates)
+	}
+
+	world := byID["WORLD"]
+	if world.Period != "2023" || len(world.Members) != 3 || len(world.Excluded) != 1 || world.Excluded[0] != "NOR" {
+		t.Fatalf("world membership = %+v", world)
+	}
+	if world.Total != 240 || world.ShareCN != 130.0/240.0 {
+		t.Fatalf("world total=%v share_cn=%v", world.Total, world.ShareCN)
+	}
+	if eu := byID["EU27"]; eu.Kind != "group" || eu.USA.Trade != 90 || eu.CHN.Trade != 50 {
+		t.Fatalf("EU27 aggregate = %+v", eu)
+	}
+	if region := byID["EUROPE_CENTRAL_ASIA"]; region.Name != "Europe & Central Asia" || len(region.Members) != 2 {
+		t.Fatalf("region aggregate = %+v", region)
+	}
+	if subregion := byID["WESTERN_EUROPE"]; subregion.Kind != "subregion" || len(subregion.Members) != 2 {
+		t.Fatalf("subregion aggregate = %+v", subregion)
+	}
+	if income := byID["LOWER_MIDDLE_INCOME"]; income.Kind != "income_group" || income.Total != 100 {
+		t.Fatalf("income group aggregate = %+v", income)
+	}
+	if asean := byID["ASEAN"]; asean.ShareCN != 0.8 {
+		t.Fatalf("ASEAN aggregate = %+v", asean)
+	}
+	if oecd := byID["OECD"]; len(oecd.Members) != 2 || len(oecd.Excluded) != 1 || oecd.Excluded[0] != "NOR" {
+		t.Fatalf("OECD aggregate = %+v", oecd)
+	}
+	if south := byID["GLOBAL_SOUTH"]; len(south.Members) != 1 || south.Members[0] != "VNM" {
+		t.Fatalf("Global South aggregate = %+v", south)
+	}
+}
@@ -0,0 +1,130 @@
+package publisher
+
+import (
+	"fmt"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// Share alignment policies accepted by -align. With alignLatest each partner
+// block uses its own latest period, so share_cn may mix periods. alignCommon
+// moves both blocks to the latest period the two partners share, and
+// alignPeriodType keeps each block at its own latest period within the most
+// frequent period type both partners report.
+const (
+	alignLatest     = "latest"
+	alignCommon     = "common"
+	alignPeriodType = "period-type"
+)
+
+func parseAlignment(value string) (string, error) {
+	policy := strings.ToLower(strings.TrimSpace(value))
+	switch policy {
+	case alignLatest, alignCommon, alignPeriodType:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported alignment %q (expected latest, common, or period-type)", value)
+	}
+}
+
+type periodRef struct {
+	PeriodType model.PeriodType
+	Period     string
+}
+
+// seriesPeriods lists every period with a value for either flow.
+func seriesPeriods(series map[model.Flow]map[string]float64) map[periodRef]bool {
+	periods := make(map[periodRef]bool)
+	for _, flowSeries := range series {
+		for key := range flowSeries {
+			periodType, period, ok := strings.Cut(key, "|")
+			if !ok {
+				continue
+			}
+			periods[periodRef{PeriodType: model.PeriodType(periodType), Period: period}] = true
+		}
+	}
+	return periods
+}
+
+// alignValues replaces the USA and CHN latest values with ones taken from the
+// period chosen by policy. Partners without a shared period keep their own
+// latest values, and other tracked partners are left untouched.
+func alignValues(values map[string]map[model.Flow]latestValue, series map[string]map[model.Flow]map[string]float64, policy string) map[string]map[model.Flow]latestValue {
+	if policy == alignLatest || values["USA"] == nil || values["CHN"] == nil {
+		return values
+	}
+	usaPeriods := seriesPeriods(series["USA"])
+	chnPeriods := seriesPeriods(series["CHN"])
+
+	var usaRef, chnRef periodRef
+	switch policy {
+	case alignCommon:
+		for ref := range usaPeriods {
+			if chnPeriods[ref] && (usaRef.Period == "" || comparePeriods(ref.PeriodType, ref.Period, usaRef.PeriodType, usaRef.Period) > 0) {
+				usaRef = ref
+			}
+		}
+		chnRef = usaRef
+	case alignPeriodType:
+		periodType := sharedPeriodType(usaPeriods, chnPeriods)
+		usaRef = latestOfType(usaPeriods, periodType)
+		chnRef = latestOfType(chnPeriods, periodType)
+	}
+	if usaRef.Period == "" || chnRef.Period == "" {
+		return values
+	}
+
+	aligned := make(map[string]map[model.Flow]latestValue, len(values))
+	for partner, partnerValues := range values {
+		aligned[partner] = partnerValues
+	}
+	aligned["USA"] = valuesAt(series["USA"], usaRef)
+	aligned["CHN"] = valuesAt(series["CHN"], chnRef)
+	return aligned
+}
+
+// sharedPeriodType returns the most frequent period type both sets contain.
+func sharedPeriodType(a, b map[periodRef]bool) model.PeriodType {
+	best := model.PeriodType("")
+	for _, periodType := range []model.PeriodType{model.PeriodYear, model.PeriodQuarter, model.PeriodMonth} {
+		if latestOfType(a, periodType).Period != "" && latestOfType(b, periodType).Period != "" {
+			best = periodType
+		}
+	}
+	return best
+}
+
+func latestOfType(periods map[periodRef]bool, periodType model.PeriodType) periodRef {
+	var latest periodRef
+	if periodType == "" {
+		return latest
+	}
+	for ref := range periods {
+		if ref.PeriodType != periodType {
+			continue
+		}
+		if latest.Period == "" || comparePeriods(ref.PeriodType, ref.Period, latest.PeriodType, latest.Period) > 0 {
+			latest = ref
+		}
+	}
+	return latest
+}
+
+func valuesAt(series map[model.Flow]map[string]float64, ref periodRef) map[model.Flow]latestValue {
+	values := make(map[model.Flow]latestValue, 2)
+	for _, flow := range []model.Flow{model.FlowExport, model.FlowImport, model.FlowTotal} {
+		value, ok := seriesValue(series, flow, ref.PeriodType, ref.Period)
+		if !ok {
+			continue
+		}
+		values[flow] = latestValue{
+			PeriodType: ref.PeriodType,
+			Period:     ref.Period,
+			ValueUSD:   value,
+			Valid:      true,
+		}
+	}
+	return values
+}
@@ -0,0 +1,65 @@
+package publisher
+
+import (
+	"reflect"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func alignmentRows() []observationRow {
+	var rows []observationRow
+	for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+		rows = append(rows,
+			observationRow{ReporterISO: "KOR", PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodYear, Period: "2022", Value: 4000},
+			observationRow{ReporterISO: "KOR", PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodYear, Period: "2023", Value: 5000},
+			observationRow{ReporterISO: "KOR", PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodYear, Period: "2022", Value: 3000},
+			observationRow{ReporterISO: "KOR", PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodMonth, Period: "2024-05", Value: 500},
+		)
+	}
+	return rows
+}
+
+func TestBuildLatestAlignsSharePeriods(t *testing.T) {
+	tests := []struct {
+		policy     string
+		usaPeriod  string
+		chnPeriod  string
+		samePeriod bool
+		shareCN    float64
+	}{
+		{policy: alignLatest, usaPeriod: "2023", chnPeriod: "2024-05", shareCN: 10.0 / 110.0},
+		{policy: alignCommon, usaPeriod: "2022", chnPeriod: "2022", samePeriod: true, shareCN: 60.0 / 140.0},
+		{policy: alignPeriodType, usaPeriod: "2023", chnPeriod: "2022", shareCN: 60.0 / 160.0},
+	}
+	for _, tt := range tests {
+		latest := buildLatest(alignmentRows(), []string{"USA", "CHN"}, growthYoY, tt.policy, mixedAllow)
+		if len(latest) != 1 {
+			t.Fatalf("%s: buildLatest() returned %d rows, want 1", tt.policy, len(latest))
+		}
+		entry := latest[0]
+		if entry.USA.Period != tt.usaPeriod || entry.CHN.Period != tt.chnPeriod {
+			t.Fatalf("%s: periods usa=%q chn=%q, want %q and %q", tt.policy, entry.USA.Period, entry.CHN.Period, tt.usaPeriod, tt.chnPeriod)
+		}
+		if entry.SamePeriod != tt.samePeriod || entry.ShareCN != tt.shareCN {
+			t.Fatalf("%s: same_period=%v share_cn=%v, want %v and %v", tt.policy, entry.SamePeriod, entry.ShareCN, tt.samePeriod, tt.shareCN)
+		}
+		if !reflect.DeepEqual(entry.Partners["CHN"], entry.CHN) {
+			t.Fatalf("%s: partners CHN block does not mirror chn", tt.policy)
+		}
+	}
+
+	common := buildLatest(alignmentRows(), []string{"USA", "CHN"}, growthYoY, alignCommon, mixedAllow)[0]
+	if common.ComparisonPeriod != "2022" || common.ComparisonPeriodType != model.PeriodYear {
+		t.Fatalf("comparison period = %q %q", common.ComparisonPeriodType, common.ComparisonPeriod)
+	}
+}
+
+func TestParseAlignment(t *testing.T) {
+	if policy, err := parseAlignment(" Common "); err != nil || policy != alignCommon {
+		t.Fatalf("parseAlignment() = %q, %v", policy, err)
+	}
+	if _, err := parseAlignment("nearest"); err == nil {
+		t.Fatal("parseAlignment() accepted an unknown policy")
+	}
+}
@@ -0,0 +1,266 @@
+package publisher
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
+	"tradegravity/internal/iso"
+)
+
+// analyticsInput is what analytics modules read: the store's totals shaped
+// into the full history and latest rows with country context, and product exports when an enabled
+// module needs them.
+type analyticsInput struct {
+	GeneratedAt     string
+	Provider        string
+	History         seriesFile
+	Latest          []latestEntry
+	ProductProvider string
+	ProductLevel    int
+	Products        []observationRow
+	ProductLabels   map[string]string
+	RankingsTop     int
+	MoversTop       int
+	DiversionTop    int
+	ForecastHorizon int
+}
+
+// analyticsModule derives artifacts from the store. Build returns them keyed
+// by their default path relative to the output directory.
+type analyticsModule struct {
+	ID       string
+	Summary  string
+	Products bool
+	Build    func(input analyticsInput) map[string]any
+}
+
+// analyticsModules are the modules analytics run executes, in output order.
+// Each one has an enable flag named after its ID.
+var analyticsModules = []analyticsModule{
+	{ID: "rankings", Summary: "rankings.json", Build: func(input analyticsInput) map[string]any {
+		return map[string]any{"rankings.json": buildRankings(input.GeneratedAt, input.Provider, input.History, input.Latest, input.RankingsTop)}
+	}},
+	{ID: "tilt", Summary: "tilt.json", Build: func(input analyticsInput) map[string]any {
+		return map[string]any{"tilt.json": buildTiltFile(input.GeneratedAt, input.Provider, input.History, input.Latest)}
+	}},
+	{ID: "movers", Summary: "movers.json", Build: func(input analyticsInput) map[string]any {
+		return map[string]any{"movers.json": buildMovers(input.GeneratedAt, input.Provider, input.History, input.Latest, input.MoversTop)}
+	}},
+	{ID: "forecast", Summary: "forecast.json", Build: func(input analyticsInput) map[string]any {
+		return map[string]any{"forecast.json": buildForecast(input.GeneratedAt, input.Provider, input.History, input.Latest, input.ForecastHorizon)}
+	}},
+	{ID: "volatility", Summary: "volatility.json", Build: func(input analyticsInput) map[string]any {
+		return map[string]any{"volatility.json": buildVolatility(input.GeneratedAt, input.Provider, input.History, input.Latest)}
+	}},
+	{ID: "diversion", Summary: "diversion.json", Build: func(input analyticsInput) map[string]any {
+		return map[string]any{"diversion.json": buildDiversion(input.GeneratedAt, input.Provider, input.History, input.Latest, input.DiversionTop)}
+	}},
+	{ID: "correlation", Summary: "correlation.json", Build: func(input analyticsInput) map[string]any {
+		return map[string]any{"correlation.json": buildCorrelation(input.GeneratedAt, input.Provider, input.History, input.Latest)}
+	}},
+	{ID: "rca", Summary: "rca/", Products: true, Build: func(input analyticsInput) map[string]any {
+		index, files := buildRCAFiles(input.GeneratedAt, input.ProductProvider, input.ProductLevel, input.Products, input.ProductLabels, input.Latest)
+		outputs := map[string]any{"rca/index.json": index}
+		for iso3, file := range files {
+			outputs["rca/"+iso3+".json"] = file
+		}
+		return outputs
+	}},
+}
+
+// Analytics runs the analytics subcommand named by args[0].
+func Analytics(program string, args []string) {
+	cli.Dispatch(program, []cli.Command{
+		{Name: "run", Summary: "execute analytics modules over the store and write their artifacts", Run: AnalyticsRun},
+	}, args)
+}
+
+// AnalyticsRun parses the analytics run flags in args, executes every
+// enabled module, and writes its artifacts into -out next to a publisher
+// build, updating index.json and the checksum manifest. It exits the
+// process on failure.
+func AnalyticsRun(args []string) {
+	fs := flag.NewFlagSet("analytics run", flag.ExitOnError)
+	outDir := fs.String("out", "site/data", "output directory")
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	provider := fs.String("provider", "wits", "provider id")
+	partnersCSV := fs.String("partners", "USA,CHN", "comma-separated partner ISO3 list (must include USA,CHN)")
+	contextPath := fs.String("context", "site/data/context.json", "country context JSON (optional)")
+	productProvider := fs.String("product-provider", "comtrade", "HS2 product provider")
+	productLevel := fs.Int("product-level", 2, "product aggregation level")
+	hs2Path := fs.String("hs2", "configs/hs2.csv", "HS2 labels CSV")
+	attributionPath := fs.String("attribution", "configs/attribution.json", "provider attribution JSON credited in every artifact (optional)")
+	rankingsTop := fs.Int("rankings-top", 20, "reporters kept per ranking in rankings.json (0 = all)")
+	moversTop := fs.Int("movers-top", 10, "reporters kept per list in movers.json (0 = all)")
+	diversionTop := fs.Int("diversion-top", 10, "reporters kept per list in diversion.json (0 = all)")
+	forecastHorizonValue := fs.Int("forecast-horizon", forecastMaxHorizon, "periods projected per series in forecast.json (1-4)")
+	generatedAt := fs.String("generated-at", "", "RFC3339 publication time to use instead of SOURCE_DATE_EPOCH, the build in -out, or now (optional)")
+	enabled := make(map[string]*bool, len(analyticsModules))
+	for _, module := range analyticsModules {
+		enabled[module.ID] = fs.Bool(module.ID, true, "run the "+module.ID+" module ("+module.Summary+")")
+	}
+	config.Parse(fs, args)
+
+	forecastHorizon, err := parseForecastHorizon(*forecastHorizonValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid forecast-horizon:", err)
+		os.Exit(1)
+	}
+	partners, err := iso.ParseList(*partnersCSV)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid partners:", err)
+		os.Exit(1)
+	}
+	if err := ensureRequiredPartners(partners, []string{"USA", "CHN"}); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid partners:", err)
+		os.Exit(1)
+	}
+	var modules []analyticsModule
+	needsProducts := false
+	for _, module := range analyticsModules {
+		if *enabled[module.ID] {
+			modules = append(modules, module)
+			needsProducts = needsProducts || module.Products
+		}
+	}
+	if len(modules) == 0 {
+		fmt.Fprintln(os.Stderr, "invalid modules: every analytics module is disabled")
+		os.Exit(1)
+	}
+	attribution, err := loadAttribution(*attributionPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid attribution:", err)
+		os.Exit(1)
+	}
+	now, err := publicationTime(*generatedAt)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid generated-at:", err)
+		os.Exit(1)
+	}
+	if now == "" {
+		// Artifacts added to a build keep the publication time of the files
+		// around them.
+		var published metaFile
+		if err := readPublished(*outDir, "meta.json", &published); err == nil && published.GeneratedAt != "" {
+			now = published.GeneratedAt
+		} else {
+			now = time.Now().UTC().Format(time.RFC3339)
+		}
+	}
+
+	rows, err := loadObservations(*dbPath, *provider, partners, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load observations:", err)
+		os.Exit(1)
+	}
+	latest := buildLatest(rows, partners, growthYoY, alignLatest, mixedAllow)
+	reporterNames, err := loadReporterNames(*dbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load reporter names:", err)
+		os.Exit(1)
+	}
+	enrichReporterNames(latest, reporterNames)
+	contextData, err := loadContext(*contextPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load country context:", err)
+		os.Exit(1)
+	}
+	enrichLatest(latest, contextData.Countries)
+	input := analyticsInput{
+		GeneratedAt:     now,
+		Provider:        *provider,
+		History:         buildSeriesFile(now, *provider, partners, rows, 0),
+		Latest:          latest,
+		ProductProvider: *productProvider,
+		ProductLevel:    *productLevel,
+		RankingsTop:     *rankingsTop,
+		MoversTop:       *moversTop,
+		DiversionTop:    *diversionTop,
+		ForecastHorizon: forecastHorizon,
+	}
+	if needsProducts {
+		productRows, err := loadProductObservations(*dbPath, *productProvider, *productLevel, partners)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load product observations:", err)
+			os.Exit(1)
+		}
+		worldProductRows, err := loadProductObservations(*dbPath, *productProvider, *productLevel, []string{worldPartner})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load world product observations:", err)
+			os.Exit(1)
+		}
+		input.Products = append(worldProductRows, productRows...)
+		input.ProductLabels, err = loadProductLabels(*hs2Path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load product labels:", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create output dir:", err)
+		os.Exit(1)
+	}
+	artifacts, err = newArtifactWriter(*outDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load artifact checksums:", err)
+		os.Exit(1)
+	}
+	artifacts.attribution = attributionPolicy{
+		entries:   attribution,
+		totals:    []string{strings.ToLower(strings.TrimSpace(*provider))},
+		products:  strings.ToLower(strings.TrimSpace(*productProvider)),
+		worldBank: contextData.Status != "missing",
+	}
+	index, err := writeAnalytics(*outDir, now, runAnalyticsModules(modules, input))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write analytics:", err)
+		os.Exit(1)
+	}
+	ids := make([]string, len(modules))
+	for position, module := range modules {
+		ids[position] = module.ID
+	}
+	finishBuild(*outDir, now, publishTargets{}, index, "analytics="+strings.Join(ids, ","))
+}
+
+// runAnalyticsModules executes modules in order and merges their artifacts.
+// A later module writing the same path replaces the earlier one.
+func runAnalyticsModules(modules []analyticsModule, input analyticsInput) map[string]any {
+	outputs := make(map[string]any)
+	for _, module := range modules {
+		for relative, value := range module.Build(input) {
+			outputs[relative] = value
+		}
+	}
+	return outputs
+}
+
+// writeAnalytics writes outputs under outDir and returns the index.json of
+// the build already there with the written files added. Without a previous
+// build the index lists only the analytics artifacts.
+func writeAnalytics(outDir, generatedAt string, outputs map[string]any) (artifactIndexFile, error) {
+	var previousIndex artifactIndexFile
+	if err := readPublished(outDir, artifactIndexName, &previousIndex); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return artifactIndexFile{}, err
+	}
+	if artifacts != nil {
+		for relative, hash := range artifacts.previous {
+			artifacts.current[relative] = hash
+		}
+	}
+	if err := writeJSONFiles(outDir, "", outputs); err != nil {
+		return artifactIndexFile{}, fmt.Errorf("write %w", err)
+	}
+	records := previousIndex.Files
+	if artifacts != nil {
+		records = append(records, artifacts.records...)
+	}
+	return buildArtifactIndex(generatedAt, records), nil
+}
@@ -0,0 +1,83 @@
+package publisher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestAnalyticsModulesWriteIntoPublishedBuild(t *testing.T) {
+	var rows []observationRow
+	for _, period := range []string{"2020", "2021", "2022", "2023", "2024"} {
+		for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+			rows = append(rows,
+				observationRow{ReporterISO: "KOR", PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodYear, Period: period, Value: 4000},
+				observationRow{ReporterISO: "KOR", PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodYear, Period: period, Value: 6000},
+			)
+		}
+	}
+	partners := []string{"USA", "CHN"}
+	input := analyticsInput{
+		GeneratedAt:     "2026-01-01T00:00:00Z",
+		Provider:        "wits",
+		History:         buildSeriesFile("2026-01-01T00:00:00Z", "wits", partners, rows, 0),
+		Latest:          buildLatest(rows, partners, growthYoY, alignLatest, mixedAllow),
+		ProductProvider: "comtrade",
+		ProductLevel:    2,
+		Products: []observationRow{{
+			ProductCode: "85", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 500,
+		}},
+		ForecastHorizon: 2,
+	}
+	var modules []analyticsModule
+	for _, module := range analyticsModules {
+		if module.ID == "forecast" || module.ID == "rca" {
+			modules = append(modules, module)
+		}
+	}
+	outputs := runAnalyticsModules(modules, input)
+	if len(outputs) != 3 || outputs["forecast.json"] == nil || outputs["rca/index.json"] == nil || outputs["rca/KOR.json"] == nil {
+		t.Fatalf("unexpected outputs %v", outputs)
+	}
+	if forecast := outputs["forecast.json"].(forecastFile); forecast.Horizon != 2 || len(forecast.Rows) != 1 {
+		t.Fatalf("unexpected forecast %+v", forecast)
+	}
+
+	dir := t.TempDir()
+	previous := buildArtifactIndex("2025-12-01T00:00:00Z", []artifactRecord{
+		{Path: "latest.json", Size: 10, SHA256: "a"},
+		{Path: "forecast.json", Size: 10, SHA256: "stale"},
+	})
+	if err := writeJSON(filepath.Join(dir, artifactIndexName), previous); err != nil {
+		t.Fatal(err)
+	}
+	writer, err := newArtifactWriter(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifacts = writer
+	defer func() { artifacts = nil }()
+
+	index, err := writeAnalytics(dir, input.GeneratedAt, outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.FileCount != 4 || index.Files[0].Path != "forecast.json" || index.Files[0].SHA256 == "stale" || index.Files[1].Path != "latest.json" || index.Files[3].Path != "rca/index.json" {
+		t.Fatalf("expected the previous index with the analytics files merged, got %+v", index.Files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rca", "KOR.json")); err != nil {
+		t.Fatalf("expected rca/KOR.json to be written: %v", err)
+	}
+
+	// Without a previous build the index lists only the new files.
+	empty := t.TempDir()
+	if artifacts, err = newArtifactWriter(empty); err != nil {
+		t.Fatal(err)
+	}
+	index, err = writeAnalytics(empty, input.GeneratedAt, map[string]any{"forecast.json": outputs["forecast.json"]})
+	if err != nil || index.FileCount != 1 {
+		t.Fatalf("expected a one-file index, got %+v %v", index, err)
+	}
+}
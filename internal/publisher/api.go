@@ -0,0 +1,203 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// APIVersions are the prefixes APIHandler serves, oldest first. A released
+// version keeps its response shapes: an output schema change ships as a new
+// version, and older versions project the current data onto the shape they
+// promised.
+var APIVersions = []string{"v1", "v2"}
+
+// apiSchemas is the schema_version of the latest and meta documents each
+// API version returns.
+var apiSchemas = map[string]string{"v1": schemaVersionV1, "v2": schemaVersion}
+
+// API configures APIHandler. DBPath, when set, enables the aggregate and
+// series endpoints, and ObserveQuery, when set, receives their store query
+// timings. LatestIngest, when set, returns the store's newest ingested_at,
+// which the aggregate and series validators are derived from.
+type API struct {
+	DataDir      string
+	DBPath       string
+	ObserveQuery func(name string, elapsed time.Duration)
+	LatestIngest func(ctx context.Context) (string, error)
+}
+
+// APIHandler serves every API version under its /{version}/ prefix:
+// latest and meta return the published documents in the version's schema,
+// and download, aggregate, and series are shared by all versions until one
+// of them changes shape. coverage, with its series quality grades, was added
+// in schema 2 and is served only by versions on it. Each response names its
+// version in an API-Version header, and every endpoint but download answers
+// conditional GETs.
+func APIHandler(api API) http.Handler {
+	mux := http.NewServeMux()
+	download := DownloadHandler(api.DataDir)
+	var aggregate, series http.Handler
+	if api.DBPath != "" {
+		contextPath := filepath.Join(api.DataDir, "context.json")
+		aggregate = withStoreValidators(AggregateHandler(api.DBPath, contextPath, api.ObserveQuery), api.LatestIngest, contextPath)
+		series = withStoreValidators(SeriesHandler(api.DBPath, api.ObserveQuery), api.LatestIngest)
+	}
+	for _, version := range APIVersions {
+		prefix := "/" + version + "/"
+		mux.Handle(prefix+"latest", apiDocumentHandler(api.DataDir, version, "latest.json"))
+		mux.Handle(prefix+"meta", apiDocumentHandler(api.DataDir, version, "meta.json"))
+		mux.Handle(prefix+"download", download)
+		if apiSchemas[version] == schemaVersion {
+			mux.Handle(prefix+"coverage", apiDocumentHandler(api.DataDir, version, "coverage.json"))
+		}
+		if aggregate != nil {
+			mux.Handle(prefix+"aggregate", aggregate)
+			mux.Handle(prefix+"series", series)
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, version := range APIVersions {
+			if strings.HasPrefix(r.URL.Path, "/"+version+"/") {
+				w.Header().Set("API-Version", version)
+			}
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func apiDocumentHandler(dataDir, version, artifact string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := apiDocument(dataDir, version, artifact)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		var published struct {
+			GeneratedAt string `json:"generated_at"`
+		}
+		json.Unmarshal(body, &published)
+		modified, _ := time.Parse(time.RFC3339, published.GeneratedAt)
+		sum := sha256.Sum256(body)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		http.ServeContent(w, r, artifact, modified, bytes.NewReader(body))
+	})
+}
+
+// withStoreValidators answers conditional GETs for a store-backed endpoint
+// before it queries the store. Its responses carry a fresh generated_at, so
+// the ETag is weak: it names the newest ingested_at, the request, and the
+// size and modification time of each file in dependsOn, and Last-Modified
+// is the latest of those times. Without latestIngest, or when the store
+// cannot say, responses have no validators.
+func withStoreValidators(next http.Handler, latestIngest func(ctx context.Context) (string, error), dependsOn ...string) http.Handler {
+	if latestIngest == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ingestedAt, err := latestIngest(r.Context())
+		if err != nil || ingestedAt == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		modified, _ := time.Parse(time.RFC3339, ingestedAt)
+		hash := sha256.New()
+		fmt.Fprintf(hash, "%s\n%s\n%s\n", ingestedAt, r.URL.Path, r.URL.RawQuery)
+		for _, name := range dependsOn {
+			if info, err := os.Stat(name); err == nil {
+				fmt.Fprintf(hash, "%d %d\n", info.Size(), info.ModTime().UnixNano())
+				if info.ModTime().After(modified) {
+					modified = info.ModTime()
+				}
+			}
+		}
+		etag := `W/"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+		w.Header().Set("ETag", etag)
+		if !modified.IsZero() {
+			w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+		}
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			if notModified(r, etag, modified) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// notModified reports whether r's If-None-Match lists etag, comparing
+// weakly, or, without If-None-Match, whether If-Modified-Since is not
+// before modified.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.IsZero() && !modified.Truncate(time.Second).After(since)
+}
+
+// apiDocument is the compatibility layer: it returns the published
+// artifact as is when its schema is the one version promises, and projects
+// schema 2 builds onto the version 1 shape for v1. A build published with
+// -schema v1 cannot be served as v2, since the extra fields are gone.
+func apiDocument(dataDir, version, artifact string) ([]byte, error) {
+	body, err := os.ReadFile(filepath.Join(dataDir, artifact))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not published", artifact)
+	}
+	var published struct {
+		SchemaVersion string `json:"schema_version"`
+	}
+	if err := json.Unmarshal(body, &published); err != nil {
+		return nil, fmt.Errorf("published %s is not valid JSON", artifact)
+	}
+	want := apiSchemas[version]
+	switch {
+	case published.SchemaVersion == want:
+		return body, nil
+	case want == schemaVersionV1 && published.SchemaVersion == schemaVersion:
+		var latest latestFile
+		if err := readPublished(dataDir, "latest.json", &latest); err != nil {
+			return nil, err
+		}
+		var document any = latestV1(latest)
+		if artifact == "meta.json" {
+			var meta metaFile
+			if err := readPublished(dataDir, "meta.json", &meta); err != nil {
+				return nil, err
+			}
+			document = metaV1(meta, latestV1(latest))
+		}
+		var projected bytes.Buffer
+		encoder := json.NewEncoder(&projected)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+		return projected.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("published %s has schema %q; /%s serves schema %q", artifact, published.SchemaVersion, version, want)
+	}
+}
@@ -0,0 +1,172 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/store/sqlite"
+)
+
+func TestAPIHandlerServesVersionedSchemas(t *testing.T) {
+	dir := t.TempDir()
+	latest := latestFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   "2026-01-02T03:04:05Z",
+		Provider:      "wits",
+		Partners:      []string{"USA", "CHN", "DEU"},
+		Rows: []latestEntry{{
+			ISO3: "KOR", Name: "Korea",
+			USA: partnerBlock{Period: "2024", PeriodType: "Y", Trade: 10},
+			CHN: partnerBlock{Period: "2024", PeriodType: "Y", Trade: 30},
+		}},
+	}
+	if err := writeJSON(filepath.Join(dir, "latest.json"), latest); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(filepath.Join(dir, "meta.json"), metaFile{SchemaVersion: schemaVersion, GeneratedAt: latest.GeneratedAt, Provider: "wits"}); err != nil {
+		t.Fatal(err)
+	}
+	handler := APIHandler(API{DataDir: dir})
+	get := func(target string) *httptest.ResponseRecorder {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder
+	}
+
+	recorder := get("/v1/latest")
+	if recorder.Code != http.StatusOK || recorder.Header().Get("API-Version") != "v1" {
+		t.Fatalf("unexpected /v1/latest response %d %q", recorder.Code, recorder.Header().Get("API-Version"))
+	}
+	var v1 latestFileV1
+	if err := json.Unmarshal(recorder.Body.Bytes(), &v1); err != nil {
+		t.Fatal(err)
+	}
+	if v1.SchemaVersion != schemaVersionV1 || strings.Join(v1.Partners, ",") != "USA,CHN" || len(v1.Rows) != 1 || v1.Rows[0].CHN.Trade != 30 {
+		t.Fatalf("expected the version 1 projection, got %+v", v1)
+	}
+	if strings.Contains(recorder.Body.String(), `"name"`) {
+		t.Fatalf("version 1 rows should not carry schema 2 fields:\n%s", recorder.Body.String())
+	}
+
+	recorder = get("/v1/meta")
+	var metaV1Body metaFileV1
+	if err := json.Unmarshal(recorder.Body.Bytes(), &metaV1Body); err != nil {
+		t.Fatal(err)
+	}
+	if metaV1Body.SchemaVersion != schemaVersionV1 || metaV1Body.AvailablePartnerBlocks != 2 {
+		t.Fatalf("unexpected /v1/meta %+v", metaV1Body)
+	}
+
+	published, err := os.ReadFile(filepath.Join(dir, "latest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder = get("/v2/latest")
+	if recorder.Header().Get("API-Version") != "v2" || recorder.Body.String() != string(published) {
+		t.Fatalf("expected /v2/latest to serve latest.json unchanged")
+	}
+	if recorder := get("/v2/download?format=xlsx"); recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected download under /v2, got %d", recorder.Code)
+	}
+	coverage := buildCoverage(latest.GeneratedAt, "wits", []string{"USA"}, nil, nil, nil, nil)
+	if err := writeJSON(filepath.Join(dir, "coverage.json"), coverage); err != nil {
+		t.Fatal(err)
+	}
+	if recorder := get("/v2/coverage"); recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), `"reporters"`) {
+		t.Fatalf("expected /v2/coverage to serve coverage.json, got %d %s", recorder.Code, recorder.Body.String())
+	}
+	if recorder := get("/v1/coverage"); recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected no coverage endpoint under /v1, got %d", recorder.Code)
+	}
+	if recorder := get("/v1/aggregate"); recorder.Code != http.StatusNotFound {
+		t.Fatalf("expected no aggregate endpoint without a store, got %d", recorder.Code)
+	}
+
+	// A -schema v1 build still serves v1 but cannot be served as v2.
+	if err := writeJSON(filepath.Join(dir, "latest.json"), latestV1(latest)); err != nil {
+		t.Fatal(err)
+	}
+	if recorder := get("/v1/latest"); recorder.Code != http.StatusOK {
+		t.Fatalf("expected a version 1 build to serve /v1/latest, got %d", recorder.Code)
+	}
+	if recorder := get("/v2/latest"); recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), `schema "1.0"`) {
+		t.Fatalf("expected 503 for a version 1 build on /v2, got %d %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestAPIHandlerAnswersConditionalGETs(t *testing.T) {
+	dir := t.TempDir()
+	latest := latestFile{SchemaVersion: schemaVersion, GeneratedAt: "2026-01-02T03:04:05Z", Provider: "wits", Partners: []string{"USA", "CHN"}}
+	if err := writeJSON(filepath.Join(dir, "latest.json"), latest); err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(dir, "tradegravity.db")
+	st, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	ingestedAt := "2026-03-01T00:00:00Z"
+	handler := APIHandler(API{DataDir: dir, DBPath: dbPath, LatestIngest: func(context.Context) (string, error) {
+		return ingestedAt, nil
+	}})
+	get := func(target string, header ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			request.Header.Set(header[i], header[i+1])
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	v2 := get("/v2/latest")
+	etag := v2.Header().Get("ETag")
+	if v2.Code != http.StatusOK || etag == "" || v2.Header().Get("Last-Modified") != "Fri, 02 Jan 2026 03:04:05 GMT" {
+		t.Fatalf("/v2/latest = %d ETag %q Last-Modified %q", v2.Code, etag, v2.Header().Get("Last-Modified"))
+	}
+	if got := get("/v1/latest").Header().Get("ETag"); got == "" || got == etag {
+		t.Fatalf("/v1/latest ETag = %q, want one of its own for the projected body", got)
+	}
+	if recorder := get("/v2/latest", "If-None-Match", etag); recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+		t.Fatalf("If-None-Match = %d, want 304", recorder.Code)
+	}
+	if recorder := get("/v2/latest", "If-Modified-Since", "Fri, 02 Jan 2026 03:04:05 GMT"); recorder.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since = %d, want 304", recorder.Code)
+	}
+
+	series := get("/v1/series?reporter=KOR")
+	seriesTag := series.Header().Get("ETag")
+	if series.Code != http.StatusOK || !strings.HasPrefix(seriesTag, `W/"`) || series.Header().Get("Last-Modified") != "Sun, 01 Mar 2026 00:00:00 GMT" {
+		t.Fatalf("/v1/series = %d ETag %q Last-Modified %q", series.Code, seriesTag, series.Header().Get("Last-Modified"))
+	}
+	if recorder := get("/v1/series?reporter=KOR", "If-None-Match", seriesTag); recorder.Code != http.StatusNotModified {
+		t.Fatalf("series If-None-Match = %d, want 304", recorder.Code)
+	}
+	if recorder := get("/v1/series?reporter=JPN", "If-None-Match", seriesTag); recorder.Code != http.StatusOK {
+		t.Fatalf("another query = %d, want 200", recorder.Code)
+	}
+	if recorder := get("/v1/series?reporter=KOR", "If-Modified-Since", "Sun, 01 Mar 2026 00:00:00 GMT"); recorder.Code != http.StatusNotModified {
+		t.Fatalf("series If-Modified-Since = %d, want 304", recorder.Code)
+	}
+	ingestedAt = "2026-03-02T00:00:00Z"
+	if recorder := get("/v1/series?reporter=KOR", "If-None-Match", seriesTag); recorder.Code != http.StatusOK {
+		t.Fatalf("series after an ingest = %d, want 200", recorder.Code)
+	}
+
+	aggregateTag := get("/v1/aggregate").Header().Get("ETag")
+	if err := writeJSON(filepath.Join(dir, "context.json"), contextDataset{Status: "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := get("/v1/aggregate").Header().Get("ETag"); aggregateTag == "" || got == aggregateTag {
+		t.Fatalf("aggregate ETag %q then %q, want it to follow context.json", aggregateTag, got)
+	}
+}
@@ -0,0 +1,176 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// worldBankProvider is the attribution id of the World Bank Open Data
+// behind the context fields and the fx_rates conversions.
+const worldBankProvider = "worldbank"
+
+// providerAttribution is the credit a provider's terms require wherever its
+// data is redistributed. Every JSON artifact lists the entries of the
+// providers whose data it holds under attribution.
+type providerAttribution struct {
+	Provider string `json:"provider"`
+	Name     string `json:"name"`
+	Text     string `json:"text"`
+	Terms    string `json:"terms,omitempty"`
+}
+
+// loadAttribution reads the -attribution file, a JSON object of entries
+// keyed by provider id, such as {"wits": {"name": ..., "text": ...}}. A
+// missing file or an empty path turns attribution off.
+func loadAttribution(path string) (map[string]providerAttribution, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	body, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]providerAttribution
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	normalized := make(map[string]providerAttribution, len(entries))
+	for id, entry := range entries {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" || strings.TrimSpace(entry.Name) == "" || strings.TrimSpace(entry.Text) == "" {
+			return nil, fmt.Errorf("%s: entry %q needs a provider id, a name, and a text", path, id)
+		}
+		entry.Provider = id
+		normalized[id] = entry
+	}
+	return normalized, nil
+}
+
+// attributionPolicy says which providers' credits each artifact carries.
+// Totals-derived artifacts credit the totals providers, plus the World Bank
+// when context or currency conversion fed them; partitioned artifacts credit
+// the provider of their partition; build-wide files credit everyone.
+type attributionPolicy struct {
+	entries   map[string]providerAttribution
+	totals    []string
+	products  string
+	matrix    string
+	tariffs   string
+	worldBank bool
+}
+
+func (p attributionPolicy) enabled() bool {
+	return len(p.entries) > 0
+}
+
+// providers returns the provider ids whose data the artifact at relative, a
+// built-in output path, holds.
+func (p attributionPolicy) providers(relative string) []string {
+	top, _, _ := strings.Cut(filepath.ToSlash(relative), "/")
+	switch top {
+	case "products", "rca", "strategic-hs6":
+		return []string{p.products}
+	case "semiconductors":
+		if strings.HasPrefix(filepath.ToSlash(relative), "semiconductors/monthly/") {
+			return []string{p.products}
+		}
+		// The reference is curated from cited official sources.
+		return nil
+	case "tariffs":
+		return []string{p.tariffs}
+	case "bilateral-matrix", "mirror":
+		return []string{p.matrix}
+	case "meta.json", "catalog.json", artifactIndexName:
+		return p.all()
+	case "discrepancies.json":
+		return []string{"comtrade", "wits"}
+	case "coverage.json":
+		return p.totalsWith(p.products)
+	}
+	if p.worldBank {
+		return p.totalsWith(worldBankProvider)
+	}
+	return p.totals
+}
+
+func (p attributionPolicy) totalsWith(extra ...string) []string {
+	return append(append([]string(nil), p.totals...), extra...)
+}
+
+func (p attributionPolicy) all() []string {
+	ids := p.totalsWith(p.products, p.matrix, p.tariffs)
+	if p.worldBank {
+		ids = append(ids, worldBankProvider)
+	}
+	return ids
+}
+
+// credits returns the entries for the artifact at relative, deduplicated and
+// ordered by provider id. Providers without an entry are left out.
+func (p attributionPolicy) credits(relative string) []providerAttribution {
+	seen := map[string]bool{}
+	var credits []providerAttribution
+	for _, id := range p.providers(relative) {
+		entry, ok := p.entries[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		credits = append(credits, entry)
+	}
+	sort.Slice(credits, func(i, j int) bool { return credits[i].Provider < credits[j].Provider })
+	return credits
+}
+
+// withAttribution appends an attribution member to body, an indented JSON
+// object ending in a newline. Other JSON values are returned unchanged.
+func withAttribution(body []byte, credits []providerAttribution) ([]byte, error) {
+	trimmed := bytes.TrimRight(body, "\n")
+	if len(credits) == 0 || len(trimmed) < 2 || trimmed[0] != '{' || trimmed[len(trimmed)-1] != '}' {
+		return body, nil
+	}
+	member, err := json.MarshalIndent(credits, "  ", "  ")
+	if err != nil {
+		return nil, err
+	}
+	head := bytes.TrimRight(trimmed[:len(trimmed)-1], " \n")
+	var out bytes.Buffer
+	out.Write(head)
+	if len(head) > 1 {
+		out.WriteByte(',')
+	}
+	out.WriteString("\n  \"attribution\": ")
+	out.Write(member)
+	out.WriteString("\n}\n")
+	return out.Bytes(), nil
+}
+
+// decodeArtifact strictly decodes a published artifact into value. The
+// attribution member the build adds to every artifact is not part of any
+// artifact type, so it is set aside first.
+func decodeArtifact(r io.Reader, value any) error {
+	var members map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&members); err != nil {
+		return err
+	}
+	delete(members, "attribution")
+	body, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(value)
+}
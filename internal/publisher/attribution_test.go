@@ -0,0 +1,96 @@
+package publisher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAttributionReadsTheShippedConfig(t *testing.T) {
+	entries, err := loadAttribution(filepath.Join("..", "..", "configs", "attribution.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"wits", "comtrade", "trains", worldBankProvider} {
+		if entry := entries[id]; entry.Provider != id || entry.Text == "" || entry.Terms == "" {
+			t.Fatalf("entry %s = %+v", id, entry)
+		}
+	}
+	if entries, err := loadAttribution(filepath.Join(t.TempDir(), "missing.json")); err != nil || entries != nil {
+		t.Fatalf("missing file = %v, %v", entries, err)
+	}
+	path := filepath.Join(t.TempDir(), "attribution.json")
+	if err := os.WriteFile(path, []byte(`{"wits": {"name": "WITS"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadAttribution(path); err == nil {
+		t.Fatal("want an error for an entry without text")
+	}
+}
+
+func TestAttributionCreditsFollowTheArtifactsData(t *testing.T) {
+	policy := attributionPolicy{
+		entries: map[string]providerAttribution{
+			"wits":            {Provider: "wits", Name: "WITS", Text: "w"},
+			"comtrade":        {Provider: "comtrade", Name: "Comtrade", Text: "c"},
+			"trains":          {Provider: "trains", Name: "TRAINS", Text: "t"},
+			worldBankProvider: {Provider: worldBankProvider, Name: "World Bank", Text: "b"},
+		},
+		totals:    []string{"wits"},
+		products:  "comtrade",
+		matrix:    "comtrade",
+		tariffs:   "trains",
+		worldBank: true,
+	}
+	for relative, want := range map[string]string{
+		"latest.json":                       "wits,worldbank",
+		"countries/KOR.json":                "wits,worldbank",
+		"products/KOR.json":                 "comtrade",
+		"strategic-hs6/KOR/2023.json":       "comtrade",
+		"semiconductors/monthly/index.json": "comtrade",
+		"semiconductors/reference.json":     "",
+		"tariffs/KOR/2023.json":             "trains",
+		"mirror/index.json":                 "comtrade",
+		"coverage.json":                     "comtrade,wits",
+		"meta.json":                         "comtrade,trains,wits,worldbank",
+	} {
+		var got []string
+		for _, credit := range policy.credits(relative) {
+			got = append(got, credit.Provider)
+		}
+		if strings.Join(got, ",") != want {
+			t.Fatalf("%s credits %v, want %s", relative, got, want)
+		}
+	}
+}
+
+func TestWithAttributionAppendsAMemberThatDecodeArtifactSetsAside(t *testing.T) {
+	credits := []providerAttribution{{Provider: "wits", Name: "WITS", Text: "Trade data: WITS."}}
+	body, err := withAttribution([]byte("{\n  \"generated_at\": \"2026-01-01T00:00:00Z\"\n}\n"), credits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"generated_at\": \"2026-01-01T00:00:00Z\",\n  \"attribution\": [\n    {\n      \"provider\": \"wits\",\n      \"name\": \"WITS\",\n      \"text\": \"Trade data: WITS.\"\n    }\n  ]\n}\n"
+	if string(body) != want {
+		t.Fatalf("body =\n%s", body)
+	}
+	var empty map[string]json.RawMessage
+	if body, err := withAttribution([]byte("{}\n"), credits); err != nil || json.Unmarshal(body, &empty) != nil || len(empty) != 1 {
+		t.Fatalf("empty object = %s, %v", body, err)
+	}
+	if body, _ := withAttribution([]byte("[1]\n"), credits); string(body) != "[1]\n" {
+		t.Fatalf("array = %s", body)
+	}
+
+	var decoded struct {
+		GeneratedAt string `json:"generated_at"`
+	}
+	if err := decodeArtifact(strings.NewReader(want), &decoded); err != nil || decoded.GeneratedAt != "2026-01-01T00:00:00Z" {
+		t.Fatalf("decoded = %+v, %v", decoded, err)
+	}
+	if err := decodeArtifact(strings.NewReader(`{"generated_at": "x", "extra": 1}`), &decoded); err == nil {
+		t.Fatal("want unknown fields other than attribution rejected")
+	}
+}
@@ -0,0 +1,128 @@
+package publisher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/store/sqlite"
+	"tradegravity/internal/synthetic"
+)
+
+// The benchmarks run against synthetic observations, 200,000 unless
+// TRADEGRAVITY_BENCH_OBSERVATIONS says otherwise. TRADEGRAVITY_BENCH_DB
+// names a database to read instead, such as one written by
+// cmd/sampledata -synthetic, which saves generating millions of rows on
+// every run.
+const (
+	benchObservationsEnv     = "TRADEGRAVITY_BENCH_OBSERVATIONS"
+	benchDBEnv               = "TRADEGRAVITY_BENCH_DB"
+	defaultBenchObservations = 200_000
+)
+
+var benchPartners = []string{"USA", "CHN"}
+
+func benchConfig(b *testing.B) synthetic.Config {
+	b.Helper()
+	cfg := synthetic.Config{Observations: defaultBenchObservations}
+	if value := os.Getenv(benchObservationsEnv); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 {
+			b.Fatalf("%s=%q: want a positive count", benchObservationsEnv, value)
+		}
+		cfg.Observations = count
+	}
+	return cfg
+}
+
+// benchDatabase returns the path of a database holding the benchmark
+// observations.
+func benchDatabase(b *testing.B) string {
+	b.Helper()
+	if path := os.Getenv(benchDBEnv); path != "" {
+		return path
+	}
+	path := filepath.Join(b.TempDir(), "synthetic.db")
+	st, err := sqlite.New(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer st.Close()
+	if _, err := synthetic.Fill(context.Background(), st, benchConfig(b)); err != nil {
+		b.Fatal(err)
+	}
+	return path
+}
+
+// benchRows returns the benchmark observations with the given partners,
+// generated in memory unless TRADEGRAVITY_BENCH_DB names a database.
+func benchRows(b *testing.B, partners []string) []observationRow {
+	b.Helper()
+	if path := os.Getenv(benchDBEnv); path != "" {
+		rows, err := loadObservations(path, "", partners, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		return rows
+	}
+	tracked := map[string]bool{}
+	for _, partner := range partners {
+		tracked[partner] = true
+	}
+	var rows []observationRow
+	err := synthetic.Each(benchConfig(b), func(observation model.Observation) error {
+		if tracked[observation.PartnerISO3] {
+			rows = append(rows, observationRow{
+				Provider: observation.Provider, ReporterISO: observation.ReporterISO3, PartnerISO: observation.PartnerISO3,
+				Flow: observation.Flow, PeriodType: observation.PeriodType, Period: observation.Period, Value: model.CentsFromUSD(observation.ValueUSD),
+				Estimated: observation.Estimated, QualityNote: observation.QualityNote,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return rows
+}
+
+func BenchmarkLoadObservations(b *testing.B) {
+	path := benchDatabase(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := loadObservations(path, "", benchPartners, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuildLatest(b *testing.B) {
+	rows := benchRows(b, benchPartners)
+	b.ReportAllocs()
+	for b.Loop() {
+		buildLatest(rows, benchPartners, growthYoY, alignLatest, mixedAllow)
+	}
+	b.ReportMetric(float64(len(rows)), "rows")
+}
+
+func BenchmarkBuildHistory(b *testing.B) {
+	rows := benchRows(b, benchPartners)
+	b.ReportAllocs()
+	for b.Loop() {
+		buildSeriesFile("2026-01-01T00:00:00Z", "wits", benchPartners, rows, 0)
+	}
+	b.ReportMetric(float64(len(rows)), "rows")
+}
+
+func BenchmarkBuildCountryFiles(b *testing.B) {
+	rows := benchRows(b, benchPartners)
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", benchPartners, rows, 0)
+	latest := buildLatest(rows, benchPartners, growthYoY, alignLatest, mixedAllow)
+	b.ReportAllocs()
+	for b.Loop() {
+		buildCountryFiles("2026-01-01T00:00:00Z", "wits", benchPartners, history, latest)
+	}
+}
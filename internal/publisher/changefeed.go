@@ -1,12 +1,10 @@
-package main
+package publisher
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -142,7 +140,7 @@ func buildPublicationChanges(generatedAt, previousDir string, currentIndex semic
 }
 
 func loadPreviousSemiconductorMonthly(dataDir string) (semiconductorMonthlyIndexFile, map[string]semiconductorMonthlyFile, bool, error) {
-	indexPath := filepath.Join(dataDir, "semiconductors", "monthly", "index.json")
+	indexPath := layoutPath(dataDir, "semiconductors/monthly/index.json")
 	file, err := os.Open(indexPath)
 	if errors.Is(err, os.ErrNotExist) {
 		return semiconductorMonthlyIndexFile{}, nil, false, nil
@@ -152,9 +150,7 @@ func loadPreviousSemiconductorMonthly(dataDir string) (semiconductorMonthlyIndex
 	}
 	defer file.Close()
 	var index semiconductorMonthlyIndexFile
-	decoder := json.NewDecoder(file)
-	decoder.DisallowUnknownFields()
-	if err := decoder.Decode(&index); err != nil {
+	if err := decodeArtifact(file, &index); err != nil {
 		return semiconductorMonthlyIndexFile{}, nil, false, fmt.Errorf("decode previous monthly semiconductor index: %w", err)
 	}
 	if strings.TrimSpace(index.GeneratedAt) == "" {
@@ -166,15 +162,13 @@ func loadPreviousSemiconductorMonthly(dataDir string) (semiconductorMonthlyIndex
 		if !isPublishedISO3(reporter) {
 			return semiconductorMonthlyIndexFile{}, nil, false, fmt.Errorf("previous monthly semiconductor index has invalid reporter %q", partition.ReporterISO3)
 		}
-		path := filepath.Join(dataDir, "semiconductors", "monthly", reporter+".json")
+		path := layoutPath(dataDir, "semiconductors/monthly/"+reporter+".json")
 		partitionFile, err := os.Open(path)
 		if err != nil {
 			return semiconductorMonthlyIndexFile{}, nil, false, fmt.Errorf("open previous monthly semiconductor partition %s: %w", reporter, err)
 		}
 		var dataset semiconductorMonthlyFile
-		partitionDecoder := json.NewDecoder(partitionFile)
-		partitionDecoder.DisallowUnknownFields()
-		decodeErr := partitionDecoder.Decode(&dataset)
+		decodeErr := decodeArtifact(partitionFile, &dataset)
 		closeErr := partitionFile.Close()
 		if decodeErr != nil {
 			return semiconductorMonthlyIndexFile{}, nil, false, fmt.Errorf("decode previous monthly semiconductor partition %s: %w", reporter, decodeErr)
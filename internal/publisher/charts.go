@@ -0,0 +1,328 @@
+package publisher
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+var chartFormats = map[string]struct{}{"svg": {}, "png": {}}
+
+// parseChartFormats validates a comma-separated -charts value. An empty
+// value writes no charts.
+func parseChartFormats(value string) ([]string, error) {
+	var formats []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		format := strings.ToLower(strings.TrimSpace(item))
+		if format == "" || seen[format] {
+			continue
+		}
+		if _, ok := chartFormats[format]; !ok {
+			return nil, fmt.Errorf("unsupported chart format %q (expected svg or png)", format)
+		}
+		seen[format] = true
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+const (
+	chartWidth  = 1200
+	chartHeight = 630
+	chartLeft   = 110
+	chartRight  = 60
+	chartTop    = 100
+	chartBottom = 80
+)
+
+var (
+	chartInk   = color.RGBA{0x1f, 0x23, 0x28, 0xff}
+	chartMuted = color.RGBA{0x59, 0x63, 0x6e, 0xff}
+	chartGrid  = color.RGBA{0xd0, 0xd7, 0xde, 0xff}
+	chartCHN   = color.RGBA{0xcf, 0x22, 0x2e, 0xff}
+)
+
+// chartShape is one drawing primitive. Charts are built once as shapes and
+// then encoded as SVG or rasterized to PNG, so both formats have the same
+// geometry. PNG output has no text, since the standard library has no font
+// rasterizer; the SVG carries the title and axis labels.
+type chartShape struct {
+	// Kind is "rect", "line", "polyline", or "text".
+	Kind   string
+	Points []chartXY
+	Width  float64
+	Height float64
+	Stroke float64
+	Color  color.RGBA
+	Text   string
+	Size   float64
+	// Anchor is the SVG text-anchor: start, middle, or end.
+	Anchor string
+}
+
+type chartXY struct {
+	X, Y float64
+}
+
+type chart struct {
+	Title  string
+	Shapes []chartShape
+}
+
+// chartValue is a labelled value on a chart axis.
+type chartValue struct {
+	Label string
+	Value float64
+}
+
+// mostComparableType is the period type with the most comparable points,
+// the finer type on a tie.
+func mostComparableType(points []seriesPoint) model.PeriodType {
+	counts := make(map[model.PeriodType]int)
+	var periodType model.PeriodType
+	for _, point := range points {
+		if !point.Comparable || point.Total <= 0 {
+			continue
+		}
+		counts[point.PeriodType]++
+		count, best := counts[point.PeriodType], counts[periodType]
+		if count > best || (count == best && periodPriority(point.PeriodType) > periodPriority(periodType)) {
+			periodType = point.PeriodType
+		}
+	}
+	return periodType
+}
+
+// shareTrendChart plots a reporter's China share on a fixed 0–100% axis.
+// Periods of different types are not mixed on one axis, so it uses the
+// period type with the most comparable points, the finer type on a tie.
+func shareTrendChart(series reporterSeries) (chart, bool) {
+	periodType := mostComparableType(series.Points)
+	var values []chartValue
+	for _, point := range series.Points {
+		if point.PeriodType == periodType && point.Comparable && point.Total > 0 {
+			values = append(values, chartValue{Label: point.Period, Value: point.ShareCN})
+		}
+	}
+	if len(values) == 0 {
+		return chart{}, false
+	}
+	output := chart{Title: series.ISO3 + ": China share of USA+CHN trade"}
+	output.Shapes = append(output.Shapes, chartTitle(output.Title))
+	output.Shapes = append(output.Shapes, shareGrid(true)...)
+
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	step := 0.0
+	if len(values) > 1 {
+		step = plotWidth / float64(len(values)-1)
+	}
+	labelEvery := int(math.Ceil(float64(len(values)) / 8))
+	line := chartShape{Kind: "polyline", Stroke: 4, Color: chartCHN}
+	for index, value := range values {
+		x := float64(chartLeft) + step*float64(index)
+		if len(values) == 1 {
+			x = float64(chartLeft) + plotWidth/2
+		}
+		y := float64(chartTop) + plotHeight*(1-clampShare(value.Value))
+		line.Points = append(line.Points, chartXY{x, y})
+		if index%labelEvery == 0 || index == len(values)-1 {
+			output.Shapes = append(output.Shapes, chartShape{Kind: "text", Points: []chartXY{{x, float64(chartHeight-chartBottom) + 32}}, Text: value.Label, Size: 18, Color: chartMuted, Anchor: "middle"})
+		}
+	}
+	if len(line.Points) == 1 {
+		point := line.Points[0]
+		output.Shapes = append(output.Shapes, chartShape{Kind: "rect", Points: []chartXY{{point.X - 6, point.Y - 6}}, Width: 12, Height: 12, Color: chartCHN})
+	} else {
+		output.Shapes = append(output.Shapes, line)
+	}
+	return output, true
+}
+
+// topShareChart draws horizontal bars for the first ten reporters of the
+// share_cn ranking.
+func topShareChart(rankings rankingsFile) (chart, bool) {
+	var values []chartValue
+	for _, entry := range rankings.Rankings {
+		if entry.ID != "share_cn" {
+			continue
+		}
+		for _, row := range entry.Rows {
+			if len(values) == 10 {
+				break
+			}
+			values = append(values, chartValue{Label: row.ISO3, Value: row.Value})
+		}
+	}
+	if len(values) == 0 {
+		return chart{}, false
+	}
+	output := chart{Title: "Highest China share of USA+CHN trade, " + rankings.PeriodType + ":" + rankings.Period}
+	output.Shapes = append(output.Shapes, chartTitle(output.Title))
+	output.Shapes = append(output.Shapes, shareGrid(false)...)
+
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	slot := float64(chartHeight-chartTop-chartBottom) / 10
+	for index, value := range values {
+		y := float64(chartTop) + slot*float64(index)
+		width := plotWidth * clampShare(value.Value)
+		output.Shapes = append(output.Shapes,
+			chartShape{Kind: "rect", Points: []chartXY{{chartLeft, y + slot*0.15}}, Width: width, Height: slot * 0.7, Color: chartCHN},
+			chartShape{Kind: "text", Points: []chartXY{{chartLeft - 12, y + slot*0.5 + 7}}, Text: value.Label, Size: 20, Color: chartInk, Anchor: "end"},
+			chartShape{Kind: "text", Points: []chartXY{{chartLeft + width + 8, y + slot*0.5 + 7}}, Text: formatPercent(value.Value), Size: 18, Color: chartMuted, Anchor: "start"},
+		)
+	}
+	return output, true
+}
+
+func chartTitle(title string) chartShape {
+	return chartShape{Kind: "text", Points: []chartXY{{40, 56}}, Text: title, Size: 30, Color: chartInk, Anchor: "start"}
+}
+
+// shareGrid draws gridlines every 25 percentage points, horizontal for a
+// vertical share axis and vertical for a horizontal one.
+func shareGrid(vertical bool) []chartShape {
+	var shapes []chartShape
+	left, right := float64(chartLeft), float64(chartWidth-chartRight)
+	top, bottom := float64(chartTop), float64(chartHeight-chartBottom)
+	for step := 0; step <= 4; step++ {
+		share := float64(step) / 4
+		label := strconv.Itoa(step*25) + "%"
+		if vertical {
+			y := bottom - (bottom-top)*share
+			shapes = append(shapes,
+				chartShape{Kind: "line", Points: []chartXY{{left, y}, {right, y}}, Stroke: 1, Color: chartGrid},
+				chartShape{Kind: "text", Points: []chartXY{{left - 12, y + 6}}, Text: label, Size: 18, Color: chartMuted, Anchor: "end"},
+			)
+			continue
+		}
+		x := left + (right-left)*share
+		shapes = append(shapes,
+			chartShape{Kind: "line", Points: []chartXY{{x, top}, {x, bottom}}, Stroke: 1, Color: chartGrid},
+			chartShape{Kind: "text", Points: []chartXY{{x, bottom + 32}}, Text: label, Size: 18, Color: chartMuted, Anchor: "middle"},
+		)
+	}
+	return shapes
+}
+
+func clampShare(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
+
+func renderChartSVG(c chart) []byte {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="system-ui, -apple-system, 'Segoe UI', sans-serif">`+"\n", chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&body, "<title>%s</title>\n", html.EscapeString(c.Title))
+	fmt.Fprintf(&body, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", chartWidth, chartHeight)
+	for _, shape := range c.Shapes {
+		fill := svgColor(shape.Color)
+		switch shape.Kind {
+		case "rect":
+			fmt.Fprintf(&body, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n", svgNumber(shape.Points[0].X), svgNumber(shape.Points[0].Y), svgNumber(shape.Width), svgNumber(shape.Height), fill)
+		case "line", "polyline":
+			points := make([]string, 0, len(shape.Points))
+			for _, point := range shape.Points {
+				points = append(points, svgNumber(point.X)+","+svgNumber(point.Y))
+			}
+			fmt.Fprintf(&body, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linejoin="round" stroke-linecap="round"/>`+"\n", strings.Join(points, " "), fill, svgNumber(shape.Stroke))
+		case "text":
+			fmt.Fprintf(&body, `<text x="%s" y="%s" font-size="%s" fill="%s" text-anchor="%s">%s</text>`+"\n", svgNumber(shape.Points[0].X), svgNumber(shape.Points[0].Y), svgNumber(shape.Size), fill, shape.Anchor, html.EscapeString(shape.Text))
+		}
+	}
+	body.WriteString("</svg>\n")
+	return body.Bytes()
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// renderChartPNG rasterizes the chart's rects and lines. Text is skipped.
+func renderChartPNG(c chart) ([]byte, error) {
+	canvas := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for _, shape := range c.Shapes {
+		switch shape.Kind {
+		case "rect":
+			origin := shape.Points[0]
+			bounds := image.Rect(int(math.Round(origin.X)), int(math.Round(origin.Y)), int(math.Round(origin.X+shape.Width)), int(math.Round(origin.Y+shape.Height)))
+			draw.Draw(canvas, bounds, image.NewUniform(shape.Color), image.Point{}, draw.Src)
+		case "line", "polyline":
+			for index := 1; index < len(shape.Points); index++ {
+				strokeSegment(canvas, shape.Points[index-1], shape.Points[index], shape.Stroke, shape.Color)
+			}
+		}
+	}
+	var body bytes.Buffer
+	if err := png.Encode(&body, canvas); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// strokeSegment stamps a square pen of the given width along the segment.
+func strokeSegment(canvas *image.RGBA, from, to chartXY, width float64, ink color.RGBA) {
+	steps := int(math.Ceil(math.Max(math.Abs(to.X-from.X), math.Abs(to.Y-from.Y))))
+	half := math.Max(width, 1) / 2
+	for step := 0; step <= steps; step++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(step) / float64(steps)
+		}
+		x := from.X + (to.X-from.X)*t
+		y := from.Y + (to.Y-from.Y)*t
+		pen := image.Rect(int(math.Round(x-half)), int(math.Round(y-half)), int(math.Round(x+half)), int(math.Round(y+half)))
+		if pen.Empty() {
+			pen = image.Rect(int(x), int(y), int(x)+1, int(y)+1)
+		}
+		draw.Draw(canvas, pen, image.NewUniform(ink), image.Point{}, draw.Src)
+	}
+}
+
+// writeCharts writes charts/top_share_cn and one charts/share_cn/{ISO3}
+// trend per reporter in each requested format.
+func writeCharts(outDir string, formats []string, history seriesFile, rankings rankingsFile) error {
+	charts := make(map[string]chart)
+	if top, ok := topShareChart(rankings); ok {
+		charts["top_share_cn"] = top
+	}
+	for _, series := range history.Rows {
+		if trend, ok := shareTrendChart(series); ok {
+			charts[filepath.Join("share_cn", series.ISO3)] = trend
+		}
+	}
+	for name, c := range charts {
+		for _, format := range formats {
+			var body []byte
+			switch format {
+			case "svg":
+				body = renderChartSVG(c)
+			case "png":
+				rendered, err := renderChartPNG(c)
+				if err != nil {
+					return fmt.Errorf("render %s.png: %w", name, err)
+				}
+				body = rendered
+			}
+			if err := writeArtifact(filepath.Join(outDir, "charts", name+"."+format), body); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
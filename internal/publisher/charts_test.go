@@ -0,0 +1,101 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestParseChartFormats(t *testing.T) {
+	formats, err := parseChartFormats(" SVG,png,svg,")
+	if err != nil {
+		t.Fatalf("parseChartFormats: %v", err)
+	}
+	if strings.Join(formats, ",") != "svg,png" {
+		t.Fatalf("formats = %v, want svg,png", formats)
+	}
+	if _, err := parseChartFormats("gif"); err == nil {
+		t.Fatal("expected an error for gif")
+	}
+}
+
+func TestShareTrendChartUsesBestCoveredPeriodType(t *testing.T) {
+	series := reporterSeries{ISO3: "KOR", Points: []seriesPoint{
+		{PeriodType: model.PeriodYear, Period: "2021", ShareCN: 0.4, Total: 10, Comparable: true},
+		{PeriodType: model.PeriodYear, Period: "2022", ShareCN: 0.9, Total: 10},
+		{PeriodType: model.PeriodYear, Period: "2023", ShareCN: 0.5, Total: 10, Comparable: true},
+		{PeriodType: model.PeriodMonth, Period: "2020-12", ShareCN: 0.2, Total: 10, Comparable: true},
+	}}
+	c, ok := shareTrendChart(series)
+	if !ok {
+		t.Fatal("expected a chart")
+	}
+	var line *chartShape
+	for index := range c.Shapes {
+		if c.Shapes[index].Kind == "polyline" {
+			line = &c.Shapes[index]
+		}
+	}
+	if line == nil || len(line.Points) != 2 {
+		t.Fatalf("trend line = %+v, want the two comparable annual points", line)
+	}
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	if want := float64(chartTop) + plotHeight*0.6; line.Points[0].Y != want || line.Points[0].X != chartLeft || line.Points[1].X != chartWidth-chartRight {
+		t.Fatalf("trend points = %+v, want 40%% at y=%v spanning the plot", line.Points, want)
+	}
+	if _, ok := shareTrendChart(reporterSeries{ISO3: "DEU"}); ok {
+		t.Fatal("expected no chart without points")
+	}
+}
+
+func TestWriteChartsWritesBothFormats(t *testing.T) {
+	dir := t.TempDir()
+	history := seriesFile{Rows: []reporterSeries{{ISO3: "KOR", Points: []seriesPoint{
+		{PeriodType: model.PeriodYear, Period: "2022", ShareCN: 0.55, Total: 10, Comparable: true},
+		{PeriodType: model.PeriodYear, Period: "2023", ShareCN: 0.6, Total: 10, Comparable: true},
+	}}}}
+	rankings := rankingsFile{PeriodType: "Y", Period: "2023", Rankings: []ranking{{ID: "share_cn", Rows: []rankingRow{
+		{Rank: 1, ISO3: "KOR", Value: 0.6},
+		{Rank: 2, ISO3: "A&B", Value: 0.3},
+	}}}}
+	if err := writeCharts(dir, []string{"svg", "png"}, history, rankings); err != nil {
+		t.Fatalf("writeCharts: %v", err)
+	}
+	for _, name := range []string{"top_share_cn.svg", "share_cn/KOR.svg"} {
+		body, err := os.ReadFile(filepath.Join(dir, "charts", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoder := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			if _, err := decoder.Token(); err != nil {
+				if err != io.EOF {
+					t.Fatalf("%s is not well-formed XML: %v", name, err)
+				}
+				break
+			}
+		}
+	}
+	top, _ := os.ReadFile(filepath.Join(dir, "charts", "top_share_cn.svg"))
+	if !strings.Contains(string(top), ">A&amp;B</text>") || !strings.Contains(string(top), ">60.0%</text>") {
+		t.Fatalf("bar chart labels missing:\n%s", top)
+	}
+	for _, name := range []string{"top_share_cn.png", "share_cn/KOR.png"} {
+		file, err := os.Open(filepath.Join(dir, "charts", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := png.DecodeConfig(file)
+		file.Close()
+		if err != nil || config.Width != chartWidth || config.Height != chartHeight {
+			t.Fatalf("%s: config=%+v err=%v", name, config, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package publisher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// checksumsName is the manifest of artifact hashes kept in the output
+// directory. It is not a published resource.
+const checksumsName = ".checksums.json"
+
+type checksumManifest struct {
+	GeneratedAt string            `json:"generated_at"`
+	Files       map[string]string `json:"files"`
+	Touched     []string          `json:"touched"`
+}
+
+// artifactWriter skips files whose sha256 matches the previous build, so
+// rsync, CDN invalidation, and git commits only see changed artifacts.
+type artifactWriter struct {
+	root        string
+	layout      outputLayout
+	attribution attributionPolicy
+	sources     map[string]string
+	previous    map[string]string
+	current     map[string]string
+	records     []artifactRecord
+	touched     []string
+	unchanged   int
+
+	// mu guards sources, current, records, touched, and unchanged while
+	// writeJSONFiles writes partitions from several goroutines.
+	mu sync.Mutex
+}
+
+// artifacts tracks writes for the running build. When nil, as in tests,
+// every artifact is written unconditionally.
+var artifacts *artifactWriter
+
+func newArtifactWriter(root string) (*artifactWriter, error) {
+	writer := &artifactWriter{root: root, previous: map[string]string{}, current: map[string]string{}, sources: map[string]string{}}
+	body, err := os.ReadFile(filepath.Join(root, checksumsName))
+	if errors.Is(err, os.ErrNotExist) {
+		return writer, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest checksumManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", checksumsName, err)
+	}
+	if manifest.Files != nil {
+		writer.previous = manifest.Files
+	}
+	return writer, nil
+}
+
+// writeArtifact writes body to path unless the previous build recorded the
+// same hash for it and the file is still there.
+func writeArtifact(path string, body []byte) error {
+	if artifacts == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, body, 0o644)
+	}
+	return artifacts.write(path, body)
+}
+
+// layoutPath is where the artifact at the default path relative lives in dir
+// under the running build's -layout. Outside a build it is the default path,
+// which is right for the top-level JSON documents no layout moves.
+func layoutPath(dir, relative string) string {
+	if artifacts != nil {
+		relative = artifacts.layout.path(relative)
+	}
+	return filepath.Join(dir, filepath.FromSlash(relative))
+}
+
+func (w *artifactWriter) write(path string, body []byte) error {
+	relative, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return err
+	}
+	relative = filepath.ToSlash(relative)
+	source := relative
+	if w.layout.enabled() {
+		relative = w.layout.path(source)
+		path = filepath.Join(w.root, filepath.FromSlash(relative))
+	}
+	sum := sha256.Sum256(body)
+	hash := hex.EncodeToString(sum[:])
+	record := newArtifactRecord(relative, hash, body)
+
+	w.mu.Lock()
+	if w.layout.enabled() {
+		if other, ok := w.sources[relative]; ok && other != source {
+			w.mu.Unlock()
+			return fmt.Errorf("layout writes both %s and %s to %s", other, source, relative)
+		}
+		w.sources[relative] = source
+	}
+	w.current[relative] = hash
+	w.records = append(w.records, record)
+	unchanged := w.previous[relative] == hash
+	w.mu.Unlock()
+
+	if unchanged {
+		if _, err := os.Stat(path); err == nil {
+			w.mu.Lock()
+			w.unchanged++
+			w.mu.Unlock()
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.touched = append(w.touched, relative)
+	w.mu.Unlock()
+	return nil
+}
+
+// finish records the hashes of this build and the files it touched.
+func (w *artifactWriter) finish(generatedAt string) error {
+	sort.Strings(w.touched)
+	manifest := checksumManifest{GeneratedAt: generatedAt, Files: w.current, Touched: w.touched}
+	if manifest.Touched == nil {
+		manifest.Touched = []string{}
+	}
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(w.root, checksumsName), append(body, '\n'), 0o644)
+}
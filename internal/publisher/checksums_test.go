@@ -0,0 +1,47 @@
+package publisher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArtifactWriterSkipsUnchangedContent(t *testing.T) {
+	dir := t.TempDir()
+	build := func(files map[string]string) *artifactWriter {
+		t.Helper()
+		writer, err := newArtifactWriter(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		artifacts = writer
+		defer func() { artifacts = nil }()
+		for name, body := range files {
+			if err := writeArtifact(filepath.Join(dir, name), []byte(body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := writer.finish("2026-01-01T00:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		return writer
+	}
+
+	first := build(map[string]string{"a.json": "1", "b.json": "2"})
+	if len(first.touched) != 2 || first.unchanged != 0 {
+		t.Fatalf("first build: touched=%v unchanged=%d", first.touched, first.unchanged)
+	}
+
+	second := build(map[string]string{"a.json": "1", "b.json": "3"})
+	if len(second.touched) != 1 || second.touched[0] != "b.json" || second.unchanged != 1 {
+		t.Fatalf("second build: touched=%v unchanged=%d", second.touched, second.unchanged)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "a.json")); err != nil {
+		t.Fatal(err)
+	}
+	third := build(map[string]string{"a.json": "1", "b.json": "3"})
+	if len(third.touched) != 1 || third.touched[0] != "a.json" || third.unchanged != 1 {
+		t.Fatalf("deleted artifact should be rewritten: touched=%v unchanged=%d", third.touched, third.unchanged)
+	}
+}
@@ -0,0 +1,162 @@
+package publisher
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"tradegravity/internal/config"
+	"tradegravity/internal/iso"
+)
+
+// compareReport is the output of publisher compare. Rows use the diff.json
+// shape, with the previous_* fields from -db-a and the current_* fields from
+// -db-b.
+type compareReport struct {
+	GeneratedAt     string             `json:"generated_at"`
+	A               compareSide        `json:"a"`
+	B               compareSide        `json:"b"`
+	Status          string             `json:"status"`
+	Summary         publishDiffSummary `json:"summary"`
+	MaxShareCNDelta *compareDelta      `json:"max_share_cn_delta,omitempty"`
+	Rows            []publishDiffRow   `json:"rows"`
+}
+
+type compareSide struct {
+	DB             string `json:"db"`
+	Provider       string `json:"provider"`
+	Reporters      int    `json:"reporters"`
+	DominantPeriod string `json:"dominant_period,omitempty"`
+}
+
+// compareDelta is the reporter whose share_cn moved most between the two
+// databases. Delta is b minus a.
+type compareDelta struct {
+	ISO3  string  `json:"iso3"`
+	Delta float64 `json:"delta"`
+}
+
+// Compare parses the compare flags in args and reports how latest.json would
+// differ if it were built from -db-b instead of -db-a, with the same options.
+func Compare(args []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	dbA := fs.String("db-a", "", "baseline sqlite database (required)")
+	dbB := fs.String("db-b", "", "candidate sqlite database (required)")
+	provider := fs.String("provider", "wits", "provider id")
+	providerB := fs.String("provider-b", "", "provider id for -db-b (default: -provider)")
+	partnersCSV := fs.String("partners", "USA,CHN", "comma-separated partner ISO3 list (must include USA,CHN)")
+	growthBasis := fs.String("growth-basis", "yoy", "latest growth basis: yoy, mom, qoq, or ytd")
+	align := fs.String("align", "latest", "USA/CHN period alignment for share_cn: latest, common, or period-type")
+	mixed := fs.String("mixed-periods", "allow", "USA/CHN blocks on different period types: allow, downgrade, or incomparable")
+	outPath := fs.String("out", "", "write the JSON report here instead of stdout (optional)")
+	config.Parse(fs, args)
+
+	if *dbA == "" || *dbB == "" {
+		fmt.Fprintln(os.Stderr, "compare needs both -db-a and -db-b")
+		os.Exit(2)
+	}
+	if *providerB == "" {
+		*providerB = *provider
+	}
+	partners, err := iso.ParseList(*partnersCSV)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid partners:", err)
+		os.Exit(1)
+	}
+	if err := ensureRequiredPartners(partners, []string{"USA", "CHN"}); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid partners:", err)
+		os.Exit(1)
+	}
+	basis, err := parseGrowthBasis(*growthBasis)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid growth basis:", err)
+		os.Exit(1)
+	}
+	alignment, err := parseAlignment(*align)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid alignment:", err)
+		os.Exit(1)
+	}
+	mixedPeriods, err := parseMixedPeriods(*mixed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid mixed-periods:", err)
+		os.Exit(1)
+	}
+
+	build := func(dbPath, provider string) []latestEntry {
+		load := loadObservations
+		if latestWindowSupported(alignment, mixedPeriods) {
+			load = loadLatestObservations
+		}
+		rows, err := load(dbPath, provider, partners, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load observations from %s: %v\n", dbPath, err)
+			os.Exit(1)
+		}
+		return buildLatest(rows, partners, basis, alignment, mixedPeriods)
+	}
+	latestA, latestB := build(*dbA, *provider), build(*dbB, *providerB)
+	generatedAt, err := publicationTime("")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if generatedAt == "" {
+		generatedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	report := compareLatest(generatedAt,
+		compareSide{DB: *dbA, Provider: strings.ToLower(*provider)}, latestA,
+		compareSide{DB: *dbB, Provider: strings.ToLower(*providerB)}, latestB)
+
+	body, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to encode compare report:", err)
+		os.Exit(1)
+	}
+	body = append(body, '\n')
+	if *outPath == "" {
+		os.Stdout.Write(body)
+		return
+	}
+	if err := os.WriteFile(*outPath, body, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write compare report:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("publisher compare complete (out=%s status=%s rows=%d)\n", *outPath, report.Status, len(report.Rows))
+}
+
+// compareLatest diffs two sets of latest rows with the diff.json rules.
+func compareLatest(generatedAt string, sideA compareSide, latestA []latestEntry, sideB compareSide, latestB []latestEntry) compareReport {
+	sideA.Reporters, sideA.DominantPeriod = len(latestA), dominantLatestPeriod(latestA)
+	sideB.Reporters, sideB.DominantPeriod = len(latestB), dominantLatestPeriod(latestB)
+	baseline := previousLatestFile{Rows: make([]previousLatestRow, 0, len(latestA))}
+	for _, row := range latestA {
+		baseline.Rows = append(baseline.Rows, previousLatestRow{
+			ISO3:    row.ISO3,
+			USA:     previousPartnerBlock{Period: row.USA.Period, PeriodType: row.USA.PeriodType, Trade: row.USA.Trade},
+			CHN:     previousPartnerBlock{Period: row.CHN.Period, PeriodType: row.CHN.PeriodType, Trade: row.CHN.Trade},
+			ShareCN: row.ShareCN,
+		})
+	}
+	var diff publishDiffFile
+	diffLatestRows(&diff, baseline, latestB)
+
+	report := compareReport{GeneratedAt: generatedAt, A: sideA, B: sideB, Status: diff.Status, Summary: diff.Summary, Rows: diff.Rows}
+	if report.Rows == nil {
+		report.Rows = []publishDiffRow{}
+	}
+	for _, row := range report.Rows {
+		if row.PreviousShareCN == nil || row.CurrentShareCN == nil {
+			continue
+		}
+		delta := *row.CurrentShareCN - *row.PreviousShareCN
+		if report.MaxShareCNDelta == nil || math.Abs(delta) > math.Abs(report.MaxShareCNDelta.Delta) {
+			report.MaxShareCNDelta = &compareDelta{ISO3: row.ISO3, Delta: delta}
+		}
+	}
+	return report
+}
@@ -0,0 +1,47 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestCompareLatestDiffsTwoBuilds(t *testing.T) {
+	annual := func(trade float64) partnerBlock {
+		return partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: trade}
+	}
+	latestA := []latestEntry{
+		{ISO3: "JPN", USA: annual(10), CHN: annual(10), ShareCN: 0.5},
+		{ISO3: "KOR", USA: annual(10), CHN: annual(10), ShareCN: 0.5},
+		{ISO3: "BRA", USA: annual(10), CHN: annual(30), ShareCN: 0.75},
+	}
+	latestB := []latestEntry{
+		{ISO3: "JPN", USA: annual(10), CHN: annual(10), ShareCN: 0.5},
+		{ISO3: "KOR", USA: annual(10), CHN: annual(30), ShareCN: 0.75},
+		{ISO3: "VNM", USA: annual(5), CHN: annual(5), ShareCN: 0.5},
+	}
+
+	report := compareLatest("2026-01-01T00:00:00Z", compareSide{DB: "a.db", Provider: "wits"}, latestA, compareSide{DB: "b.db", Provider: "comtrade"}, latestB)
+	if report.Status != "changed" || report.A.Reporters != 3 || report.A.DominantPeriod != "Y:2023" || report.B.Provider != "comtrade" {
+		t.Fatalf("unexpected report header: %+v", report)
+	}
+	want := publishDiffSummary{AddedRows: 1, RemovedRows: 1, ValueChanged: 1, UnchangedRows: 1}
+	if report.Summary != want {
+		t.Fatalf("summary = %+v, want %+v", report.Summary, want)
+	}
+	if len(report.Rows) != 3 || report.Rows[1].ISO3 != "KOR" || *report.Rows[1].CHN.PreviousTrade != 10 || *report.Rows[1].CHN.CurrentTrade != 30 {
+		t.Fatalf("rows = %+v", report.Rows)
+	}
+	if report.MaxShareCNDelta == nil || report.MaxShareCNDelta.ISO3 != "KOR" || math.Abs(report.MaxShareCNDelta.Delta-0.25) > 1e-12 {
+		t.Fatalf("max share delta = %+v, want KOR +0.25", report.MaxShareCNDelta)
+	}
+}
+
+func TestCompareLatestReportsIdenticalBuildsUnchanged(t *testing.T) {
+	latest := []latestEntry{{ISO3: "KOR", ShareCN: 0.5}}
+	report := compareLatest("2026-01-01T00:00:00Z", compareSide{}, latest, compareSide{}, latest)
+	if report.Status != "unchanged" || len(report.Rows) != 0 || report.Rows == nil || report.MaxShareCNDelta != nil {
+		t.Fatalf("report = %+v, want an unchanged report with no rows", report)
+	}
+}
@@ -0,0 +1,72 @@
+package publisher
+
+import "sort"
+
+// concentration is a Herfindahl-Hirschman index of a reporter's trade across
+// partners: the sum of squared partner shares, from 1/n for trade spread
+// evenly over n partners to 1 for a single partner. EffectivePartners is its
+// inverse, the number of equally sized partners with the same concentration.
+type concentration struct {
+	HHI               float64 `json:"hhi"`
+	EffectivePartners float64 `json:"effective_partners"`
+	PartnerCount      int     `json:"partner_count"`
+	TopPartner        string  `json:"top_partner"`
+	TopShare          float64 `json:"top_share"`
+}
+
+// partnerConcentration returns nil when there is no positive trade.
+func partnerConcentration(trade map[string]float64) *concentration {
+	total := 0.0
+	partners := make([]string, 0, len(trade))
+	for partner, value := range trade {
+		if value > 0 {
+			total += value
+			partners = append(partners, partner)
+		}
+	}
+	if total <= 0 {
+		return nil
+	}
+	sort.Strings(partners)
+	output := &concentration{PartnerCount: len(partners)}
+	for _, partner := range partners {
+		share := trade[partner] / total
+		output.HHI += share * share
+		if share > output.TopShare {
+			output.TopPartner, output.TopShare = partner, share
+		}
+	}
+	output.EffectivePartners = 1 / output.HHI
+	return output
+}
+
+// applyLatestConcentration sets the concentration over a row's tracked
+// partner blocks when every block is on the same period, so the shares do not
+// mix periods.
+func applyLatestConcentration(latest []latestEntry) {
+	for index := range latest {
+		row := &latest[index]
+		trade := make(map[string]float64, len(row.Partners))
+		var first partnerBlock
+		samePeriod := true
+		for partner, block := range row.Partners {
+			if len(trade) == 0 {
+				first = block
+			} else if block.PeriodType != first.PeriodType || block.Period != first.Period {
+				samePeriod = false
+			}
+			trade[partner] = block.Trade
+		}
+		if samePeriod {
+			row.Concentration = partnerConcentration(trade)
+		}
+	}
+}
+
+func matrixConcentration(rows []matrixPartner) *concentration {
+	trade := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		trade[row.PartnerISO3] = row.TradeUSD
+	}
+	return partnerConcentration(trade)
+}
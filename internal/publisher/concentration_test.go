@@ -0,0 +1,42 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestPartnerConcentrationSumsSquaredShares(t *testing.T) {
+	got := partnerConcentration(map[string]float64{"USA": 60, "CHN": 30, "JPN": 10, "DEU": 0})
+	if got == nil {
+		t.Fatal("partnerConcentration() = nil, want an index")
+	}
+	if math.Abs(got.HHI-0.46) > 1e-12 || math.Abs(got.EffectivePartners-1/0.46) > 1e-12 {
+		t.Fatalf("HHI = %v effective = %v, want 0.46 and %v", got.HHI, got.EffectivePartners, 1/0.46)
+	}
+	if got.PartnerCount != 3 || got.TopPartner != "USA" || got.TopShare != 0.6 {
+		t.Fatalf("concentration = %+v, want 3 partners led by USA at 0.6", *got)
+	}
+	if partnerConcentration(map[string]float64{"USA": 0}) != nil {
+		t.Fatal("partnerConcentration() without trade should be nil")
+	}
+}
+
+func TestApplyLatestConcentrationSkipsMixedPeriods(t *testing.T) {
+	usa := partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: 50}
+	chn := partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: 50}
+	monthly := partnerBlock{PeriodType: model.PeriodMonth, Period: "2024-05", Trade: 5}
+	latest := []latestEntry{
+		{ISO3: "KOR", Partners: map[string]partnerBlock{"USA": usa, "CHN": chn}},
+		{ISO3: "VNM", Partners: map[string]partnerBlock{"USA": usa, "CHN": monthly}},
+	}
+
+	applyLatestConcentration(latest)
+	if got := latest[0].Concentration; got == nil || got.HHI != 0.5 || got.EffectivePartners != 2 {
+		t.Fatalf("KOR concentration = %+v, want HHI 0.5 over two partners", got)
+	}
+	if latest[1].Concentration != nil {
+		t.Fatalf("VNM concentration = %+v, want none across different periods", latest[1].Concentration)
+	}
+}
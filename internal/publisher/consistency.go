@@ -0,0 +1,111 @@
+package publisher
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// defaultConsistencyTolerance is the largest relative gap between an annual
+// value and its twelve summed months that is not flagged. Providers revise
+// annual and monthly releases on different schedules, so small gaps are
+// expected.
+const defaultConsistencyTolerance = 0.05
+
+// periodConsistency is one annual observation whose twelve monthly
+// observations sum to a different value. DeltaRatio is
+// (monthly_sum - annual) / annual.
+type periodConsistency struct {
+	ISO3          string     `json:"iso3"`
+	Partner       string     `json:"partner"`
+	Flow          model.Flow `json:"flow"`
+	Year          string     `json:"year"`
+	AnnualUSD     float64    `json:"annual_usd"`
+	MonthlySumUSD float64    `json:"monthly_sum_usd"`
+	DeltaRatio    float64    `json:"delta_ratio"`
+}
+
+// checkPeriodConsistency compares every positive annual observation with the
+// sum of the same reporter, partner, and flow's monthly observations for
+// that year, when all twelve months are stored. It returns how many pairs
+// were compared and those off by more than tolerance, largest gap first.
+func checkPeriodConsistency(rows []observationRow, tolerance float64) (int, []periodConsistency) {
+	checked, flagged := periodConsistencyChecks(rows, tolerance)
+	sortPeriodConsistency(flagged)
+	return checked, flagged
+}
+
+// periodConsistencyChecks is checkPeriodConsistency without the ordering, so
+// a build can check one reporter at a time and sort once.
+func periodConsistencyChecks(rows []observationRow, tolerance float64) (int, []periodConsistency) {
+	type monthlySum struct {
+		months map[int]struct{}
+		value  model.Cents
+	}
+	annual := make(map[string]observationRow)
+	monthly := make(map[string]*monthlySum)
+	for _, row := range rows {
+		prefix := strings.Join([]string{strings.ToUpper(row.ReporterISO), strings.ToUpper(row.PartnerISO), string(row.Flow)}, "|")
+		switch row.PeriodType {
+		case model.PeriodYear:
+			if _, ok := model.ParseYear(row.Period); ok {
+				annual[prefix+"|"+row.Period] = row
+			}
+		case model.PeriodMonth:
+			year, month, ok := model.ParseYearMonth(row.Period)
+			if !ok {
+				continue
+			}
+			key := fmt.Sprintf("%s|%04d", prefix, year)
+			sum := monthly[key]
+			if sum == nil {
+				sum = &monthlySum{months: make(map[int]struct{}, 12)}
+				monthly[key] = sum
+			}
+			if _, seen := sum.months[month]; !seen {
+				sum.months[month] = struct{}{}
+				sum.value += row.Value
+			}
+		}
+	}
+	checked := 0
+	flagged := []periodConsistency{}
+	for key, row := range annual {
+		sum, ok := monthly[key]
+		if !ok || len(sum.months) != 12 || row.Value <= 0 {
+			continue
+		}
+		checked++
+		delta := float64(sum.value-row.Value) / float64(row.Value)
+		if math.Abs(delta) <= tolerance {
+			continue
+		}
+		flagged = append(flagged, periodConsistency{
+			ISO3: strings.ToUpper(row.ReporterISO), Partner: strings.ToUpper(row.PartnerISO), Flow: row.Flow, Year: row.Period,
+			AnnualUSD: row.Value.USD(), MonthlySumUSD: sum.value.USD(), DeltaRatio: delta,
+		})
+	}
+	return checked, flagged
+}
+
+func sortPeriodConsistency(flagged []periodConsistency) {
+	sort.Slice(flagged, func(i, j int) bool {
+		left, right := math.Abs(flagged[i].DeltaRatio), math.Abs(flagged[j].DeltaRatio)
+		if left != right {
+			return left > right
+		}
+		if flagged[i].ISO3 != flagged[j].ISO3 {
+			return flagged[i].ISO3 < flagged[j].ISO3
+		}
+		if flagged[i].Partner != flagged[j].Partner {
+			return flagged[i].Partner < flagged[j].Partner
+		}
+		if flagged[i].Flow != flagged[j].Flow {
+			return flagged[i].Flow < flagged[j].Flow
+		}
+		return flagged[i].Year < flagged[j].Year
+	})
+}
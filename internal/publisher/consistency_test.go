@@ -0,0 +1,42 @@
+package publisher
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestCheckPeriodConsistencyFlagsAnnualValuesOffTheirMonths(t *testing.T) {
+	var rows []observationRow
+	months := func(reporter, partner string, flow model.Flow, year string, count int, value float64) {
+		for month := 1; month <= count; month++ {
+			rows = append(rows, observationRow{ReporterISO: reporter, PartnerISO: partner, Flow: flow, PeriodType: model.PeriodMonth, Period: fmt.Sprintf("%s-%02d", year, month), Value: model.CentsFromUSD(value)})
+		}
+	}
+	annual := func(reporter, partner string, flow model.Flow, year string, value float64) {
+		rows = append(rows, observationRow{ReporterISO: reporter, PartnerISO: partner, Flow: flow, PeriodType: model.PeriodYear, Period: year, Value: model.CentsFromUSD(value)})
+	}
+	// KOR exports to the USA match within 1%; imports from China are 20%
+	// short of the annual value; 2022 has only eleven months stored.
+	months("KOR", "USA", model.FlowExport, "2023", 12, 10)
+	annual("KOR", "USA", model.FlowExport, "2023", 121)
+	months("KOR", "CHN", model.FlowImport, "2023", 12, 8)
+	annual("KOR", "CHN", model.FlowImport, "2023", 120)
+	months("KOR", "CHN", model.FlowImport, "2022", 11, 1)
+	annual("KOR", "CHN", model.FlowImport, "2022", 500)
+	annual("VNM", "USA", model.FlowExport, "2023", 100)
+
+	checked, flagged := checkPeriodConsistency(rows, defaultConsistencyTolerance)
+	if checked != 2 || len(flagged) != 1 {
+		t.Fatalf("checked=%d flagged=%+v, want two checks and one flag", checked, flagged)
+	}
+	flag := flagged[0]
+	if flag.ISO3 != "KOR" || flag.Partner != "CHN" || flag.Flow != model.FlowImport || flag.Year != "2023" || flag.MonthlySumUSD != 96 || math.Abs(flag.DeltaRatio+0.2) > 1e-12 {
+		t.Fatalf("unexpected flag %+v", flag)
+	}
+	if _, strict := checkPeriodConsistency(rows, 0); len(strict) != 2 || strict[0].Partner != "CHN" {
+		t.Fatalf("expected a zero tolerance to flag both pairs, largest gap first, got %+v", strict)
+	}
+}
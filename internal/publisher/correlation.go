@@ -0,0 +1,293 @@
+package publisher
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// correlationMethod documents how correlation.json values are derived.
+const correlationMethod = "pearson correlation of period-over-period share_cn changes between reporters of a context region over the latest ten years of the region's period type; blocs join pairs at or above the bloc threshold"
+
+// Correlation limits. A pair needs correlationMinOverlap changes in common
+// inside the last correlationYears years; pairs correlated at or above
+// correlationBlocMin are linked into co-moving blocs.
+const (
+	correlationYears      = 10
+	correlationMinOverlap = 5
+	correlationBlocMin    = 0.7
+)
+
+// correlationFile is correlation.json: for each context region, how closely
+// its reporters' China shares move together, so blocs shifting toward or
+// away from China at the same time stand out from reporters moving alone.
+type correlationFile struct {
+	SchemaVersion string              `json:"schema_version"`
+	GeneratedAt   string              `json:"generated_at"`
+	Provider      string              `json:"provider"`
+	Method        string              `json:"method"`
+	WindowYears   int                 `json:"window_years"`
+	MinOverlap    int                 `json:"min_overlap"`
+	BlocThreshold float64             `json:"bloc_threshold"`
+	Regions       []regionCorrelation `json:"regions"`
+}
+
+// regionCorrelation is one region's matrix. Matrix and Overlaps are indexed
+// by Members in both dimensions; a cell is null when the pair shares fewer
+// than MinOverlap changes or either series is constant over them.
+type regionCorrelation struct {
+	Region      string              `json:"region"`
+	PeriodType  model.PeriodType    `json:"period_type"`
+	FirstPeriod string              `json:"first_period"`
+	LastPeriod  string              `json:"last_period"`
+	Members     []correlationMember `json:"members"`
+	Matrix      [][]*float64        `json:"matrix"`
+	Overlaps    [][]int             `json:"overlaps"`
+	Blocs       []correlationBloc   `json:"blocs"`
+}
+
+type correlationMember struct {
+	ISO3 string `json:"iso3"`
+	Name string `json:"name,omitempty"`
+}
+
+// correlationBloc is a connected group of reporters whose linking pairs
+// correlate at or above the bloc threshold. Members are not all pairwise
+// linked; MeanCorrelation and MinCorrelation cover every pair in the bloc
+// with a correlation.
+type correlationBloc struct {
+	Members         []string `json:"members"`
+	MeanCorrelation float64  `json:"mean_correlation"`
+	MinCorrelation  float64  `json:"min_correlation"`
+}
+
+// buildCorrelation computes a matrix for every region with two or more
+// reporters that have share changes. Each region uses the period type most
+// of its reporters can be correlated on, the finer type on a tie, and the
+// window ends at the latest change any member has in that type.
+func buildCorrelation(generatedAt, provider string, history seriesFile, latest []latestEntry) correlationFile {
+	output := correlationFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Method:        correlationMethod,
+		WindowYears:   correlationYears,
+		MinOverlap:    correlationMinOverlap,
+		BlocThreshold: correlationBlocMin,
+		Regions:       []regionCorrelation{},
+	}
+	changes := make(map[string]map[model.PeriodType]map[string]float64, len(history.Rows))
+	for _, row := range history.Rows {
+		changes[row.ISO3] = shareChanges(row.Points)
+	}
+	regions := make(map[string][]latestEntry)
+	for _, row := range latest {
+		if row.Region != "" {
+			regions[row.Region] = append(regions[row.Region], row)
+		}
+	}
+	names := make([]string, 0, len(regions))
+	for region := range regions {
+		names = append(names, region)
+	}
+	sort.Strings(names)
+	for _, region := range names {
+		if matrix, ok := correlateRegion(region, regions[region], changes); ok {
+			output.Regions = append(output.Regions, matrix)
+		}
+	}
+	return output
+}
+
+// shareChanges returns a reporter's share_cn change at every comparable
+// point whose preceding period is also comparable, by period type and
+// period.
+func shareChanges(points []seriesPoint) map[model.PeriodType]map[string]float64 {
+	byPeriod := make(map[model.PeriodType]map[string]seriesPoint)
+	for _, point := range points {
+		if !point.Comparable || point.Total <= 0 {
+			continue
+		}
+		if byPeriod[point.PeriodType] == nil {
+			byPeriod[point.PeriodType] = make(map[string]seriesPoint)
+		}
+		byPeriod[point.PeriodType][point.Period] = point
+	}
+	changes := make(map[model.PeriodType]map[string]float64)
+	for periodType, periods := range byPeriod {
+		for period, point := range periods {
+			previous, ok := periods[precedingPeriod(periodType, period)]
+			if !ok {
+				continue
+			}
+			if changes[periodType] == nil {
+				changes[periodType] = make(map[string]float64)
+			}
+			changes[periodType][period] = point.ShareCN - previous.ShareCN
+		}
+	}
+	return changes
+}
+
+func correlateRegion(region string, rows []latestEntry, changes map[string]map[model.PeriodType]map[string]float64) (regionCorrelation, bool) {
+	counts := make(map[model.PeriodType]int)
+	var periodType model.PeriodType
+	for _, row := range rows {
+		for candidate, series := range changes[row.ISO3] {
+			if len(series) < correlationMinOverlap {
+				continue
+			}
+			counts[candidate]++
+		}
+	}
+	for candidate, count := range counts {
+		if count > counts[periodType] || (count == counts[periodType] && periodPriority(candidate) > periodPriority(periodType)) {
+			periodType = candidate
+		}
+	}
+	if counts[periodType] < 2 {
+		return regionCorrelation{}, false
+	}
+
+	lastKey, last := math.MinInt, ""
+	for _, row := range rows {
+		for period := range changes[row.ISO3][periodType] {
+			if key := periodKey(periodType, period); key > lastKey {
+				lastKey, last = key, period
+			}
+		}
+	}
+	var first string
+	if window, err := model.PeriodRangeEnding(periodType, last, correlationYears*max(seasonLength(periodType), 1)); err == nil {
+		first = window.From
+	}
+	firstKey := periodKey(periodType, first)
+
+	type memberSeries struct {
+		member  correlationMember
+		periods []string
+		values  map[string]float64
+	}
+	var members []memberSeries
+	earliestKey, earliest := math.MaxInt, ""
+	for _, row := range rows {
+		series := memberSeries{member: correlationMember{ISO3: row.ISO3, Name: row.Name}, values: make(map[string]float64)}
+		for period, change := range changes[row.ISO3][periodType] {
+			if periodKey(periodType, period) >= firstKey {
+				series.periods = append(series.periods, period)
+				series.values[period] = change
+			}
+		}
+		if len(series.periods) < correlationMinOverlap {
+			continue
+		}
+		sort.Slice(series.periods, func(i, j int) bool {
+			return periodKey(periodType, series.periods[i]) < periodKey(periodType, series.periods[j])
+		})
+		if key := periodKey(periodType, series.periods[0]); key < earliestKey {
+			earliestKey, earliest = key, series.periods[0]
+		}
+		members = append(members, series)
+	}
+	if len(members) < 2 {
+		return regionCorrelation{}, false
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].member.ISO3 < members[j].member.ISO3 })
+
+	output := regionCorrelation{
+		Region:      region,
+		PeriodType:  periodType,
+		FirstPeriod: earliest,
+		LastPeriod:  last,
+		Members:     make([]correlationMember, len(members)),
+		Matrix:      make([][]*float64, len(members)),
+		Overlaps:    make([][]int, len(members)),
+	}
+	for index, series := range members {
+		output.Members[index] = series.member
+		output.Matrix[index] = make([]*float64, len(members))
+		output.Overlaps[index] = make([]int, len(members))
+	}
+	for i := range members {
+		for j := i; j < len(members); j++ {
+			var x, y []float64
+			for _, period := range members[i].periods {
+				value := members[i].values[period]
+				if other, ok := members[j].values[period]; ok {
+					x, y = append(x, value), append(y, other)
+				}
+			}
+			output.Overlaps[i][j], output.Overlaps[j][i] = len(x), len(x)
+			if len(x) < correlationMinOverlap {
+				continue
+			}
+			correlation, ok := pearson(x, y)
+			if !ok {
+				continue
+			}
+			correlation = math.Max(-1, math.Min(1, correlation))
+			output.Matrix[i][j], output.Matrix[j][i] = &correlation, &correlation
+		}
+	}
+	output.Blocs = correlationBlocs(output)
+	return output, true
+}
+
+// correlationBlocs links members by pairs at or above correlationBlocMin and
+// returns the groups of two or more, largest and most correlated first.
+func correlationBlocs(region regionCorrelation) []correlationBloc {
+	parent := make([]int, len(region.Members))
+	for index := range parent {
+		parent[index] = index
+	}
+	var find func(int) int
+	find = func(index int) int {
+		if parent[index] != index {
+			parent[index] = find(parent[index])
+		}
+		return parent[index]
+	}
+	for i := range region.Members {
+		for j := i + 1; j < len(region.Members); j++ {
+			if value := region.Matrix[i][j]; value != nil && *value >= correlationBlocMin {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+	groups := make(map[int][]int)
+	for index := range region.Members {
+		root := find(index)
+		groups[root] = append(groups[root], index)
+	}
+	blocs := []correlationBloc{}
+	for _, indexes := range groups {
+		if len(indexes) < 2 {
+			continue
+		}
+		bloc := correlationBloc{MinCorrelation: 1}
+		var values []float64
+		for position, i := range indexes {
+			bloc.Members = append(bloc.Members, region.Members[i].ISO3)
+			for _, j := range indexes[position+1:] {
+				if value := region.Matrix[i][j]; value != nil {
+					values = append(values, *value)
+					bloc.MinCorrelation = math.Min(bloc.MinCorrelation, *value)
+				}
+			}
+		}
+		bloc.MeanCorrelation = mean(values)
+		blocs = append(blocs, bloc)
+	}
+	sort.Slice(blocs, func(i, j int) bool {
+		if len(blocs[i].Members) != len(blocs[j].Members) {
+			return len(blocs[i].Members) > len(blocs[j].Members)
+		}
+		if blocs[i].MeanCorrelation != blocs[j].MeanCorrelation {
+			return blocs[i].MeanCorrelation > blocs[j].MeanCorrelation
+		}
+		return blocs[i].Members[0] < blocs[j].Members[0]
+	})
+	return blocs
+}
@@ -0,0 +1,60 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildCorrelationFindsCoMovingBlocsWithinRegions(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter string, shares []float64) {
+		for index, share := range shares {
+			period := []string{"2017", "2018", "2019", "2020", "2021", "2022", "2023", "2024"}[index]
+			for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+				rows = append(rows,
+					observationRow{ReporterISO: reporter, PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD((1 - share) * 50)},
+					observationRow{ReporterISO: reporter, PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(share * 50)},
+				)
+			}
+		}
+	}
+	// KOR and JPN shift toward and away from China together, VNM moves
+	// against them, and MNG has too few changes to correlate.
+	add("KOR", []float64{0.40, 0.45, 0.44, 0.50, 0.52, 0.51, 0.56, 0.58})
+	add("JPN", []float64{0.30, 0.34, 0.33, 0.38, 0.41, 0.40, 0.44, 0.47})
+	add("VNM", []float64{0.60, 0.55, 0.57, 0.50, 0.49, 0.51, 0.45, 0.44})
+	add("MNG", []float64{0.80, 0.82, 0.85})
+	add("DEU", []float64{0.20, 0.21, 0.22, 0.23, 0.25, 0.24, 0.26, 0.27})
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+	latest := []latestEntry{
+		{ISO3: "KOR", Name: "Korea", Region: "East Asia"},
+		{ISO3: "JPN", Region: "East Asia"},
+		{ISO3: "VNM", Region: "East Asia"},
+		{ISO3: "MNG", Region: "East Asia"},
+		{ISO3: "DEU", Region: "Europe"},
+	}
+
+	output := buildCorrelation("2026-01-01T00:00:00Z", "WITS", history, latest)
+	if output.Provider != "wits" || output.MinOverlap != correlationMinOverlap || len(output.Regions) != 1 {
+		t.Fatalf("expected only East Asia to have a matrix, got %+v", output)
+	}
+	region := output.Regions[0]
+	if region.Region != "East Asia" || region.PeriodType != model.PeriodYear || region.FirstPeriod != "2018" || region.LastPeriod != "2024" {
+		t.Fatalf("unexpected region window %+v", region)
+	}
+	if len(region.Members) != 3 || region.Members[0].ISO3 != "JPN" || region.Members[1].Name != "Korea" || region.Members[2].ISO3 != "VNM" {
+		t.Fatalf("members = %+v, want JPN, KOR, VNM without MNG", region.Members)
+	}
+	if diagonal := region.Matrix[1][1]; diagonal == nil || math.Abs(*diagonal-1) > 1e-12 || region.Overlaps[0][1] != 7 {
+		t.Fatalf("unexpected diagonal %v or overlap %d", diagonal, region.Overlaps[0][1])
+	}
+	together, against := region.Matrix[0][1], region.Matrix[1][2]
+	if together == nil || *together < correlationBlocMin || against == nil || *against >= 0 || region.Matrix[2][1] != against {
+		t.Fatalf("want JPN/KOR above the bloc threshold and KOR/VNM negative, got %v and %v", together, against)
+	}
+	if len(region.Blocs) != 1 || len(region.Blocs[0].Members) != 2 || region.Blocs[0].Members[0] != "JPN" || region.Blocs[0].MeanCorrelation != *together {
+		t.Fatalf("blocs = %+v, want the JPN-KOR pair", region.Blocs)
+	}
+}
@@ -0,0 +1,137 @@
+package publisher
+
+import (
+	"sort"
+	"strings"
+)
+
+type countryIndexFile struct {
+	SchemaVersion string             `json:"schema_version"`
+	GeneratedAt   string             `json:"generated_at"`
+	Provider      string             `json:"provider"`
+	Partners      []string           `json:"partners"`
+	Reporters     []string           `json:"reporters"`
+	Partitions    []countryPartition `json:"partitions"`
+}
+
+type countryPartition struct {
+	ReporterISO3 string `json:"reporter_iso3"`
+	Name         string `json:"name,omitempty"`
+	NameKO       string `json:"name_ko,omitempty"`
+	Href         string `json:"href"`
+	PointCount   int    `json:"point_count"`
+}
+
+// countryFile is the detail-page payload for one reporter: its latest
+// snapshot with context, and every stored period with growth against the
+// same period a year earlier and the rolling correlation of that export
+// growth between the USA and China.
+type countryFile struct {
+	SchemaVersion string       `json:"schema_version"`
+	GeneratedAt   string       `json:"generated_at"`
+	Provider      string       `json:"provider"`
+	Partners      []string     `json:"partners"`
+	ReporterISO3  string       `json:"reporter_iso3"`
+	Name          string       `json:"name,omitempty"`
+	NameKO        string       `json:"name_ko,omitempty"`
+	Region        string       `json:"region,omitempty"`
+	Subregion     string       `json:"subregion,omitempty"`
+	IncomeGroup   string       `json:"income_group,omitempty"`
+	Latest        *latestEntry `json:"latest,omitempty"`
+	// ExportRelationship classifies the latest rolling export correlation.
+	ExportRelationship *exportRelationship `json:"export_relationship,omitempty"`
+	Points             []countryPoint      `json:"points"`
+}
+
+type countryPoint struct {
+	seriesPoint
+	PrevPeriod    string       `json:"prev_period,omitempty"`
+	USAGrowth     *growthBlock `json:"usa_growth,omitempty"`
+	CHNGrowth     *growthBlock `json:"chn_growth,omitempty"`
+	ShareCNChange *float64     `json:"share_cn_change,omitempty"`
+	// ExportCorrelation is the rolling correlation of USA and CHN export
+	// growth over the window ending at this point.
+	ExportCorrelation *float64 `json:"export_correlation,omitempty"`
+}
+
+func buildCountryFiles(generatedAt, provider string, partners []string, history seriesFile, latest []latestEntry) (countryIndexFile, map[string]countryFile) {
+	latestByISO := make(map[string]latestEntry, len(latest))
+	for _, row := range latest {
+		latestByISO[row.ISO3] = row
+	}
+	index := countryIndexFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Partners:      append([]string(nil), partners...),
+		Reporters:     []string{},
+		Partitions:    []countryPartition{},
+	}
+	files := make(map[string]countryFile, len(history.Rows))
+	for _, row := range history.Rows {
+		byPeriod := make(map[string]seriesPoint, len(row.Points))
+		for _, point := range row.Points {
+			byPeriod[seriesKey(point.PeriodType, point.Period)] = point
+		}
+		file := countryFile{
+			SchemaVersion: schemaVersion,
+			GeneratedAt:   generatedAt,
+			Provider:      index.Provider,
+			Partners:      index.Partners,
+			ReporterISO3:  row.ISO3,
+			Points:        make([]countryPoint, 0, len(row.Points)),
+		}
+		if entry, ok := latestByISO[row.ISO3]; ok {
+			file.Latest = &entry
+			file.Name, file.NameKO, file.Region = entry.Name, entry.NameKO, entry.Region
+			file.Subregion, file.IncomeGroup = entry.Subregion, entry.IncomeGroup
+		}
+		for _, point := range row.Points {
+			output := countryPoint{seriesPoint: point}
+			prev := prevPeriod(point.PeriodType, point.Period)
+			if previous, ok := byPeriod[seriesKey(point.PeriodType, prev)]; ok {
+				output.PrevPeriod = prev
+				output.USAGrowth = blockGrowth(point.USA, previous.USA)
+				output.CHNGrowth = blockGrowth(point.CHN, previous.CHN)
+				if point.Comparable && previous.Comparable && point.Total > 0 && previous.Total > 0 {
+					change := point.ShareCN - previous.ShareCN
+					output.ShareCNChange = &change
+				}
+			}
+			file.Points = append(file.Points, output)
+		}
+		applyExportCorrelation(&file)
+		files[row.ISO3] = file
+
+		relativePath := row.ISO3 + ".json"
+		partition := countryPartition{ReporterISO3: row.ISO3, Href: "./" + relativePath, PointCount: len(file.Points)}
+		partition.Name, partition.NameKO = file.Name, file.NameKO
+		index.Reporters = append(index.Reporters, row.ISO3)
+		index.Partitions = append(index.Partitions, partition)
+	}
+	sort.Strings(index.Reporters)
+	sort.Slice(index.Partitions, func(i, j int) bool { return index.Partitions[i].ReporterISO3 < index.Partitions[j].ReporterISO3 })
+	return index, files
+}
+
+func blockGrowth(current, previous seriesBlock) *growthBlock {
+	if !current.Available || !previous.Available {
+		return nil
+	}
+	growth := &growthBlock{
+		Export: growthForValue(current.Export, previous.Export, true, true),
+		Import: growthForValue(current.Import, previous.Import, true, true),
+		Trade:  growthForValue(current.Trade, previous.Trade, true, true),
+	}
+	if growth.Export == nil && growth.Import == nil && growth.Trade == nil {
+		return nil
+	}
+	return growth
+}
+
+func augmentCountryMeta(meta *metaFile, index countryIndexFile) {
+	if meta == nil {
+		return
+	}
+	meta.CountryFileCount = len(index.Partitions)
+}
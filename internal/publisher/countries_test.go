@@ -0,0 +1,37 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildCountryFilesAddsYearOverYearGrowth(t *testing.T) {
+	rows := []observationRow{
+		{ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2022", Value: 10000},
+		{ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2022", Value: 10000},
+		{ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 15000},
+		{ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 5000},
+	}
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+	latest := []latestEntry{{ISO3: "KOR", Name: "Korea"}}
+
+	index, files := buildCountryFiles("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, history, latest)
+	if len(index.Partitions) != 1 || index.Partitions[0].Href != "./KOR.json" || index.Partitions[0].Name != "Korea" || index.Partitions[0].PointCount != 2 {
+		t.Fatalf("unexpected country index: %+v", index)
+	}
+	file := files["KOR"]
+	if file.Latest == nil || file.Latest.Name != "Korea" || len(file.Points) != 2 {
+		t.Fatalf("unexpected country file: %+v", file)
+	}
+	if file.Points[0].USAGrowth != nil || file.Points[0].PrevPeriod != "" {
+		t.Fatalf("first point should have no growth: %+v", file.Points[0])
+	}
+	current := file.Points[1]
+	if current.PrevPeriod != "2022" || current.USAGrowth == nil || *current.USAGrowth.Export != 0.5 || *current.CHNGrowth.Trade != -0.5 {
+		t.Fatalf("unexpected growth: %+v", current)
+	}
+	if current.ShareCNChange == nil || *current.ShareCNChange != -0.25 {
+		t.Fatalf("unexpected share change: %v", current.ShareCNChange)
+	}
+}
@@ -0,0 +1,259 @@
+package publisher
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"tradegravity/internal/model"
+)
+
+type coverageFile struct {
+	SchemaVersion string             `json:"schema_version"`
+	GeneratedAt   string             `json:"generated_at"`
+	Provider      string             `json:"provider"`
+	Partners      []string           `json:"partners"`
+	Reporters     []reporterCoverage `json:"reporters"`
+}
+
+// reporterCoverage says how current each reporter's data is. DataAsOf is the
+// latest period with any tracked-partner observation, and MaxStalenessDays is
+// the oldest flow's staleness, so one number flags a lagging partner or flow.
+type reporterCoverage struct {
+	ISO3             string                     `json:"iso3"`
+	Name             string                     `json:"name,omitempty"`
+	Providers        []string                   `json:"providers"`
+	DataAsOf         string                     `json:"data_as_of,omitempty"`
+	MaxStalenessDays *int                       `json:"max_staleness_days,omitempty"`
+	Partners         map[string]partnerCoverage `json:"partners"`
+}
+
+type partnerCoverage struct {
+	Flows           map[model.Flow]flowCoverage `json:"flows"`
+	GrowthAvailable bool                        `json:"growth_available"`
+	GrowthBasis     string                      `json:"growth_basis,omitempty"`
+}
+
+// flowCoverage is the latest stored period for one flow. StalenessDays counts
+// whole days from the end of that period to generated_at, and Quality grades
+// the flow's series of that period type.
+type flowCoverage struct {
+	PeriodType    model.PeriodType `json:"period_type"`
+	Period        string           `json:"period"`
+	StalenessDays *int             `json:"staleness_days,omitempty"`
+	Quality       *seriesQuality   `json:"quality,omitempty"`
+}
+
+// seriesQuality is the collector's grade of one series, from
+// series_quality. Completeness is the share of periods stored between the
+// series' first and last, RevisionRate the share of stored periods revised
+// since, and DiscrepancyPct the median absolute WITS/Comtrade discrepancy.
+type seriesQuality struct {
+	Grade          string   `json:"grade"`
+	Periods        int      `json:"periods"`
+	Completeness   float64  `json:"completeness"`
+	RevisionRate   float64  `json:"revision_rate"`
+	DiscrepancyPct *float64 `json:"discrepancy_pct,omitempty"`
+}
+
+type seriesQualityKey struct {
+	Reporter   string
+	Partner    string
+	Flow       model.Flow
+	PeriodType model.PeriodType
+}
+
+// loadSeriesQuality reads the grades the collector persisted. A store
+// without the table has none.
+func loadSeriesQuality(dbPath string) (map[seriesQualityKey]seriesQuality, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	columns, err := sqliteTableColumns(db, "series_quality")
+	if err != nil || len(columns) == 0 {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT reporter_iso3, partner_iso3, flow, period_type, periods, span, revised_periods, discrepancy_pct, grade FROM series_quality`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	grades := make(map[seriesQualityKey]seriesQuality)
+	for rows.Next() {
+		var key seriesQualityKey
+		var quality model.SeriesQuality
+		var discrepancy sql.NullFloat64
+		var grade string
+		if err := rows.Scan(&key.Reporter, &key.Partner, &key.Flow, &key.PeriodType, &quality.Periods, &quality.Span, &quality.RevisedPeriods, &discrepancy, &grade); err != nil {
+			return nil, err
+		}
+		key.Reporter, key.Partner = strings.ToUpper(key.Reporter), strings.ToUpper(key.Partner)
+		entry := seriesQuality{Grade: grade, Periods: quality.Periods, Completeness: quality.Completeness(), RevisionRate: quality.RevisionRate()}
+		if discrepancy.Valid {
+			entry.DiscrepancyPct = &discrepancy.Float64
+		}
+		grades[key] = entry
+	}
+	return grades, rows.Err()
+}
+
+// buildCoverage reports, per reporter, the providers that supplied totals or
+// products, the latest period and series grade for every tracked partner and
+// flow, and whether the latest block has growth.
+func buildCoverage(generatedAt, provider string, partners []string, latest []latestEntry, rows, productRows []observationRow, grades map[seriesQualityKey]seriesQuality) coverageFile {
+	fold := newCoverageFold()
+	fold.add(rows)
+	fold.addProviders(productRows)
+	return fold.file(generatedAt, provider, partners, latest, grades)
+}
+
+// coverageFold keeps the providers and latest period per flow that coverage
+// reads, so a build can stream observations through it.
+type coverageFold struct {
+	providers map[string]map[string]bool
+	flows     map[string]map[string]map[model.Flow]periodRef
+}
+
+func newCoverageFold() *coverageFold {
+	return &coverageFold{
+		providers: make(map[string]map[string]bool),
+		flows:     make(map[string]map[string]map[model.Flow]periodRef),
+	}
+}
+
+// add folds total-trade observations into the latest period per flow.
+func (c *coverageFold) add(rows []observationRow) {
+	for _, row := range rows {
+		reporter := strings.ToUpper(row.ReporterISO)
+		partner := strings.ToUpper(row.PartnerISO)
+		c.addProvider(reporter, row.Provider)
+		if c.flows[reporter] == nil {
+			c.flows[reporter] = make(map[string]map[model.Flow]periodRef)
+		}
+		if c.flows[reporter][partner] == nil {
+			c.flows[reporter][partner] = make(map[model.Flow]periodRef)
+		}
+		current, ok := c.flows[reporter][partner][row.Flow]
+		if !ok || comparePeriods(row.PeriodType, row.Period, current.PeriodType, current.Period) > 0 {
+			c.flows[reporter][partner][row.Flow] = periodRef{PeriodType: row.PeriodType, Period: row.Period}
+		}
+	}
+}
+
+// addProviders records the providers of product observations, which count
+// toward a reporter's providers but not its flows.
+func (c *coverageFold) addProviders(rows []observationRow) {
+	for _, row := range rows {
+		c.addProvider(strings.ToUpper(row.ReporterISO), row.Provider)
+	}
+}
+
+func (c *coverageFold) addProvider(reporter, name string) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return
+	}
+	if c.providers[reporter] == nil {
+		c.providers[reporter] = make(map[string]bool)
+	}
+	c.providers[reporter][name] = true
+}
+
+func (c *coverageFold) file(generatedAt, provider string, partners []string, latest []latestEntry, grades map[seriesQualityKey]seriesQuality) coverageFile {
+	providers, flows := c.providers, c.flows
+	output := coverageFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Partners:      append([]string(nil), partners...),
+		Reporters:     []reporterCoverage{},
+	}
+	now, err := time.Parse(time.RFC3339, generatedAt)
+	hasNow := err == nil
+
+	for _, entry := range latest {
+		coverage := reporterCoverage{
+			ISO3:      entry.ISO3,
+			Name:      entry.Name,
+			Providers: []string{},
+			Partners:  make(map[string]partnerCoverage, len(partners)),
+		}
+		for name := range providers[entry.ISO3] {
+			coverage.Providers = append(coverage.Providers, name)
+		}
+		sort.Strings(coverage.Providers)
+
+		var asOf periodRef
+		for _, partner := range partners {
+			partnerFlows := flows[entry.ISO3][partner]
+			if len(partnerFlows) == 0 {
+				continue
+			}
+			item := partnerCoverage{Flows: make(map[model.Flow]flowCoverage, len(partnerFlows))}
+			for flow, ref := range partnerFlows {
+				flowItem := flowCoverage{PeriodType: ref.PeriodType, Period: ref.Period}
+				if grade, ok := grades[seriesQualityKey{Reporter: entry.ISO3, Partner: partner, Flow: flow, PeriodType: ref.PeriodType}]; ok {
+					flowItem.Quality = &grade
+				}
+				if end, ok := periodEnd(ref.PeriodType, ref.Period); ok && hasNow {
+					days := max(0, int(now.Sub(end).Hours()/24))
+					flowItem.StalenessDays = &days
+					if coverage.MaxStalenessDays == nil || days > *coverage.MaxStalenessDays {
+						coverage.MaxStalenessDays = &days
+					}
+				}
+				item.Flows[flow] = flowItem
+				if asOf.Period == "" || comparePeriods(ref.PeriodType, ref.Period, asOf.PeriodType, asOf.Period) > 0 {
+					asOf = ref
+				}
+			}
+			if block, ok := entry.Partners[partner]; ok && block.Growth != nil && block.Growth.Trade != nil {
+				item.GrowthAvailable = true
+				item.GrowthBasis = block.GrowthBasis
+			}
+			coverage.Partners[partner] = item
+		}
+		if asOf.Period != "" {
+			coverage.DataAsOf = string(asOf.PeriodType) + ":" + asOf.Period
+		}
+		output.Reporters = append(output.Reporters, coverage)
+	}
+	return output
+}
+
+// periodEnd returns the first instant after period, so a period that ended
+// yesterday is one day stale.
+func periodEnd(periodType model.PeriodType, period string) (time.Time, bool) {
+	switch periodType {
+	case model.PeriodYear:
+		year, ok := model.ParseYear(period)
+		if !ok {
+			return time.Time{}, false
+		}
+		return time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC), true
+	case model.PeriodQuarter:
+		year, quarter, ok := model.ParseYearQuarter(period)
+		if !ok {
+			return time.Time{}, false
+		}
+		return time.Date(year, time.Month(quarter*3+1), 1, 0, 0, 0, 0, time.UTC), true
+	case model.PeriodMonth:
+		year, month, ok := model.ParseYearMonth(period)
+		if !ok {
+			return time.Time{}, false
+		}
+		return time.Date(year, time.Month(month+1), 1, 0, 0, 0, 0, time.UTC), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func augmentCoverageMeta(meta *metaFile, coverage coverageFile) {
+	if meta == nil {
+		return
+	}
+	meta.CoverageReporterCount = len(coverage.Reporters)
+}
@@ -0,0 +1,62 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildCoverageReportsLatestPeriodsAndStaleness(t *testing.T) {
+	rows := []observationRow{
+		{Provider: "wits", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2022", Value: 1000},
+		{Provider: "wits", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 1200},
+		{Provider: "wits", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2022", Value: 600},
+		{Provider: "wits", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 800},
+		{Provider: "wits", ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodMonth, Period: "2024-03", Value: 500},
+	}
+	productRows := []observationRow{{Provider: "Comtrade", ReporterISO: "KOR", PartnerISO: "USA"}}
+	latest := buildLatest(rows, []string{"USA", "CHN"}, growthYoY, alignLatest, mixedAllow)
+
+	discrepancy := 4.0
+	grades := map[seriesQualityKey]seriesQuality{
+		{Reporter: "KOR", Partner: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear}:  {Grade: model.GradeB, Periods: 2, Completeness: 1, DiscrepancyPct: &discrepancy},
+		{Reporter: "KOR", Partner: "USA", Flow: model.FlowExport, PeriodType: model.PeriodMonth}: {Grade: model.GradeD, Periods: 1, Completeness: 1, RevisionRate: 1},
+	}
+	coverage := buildCoverage("2024-04-11T00:00:00Z", "wits", []string{"USA", "CHN"}, latest, rows, productRows, grades)
+	if len(coverage.Reporters) != 1 {
+		t.Fatalf("coverage reporters = %+v", coverage.Reporters)
+	}
+	kor := coverage.Reporters[0]
+	if len(kor.Providers) != 2 || kor.Providers[0] != "comtrade" || kor.Providers[1] != "wits" {
+		t.Fatalf("providers = %v", kor.Providers)
+	}
+	if kor.DataAsOf != "M:2024-03" {
+		t.Fatalf("data_as_of = %q", kor.DataAsOf)
+	}
+	chnImport := kor.Partners["CHN"].Flows[model.FlowImport]
+	if chnImport.StalenessDays == nil || *chnImport.StalenessDays != 10 {
+		t.Fatalf("CHN import staleness = %v", chnImport.StalenessDays)
+	}
+	if kor.MaxStalenessDays == nil || *kor.MaxStalenessDays != 101 {
+		t.Fatalf("max staleness = %v", kor.MaxStalenessDays)
+	}
+	if usa := kor.Partners["USA"]; !usa.GrowthAvailable || usa.GrowthBasis != growthYoY || usa.Flows[model.FlowExport].Period != "2023" {
+		t.Fatalf("USA coverage = %+v", usa)
+	}
+	if quality := kor.Partners["USA"].Flows[model.FlowExport].Quality; quality == nil || quality.Grade != model.GradeB || *quality.DiscrepancyPct != 4 {
+		t.Fatalf("USA export quality = %+v, want the annual series grade", quality)
+	}
+	if quality := kor.Partners["USA"].Flows[model.FlowImport].Quality; quality != nil {
+		t.Fatalf("USA import quality = %+v, want none without a stored grade", quality)
+	}
+	if kor.Partners["CHN"].GrowthAvailable {
+		t.Fatal("CHN growth reported without a prior period")
+	}
+}
+
+func TestPeriodEndRollsOverYear(t *testing.T) {
+	end, ok := periodEnd(model.PeriodQuarter, "2023-Q4")
+	if !ok || end.Year() != 2024 || end.Month() != 1 || end.Day() != 1 {
+		t.Fatalf("periodEnd(2023-Q4) = %v, %v", end, ok)
+	}
+}
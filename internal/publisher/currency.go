@@ -0,0 +1,168 @@
+package publisher
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// currencyValues converts one latest row's partner blocks out of USD.
+// Each block records the rate it used and the rate's period, which can be
+// earlier than the block period when a newer rate is not yet published.
+// Partners holds every converted block, keyed like the row's Partners, and
+// USA and CHN mirror their entries. Total is set only when both USA and CHN
+// were converted.
+type currencyValues struct {
+	USA      *convertedBlock            `json:"usa,omitempty"`
+	CHN      *convertedBlock            `json:"chn,omitempty"`
+	Total    *float64                   `json:"total,omitempty"`
+	Partners map[string]*convertedBlock `json:"partners,omitempty"`
+}
+
+type convertedBlock struct {
+	Rate       float64 `json:"rate"`
+	RatePeriod string  `json:"rate_period"`
+	Export     float64 `json:"export"`
+	Import     float64 `json:"import"`
+	Trade      float64 `json:"trade"`
+}
+
+// parseCurrencies returns the non-USD currencies in value. USD is always
+// published and needs no rate.
+func parseCurrencies(value string) ([]string, error) {
+	var currencies []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		currency := strings.ToUpper(strings.TrimSpace(item))
+		if currency == "" || currency == "USD" || seen[currency] {
+			continue
+		}
+		if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return nil, fmt.Errorf("invalid currency code %q", item)
+		}
+		seen[currency] = true
+		currencies = append(currencies, currency)
+	}
+	return currencies, nil
+}
+
+// loadFXRates reads the fx_rates table for currencies, keyed by currency. A
+// database without the table yields no rates.
+func loadFXRates(dbPath string, currencies []string) (map[string][]model.FXRate, error) {
+	rates := make(map[string][]model.FXRate)
+	if len(currencies) == 0 {
+		return rates, nil
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	columns, err := sqliteTableColumns(db, "fx_rates")
+	if err != nil || len(columns) == 0 {
+		return rates, err
+	}
+	wanted := make(map[string]bool, len(currencies))
+	for _, currency := range currencies {
+		wanted[currency] = true
+	}
+	rows, err := db.Query(`SELECT currency, period_type, period, rate_per_usd, source FROM fx_rates`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rate model.FXRate
+		if err := rows.Scan(&rate.Currency, &rate.PeriodType, &rate.Period, &rate.RatePerUSD, &rate.Source); err != nil {
+			return nil, err
+		}
+		if wanted[rate.Currency] && rate.RatePerUSD > 0 {
+			rates[rate.Currency] = append(rates[rate.Currency], rate)
+		}
+	}
+	return rates, rows.Err()
+}
+
+// applyCurrencies adds converted values for every currency to each row.
+func applyCurrencies(rows []latestEntry, currencies []string, rates map[string][]model.FXRate) error {
+	for _, currency := range currencies {
+		if len(rates[currency]) == 0 {
+			return fmt.Errorf("no fx_rates for %s (run the collector fx command)", currency)
+		}
+	}
+	for index := range rows {
+		row := &rows[index]
+		for _, currency := range currencies {
+			values := currencyValues{
+				USA: convertBlock(row.USA, rates[currency]),
+				CHN: convertBlock(row.CHN, rates[currency]),
+			}
+			for partner, block := range row.Partners {
+				converted := convertBlock(block, rates[currency])
+				if converted == nil {
+					continue
+				}
+				if values.Partners == nil {
+					values.Partners = make(map[string]*convertedBlock, len(row.Partners))
+				}
+				values.Partners[partner] = converted
+			}
+			if values.USA == nil && values.CHN == nil && values.Partners == nil {
+				continue
+			}
+			if values.USA != nil && values.CHN != nil && !row.Incomparable {
+				total := roundCents(values.USA.Trade + values.CHN.Trade)
+				values.Total = &total
+			}
+			if row.Currencies == nil {
+				row.Currencies = make(map[string]currencyValues, len(currencies))
+			}
+			row.Currencies[currency] = values
+		}
+	}
+	return nil
+}
+
+func convertBlock(block partnerBlock, rates []model.FXRate) *convertedBlock {
+	if block.Period == "" {
+		return nil
+	}
+	rate, ok := rateFor(block.PeriodType, block.Period, rates)
+	if !ok {
+		return nil
+	}
+	return &convertedBlock{
+		Rate:       rate.RatePerUSD,
+		RatePeriod: rate.Period,
+		Export:     roundCents(roundCents(block.Export) * rate.RatePerUSD),
+		Import:     roundCents(roundCents(block.Import) * rate.RatePerUSD),
+		Trade:      roundCents(roundCents(block.Trade) * rate.RatePerUSD),
+	}
+}
+
+// rateFor picks the rate for exactly the block's period when there is one,
+// otherwise the annual rate for the block's year, otherwise the latest annual
+// rate before it. Rates after the block's year are never used.
+func rateFor(periodType model.PeriodType, period string, rates []model.FXRate) (model.FXRate, bool) {
+	year := yearForPeriod(periodType, period)
+	if year == 0 {
+		return model.FXRate{}, false
+	}
+	annual := make([]model.FXRate, 0, len(rates))
+	for _, rate := range rates {
+		if rate.PeriodType == periodType && rate.Period == period {
+			return rate, true
+		}
+		if rateYear, ok := model.ParseYear(rate.Period); ok && rate.PeriodType == model.PeriodYear && rateYear <= year {
+			annual = append(annual, rate)
+		}
+	}
+	if len(annual) == 0 {
+		return model.FXRate{}, false
+	}
+	sort.Slice(annual, func(i, j int) bool { return annual[i].Period > annual[j].Period })
+	return annual[0], true
+}
@@ -0,0 +1,67 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestApplyCurrenciesRecordsRatePerBlock(t *testing.T) {
+	rates := map[string][]model.FXRate{"KRW": {
+		{Currency: "KRW", PeriodType: model.PeriodYear, Period: "2022", RatePerUSD: 1290},
+		{Currency: "KRW", PeriodType: model.PeriodYear, Period: "2023", RatePerUSD: 1300},
+		{Currency: "KRW", PeriodType: model.PeriodYear, Period: "2025", RatePerUSD: 1400},
+		{Currency: "KRW", PeriodType: model.PeriodMonth, Period: "2024-05", RatePerUSD: 1360},
+	}}
+	rows := []latestEntry{
+		{
+			ISO3: "KOR",
+			USA:  partnerBlock{PeriodType: model.PeriodYear, Period: "2024", Export: 1, Import: 1, Trade: 2},
+			CHN:  partnerBlock{PeriodType: model.PeriodMonth, Period: "2024-05", Export: 2, Import: 1, Trade: 3},
+			Partners: map[string]partnerBlock{
+				"USA": {PeriodType: model.PeriodYear, Period: "2024", Export: 1, Import: 1, Trade: 2},
+				"CHN": {PeriodType: model.PeriodMonth, Period: "2024-05", Export: 2, Import: 1, Trade: 3},
+				"JPN": {PeriodType: model.PeriodYear, Period: "2022", Export: 1, Trade: 1},
+				"VNM": {PeriodType: model.PeriodYear, Period: "2020", Export: 1, Trade: 1},
+			},
+		},
+		{ISO3: "JPN", USA: partnerBlock{PeriodType: model.PeriodYear, Period: "2021", Trade: 5}},
+	}
+
+	if err := applyCurrencies(rows, []string{"KRW"}, rates); err != nil {
+		t.Fatalf("applyCurrencies() error = %v", err)
+	}
+	krw := rows[0].Currencies["KRW"]
+	if krw.USA == nil || krw.USA.Rate != 1300 || krw.USA.RatePeriod != "2023" || krw.USA.Trade != 2600 {
+		t.Fatalf("USA = %+v, want the 2023 annual rate for a 2024 block", krw.USA)
+	}
+	if krw.CHN == nil || krw.CHN.Rate != 1360 || krw.CHN.RatePeriod != "2024-05" || krw.CHN.Export != 2720 {
+		t.Fatalf("CHN = %+v, want the exact monthly rate", krw.CHN)
+	}
+	if krw.Total == nil || *krw.Total != 2600+4080 {
+		t.Fatalf("total = %v, want converted USA+CHN trade", krw.Total)
+	}
+	if len(krw.Partners) != 3 || krw.Partners["CHN"].Export != 2720 || krw.Partners["JPN"].RatePeriod != "2022" || krw.Partners["JPN"].Trade != 1290 {
+		t.Fatalf("partners = %+v, want every block with a rate converted", krw.Partners)
+	}
+	if _, ok := krw.Partners["VNM"]; ok {
+		t.Fatalf("VNM = %+v, want no conversion without a rate at or before 2020", krw.Partners["VNM"])
+	}
+	if _, ok := rows[1].Currencies["KRW"]; ok {
+		t.Fatalf("JPN = %+v, want no conversion without a rate at or before 2021", rows[1].Currencies)
+	}
+
+	if err := applyCurrencies(rows, []string{"EUR"}, rates); err == nil {
+		t.Fatal("applyCurrencies(EUR) error = nil, want missing rates")
+	}
+}
+
+func TestParseCurrenciesDropsUSD(t *testing.T) {
+	currencies, err := parseCurrencies("usd, krw,EUR,KRW")
+	if err != nil || len(currencies) != 2 || currencies[0] != "KRW" || currencies[1] != "EUR" {
+		t.Fatalf("parseCurrencies() = %v, %v, want [KRW EUR]", currencies, err)
+	}
+	if _, err := parseCurrencies("USD,WON1"); err == nil {
+		t.Fatal("parseCurrencies() accepted an invalid code")
+	}
+}
@@ -0,0 +1,247 @@
+package publisher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// Row changes reported in diff.json. A row can carry several.
+const (
+	diffAdded          = "added"
+	diffRemoved        = "removed"
+	diffPeriodAdvanced = "period_advanced"
+	diffPeriodReverted = "period_reverted"
+	diffValueChanged   = "value_changed"
+)
+
+type publishDiffFile struct {
+	SchemaVersion       string             `json:"schema_version"`
+	GeneratedAt         string             `json:"generated_at"`
+	PreviousGeneratedAt string             `json:"previous_generated_at,omitempty"`
+	Status              string             `json:"status"`
+	Summary             publishDiffSummary `json:"summary"`
+	Rows                []publishDiffRow   `json:"rows"`
+}
+
+type publishDiffSummary struct {
+	AddedRows      int `json:"added_rows"`
+	RemovedRows    int `json:"removed_rows"`
+	PeriodAdvanced int `json:"period_advanced"`
+	PeriodReverted int `json:"period_reverted"`
+	ValueChanged   int `json:"value_changed"`
+	UnchangedRows  int `json:"unchanged_rows"`
+}
+
+// publishDiffRow lists one reporter whose latest row differs from the
+// previous publish. Blocks and shares are nil on the side where the row is
+// missing.
+type publishDiffRow struct {
+	ISO3            string           `json:"iso3"`
+	Changes         []string         `json:"changes"`
+	USA             *publishDiffPair `json:"usa,omitempty"`
+	CHN             *publishDiffPair `json:"chn,omitempty"`
+	PreviousShareCN *float64         `json:"previous_share_cn,omitempty"`
+	CurrentShareCN  *float64         `json:"current_share_cn,omitempty"`
+}
+
+type publishDiffPair struct {
+	PreviousPeriod string   `json:"previous_period,omitempty"`
+	CurrentPeriod  string   `json:"current_period,omitempty"`
+	PreviousTrade  *float64 `json:"previous_trade,omitempty"`
+	CurrentTrade   *float64 `json:"current_trade,omitempty"`
+}
+
+// previousLatestFile reads only the latest.json fields compared here, so a
+// previous publish in either schema version can be diffed.
+type previousLatestFile struct {
+	SchemaVersion string              `json:"schema_version"`
+	GeneratedAt   string              `json:"generated_at"`
+	Rows          []previousLatestRow `json:"rows"`
+}
+
+type previousLatestRow struct {
+	ISO3    string               `json:"iso3"`
+	USA     previousPartnerBlock `json:"usa"`
+	CHN     previousPartnerBlock `json:"chn"`
+	ShareCN float64              `json:"share_cn"`
+}
+
+type previousPartnerBlock struct {
+	Period     string           `json:"period"`
+	PeriodType model.PeriodType `json:"period_type"`
+	Trade      float64          `json:"trade"`
+}
+
+// buildPublishDiff compares the current latest rows with latest.json in
+// previousDir. A missing previous file yields a "baseline" diff.
+func buildPublishDiff(generatedAt, previousDir string, latest []latestEntry) (publishDiffFile, error) {
+	result := publishDiffFile{
+		SchemaVersion: "1.0",
+		GeneratedAt:   generatedAt,
+		Status:        "baseline",
+		Rows:          []publishDiffRow{},
+	}
+	if strings.TrimSpace(previousDir) == "" {
+		return result, nil
+	}
+	previous, found, err := loadPreviousLatest(previousDir)
+	if err != nil {
+		return publishDiffFile{}, err
+	}
+	if !found {
+		return result, nil
+	}
+	result.PreviousGeneratedAt = previous.GeneratedAt
+	diffLatestRows(&result, previous, latest)
+	return result, nil
+}
+
+// diffLatestRows adds a row to result for every reporter whose latest row
+// differs between previous and latest, and sets the summary and status.
+func diffLatestRows(result *publishDiffFile, previous previousLatestFile, latest []latestEntry) {
+	previousRows := make(map[string]int, len(previous.Rows))
+	for index, row := range previous.Rows {
+		previousRows[strings.ToUpper(row.ISO3)] = index
+	}
+	currentRows := make(map[string]bool, len(latest))
+	for _, row := range latest {
+		currentRows[row.ISO3] = true
+		currentShare := row.ShareCN
+		index, ok := previousRows[row.ISO3]
+		if !ok {
+			result.Summary.AddedRows++
+			result.Rows = append(result.Rows, publishDiffRow{
+				ISO3:           row.ISO3,
+				Changes:        []string{diffAdded},
+				USA:            &publishDiffPair{CurrentPeriod: row.USA.Period, CurrentTrade: floatPointer(row.USA.Trade)},
+				CHN:            &publishDiffPair{CurrentPeriod: row.CHN.Period, CurrentTrade: floatPointer(row.CHN.Trade)},
+				CurrentShareCN: &currentShare,
+			})
+			continue
+		}
+		old := previous.Rows[index]
+		diff := publishDiffRow{ISO3: row.ISO3}
+		for _, change := range []string{blockChange(old.USA, row.USA), blockChange(old.CHN, row.CHN)} {
+			if change != "" && !slices.Contains(diff.Changes, change) {
+				diff.Changes = append(diff.Changes, change)
+			}
+		}
+		if !slices.Contains(diff.Changes, diffValueChanged) && !valuesClose(old.ShareCN, row.ShareCN) {
+			diff.Changes = append(diff.Changes, diffValueChanged)
+		}
+		if len(diff.Changes) == 0 {
+			result.Summary.UnchangedRows++
+			continue
+		}
+		for _, change := range diff.Changes {
+			switch change {
+			case diffPeriodAdvanced:
+				result.Summary.PeriodAdvanced++
+			case diffPeriodReverted:
+				result.Summary.PeriodReverted++
+			case diffValueChanged:
+				result.Summary.ValueChanged++
+			}
+		}
+		previousShare := old.ShareCN
+		diff.USA = diffPair(old.USA, row.USA)
+		diff.CHN = diffPair(old.CHN, row.CHN)
+		diff.PreviousShareCN, diff.CurrentShareCN = &previousShare, &currentShare
+		result.Rows = append(result.Rows, diff)
+	}
+	for _, row := range previous.Rows {
+		iso3 := strings.ToUpper(row.ISO3)
+		if currentRows[iso3] {
+			continue
+		}
+		previousShare := row.ShareCN
+		result.Summary.RemovedRows++
+		result.Rows = append(result.Rows, publishDiffRow{
+			ISO3:            iso3,
+			Changes:         []string{diffRemoved},
+			USA:             &publishDiffPair{PreviousPeriod: row.USA.Period, PreviousTrade: floatPointer(row.USA.Trade)},
+			CHN:             &publishDiffPair{PreviousPeriod: row.CHN.Period, PreviousTrade: floatPointer(row.CHN.Trade)},
+			PreviousShareCN: &previousShare,
+		})
+	}
+
+	sort.Slice(result.Rows, func(i, j int) bool {
+		return result.Rows[i].ISO3 < result.Rows[j].ISO3
+	})
+	if len(result.Rows) > 0 {
+		result.Status = "changed"
+	} else {
+		result.Status = "unchanged"
+	}
+}
+
+// blockChange classifies one partner block: a later period is an advance, an
+// earlier one a revert, and a different trade value on the same period a value
+// change.
+func blockChange(previous previousPartnerBlock, current partnerBlock) string {
+	if previous.Period == "" && current.Period == "" {
+		return ""
+	}
+	if previous.Period == "" {
+		return diffPeriodAdvanced
+	}
+	if current.Period == "" {
+		return diffPeriodReverted
+	}
+	switch comparePeriods(current.PeriodType, current.Period, previous.PeriodType, previous.Period) {
+	case 1:
+		return diffPeriodAdvanced
+	case -1:
+		return diffPeriodReverted
+	}
+	if !valuesClose(previous.Trade, current.Trade) {
+		return diffValueChanged
+	}
+	return ""
+}
+
+func diffPair(previous previousPartnerBlock, current partnerBlock) *publishDiffPair {
+	pair := &publishDiffPair{PreviousPeriod: previous.Period, CurrentPeriod: current.Period}
+	if previous.Period != "" {
+		pair.PreviousTrade = floatPointer(previous.Trade)
+	}
+	if current.Period != "" {
+		pair.CurrentTrade = floatPointer(current.Trade)
+	}
+	return pair
+}
+
+func valuesClose(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func floatPointer(value float64) *float64 {
+	return &value
+}
+
+func loadPreviousLatest(dataDir string) (previousLatestFile, bool, error) {
+	file, err := os.Open(layoutPath(dataDir, "latest.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return previousLatestFile{}, false, nil
+	}
+	if err != nil {
+		return previousLatestFile{}, false, fmt.Errorf("open previous latest.json: %w", err)
+	}
+	defer file.Close()
+	var previous previousLatestFile
+	if err := json.NewDecoder(file).Decode(&previous); err != nil {
+		return previousLatestFile{}, false, fmt.Errorf("decode previous latest.json: %w", err)
+	}
+	if strings.TrimSpace(previous.GeneratedAt) == "" {
+		return previousLatestFile{}, false, errors.New("previous latest.json has no generated_at")
+	}
+	return previous, true, nil
+}
@@ -0,0 +1,66 @@
+package publisher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildPublishDiffClassifiesRowChanges(t *testing.T) {
+	dir := t.TempDir()
+	previous := `{"schema_version":"1.0","generated_at":"2026-01-01T00:00:00Z","rows":[
+		{"iso3":"DEU","usa":{"period":"2022","period_type":"Y","trade":10},"chn":{"period":"2022","period_type":"Y","trade":10},"share_cn":0.5},
+		{"iso3":"JPN","usa":{"period":"2023","period_type":"Y","trade":10},"chn":{"period":"2023","period_type":"Y","trade":30},"share_cn":0.75},
+		{"iso3":"KOR","usa":{"period":"2023","period_type":"Y","trade":10},"chn":{"period":"2023","period_type":"Y","trade":10},"share_cn":0.5},
+		{"iso3":"BRA","usa":{"period":"2023","period_type":"Y","trade":10},"chn":{"period":"2023","period_type":"Y","trade":10},"share_cn":0.5}
+	]}`
+	if err := os.WriteFile(filepath.Join(dir, "latest.json"), []byte(previous), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	annual := func(period string, trade float64) partnerBlock {
+		return partnerBlock{PeriodType: model.PeriodYear, Period: period, Trade: trade}
+	}
+	latest := []latestEntry{
+		{ISO3: "DEU", USA: annual("2023", 12), CHN: annual("2023", 12), ShareCN: 0.5},
+		{ISO3: "JPN", USA: annual("2023", 10), CHN: annual("2023", 30), ShareCN: 0.75},
+		{ISO3: "KOR", USA: annual("2023", 10), CHN: annual("2023", 30), ShareCN: 0.75},
+		{ISO3: "VNM", USA: annual("2023", 5), CHN: annual("2023", 5), ShareCN: 0.5},
+	}
+
+	diff, err := buildPublishDiff("2026-02-01T00:00:00Z", dir, latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Status != "changed" || diff.PreviousGeneratedAt != "2026-01-01T00:00:00Z" {
+		t.Fatalf("unexpected diff header: %+v", diff)
+	}
+	want := publishDiffSummary{AddedRows: 1, RemovedRows: 1, PeriodAdvanced: 1, ValueChanged: 1, UnchangedRows: 1}
+	if diff.Summary != want {
+		t.Fatalf("summary = %+v, want %+v", diff.Summary, want)
+	}
+	byISO3 := make(map[string]publishDiffRow)
+	for _, row := range diff.Rows {
+		byISO3[row.ISO3] = row
+	}
+	if row := byISO3["DEU"]; len(row.Changes) != 1 || row.Changes[0] != diffPeriodAdvanced || row.USA.PreviousPeriod != "2022" || row.USA.CurrentPeriod != "2023" {
+		t.Fatalf("DEU diff = %+v", row)
+	}
+	if row := byISO3["KOR"]; len(row.Changes) != 1 || row.Changes[0] != diffValueChanged || *row.CHN.PreviousTrade != 10 || *row.CHN.CurrentTrade != 30 {
+		t.Fatalf("KOR diff = %+v", row)
+	}
+	if row := byISO3["BRA"]; row.Changes[0] != diffRemoved || row.CurrentShareCN != nil {
+		t.Fatalf("BRA diff = %+v", row)
+	}
+	if _, ok := byISO3["JPN"]; ok {
+		t.Fatal("unchanged JPN row listed in diff")
+	}
+}
+
+func TestBuildPublishDiffWithoutPreviousIsBaseline(t *testing.T) {
+	diff, err := buildPublishDiff("2026-02-01T00:00:00Z", t.TempDir(), nil)
+	if err != nil || diff.Status != "baseline" || len(diff.Rows) != 0 {
+		t.Fatalf("buildPublishDiff() = %+v, %v", diff, err)
+	}
+}
@@ -0,0 +1,175 @@
+package publisher
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	texttemplate "text/template"
+
+	"tradegravity/internal/mail"
+)
+
+//go:embed templates/digest.txt
+var digestTemplate string
+
+const (
+	// digestPeriodRows and digestMoverRows keep the email short; the full
+	// lists are in diff.json and movers.json.
+	digestPeriodRows = 10
+	digestMoverRows  = 3
+)
+
+// digestData is the email -email-to sends after a build: which reporters
+// reached a newer period, the collection runs that failed since the last
+// publish, and the top movers.
+type digestData struct {
+	GeneratedAt    string
+	Provider       string
+	ReporterCount  int
+	DominantPeriod string
+	HasDiff        bool
+	NewPeriods     []string
+	MorePeriods    int
+	Since          string
+	RunCount       int
+	Problems       []ingestRunRecord
+	Movers         []reportMoverTable
+}
+
+// emailDigest mails the digest of the build in outDir to recipients through
+// the SMTP relay configured in the environment.
+func emailDigest(outDir string, recipients []string) error {
+	cfg, err := mail.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	data, err := buildDigest(outDir)
+	if err != nil {
+		return err
+	}
+	body, err := renderDigest(data)
+	if err != nil {
+		return err
+	}
+	return mail.Send(cfg, recipients, digestSubject(data), body)
+}
+
+func buildDigest(outDir string) (digestData, error) {
+	var metadata metaFile
+	if err := readPublished(outDir, "meta.json", &metadata); err != nil {
+		return digestData{}, err
+	}
+	data := digestData{
+		GeneratedAt:    metadata.GeneratedAt,
+		Provider:       metadata.Provider,
+		ReporterCount:  metadata.ReporterCount,
+		DominantPeriod: metadata.DominantPeriod,
+	}
+	var diff publishDiffFile
+	if err := readPublished(outDir, "diff.json", &diff); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return digestData{}, err
+	}
+	// A first publish has a diff.json with no previous build to compare.
+	data.HasDiff = diff.PreviousGeneratedAt != ""
+	data.Since = diff.PreviousGeneratedAt
+	for _, row := range diff.Rows {
+		if !slices.Contains(row.Changes, diffAdded) && !slices.Contains(row.Changes, diffPeriodAdvanced) {
+			continue
+		}
+		if len(data.NewPeriods) == digestPeriodRows {
+			data.MorePeriods++
+			continue
+		}
+		data.NewPeriods = append(data.NewPeriods, digestPeriodLine(row))
+	}
+
+	var quality qualityFile
+	if err := readPublished(outDir, "quality.json", &quality); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return digestData{}, err
+	}
+	for _, run := range quality.CollectionRuns {
+		// RFC3339 UTC timestamps compare in time order as strings.
+		if data.Since != "" && run.FinishedAt <= data.Since {
+			continue
+		}
+		data.RunCount++
+		if run.Status != "success" {
+			data.Problems = append(data.Problems, run)
+		}
+	}
+
+	var movers moversFile
+	if err := readPublished(outDir, "movers.json", &movers); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return digestData{}, err
+	}
+	if len(movers.Windows) > 0 {
+		// The last_period window only; five-year swings rarely change
+		// between publishes.
+		movers.Windows = movers.Windows[:1]
+	}
+	data.Movers = reportMovers(movers, digestMoverRows)
+	return data, nil
+}
+
+// digestPeriodLine describes one reporter's period change, such as
+// "KOR  USA Y:2023 → Y:2024, CHN Y:2023 → Y:2024".
+func digestPeriodLine(row publishDiffRow) string {
+	var parts []string
+	for _, block := range []struct {
+		partner string
+		pair    *publishDiffPair
+	}{{"USA", row.USA}, {"CHN", row.CHN}} {
+		switch {
+		case block.pair == nil || block.pair.CurrentPeriod == "":
+		case block.pair.PreviousPeriod == "" || block.pair.PreviousPeriod == block.pair.CurrentPeriod:
+			parts = append(parts, block.partner+" "+block.pair.CurrentPeriod)
+		default:
+			parts = append(parts, block.partner+" "+block.pair.PreviousPeriod+" → "+block.pair.CurrentPeriod)
+		}
+	}
+	line := row.ISO3 + "  " + strings.Join(parts, ", ")
+	if slices.Contains(row.Changes, diffAdded) {
+		line += " (new reporter)"
+	}
+	return line
+}
+
+// digestSubject leads with what needs attention: failed runs, then new
+// periods.
+func digestSubject(data digestData) string {
+	subject := "TradeGravity publish " + data.GeneratedAt
+	var notes []string
+	if len(data.Problems) > 0 {
+		notes = append(notes, plural(len(data.Problems), "failed or partial run"))
+	}
+	if periods := len(data.NewPeriods) + data.MorePeriods; periods > 0 {
+		notes = append(notes, plural(periods, "reporter")+" with new periods")
+	}
+	if len(notes) > 0 {
+		subject += ": " + strings.Join(notes, ", ")
+	}
+	return subject
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+func renderDigest(data digestData) (string, error) {
+	page, err := texttemplate.New("digest.txt").Parse(digestTemplate)
+	if err != nil {
+		return "", err
+	}
+	var body bytes.Buffer
+	if err := page.Execute(&body, data); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
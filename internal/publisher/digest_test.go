@@ -0,0 +1,98 @@
+package publisher
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildDigestSummarizesPeriodsRunsAndMovers(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, value any) {
+		t.Helper()
+		if err := writeJSON(filepath.Join(dir, name), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("meta.json", metaFile{GeneratedAt: "2026-07-15T12:00:00Z", Provider: "wits", ReporterCount: 14, DominantPeriod: "Y:2024"})
+	diff := publishDiffFile{GeneratedAt: "2026-07-15T12:00:00Z", PreviousGeneratedAt: "2026-07-08T12:00:00Z", Rows: []publishDiffRow{
+		{ISO3: "KOR", Changes: []string{diffPeriodAdvanced}, USA: &publishDiffPair{PreviousPeriod: "Y:2023", CurrentPeriod: "Y:2024"}, CHN: &publishDiffPair{PreviousPeriod: "Y:2024", CurrentPeriod: "Y:2024"}},
+		{ISO3: "VNM", Changes: []string{diffAdded}, USA: &publishDiffPair{CurrentPeriod: "Y:2024"}},
+		{ISO3: "DEU", Changes: []string{diffValueChanged}},
+	}}
+	for index := range digestPeriodRows {
+		diff.Rows = append(diff.Rows, publishDiffRow{ISO3: fmt.Sprintf("X%02d", index), Changes: []string{diffPeriodAdvanced}})
+	}
+	write("diff.json", diff)
+	write("quality.json", qualityFile{CollectionRuns: []ingestRunRecord{
+		{Provider: "comtrade", Mode: "products-hs2", FinishedAt: "2026-07-14T01:00:00Z", Status: "partial", RequestCount: 40, FailureCount: 3, Errors: []string{"KOR 2024: status 429"}},
+		{Provider: "wits", Mode: "totals", FinishedAt: "2026-07-14T00:30:00Z", Status: "success"},
+		{Provider: "wits", Mode: "totals", FinishedAt: "2026-07-01T00:30:00Z", Status: "failed"},
+	}})
+	write("movers.json", moversFile{Period: "2024", Windows: []moverWindow{
+		{ID: "last_period", BasePeriod: "2023", Lists: []moverList{{ID: "share_cn_abs", Title: "Largest swings in China share (percentage points)", Rows: []moverRow{
+			{Rank: 1, ISO3: "KOR", Name: "Korea", BaseValue: 0.5, Value: 0.53, Change: 0.03},
+			{Rank: 2, ISO3: "JPN", BaseValue: 0.4, Value: 0.39, Change: -0.01},
+			{Rank: 3, ISO3: "DEU", BaseValue: 0.3, Value: 0.3, Change: 0},
+			{Rank: 4, ISO3: "VNM", BaseValue: 0.6, Value: 0.6, Change: 0},
+		}}}},
+		{ID: "five_years", BasePeriod: "2019"},
+	}})
+
+	data, err := buildDigest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.NewPeriods) != digestPeriodRows || data.MorePeriods != 2 {
+		t.Fatalf("new periods = %q, more = %d", data.NewPeriods, data.MorePeriods)
+	}
+	if data.NewPeriods[0] != "KOR  USA Y:2023 → Y:2024, CHN Y:2024" || data.NewPeriods[1] != "VNM  USA Y:2024 (new reporter)" {
+		t.Fatalf("period lines = %q", data.NewPeriods[:2])
+	}
+	if data.RunCount != 2 || len(data.Problems) != 1 || data.Problems[0].Mode != "products-hs2" {
+		t.Fatalf("runs = %d, problems = %+v, want only runs since the previous publish", data.RunCount, data.Problems)
+	}
+	if len(data.Movers) != 1 || len(data.Movers[0].Rows) != digestMoverRows {
+		t.Fatalf("movers = %+v", data.Movers)
+	}
+	if subject := digestSubject(data); subject != "TradeGravity publish 2026-07-15T12:00:00Z: 1 failed or partial run, 12 reporters with new periods" {
+		t.Fatalf("subject = %q", subject)
+	}
+	body, err := renderDigest(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Provider wits · 14 reporters · dominant period Y:2024",
+		"  … and 2 more reporters in diff.json",
+		"COLLECTION RUNS since 2026-07-08T12:00:00Z\n  2 runs, 1 failed or partial",
+		"  partial: comtrade products-hs2 at 2026-07-14T01:00:00Z, 3 of 40 requests failed\n    KOR 2024: status 429",
+		"    1. KOR Korea  50.0% → 53.0% (+3.0 pp)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("digest missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestDigestWithoutPreviousPublishOrMovers(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeJSON(filepath.Join(dir, "meta.json"), metaFile{GeneratedAt: "2026-07-15T12:00:00Z", Provider: "wits"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := buildDigest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := renderDigest(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body, "No previous publish to compare with.") || !strings.Contains(body, "movers.json is not published in this build.") {
+		t.Fatalf("digest = %s", body)
+	}
+	if subject := digestSubject(data); subject != "TradeGravity publish 2026-07-15T12:00:00Z" {
+		t.Fatalf("subject = %q", subject)
+	}
+}
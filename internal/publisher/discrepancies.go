@@ -0,0 +1,180 @@
+package publisher
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// discrepancyFormula documents how discrepancies.json values are derived.
+const discrepancyFormula = "discrepancy_pct = (comtrade - wits) / wits × 100 for each reporter, partner, flow, and period both providers report"
+
+// Trust classes, on the median absolute discrepancy in percent. At or below
+// discrepancyHigh the providers agree closely; above discrepancyMedium a
+// reporter's numbers depend noticeably on the source.
+const (
+	discrepancyHigh   = 2.0
+	discrepancyMedium = 10.0
+)
+
+// discrepancyFile is discrepancies.json: how far WITS and Comtrade totals
+// disagree for each reporter's trade with the tracked partners, summarized
+// as a trust class.
+type discrepancyFile struct {
+	SchemaVersion string                `json:"schema_version"`
+	GeneratedAt   string                `json:"generated_at"`
+	Providers     []string              `json:"providers"`
+	Formula       string                `json:"formula"`
+	Thresholds    map[string]float64    `json:"thresholds"`
+	Summary       discrepancySummary    `json:"summary"`
+	Rows          []reporterDiscrepancy `json:"rows"`
+}
+
+type discrepancySummary struct {
+	Pairs        int      `json:"pairs"`
+	Reporters    int      `json:"reporters"`
+	MedianAbsPct *float64 `json:"median_abs_pct,omitempty"`
+}
+
+// reporterDiscrepancy summarizes one reporter's pairs. Largest is the pair
+// with the largest absolute discrepancy.
+type reporterDiscrepancy struct {
+	ISO3         string          `json:"iso3"`
+	Name         string          `json:"name,omitempty"`
+	Pairs        int             `json:"pairs"`
+	MedianAbsPct float64         `json:"median_abs_pct"`
+	MeanAbsPct   float64         `json:"mean_abs_pct"`
+	Trust        string          `json:"trust"`
+	Largest      discrepancyPair `json:"largest"`
+}
+
+// discrepancyPair is one provider_discrepancies row.
+type discrepancyPair struct {
+	ISO3           string           `json:"-"`
+	Partner        string           `json:"partner"`
+	Flow           model.Flow       `json:"flow"`
+	PeriodType     model.PeriodType `json:"period_type"`
+	Period         string           `json:"period"`
+	WITSUSD        float64          `json:"wits_usd"`
+	ComtradeUSD    float64          `json:"comtrade_usd"`
+	DiscrepancyPct float64          `json:"discrepancy_pct"`
+}
+
+// loadProviderDiscrepancies reads the pairs the collector persisted for
+// partners. A store without the table has none.
+func loadProviderDiscrepancies(dbPath string, partners []string) ([]discrepancyPair, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	columns, err := sqliteTableColumns(db, "provider_discrepancies")
+	if err != nil || len(columns) == 0 {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(partners))
+	for _, partner := range partners {
+		wanted[strings.ToUpper(partner)] = true
+	}
+	rows, err := db.Query(`SELECT reporter_iso3, partner_iso3, flow, period_type, period, wits_value_usd, comtrade_value_usd, discrepancy_pct FROM provider_discrepancies`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var pairs []discrepancyPair
+	for rows.Next() {
+		var pair discrepancyPair
+		if err := rows.Scan(&pair.ISO3, &pair.Partner, &pair.Flow, &pair.PeriodType, &pair.Period, &pair.WITSUSD, &pair.ComtradeUSD, &pair.DiscrepancyPct); err != nil {
+			return nil, err
+		}
+		pair.ISO3, pair.Partner = strings.ToUpper(pair.ISO3), strings.ToUpper(pair.Partner)
+		if wanted[pair.Partner] {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, rows.Err()
+}
+
+// buildDiscrepancies groups pairs by reporter.
+func buildDiscrepancies(generatedAt string, pairs []discrepancyPair, latest []latestEntry) discrepancyFile {
+	output := discrepancyFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Providers:     []string{"wits", "comtrade"},
+		Formula:       discrepancyFormula,
+		Thresholds:    map[string]float64{"high": discrepancyHigh, "medium": discrepancyMedium},
+		Summary:       discrepancySummary{Pairs: len(pairs)},
+		Rows:          []reporterDiscrepancy{},
+	}
+	names := make(map[string]string, len(latest))
+	for _, row := range latest {
+		names[row.ISO3] = row.Name
+	}
+	byReporter := make(map[string][]discrepancyPair)
+	all := make([]float64, 0, len(pairs))
+	for _, pair := range pairs {
+		byReporter[pair.ISO3] = append(byReporter[pair.ISO3], pair)
+		all = append(all, math.Abs(pair.DiscrepancyPct))
+	}
+	if len(all) > 0 {
+		overall := medianOf(all)
+		output.Summary.MedianAbsPct = &overall
+	}
+	for iso3, reporterPairs := range byReporter {
+		sort.Slice(reporterPairs, func(i, j int) bool {
+			left, right := math.Abs(reporterPairs[i].DiscrepancyPct), math.Abs(reporterPairs[j].DiscrepancyPct)
+			if left != right {
+				return left > right
+			}
+			if reporterPairs[i].Period != reporterPairs[j].Period {
+				return reporterPairs[i].Period > reporterPairs[j].Period
+			}
+			if reporterPairs[i].Partner != reporterPairs[j].Partner {
+				return reporterPairs[i].Partner < reporterPairs[j].Partner
+			}
+			if reporterPairs[i].Flow != reporterPairs[j].Flow {
+				return reporterPairs[i].Flow < reporterPairs[j].Flow
+			}
+			return reporterPairs[i].PeriodType < reporterPairs[j].PeriodType
+		})
+		values := make([]float64, len(reporterPairs))
+		for index, pair := range reporterPairs {
+			values[index] = math.Abs(pair.DiscrepancyPct)
+		}
+		row := reporterDiscrepancy{
+			ISO3: iso3, Name: names[iso3], Pairs: len(reporterPairs),
+			MedianAbsPct: medianOf(values), MeanAbsPct: mean(values), Largest: reporterPairs[0],
+		}
+		row.Trust = discrepancyTrust(row.MedianAbsPct)
+		output.Rows = append(output.Rows, row)
+	}
+	sort.Slice(output.Rows, func(i, j int) bool { return output.Rows[i].ISO3 < output.Rows[j].ISO3 })
+	output.Summary.Reporters = len(output.Rows)
+	return output
+}
+
+// discrepancyTrust places a median absolute discrepancy in its class.
+func discrepancyTrust(medianAbsPct float64) string {
+	switch {
+	case medianAbsPct <= discrepancyHigh:
+		return "high"
+	case medianAbsPct <= discrepancyMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// medianOf returns the median of values without reordering them.
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
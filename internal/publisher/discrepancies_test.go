@@ -0,0 +1,41 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildDiscrepanciesClassifiesReporterTrust(t *testing.T) {
+	pair := func(iso3, partner string, flow model.Flow, period string, pct float64) discrepancyPair {
+		return discrepancyPair{ISO3: iso3, Partner: partner, Flow: flow, PeriodType: model.PeriodYear, Period: period, WITSUSD: 100, ComtradeUSD: 100 + pct, DiscrepancyPct: pct}
+	}
+	pairs := []discrepancyPair{
+		pair("KOR", "USA", model.FlowExport, "2023", 1),
+		pair("KOR", "CHN", model.FlowExport, "2023", -1.5),
+		pair("KOR", "CHN", model.FlowImport, "2022", -30),
+		pair("VNM", "USA", model.FlowExport, "2023", 6),
+		pair("MEX", "USA", model.FlowImport, "2023", -25),
+	}
+	output := buildDiscrepancies("2026-01-01T00:00:00Z", pairs, []latestEntry{{ISO3: "KOR", Name: "Korea"}})
+	if output.Summary.Pairs != 5 || output.Summary.Reporters != 3 || output.Summary.MedianAbsPct == nil || *output.Summary.MedianAbsPct != 6 {
+		t.Fatalf("unexpected summary %+v", output.Summary)
+	}
+	if len(output.Rows) != 3 || output.Rows[0].ISO3 != "KOR" || output.Rows[1].ISO3 != "MEX" || output.Rows[2].ISO3 != "VNM" {
+		t.Fatalf("rows = %+v, want KOR, MEX, VNM", output.Rows)
+	}
+	kor := output.Rows[0]
+	if kor.Name != "Korea" || kor.Pairs != 3 || kor.MedianAbsPct != 1.5 || math.Abs(kor.MeanAbsPct-32.5/3) > 1e-12 || kor.Trust != "high" {
+		t.Fatalf("unexpected KOR summary %+v", kor)
+	}
+	if kor.Largest.Partner != "CHN" || kor.Largest.Flow != model.FlowImport || kor.Largest.DiscrepancyPct != -30 {
+		t.Fatalf("unexpected KOR largest pair %+v", kor.Largest)
+	}
+	if output.Rows[1].Trust != "low" || output.Rows[2].Trust != "medium" {
+		t.Fatalf("trust = %s/%s, want low for MEX and medium for VNM", output.Rows[1].Trust, output.Rows[2].Trust)
+	}
+	if empty := buildDiscrepancies("", nil, nil); empty.Summary.MedianAbsPct != nil || len(empty.Rows) != 0 {
+		t.Fatalf("expected an empty file without pairs, got %+v", empty)
+	}
+}
@@ -0,0 +1,192 @@
+package publisher
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// diversionMethod documents how diversion.json scores are derived.
+const diversionMethod = "correlation of year-over-year USA and CHN trade growth over the latest five years of consecutive comparable periods; score = -correlation × |tilt change| when the two move in opposite directions"
+
+// Diversion limits. A reporter needs diversionMinChanges year-over-year
+// changes inside the last diversionYears years to be scored.
+const (
+	diversionYears      = 5
+	diversionMinChanges = 4
+)
+
+// diversionFile is diversion.json: reporters whose USA and China trade
+// growth move against each other, ranked as likely trade diversion from the
+// USA to China and from China to the USA. A high score is a prompt to look
+// at the reporter, not evidence of rerouting on its own.
+type diversionFile struct {
+	SchemaVersion string          `json:"schema_version"`
+	GeneratedAt   string          `json:"generated_at"`
+	Provider      string          `json:"provider"`
+	Method        string          `json:"method"`
+	Limit         int             `json:"limit"`
+	Lists         []diversionList `json:"lists"`
+}
+
+type diversionList struct {
+	ID    string               `json:"id"`
+	Title string               `json:"title"`
+	Rows  []diversionCandidate `json:"rows"`
+}
+
+// diversionCandidate is one scored reporter. BasePeriod is the period the
+// first change is measured from, and USAChange and CHNChange are relative
+// growth from BasePeriod to LastPeriod. OpposingPeriods counts the changes
+// where the losing partner's trade fell while the gaining partner's rose.
+type diversionCandidate struct {
+	Rank            int              `json:"rank"`
+	ISO3            string           `json:"iso3"`
+	Name            string           `json:"name,omitempty"`
+	PeriodType      model.PeriodType `json:"period_type"`
+	BasePeriod      string           `json:"base_period"`
+	LastPeriod      string           `json:"last_period"`
+	Changes         int              `json:"changes"`
+	OpposingPeriods int              `json:"opposing_periods"`
+	Correlation     float64          `json:"correlation"`
+	USAChange       float64          `json:"usa_change"`
+	CHNChange       float64          `json:"chn_change"`
+	TiltChange      float64          `json:"tilt_change"`
+	Score           float64          `json:"score"`
+
+	usaFalls, chnFalls int
+}
+
+// diversionLists are the published directions. TowardCHN lists reporters
+// whose tilt moved toward China; the other list those that moved toward the
+// USA.
+var diversionLists = []struct {
+	ID, Title string
+	TowardCHN bool
+}{
+	{ID: "usa_to_chn", Title: "USA trade falling as China trade rises", TowardCHN: true},
+	{ID: "chn_to_usa", Title: "China trade falling as USA trade rises", TowardCHN: false},
+}
+
+// buildDiversion scores every reporter with enough consecutive comparable
+// periods. Growth is measured year over year so the seasonality both
+// partners share in monthly and quarterly data does not mask diversion.
+// Candidates need a negative correlation, a tilt change in the list's
+// direction, and at least one opposing period.
+func buildDiversion(generatedAt, provider string, history seriesFile, latest []latestEntry, limit int) diversionFile {
+	output := diversionFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Method:        diversionMethod,
+		Limit:         limit,
+		Lists:         []diversionList{},
+	}
+	names := make(map[string]string, len(latest))
+	for _, row := range latest {
+		names[row.ISO3] = row.Name
+	}
+	var candidates []diversionCandidate
+	for _, row := range history.Rows {
+		candidate, ok := scoreDiversion(row.Points)
+		if ok {
+			candidate.ISO3, candidate.Name = row.ISO3, names[row.ISO3]
+			candidates = append(candidates, candidate)
+		}
+	}
+	for _, definition := range diversionLists {
+		list := diversionList{ID: definition.ID, Title: definition.Title, Rows: []diversionCandidate{}}
+		for _, candidate := range candidates {
+			if (candidate.TiltChange > 0) != definition.TowardCHN || candidate.TiltChange == 0 {
+				continue
+			}
+			candidate.OpposingPeriods = candidate.chnFalls
+			if definition.TowardCHN {
+				candidate.OpposingPeriods = candidate.usaFalls
+			}
+			if candidate.OpposingPeriods == 0 {
+				continue
+			}
+			list.Rows = append(list.Rows, candidate)
+		}
+		sort.Slice(list.Rows, func(i, j int) bool {
+			if list.Rows[i].Score != list.Rows[j].Score {
+				return list.Rows[i].Score > list.Rows[j].Score
+			}
+			return list.Rows[i].ISO3 < list.Rows[j].ISO3
+		})
+		if limit > 0 && len(list.Rows) > limit {
+			list.Rows = list.Rows[:limit]
+		}
+		for index := range list.Rows {
+			list.Rows[index].Rank = index + 1
+		}
+		output.Lists = append(output.Lists, list)
+	}
+	return output
+}
+
+// scoreDiversion measures one reporter's latest run of comparable points.
+func scoreDiversion(points []seriesPoint) (diversionCandidate, bool) {
+	periodType, run := forecastRun(points)
+	byPeriod := make(map[string]seriesPoint, len(run))
+	for _, point := range run {
+		byPeriod[point.Period] = point
+	}
+	var bases, currents []seriesPoint
+	for _, point := range run {
+		base, ok := byPeriod[basePeriod(growthYoY, periodType, point.Period)]
+		if ok && base.USA.Trade > 0 && base.CHN.Trade > 0 {
+			bases, currents = append(bases, base), append(currents, point)
+		}
+	}
+	if window := diversionYears * max(seasonLength(periodType), 1); len(currents) > window {
+		bases, currents = bases[len(bases)-window:], currents[len(currents)-window:]
+	}
+	if len(currents) < diversionMinChanges {
+		return diversionCandidate{}, false
+	}
+	usaGrowth, chnGrowth := make([]float64, len(currents)), make([]float64, len(currents))
+	candidate := diversionCandidate{PeriodType: periodType, Changes: len(currents)}
+	for index, point := range currents {
+		usaGrowth[index] = point.USA.Trade/bases[index].USA.Trade - 1
+		chnGrowth[index] = point.CHN.Trade/bases[index].CHN.Trade - 1
+		switch {
+		case usaGrowth[index] < 0 && chnGrowth[index] > 0:
+			candidate.usaFalls++
+		case chnGrowth[index] < 0 && usaGrowth[index] > 0:
+			candidate.chnFalls++
+		}
+	}
+	correlation, ok := pearson(usaGrowth, chnGrowth)
+	if !ok || correlation >= 0 {
+		return diversionCandidate{}, false
+	}
+	first, last := bases[0], currents[len(currents)-1]
+	candidate.BasePeriod, candidate.LastPeriod = first.Period, last.Period
+	candidate.Correlation = correlation
+	candidate.USAChange = last.USA.Trade/first.USA.Trade - 1
+	candidate.CHNChange = last.CHN.Trade/first.CHN.Trade - 1
+	candidate.TiltChange = (last.CHN.Trade-last.USA.Trade)/last.Total - (first.CHN.Trade-first.USA.Trade)/first.Total
+	candidate.Score = -correlation * math.Abs(candidate.TiltChange)
+	return candidate, true
+}
+
+// pearson is the sample correlation of x and y. It is undefined, and ok is
+// false, when either series is constant.
+func pearson(x, y []float64) (float64, bool) {
+	meanX, meanY := mean(x), mean(y)
+	var covariance, varianceX, varianceY float64
+	for index := range x {
+		dx, dy := x[index]-meanX, y[index]-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return 0, false
+	}
+	return covariance / math.Sqrt(varianceX*varianceY), true
+}
@@ -0,0 +1,53 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildDiversionScoresOpposingPartnerGrowth(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter string, usa, chn []float64) {
+		for index := range usa {
+			period := []string{"2018", "2019", "2020", "2021", "2022", "2023", "2024"}[index]
+			for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+				rows = append(rows,
+					observationRow{ReporterISO: reporter, PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(usa[index] / 2)},
+					observationRow{ReporterISO: reporter, PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(chn[index] / 2)},
+				)
+			}
+		}
+	}
+	// KOR swings from the USA to China, MEX the other way, and JPN's
+	// partners grow together, so it is never a candidate.
+	add("KOR", []float64{100, 90, 80, 85, 70, 60, 55}, []float64{100, 110, 125, 120, 140, 150, 160})
+	add("MEX", []float64{100, 110, 130, 135, 160, 170, 172}, []float64{100, 95, 85, 86, 70, 66, 65})
+	add("JPN", []float64{100, 110, 120, 130, 140, 150, 160}, []float64{100, 112, 121, 133, 139, 152, 158})
+	add("VNM", []float64{100, 90, 80, 70}, []float64{100, 110, 120, 130})
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+
+	output := buildDiversion("2026-01-01T00:00:00Z", "WITS", history, []latestEntry{{ISO3: "KOR", Name: "Korea"}}, 10)
+	if output.Provider != "wits" || output.Method != diversionMethod || len(output.Lists) != 2 {
+		t.Fatalf("unexpected diversion header: %+v", output)
+	}
+	toCHN, toUSA := output.Lists[0], output.Lists[1]
+	if toCHN.ID != "usa_to_chn" || len(toCHN.Rows) != 1 || toUSA.ID != "chn_to_usa" || len(toUSA.Rows) != 1 || toUSA.Rows[0].ISO3 != "MEX" {
+		t.Fatalf("lists = %+v, want KOR toward China and MEX toward the USA; JPN and VNM (three changes) left out", output.Lists)
+	}
+	kor := toCHN.Rows[0]
+	if kor.Rank != 1 || kor.Name != "Korea" || kor.BasePeriod != "2019" || kor.LastPeriod != "2024" || kor.Changes != 5 || kor.OpposingPeriods != 4 {
+		t.Fatalf("unexpected KOR window %+v", kor)
+	}
+	wantTilt := (160.0-55)/215 - (110.0-90)/200
+	if kor.Correlation >= 0 || math.Abs(kor.TiltChange-wantTilt) > 1e-9 || math.Abs(kor.Score+kor.Correlation*wantTilt) > 1e-9 || math.Abs(kor.USAChange-(55.0/90-1)) > 1e-9 {
+		t.Fatalf("unexpected KOR score %+v", kor)
+	}
+	if limited := buildDiversion("", "wits", history, nil, 1); len(limited.Lists[0].Rows) != 1 || limited.Limit != 1 {
+		t.Fatalf("expected -diversion-top to cap each list, got %+v", limited.Lists)
+	}
+	if _, ok := pearson([]float64{1, 1, 1}, []float64{1, 2, 3}); ok {
+		t.Fatalf("expected a constant series to have no correlation")
+	}
+}
@@ -0,0 +1,157 @@
+package publisher
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DownloadPath is the bulk download endpoint served by DownloadHandler.
+const DownloadPath = "/v1/download"
+
+// downloadFormats maps a /v1/download format to the file extension of the
+// archive entries it produces.
+var downloadFormats = map[string]string{"csv": ".csv", "parquet": ".parquet", "zip": ""}
+
+// DownloadHandler streams the published dataset in dataDir as one zip
+// archive. format=csv and format=parquet hold the latest, history, and
+// rankings tables the build's -format option writes, generated from the
+// published JSON; format=zip holds every published artifact as is. The
+// archive is written as it is generated, so nothing is cached on disk.
+func DownloadHandler(dataDir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+		if format == "" {
+			format = "zip"
+		}
+		if _, ok := downloadFormats[format]; !ok {
+			http.Error(w, fmt.Sprintf("unsupported format %q (expected csv, parquet, or zip)", format), http.StatusBadRequest)
+			return
+		}
+		var latest latestFile
+		if err := readPublished(dataDir, "latest.json", &latest); err != nil {
+			http.Error(w, "no published data to download", http.StatusServiceUnavailable)
+			return
+		}
+		var tables []exportTable
+		if format != "zip" {
+			var err error
+			if tables, err = downloadTables(dataDir, latest); err != nil {
+				http.Error(w, "failed to read published data", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		generatedAt, _ := time.Parse(time.RFC3339, latest.GeneratedAt)
+		name := "tradegravity"
+		if !generatedAt.IsZero() {
+			name += "-" + generatedAt.UTC().Format("2006-01-02")
+		}
+		if format != "zip" {
+			name += "-" + format
+		}
+		name += ".zip"
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Method == http.MethodHead {
+			return
+		}
+		archive := zip.NewWriter(w)
+		var err error
+		if format == "zip" {
+			err = zipPublished(archive, dataDir)
+		} else {
+			err = zipTables(archive, tables, downloadFormats[format], generatedAt)
+		}
+		if err != nil {
+			// The status is already sent; a truncated archive fails to open.
+			return
+		}
+		archive.Close()
+	})
+}
+
+// downloadTables rebuilds the tabular exports from the published JSON.
+// rankings.json is optional, as it is in the build.
+func downloadTables(dataDir string, latest latestFile) ([]exportTable, error) {
+	var history seriesFile
+	if err := readPublished(dataDir, "history.json", &history); err != nil {
+		return nil, err
+	}
+	tables := []exportTable{latestTable(latest.Rows), historyTable(history)}
+	var rankings rankingsFile
+	if err := readPublished(dataDir, "rankings.json", &rankings); err == nil {
+		tables = append(tables, rankingsTable(rankings))
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// zipTables writes each table as one archive entry dated modified, the
+// publication time of the data it was generated from.
+func zipTables(archive *zip.Writer, tables []exportTable, extension string, modified time.Time) error {
+	for _, table := range tables {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: table.Name + extension, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		if extension == ".csv" {
+			err = encodeCSV(entry, table)
+		} else {
+			var body []byte
+			if body, err = encodeParquet(table); err == nil {
+				_, err = entry.Write(body)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s%s: %w", table.Name, extension, err)
+		}
+	}
+	return nil
+}
+
+// zipPublished copies every file under dataDir into the archive, keeping
+// the published paths. Already compressed images are stored, not deflated.
+func zipPublished(archive *zip.Writer, dataDir string) error {
+	return filepath.WalkDir(dataDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(dataDir, path)
+		if err != nil {
+			return err
+		}
+		header := &zip.FileHeader{Name: filepath.ToSlash(relative), Method: zip.Deflate}
+		if strings.HasSuffix(relative, ".png") {
+			header.Method = zip.Store
+		}
+		if info, err := entry.Info(); err == nil {
+			header.Modified = info.ModTime()
+		}
+		writer, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(writer, file)
+		return err
+	})
+}
@@ -0,0 +1,111 @@
+package publisher
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadHandlerStreamsArchives(t *testing.T) {
+	dir := t.TempDir()
+	handler := DownloadHandler(dir)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DownloadPath, nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without published data, got %d", recorder.Code)
+	}
+
+	if err := writeJSON(filepath.Join(dir, "latest.json"), latestFile{
+		GeneratedAt: "2026-01-02T03:04:05Z",
+		Rows:        []latestEntry{{ISO3: "KOR", Name: "Korea", USA: partnerBlock{Trade: 10}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(filepath.Join(dir, "history.json"), seriesFile{
+		Rows: []reporterSeries{{ISO3: "KOR", Points: []seriesPoint{{Period: "2024"}, {Period: "2025"}}}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "countries"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "countries", "KOR.json"), []byte(`{"iso3":"KOR"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	download := func(method, target string) (*httptest.ResponseRecorder, map[string]string) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, target, nil))
+		if recorder.Code != http.StatusOK || method == http.MethodHead {
+			return recorder, nil
+		}
+		body := recorder.Body.Bytes()
+		reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			t.Fatalf("%s is not a zip archive: %v", target, err)
+		}
+		entries := map[string]string{}
+		for _, file := range reader.File {
+			opened, err := file.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := io.ReadAll(opened)
+			opened.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries[file.Name] = string(content)
+		}
+		return recorder, entries
+	}
+
+	recorder, entries := download(http.MethodGet, DownloadPath+"?format=csv")
+	if got := recorder.Header().Get("Content-Disposition"); got != `attachment; filename="tradegravity-2026-01-02-csv.zip"` {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected latest.csv and history.csv without rankings.json, got %v", entries)
+	}
+	if !strings.HasPrefix(entries["latest.csv"], "iso3,iso2,name,") || !strings.Contains(entries["latest.csv"], "KOR,,Korea,") {
+		t.Fatalf("unexpected latest.csv:\n%s", entries["latest.csv"])
+	}
+	if lines := strings.Count(entries["history.csv"], "\n"); lines != 3 {
+		t.Fatalf("expected a header and two history rows, got %d lines", lines)
+	}
+
+	_, entries = download(http.MethodGet, DownloadPath+"?format=PARQUET")
+	for _, name := range []string{"latest.parquet", "history.parquet"} {
+		if body := entries[name]; !strings.HasPrefix(body, "PAR1") || !strings.HasSuffix(body, "PAR1") {
+			t.Fatalf("%s is not a parquet file", name)
+		}
+	}
+
+	recorder, entries = download(http.MethodGet, DownloadPath+"?format=zip")
+	if got := recorder.Header().Get("Content-Disposition"); got != `attachment; filename="tradegravity-2026-01-02.zip"` {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+	if entries["countries/KOR.json"] != `{"iso3":"KOR"}` || entries["latest.json"] == "" || entries["history.json"] == "" {
+		t.Fatalf("expected every published artifact, got %v", entries)
+	}
+
+	recorder, _ = download(http.MethodHead, DownloadPath+"?format=csv")
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 || recorder.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("unexpected HEAD response %d with %d bytes", recorder.Code, recorder.Body.Len())
+	}
+	recorder, _ = download(http.MethodGet, DownloadPath+"?format=xlsx")
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unsupported format, got %d", recorder.Code)
+	}
+	recorder, _ = download(http.MethodPost, DownloadPath)
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,108 @@
+package publisher
+
+import (
+	"database/sql"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// observationQualityColumns returns the select list for trade_observations'
+// estimated and quality_note columns, or constants reading every row as
+// official when the store predates them.
+func observationQualityColumns(db *sql.DB) (string, error) {
+	columns, err := sqliteTableColumns(db, "trade_observations")
+	if err != nil {
+		return "", err
+	}
+	_, estimated := columns["estimated"]
+	_, note := columns["quality_note"]
+	if !estimated || !note {
+		return "0 AS estimated, '' AS quality_note", nil
+	}
+	return "estimated, quality_note", nil
+}
+
+// observationEstimate is how an observation, or a month or quarter inside a
+// coarser period, was derived.
+type observationEstimate struct {
+	Estimated bool
+	Note      string
+}
+
+// estimateIndex records the quality of every observation by reporter,
+// partner, flow, and period. Rolled holds the quarters and years containing
+// an estimated month or quarter, for blocks summed from finer periods.
+type estimateIndex struct {
+	reported map[string]observationEstimate
+	rolled   map[string]observationEstimate
+}
+
+func estimateKey(reporter, partner string, flow model.Flow, periodType model.PeriodType, period string) string {
+	return strings.Join([]string{reporter, partner, string(flow), seriesKey(periodType, period)}, "|")
+}
+
+func indexEstimates(rows []observationRow) estimateIndex {
+	index := estimateIndex{reported: make(map[string]observationEstimate), rolled: make(map[string]observationEstimate)}
+	for _, row := range rows {
+		reporter, partner := strings.ToUpper(row.ReporterISO), strings.ToUpper(row.PartnerISO)
+		estimate := observationEstimate{Estimated: row.Estimated, Note: row.QualityNote}
+		index.reported[estimateKey(reporter, partner, row.Flow, row.PeriodType, row.Period)] = estimate
+		if !row.Estimated {
+			continue
+		}
+		for _, coarse := range []model.PeriodType{model.PeriodQuarter, model.PeriodYear} {
+			if target, _, ok := coarsePeriod(row.PeriodType, row.Period, coarse); ok {
+				key := estimateKey(reporter, partner, row.Flow, coarse, target)
+				if _, seen := index.rolled[key]; !seen {
+					index.rolled[key] = estimate
+				}
+			}
+		}
+	}
+	return index
+}
+
+// lookup reports whether either flow of a block at periodType and period is
+// estimated, and the first quality note among them. A flow with a reported
+// observation at that period uses its flags; otherwise the block was summed
+// from finer periods and is estimated when any of them is.
+func (index estimateIndex) lookup(reporter, partner string, periodType model.PeriodType, period string) observationEstimate {
+	var result observationEstimate
+	for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+		key := estimateKey(reporter, partner, flow, periodType, period)
+		estimate, ok := index.reported[key]
+		if !ok {
+			estimate = index.rolled[key]
+		}
+		if !estimate.Estimated {
+			continue
+		}
+		result.Estimated = true
+		if result.Note == "" {
+			result.Note = estimate.Note
+		}
+	}
+	return result
+}
+
+// markEstimated flags a partner block whose values include an estimated
+// observation. Growth is computed against earlier periods and is not
+// flagged by their estimates.
+func markEstimated(block *partnerBlock, index estimateIndex, reporter, partner string) {
+	if block.Period == "" {
+		return
+	}
+	estimate := index.lookup(reporter, partner, block.PeriodType, block.Period)
+	block.Estimated, block.QualityNote = estimate.Estimated, estimate.Note
+}
+
+func countEstimated(rows []observationRow) int {
+	count := 0
+	for _, row := range rows {
+		if row.Estimated {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,50 @@
+package publisher
+
+import (
+	"fmt"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildLatestMarksBlocksWithEstimatedObservations(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter, partner string, flow model.Flow, periodType model.PeriodType, period string, estimated bool) {
+		row := observationRow{ReporterISO: reporter, PartnerISO: partner, Flow: flow, PeriodType: periodType, Period: period, Value: 1000, Estimated: estimated}
+		if estimated {
+			row.QualityNote = model.QualityProviderEstimate
+		}
+		rows = append(rows, row)
+	}
+	for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+		add("KOR", "USA", flow, model.PeriodYear, "2023", flow == model.FlowImport)
+		add("KOR", "CHN", flow, model.PeriodYear, "2023", false)
+		// VNM's annual USA block is summed from quarters, one of them estimated.
+		add("VNM", "CHN", flow, model.PeriodYear, "2023", false)
+		for quarter := 1; quarter <= 4; quarter++ {
+			add("VNM", "USA", flow, model.PeriodQuarter, fmt.Sprintf("2023-Q%d", quarter), quarter == 4 && flow == model.FlowExport)
+		}
+	}
+
+	latest := buildLatest(rows, []string{"USA", "CHN"}, growthYoY, alignLatest, mixedDowngrade)
+	if len(latest) != 2 {
+		t.Fatalf("expected two rows, got %d", len(latest))
+	}
+	kor, vnm := latest[0], latest[1]
+	if !kor.USA.Estimated || kor.USA.QualityNote != model.QualityProviderEstimate || !kor.Partners["USA"].Estimated {
+		t.Fatalf("KOR USA block should be a provider estimate, got %+v", kor.USA)
+	}
+	if kor.CHN.Estimated || kor.CHN.QualityNote != "" {
+		t.Fatalf("KOR CHN block is official, got %+v", kor.CHN)
+	}
+	if vnm.USA.PeriodType != model.PeriodYear || !vnm.USA.Estimated {
+		t.Fatalf("VNM USA block summed from an estimated quarter should be estimated, got %+v", vnm.USA)
+	}
+
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+	for _, point := range history.Rows[1].Points {
+		if want := point.Period == "2023-Q4"; point.USA.Estimated != want {
+			t.Fatalf("VNM %s USA estimated = %t, want %t", point.Period, point.USA.Estimated, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// exportTable is a flat view of a published artifact for tabular formats.
+// Cells hold string, float64, *float64, int, *int, or bool values (nil
+// pointers are empty),
+// and column order is part of the published contract.
+type exportTable struct {
+	Name    string
+	Columns []string
+	Rows    [][]any
+}
+
+var exportFormats = map[string]struct{}{"json": {}, "csv": {}, "parquet": {}, "xlsx": {}, "ndjson": {}}
+
+// parseExportFormats validates a comma-separated -format value. JSON is always
+// written because the site and validator read it; other formats are extra.
+func parseExportFormats(value string) (map[string]bool, error) {
+	formats := map[string]bool{"json": true}
+	for _, item := range strings.Split(value, ",") {
+		format := strings.ToLower(strings.TrimSpace(item))
+		if format == "" {
+			continue
+		}
+		if _, ok := exportFormats[format]; !ok {
+			return nil, fmt.Errorf("unsupported format %q", format)
+		}
+		formats[format] = true
+	}
+	return formats, nil
+}
+
+func latestTable(rows []latestEntry) exportTable {
+	table := exportTable{
+		Name: "latest",
+		Columns: []string{
+			"iso3", "iso2", "name", "region", "income_group",
+			"usa_period_type", "usa_period", "usa_export", "usa_import", "usa_trade", "usa_trade_growth",
+			"chn_period_type", "chn_period", "chn_export", "chn_import", "chn_trade", "chn_trade_growth",
+			"total", "share_cn", "same_period", "comparison_period",
+			"usa_estimated", "chn_estimated", "subregion",
+		},
+	}
+	for _, row := range rows {
+		cells := []any{row.ISO3, row.ISO2, row.Name, row.Region, row.IncomeGroup}
+		for _, block := range []partnerBlock{row.USA, row.CHN} {
+			var growth *float64
+			if block.Growth != nil {
+				growth = block.Growth.Trade
+			}
+			cells = append(cells, string(block.PeriodType), block.Period, roundCents(block.Export), roundCents(block.Import), roundCents(block.Trade), growth)
+		}
+		cells = append(cells, row.Total, row.ShareCN, row.SamePeriod, row.ComparisonPeriod, row.USA.Estimated, row.CHN.Estimated, row.Subregion)
+		table.Rows = append(table.Rows, cells)
+	}
+	return table
+}
+
+func historyTable(history seriesFile) exportTable {
+	table := exportTable{
+		Name: "history",
+		Columns: []string{
+			"iso3", "period_type", "period",
+			"usa_available", "usa_export", "usa_import", "usa_trade",
+			"chn_available", "chn_export", "chn_import", "chn_trade",
+			"total", "share_cn", "comparable",
+			"usa_estimated", "chn_estimated",
+		},
+	}
+	for _, row := range history.Rows {
+		for _, point := range row.Points {
+			table.Rows = append(table.Rows, []any{
+				row.ISO3, string(point.PeriodType), point.Period,
+				point.USA.Available, roundCents(point.USA.Export), roundCents(point.USA.Import), roundCents(point.USA.Trade),
+				point.CHN.Available, roundCents(point.CHN.Export), roundCents(point.CHN.Import), roundCents(point.CHN.Trade),
+				point.Total, point.ShareCN, point.Comparable,
+				point.USA.Estimated, point.CHN.Estimated,
+			})
+		}
+	}
+	return table
+}
+
+func rankingsTable(rankings rankingsFile) exportTable {
+	table := exportTable{
+		Name:    "rankings",
+		Columns: []string{"ranking", "period_type", "period", "rank", "iso3", "name", "value", "prev_rank", "rank_delta"},
+	}
+	for _, entry := range rankings.Rankings {
+		for _, row := range entry.Rows {
+			table.Rows = append(table.Rows, []any{
+				entry.ID, rankings.PeriodType, rankings.Period, row.Rank, row.ISO3, row.Name, row.Value, row.PrevRank, row.RankDelta,
+			})
+		}
+	}
+	return table
+}
+
+func writeCSV(path string, table exportTable) error {
+	var body bytes.Buffer
+	if err := encodeCSV(&body, table); err != nil {
+		return err
+	}
+	return writeArtifact(path, body.Bytes())
+}
+
+func encodeCSV(w io.Writer, table exportTable) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(table.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(table.Columns))
+	for _, row := range table.Rows {
+		for index, cell := range row {
+			record[index] = formatCell(cell)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatCell(cell any) string {
+	switch value := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case *float64:
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(value)
+	case *int:
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// writeTables writes each table in every requested non-JSON format. The
+// xlsx format collects all tables into one workbook, one sheet per table.
+func writeTables(outDir string, formats map[string]bool, tables ...exportTable) error {
+	for _, table := range tables {
+		if formats["csv"] {
+			if err := writeCSV(filepath.Join(outDir, table.Name+".csv"), table); err != nil {
+				return fmt.Errorf("write %s.csv: %w", table.Name, err)
+			}
+		}
+		if formats["parquet"] {
+			if err := writeParquet(filepath.Join(outDir, table.Name+".parquet"), table); err != nil {
+				return fmt.Errorf("write %s.parquet: %w", table.Name, err)
+			}
+		}
+	}
+	if formats["xlsx"] {
+		if err := writeXLSX(filepath.Join(outDir, "tradegravity.xlsx"), tables); err != nil {
+			return fmt.Errorf("write tradegravity.xlsx: %w", err)
+		}
+	}
+	return nil
+}
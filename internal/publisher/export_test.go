@@ -0,0 +1,108 @@
+package publisher
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestParseExportFormatsAlwaysKeepsJSON(t *testing.T) {
+	formats, err := parseExportFormats("CSV")
+	if err != nil {
+		t.Fatalf("parseExportFormats() error = %v", err)
+	}
+	if !formats["json"] || !formats["csv"] {
+		t.Fatalf("formats = %#v", formats)
+	}
+	if _, err := parseExportFormats("json,pdf"); err == nil {
+		t.Fatal("expected unsupported format to be rejected")
+	}
+}
+
+func TestWriteTablesProducesStableCSVColumns(t *testing.T) {
+	growth := 0.25
+	latest := []latestEntry{{
+		ISO3: "KOR", Name: "Korea, Rep.", Subregion: "Eastern Asia",
+		USA:   partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Export: 10, Import: 5, Trade: 15, Growth: &growthBlock{Trade: &growth}, Estimated: true},
+		CHN:   partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Export: 20, Import: 15, Trade: 35},
+		Total: 50, ShareCN: 0.7, SamePeriod: true,
+	}}
+	dir := t.TempDir()
+	formats, _ := parseExportFormats("csv")
+	if err := writeTables(dir, formats, latestTable(latest), historyTable(seriesFile{})); err != nil {
+		t.Fatalf("writeTables() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "latest.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "iso3,iso2,name,region,income_group,usa_period_type") {
+		t.Fatalf("unexpected latest.csv:\n%s", content)
+	}
+	if lines[1] != `KOR,,"Korea, Rep.",,,Y,2023,10,5,15,0.25,Y,2023,20,15,35,,50,0.7,true,,true,false,Eastern Asia` {
+		t.Fatalf("unexpected latest.csv row: %s", lines[1])
+	}
+	history, err := os.ReadFile(filepath.Join(dir, "history.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(history)) != "iso3,period_type,period,usa_available,usa_export,usa_import,usa_trade,chn_available,chn_export,chn_import,chn_trade,total,share_cn,comparable,usa_estimated,chn_estimated" {
+		t.Fatalf("unexpected history.csv:\n%s", history)
+	}
+}
+
+func TestWriteXLSXBuildsOneSheetPerTable(t *testing.T) {
+	delta := 2
+	rankings := rankingsFile{PeriodType: "Y", Period: "2023", Rankings: []ranking{{ID: "share_cn", Rows: []rankingRow{{Rank: 1, ISO3: "VNM", Value: 0.9, RankDelta: &delta}}}}}
+	latest := []latestEntry{{ISO3: "KOR", Name: "Korea & Co", Total: 1500, ShareCN: 0.7}}
+	path := filepath.Join(t.TempDir(), "tradegravity.xlsx")
+	if err := writeXLSX(path, []exportTable{latestTable(latest), historyTable(seriesFile{}), rankingsTable(rankings)}); err != nil {
+		t.Fatalf("writeXLSX() error = %v", err)
+	}
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer archive.Close()
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[file.Name] = string(content)
+	}
+	workbook := parts["xl/workbook.xml"]
+	for _, name := range []string{`name="Latest"`, `name="History"`, `name="Rankings"`} {
+		if !strings.Contains(workbook, name) {
+			t.Fatalf("workbook is missing sheet %s: %s", name, workbook)
+		}
+	}
+	latestSheet := parts["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(latestSheet, `state="frozen"`) || !strings.Contains(latestSheet, "Korea &amp; Co") {
+		t.Fatalf("unexpected latest sheet: %s", latestSheet)
+	}
+	if !strings.Contains(latestSheet, `<c r="S2" s="3"><v>0.7</v></c>`) || !strings.Contains(latestSheet, `<c r="R2" s="2"><v>1500</v></c>`) {
+		t.Fatalf("share and total cells are not formatted: %s", latestSheet)
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet3.xml"], `<c r="I2" s="0"><v>2</v></c>`) {
+		t.Fatalf("rank delta cell missing: %s", parts["xl/worksheets/sheet3.xml"])
+	}
+	for _, err := range []error{xml.Unmarshal([]byte(latestSheet), new(struct{})), xml.Unmarshal([]byte(parts["xl/styles.xml"]), new(struct{}))} {
+		if err != nil {
+			t.Fatalf("invalid XML: %v", err)
+		}
+	}
+}
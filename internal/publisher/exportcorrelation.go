@@ -0,0 +1,113 @@
+package publisher
+
+import (
+	"math"
+	"sort"
+
+	"tradegravity/internal/model"
+)
+
+// Export relationship classes. A rolling correlation at or above
+// exportComplementary means exports to the USA and China grow and shrink
+// together; at or below exportSubstitutive, one gains when the other loses.
+const (
+	exportComplementary = 0.3
+	exportSubstitutive  = -0.3
+)
+
+// exportRelationship summarizes a reporter's latest rolling correlation
+// between year-over-year export growth to the USA and to China.
+type exportRelationship struct {
+	PeriodType  model.PeriodType `json:"period_type"`
+	Through     string           `json:"through"`
+	Window      int              `json:"window"`
+	Correlation float64          `json:"correlation"`
+	Class       string           `json:"class"`
+}
+
+// exportCorrelationWindow is the number of consecutive growth values each
+// rolling correlation covers: six years of annual data, and three and two
+// years of quarterly and monthly data.
+func exportCorrelationWindow(periodType model.PeriodType) int {
+	switch periodType {
+	case model.PeriodMonth:
+		return 24
+	case model.PeriodQuarter:
+		return 12
+	default:
+		return 6
+	}
+}
+
+// exportRelationshipClass places a correlation in its class.
+func exportRelationshipClass(correlation float64) string {
+	switch {
+	case correlation >= exportComplementary:
+		return "complementary"
+	case correlation <= exportSubstitutive:
+		return "substitutive"
+	default:
+		return "independent"
+	}
+}
+
+// applyExportCorrelation sets ExportCorrelation on every point that ends a
+// full window of consecutive periods with year-over-year export growth to
+// both partners, and sets the file's ExportRelationship from the latest such
+// point of the period type with the most of them, the finer type on a tie.
+// It must run after the points' growth is filled in.
+func applyExportCorrelation(file *countryFile) {
+	byType := make(map[model.PeriodType][]int)
+	for index, point := range file.Points {
+		byType[point.PeriodType] = append(byType[point.PeriodType], index)
+	}
+	counts := make(map[model.PeriodType]int)
+	latest := make(map[model.PeriodType]int)
+	for periodType, indexes := range byType {
+		sort.Slice(indexes, func(i, j int) bool {
+			return periodKey(periodType, file.Points[indexes[i]].Period) < periodKey(periodType, file.Points[indexes[j]].Period)
+		})
+		window := exportCorrelationWindow(periodType)
+		var usa, chn []float64
+		for position, index := range indexes {
+			point := &file.Points[index]
+			if point.USAGrowth == nil || point.USAGrowth.Export == nil || point.CHNGrowth == nil || point.CHNGrowth.Export == nil {
+				usa, chn = nil, nil
+				continue
+			}
+			if position > 0 && precedingPeriod(periodType, point.Period) != file.Points[indexes[position-1]].Period {
+				usa, chn = nil, nil
+			}
+			usa, chn = append(usa, *point.USAGrowth.Export), append(chn, *point.CHNGrowth.Export)
+			if len(usa) < window {
+				continue
+			}
+			correlation, ok := pearson(usa[len(usa)-window:], chn[len(chn)-window:])
+			if !ok {
+				continue
+			}
+			correlation = math.Max(-1, math.Min(1, correlation))
+			point.ExportCorrelation = &correlation
+			counts[periodType]++
+			latest[periodType] = index
+		}
+	}
+	file.ExportRelationship = nil
+	var periodType model.PeriodType
+	for candidate, count := range counts {
+		if count > counts[periodType] || (count == counts[periodType] && periodPriority(candidate) > periodPriority(periodType)) {
+			periodType = candidate
+		}
+	}
+	if counts[periodType] == 0 {
+		return
+	}
+	point := file.Points[latest[periodType]]
+	file.ExportRelationship = &exportRelationship{
+		PeriodType:  periodType,
+		Through:     point.Period,
+		Window:      exportCorrelationWindow(periodType),
+		Correlation: *point.ExportCorrelation,
+		Class:       exportRelationshipClass(*point.ExportCorrelation),
+	}
+}
@@ -0,0 +1,50 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestApplyExportCorrelationClassifiesRollingWindows(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter string, years []string, usa, chn []float64) {
+		for index, year := range years {
+			rows = append(rows,
+				observationRow{ReporterISO: reporter, PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: year, Value: model.CentsFromUSD(usa[index])},
+				observationRow{ReporterISO: reporter, PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: year, Value: model.CentsFromUSD(chn[index])},
+			)
+		}
+	}
+	years := []string{"2016", "2017", "2018", "2019", "2020", "2021", "2022", "2023"}
+	// KOR's exports to China rise when those to the USA fall; MEX has a gap
+	// in 2019, so no run is long enough for a window.
+	add("KOR", years, []float64{100, 110, 99, 109, 98, 108, 97, 107}, []float64{100, 90, 99, 89, 98, 88, 97, 87})
+	add("MEX", append(years[:3:3], years[4:]...), []float64{100, 110, 120, 130, 140, 150, 160}, []float64{100, 110, 120, 130, 140, 150, 160})
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+
+	_, files := buildCountryFiles("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, history, nil)
+	kor := files["KOR"]
+	var correlated []string
+	for _, point := range kor.Points {
+		if point.ExportCorrelation != nil {
+			if *point.ExportCorrelation > exportSubstitutive {
+				t.Fatalf("KOR %s correlation = %v, want substitutive", point.Period, *point.ExportCorrelation)
+			}
+			correlated = append(correlated, point.Period)
+		}
+	}
+	if len(correlated) != 2 || correlated[0] != "2022" || correlated[1] != "2023" {
+		t.Fatalf("correlated periods = %v, want the two ending six growth years", correlated)
+	}
+	relationship := kor.ExportRelationship
+	if relationship == nil || relationship.Through != "2023" || relationship.Window != 6 || relationship.PeriodType != model.PeriodYear || relationship.Class != "substitutive" {
+		t.Fatalf("unexpected KOR relationship %+v", relationship)
+	}
+	if files["MEX"].ExportRelationship != nil {
+		t.Fatalf("expected no MEX relationship across a gap, got %+v", files["MEX"].ExportRelationship)
+	}
+	if exportRelationshipClass(0.5) != "complementary" || exportRelationshipClass(0) != "independent" {
+		t.Fatalf("unexpected relationship classes")
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package publisher
 
 import (
 	"context"
@@ -58,13 +58,42 @@ type seriesPoint struct {
 	Total      float64          `json:"total"`
 	ShareCN    float64          `json:"share_cn"`
 	Comparable bool             `json:"comparable"`
+	ShareCNTTM *float64         `json:"share_cn_ttm,omitempty"`
 }
 
 type seriesBlock struct {
-	Available bool    `json:"available"`
-	Export    float64 `json:"export"`
-	Import    float64 `json:"import"`
-	Trade     float64 `json:"trade"`
+	Available   bool           `json:"available"`
+	Export      float64        `json:"export"`
+	Import      float64        `json:"import"`
+	Trade       float64        `json:"trade"`
+	TTM         *trailingBlock `json:"ttm,omitempty"`
+	Estimated   bool           `json:"estimated,omitempty"`
+	QualityNote string         `json:"quality_note,omitempty"`
+
+	// exportCents and importCents hold the exact values the block is built
+	// from, until settle converts them.
+	exportCents, importCents model.Cents
+}
+
+// add sums value into flow's exact total.
+func (block *seriesBlock) add(flow model.Flow, value model.Cents) {
+	switch flow {
+	case model.FlowExport:
+		block.exportCents += value
+	case model.FlowImport:
+		block.importCents += value
+	}
+}
+
+// settleBlocks sets the export, import, and trade dollars of a USA and a CHN
+// block from their exact totals and returns the combined trade, so each sum
+// is converted once.
+func settleBlocks(usa, chn *seriesBlock) float64 {
+	for _, block := range []*seriesBlock{usa, chn} {
+		block.Export, block.Import = block.exportCents.USD(), block.importCents.USD()
+		block.Trade = (block.exportCents + block.importCents).USD()
+	}
+	return (usa.exportCents + usa.importCents + chn.exportCents + chn.importCents).USD()
 }
 
 type productIndexFile struct {
@@ -250,11 +279,15 @@ type matrixFile struct {
 	ProductLevel  int             `json:"product_level"`
 	ReporterISO3  string          `json:"reporter_iso3"`
 	Period        string          `json:"period"`
+	Concentration *concentration  `json:"concentration,omitempty"`
 	Rows          []matrixPartner `json:"rows"`
 }
 
+// matrixPartner is one partner of a reporter's matrix. Label names partners
+// that are not countries, such as EUU, when the partner policy publishes them.
 type matrixPartner struct {
 	PartnerISO3     string  `json:"partner_iso3"`
+	Label           string  `json:"label,omitempty"`
 	ExportAvailable bool    `json:"export_available"`
 	ImportAvailable bool    `json:"import_available"`
 	ExportUSD       float64 `json:"export_usd"`
@@ -306,6 +339,8 @@ type ingestRunRecord struct {
 	SkippedCount  int      `json:"skipped_count"`
 	StoredCount   int      `json:"stored_count"`
 	Errors        []string `json:"errors"`
+	CodeVersion   string   `json:"code_version,omitempty"`
+	CodeCommit    string   `json:"code_commit,omitempty"`
 }
 
 type qualityFile struct {
@@ -317,6 +352,10 @@ type qualityFile struct {
 	ReporterIssues     []reporterIssue      `json:"reporter_issues"`
 	CollectionRuns     []ingestRunRecord    `json:"collection_runs"`
 	ProviderComparison []providerComparison `json:"provider_comparison"`
+	// PeriodConsistency lists annual observations that disagree with their
+	// summed months by more than ConsistencyTolerance.
+	ConsistencyTolerance float64             `json:"consistency_tolerance"`
+	PeriodConsistency    []periodConsistency `json:"period_consistency"`
 }
 
 type qualitySummary struct {
@@ -326,6 +365,10 @@ type qualitySummary struct {
 	MissingPartnerBlocks  int `json:"missing_partner_blocks"`
 	StalePartnerBlocks    int `json:"stale_partner_blocks"`
 	ComparisonCount       int `json:"provider_comparison_count"`
+	ConsistencyChecks     int `json:"consistency_check_count"`
+	ConsistencyFlags      int `json:"consistency_flag_count"`
+	EstimatedObservations int `json:"estimated_observation_count"`
+	EstimatedBlocks       int `json:"estimated_partner_blocks"`
 }
 
 type reporterIssue struct {
@@ -347,7 +390,7 @@ type providerComparison struct {
 	DeltaRatio        float64 `json:"delta_ratio"`
 }
 
-func buildDataCatalog(generatedAt, provider, contextStatus string, series seriesFile, products productIndexFile, strategicIndex strategicIndexFile, tariffIndex tariffIndexFile, matrixIndex matrixIndexFile, mirrorIndex mirrorIndexFile, semiconductorMonthlyIndex semiconductorMonthlyIndexFile, publicationChanges publicationChangesFile, semiconductorReferences ...semiconductor.Reference) dataCatalogFile {
+func buildDataCatalog(generatedAt, provider, contextStatus string, series, history seriesFile, countries countryIndexFile, rankings rankingsFile, tilt tiltFile, movers moversFile, forecast forecastFile, volatility volatilityFile, diversion diversionFile, correlation correlationFile, aggregates aggregatesFile, mapProperties mapPropertiesFile, coverage coverageFile, discrepancies discrepancyFile, publishDiff publishDiffFile, products productIndexFile, rca rcaIndexFile, strategicIndex strategicIndexFile, tariffIndex tariffIndexFile, matrixIndex matrixIndexFile, mirrorIndex mirrorIndexFile, semiconductorMonthlyIndex semiconductorMonthlyIndexFile, publicationChanges publicationChangesFile, semiconductorReferences ...semiconductor.Reference) dataCatalogFile {
 	semiconductorReference := semiconductor.Reference{}
 	if len(semiconductorReferences) > 0 {
 		semiconductorReference = semiconductorReferences[0]
@@ -395,15 +438,31 @@ func buildDataCatalog(generatedAt, provider, contextStatus string, series series
 		Resources: []catalogResource{
 			{ID: "headline_totals", Title: "Headline bilateral totals", Status: "ready", Provider: primaryProvider, Grain: "reporter × USA/CHN partner × flow × latest period", Partitioning: "single publication", Href: "./latest.json"},
 			{ID: "time_series", Title: "Headline time series", Status: statusForCount(len(series.Rows)), Provider: primaryProvider, Grain: "reporter × USA/CHN partner × flow × period", Partitioning: "single publication", Href: "./series.json"},
+			{ID: "full_history", Title: "Full headline history", Status: statusForCount(len(history.Rows)), Provider: primaryProvider, Grain: "reporter × USA/CHN partner × flow × every stored period", Partitioning: "single publication", Href: "./history.json"},
+			{ID: "country_detail", Title: "Per-country detail", Status: statusForCount(len(countries.Partitions)), Provider: primaryProvider, Grain: "reporter × USA/CHN partner × flow × every stored period + year-over-year growth", Partitioning: "index + one file per reporter", Href: "./countries/index.json"},
+			{ID: "rankings", Title: "Headline rankings", Status: statusForCount(len(rankings.Rankings)), Provider: primaryProvider, Grain: "metric × top reporters × dominant latest period", Partitioning: "single publication", Href: "./rankings.json"},
+			{ID: "china_tilt", Title: "China-tilt index time series", Status: statusForCount(len(tilt.Rows)), Provider: primaryProvider, Grain: "reporter × every stored period with both USA/CHN blocks", Partitioning: "single publication", Href: "./tilt.json"},
+			{ID: "movers", Title: "Top movers in China share and USA+CHN trade", Status: statusForCount(len(movers.Windows)), Provider: primaryProvider, Grain: "window × metric × top reporters × dominant latest period", Partitioning: "single publication", Href: "./movers.json"},
+			{ID: "forecast", Title: "Next-period projections of China share and USA/CHN trade", Status: statusForCount(len(forecast.Rows)), Provider: primaryProvider, Grain: "reporter × metric × next 1-4 periods of the latest comparable run", Partitioning: "single publication", Href: "./forecast.json"},
+			{ID: "volatility", Title: "Rolling twelve-month volatility of USA/CHN trade", Status: statusForCount(len(volatility.Rows)), Provider: primaryProvider, Grain: "reporter × USA/CHN partner × month ending a complete twelve-month window", Partitioning: "single publication", Href: "./volatility.json"},
+			{ID: "diversion", Title: "Likely trade diversion between the USA and China", Status: statusForCount(len(diversion.Lists)), Provider: primaryProvider, Grain: "direction × top reporters × latest five years of year-over-year changes", Partitioning: "single publication", Href: "./diversion.json"},
+			{ID: "correlation", Title: "Co-movement of China share changes within regions", Status: statusForCount(len(correlation.Regions)), Provider: primaryProvider, Grain: "context region × reporter pair × latest ten years of share changes", Partitioning: "single publication", Href: "./correlation.json"},
+			{ID: "aggregates", Title: "World, regional, and group aggregates", Status: statusForCount(len(aggregates.Aggregates)), Provider: primaryProvider, Grain: "aggregate × USA/CHN partner × flow × shared latest period", Partitioning: "single publication", Href: "./aggregates.json"},
 			{ID: "country_context", Title: "Country economic context", Status: countryContextStatus, Provider: "world_bank", Grain: "reporter × indicator × year", Partitioning: "single publication", Href: "./context.json"},
 			{ID: "product_chapters", Title: "Product chapter observations", Status: productStatus, Provider: strings.ToLower(products.Provider), Classification: products.Classification, ProductLevel: products.Level, Grain: "reporter × partner × flow × HS2 × period", Partitioning: "index + one file per reporter", Href: "./products/index.json"},
+			{ID: "sector_rca", Title: "Revealed comparative advantage by sector", Status: statusForCount(len(rca.Reporters)), Provider: rca.Provider, Classification: rca.Classification, ProductLevel: rca.Level, Grain: "reporter × sector × USA/CHN/world market × latest product year", Partitioning: "index + one file per reporter", Href: "./rca/index.json"},
+			{ID: "coverage", Title: "Per-reporter data coverage", Status: statusForCount(len(coverage.Reporters)), Provider: "tradegravity", Grain: "reporter × tracked partner × flow × latest stored period", Partitioning: "single publication", Href: "./coverage.json"},
 			{ID: "quality", Title: "Quality and provenance signals", Status: "ready", Provider: "tradegravity", Grain: "publication + reporter/provider issue", Partitioning: "single publication", Href: "./quality.json"},
+			{ID: "discrepancies", Title: "WITS and Comtrade total discrepancies by reporter", Status: statusForCount(len(discrepancies.Rows)), Provider: "wits+comtrade", Grain: "reporter × tracked partner × flow × period reported by both providers", Partitioning: "single publication", Href: "./discrepancies.json"},
 			{ID: "strategic_hs6", Title: "Curated strategic HS6 products", Status: strategicStatus, Provider: strategicIndex.Provider, Classification: "source HS revision", ProductLevel: 6, Grain: "reporter × partner × flow × HS6 × period × source classification", Partitioning: "index + reporter/year chunks", Href: "./strategic-hs6/index.json"},
 			{ID: "tariff_schedules", Title: "Tariff schedules", Status: tariffStatus, Provider: tariffIndex.Provider, Classification: "source HS revision", ProductLevel: 6, Grain: "importer × exporter/regime × HS6 × year × data type", Partitioning: "index + importer/year chunks", Href: "./tariffs/index.json"},
 			{ID: "bilateral_matrix", Title: "Multi-partner bilateral matrix", Status: matrixStatus, Provider: matrixIndex.Provider, ProductLevel: 0, Grain: "reporter × partner × flow × TOTAL × annual period", Partitioning: "index + reporter/year chunks", Href: "./bilateral-matrix/index.json"},
 			{ID: "semiconductor_atlas", Title: "Semiconductor value-chain atlas", Status: semiconductorStatus, Provider: "tradegravity + cited official sources", Classification: "stage-mapped source HS revision", ProductLevel: 6, Grain: "stage taxonomy + country role context + policy event + published HS6 coverage", Partitioning: "reference publication + strategic HS6 reporter/year chunks", Href: "./semiconductors/reference.json"},
 			{ID: "semiconductor_monthly", Title: "Focused US-China semiconductor turning points", Status: semiconductorMonthlyStatus, Provider: semiconductorMonthlyIndex.Provider, Classification: "source HS revision", ProductLevel: 6, Grain: "focused reporter × USA/CHN partner × flow × selected HS6 × month", Partitioning: "index + one file per reporter", Href: "./semiconductors/monthly/index.json"},
 			{ID: "publication_changes", Title: "Observed publication changes", Status: publicationChangesStatus, Provider: "tradegravity", Classification: "source HS revision", ProductLevel: 6, Grain: "publication × focused reporter × month × selected HS6", Partitioning: "single bounded change feed", Href: "./changes.json"},
+			{ID: "map_properties", Title: "Choropleth properties by reporter", Status: statusForCount(len(mapProperties.Properties)), Provider: mapProperties.Provider, Grain: "reporter latest snapshot", Partitioning: "single object keyed by ISO3", Href: "./map.json"},
+			{ID: "artifact_index", Title: "Published file manifest", Status: "ready", Provider: "tradegravity", Grain: "publication × file", Partitioning: "single publication", Href: "./index.json"},
+			{ID: "publish_diff", Title: "Headline changes since the previous publish", Status: statusForPublishDiff(publishDiff), Provider: "tradegravity", Grain: "publication × reporter × USA/CHN latest block", Partitioning: "single publication", Href: "./diff.json"},
 			{ID: "mirror_reconciliation", Title: "Unadjusted mirror-reporting diagnostics", Status: mirrorStatus, Provider: mirrorIndex.Provider, ProductLevel: 0, Grain: "third-country reporter × USA/CHN anchor × mirrored flow × TOTAL × annual period", Partitioning: "index + reporter/year chunks", Href: "./mirror/index.json"},
 			{ID: "value_added_network", Title: "Value-added supply-chain exposure", Status: "planned", Grain: "origin × destination × industry × year", Partitioning: "year/industry chunks"},
 			{ID: "scenario_runs", Title: "Versioned scenario outputs", Status: "planned", Grain: "scenario × market × product × partner", Partitioning: "one manifest and result set per run"},
@@ -411,6 +470,15 @@ func buildDataCatalog(generatedAt, provider, contextStatus string, series series
 	}
 }
 
+// statusForPublishDiff is partial for a baseline diff, which has no previous
+// publish to compare with.
+func statusForPublishDiff(diff publishDiffFile) string {
+	if diff.Status == "changed" || diff.Status == "unchanged" {
+		return "ready"
+	}
+	return "partial"
+}
+
 func statusForCount(count int) string {
 	if count > 0 {
 		return "ready"
@@ -463,7 +531,17 @@ func enrichLatest(rows []latestEntry, countries []contextCountry) {
 }
 
 func buildSeriesFile(generatedAt, provider string, partners []string, observations []observationRow, maxYears int) seriesFile {
-	grouped := make(map[string]map[string]*seriesPoint)
+	points := seriesPoints{}
+	points.add(observations)
+	return points.file(generatedAt, provider, partners, maxYears)
+}
+
+// seriesPoints holds each reporter's series points by period as
+// observations are added, so a build can fold them in as it streams. series
+// and history are the same points read with different year limits.
+type seriesPoints map[string]map[string]*seriesPoint
+
+func (grouped seriesPoints) add(observations []observationRow) {
 	for _, row := range observations {
 		reporter := strings.ToUpper(strings.TrimSpace(row.ReporterISO))
 		if reporter == "" {
@@ -488,14 +566,24 @@ func buildSeriesFile(generatedAt, provider string, partners []string, observatio
 			continue
 		}
 		block.Available = true
+		if row.Estimated {
+			block.Estimated = true
+			if block.QualityNote == "" {
+				block.QualityNote = row.QualityNote
+			}
+		}
 		switch row.Flow {
 		case model.FlowExport:
-			block.Export = row.ValueUSD
+			block.exportCents = row.Value
 		case model.FlowImport:
-			block.Import = row.ValueUSD
+			block.importCents = row.Value
 		}
 	}
+}
 
+// file returns the series file of the points, keeping each reporter's last
+// maxYears years, or every year when maxYears is 0.
+func (grouped seriesPoints) file(generatedAt, provider string, partners []string, maxYears int) seriesFile {
 	output := seriesFile{
 		SchemaVersion: schemaVersion,
 		GeneratedAt:   generatedAt,
@@ -507,13 +595,14 @@ func buildSeriesFile(generatedAt, provider string, partners []string, observatio
 		points := make([]seriesPoint, 0, len(pointsByPeriod))
 		maxYear := 0
 		for _, point := range pointsByPeriod {
-			point.USA.Trade = point.USA.Export + point.USA.Import
-			point.CHN.Trade = point.CHN.Export + point.CHN.Import
-			point.Total = point.USA.Trade + point.CHN.Trade
+			point.Total = settleBlocks(&point.USA, &point.CHN)
 			if point.Total > 0 {
 				point.ShareCN = point.CHN.Trade / point.Total
 			}
 			point.Comparable = point.USA.Available && point.CHN.Available
+		}
+		applyTrailingTotals(pointsByPeriod)
+		for _, point := range pointsByPeriod {
 			if year := yearForPeriod(point.PeriodType, point.Period); year > maxYear {
 				maxYear = year
 			}
@@ -541,13 +630,13 @@ func buildSeriesFile(generatedAt, provider string, partners []string, observatio
 func yearForPeriod(periodType model.PeriodType, period string) int {
 	switch periodType {
 	case model.PeriodYear:
-		year, _ := parseYear(period)
+		year, _ := model.ParseYear(period)
 		return year
 	case model.PeriodQuarter:
-		year, _, _ := parseYearQuarter(period)
+		year, _, _ := model.ParseYearQuarter(period)
 		return year
 	case model.PeriodMonth:
-		year, _, _ := parseYearMonth(period)
+		year, _, _ := model.ParseYearMonth(period)
 		return year
 	default:
 		return 0
@@ -560,8 +649,12 @@ func loadProductObservations(dbPath, provider string, level int, partners []stri
 		return nil, err
 	}
 	defer db.Close()
+	value, err := observationValueColumn(db)
+	if err != nil {
+		return nil, err
+	}
 	query := `SELECT provider, classification, product_code, product_level,
-		reporter_iso3, partner_iso3, flow, period_type, period, value_usd
+		reporter_iso3, partner_iso3, flow, period_type, period, ` + value + `
 		FROM trade_observations
 		WHERE provider = ? AND product_level = ? AND flow IN ('export','import')`
 	args := []any{strings.ToLower(strings.TrimSpace(provider)), level}
@@ -581,7 +674,7 @@ func loadProductObservations(dbPath, provider string, level int, partners []stri
 		var row observationRow
 		var flow, periodType string
 		if err := rows.Scan(&row.Provider, &row.Classification, &row.ProductCode, &row.ProductLevel,
-			&row.ReporterISO, &row.PartnerISO, &flow, &periodType, &row.Period, &row.ValueUSD); err != nil {
+			&row.ReporterISO, &row.PartnerISO, &flow, &periodType, &row.Period, (*int64)(&row.Value)); err != nil {
 			return nil, err
 		}
 		row.Flow = model.Flow(strings.ToLower(flow))
@@ -652,11 +745,7 @@ func buildProductFiles(generatedAt, provider string, level int, partners []strin
 			continue
 		}
 		block.Available = true
-		if row.Flow == model.FlowExport {
-			block.Export += row.ValueUSD
-		} else if row.Flow == model.FlowImport {
-			block.Import += row.ValueUSD
-		}
+		block.add(row.Flow, row.Value)
 		periodSet[row.Period] = struct{}{}
 	}
 
@@ -674,9 +763,7 @@ func buildProductFiles(generatedAt, provider string, level int, partners []strin
 		}
 		filePeriodSet := make(map[string]struct{})
 		for _, entry := range entriesByKey {
-			entry.USA.Trade = entry.USA.Export + entry.USA.Import
-			entry.CHN.Trade = entry.CHN.Export + entry.CHN.Import
-			entry.Total = entry.USA.Trade + entry.CHN.Trade
+			entry.Total = settleBlocks(&entry.USA, &entry.CHN)
 			if entry.Total > 0 {
 				entry.ShareCN = entry.CHN.Trade / entry.Total
 			}
@@ -781,11 +868,7 @@ func buildStrategicFiles(generatedAt, provider string, partners []string, observ
 			block = &entry.CHN
 		}
 		block.Available = true
-		if row.Flow == model.FlowExport {
-			block.Export += row.ValueUSD
-		} else if row.Flow == model.FlowImport {
-			block.Import += row.ValueUSD
-		}
+		block.add(row.Flow, row.Value)
 		index.ObservationCount++
 	}
 
@@ -814,9 +897,7 @@ func buildStrategicFiles(generatedAt, provider string, partners []string, observ
 			Rows:          []strategicProductEntry{},
 		}
 		for _, entry := range grouped[key] {
-			entry.USA.Trade = entry.USA.Export + entry.USA.Import
-			entry.CHN.Trade = entry.CHN.Export + entry.CHN.Import
-			entry.Total = entry.USA.Trade + entry.CHN.Trade
+			entry.Total = settleBlocks(&entry.USA, &entry.CHN)
 			if entry.Total > 0 {
 				entry.ShareCN = entry.CHN.Trade / entry.Total
 			}
@@ -1078,8 +1159,12 @@ func loadMatrixObservations(dbPath, provider string) ([]observationRow, error) {
 		return nil, err
 	}
 	defer db.Close()
+	value, err := observationValueColumn(db)
+	if err != nil {
+		return nil, err
+	}
 	query := `SELECT provider, reporter_iso3, partner_iso3, flow, period_type, period,
-		MAX(value_usd), MAX(classification), 'TOTAL', 0
+		MAX(` + value + `), MAX(classification), 'TOTAL', 0
 		FROM trade_observations
 		WHERE product_level = 0 AND product_code = 'TOTAL' AND period_type = 'Y'
 			AND flow IN ('export','import') AND partner_iso3 <> 'WLD' AND partner_iso3 <> reporter_iso3`
@@ -1097,7 +1182,7 @@ func loadMatrixObservations(dbPath, provider string) ([]observationRow, error) {
 	result := make([]observationRow, 0)
 	for rows.Next() {
 		var row observationRow
-		if err := rows.Scan(&row.Provider, &row.ReporterISO, &row.PartnerISO, &row.Flow, &row.PeriodType, &row.Period, &row.ValueUSD, &row.Classification, &row.ProductCode, &row.ProductLevel); err != nil {
+		if err := rows.Scan(&row.Provider, &row.ReporterISO, &row.PartnerISO, &row.Flow, &row.PeriodType, &row.Period, (*int64)(&row.Value), &row.Classification, &row.ProductCode, &row.ProductLevel); err != nil {
 			return nil, err
 		}
 		result = append(result, row)
@@ -1105,7 +1190,7 @@ func loadMatrixObservations(dbPath, provider string) ([]observationRow, error) {
 	return result, rows.Err()
 }
 
-func buildMatrixFiles(generatedAt, provider string, observations []observationRow) (matrixIndexFile, map[string]matrixFile) {
+func buildMatrixFiles(generatedAt, provider string, observations []observationRow, policy model.PartnerPolicy) (matrixIndexFile, map[string]matrixFile) {
 	type partitionKey struct {
 		reporter string
 		period   string
@@ -1123,7 +1208,7 @@ func buildMatrixFiles(generatedAt, provider string, observations []observationRo
 		reporter := strings.ToUpper(strings.TrimSpace(observation.ReporterISO))
 		partner := strings.ToUpper(strings.TrimSpace(observation.PartnerISO))
 		period := strings.TrimSpace(observation.Period)
-		if !isPublishedISO3(reporter) || !isPublishedISO3(partner) || partner == "WLD" || partner == reporter || len(period) != 4 || observation.ValueUSD < 0 {
+		if !isPublishedISO3(reporter) || !policy.Published(partner) || partner == reporter || len(period) != 4 || observation.Value < 0 {
 			continue
 		}
 		key := partitionKey{reporter: reporter, period: period}
@@ -1133,15 +1218,18 @@ func buildMatrixFiles(generatedAt, provider string, observations []observationRo
 		entry := grouped[key][partner]
 		if entry == nil {
 			entry = &matrixPartner{PartnerISO3: partner}
+			if _, special := model.LookupSpecialPartner(partner); special {
+				entry.Label = model.PartnerLabel(partner)
+			}
 			grouped[key][partner] = entry
 		}
 		switch observation.Flow {
 		case model.FlowExport:
 			entry.ExportAvailable = true
-			entry.ExportUSD = observation.ValueUSD
+			entry.ExportUSD = observation.Value.USD()
 		case model.FlowImport:
 			entry.ImportAvailable = true
-			entry.ImportUSD = observation.ValueUSD
+			entry.ImportUSD = observation.Value.USD()
 		default:
 			continue
 		}
@@ -1178,6 +1266,7 @@ func buildMatrixFiles(generatedAt, provider string, observations []observationRo
 			}
 			return file.Rows[i].PartnerISO3 < file.Rows[j].PartnerISO3
 		})
+		file.Concentration = matrixConcentration(file.Rows)
 		relativePath := key.reporter + "/" + key.period + ".json"
 		files[relativePath] = file
 		index.Partitions = append(index.Partitions, matrixPartition{ReporterISO3: key.reporter, Period: key.period, Href: "./" + relativePath, RowCount: len(file.Rows)})
@@ -1218,8 +1307,18 @@ func loadIngestRuns(dbPath string, limit int) ([]ingestRunRecord, error) {
 		return nil, err
 	}
 	defer db.Close()
+	columns, err := sqliteTableColumns(db, "ingest_runs")
+	if err != nil {
+		return nil, err
+	}
+	// Stores from before runs recorded their build read as unknown.
+	codeColumns := "'' AS code_version, '' AS code_commit"
+	if _, ok := columns["code_version"]; ok {
+		codeColumns = "code_version, code_commit"
+	}
 	rows, err := db.Query(`SELECT run_id, provider, mode, started_at, finished_at, status,
-		reporter_count, request_count, success_count, failure_count, skipped_count, stored_count, errors_json
+		reporter_count, request_count, success_count, failure_count, skipped_count, stored_count, errors_json,
+		`+codeColumns+`
 		FROM ingest_runs ORDER BY finished_at DESC LIMIT ?`, limit)
 	if err != nil {
 		return nil, err
@@ -1231,7 +1330,7 @@ func loadIngestRuns(dbPath string, limit int) ([]ingestRunRecord, error) {
 		var errorsJSON string
 		if err := rows.Scan(&item.RunID, &item.Provider, &item.Mode, &item.StartedAt, &item.FinishedAt, &item.Status,
 			&item.ReporterCount, &item.RequestCount, &item.SuccessCount, &item.FailureCount,
-			&item.SkippedCount, &item.StoredCount, &errorsJSON); err != nil {
+			&item.SkippedCount, &item.StoredCount, &errorsJSON, &item.CodeVersion, &item.CodeCommit); err != nil {
 			return nil, err
 		}
 		_ = json.Unmarshal([]byte(errorsJSON), &item.Errors)
@@ -1243,11 +1342,49 @@ func loadIngestRuns(dbPath string, limit int) ([]ingestRunRecord, error) {
 	return results, rows.Err()
 }
 
-func buildQualityFile(generatedAt, primaryProvider string, latest []latestEntry, primaryRows, productRows []observationRow, runs []ingestRunRecord) qualityFile {
+func buildQualityFile(generatedAt, primaryProvider string, latest []latestEntry, primaryRows, productRows []observationRow, runs []ingestRunRecord, consistencyTolerance float64) qualityFile {
+	fold := newQualityFold(primaryProvider, productRows, consistencyTolerance)
+	fold.add(primaryRows)
+	return fold.file(generatedAt, latest, runs)
+}
+
+// qualityFold gathers what quality.json reads from total-trade observations
+// as a build streams them: comparisons with the product totals, annual and
+// monthly consistency, and the estimate count. Every call to add must hold
+// whole reporters, since each is checked against itself alone.
+type qualityFold struct {
+	primaryProvider string
+	tolerance       float64
+	secondary       map[string]*flowTotal
+	comparisons     []providerComparison
+	checked         int
+	flagged         []periodConsistency
+	estimated       int
+}
+
+func newQualityFold(primaryProvider string, productRows []observationRow, tolerance float64) *qualityFold {
+	return &qualityFold{
+		primaryProvider: primaryProvider,
+		tolerance:       tolerance,
+		secondary:       aggregateFlows(productRows, true),
+		flagged:         []periodConsistency{},
+	}
+}
+
+func (q *qualityFold) add(rows []observationRow) {
+	q.comparisons = append(q.comparisons, compareFlowTotals(q.primaryProvider, aggregateFlows(rows, false), q.secondary)...)
+	checked, flagged := periodConsistencyChecks(rows, q.tolerance)
+	q.checked += checked
+	q.flagged = append(q.flagged, flagged...)
+	q.estimated += countEstimated(rows)
+}
+
+func (q *qualityFold) file(generatedAt string, latest []latestEntry, runs []ingestRunRecord) qualityFile {
+	consistencyTolerance := q.tolerance
 	dominant := dominantLatestPeriod(latest)
 	output := qualityFile{
 		SchemaVersion: schemaVersion, GeneratedAt: generatedAt,
-		PrimaryProvider: strings.ToLower(strings.TrimSpace(primaryProvider)),
+		PrimaryProvider: strings.ToLower(strings.TrimSpace(q.primaryProvider)),
 		DominantPeriod:  dominant, CollectionRuns: runs,
 		ReporterIssues: []reporterIssue{}, ProviderComparison: []providerComparison{},
 	}
@@ -1271,18 +1408,32 @@ func buildQualityFile(generatedAt, primaryProvider string, latest []latestEntry,
 			output.Summary.IncomparableReporters++
 			issue.Issues = append(issue.Issues, "mixed_or_missing_periods")
 		}
-		for label, block := range map[string]partnerBlock{"usa": row.USA, "chn": row.CHN} {
+		for _, labeled := range []struct {
+			label string
+			block partnerBlock
+		}{{"usa", row.USA}, {"chn", row.CHN}} {
+			label, block := labeled.label, labeled.block
 			if block.Period != "" && string(block.PeriodType)+":"+block.Period != dominant {
 				issue.Issues = append(issue.Issues, "stale_"+label)
 				output.Summary.StalePartnerBlocks++
 			}
+			if block.Estimated {
+				issue.Issues = append(issue.Issues, "estimated_"+label)
+				output.Summary.EstimatedBlocks++
+			}
 		}
 		if len(issue.Issues) > 0 {
 			output.ReporterIssues = append(output.ReporterIssues, issue)
 		}
 	}
-	output.ProviderComparison = compareProviders(primaryProvider, primaryRows, productRows)
+	sortProviderComparisons(q.comparisons)
+	output.ProviderComparison = q.comparisons
 	output.Summary.ComparisonCount = len(output.ProviderComparison)
+	output.ConsistencyTolerance = consistencyTolerance
+	sortPeriodConsistency(q.flagged)
+	output.Summary.ConsistencyChecks, output.PeriodConsistency = q.checked, q.flagged
+	output.Summary.ConsistencyFlags = len(output.PeriodConsistency)
+	output.Summary.EstimatedObservations = q.estimated
 	return output
 }
 
@@ -1306,7 +1457,7 @@ func dominantLatestPeriod(latest []latestEntry) string {
 }
 
 type flowTotal struct {
-	export, imported     float64
+	export, imported     model.Cents
 	hasExport, hasImport bool
 	provider             string
 	reporter             string
@@ -1316,8 +1467,14 @@ type flowTotal struct {
 }
 
 func compareProviders(primaryProvider string, primaryRows, productRows []observationRow) []providerComparison {
-	primary := aggregateFlows(primaryRows, false)
-	secondary := aggregateFlows(productRows, true)
+	comparisons := compareFlowTotals(primaryProvider, aggregateFlows(primaryRows, false), aggregateFlows(productRows, true))
+	sortProviderComparisons(comparisons)
+	return comparisons
+}
+
+// compareFlowTotals pairs the primary and product totals that cover the same
+// reporter, partner and period, unsorted.
+func compareFlowTotals(primaryProvider string, primary, secondary map[string]*flowTotal) []providerComparison {
 	var comparisons []providerComparison
 	for key, left := range primary {
 		right, ok := secondary[key]
@@ -1332,10 +1489,14 @@ func compareProviders(primaryProvider string, primaryRows, productRows []observa
 		comparisons = append(comparisons, providerComparison{
 			ISO3: left.reporter, Partner: left.partner, PeriodType: string(left.periodType), Period: left.period,
 			PrimaryProvider: strings.ToLower(strings.TrimSpace(primaryProvider)), SecondaryProvider: right.provider,
-			PrimaryTradeUSD: primaryTrade, SecondaryTradeUSD: secondaryTrade,
-			DeltaRatio: (secondaryTrade - primaryTrade) / primaryTrade,
+			PrimaryTradeUSD: primaryTrade.USD(), SecondaryTradeUSD: secondaryTrade.USD(),
+			DeltaRatio: float64(secondaryTrade-primaryTrade) / float64(primaryTrade),
 		})
 	}
+	return comparisons
+}
+
+func sortProviderComparisons(comparisons []providerComparison) {
 	sort.Slice(comparisons, func(i, j int) bool {
 		if comparisons[i].ISO3 != comparisons[j].ISO3 {
 			return comparisons[i].ISO3 < comparisons[j].ISO3
@@ -1345,7 +1506,6 @@ func compareProviders(primaryProvider string, primaryRows, productRows []observa
 		}
 		return comparisons[i].Period < comparisons[j].Period
 	})
-	return comparisons
 }
 
 func aggregateFlows(rows []observationRow, sumProducts bool) map[string]*flowTotal {
@@ -1359,16 +1519,16 @@ func aggregateFlows(rows []observationRow, sumProducts bool) map[string]*flowTot
 		}
 		if row.Flow == model.FlowExport {
 			if sumProducts {
-				item.export += row.ValueUSD
+				item.export += row.Value
 			} else {
-				item.export = row.ValueUSD
+				item.export = row.Value
 			}
 			item.hasExport = true
 		} else if row.Flow == model.FlowImport {
 			if sumProducts {
-				item.imported += row.ValueUSD
+				item.imported += row.Value
 			} else {
-				item.imported = row.ValueUSD
+				item.imported = row.Value
 			}
 			item.hasImport = true
 		}
@@ -1380,6 +1540,20 @@ func augmentMeta(meta *metaFile, latest []latestEntry, series seriesFile, produc
 	if meta == nil {
 		return
 	}
+	augmentLatestMeta(meta, latest)
+	meta.SeriesReporterCount = len(series.Rows)
+	for _, row := range series.Rows {
+		meta.SeriesPointCount += len(row.Points)
+	}
+	meta.ProductProvider = products.Provider
+	meta.ProductClassification = products.Classification
+	meta.ProductLevel = products.Level
+	meta.ProductReporterCount = len(products.Reporters)
+	meta.ProductObservationCount = productObservationCount
+	meta.ContextStatus = contextStatus
+}
+
+func augmentLatestMeta(meta *metaFile, latest []latestEntry) {
 	meta.DominantPeriod = dominantLatestPeriod(latest)
 	for _, row := range latest {
 		if row.SamePeriod {
@@ -1393,16 +1567,6 @@ func augmentMeta(meta *metaFile, latest []latestEntry, series seriesFile, produc
 			}
 		}
 	}
-	meta.SeriesReporterCount = len(series.Rows)
-	for _, row := range series.Rows {
-		meta.SeriesPointCount += len(row.Points)
-	}
-	meta.ProductProvider = products.Provider
-	meta.ProductClassification = products.Classification
-	meta.ProductLevel = products.Level
-	meta.ProductReporterCount = len(products.Reporters)
-	meta.ProductObservationCount = productObservationCount
-	meta.ContextStatus = contextStatus
 }
 
 func augmentStrategicMeta(meta *metaFile, index strategicIndexFile) {
@@ -1438,6 +1602,34 @@ func augmentMatrixMeta(meta *metaFile, index matrixIndexFile) {
 	meta.MatrixObservationCount = index.ObservationCount
 }
 
+// augmentHistoryMeta records the size and period span of history.json, which
+// unlike series.json is not trimmed to the -series-years window.
+func augmentHistoryMeta(meta *metaFile, history seriesFile) {
+	if meta == nil {
+		return
+	}
+	meta.HistoryReporterCount = len(history.Rows)
+	meta.HistoryPointCount = 0
+	var first, last *seriesPoint
+	for rowIndex := range history.Rows {
+		points := history.Rows[rowIndex].Points
+		meta.HistoryPointCount += len(points)
+		if len(points) == 0 {
+			continue
+		}
+		if head := &points[0]; first == nil || comparePeriods(head.PeriodType, head.Period, first.PeriodType, first.Period) < 0 {
+			first = head
+		}
+		if tail := &points[len(points)-1]; last == nil || comparePeriods(tail.PeriodType, tail.Period, last.PeriodType, last.Period) > 0 {
+			last = tail
+		}
+	}
+	if first != nil {
+		meta.HistoryFirstPeriod = string(first.PeriodType) + ":" + first.Period
+		meta.HistoryLastPeriod = string(last.PeriodType) + ":" + last.Period
+	}
+}
+
 func augmentMirrorMeta(meta *metaFile, index mirrorIndexFile) {
 	if meta == nil {
 		return
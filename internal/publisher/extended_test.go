@@ -1,4 +1,4 @@
-package main
+package publisher
 
 import (
 	"fmt"
@@ -16,7 +16,7 @@ func TestBuildSeriesFileLimitsAnnualWindowAndMarksComparability(t *testing.T) {
 				continue
 			}
 			for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
-				rows = append(rows, observationRow{Provider: "wits", ReporterISO: "KOR", PartnerISO: partner, Flow: flow, PeriodType: model.PeriodYear, Period: fmt.Sprint(year), ValueUSD: float64(year)})
+				rows = append(rows, observationRow{Provider: "wits", ReporterISO: "KOR", PartnerISO: partner, Flow: flow, PeriodType: model.PeriodYear, Period: fmt.Sprint(year), Value: model.CentsFromUSD(float64(year))})
 			}
 		}
 	}
@@ -33,12 +33,32 @@ func TestBuildSeriesFileLimitsAnnualWindowAndMarksComparability(t *testing.T) {
 	}
 }
 
+func TestHistoryKeepsEveryStoredPeriod(t *testing.T) {
+	var rows []observationRow
+	for year := 2001; year <= 2023; year++ {
+		for _, partner := range []string{"USA", "CHN"} {
+			rows = append(rows, observationRow{Provider: "wits", ReporterISO: "KOR", PartnerISO: partner, Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: fmt.Sprint(year), Value: 100})
+		}
+	}
+	rows = append(rows, observationRow{Provider: "wits", ReporterISO: "JPN", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2022", Value: 100})
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+	if len(history.Rows) != 2 || len(history.Rows[1].Points) != 23 {
+		t.Fatalf("unexpected history shape: %#v", history.Rows)
+	}
+
+	var meta metaFile
+	augmentHistoryMeta(&meta, history)
+	if meta.HistoryReporterCount != 2 || meta.HistoryPointCount != 24 || meta.HistoryFirstPeriod != "Y:2001" || meta.HistoryLastPeriod != "Y:2023" {
+		t.Fatalf("unexpected history meta: %+v", meta)
+	}
+}
+
 func TestBuildProductFilesAggregatesFlowsWithoutChangingProvider(t *testing.T) {
 	rows := []observationRow{
-		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 60},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 40},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 30},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 70},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 6000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 4000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 3000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 7000},
 	}
 	index, files := buildProductFiles("2026-01-01T00:00:00Z", "comtrade", 2, []string{"USA", "CHN"}, rows, map[string]string{"85": "Electrical machinery"})
 	file := files["KOR"]
@@ -56,7 +76,7 @@ func TestBuildQualityFileFlagsMixedAndStalePeriods(t *testing.T) {
 		{ISO3: "KOR", SamePeriod: true, USA: partnerBlock{PeriodType: model.PeriodYear, Period: "2023"}, CHN: partnerBlock{PeriodType: model.PeriodYear, Period: "2023"}},
 		{ISO3: "BGD", SamePeriod: false, USA: partnerBlock{PeriodType: model.PeriodYear, Period: "2015"}, CHN: partnerBlock{}},
 	}
-	quality := buildQualityFile("2026-01-01T00:00:00Z", "wits", latest, nil, nil, nil)
+	quality := buildQualityFile("2026-01-01T00:00:00Z", "wits", latest, nil, nil, nil, defaultConsistencyTolerance)
 	if quality.DominantPeriod != "Y:2023" || quality.Summary.ComparableReporters != 1 || quality.Summary.IncomparableReporters != 1 || quality.Summary.MissingPartnerBlocks != 1 || quality.Summary.StalePartnerBlocks != 1 {
 		t.Fatalf("unexpected quality summary: %+v", quality)
 	}
@@ -71,7 +91,22 @@ func TestBuildDataCatalogSeparatesReadyAndPlannedResources(t *testing.T) {
 		"wits",
 		"success",
 		seriesFile{Rows: []reporterSeries{{ISO3: "KOR"}}},
+		seriesFile{},
+		countryIndexFile{},
+		rankingsFile{},
+		tiltFile{},
+		moversFile{},
+		forecastFile{},
+		volatilityFile{},
+		diversionFile{},
+		correlationFile{},
+		aggregatesFile{},
+		mapPropertiesFile{},
+		coverageFile{},
+		discrepancyFile{},
+		publishDiffFile{},
 		productIndexFile{Provider: "comtrade", Classification: "H6", Level: 2, Reporters: []string{"KOR"}},
+		rcaIndexFile{Provider: "comtrade", Classification: "H6", Level: 2},
 		strategicIndexFile{Provider: "comtrade", Level: 6, Partitions: []strategicPartition{{ReporterISO3: "KOR", Period: "2023"}}},
 		tariffIndexFile{Provider: "trains", Level: 6, Partitions: []tariffPartition{{ImporterISO3: "KOR", Year: "2023"}}},
 		matrixIndexFile{Provider: "comtrade", ProductCode: "TOTAL", Partitions: []matrixPartition{{ReporterISO3: "KOR", Period: "2023"}}},
@@ -126,12 +161,12 @@ func TestBuildMirrorFilesComparesBothReportedDirectionsWithoutChoosingTruth(t *t
 
 func TestBuildMatrixFilesAggregatesPartnerFlows(t *testing.T) {
 	rows := []observationRow{
-		{Provider: "comtrade", ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 60},
-		{Provider: "comtrade", ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 40},
-		{Provider: "comtrade", ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 80},
-		{Provider: "comtrade", ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "WLD", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 999},
+		{Provider: "comtrade", ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 6000},
+		{Provider: "comtrade", ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 4000},
+		{Provider: "comtrade", ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 8000},
+		{Provider: "comtrade", ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "WLD", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 99900},
 	}
-	index, files := buildMatrixFiles("2026-01-01T00:00:00Z", "comtrade", rows)
+	index, files := buildMatrixFiles("2026-01-01T00:00:00Z", "comtrade", rows, model.DefaultPartnerPolicy())
 	if index.ObservationCount != 3 || index.PartnerRowCount != 2 || len(index.Partitions) != 1 || index.Partitions[0].Href != "./KOR/2023.json" {
 		t.Fatalf("unexpected matrix index: %+v", index)
 	}
@@ -144,6 +179,34 @@ func TestBuildMatrixFilesAggregatesPartnerFlows(t *testing.T) {
 	}
 }
 
+func TestBuildMatrixFilesFollowsSpecialPartnerPolicy(t *testing.T) {
+	rows := []observationRow{
+		{ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 6000},
+		{ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "EUU", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 5000},
+		{ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "OTH", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 500},
+		{ProductCode: "TOTAL", ReporterISO: "KOR", PartnerISO: "XYZ", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 700},
+	}
+	_, files := buildMatrixFiles("2026-01-01T00:00:00Z", "comtrade", rows, model.DefaultPartnerPolicy())
+	if got := files["KOR/2023.json"].Rows; len(got) != 1 || got[0].PartnerISO3 != "USA" || got[0].Label != "" {
+		t.Fatalf("default policy rows = %+v, want only the country partner", got)
+	}
+
+	policy, err := model.ParsePartnerPolicy("eun=publish, OTH=store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, files := buildMatrixFiles("2026-01-01T00:00:00Z", "comtrade", rows, policy)
+	got := files["KOR/2023.json"].Rows
+	if len(got) != 2 || got[1].PartnerISO3 != "EUU" || got[1].Label != "European Union" || len(index.Partners) != 2 {
+		t.Fatalf("EUU=publish rows = %+v, partners = %v", got, index.Partners)
+	}
+	for _, value := range []string{"WLD=publish", "XYZ=store", "EUU=hide", "EUU"} {
+		if _, err := model.ParsePartnerPolicy(value); err == nil {
+			t.Fatalf("ParsePartnerPolicy(%q) accepted an invalid override", value)
+		}
+	}
+}
+
 func TestBuildTariffFilesPartitionsByImporterAndYear(t *testing.T) {
 	registry := []strategic.Product{{Code: "854231", Sector: "semiconductors", Label: "Processors", RevisionNote: "HS 2007+"}}
 	minRate := 2.0
@@ -171,10 +234,10 @@ func TestBuildStrategicFilesPartitionsByReporterAndYear(t *testing.T) {
 		{Code: "850760", Sector: "ev_batteries", Label: "Lithium-ion accumulators", RevisionNote: "compatible"},
 	}
 	rows := []observationRow{
-		{Provider: "comtrade", Classification: "H6", ProductCode: "854231", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 60},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "854231", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 40},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "850760", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 100},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "999999", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 500},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "854231", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 6000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "854231", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 4000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "850760", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 10000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "999999", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 50000},
 	}
 	index, files := buildStrategicFiles("2026-01-01T00:00:00Z", "comtrade", []string{"USA", "CHN"}, rows, registry)
 	if index.ObservationCount != 3 || len(index.Partitions) != 1 || index.Partitions[0].Href != "./KOR/2023.json" {
@@ -0,0 +1,331 @@
+package publisher
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// forecastMethod documents how forecast.json values are derived.
+const forecastMethod = "additive Holt (Y) or Holt-Winters (Q, M with two full seasons) exponential smoothing; smoothing weights chosen from a 0.1 grid by one-step-ahead squared error"
+
+// Forecast limits. A series needs forecastMinPoints consecutive comparable
+// periods; horizons beyond forecastMaxHorizon are too uncertain to publish.
+const (
+	forecastMinPoints  = 5
+	forecastMaxHorizon = 4
+)
+
+// forecastZ are the normal quantiles of the published 80% and 95% bands.
+const (
+	forecastZ80 = 1.2816
+	forecastZ95 = 1.9600
+)
+
+// forecastMetrics are the series forecast per reporter. share_cn is kept in
+// [0, 1] and trade values at or above zero.
+var forecastMetrics = []struct {
+	ID    string
+	Value func(seriesPoint) float64
+	Max   float64
+}{
+	{ID: "share_cn", Value: func(point seriesPoint) float64 { return point.ShareCN }, Max: 1},
+	{ID: "usa_trade", Value: func(point seriesPoint) float64 { return point.USA.Trade }, Max: math.Inf(1)},
+	{ID: "chn_trade", Value: func(point seriesPoint) float64 { return point.CHN.Trade }, Max: math.Inf(1)},
+}
+
+// forecastFile is forecast.json: projections of each reporter's China share
+// and USA and China trade for the next periods after its latest comparable
+// run, with 80% and 95% bands. They extrapolate recent trend and
+// seasonality only and know nothing about policy or shocks.
+type forecastFile struct {
+	SchemaVersion string           `json:"schema_version"`
+	GeneratedAt   string           `json:"generated_at"`
+	Provider      string           `json:"provider"`
+	Method        string           `json:"method"`
+	Horizon       int              `json:"horizon"`
+	Rows          []forecastSeries `json:"rows"`
+}
+
+type forecastSeries struct {
+	ISO3        string           `json:"iso3"`
+	Name        string           `json:"name,omitempty"`
+	PeriodType  model.PeriodType `json:"period_type"`
+	FirstPeriod string           `json:"first_period"`
+	LastPeriod  string           `json:"last_period"`
+	Points      int              `json:"points"`
+	Metrics     []forecastMetric `json:"metrics"`
+}
+
+type forecastMetric struct {
+	Metric   string          `json:"metric"`
+	Model    string          `json:"model"`
+	Alpha    float64         `json:"alpha"`
+	Beta     float64         `json:"beta"`
+	Gamma    *float64        `json:"gamma,omitempty"`
+	RMSE     float64         `json:"rmse"`
+	Forecast []forecastPoint `json:"forecast"`
+}
+
+type forecastPoint struct {
+	Period  string  `json:"period"`
+	Value   float64 `json:"value"`
+	Lower80 float64 `json:"lower_80"`
+	Upper80 float64 `json:"upper_80"`
+	Lower95 float64 `json:"lower_95"`
+	Upper95 float64 `json:"upper_95"`
+}
+
+func parseForecastHorizon(value int) (int, error) {
+	if value < 1 || value > forecastMaxHorizon {
+		return 0, fmt.Errorf("forecast horizon %d is outside 1-%d", value, forecastMaxHorizon)
+	}
+	return value, nil
+}
+
+// buildForecast fits each reporter's most frequent comparable period type,
+// finer on a tie, over its latest run of consecutive periods. Reporters
+// with fewer than forecastMinPoints such periods are omitted.
+func buildForecast(generatedAt, provider string, history seriesFile, latest []latestEntry, horizon int) forecastFile {
+	output := forecastFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Method:        forecastMethod,
+		Horizon:       horizon,
+		Rows:          []forecastSeries{},
+	}
+	names := make(map[string]string, len(latest))
+	for _, row := range latest {
+		names[row.ISO3] = row.Name
+	}
+	for _, row := range history.Rows {
+		periodType, points := forecastRun(row.Points)
+		if len(points) < forecastMinPoints {
+			continue
+		}
+		series := forecastSeries{
+			ISO3:        row.ISO3,
+			Name:        names[row.ISO3],
+			PeriodType:  periodType,
+			FirstPeriod: points[0].Period,
+			LastPeriod:  points[len(points)-1].Period,
+			Points:      len(points),
+		}
+		periods := make([]string, horizon)
+		period := series.LastPeriod
+		for step := range periods {
+			period = followingPeriod(periodType, period)
+			periods[step] = period
+		}
+		for _, metric := range forecastMetrics {
+			values := make([]float64, len(points))
+			for index, point := range points {
+				values[index] = metric.Value(point)
+			}
+			fit := fitSmoothing(values, seasonLength(periodType))
+			forecast := forecastMetric{
+				Metric: metric.ID,
+				Model:  fit.model(),
+				Alpha:  fit.alpha,
+				Beta:   fit.beta,
+				RMSE:   roundForecast(fit.sigma),
+			}
+			if fit.season > 0 {
+				gamma := fit.gamma
+				forecast.Gamma = &gamma
+			}
+			for step, period := range periods {
+				value, spread := fit.forecast(step + 1)
+				clamp := func(value float64) float64 { return roundForecast(math.Min(metric.Max, math.Max(0, value))) }
+				forecast.Forecast = append(forecast.Forecast, forecastPoint{
+					Period:  period,
+					Value:   clamp(value),
+					Lower80: clamp(value - forecastZ80*spread),
+					Upper80: clamp(value + forecastZ80*spread),
+					Lower95: clamp(value - forecastZ95*spread),
+					Upper95: clamp(value + forecastZ95*spread),
+				})
+			}
+			series.Metrics = append(series.Metrics, forecast)
+		}
+		output.Rows = append(output.Rows, series)
+	}
+	return output
+}
+
+// forecastRun picks the period type to forecast and returns its latest run
+// of consecutive comparable points in period order. A gap ends the run, so
+// smoothing never steps over missing periods.
+func forecastRun(points []seriesPoint) (model.PeriodType, []seriesPoint) {
+	periodType := mostComparableType(points)
+	var run []seriesPoint
+	for _, point := range points {
+		if point.PeriodType == periodType && point.Comparable && point.Total > 0 {
+			run = append(run, point)
+		}
+	}
+	sort.Slice(run, func(i, j int) bool {
+		return periodKey(periodType, run[i].Period) < periodKey(periodType, run[j].Period)
+	})
+	start := len(run) - 1
+	for start > 0 && precedingPeriod(periodType, run[start].Period) == run[start-1].Period {
+		start--
+	}
+	if start < 0 {
+		return periodType, nil
+	}
+	return periodType, run[start:]
+}
+
+// followingPeriod is the period immediately after period: the next month,
+// quarter, or year.
+func followingPeriod(periodType model.PeriodType, period string) string {
+	return model.NextPeriod(periodType, period)
+}
+
+// seasonLength is the number of periods per year for seasonal models.
+func seasonLength(periodType model.PeriodType) int {
+	switch periodType {
+	case model.PeriodMonth:
+		return 12
+	case model.PeriodQuarter:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// smoothingFit is a fitted additive exponential smoothing model: level,
+// trend, and, when season is positive, the last season of seasonal terms.
+type smoothingFit struct {
+	alpha, beta, gamma float64
+	season             int
+	level, trend       float64
+	seasonal           []float64
+	sigma              float64
+}
+
+func (f smoothingFit) model() string {
+	if f.season > 0 {
+		return "holt_winters"
+	}
+	return "holt"
+}
+
+// forecast returns the point forecast h steps ahead and its standard error,
+// using the additive ETS prediction variance
+// σ²(1 + Σ_{j<h} (α(1 + jβ) + γ(1 − α)·[j mod m = 0])²). runSmoothing
+// updates the seasonal terms against the new level, and that γ is (1 − α)
+// times the γ of the error-correction form the variance is derived for.
+func (f smoothingFit) forecast(h int) (float64, float64) {
+	value := f.level + float64(h)*f.trend
+	if f.season > 0 {
+		value += f.seasonal[(h-1)%f.season]
+	}
+	variance := 1.0
+	for j := 1; j < h; j++ {
+		c := f.alpha * (1 + float64(j)*f.beta)
+		if f.season > 0 && j%f.season == 0 {
+			c += f.gamma * (1 - f.alpha)
+		}
+		variance += c * c
+	}
+	return value, f.sigma * math.Sqrt(variance)
+}
+
+// fitSmoothing grid-searches the smoothing weights that minimize the
+// one-step-ahead squared error. A seasonal model is fit only with two full
+// seasons of data; otherwise it falls back to Holt's linear trend.
+func fitSmoothing(values []float64, season int) smoothingFit {
+	if season > 0 && len(values) < 2*season {
+		season = 0
+	}
+	gammas := []float64{0}
+	if season > 0 {
+		gammas = forecastGrid()
+	}
+	best := smoothingFit{sigma: math.Inf(1)}
+	for _, alpha := range forecastGrid() {
+		for _, beta := range forecastGrid() {
+			for _, gamma := range gammas {
+				fit := runSmoothing(values, season, alpha, beta, gamma)
+				if fit.sigma < best.sigma {
+					best = fit
+				}
+			}
+		}
+	}
+	return best
+}
+
+func forecastGrid() []float64 {
+	return []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+}
+
+// runSmoothing applies the additive recursions with fixed weights. Holt
+// starts from the second value and first difference; Holt-Winters from the
+// change between the first two season means per period, the first season's
+// mean carried along that trend to its last period, and the first season's
+// deviations from the trend line.
+func runSmoothing(values []float64, season int, alpha, beta, gamma float64) smoothingFit {
+	fit := smoothingFit{alpha: alpha, beta: beta, gamma: gamma, season: season}
+	var start int
+	var seasonal []float64
+	if season > 0 {
+		first, second := mean(values[:season]), mean(values[season:2*season])
+		fit.trend = (second - first) / float64(season)
+		center := float64(season-1) / 2
+		fit.level = first + fit.trend*center
+		for index, value := range values[:season] {
+			seasonal = append(seasonal, value-(first+fit.trend*(float64(index)-center)))
+		}
+		start = season
+	} else {
+		fit.level, fit.trend = values[1], values[1]-values[0]
+		start = 2
+	}
+	var sse float64
+	count := 0
+	for t := start; t < len(values); t++ {
+		var seasonTerm float64
+		if season > 0 {
+			seasonTerm = seasonal[t-season]
+		}
+		err := values[t] - (fit.level + fit.trend + seasonTerm)
+		sse += err * err
+		count++
+		level := alpha*(values[t]-seasonTerm) + (1-alpha)*(fit.level+fit.trend)
+		fit.trend = beta*(level-fit.level) + (1-beta)*fit.trend
+		fit.level = level
+		if season > 0 {
+			seasonal = append(seasonal, gamma*(values[t]-level)+(1-gamma)*seasonTerm)
+		}
+	}
+	if season > 0 {
+		fit.seasonal = seasonal[len(seasonal)-season:]
+	}
+	fit.sigma = math.Sqrt(sse / float64(max(count, 1)))
+	return fit
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// roundForecast keeps six significant digits, which is more precision than
+// any projection carries.
+func roundForecast(value float64) float64 {
+	if value == 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+		return value
+	}
+	scale := math.Pow(10, 5-math.Floor(math.Log10(math.Abs(value))))
+	return math.Round(value*scale) / scale
+}
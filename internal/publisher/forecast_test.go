@@ -0,0 +1,137 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestFitSmoothingFollowsTrendAndSeason(t *testing.T) {
+	var linear []float64
+	for step := 0; step < 8; step++ {
+		linear = append(linear, 10+2*float64(step))
+	}
+	fit := fitSmoothing(linear, 0)
+	if fit.model() != "holt" || fit.sigma > 1e-9 {
+		t.Fatalf("expected an exact Holt fit of a straight line, got %+v", fit)
+	}
+	if value, spread := fit.forecast(3); math.Abs(value-30) > 1e-9 || spread > 1e-9 {
+		t.Fatalf("forecast(3) = %v ± %v, want 30", value, spread)
+	}
+
+	season := []float64{-5, 10, 0, -5}
+	var quarterly []float64
+	for step := 0; step < 16; step++ {
+		quarterly = append(quarterly, 100+float64(step)+season[step%4])
+	}
+	fit = fitSmoothing(quarterly, 4)
+	if fit.model() != "holt_winters" || fit.sigma > 1e-9 {
+		t.Fatalf("expected an exact Holt-Winters fit, got %+v", fit)
+	}
+	for h := 1; h <= 4; h++ {
+		want := 100 + float64(15+h) + season[(15+h)%4]
+		if value, _ := fit.forecast(h); math.Abs(value-want) > 1e-9 {
+			t.Fatalf("forecast(%d) = %v, want %v", h, value, want)
+		}
+	}
+	if fit := fitSmoothing(quarterly[:7], 4); fit.model() != "holt" {
+		t.Fatalf("expected Holt without two full seasons, got %s", fit.model())
+	}
+
+	noisy := []float64{10, 14, 9, 15, 11, 16, 10}
+	fit = fitSmoothing(noisy, 0)
+	_, near := fit.forecast(1)
+	_, far := fit.forecast(4)
+	if fit.sigma <= 0 || near <= 0 || far <= near {
+		t.Fatalf("expected bands that widen with the horizon, got %v then %v", near, far)
+	}
+}
+
+func TestSmoothingForecastSeasonalVariance(t *testing.T) {
+	// One season ahead, the error enters through the level (α) and through
+	// the seasonal term, which takes γ of what the level update leaves, γ(1-α):
+	// 1 + 3·0.5² + (0.5 + 0.4·0.5)² = 2.24.
+	fit := smoothingFit{alpha: 0.5, gamma: 0.4, season: 4, seasonal: make([]float64, 4), sigma: 1}
+	if _, spread := fit.forecast(5); math.Abs(spread-math.Sqrt(2.24)) > 1e-12 {
+		t.Fatalf("forecast(5) spread = %v, want %v", spread, math.Sqrt(2.24))
+	}
+}
+
+func TestBuildForecastUsesLatestConsecutiveRun(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter, partner, period string, value float64) {
+		for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+			rows = append(rows, observationRow{ReporterISO: reporter, PartnerISO: partner, Flow: flow, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(value)})
+		}
+	}
+	// KOR has a gap in 2017, so only 2018-2024 are fit. China's share rises
+	// quickly enough that an unclamped projection would pass 100%.
+	add("KOR", "USA", "2015", 50)
+	add("KOR", "CHN", "2015", 50)
+	for index, period := range []string{"2018", "2019", "2020", "2021", "2022", "2023", "2024"} {
+		add("KOR", "USA", period, 40-5*float64(index))
+		add("KOR", "CHN", period, 60+5*float64(index))
+	}
+	for _, period := range []string{"2021", "2022", "2023", "2024"} {
+		add("JPN", "USA", period, 10)
+		add("JPN", "CHN", period, 10)
+	}
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+
+	output := buildForecast("2026-01-01T00:00:00Z", "WITS", history, []latestEntry{{ISO3: "KOR", Name: "Korea"}}, 4)
+	if output.Provider != "wits" || output.Horizon != 4 || output.Method != forecastMethod {
+		t.Fatalf("unexpected forecast header: %+v", output)
+	}
+	if len(output.Rows) != 1 {
+		t.Fatalf("rows = %+v, want only KOR because JPN has four points", output.Rows)
+	}
+	row := output.Rows[0]
+	if row.Name != "Korea" || row.PeriodType != model.PeriodYear || row.FirstPeriod != "2018" || row.LastPeriod != "2024" || row.Points != 7 || len(row.Metrics) != 3 {
+		t.Fatalf("unexpected series %+v", row)
+	}
+	share := row.Metrics[0]
+	if share.Metric != "share_cn" || share.Model != "holt" || share.Gamma != nil || len(share.Forecast) != 4 {
+		t.Fatalf("unexpected share forecast %+v", share)
+	}
+	for index, point := range share.Forecast {
+		if want := []string{"2025", "2026", "2027", "2028"}[index]; point.Period != want {
+			t.Fatalf("period %d = %s, want %s", index, point.Period, want)
+		}
+		if point.Upper95 > 1 || point.Lower95 > point.Lower80 || point.Lower80 > point.Value || point.Value > point.Upper80 || point.Upper80 > point.Upper95 {
+			t.Fatalf("bands out of order or past 100%%: %+v", point)
+		}
+	}
+	if share.Forecast[0].Value != 0.95 || share.Forecast[3].Value != 1 {
+		t.Fatalf("share projection = %+v, want 0.95 then clamped to 1", share.Forecast)
+	}
+	usa := row.Metrics[1]
+	if usa.Metric != "usa_trade" || usa.Forecast[3].Value != 0 {
+		t.Fatalf("USA trade projection = %+v, want clamped at 0", usa.Forecast)
+	}
+}
+
+func TestFollowingPeriodAndHorizon(t *testing.T) {
+	cases := []struct {
+		periodType model.PeriodType
+		period     string
+		want       string
+	}{
+		{model.PeriodYear, "2024", "2025"},
+		{model.PeriodQuarter, "2024-Q3", "2024-Q4"},
+		{model.PeriodQuarter, "2024-Q4", "2025-Q1"},
+		{model.PeriodMonth, "2024-09", "2024-10"},
+		{model.PeriodMonth, "2024-12", "2025-01"},
+		{model.PeriodYear, "bad", ""},
+	}
+	for _, tc := range cases {
+		if got := followingPeriod(tc.periodType, tc.period); got != tc.want {
+			t.Fatalf("followingPeriod(%s, %s) = %q, want %q", tc.periodType, tc.period, got, tc.want)
+		}
+	}
+	for _, horizon := range []int{0, 5} {
+		if _, err := parseForecastHorizon(horizon); err == nil {
+			t.Fatalf("expected horizon %d to be rejected", horizon)
+		}
+	}
+}
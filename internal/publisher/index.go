@@ -0,0 +1,69 @@
+package publisher
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+)
+
+// artifactIndexName is the manifest of every file the build wrote. It is not
+// listed in itself.
+const artifactIndexName = "index.json"
+
+type artifactIndexFile struct {
+	SchemaVersion string           `json:"schema_version"`
+	GeneratedAt   string           `json:"generated_at"`
+	FileCount     int              `json:"file_count"`
+	TotalBytes    int64            `json:"total_bytes"`
+	Files         []artifactRecord `json:"files"`
+}
+
+// artifactRecord describes one published file. SchemaVersion and GeneratedAt
+// are copied from JSON artifacts that carry them, so a file left over from an
+// older publish stands out against the index's own generated_at.
+type artifactRecord struct {
+	Path          string `json:"path"`
+	Size          int64  `json:"size"`
+	SHA256        string `json:"sha256"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+	GeneratedAt   string `json:"generated_at,omitempty"`
+}
+
+func newArtifactRecord(relative, hash string, body []byte) artifactRecord {
+	record := artifactRecord{Path: relative, Size: int64(len(body)), SHA256: hash}
+	if path.Ext(relative) == ".json" {
+		var header struct {
+			SchemaVersion string `json:"schema_version"`
+			GeneratedAt   string `json:"generated_at"`
+		}
+		if json.Unmarshal(body, &header) == nil {
+			record.SchemaVersion, record.GeneratedAt = header.SchemaVersion, header.GeneratedAt
+		}
+	}
+	return record
+}
+
+// buildArtifactIndex lists records by path. A path written twice keeps its
+// last record.
+func buildArtifactIndex(generatedAt string, records []artifactRecord) artifactIndexFile {
+	byPath := make(map[string]artifactRecord, len(records))
+	for _, record := range records {
+		if record.Path != artifactIndexName {
+			byPath[record.Path] = record
+		}
+	}
+	output := artifactIndexFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Files:         make([]artifactRecord, 0, len(byPath)),
+	}
+	for _, record := range byPath {
+		output.Files = append(output.Files, record)
+		output.TotalBytes += record.Size
+	}
+	sort.Slice(output.Files, func(i, j int) bool {
+		return output.Files[i].Path < output.Files[j].Path
+	})
+	output.FileCount = len(output.Files)
+	return output
+}
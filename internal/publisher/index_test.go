@@ -0,0 +1,27 @@
+package publisher
+
+import "testing"
+
+func TestBuildArtifactIndexListsLastWriteOfEachFile(t *testing.T) {
+	records := []artifactRecord{
+		newArtifactRecord("meta.json", "old", []byte(`{"schema_version":"2.0","generated_at":"2026-01-01T00:00:00Z"}`)),
+		newArtifactRecord("latest.csv", "csv", []byte("iso3\nDEU\n")),
+		newArtifactRecord("meta.json", "new", []byte(`{"schema_version":"2.0","generated_at":"2026-02-01T00:00:00Z","extra":1}`)),
+		newArtifactRecord(artifactIndexName, "self", []byte(`{}`)),
+	}
+	index := buildArtifactIndex("2026-02-01T00:00:00Z", records)
+
+	if index.FileCount != 2 || len(index.Files) != 2 {
+		t.Fatalf("unexpected files: %+v", index.Files)
+	}
+	csv, meta := index.Files[0], index.Files[1]
+	if csv.Path != "latest.csv" || csv.Size != 9 || csv.SchemaVersion != "" || csv.GeneratedAt != "" {
+		t.Fatalf("unexpected csv record: %+v", csv)
+	}
+	if meta.Path != "meta.json" || meta.SHA256 != "new" || meta.SchemaVersion != "2.0" || meta.GeneratedAt != "2026-02-01T00:00:00Z" {
+		t.Fatalf("unexpected meta record: %+v", meta)
+	}
+	if index.TotalBytes != csv.Size+meta.Size {
+		t.Fatalf("total_bytes = %d", index.TotalBytes)
+	}
+}
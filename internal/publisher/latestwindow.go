@@ -0,0 +1,72 @@
+package publisher
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// latestWindowSupported reports whether buildLatest gives the same rows from
+// loadLatestObservations as from every observation. Common and period-type
+// alignment and the downgrade policy can reach periods more than a year
+// before the latest one, so they still need the full history.
+func latestWindowSupported(alignment, mixed string) bool {
+	return alignment == alignLatest && mixed != mixedDowngrade
+}
+
+// loadLatestObservations loads only the observations buildLatest reads. For
+// each reporter, partner, and flow, SQLite finds the latest period the way
+// comparePeriods orders them (finest period type first, then newest) and
+// returns that calendar year and the one before it, which covers every
+// growth basis and the trailing twelve months. Older periods never leave the
+// database, so the latest rows no longer grow with the stored history.
+func loadLatestObservations(dbPath, provider string, partners, reporters []string) ([]observationRow, error) {
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, errors.New("db path is required")
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	quality, err := observationQualityColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	value, err := observationValueColumn(db)
+	if err != nil {
+		return nil, err
+	}
+	filter, args := totalsFilter(provider, partners, reporters)
+	rows, err := db.QueryContext(context.Background(), `
+		WITH totals AS (
+			SELECT provider, reporter_iso3, partner_iso3, flow, period_type, period, `+value+` AS value_cents, `+quality+`,
+				CASE UPPER(period_type) WHEN 'M' THEN 3 WHEN 'Q' THEN 2 WHEN 'Y' THEN 1 ELSE 0 END AS priority,
+				CAST(substr(period, 1, 4) AS INTEGER) AS year
+			FROM trade_observations
+			WHERE `+filter+`
+		),
+		finest AS (
+			SELECT reporter_iso3, partner_iso3, flow, year,
+				RANK() OVER (PARTITION BY reporter_iso3, partner_iso3, flow ORDER BY priority DESC) AS rank
+			FROM totals
+		),
+		latest AS (
+			SELECT reporter_iso3, partner_iso3, flow, MAX(year) AS year
+			FROM finest
+			WHERE rank = 1
+			GROUP BY reporter_iso3, partner_iso3, flow
+		)
+		SELECT t.provider, t.reporter_iso3, t.partner_iso3, t.flow, t.period_type, t.period, t.value_cents, t.estimated, t.quality_note
+		FROM totals t
+		JOIN latest l ON l.reporter_iso3 = t.reporter_iso3 AND l.partner_iso3 = t.partner_iso3 AND l.flow = t.flow
+		WHERE t.year >= l.year - 1
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanObservations(rows)
+}
@@ -0,0 +1,81 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/store/sqlite"
+)
+
+func TestLoadLatestObservationsMatchesFullHistory(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tradegravity.db")
+	st, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var observations []model.Observation
+	add := func(reporter, partner string, flow model.Flow, periodType model.PeriodType, period string, value float64) {
+		observations = append(observations, model.Observation{
+			Provider: "wits", ReporterISO3: reporter, PartnerISO3: partner, Flow: flow,
+			PeriodType: periodType, Period: period, ValueUSD: value,
+		})
+	}
+	for year := 2010; year <= 2023; year++ {
+		for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+			add("KOR", "USA", flow, model.PeriodYear, fmt.Sprint(year), float64(year))
+			add("KOR", "CHN", flow, model.PeriodYear, fmt.Sprint(year), float64(2*year))
+			add("JPN", "CHN", flow, model.PeriodYear, fmt.Sprint(year), float64(3*year))
+		}
+		for quarter := 1; quarter <= 4; quarter++ {
+			add("KOR", "CHN", model.FlowExport, model.PeriodQuarter, fmt.Sprintf("%d-Q%d", year, quarter), float64(year+quarter))
+		}
+	}
+	for year := 2019; year <= 2024; year++ {
+		for month := 1; month <= 12; month++ {
+			add("KOR", "USA", model.FlowExport, model.PeriodMonth, fmt.Sprintf("%d-%02d", year, month), float64(year*100+month))
+		}
+	}
+	// JPN-USA imports stop early, so their latest period is far behind exports.
+	add("JPN", "USA", model.FlowExport, model.PeriodYear, "2023", 50)
+	add("JPN", "USA", model.FlowExport, model.PeriodYear, "2022", 40)
+	add("JPN", "USA", model.FlowImport, model.PeriodYear, "2012", 30)
+	if err := st.UpsertObservations(context.Background(), observations); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+
+	partners := []string{"USA", "CHN"}
+	full, err := loadObservations(dbPath, "wits", partners, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window, err := loadLatestObservations(dbPath, "wits", partners, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(window) >= len(full)/2 {
+		t.Fatalf("window kept %d of %d observations, want far fewer", len(window), len(full))
+	}
+	for _, basis := range []string{growthYoY, growthMoM, growthQoQ, growthYTD} {
+		for _, mixed := range []string{mixedAllow, mixedIncomparable} {
+			want := buildLatest(full, partners, basis, alignLatest, mixed)
+			got := buildLatest(window, partners, basis, alignLatest, mixed)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("basis %s, mixed %s: windowed latest rows differ\n got %+v\nwant %+v", basis, mixed, got, want)
+			}
+		}
+	}
+}
+
+func TestLatestWindowSupportedNeedsLatestAlignment(t *testing.T) {
+	if !latestWindowSupported(alignLatest, mixedAllow) || !latestWindowSupported(alignLatest, mixedIncomparable) {
+		t.Fatal("latest alignment should use the window")
+	}
+	if latestWindowSupported(alignCommon, mixedAllow) || latestWindowSupported(alignLatest, mixedDowngrade) {
+		t.Fatal("common alignment and downgrade need the full history")
+	}
+}
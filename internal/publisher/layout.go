@@ -0,0 +1,163 @@
+package publisher
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// layoutPlaceholders are the fields an -layout template can use. A default
+// artifact path such as strategic-hs6/KOR/2023.json has artifact
+// strategic-hs6, iso3 KOR, period 2023, name 2023, and ext json; a top-level
+// file such as latest.json has artifact and name latest and no iso3.
+var layoutPlaceholders = []string{"artifact", "iso3", "period", "name", "ext"}
+
+var (
+	layoutPlaceholderPattern = regexp.MustCompile(`\{([a-z0-9]+)\}`)
+	layoutISO3Pattern        = regexp.MustCompile(`^[A-Z]{3}$`)
+	layoutPeriodPattern      = regexp.MustCompile(`^\d{4}(-Q[1-4]|-\d{2})?$`)
+)
+
+// outputLayout maps default artifact paths to templated ones. Each file uses
+// the first template whose placeholders it can fill and whose extension
+// matches; files no template fits keep their default path. Top-level JSON
+// documents always keep theirs: uploads, tradegravity serve and its API, and
+// the validators open latest.json, meta.json, index.json, catalog.json, and
+// the rest by name, and the indexes lead readers to everything else. The
+// zero value keeps every default path.
+type outputLayout struct {
+	templates []string
+}
+
+func parseLayout(value string) (outputLayout, error) {
+	var layout outputLayout
+	for _, item := range strings.Split(value, ",") {
+		template := strings.TrimSpace(item)
+		if template == "" {
+			continue
+		}
+		if strings.HasPrefix(template, "/") || strings.Contains(template, "..") || strings.Contains(template, `\`) {
+			return outputLayout{}, fmt.Errorf("layout template %q must be a relative path inside -out", template)
+		}
+		if !strings.Contains(template, "{ext}") && path.Ext(template) == "" {
+			return outputLayout{}, fmt.Errorf("layout template %q needs a file extension or {ext}", template)
+		}
+		for _, match := range layoutPlaceholderPattern.FindAllStringSubmatch(template, -1) {
+			known := false
+			for _, placeholder := range layoutPlaceholders {
+				known = known || match[1] == placeholder
+			}
+			if !known {
+				return outputLayout{}, fmt.Errorf("layout template %q has unknown placeholder {%s} (expected %s)", template, match[1], strings.Join(layoutPlaceholders, ", "))
+			}
+		}
+		layout.templates = append(layout.templates, template)
+	}
+	return layout, nil
+}
+
+func (l outputLayout) enabled() bool {
+	return len(l.templates) > 0
+}
+
+// path returns where the artifact at the slash-separated default path
+// relative is written.
+func (l outputLayout) path(relative string) string {
+	if !l.enabled() || !strings.Contains(relative, "/") && path.Ext(relative) == ".json" {
+		return relative
+	}
+	fields := layoutFields(relative)
+	for _, template := range l.templates {
+		if !strings.Contains(template, "{ext}") && path.Ext(template) != "."+fields["ext"] {
+			continue
+		}
+		complete := true
+		mapped := layoutPlaceholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+			value := fields[strings.Trim(placeholder, "{}")]
+			complete = complete && value != ""
+			return value
+		})
+		if complete {
+			return path.Clean(mapped)
+		}
+	}
+	return relative
+}
+
+// href rewrites a relative href written into the index at the default path
+// indexRelative, so it still resolves after both files are moved.
+func (l outputLayout) href(indexRelative, href string) string {
+	if !l.enabled() || href == "" {
+		return href
+	}
+	target := path.Join(path.Dir(indexRelative), href)
+	from := strings.Split(path.Dir(l.path(indexRelative)), "/")
+	to := strings.Split(l.path(target), "/")
+	if from[0] == "." {
+		from = nil
+	}
+	common := 0
+	for common < len(from) && common < len(to)-1 && from[common] == to[common] {
+		common++
+	}
+	parts := make([]string, 0, len(from)-common+len(to)-common)
+	for range from[common:] {
+		parts = append(parts, "..")
+	}
+	parts = append(parts, to[common:]...)
+	if parts[0] == ".." {
+		return strings.Join(parts, "/")
+	}
+	return "./" + strings.Join(parts, "/")
+}
+
+func layoutFields(relative string) map[string]string {
+	segments := strings.Split(relative, "/")
+	base := segments[len(segments)-1]
+	dirs := segments[:len(segments)-1]
+	ext := strings.TrimPrefix(path.Ext(base), ".")
+	name := strings.TrimSuffix(base, path.Ext(base))
+	fields := map[string]string{"name": name, "ext": ext}
+	switch {
+	case len(dirs) >= 2 && layoutISO3Pattern.MatchString(dirs[len(dirs)-1]) && layoutPeriodPattern.MatchString(name):
+		fields["iso3"], fields["period"] = dirs[len(dirs)-1], name
+		dirs = dirs[:len(dirs)-1]
+	case len(dirs) >= 1 && layoutISO3Pattern.MatchString(name):
+		fields["iso3"] = name
+	}
+	fields["artifact"] = strings.Join(dirs, "/")
+	if fields["artifact"] == "" {
+		fields["artifact"] = name
+	}
+	return fields
+}
+
+// relinkIndexes rewrites the hrefs in every partition index and the catalog
+// for layout.
+func relinkIndexes(layout outputLayout, catalog *dataCatalogFile, countries *countryIndexFile, strategicIndex *strategicIndexFile, semiconductorMonthlyIndex *semiconductorMonthlyIndexFile, tariffIndex *tariffIndexFile, matrixIndex *matrixIndexFile, mirrorIndex *mirrorIndexFile) {
+	if !layout.enabled() {
+		return
+	}
+	for index := range catalog.Resources {
+		catalog.Resources[index].Href = layout.href("catalog.json", catalog.Resources[index].Href)
+	}
+	for index := range countries.Partitions {
+		countries.Partitions[index].Href = layout.href("countries/index.json", countries.Partitions[index].Href)
+	}
+	for index := range strategicIndex.Partitions {
+		strategicIndex.Partitions[index].Href = layout.href("strategic-hs6/index.json", strategicIndex.Partitions[index].Href)
+	}
+	for index := range semiconductorMonthlyIndex.Partitions {
+		semiconductorMonthlyIndex.Partitions[index].Href = layout.href("semiconductors/monthly/index.json", semiconductorMonthlyIndex.Partitions[index].Href)
+	}
+	for index := range tariffIndex.Partitions {
+		tariffIndex.Partitions[index].Href = layout.href("tariffs/index.json", tariffIndex.Partitions[index].Href)
+	}
+	for index := range matrixIndex.Partitions {
+		matrixIndex.Partitions[index].Href = layout.href("bilateral-matrix/index.json", matrixIndex.Partitions[index].Href)
+	}
+	for index := range mirrorIndex.Partitions {
+		mirrorIndex.Partitions[index].Href = layout.href("mirror/index.json", mirrorIndex.Partitions[index].Href)
+	}
+}
@@ -0,0 +1,93 @@
+package publisher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputLayoutMapsDefaultPaths(t *testing.T) {
+	layout, err := parseLayout("data/{artifact}/{iso3}/{period}.json, data/{artifact}/{iso3}.json, data/{name}.{ext}")
+	if err != nil {
+		t.Fatalf("parseLayout() error = %v", err)
+	}
+	for _, tt := range []struct{ relative, want string }{
+		{"latest.json", "latest.json"},
+		{"meta.json", "meta.json"},
+		{"latest.csv", "data/latest.csv"},
+		{"countries/KOR.json", "data/countries/KOR.json"},
+		{"semiconductors/monthly/KOR.json", "data/semiconductors/monthly/KOR.json"},
+		{"strategic-hs6/KOR/2023.json", "data/strategic-hs6/KOR/2023.json"},
+		{"countries/index.json", "data/index.json"},
+	} {
+		if got := layout.path(tt.relative); got != tt.want {
+			t.Fatalf("path(%q) = %q, want %q", tt.relative, got, tt.want)
+		}
+	}
+	if got := (outputLayout{}).path("countries/KOR.json"); got != "countries/KOR.json" {
+		t.Fatalf("zero layout path = %q, want the default", got)
+	}
+}
+
+func TestOutputLayoutSkipsTemplatesItCannotFill(t *testing.T) {
+	layout, err := parseLayout("site/{iso3}.json")
+	if err != nil {
+		t.Fatalf("parseLayout() error = %v", err)
+	}
+	if got := layout.path("latest.json"); got != "latest.json" {
+		t.Fatalf("path(latest.json) = %q, want the default path without an iso3", got)
+	}
+	if got := layout.path("latest.csv"); got != "latest.csv" {
+		t.Fatalf("path(latest.csv) = %q, want the default path for another extension", got)
+	}
+	if got := layout.path("products/KOR.json"); got != "site/KOR.json" {
+		t.Fatalf("path(products/KOR.json) = %q, want site/KOR.json", got)
+	}
+}
+
+func TestOutputLayoutRelinksHrefs(t *testing.T) {
+	layout, err := parseLayout("v1/{artifact}-{iso3}-{period}.json,v1/{artifact}/{name}.json")
+	if err != nil {
+		t.Fatalf("parseLayout() error = %v", err)
+	}
+	if got := layout.href("bilateral-matrix/index.json", "./KOR/2023.json"); got != "../bilateral-matrix-KOR-2023.json" {
+		t.Fatalf("matrix href = %q", got)
+	}
+	if got := layout.href("catalog.json", "./countries/index.json"); got != "./v1/countries/index.json" {
+		t.Fatalf("catalog href = %q", got)
+	}
+	if got := layout.href("catalog.json", "./latest.json"); got != "./latest.json" {
+		t.Fatalf("catalog href = %q, want top-level JSON left in place", got)
+	}
+	if got := (outputLayout{}).href("countries/index.json", "./KOR.json"); got != "./KOR.json" {
+		t.Fatalf("zero layout href = %q, want it unchanged", got)
+	}
+}
+
+func TestParseLayoutRejectsUnsafeTemplates(t *testing.T) {
+	for _, value := range []string{"../{name}.json", "/srv/{name}.json", "data/{country}.json", "data/{name}"} {
+		if _, err := parseLayout(value); err == nil {
+			t.Fatalf("parseLayout(%q) error = nil", value)
+		}
+	}
+}
+
+func TestArtifactWriterRejectsLayoutCollisions(t *testing.T) {
+	root := t.TempDir()
+	writer, err := newArtifactWriter(root)
+	if err != nil {
+		t.Fatalf("newArtifactWriter() error = %v", err)
+	}
+	writer.layout, _ = parseLayout("data/{name}.json")
+	if err := writer.write(filepath.Join(root, "countries", "index.json"), []byte("{}")); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "data", "index.json")); err != nil {
+		t.Fatalf("templated file missing: %v", err)
+	}
+	err = writer.write(filepath.Join(root, "products", "index.json"), []byte("{}"))
+	if err == nil || !strings.Contains(err.Error(), "layout writes both") {
+		t.Fatalf("write() error = %v, want a collision", err)
+	}
+}
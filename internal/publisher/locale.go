@@ -0,0 +1,144 @@
+package publisher
+
+import (
+	"fmt"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// supportedLocales are the -locales values with label tables. English labels
+// use the source names; Korean labels use the reporters table name_ko.
+var supportedLocales = map[string]bool{"en": true, "ko": true}
+
+// koreanRegions and koreanIncomeGroups translate the World Bank context
+// labels. Unknown labels are published unchanged.
+var koreanRegions = map[string]string{
+	"East Asia & Pacific":        "동아시아·태평양",
+	"Europe & Central Asia":      "유럽·중앙아시아",
+	"Latin America & Caribbean":  "라틴아메리카·카리브",
+	"Middle East & North Africa": "중동·북아프리카",
+	"North America":              "북미",
+	"South Asia":                 "남아시아",
+	"Sub-Saharan Africa":         "사하라 이남 아프리카",
+}
+
+var koreanIncomeGroups = map[string]string{
+	"High income":         "고소득",
+	"Upper middle income": "중상위 소득",
+	"Lower middle income": "중하위 소득",
+	"Low income":          "저소득",
+}
+
+var englishMonths = [...]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// localizedLatestFile is latest.{locale}.json: the latest.json rows plus
+// display labels, so a localized page needs no label tables of its own.
+type localizedLatestFile struct {
+	SchemaVersion string                 `json:"schema_version"`
+	GeneratedAt   string                 `json:"generated_at"`
+	Provider      string                 `json:"provider"`
+	Locale        string                 `json:"locale"`
+	Partners      []string               `json:"partners"`
+	Rows          []localizedLatestEntry `json:"rows"`
+}
+
+type localizedLatestEntry struct {
+	latestEntry
+	Labels rowLabels `json:"labels"`
+}
+
+type rowLabels struct {
+	Name             string `json:"name"`
+	Region           string `json:"region,omitempty"`
+	IncomeGroup      string `json:"income_group,omitempty"`
+	USAPeriod        string `json:"usa_period,omitempty"`
+	CHNPeriod        string `json:"chn_period,omitempty"`
+	ComparisonPeriod string `json:"comparison_period,omitempty"`
+}
+
+func parseLocales(value string) ([]string, error) {
+	var locales []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		locale := strings.ToLower(strings.TrimSpace(item))
+		if locale == "" || seen[locale] {
+			continue
+		}
+		if !supportedLocales[locale] {
+			return nil, fmt.Errorf("unsupported locale %q", locale)
+		}
+		seen[locale] = true
+		locales = append(locales, locale)
+	}
+	return locales, nil
+}
+
+func localizedLatestName(locale string) string {
+	return "latest." + locale + ".json"
+}
+
+// buildLocalizedLatest labels every latest row for locale. Names fall back
+// to the English name and then the ISO3 code.
+func buildLocalizedLatest(latest latestFile, locale string) localizedLatestFile {
+	output := localizedLatestFile{
+		SchemaVersion: latest.SchemaVersion,
+		GeneratedAt:   latest.GeneratedAt,
+		Provider:      latest.Provider,
+		Locale:        locale,
+		Partners:      latest.Partners,
+		Rows:          make([]localizedLatestEntry, 0, len(latest.Rows)),
+	}
+	for _, row := range latest.Rows {
+		labels := rowLabels{
+			Name:             firstNonEmpty(row.Name, row.ISO3),
+			Region:           row.Region,
+			IncomeGroup:      row.IncomeGroup,
+			USAPeriod:        periodLabel(locale, row.USA.PeriodType, row.USA.Period),
+			CHNPeriod:        periodLabel(locale, row.CHN.PeriodType, row.CHN.Period),
+			ComparisonPeriod: periodLabel(locale, row.ComparisonPeriodType, row.ComparisonPeriod),
+		}
+		if locale == "ko" {
+			labels.Name = firstNonEmpty(row.NameKO, labels.Name)
+			labels.Region = firstNonEmpty(koreanRegions[row.Region], row.Region)
+			labels.IncomeGroup = firstNonEmpty(koreanIncomeGroups[row.IncomeGroup], row.IncomeGroup)
+		}
+		output.Rows = append(output.Rows, localizedLatestEntry{latestEntry: row, Labels: labels})
+	}
+	return output
+}
+
+// periodLabel renders a period for display, such as "Mar 2024" or
+// "2024년 3월". Unparseable periods are returned unchanged.
+func periodLabel(locale string, periodType model.PeriodType, period string) string {
+	switch periodType {
+	case model.PeriodMonth:
+		if year, month, ok := model.ParseYearMonth(period); ok {
+			if locale == "ko" {
+				return fmt.Sprintf("%d년 %d월", year, month)
+			}
+			return fmt.Sprintf("%s %d", englishMonths[month-1], year)
+		}
+	case model.PeriodQuarter:
+		if year, quarter, ok := model.ParseYearQuarter(period); ok {
+			if locale == "ko" {
+				return fmt.Sprintf("%d년 %d분기", year, quarter)
+			}
+			return fmt.Sprintf("Q%d %d", quarter, year)
+		}
+	case model.PeriodYear:
+		if year, ok := model.ParseYear(period); ok && locale == "ko" {
+			return fmt.Sprintf("%d년", year)
+		}
+	}
+	return period
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
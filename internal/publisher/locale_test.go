@@ -0,0 +1,58 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildLocalizedLatestLabelsKoreanRows(t *testing.T) {
+	latest := latestFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   "2026-01-01T00:00:00Z",
+		Provider:      "wits",
+		Partners:      []string{"USA", "CHN"},
+		Rows: []latestEntry{
+			{
+				ISO3: "KOR", Name: "Korea, Rep.", NameKO: "대한민국", Region: "East Asia & Pacific", IncomeGroup: "High income",
+				USA:                  partnerBlock{PeriodType: model.PeriodMonth, Period: "2024-03"},
+				CHN:                  partnerBlock{PeriodType: model.PeriodQuarter, Period: "2024-Q1"},
+				ComparisonPeriodType: model.PeriodYear, ComparisonPeriod: "2023",
+			},
+			{ISO3: "XKX", Region: "Unlisted"},
+		},
+	}
+
+	korean := buildLocalizedLatest(latest, "ko")
+	if korean.Locale != "ko" || len(korean.Rows) != 2 {
+		t.Fatalf("korean file = %+v, want ko locale with two rows", korean)
+	}
+	labels := korean.Rows[0].Labels
+	if labels.Name != "대한민국" || labels.Region != "동아시아·태평양" || labels.IncomeGroup != "고소득" {
+		t.Fatalf("KOR labels = %+v, want Korean name, region, and income group", labels)
+	}
+	if labels.USAPeriod != "2024년 3월" || labels.CHNPeriod != "2024년 1분기" || labels.ComparisonPeriod != "2023년" {
+		t.Fatalf("KOR period labels = %+v", labels)
+	}
+	if fallback := korean.Rows[1].Labels; fallback.Name != "XKX" || fallback.Region != "Unlisted" {
+		t.Fatalf("XKX labels = %+v, want ISO3 name and untranslated region", fallback)
+	}
+	if korean.Rows[0].Name != "Korea, Rep." {
+		t.Fatalf("KOR name = %q, want the latest.json value kept", korean.Rows[0].Name)
+	}
+
+	english := buildLocalizedLatest(latest, "en").Rows[0].Labels
+	if english.Name != "Korea, Rep." || english.USAPeriod != "Mar 2024" || english.CHNPeriod != "Q1 2024" || english.ComparisonPeriod != "2023" {
+		t.Fatalf("English labels = %+v", english)
+	}
+}
+
+func TestParseLocalesRejectsUnknownLocale(t *testing.T) {
+	locales, err := parseLocales(" EN,ko,en ")
+	if err != nil || len(locales) != 2 || locales[0] != "en" || locales[1] != "ko" {
+		t.Fatalf("parseLocales() = %v, %v, want [en ko]", locales, err)
+	}
+	if _, err := parseLocales("en,fr"); err == nil {
+		t.Fatal("parseLocales(fr) error = nil, want unsupported locale")
+	}
+}
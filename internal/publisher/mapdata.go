@@ -0,0 +1,88 @@
+package publisher
+
+import "strings"
+
+// mapPropertiesFile holds choropleth properties keyed by ISO3, so the site can
+// join them onto any world TopoJSON or GeoJSON whose features carry an ISO3
+// code. Geometry is not published.
+type mapPropertiesFile struct {
+	SchemaVersion string                   `json:"schema_version"`
+	GeneratedAt   string                   `json:"generated_at"`
+	Provider      string                   `json:"provider"`
+	Key           string                   `json:"key"`
+	Properties    map[string]mapProperties `json:"properties"`
+}
+
+type mapProperties struct {
+	Name         string   `json:"name,omitempty"`
+	Region       string   `json:"region,omitempty"`
+	Subregion    string   `json:"subregion,omitempty"`
+	IncomeGroup  string   `json:"income_group,omitempty"`
+	ShareCN      float64  `json:"share_cn"`
+	ShareCNTTM   *float64 `json:"share_cn_ttm,omitempty"`
+	Total        float64  `json:"total"`
+	USATrade     float64  `json:"usa_trade"`
+	CHNTrade     float64  `json:"chn_trade"`
+	SamePeriod   bool     `json:"same_period"`
+	Incomparable bool     `json:"incomparable,omitempty"`
+	PeriodType   string   `json:"period_type,omitempty"`
+	Period       string   `json:"period,omitempty"`
+	Growth       *float64 `json:"growth,omitempty"`
+	GrowthBasis  string   `json:"growth_basis,omitempty"`
+}
+
+// buildMapProperties flattens each latest row into the properties a
+// choropleth colours by. Period is set only when both blocks share it.
+func buildMapProperties(generatedAt, provider string, latest []latestEntry) mapPropertiesFile {
+	output := mapPropertiesFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Key:           "iso3",
+		Properties:    make(map[string]mapProperties, len(latest)),
+	}
+	for _, row := range latest {
+		properties := mapProperties{
+			Name:         row.Name,
+			Region:       row.Region,
+			Subregion:    row.Subregion,
+			IncomeGroup:  row.IncomeGroup,
+			ShareCN:      row.ShareCN,
+			ShareCNTTM:   row.ShareCNTTM,
+			Total:        row.Total,
+			USATrade:     row.USA.Trade,
+			CHNTrade:     row.CHN.Trade,
+			SamePeriod:   row.SamePeriod,
+			Incomparable: row.Incomparable,
+		}
+		if row.ComparisonPeriod != "" {
+			properties.PeriodType, properties.Period = string(row.ComparisonPeriodType), row.ComparisonPeriod
+		} else if row.SamePeriod {
+			properties.PeriodType, properties.Period = string(row.USA.PeriodType), row.USA.Period
+		}
+		if growth, ok := totalGrowth(row.USA, row.CHN); ok {
+			properties.Growth, properties.GrowthBasis = &growth, row.USA.GrowthBasis
+		}
+		output.Properties[row.ISO3] = properties
+	}
+	return output
+}
+
+// totalGrowth is the growth of USA+CHN trade, recovered from each block's
+// trade growth. Both blocks must be on the same period and basis.
+func totalGrowth(usa, chn partnerBlock) (float64, bool) {
+	if usa.Period == "" || usa.Period != chn.Period || usa.PeriodType != chn.PeriodType || usa.GrowthBasis != chn.GrowthBasis {
+		return 0, false
+	}
+	previous := 0.0
+	for _, block := range []partnerBlock{usa, chn} {
+		if block.Growth == nil || block.Growth.Trade == nil || *block.Growth.Trade <= -1 {
+			return 0, false
+		}
+		previous += block.Trade / (1 + *block.Growth.Trade)
+	}
+	if previous <= 0 {
+		return 0, false
+	}
+	return (usa.Trade+chn.Trade)/previous - 1, true
+}
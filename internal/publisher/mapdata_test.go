@@ -0,0 +1,41 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildMapPropertiesKeysRowsByISO3(t *testing.T) {
+	growth := func(value float64) *growthBlock { return &growthBlock{Trade: &value} }
+	latest := []latestEntry{
+		{
+			ISO3: "DEU", Name: "Germany", Total: 200, ShareCN: 0.5, SamePeriod: true,
+			USA: partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: 100, Growth: growth(0), GrowthBasis: growthYoY},
+			CHN: partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: 100, Growth: growth(1), GrowthBasis: growthYoY},
+		},
+		{
+			ISO3: "VNM", Total: 30, ShareCN: 1.0 / 3.0,
+			USA: partnerBlock{PeriodType: model.PeriodYear, Period: "2022", Trade: 20, Growth: growth(0.1), GrowthBasis: growthYoY},
+			CHN: partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: 10, Growth: growth(0.1), GrowthBasis: growthYoY},
+		},
+	}
+
+	output := buildMapProperties("2026-01-01T00:00:00Z", "WITS", latest)
+	if output.Key != "iso3" || output.Provider != "wits" || len(output.Properties) != 2 {
+		t.Fatalf("unexpected map file: %+v", output)
+	}
+	deu := output.Properties["DEU"]
+	if deu.Name != "Germany" || deu.Period != "2023" || deu.PeriodType != "Y" || deu.USATrade != 100 || deu.CHNTrade != 100 {
+		t.Fatalf("unexpected DEU properties: %+v", deu)
+	}
+	// Previous USA+CHN trade was 100 + 50, so total growth is 200/150 - 1.
+	if deu.Growth == nil || math.Abs(*deu.Growth-(200.0/150.0-1)) > 1e-12 || deu.GrowthBasis != growthYoY {
+		t.Fatalf("unexpected DEU growth: %+v", deu)
+	}
+	vnm := output.Properties["VNM"]
+	if vnm.Period != "" || vnm.Growth != nil || vnm.SamePeriod {
+		t.Fatalf("mismatched periods should have no period or growth: %+v", vnm)
+	}
+}
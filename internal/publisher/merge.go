@@ -0,0 +1,111 @@
+package publisher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// mergedProvider is the provider id published when -provider-merge draws
+// totals from several providers. coverage.json still names the provider
+// behind each reporter.
+const mergedProvider = "merged"
+
+// providerMerge is the -provider-merge policy, such as
+// M=comtrade,Y=wits/comtrade: for each period type, the providers to draw
+// totals from, most preferred first. Period types it does not name are not
+// published. The zero value merges nothing.
+type providerMerge struct {
+	spec  string
+	order map[model.PeriodType][]string
+}
+
+func parseProviderMerge(value string) (providerMerge, error) {
+	merge := providerMerge{order: make(map[model.PeriodType][]string)}
+	var parts []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, providers, ok := strings.Cut(item, "=")
+		periodType := model.PeriodType(strings.ToUpper(strings.TrimSpace(key)))
+		if !ok || periodPriority(periodType) == 0 {
+			return providerMerge{}, fmt.Errorf("invalid provider preference %q (expected Y, Q, or M=provider/provider, e.g. M=comtrade)", item)
+		}
+		if _, duplicate := merge.order[periodType]; duplicate {
+			return providerMerge{}, fmt.Errorf("period type %s is listed twice", periodType)
+		}
+		var order []string
+		for _, provider := range strings.Split(providers, "/") {
+			provider = strings.ToLower(strings.TrimSpace(provider))
+			if provider == "" {
+				return providerMerge{}, fmt.Errorf("invalid provider preference %q (empty provider)", item)
+			}
+			order = append(order, provider)
+		}
+		merge.order[periodType] = order
+		parts = append(parts, string(periodType)+"="+strings.Join(order, "/"))
+	}
+	merge.spec = strings.Join(parts, ",")
+	return merge, nil
+}
+
+func (m providerMerge) enabled() bool {
+	return len(m.order) > 0
+}
+
+// providers lists every provider the policy draws from, sorted.
+func (m providerMerge) providers() []string {
+	seen := map[string]bool{}
+	var providers []string
+	for _, order := range m.order {
+		for _, provider := range order {
+			if !seen[provider] {
+				seen[provider] = true
+				providers = append(providers, provider)
+			}
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// rank is the preference for row's provider among those the policy names
+// for its period type, 0 for the most preferred, or -1 when it names none.
+func (m providerMerge) rank(row observationRow) int {
+	for index, provider := range m.order[row.PeriodType] {
+		if strings.EqualFold(row.Provider, provider) {
+			return index
+		}
+	}
+	return -1
+}
+
+// apply keeps, for every reporter, partner, and period, the observations of
+// the most preferred provider that reported it. Both flows of a period come
+// from the same provider, so export and import always agree on the source.
+func (m providerMerge) apply(rows []observationRow) []observationRow {
+	key := func(row observationRow) string {
+		return strings.ToUpper(row.ReporterISO) + "|" + strings.ToUpper(row.PartnerISO) + "|" + seriesKey(row.PeriodType, row.Period)
+	}
+	best := make(map[string]int)
+	for _, row := range rows {
+		candidate := m.rank(row)
+		if candidate < 0 {
+			continue
+		}
+		if current, ok := best[key(row)]; !ok || candidate < current {
+			best[key(row)] = candidate
+		}
+	}
+	merged := make([]observationRow, 0, len(rows))
+	for _, row := range rows {
+		if candidate := m.rank(row); candidate >= 0 && candidate == best[key(row)] {
+			merged = append(merged, row)
+		}
+	}
+	return merged
+}
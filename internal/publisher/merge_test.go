@@ -0,0 +1,59 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestParseProviderMergeNormalizesPolicy(t *testing.T) {
+	merge, err := parseProviderMerge(" m=Comtrade , y=wits/comtrade")
+	if err != nil {
+		t.Fatalf("parseProviderMerge: %v", err)
+	}
+	if merge.spec != "M=comtrade,Y=wits/comtrade" || !merge.enabled() {
+		t.Fatalf("spec = %q, want M=comtrade,Y=wits/comtrade", merge.spec)
+	}
+	for _, value := range []string{"W=wits", "Y", "Y=wits,Y=comtrade", "M=comtrade/"} {
+		if _, err := parseProviderMerge(value); err == nil {
+			t.Fatalf("expected an error for %q", value)
+		}
+	}
+	if merge, _ := parseProviderMerge(""); merge.enabled() {
+		t.Fatal("an empty policy should be disabled")
+	}
+}
+
+func TestProviderMergePrefersProvidersPerPeriodType(t *testing.T) {
+	row := func(provider string, periodType model.PeriodType, period string, flow model.Flow, value float64) observationRow {
+		return observationRow{Provider: provider, ReporterISO: "KOR", PartnerISO: "USA", Flow: flow, PeriodType: periodType, Period: period, Value: model.CentsFromUSD(value)}
+	}
+	rows := []observationRow{
+		row("wits", model.PeriodYear, "2023", model.FlowExport, 1),
+		row("wits", model.PeriodYear, "2023", model.FlowImport, 2),
+		row("comtrade", model.PeriodYear, "2023", model.FlowExport, 10),
+		row("comtrade", model.PeriodYear, "2022", model.FlowExport, 20),
+		row("comtrade", model.PeriodYear, "2022", model.FlowImport, 30),
+		row("wits", model.PeriodMonth, "2024-01", model.FlowExport, 40),
+		row("comtrade", model.PeriodMonth, "2024-01", model.FlowExport, 50),
+		row("comtrade", model.PeriodQuarter, "2024-Q1", model.FlowExport, 60),
+	}
+	merge, err := parseProviderMerge("M=comtrade,Y=wits/comtrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values []float64
+	for _, row := range merge.apply(rows) {
+		values = append(values, row.Value.USD())
+	}
+	want := []float64{1, 2, 20, 30, 50}
+	if len(values) != len(want) {
+		t.Fatalf("merged values = %v, want %v", values, want)
+	}
+	for index := range want {
+		if values[index] != want[index] {
+			t.Fatalf("merged values = %v, want %v (wits 2023, comtrade fallback for 2022, comtrade months, no quarters)", values, want)
+		}
+	}
+}
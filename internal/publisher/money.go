@@ -0,0 +1,65 @@
+package publisher
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"tradegravity/internal/model"
+)
+
+// roundCents rounds a dollar amount to whole cents. Stored values are exact
+// cents, but sums and conversions of them are not, so published amounts are
+// rounded once more when they are encoded.
+func roundCents(usd float64) float64 {
+	return model.CentsFromUSD(usd).USD()
+}
+
+// observationValueColumn returns the expression for an observation's exact
+// value in cents, derived from value_usd as the store's migration does when
+// the store predates value_cents.
+func observationValueColumn(db *sql.DB) (string, error) {
+	columns, err := sqliteTableColumns(db, "trade_observations")
+	if err != nil {
+		return "", err
+	}
+	if _, ok := columns["value_cents"]; !ok {
+		return "CAST(ROUND(value_usd * 100) AS INTEGER)", nil
+	}
+	return "value_cents", nil
+}
+
+func (block partnerBlock) MarshalJSON() ([]byte, error) {
+	type plain partnerBlock
+	block.Export, block.Import, block.Trade = roundCents(block.Export), roundCents(block.Import), roundCents(block.Trade)
+	return json.Marshal(plain(block))
+}
+
+func (block seriesBlock) MarshalJSON() ([]byte, error) {
+	type plain seriesBlock
+	block.Export, block.Import, block.Trade = roundCents(block.Export), roundCents(block.Import), roundCents(block.Trade)
+	return json.Marshal(plain(block))
+}
+
+func (block trailingBlock) MarshalJSON() ([]byte, error) {
+	type plain trailingBlock
+	block.Export, block.Import, block.Trade = roundCents(block.Export), roundCents(block.Import), roundCents(block.Trade)
+	return json.Marshal(plain(block))
+}
+
+func (block aggregateBlock) MarshalJSON() ([]byte, error) {
+	type plain aggregateBlock
+	block.Export, block.Import, block.Trade = roundCents(block.Export), roundCents(block.Import), roundCents(block.Trade)
+	return json.Marshal(plain(block))
+}
+
+func (block partnerBlockV1) MarshalJSON() ([]byte, error) {
+	type plain partnerBlockV1
+	block.Export, block.Import, block.Trade = roundCents(block.Export), roundCents(block.Import), roundCents(block.Trade)
+	return json.Marshal(plain(block))
+}
+
+func (observation historyObservation) MarshalJSON() ([]byte, error) {
+	type plain historyObservation
+	observation.Export, observation.Import, observation.Trade = roundCents(observation.Export), roundCents(observation.Import), roundCents(observation.Trade)
+	return json.Marshal(plain(observation))
+}
@@ -0,0 +1,76 @@
+package publisher
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/store/sqlite"
+)
+
+func TestPartnerBlockPublishesWholeCents(t *testing.T) {
+	block := partnerBlock{Period: "2024", PeriodType: model.PeriodYear, Export: 0.1 + 0.2, Import: 1005.004999, Trade: 0.1 + 0.2 + 1005.004999,
+		TTM: &trailingBlock{Through: "2024-12", Export: 1.005000001, Import: 2, Trade: 3.005000001}}
+	data, err := json.Marshal(block)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, want := range []string{`"export":0.3,`, `"import":1005,`, `"trade":1005.3,`, `"ttm":{"through":"2024-12","export":1.01,"import":2,"trade":3.01}`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("json = %s, want %s", data, want)
+		}
+	}
+	if block.Export != 0.1+0.2 {
+		t.Fatalf("marshal changed the block: %v", block.Export)
+	}
+}
+
+func TestConvertBlockRoundsToCents(t *testing.T) {
+	rates := []model.FXRate{{Currency: "KRW", PeriodType: model.PeriodYear, Period: "2024", RatePerUSD: 1333.333}}
+	converted := convertBlock(partnerBlock{Period: "2024", PeriodType: model.PeriodYear, Export: 0.1 + 0.2, Import: 10, Trade: 10.3}, rates)
+	if converted == nil || converted.Export != 400 || converted.Import != 13333.33 || converted.Trade != 13733.33 {
+		t.Fatalf("converted = %+v", converted)
+	}
+}
+
+func TestLoadObservationsReadsStoredCents(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tradegravity.db")
+	st, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.UpsertObservations(context.Background(), []model.Observation{{
+		Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport,
+		PeriodType: model.PeriodYear, Period: "2024", ValueUSD: 1005.01,
+	}})
+	st.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// value_usd is only a convenience copy, so a drifted one must not leak.
+	if _, err := db.Exec(`UPDATE trade_observations SET value_usd = 1005.0099999`); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := loadObservations(dbPath, "wits", []string{"USA"}, nil)
+	if err != nil || len(rows) != 1 || rows[0].Value != 100501 {
+		t.Fatalf("rows = %+v, %v; want value_cents read", rows, err)
+	}
+
+	// A store that predates value_cents is read from value_usd, rounded.
+	if _, err := db.Exec(`ALTER TABLE trade_observations DROP COLUMN value_cents`); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = loadObservations(dbPath, "wits", []string{"USA"}, nil)
+	if err != nil || len(rows) != 1 || rows[0].Value != 100501 {
+		t.Fatalf("rows without value_cents = %+v, %v", rows, err)
+	}
+}
@@ -0,0 +1,162 @@
+package publisher
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// moversFile is movers.json: the reporters whose China share and USA+CHN
+// trade swung most between a base period and the dominant latest period.
+type moversFile struct {
+	SchemaVersion string        `json:"schema_version"`
+	GeneratedAt   string        `json:"generated_at"`
+	Provider      string        `json:"provider"`
+	PeriodType    string        `json:"period_type"`
+	Period        string        `json:"period"`
+	Limit         int           `json:"limit"`
+	Windows       []moverWindow `json:"windows"`
+}
+
+// moverWindow compares the latest period with BasePeriod: the preceding
+// period for last_period and the same period five years earlier for
+// five_years.
+type moverWindow struct {
+	ID         string      `json:"id"`
+	BasePeriod string      `json:"base_period"`
+	Lists      []moverList `json:"lists"`
+}
+
+type moverList struct {
+	ID    string     `json:"id"`
+	Title string     `json:"title"`
+	Rows  []moverRow `json:"rows"`
+}
+
+// moverRow keeps the numbers behind a swing. Change is Value − BaseValue for
+// absolute lists and (Value − BaseValue) / BaseValue for relative ones; rows
+// are ordered by its magnitude, so rises and falls share a list.
+type moverRow struct {
+	Rank      int     `json:"rank"`
+	ISO3      string  `json:"iso3"`
+	Name      string  `json:"name,omitempty"`
+	BaseValue float64 `json:"base_value"`
+	Value     float64 `json:"value"`
+	Change    float64 `json:"change"`
+}
+
+type moverMetric struct {
+	id, title string
+	value     func(point *seriesPoint) float64
+	relative  bool
+}
+
+var moverMetrics = []moverMetric{
+	{id: "share_cn_abs", title: "Largest swings in China share (percentage points)", value: func(point *seriesPoint) float64 { return point.ShareCN }},
+	{id: "share_cn_rel", title: "Largest relative swings in China share", value: func(point *seriesPoint) float64 { return point.ShareCN }, relative: true},
+	{id: "total_abs", title: "Largest swings in USA+CHN trade (USD)", value: func(point *seriesPoint) float64 { return point.Total }},
+	{id: "total_rel", title: "Largest relative swings in USA+CHN trade", value: func(point *seriesPoint) float64 { return point.Total }, relative: true},
+}
+
+const moverYears = 5
+
+// buildMovers compares comparable history points at the dominant latest
+// period with each window's base period. Relative changes skip reporters
+// whose base value is zero.
+func buildMovers(generatedAt, provider string, history seriesFile, latest []latestEntry, limit int) moversFile {
+	output := moversFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Limit:         limit,
+		Windows:       []moverWindow{},
+	}
+	periodType, period, ok := strings.Cut(dominantLatestPeriod(latest), ":")
+	if !ok {
+		return output
+	}
+	output.PeriodType = periodType
+	output.Period = period
+
+	names := make(map[string]string, len(latest))
+	for _, row := range latest {
+		names[row.ISO3] = row.Name
+	}
+	points := make(map[string]map[string]*seriesPoint, len(history.Rows))
+	for rowIndex := range history.Rows {
+		row := &history.Rows[rowIndex]
+		byPeriod := make(map[string]*seriesPoint, len(row.Points))
+		for pointIndex := range row.Points {
+			point := &row.Points[pointIndex]
+			if point.Comparable && point.Total > 0 {
+				byPeriod[seriesKey(point.PeriodType, point.Period)] = point
+			}
+		}
+		points[row.ISO3] = byPeriod
+	}
+
+	fiveYears := period
+	for range moverYears {
+		fiveYears = prevPeriod(model.PeriodType(periodType), fiveYears)
+	}
+	for _, window := range []struct{ id, base string }{
+		{id: "last_period", base: precedingPeriod(model.PeriodType(periodType), period)},
+		{id: "five_years", base: fiveYears},
+	} {
+		if window.base == "" {
+			continue
+		}
+		current := seriesKey(model.PeriodType(periodType), period)
+		base := seriesKey(model.PeriodType(periodType), window.base)
+		entry := moverWindow{ID: window.id, BasePeriod: window.base, Lists: []moverList{}}
+		for _, metric := range moverMetrics {
+			rows := moverRows(points, metric, current, base)
+			if limit > 0 && len(rows) > limit {
+				rows = rows[:limit]
+			}
+			for index := range rows {
+				rows[index].Name = names[rows[index].ISO3]
+			}
+			entry.Lists = append(entry.Lists, moverList{ID: metric.id, Title: metric.title, Rows: rows})
+		}
+		output.Windows = append(output.Windows, entry)
+	}
+	return output
+}
+
+func moverRows(points map[string]map[string]*seriesPoint, metric moverMetric, currentKey, baseKey string) []moverRow {
+	rows := []moverRow{}
+	for iso3, byPeriod := range points {
+		current, base := byPeriod[currentKey], byPeriod[baseKey]
+		if current == nil || base == nil {
+			continue
+		}
+		row := moverRow{ISO3: iso3, BaseValue: metric.value(base), Value: metric.value(current)}
+		row.Change = row.Value - row.BaseValue
+		if metric.relative {
+			if row.BaseValue == 0 {
+				continue
+			}
+			row.Change /= row.BaseValue
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if math.Abs(rows[i].Change) != math.Abs(rows[j].Change) {
+			return math.Abs(rows[i].Change) > math.Abs(rows[j].Change)
+		}
+		return rows[i].ISO3 < rows[j].ISO3
+	})
+	for index := range rows {
+		rows[index].Rank = index + 1
+	}
+	return rows
+}
+
+// precedingPeriod is the period immediately before period: the previous
+// month, quarter, or year.
+func precedingPeriod(periodType model.PeriodType, period string) string {
+	return model.PreviousPeriod(periodType, period)
+}
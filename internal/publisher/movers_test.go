@@ -0,0 +1,59 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildMoversRanksSwingsByMagnitude(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter, period string, usa, chn float64) {
+		rows = append(rows,
+			observationRow{ReporterISO: reporter, PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(usa)},
+			observationRow{ReporterISO: reporter, PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(chn)},
+		)
+	}
+	add("KOR", "2018", 50, 50)
+	add("KOR", "2022", 80, 20)
+	add("KOR", "2023", 40, 60)
+	add("JPN", "2022", 50, 50)
+	add("JPN", "2023", 90, 10)
+	add("VNM", "2023", 10, 90)
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+	block := partnerBlock{PeriodType: model.PeriodYear, Period: "2023"}
+	latest := []latestEntry{{ISO3: "KOR", Name: "Korea", USA: block, CHN: block}, {ISO3: "JPN", USA: block, CHN: block}}
+
+	output := buildMovers("2026-01-01T00:00:00Z", "wits", history, latest, 10)
+	if output.Period != "2023" || len(output.Windows) != 2 {
+		t.Fatalf("unexpected movers header: %+v", output)
+	}
+	last, five := output.Windows[0], output.Windows[1]
+	if last.ID != "last_period" || last.BasePeriod != "2022" || five.ID != "five_years" || five.BasePeriod != "2018" {
+		t.Fatalf("unexpected windows: %+v %+v", last, five)
+	}
+	share := last.Lists[0].Rows
+	if len(share) != 2 || share[0].ISO3 != "JPN" || math.Abs(share[0].Change+0.4) > 1e-9 || share[1].ISO3 != "KOR" || share[1].Name != "Korea" || math.Abs(share[1].Change-0.4) > 1e-9 {
+		t.Fatalf("share rows = %+v, want JPN -0.4 ahead of KOR +0.4", share)
+	}
+	relative := five.Lists[1].Rows
+	if len(relative) != 1 || relative[0].ISO3 != "KOR" || relative[0].BaseValue != 0.5 || math.Abs(relative[0].Change-0.2) > 1e-9 {
+		t.Fatalf("five-year relative share rows = %+v, want KOR up 20%%", relative)
+	}
+}
+
+func TestPrecedingPeriodStepsBackOnePeriod(t *testing.T) {
+	for _, tt := range []struct {
+		periodType   model.PeriodType
+		period, want string
+	}{
+		{model.PeriodMonth, "2024-01", "2023-12"},
+		{model.PeriodQuarter, "2024-Q3", "2024-Q2"},
+		{model.PeriodYear, "2024", "2023"},
+	} {
+		if got := precedingPeriod(tt.periodType, tt.period); got != tt.want {
+			t.Fatalf("precedingPeriod(%s, %s) = %q, want %q", tt.periodType, tt.period, got, tt.want)
+		}
+	}
+}
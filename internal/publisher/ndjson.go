@@ -0,0 +1,53 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"tradegravity/internal/model"
+)
+
+// historyObservation is one line of history.ndjson: a single reporter,
+// partner, and period from history.json. Blocks that are not available are
+// left out rather than written as zeros.
+type historyObservation struct {
+	ISO3        string           `json:"iso3"`
+	PartnerISO3 string           `json:"partner_iso3"`
+	PeriodType  model.PeriodType `json:"period_type"`
+	Period      string           `json:"period"`
+	Export      float64          `json:"export"`
+	Import      float64          `json:"import"`
+	Trade       float64          `json:"trade"`
+}
+
+// writeHistoryNDJSON writes history as newline-delimited JSON, one
+// observation per line in history.json order, so consumers can stream it
+// without parsing one large array.
+func writeHistoryNDJSON(path string, history seriesFile) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, row := range history.Rows {
+		for _, point := range row.Points {
+			for _, partner := range []struct {
+				iso3  string
+				block seriesBlock
+			}{{"USA", point.USA}, {"CHN", point.CHN}} {
+				if !partner.block.Available {
+					continue
+				}
+				if err := encoder.Encode(historyObservation{
+					ISO3:        row.ISO3,
+					PartnerISO3: partner.iso3,
+					PeriodType:  point.PeriodType,
+					Period:      point.Period,
+					Export:      partner.block.Export,
+					Import:      partner.block.Import,
+					Trade:       partner.block.Trade,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return writeArtifact(path, body.Bytes())
+}
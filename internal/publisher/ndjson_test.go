@@ -0,0 +1,49 @@
+package publisher
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestWriteHistoryNDJSONWritesOneObservationPerLine(t *testing.T) {
+	history := seriesFile{Rows: []reporterSeries{{
+		ISO3: "KOR",
+		Points: []seriesPoint{
+			{PeriodType: model.PeriodYear, Period: "2022", USA: seriesBlock{Available: true, Export: 3, Import: 2, Trade: 5}},
+			{PeriodType: model.PeriodYear, Period: "2023",
+				USA: seriesBlock{Available: true, Export: 4, Import: 2, Trade: 6},
+				CHN: seriesBlock{Available: true, Export: 1, Import: 1, Trade: 2}},
+		},
+	}}}
+	path := filepath.Join(t.TempDir(), "history.ndjson")
+	if err := writeHistoryNDJSON(path, history); err != nil {
+		t.Fatalf("writeHistoryNDJSON: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var lines []historyObservation
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var observation historyObservation
+		if err := json.Unmarshal(scanner.Bytes(), &observation); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v", len(lines)+1, err)
+		}
+		lines = append(lines, observation)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3 (the unavailable 2022 CHN block is skipped)", len(lines))
+	}
+	last := lines[2]
+	if last.ISO3 != "KOR" || last.PartnerISO3 != "CHN" || last.Period != "2023" || last.Trade != 2 {
+		t.Fatalf("unexpected last observation: %+v", last)
+	}
+}
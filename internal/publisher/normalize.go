@@ -0,0 +1,33 @@
+package publisher
+
+import "tradegravity/internal/model"
+
+// applyNormalization adds trade per capita and trade as a share of GDP to
+// every annual partner block, using the row's WDI population and GDP. Only
+// annual blocks are normalized, because both denominators are annual; the
+// denominators' own years stay on the row as population.year and gdp.year.
+func applyNormalization(latest []latestEntry) {
+	for index := range latest {
+		row := &latest[index]
+		row.USA = normalizeBlock(row.USA, row.Population, row.GDP)
+		row.CHN = normalizeBlock(row.CHN, row.Population, row.GDP)
+		for partner, block := range row.Partners {
+			row.Partners[partner] = normalizeBlock(block, row.Population, row.GDP)
+		}
+	}
+}
+
+func normalizeBlock(block partnerBlock, population, gdp contextMetric) partnerBlock {
+	if block.PeriodType != model.PeriodYear || block.Period == "" {
+		return block
+	}
+	if population.Value != nil && *population.Value > 0 {
+		perCapita := block.Trade / *population.Value
+		block.TradePerCapita = &perCapita
+	}
+	if gdp.Value != nil && *gdp.Value > 0 {
+		share := block.Trade / *gdp.Value
+		block.TradeShareOfGDP = &share
+	}
+	return block
+}
@@ -0,0 +1,39 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestApplyNormalizationDividesAnnualBlocks(t *testing.T) {
+	population, gdp := 50.0, 1000.0
+	usa := partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: 100}
+	chn := partnerBlock{PeriodType: model.PeriodMonth, Period: "2024-05", Trade: 20}
+	latest := []latestEntry{{
+		ISO3:       "KOR",
+		Population: contextMetric{Value: &population, Year: "2023"},
+		GDP:        contextMetric{Value: &gdp, Year: "2023"},
+		USA:        usa,
+		CHN:        chn,
+		Partners:   map[string]partnerBlock{"USA": usa, "CHN": chn},
+	}, {
+		ISO3: "XKX",
+		USA:  usa,
+	}}
+
+	applyNormalization(latest)
+	row := latest[0]
+	if row.USA.TradePerCapita == nil || *row.USA.TradePerCapita != 2 || row.USA.TradeShareOfGDP == nil || *row.USA.TradeShareOfGDP != 0.1 {
+		t.Fatalf("USA = %+v, want per capita 2 and GDP share 0.1", row.USA)
+	}
+	if row.Partners["USA"].TradePerCapita == nil || *row.Partners["USA"].TradePerCapita != 2 {
+		t.Fatalf("partners USA = %+v, want the same normalization as usa", row.Partners["USA"])
+	}
+	if row.CHN.TradePerCapita != nil || row.CHN.TradeShareOfGDP != nil {
+		t.Fatalf("CHN = %+v, want monthly block left unnormalized", row.CHN)
+	}
+	if latest[1].USA.TradePerCapita != nil {
+		t.Fatalf("XKX USA = %+v, want no normalization without context", latest[1].USA)
+	}
+}
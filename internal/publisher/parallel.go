@@ -0,0 +1,58 @@
+package publisher
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// writeConcurrency bounds the partition files encoded and written at once,
+// set by build -concurrency.
+var writeConcurrency = runtime.GOMAXPROCS(0)
+
+// encodeBuffers holds the buffers writeJSON encodes into, so writing
+// hundreds of partitions reuses a few buffers sized for the largest file
+// rather than growing a new one for each.
+var encodeBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// writeJSONFiles writes each file under dir at its slash-separated key plus
+// suffix with up to writeConcurrency workers. Files are handed out in key
+// order, and the error of the first key that failed is returned after the
+// rest finish.
+func writeJSONFiles[T any](dir, suffix string, files map[string]T) error {
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	workerCount := max(1, min(writeConcurrency, len(keys)))
+	jobs := make(chan int)
+	errs := make([]error, len(keys))
+	var workers sync.WaitGroup
+	for range workerCount {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for position := range jobs {
+				key := keys[position]
+				if err := writeJSON(filepath.Join(dir, filepath.FromSlash(key+suffix)), files[key]); err != nil {
+					errs[position] = fmt.Errorf("%s: %w", key, err)
+				}
+			}
+		}()
+	}
+	for position := range keys {
+		jobs <- position
+	}
+	close(jobs)
+	workers.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package publisher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSONFilesWritesEveryPartitionConcurrently(t *testing.T) {
+	defer func(previous int) { writeConcurrency = previous }(writeConcurrency)
+	writeConcurrency = 8
+
+	dir := t.TempDir()
+	files := map[string]countryFile{}
+	for i := range 200 {
+		iso3 := fmt.Sprintf("R%03d", i)
+		files[iso3] = countryFile{SchemaVersion: schemaVersion, GeneratedAt: "2026-01-01T00:00:00Z", ReporterISO3: iso3}
+	}
+	build := func() *artifactWriter {
+		t.Helper()
+		writer, err := newArtifactWriter(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		artifacts = writer
+		defer func() { artifacts = nil }()
+		if err := writeJSONFiles(filepath.Join(dir, "countries"), ".json", files); err != nil {
+			t.Fatal(err)
+		}
+		if err := writer.finish("2026-01-01T00:00:00Z"); err != nil {
+			t.Fatal(err)
+		}
+		return writer
+	}
+
+	first := build()
+	index := buildArtifactIndex("2026-01-01T00:00:00Z", first.records)
+	if len(first.touched) != 200 || index.FileCount != 200 || index.Files[0].Path != "countries/R000.json" || index.Files[199].Path != "countries/R199.json" {
+		t.Fatalf("first build: touched=%d index=%d first=%s", len(first.touched), index.FileCount, index.Files[0].Path)
+	}
+	body, err := os.ReadFile(filepath.Join(dir, "countries", "R042.json"))
+	if err != nil || !strings.Contains(string(body), `"reporter_iso3": "R042"`) {
+		t.Fatalf("R042.json = %s, %v", body, err)
+	}
+	if second := build(); len(second.touched) != 0 || second.unchanged != 200 {
+		t.Fatalf("second build: touched=%d unchanged=%d", len(second.touched), second.unchanged)
+	}
+}
+
+func TestWriteJSONFilesReportsTheFirstFailingKey(t *testing.T) {
+	dir := t.TempDir()
+	// A file where a partition directory belongs makes its writes fail.
+	if err := os.WriteFile(filepath.Join(dir, "KOR"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]int{"KOR/2023.json": 1, "KOR/2024.json": 2, "VNM/2023.json": 3}
+	err := writeJSONFiles(dir, "", files)
+	if err == nil || !strings.HasPrefix(err.Error(), "KOR/2023.json: ") {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "VNM", "2023.json")); err != nil {
+		t.Fatalf("other partitions should still be written: %v", err)
+	}
+}
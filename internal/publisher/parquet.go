@@ -0,0 +1,313 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Minimal Parquet writer for exportTable. Each table becomes one row group
+// with one uncompressed PLAIN data page per column. Every column is OPTIONAL
+// so unavailable values stay null rather than zero. The format is described at
+// https://parquet.apache.org/docs/file-format/.
+
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetOptional      = 1
+	parquetConvertedUTF8 = 0
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+	parquetDataPage      = 0
+)
+
+// Thrift compact protocol field types.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+func writeParquet(path string, table exportTable) error {
+	body, err := encodeParquet(table)
+	if err != nil {
+		return err
+	}
+	return writeArtifact(path, body)
+}
+
+func encodeParquet(table exportTable) ([]byte, error) {
+	types := make([]int32, len(table.Columns))
+	for column := range table.Columns {
+		types[column] = parquetColumnType(table.Rows, column)
+	}
+
+	var body bytes.Buffer
+	body.WriteString("PAR1")
+	chunks := make([][]byte, len(table.Columns))
+	var totalSize int64
+	for column := range table.Columns {
+		page, err := parquetPage(table.Rows, column, types[column])
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", table.Columns[column], err)
+		}
+		offset := int64(body.Len())
+		body.Write(page)
+		size := int64(len(page))
+		totalSize += size
+
+		var meta thriftWriter
+		meta.i32(1, types[column])
+		meta.listI32(2, []int32{parquetEncodingPlain, parquetEncodingRLE})
+		meta.listString(3, []string{table.Columns[column]})
+		meta.i32(4, 0)
+		meta.i64(5, int64(len(table.Rows)))
+		meta.i64(6, size)
+		meta.i64(7, size)
+		meta.i64(9, offset)
+		meta.stop()
+
+		var chunk thriftWriter
+		chunk.i64(2, offset)
+		chunk.structField(3, meta.bytes())
+		chunk.stop()
+		chunks[column] = chunk.bytes()
+	}
+
+	schema := make([][]byte, 0, len(table.Columns)+1)
+	var root thriftWriter
+	root.binary(4, []byte("schema"))
+	root.i32(5, int32(len(table.Columns)))
+	root.stop()
+	schema = append(schema, root.bytes())
+	for column, name := range table.Columns {
+		var element thriftWriter
+		element.i32(1, types[column])
+		element.i32(3, parquetOptional)
+		element.binary(4, []byte(name))
+		if types[column] == parquetByteArray {
+			element.i32(6, parquetConvertedUTF8)
+		}
+		element.stop()
+		schema = append(schema, element.bytes())
+	}
+
+	var rowGroup thriftWriter
+	rowGroup.listStruct(1, chunks)
+	rowGroup.i64(2, totalSize)
+	rowGroup.i64(3, int64(len(table.Rows)))
+	rowGroup.stop()
+
+	var footer thriftWriter
+	footer.i32(1, 1)
+	footer.listStruct(2, schema)
+	footer.i64(3, int64(len(table.Rows)))
+	footer.listStruct(4, [][]byte{rowGroup.bytes()})
+	footer.binary(6, []byte("tradegravity publisher"))
+	footer.stop()
+
+	body.Write(footer.bytes())
+	binary.Write(&body, binary.LittleEndian, uint32(len(footer.bytes())))
+	body.WriteString("PAR1")
+	return body.Bytes(), nil
+}
+
+func parquetColumnType(rows [][]any, column int) int32 {
+	for _, row := range rows {
+		switch row[column].(type) {
+		case float64, *float64:
+			return parquetDouble
+		case int, *int:
+			return parquetInt64
+		case bool:
+			return parquetBoolean
+		case string:
+			return parquetByteArray
+		}
+	}
+	return parquetByteArray
+}
+
+// parquetPage encodes one column as a v1 data page: header, definition
+// levels, then the non-null values.
+func parquetPage(rows [][]any, column int, columnType int32) ([]byte, error) {
+	levels := make([]byte, len(rows))
+	var values bytes.Buffer
+	var bits []bool
+	for index, row := range rows {
+		cell := row[column]
+		switch pointer := cell.(type) {
+		case *float64:
+			if pointer == nil {
+				continue
+			}
+			cell = *pointer
+		case *int:
+			if pointer == nil {
+				continue
+			}
+			cell = *pointer
+		}
+		if cell == nil {
+			continue
+		}
+		levels[index] = 1
+		switch columnType {
+		case parquetDouble:
+			value, ok := cell.(float64)
+			if !ok {
+				return nil, fmt.Errorf("row %d: %T is not a float", index, cell)
+			}
+			binary.Write(&values, binary.LittleEndian, math.Float64bits(value))
+		case parquetInt64:
+			value, ok := cell.(int)
+			if !ok {
+				return nil, fmt.Errorf("row %d: %T is not an int", index, cell)
+			}
+			binary.Write(&values, binary.LittleEndian, int64(value))
+		case parquetBoolean:
+			value, ok := cell.(bool)
+			if !ok {
+				return nil, fmt.Errorf("row %d: %T is not a bool", index, cell)
+			}
+			bits = append(bits, value)
+		default:
+			value, ok := cell.(string)
+			if !ok {
+				return nil, fmt.Errorf("row %d: %T is not a string", index, cell)
+			}
+			binary.Write(&values, binary.LittleEndian, uint32(len(value)))
+			values.WriteString(value)
+		}
+	}
+	if columnType == parquetBoolean {
+		packed := make([]byte, (len(bits)+7)/8)
+		for index, bit := range bits {
+			if bit {
+				packed[index/8] |= 1 << (index % 8)
+			}
+		}
+		values.Write(packed)
+	}
+
+	encodedLevels := rleLevels(levels)
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, uint32(len(encodedLevels)))
+	data.Write(encodedLevels)
+	data.Write(values.Bytes())
+
+	var pageHeader thriftWriter
+	pageHeader.i32(1, parquetDataPage)
+	pageHeader.i32(2, int32(data.Len()))
+	pageHeader.i32(3, int32(data.Len()))
+	var dataHeader thriftWriter
+	dataHeader.i32(1, int32(len(rows)))
+	dataHeader.i32(2, parquetEncodingPlain)
+	dataHeader.i32(3, parquetEncodingRLE)
+	dataHeader.i32(4, parquetEncodingRLE)
+	dataHeader.stop()
+	pageHeader.structField(5, dataHeader.bytes())
+	pageHeader.stop()
+
+	return append(pageHeader.bytes(), data.Bytes()...), nil
+}
+
+// rleLevels encodes bit-width-1 definition levels as RLE runs of the
+// RLE/bit-packing hybrid encoding.
+func rleLevels(levels []byte) []byte {
+	var out []byte
+	for start := 0; start < len(levels); {
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		out = binary.AppendUvarint(out, uint64(end-start)<<1)
+		out = append(out, levels[start])
+		start = end
+	}
+	return out
+}
+
+// thriftWriter encodes structs with the Thrift compact protocol used by
+// Parquet metadata.
+type thriftWriter struct {
+	buf       []byte
+	lastField int16
+}
+
+func (w *thriftWriter) bytes() []byte {
+	return w.buf
+}
+
+func (w *thriftWriter) field(id int16, fieldType byte) {
+	if delta := id - w.lastField; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|fieldType)
+	} else {
+		w.buf = append(w.buf, fieldType)
+		w.buf = binary.AppendVarint(w.buf, int64(id))
+	}
+	w.lastField = id
+}
+
+func (w *thriftWriter) i32(id int16, value int32) {
+	w.field(id, thriftI32)
+	w.buf = binary.AppendVarint(w.buf, int64(value))
+}
+
+func (w *thriftWriter) i64(id int16, value int64) {
+	w.field(id, thriftI64)
+	w.buf = binary.AppendVarint(w.buf, value)
+}
+
+func (w *thriftWriter) binary(id int16, value []byte) {
+	w.field(id, thriftBinary)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+func (w *thriftWriter) structField(id int16, encoded []byte) {
+	w.field(id, thriftStruct)
+	w.buf = append(w.buf, encoded...)
+}
+
+func (w *thriftWriter) listHeader(id int16, size int, elementType byte) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.buf = append(w.buf, byte(size)<<4|elementType)
+		return
+	}
+	w.buf = append(w.buf, 0xf0|elementType)
+	w.buf = binary.AppendUvarint(w.buf, uint64(size))
+}
+
+func (w *thriftWriter) listI32(id int16, values []int32) {
+	w.listHeader(id, len(values), thriftI32)
+	for _, value := range values {
+		w.buf = binary.AppendVarint(w.buf, int64(value))
+	}
+}
+
+func (w *thriftWriter) listString(id int16, values []string) {
+	w.listHeader(id, len(values), thriftBinary)
+	for _, value := range values {
+		w.buf = binary.AppendUvarint(w.buf, uint64(len(value)))
+		w.buf = append(w.buf, value...)
+	}
+}
+
+func (w *thriftWriter) listStruct(id int16, encoded [][]byte) {
+	w.listHeader(id, len(encoded), thriftStruct)
+	for _, value := range encoded {
+		w.buf = append(w.buf, value...)
+	}
+}
+
+func (w *thriftWriter) stop() {
+	w.buf = append(w.buf, 0)
+}
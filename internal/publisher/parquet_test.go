@@ -0,0 +1,126 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteParquetRoundTripsFooterAndValues(t *testing.T) {
+	growth := 0.5
+	table := exportTable{
+		Name:    "latest",
+		Columns: []string{"iso3", "trade", "growth", "comparable"},
+		Rows: [][]any{
+			{"KOR", 10.0, &growth, true},
+			{"JPN", 20.0, (*float64)(nil), false},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "latest.parquet")
+	if err := writeParquet(path, table); err != nil {
+		t.Fatalf("writeParquet() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(content, []byte("PAR1")) || !bytes.HasSuffix(content, []byte("PAR1")) {
+		t.Fatal("missing parquet magic")
+	}
+	footerLength := int(binary.LittleEndian.Uint32(content[len(content)-8:]))
+	reader := &thriftReader{buf: content[len(content)-8-footerLength : len(content)-8]}
+	footer := reader.readStruct()
+	if footer[3] != int64(2) {
+		t.Fatalf("num_rows = %v", footer[3])
+	}
+	schema := footer[2].([]any)
+	if len(schema) != 5 || string(schema[1].(map[int16]any)[4].([]byte)) != "iso3" || schema[3].(map[int16]any)[1] != int64(parquetDouble) {
+		t.Fatalf("unexpected schema: %v", schema)
+	}
+
+	rowGroup := footer[4].([]any)[0].(map[int16]any)
+	growthChunk := rowGroup[1].([]any)[2].(map[int16]any)[3].(map[int16]any)
+	offset := growthChunk[9].(int64)
+	page := &thriftReader{buf: content[offset:]}
+	header := page.readStruct()
+	if header[5].(map[int16]any)[1] != int64(2) {
+		t.Fatalf("page num_values = %v", header[5])
+	}
+	data := page.buf[page.pos : page.pos+int(header[3].(int64))]
+	levelLength := int(binary.LittleEndian.Uint32(data))
+	values := data[4+levelLength:]
+	if len(values) != 8 || math.Float64frombits(binary.LittleEndian.Uint64(values)) != 0.5 {
+		t.Fatalf("unexpected growth values: %v", values)
+	}
+}
+
+// thriftReader decodes Thrift compact structs into field-id maps for tests.
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) varint() int64 {
+	value, n := binary.Varint(r.buf[r.pos:])
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	value, n := binary.Uvarint(r.buf[r.pos:])
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := map[int16]any{}
+	var last int16
+	for {
+		header := r.buf[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		last = id
+		fields[id] = r.readValue(header & 0x0f)
+	}
+}
+
+func (r *thriftReader) readValue(fieldType byte) any {
+	switch fieldType {
+	case 1:
+		return true
+	case 2:
+		return false
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		size := int(r.uvarint())
+		value := r.buf[r.pos : r.pos+size]
+		r.pos += size
+		return value
+	case thriftList:
+		header := r.buf[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		items := make([]any, size)
+		for index := range items {
+			items[index] = r.readValue(header & 0x0f)
+		}
+		return items
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		panic("unsupported thrift type")
+	}
+}
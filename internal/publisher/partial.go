@@ -0,0 +1,207 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/buildinfo"
+	"tradegravity/internal/iso"
+)
+
+// parseOnly returns the reporters named by -only, upper-cased and sorted.
+func parseOnly(value string) ([]string, error) {
+	var reporters []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		iso3, err := iso.Normalize(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reporter: %w (expected codes such as KOR,VNM)", err)
+		}
+		if seen[iso3] {
+			continue
+		}
+		seen[iso3] = true
+		reporters = append(reporters, iso3)
+	}
+	sort.Strings(reporters)
+	return reporters, nil
+}
+
+// readPublished decodes the artifact at the default path relative from a
+// previous build in dir.
+func readPublished(dir, relative string, target any) error {
+	body, err := os.ReadFile(layoutPath(dir, relative))
+	if err != nil {
+		return fmt.Errorf("read published %s: %w", relative, err)
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("decode published %s: %w", relative, err)
+	}
+	return nil
+}
+
+// loadPublishedLatest reads the latest.json a partial rebuild patches. The
+// previous build must use the same provider and partners, since the rows
+// that are kept are not recomputed.
+func loadPublishedLatest(dir, provider string, partners []string) (latestFile, error) {
+	var published latestFile
+	if err := readPublished(dir, "latest.json", &published); err != nil {
+		return latestFile{}, err
+	}
+	if published.SchemaVersion != schemaVersion {
+		return latestFile{}, fmt.Errorf("published latest.json has schema %q; -only needs a full %s build first", published.SchemaVersion, schemaVersion)
+	}
+	if published.Provider != strings.ToLower(strings.TrimSpace(provider)) {
+		return latestFile{}, fmt.Errorf("published latest.json is from provider %q, not %q", published.Provider, provider)
+	}
+	if strings.Join(published.Partners, ",") != strings.Join(partners, ",") {
+		return latestFile{}, fmt.Errorf("published latest.json has partners %s, not %s", strings.Join(published.Partners, ","), strings.Join(partners, ","))
+	}
+	return published, nil
+}
+
+// spliceLatest replaces the rows of the rebuilt reporters in published with
+// fresh. A rebuilt reporter missing from fresh, because it no longer has
+// observations or was excluded as stale, is dropped.
+func spliceLatest(published []latestEntry, reporters []string, fresh []latestEntry) []latestEntry {
+	rebuilt := make(map[string]bool, len(reporters))
+	for _, iso3 := range reporters {
+		rebuilt[iso3] = true
+	}
+	spliced := make([]latestEntry, 0, len(published)+len(fresh))
+	for _, row := range published {
+		if !rebuilt[row.ISO3] {
+			spliced = append(spliced, row)
+		}
+	}
+	spliced = append(spliced, fresh...)
+	sort.Slice(spliced, func(i, j int) bool { return spliced[i].ISO3 < spliced[j].ISO3 })
+	return spliced
+}
+
+// spliceCountryIndex does the same for the partitions of countries/index.json.
+func spliceCountryIndex(published countryIndexFile, reporters []string, fresh countryIndexFile) countryIndexFile {
+	rebuilt := make(map[string]bool, len(reporters))
+	for _, iso3 := range reporters {
+		rebuilt[iso3] = true
+	}
+	spliced := published
+	spliced.Reporters = []string{}
+	spliced.Partitions = []countryPartition{}
+	for _, partition := range published.Partitions {
+		if !rebuilt[partition.ReporterISO3] {
+			spliced.Partitions = append(spliced.Partitions, partition)
+		}
+	}
+	spliced.Partitions = append(spliced.Partitions, fresh.Partitions...)
+	sort.Slice(spliced.Partitions, func(i, j int) bool {
+		return spliced.Partitions[i].ReporterISO3 < spliced.Partitions[j].ReporterISO3
+	})
+	for _, partition := range spliced.Partitions {
+		spliced.Reporters = append(spliced.Reporters, partition.ReporterISO3)
+	}
+	return spliced
+}
+
+// patchLatestMeta recomputes the meta.json fields derived from latest.json
+// rows and records the build that patched them. Series, product, and the
+// other aggregate counts are left as the previous build wrote them.
+func patchLatestMeta(meta *metaFile, latest []latestEntry, reporters, stale []string) {
+	fresh := buildMeta(meta.GeneratedAt, meta.Provider, meta.Partners, 0, latest)
+	build := buildinfo.Get()
+	meta.Build = &build
+	meta.ReporterCount = fresh.ReporterCount
+	meta.ExpectedPartnerBlocks = fresh.ExpectedPartnerBlocks
+	meta.AvailablePartnerBlocks = fresh.AvailablePartnerBlocks
+	meta.MissingPartnerBlocks = fresh.MissingPartnerBlocks
+	meta.PeriodCounts = fresh.PeriodCounts
+	meta.ComparableReporters, meta.IncomparableReporters, meta.StalePartnerBlocks = 0, 0, 0
+	augmentLatestMeta(meta, latest)
+	if meta.MaxStaleness == "" {
+		return
+	}
+	rebuilt := make(map[string]bool, len(reporters))
+	for _, iso3 := range reporters {
+		rebuilt[iso3] = true
+	}
+	kept := make([]string, 0, len(meta.StaleReporters)+len(stale))
+	for _, iso3 := range meta.StaleReporters {
+		if !rebuilt[iso3] {
+			kept = append(kept, iso3)
+		}
+	}
+	meta.StaleReporters = append(kept, stale...)
+	sort.Strings(meta.StaleReporters)
+}
+
+// writePartialBuild writes latest.json, its localized copies, meta.json, and
+// the country files of a -only rebuild over the previous build in outDir,
+// and returns the index.json listing both the rewritten and untouched files.
+func writePartialBuild(outDir string, reporters []string, published latestFile, latest []latestEntry, stale []string, countryIndex countryIndexFile, countryFiles map[string]countryFile, locales []string) (artifactIndexFile, error) {
+	var previousIndex artifactIndexFile
+	if err := readPublished(outDir, artifactIndexName, &previousIndex); err != nil {
+		return artifactIndexFile{}, err
+	}
+	var metadata metaFile
+	if err := readPublished(outDir, "meta.json", &metadata); err != nil {
+		return artifactIndexFile{}, err
+	}
+	var publishedCountries countryIndexFile
+	if err := readPublished(outDir, "countries/index.json", &publishedCountries); err != nil {
+		return artifactIndexFile{}, err
+	}
+	if artifacts != nil {
+		for index := range countryIndex.Partitions {
+			countryIndex.Partitions[index].Href = artifacts.layout.href("countries/index.json", countryIndex.Partitions[index].Href)
+		}
+		for relative, hash := range artifacts.previous {
+			artifacts.current[relative] = hash
+		}
+	}
+
+	output := published
+	output.Rows = spliceLatest(published.Rows, reporters, latest)
+	// Peers outside the selection still count toward the rebuilt ranks.
+	applyPeerPercentiles(output.Rows)
+	for _, row := range output.Rows {
+		if file, ok := countryFiles[row.ISO3]; ok && file.Latest != nil {
+			file.Latest.Percentiles = row.Percentiles
+		}
+	}
+	countries := spliceCountryIndex(publishedCountries, reporters, countryIndex)
+	patchLatestMeta(&metadata, output.Rows, reporters, stale)
+	metadata.CountryFileCount = len(countries.Partitions)
+
+	if err := writeJSON(filepath.Join(outDir, "meta.json"), metadata); err != nil {
+		return artifactIndexFile{}, fmt.Errorf("write meta.json: %w", err)
+	}
+	if err := writeJSON(filepath.Join(outDir, "latest.json"), output); err != nil {
+		return artifactIndexFile{}, fmt.Errorf("write latest.json: %w", err)
+	}
+	for _, locale := range locales {
+		name := localizedLatestName(locale)
+		if err := writeJSON(filepath.Join(outDir, name), buildLocalizedLatest(output, locale)); err != nil {
+			return artifactIndexFile{}, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	countriesDir := filepath.Join(outDir, "countries")
+	if err := writeJSON(filepath.Join(countriesDir, "index.json"), countries); err != nil {
+		return artifactIndexFile{}, fmt.Errorf("write country index: %w", err)
+	}
+	if err := writeJSONFiles(countriesDir, ".json", countryFiles); err != nil {
+		return artifactIndexFile{}, fmt.Errorf("write country detail for %w", err)
+	}
+
+	records := previousIndex.Files
+	if artifacts != nil {
+		records = append(records, artifacts.records...)
+	}
+	return buildArtifactIndex(published.GeneratedAt, records), nil
+}
@@ -0,0 +1,129 @@
+package publisher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestParseOnlyNormalizesReporters(t *testing.T) {
+	reporters, err := parseOnly(" vnm,KOR,,kor ,MEX")
+	if err != nil {
+		t.Fatalf("parseOnly: %v", err)
+	}
+	if !reflect.DeepEqual(reporters, []string{"KOR", "MEX", "VNM"}) {
+		t.Fatalf("reporters = %v, want KOR MEX VNM", reporters)
+	}
+	if _, err := parseOnly("KOR,KOREA"); err == nil {
+		t.Fatal("expected an error for a non-ISO3 reporter")
+	}
+}
+
+func TestSpliceLatestReplacesOnlySelectedRows(t *testing.T) {
+	published := []latestEntry{{ISO3: "DEU", Total: 1}, {ISO3: "JPN", Total: 2}, {ISO3: "KOR", Total: 3}}
+	fresh := []latestEntry{{ISO3: "KOR", Total: 30}, {ISO3: "VNM", Total: 40}}
+
+	spliced := spliceLatest(published, []string{"JPN", "KOR", "VNM"}, fresh)
+	var got []string
+	for _, row := range spliced {
+		got = append(got, row.ISO3)
+	}
+	if !reflect.DeepEqual(got, []string{"DEU", "KOR", "VNM"}) {
+		t.Fatalf("spliced reporters = %v, want DEU KOR VNM (JPN had no fresh row)", got)
+	}
+	if spliced[0].Total != 1 || spliced[1].Total != 30 {
+		t.Fatalf("spliced rows = %+v, want DEU kept and KOR replaced", spliced)
+	}
+}
+
+func TestPatchLatestMetaRecountsRows(t *testing.T) {
+	annual := partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: 1}
+	older := partnerBlock{PeriodType: model.PeriodYear, Period: "2022", Trade: 1}
+	latest := []latestEntry{
+		{ISO3: "DEU", USA: annual, CHN: annual, SamePeriod: true, Partners: map[string]partnerBlock{"USA": annual, "CHN": annual}},
+		{ISO3: "KOR", USA: annual, CHN: older, Partners: map[string]partnerBlock{"USA": annual, "CHN": older}},
+	}
+	meta := metaFile{
+		Partners:            []string{"USA", "CHN"},
+		ReporterCount:       5,
+		ComparableReporters: 5,
+		SeriesPointCount:    42,
+		MaxStaleness:        "1y",
+		StaleReporters:      []string{"JPN", "KOR"},
+	}
+
+	patchLatestMeta(&meta, latest, []string{"KOR"}, nil)
+	if meta.ReporterCount != 2 || meta.ExpectedPartnerBlocks != 4 || meta.AvailablePartnerBlocks != 4 {
+		t.Fatalf("unexpected reporter and block counts: %+v", meta)
+	}
+	if meta.DominantPeriod != "Y:2023" || meta.ComparableReporters != 1 || meta.IncomparableReporters != 1 || meta.StalePartnerBlocks != 1 {
+		t.Fatalf("unexpected comparability counts: %+v", meta)
+	}
+	if meta.SeriesPointCount != 42 {
+		t.Fatalf("series_point_count = %d, want the previous build's 42", meta.SeriesPointCount)
+	}
+	if !reflect.DeepEqual(meta.StaleReporters, []string{"JPN"}) {
+		t.Fatalf("stale reporters = %v, want KOR cleared and JPN kept", meta.StaleReporters)
+	}
+}
+
+func TestWritePartialBuildKeepsOtherCountries(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, value any) {
+		t.Helper()
+		if err := writeJSON(filepath.Join(dir, name), value); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	block := partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: 1}
+	published := latestFile{SchemaVersion: schemaVersion, GeneratedAt: "2026-01-01T00:00:00Z", Provider: "wits", Partners: []string{"USA", "CHN"}, Rows: []latestEntry{
+		{ISO3: "DEU", USA: block, CHN: block, SamePeriod: true},
+		{ISO3: "KOR", USA: block, CHN: block, SamePeriod: true, Total: 2},
+	}}
+	write("latest.json", published)
+	write("meta.json", metaFile{SchemaVersion: schemaVersion, GeneratedAt: published.GeneratedAt, Partners: published.Partners, CountryFileCount: 2})
+	write("countries/index.json", countryIndexFile{Partitions: []countryPartition{
+		{ReporterISO3: "DEU", Href: "./DEU.json", PointCount: 3},
+		{ReporterISO3: "KOR", Href: "./KOR.json", PointCount: 3},
+	}})
+	write(artifactIndexName, artifactIndexFile{Files: []artifactRecord{{Path: "series.json", Size: 10}, {Path: "latest.json", Size: 1}}})
+
+	fresh := []latestEntry{{ISO3: "KOR", USA: block, CHN: block, SamePeriod: true, Total: 4}}
+	countries := countryIndexFile{Partitions: []countryPartition{{ReporterISO3: "KOR", Href: "./KOR.json", PointCount: 4}}}
+	files := map[string]countryFile{"KOR": {ReporterISO3: "KOR"}}
+	index, err := writePartialBuild(dir, []string{"KOR"}, published, fresh, nil, countries, files, nil)
+	if err != nil {
+		t.Fatalf("writePartialBuild: %v", err)
+	}
+
+	var latest latestFile
+	if err := readPublished(dir, "latest.json", &latest); err != nil {
+		t.Fatal(err)
+	}
+	if len(latest.Rows) != 2 || latest.Rows[0].ISO3 != "DEU" || latest.Rows[1].Total != 4 || latest.GeneratedAt != published.GeneratedAt {
+		t.Fatalf("patched latest = %+v", latest)
+	}
+	var countryIndex countryIndexFile
+	if err := readPublished(dir, "countries/index.json", &countryIndex); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(countryIndex.Reporters, []string{"DEU", "KOR"}) || countryIndex.Partitions[1].PointCount != 4 {
+		t.Fatalf("patched country index = %+v", countryIndex)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "countries", "KOR.json")); err != nil {
+		t.Fatalf("country file not written: %v", err)
+	}
+	paths := make(map[string]int64)
+	for _, record := range index.Files {
+		paths[record.Path] = record.Size
+	}
+	if paths["series.json"] != 10 {
+		t.Fatalf("index.json lost the untouched series.json record: %+v", index.Files)
+	}
+	if index.GeneratedAt != published.GeneratedAt {
+		t.Fatalf("index generated_at = %s, want the published %s", index.GeneratedAt, published.GeneratedAt)
+	}
+}
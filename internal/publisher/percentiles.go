@@ -0,0 +1,111 @@
+package publisher
+
+import "math"
+
+// percentileMinPeers is the fewest other reporters a group needs before a
+// percentile is published; with one peer every rank reads as 0% or 100%.
+const percentileMinPeers = 2
+
+// peerPercentiles places a reporter among the other reporters of its context
+// region and income group. Groups the reporter has no value for, or that
+// are too small, are left out.
+type peerPercentiles struct {
+	Region      *metricPercentiles `json:"region,omitempty"`
+	IncomeGroup *metricPercentiles `json:"income_group,omitempty"`
+}
+
+type metricPercentiles struct {
+	ShareCN *peerPercentile `json:"share_cn,omitempty"`
+	Growth  *peerPercentile `json:"growth,omitempty"`
+	Tilt    *peerPercentile `json:"tilt,omitempty"`
+}
+
+// peerPercentile is the fraction of Peers, the other reporters in the group
+// with the metric, whose value is strictly lower. 0.8 reads as "higher than
+// 80% of peers".
+type peerPercentile struct {
+	Percentile float64 `json:"percentile"`
+	Peers      int     `json:"peers"`
+}
+
+// percentileMetrics are the ranked headline metrics. share_cn and tilt need
+// comparable USA and CHN blocks; growth is the USA+CHN trade growth map.json
+// publishes.
+var percentileMetrics = []struct {
+	Set   func(*metricPercentiles, *peerPercentile)
+	Value func(latestEntry) (float64, bool)
+}{
+	{
+		Set: func(group *metricPercentiles, rank *peerPercentile) { group.ShareCN = rank },
+		Value: func(row latestEntry) (float64, bool) {
+			return row.ShareCN, !row.Incomparable && row.Total > 0
+		},
+	},
+	{
+		Set:   func(group *metricPercentiles, rank *peerPercentile) { group.Growth = rank },
+		Value: func(row latestEntry) (float64, bool) { return totalGrowth(row.USA, row.CHN) },
+	},
+	{
+		Set: func(group *metricPercentiles, rank *peerPercentile) { group.Tilt = rank },
+		Value: func(row latestEntry) (float64, bool) {
+			return (row.CHN.Trade - row.USA.Trade) / row.Total, !row.Incomparable && row.Total > 0
+		},
+	},
+}
+
+// applyPeerPercentiles sets Percentiles on every row from the rows passed
+// in, so it must run on the complete latest set after context enrichment.
+func applyPeerPercentiles(latest []latestEntry) {
+	groupings := []struct {
+		Key func(latestEntry) string
+		Set func(*peerPercentiles, *metricPercentiles)
+	}{
+		{Key: func(row latestEntry) string { return row.Region }, Set: func(p *peerPercentiles, m *metricPercentiles) { p.Region = m }},
+		{Key: func(row latestEntry) string { return row.IncomeGroup }, Set: func(p *peerPercentiles, m *metricPercentiles) { p.IncomeGroup = m }},
+	}
+	ranks := make([]peerPercentiles, len(latest))
+	for _, grouping := range groupings {
+		members := make(map[string][]int)
+		for index, row := range latest {
+			if key := grouping.Key(row); key != "" {
+				members[key] = append(members[key], index)
+			}
+		}
+		for _, indexes := range members {
+			groups := make(map[int]*metricPercentiles)
+			for _, metric := range percentileMetrics {
+				values := make(map[int]float64, len(indexes))
+				for _, index := range indexes {
+					if value, ok := metric.Value(latest[index]); ok && !math.IsNaN(value) && !math.IsInf(value, 0) {
+						values[index] = value
+					}
+				}
+				if len(values) <= percentileMinPeers {
+					continue
+				}
+				for index, value := range values {
+					lower := 0
+					for other, otherValue := range values {
+						if other != index && otherValue < value {
+							lower++
+						}
+					}
+					if groups[index] == nil {
+						groups[index] = &metricPercentiles{}
+					}
+					metric.Set(groups[index], &peerPercentile{Percentile: float64(lower) / float64(len(values)-1), Peers: len(values) - 1})
+				}
+			}
+			for index, group := range groups {
+				grouping.Set(&ranks[index], group)
+			}
+		}
+	}
+	for index := range latest {
+		latest[index].Percentiles = nil
+		if ranks[index].Region != nil || ranks[index].IncomeGroup != nil {
+			rank := ranks[index]
+			latest[index].Percentiles = &rank
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyPeerPercentilesRanksWithinRegionAndIncomeGroup(t *testing.T) {
+	growth := func(value float64) *growthBlock { return &growthBlock{Trade: &value} }
+	row := func(iso3, region, income string, usa, chn float64) latestEntry {
+		return latestEntry{
+			ISO3: iso3, Region: region, IncomeGroup: income,
+			USA:   partnerBlock{PeriodType: "Y", Period: "2024", Trade: usa, Growth: growth(0.1), GrowthBasis: growthYoY},
+			CHN:   partnerBlock{PeriodType: "Y", Period: "2024", Trade: chn, Growth: growth(0.1), GrowthBasis: growthYoY},
+			Total: usa + chn, ShareCN: chn / (usa + chn),
+		}
+	}
+	latest := []latestEntry{
+		row("KOR", "East Asia", "High income", 40, 60),
+		row("JPN", "East Asia", "High income", 60, 40),
+		row("VNM", "East Asia", "Lower middle income", 30, 70),
+		row("MNG", "East Asia", "", 10, 90),
+		row("DEU", "Europe", "High income", 50, 50),
+	}
+	latest[2].Incomparable = true
+	latest[3].CHN.Growth = growth(0.5)
+	applyPeerPercentiles(latest)
+
+	kor := latest[0].Percentiles
+	if kor == nil || kor.Region == nil || kor.IncomeGroup == nil {
+		t.Fatalf("expected region and income group ranks for KOR, got %+v", kor)
+	}
+	// East Asia has KOR, JPN, and MNG comparable; VNM is incomparable.
+	if rank := kor.Region.ShareCN; rank == nil || rank.Peers != 2 || rank.Percentile != 0.5 {
+		t.Fatalf("KOR region share_cn = %+v, want higher than one of two peers", rank)
+	}
+	if rank := kor.Region.Tilt; rank == nil || *rank != *kor.Region.ShareCN {
+		t.Fatalf("KOR region tilt = %+v, want the share_cn rank", rank)
+	}
+	if rank := latest[3].Percentiles.Region.Growth; rank == nil || rank.Peers != 3 || rank.Percentile != 1 {
+		t.Fatalf("MNG region growth = %+v, want higher than all three peers", rank)
+	}
+	if rank := kor.IncomeGroup.ShareCN; rank == nil || rank.Peers != 2 || math.Abs(rank.Percentile-1) > 1e-12 {
+		t.Fatalf("KOR income share_cn = %+v, want higher than JPN and DEU", rank)
+	}
+	if latest[2].Percentiles.Region.ShareCN != nil || latest[2].Percentiles.IncomeGroup != nil {
+		t.Fatalf("VNM is incomparable and alone in its income group, got %+v", latest[2].Percentiles)
+	}
+	if latest[4].Percentiles.Region != nil || latest[3].Percentiles.IncomeGroup != nil {
+		t.Fatalf("expected no ranks for a one-member region or a missing income group")
+	}
+}
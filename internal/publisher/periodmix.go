@@ -0,0 +1,131 @@
+package publisher
+
+import (
+	"fmt"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// Mixed period-type policies accepted by -mixed-periods. They apply when the
+// USA and CHN blocks end up on different period types, for example an annual
+// USA block beside a monthly CHN block, whose sum is not a meaningful total.
+// mixedAllow publishes such rows unchanged. mixedDowngrade moves the finer
+// block to the coarser period type, summing complete months or quarters when
+// that type is not reported. mixedIncomparable keeps both blocks but marks
+// the row incomparable and zeroes total and share_cn.
+const (
+	mixedAllow        = "allow"
+	mixedDowngrade    = "downgrade"
+	mixedIncomparable = "incomparable"
+)
+
+func parseMixedPeriods(value string) (string, error) {
+	policy := strings.ToLower(strings.TrimSpace(value))
+	switch policy {
+	case mixedAllow, mixedDowngrade, mixedIncomparable:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported mixed-periods policy %q (expected allow, downgrade, or incomparable)", value)
+	}
+}
+
+// downgradePeriodTypes moves whichever of the USA and CHN blocks has the finer
+// period type to the latest period of the coarser type. The finer partner's
+// series gains the summed coarse periods, so growth can be computed on them.
+// Values are returned unchanged when the types already match or the finer
+// partner has no complete coarse period.
+func downgradePeriodTypes(values map[string]map[model.Flow]latestValue, series map[string]map[model.Flow]map[string]float64) (map[string]map[model.Flow]latestValue, map[string]map[model.Flow]map[string]float64) {
+	usaType, _ := selectLatestPeriod(values["USA"][model.FlowExport], values["USA"][model.FlowImport])
+	chnType, _ := selectLatestPeriod(values["CHN"][model.FlowExport], values["CHN"][model.FlowImport])
+	if usaType == "" || chnType == "" || usaType == chnType {
+		return values, series
+	}
+	finer, coarse := "USA", chnType
+	if periodPriority(chnType) > periodPriority(usaType) {
+		finer, coarse = "CHN", usaType
+	}
+
+	rolled := rollUpSeries(series[finer], coarse)
+	ref := latestOfType(seriesPeriods(rolled), coarse)
+	if ref.Period == "" {
+		return values, series
+	}
+	downgradedValues := make(map[string]map[model.Flow]latestValue, len(values))
+	for partner, partnerValues := range values {
+		downgradedValues[partner] = partnerValues
+	}
+	downgradedValues[finer] = valuesAt(rolled, ref)
+	downgradedSeries := make(map[string]map[model.Flow]map[string]float64, len(series))
+	for partner, partnerSeries := range series {
+		downgradedSeries[partner] = partnerSeries
+	}
+	downgradedSeries[finer] = rolled
+	return downgradedValues, downgradedSeries
+}
+
+// rollUpSeries returns a copy of series with every complete coarse period
+// summed from finer ones. Reported coarse values take precedence.
+func rollUpSeries(series map[model.Flow]map[string]float64, coarse model.PeriodType) map[model.Flow]map[string]float64 {
+	rolled := make(map[model.Flow]map[string]float64, len(series))
+	for flow, flowSeries := range series {
+		sums := make(map[string]float64)
+		counts := make(map[string]int)
+		copied := make(map[string]float64, len(flowSeries))
+		for key, value := range flowSeries {
+			copied[key] = value
+			periodType, period, ok := strings.Cut(key, "|")
+			if !ok {
+				continue
+			}
+			target, parts, ok := coarsePeriod(model.PeriodType(periodType), period, coarse)
+			if !ok {
+				continue
+			}
+			sums[target] += value
+			counts[target]++
+			if counts[target] == parts {
+				if _, reported := flowSeries[seriesKey(coarse, target)]; !reported {
+					copied[seriesKey(coarse, target)] = sums[target]
+				}
+			}
+		}
+		rolled[flow] = copied
+	}
+	return rolled
+}
+
+// coarsePeriod maps a month or quarter to the quarter or year containing it,
+// along with how many such parts make the coarse period complete.
+func coarsePeriod(periodType model.PeriodType, period string, coarse model.PeriodType) (string, int, bool) {
+	switch {
+	case periodType == model.PeriodMonth && coarse == model.PeriodYear:
+		if year, _, ok := model.ParseYearMonth(period); ok {
+			return fmt.Sprintf("%04d", year), 12, true
+		}
+	case periodType == model.PeriodMonth && coarse == model.PeriodQuarter:
+		if year, month, ok := model.ParseYearMonth(period); ok {
+			return fmt.Sprintf("%04d-Q%d", year, (month-1)/3+1), 3, true
+		}
+	case periodType == model.PeriodQuarter && coarse == model.PeriodYear:
+		if year, _, ok := model.ParseYearQuarter(period); ok {
+			return fmt.Sprintf("%04d", year), 4, true
+		}
+	}
+	return "", 0, false
+}
+
+// markIncomparable flags rows whose USA and CHN blocks are on different
+// period types and zeroes the USA+CHN total and China share.
+func markIncomparable(rows []latestEntry) {
+	for index := range rows {
+		row := &rows[index]
+		if row.USA.Period == "" || row.CHN.Period == "" || row.USA.PeriodType == row.CHN.PeriodType {
+			continue
+		}
+		row.Incomparable = true
+		row.Total = 0
+		row.ShareCN = 0
+		row.ShareCNTTM = nil
+	}
+}
@@ -0,0 +1,58 @@
+package publisher
+
+import (
+	"fmt"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func mixedPeriodRows() []observationRow {
+	var rows []observationRow
+	for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+		rows = append(rows,
+			observationRow{ReporterISO: "KOR", PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodYear, Period: "2023", Value: 10000},
+			observationRow{ReporterISO: "KOR", PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodMonth, Period: "2024-01", Value: 5000},
+		)
+		for month := 1; month <= 12; month++ {
+			rows = append(rows, observationRow{ReporterISO: "KOR", PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodMonth, Period: fmt.Sprintf("2023-%02d", month), Value: 1000})
+		}
+	}
+	return rows
+}
+
+func TestBuildLatestHandlesMixedPeriodTypes(t *testing.T) {
+	allowed := buildLatest(mixedPeriodRows(), []string{"USA", "CHN"}, growthYoY, alignLatest, mixedAllow)[0]
+	if allowed.CHN.Period != "2024-01" || allowed.Total != 300 || allowed.Incomparable {
+		t.Fatalf("allow: chn=%q total=%v incomparable=%v, want monthly block summed as before", allowed.CHN.Period, allowed.Total, allowed.Incomparable)
+	}
+
+	downgraded := buildLatest(mixedPeriodRows(), []string{"USA", "CHN"}, growthYoY, alignLatest, mixedDowngrade)[0]
+	if downgraded.CHN.PeriodType != model.PeriodYear || downgraded.CHN.Period != "2023" || downgraded.CHN.Trade != 240 {
+		t.Fatalf("downgrade: chn = %+v, want 2023 summed from twelve months", downgraded.CHN)
+	}
+	if !downgraded.SamePeriod || downgraded.Total != 440 || downgraded.Partners["CHN"].Period != "2023" {
+		t.Fatalf("downgrade: same_period=%v total=%v, want comparable 2023 total", downgraded.SamePeriod, downgraded.Total)
+	}
+
+	incomparable := buildLatest(mixedPeriodRows(), []string{"USA", "CHN"}, growthYoY, alignLatest, mixedIncomparable)[0]
+	if !incomparable.Incomparable || incomparable.Total != 0 || incomparable.ShareCN != 0 || incomparable.CHN.Trade != 100 {
+		t.Fatalf("incomparable: %+v, want flagged row with blocks kept and no total", incomparable)
+	}
+}
+
+func TestRollUpSeriesSkipsIncompleteYears(t *testing.T) {
+	series := map[model.Flow]map[string]float64{model.FlowExport: {
+		seriesKey(model.PeriodQuarter, "2023-Q1"): 1,
+		seriesKey(model.PeriodQuarter, "2023-Q2"): 2,
+		seriesKey(model.PeriodQuarter, "2023-Q3"): 3,
+	}}
+	rolled := rollUpSeries(series, model.PeriodYear)
+	if _, ok := rolled[model.FlowExport][seriesKey(model.PeriodYear, "2023")]; ok {
+		t.Fatal("rollUpSeries() summed a year with three quarters")
+	}
+	series[model.FlowExport][seriesKey(model.PeriodQuarter, "2023-Q4")] = 4
+	if got := rollUpSeries(series, model.PeriodYear)[model.FlowExport][seriesKey(model.PeriodYear, "2023")]; got != 10 {
+		t.Fatalf("2023 = %v, want 10", got)
+	}
+}
@@ -0,0 +1,1759 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"tradegravity/internal/buildinfo"
+	"tradegravity/internal/cdn"
+	"tradegravity/internal/cli"
+	"tradegravity/internal/config"
+	"tradegravity/internal/iso"
+	"tradegravity/internal/mail"
+	"tradegravity/internal/model"
+	"tradegravity/internal/objectstore"
+	"tradegravity/internal/semiconductor"
+	"tradegravity/internal/strategic"
+)
+
+const schemaVersion = "2.0"
+
+type metaFile struct {
+	SchemaVersion                        string          `json:"schema_version"`
+	GeneratedAt                          string          `json:"generated_at"`
+	Build                                *buildinfo.Info `json:"build,omitempty"`
+	Provider                             string          `json:"provider"`
+	Partners                             []string        `json:"partners"`
+	ReporterCount                        int             `json:"reporter_count"`
+	ObservationCount                     int             `json:"observation_count"`
+	ExpectedPartnerBlocks                int             `json:"expected_partner_blocks"`
+	AvailablePartnerBlocks               int             `json:"available_partner_blocks"`
+	MissingPartnerBlocks                 int             `json:"missing_partner_blocks"`
+	PeriodCounts                         map[string]int  `json:"period_counts"`
+	DominantPeriod                       string          `json:"dominant_period"`
+	ShareAlignment                       string          `json:"share_alignment,omitempty"`
+	MixedPeriods                         string          `json:"mixed_periods,omitempty"`
+	ProviderMerge                        string          `json:"provider_merge,omitempty"`
+	Currencies                           []string        `json:"currencies,omitempty"`
+	MaxStaleness                         string          `json:"max_staleness,omitempty"`
+	StalePolicy                          string          `json:"stale_policy,omitempty"`
+	StaleReporters                       []string        `json:"stale_reporters,omitempty"`
+	ComparableReporters                  int             `json:"comparable_reporters"`
+	IncomparableReporters                int             `json:"incomparable_reporters"`
+	StalePartnerBlocks                   int             `json:"stale_partner_blocks"`
+	SeriesReporterCount                  int             `json:"series_reporter_count"`
+	SeriesPointCount                     int             `json:"series_point_count"`
+	HistoryReporterCount                 int             `json:"history_reporter_count"`
+	HistoryPointCount                    int             `json:"history_point_count"`
+	HistoryFirstPeriod                   string          `json:"history_first_period,omitempty"`
+	HistoryLastPeriod                    string          `json:"history_last_period,omitempty"`
+	CountryFileCount                     int             `json:"country_file_count"`
+	AggregateCount                       int             `json:"aggregate_count"`
+	CoverageReporterCount                int             `json:"coverage_reporter_count"`
+	ProductProvider                      string          `json:"product_provider,omitempty"`
+	ProductClassification                string          `json:"product_classification,omitempty"`
+	ProductLevel                         int             `json:"product_level,omitempty"`
+	ProductReporterCount                 int             `json:"product_reporter_count"`
+	ProductObservationCount              int             `json:"product_observation_count"`
+	ContextStatus                        string          `json:"context_status"`
+	StrategicProvider                    string          `json:"strategic_provider,omitempty"`
+	StrategicLevel                       int             `json:"strategic_level,omitempty"`
+	StrategicProductCount                int             `json:"strategic_product_count"`
+	StrategicReporterCount               int             `json:"strategic_reporter_count"`
+	StrategicPartitionCount              int             `json:"strategic_partition_count"`
+	StrategicObservationCount            int             `json:"strategic_observation_count"`
+	TariffProvider                       string          `json:"tariff_provider,omitempty"`
+	TariffImporterCount                  int             `json:"tariff_importer_count"`
+	TariffPartitionCount                 int             `json:"tariff_partition_count"`
+	TariffObservationCount               int             `json:"tariff_observation_count"`
+	MatrixProvider                       string          `json:"matrix_provider,omitempty"`
+	MatrixReporterCount                  int             `json:"matrix_reporter_count"`
+	MatrixPartitionCount                 int             `json:"matrix_partition_count"`
+	MatrixPartnerRowCount                int             `json:"matrix_partner_row_count"`
+	MatrixObservationCount               int             `json:"matrix_observation_count"`
+	MirrorProvider                       string          `json:"mirror_provider,omitempty"`
+	MirrorReporterCount                  int             `json:"mirror_reporter_count"`
+	MirrorPartitionCount                 int             `json:"mirror_partition_count"`
+	MirrorComparisonCount                int             `json:"mirror_comparison_count"`
+	SemiconductorStatus                  string          `json:"semiconductor_status,omitempty"`
+	SemiconductorCodeCount               int             `json:"semiconductor_code_count"`
+	SemiconductorReporterCount           int             `json:"semiconductor_reporter_count"`
+	SemiconductorPeriodCount             int             `json:"semiconductor_period_count"`
+	SemiconductorMonthlyProvider         string          `json:"semiconductor_monthly_provider,omitempty"`
+	SemiconductorMonthlyReporterCount    int             `json:"semiconductor_monthly_reporter_count"`
+	SemiconductorMonthlyPeriodCount      int             `json:"semiconductor_monthly_period_count"`
+	SemiconductorMonthlyObservationCount int             `json:"semiconductor_monthly_observation_count"`
+}
+
+type latestFile struct {
+	SchemaVersion string        `json:"schema_version"`
+	GeneratedAt   string        `json:"generated_at"`
+	Provider      string        `json:"provider"`
+	Partners      []string      `json:"partners"`
+	Rows          []latestEntry `json:"rows"`
+}
+
+type latestEntry struct {
+	ISO3             string        `json:"iso3"`
+	ISO2             string        `json:"iso2,omitempty"`
+	Name             string        `json:"name,omitempty"`
+	NameKO           string        `json:"name_ko,omitempty"`
+	Region           string        `json:"region,omitempty"`
+	Subregion        string        `json:"subregion,omitempty"`
+	IncomeGroup      string        `json:"income_group,omitempty"`
+	Groups           []string      `json:"groups,omitempty"`
+	Population       contextMetric `json:"population"`
+	GDP              contextMetric `json:"gdp"`
+	USA              partnerBlock  `json:"usa"`
+	CHN              partnerBlock  `json:"chn"`
+	Total            float64       `json:"total"`
+	ShareCN          float64       `json:"share_cn"`
+	SamePeriod       bool          `json:"same_period"`
+	ComparisonPeriod string        `json:"comparison_period,omitempty"`
+	// ComparisonPeriodType pairs with ComparisonPeriod to name the period
+	// ShareCN was computed on; both are empty when the blocks differ.
+	ComparisonPeriodType model.PeriodType `json:"comparison_period_type,omitempty"`
+	// Partners holds a block for every tracked partner with data, keyed by
+	// ISO3. USA and CHN above mirror their entries for the US-China lens.
+	Partners map[string]partnerBlock `json:"partners"`
+	// TrackedTotal is the trade summed across every tracked partner, and
+	// Shares is each partner's fraction of it. With the default USA,CHN
+	// partners TrackedTotal equals Total and Shares["CHN"] equals ShareCN.
+	TrackedTotal float64            `json:"tracked_total"`
+	Shares       map[string]float64 `json:"shares"`
+	// ShareCNTTM is the China share of USA+CHN trade over the trailing twelve
+	// months, set when both monthly blocks have trailing totals through the
+	// same month.
+	ShareCNTTM *float64 `json:"share_cn_ttm,omitempty"`
+	// Incomparable is set under -mixed-periods incomparable when the USA and
+	// CHN blocks are on different period types; Total and ShareCN are zero.
+	Incomparable bool `json:"incomparable,omitempty"`
+	// Concentration is the HHI over the tracked partner blocks, set when they
+	// all share one period.
+	Concentration *concentration `json:"concentration,omitempty"`
+	// Percentiles ranks ShareCN, USA+CHN trade growth, and the tilt index
+	// among the reporter's context region and income group.
+	Percentiles *peerPercentiles `json:"percentiles,omitempty"`
+	// Currencies holds the partner blocks converted into each -currencies
+	// code other than USD.
+	Currencies map[string]currencyValues `json:"currencies,omitempty"`
+	// Stale is set under -stale-policy flag when the reporter's freshest
+	// partner block is older than -max-staleness.
+	Stale bool `json:"stale,omitempty"`
+}
+
+type partnerBlock struct {
+	Period      string           `json:"period"`
+	PeriodType  model.PeriodType `json:"period_type"`
+	PrevPeriod  string           `json:"prev_period,omitempty"`
+	Export      float64          `json:"export"`
+	Import      float64          `json:"import"`
+	Trade       float64          `json:"trade"`
+	Growth      *growthBlock     `json:"growth,omitempty"`
+	GrowthBasis string           `json:"growth_basis,omitempty"`
+	TTM         *trailingBlock   `json:"ttm,omitempty"`
+	// ShareOfTotal is Trade divided by the reporter's trade with the world
+	// (partner WLD) in the same period.
+	ShareOfTotal *float64 `json:"share_of_total,omitempty"`
+	// TradePerCapita and TradeShareOfGDP divide an annual block's Trade by
+	// the reporter's WDI population and GDP.
+	TradePerCapita  *float64 `json:"trade_per_capita,omitempty"`
+	TradeShareOfGDP *float64 `json:"trade_share_of_gdp,omitempty"`
+	// Estimated is set when the block's export or import is not an
+	// official reported figure; QualityNote names how it was derived.
+	Estimated   bool   `json:"estimated,omitempty"`
+	QualityNote string `json:"quality_note,omitempty"`
+}
+
+type growthBlock struct {
+	Export *float64 `json:"export"`
+	Import *float64 `json:"import"`
+	Trade  *float64 `json:"trade"`
+}
+
+type observationRow struct {
+	Provider       string
+	ReporterISO    string
+	PartnerISO     string
+	Flow           model.Flow
+	PeriodType     model.PeriodType
+	Period         string
+	Value          model.Cents
+	Classification string
+	ProductCode    string
+	ProductLevel   int
+	Estimated      bool
+	QualityNote    string
+}
+
+type latestValue struct {
+	PeriodType model.PeriodType
+	Period     string
+	ValueUSD   float64
+	Valid      bool
+}
+
+// Main runs the publisher subcommand named by args[0]. program is the name
+// printed in usage text.
+func Main(program string, args []string) {
+	if len(args) < 1 {
+		usage(program)
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "-version", "--version":
+		buildinfo.Print(os.Stdout, program)
+	case "build":
+		Build(args[1:])
+	case "validate":
+		Validate(args[1:])
+	case "compare":
+		Compare(args[1:])
+	case "report":
+		Report(args[1:])
+	case "serve":
+		Serve(program, args[1:])
+	case "analytics":
+		Analytics(program+" analytics", args[1:])
+	default:
+		usage(program)
+		os.Exit(2)
+	}
+}
+
+// Build parses the build flags in args and writes every site artifact. It
+// exits the process on failure.
+func Build(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	outDir := fs.String("out", "site/data", "output directory")
+	dbPath := fs.String("db", "tradegravity.db", "sqlite database path")
+	lockWait := fs.Duration("lock-wait", 0, "wait this long for another run holding the -db lock before exiting without building (0 = exit at once)")
+	provider := fs.String("provider", "wits", "provider id")
+	providerMergeValue := fs.String("provider-merge", "", "per-period-type provider preference replacing -provider, e.g. M=comtrade,Y=wits/comtrade (optional)")
+	partnersCSV := fs.String("partners", "USA,CHN", "comma-separated partner ISO3 list (must include USA,CHN)")
+	contextPath := fs.String("context", "site/data/context.json", "country context JSON (optional)")
+	productProvider := fs.String("product-provider", "comtrade", "HS2 product provider")
+	matrixProvider := fs.String("matrix-provider", "comtrade", "bilateral matrix provider")
+	specialPartners := fs.String("special-partners", "", "handling overrides for non-country matrix partners, e.g. EUU=publish (publish, store, or drop)")
+	productLevel := fs.Int("product-level", 2, "product aggregation level")
+	hs2Path := fs.String("hs2", "configs/hs2.csv", "HS2 labels CSV")
+	strategicRegistryPath := fs.String("strategic-registry", "configs/strategic_hs6.csv", "strategic HS6 registry CSV")
+	semiconductorReferencePath := fs.String("semiconductor-reference", "configs/semiconductor_reference.json", "semiconductor value-chain reference JSON")
+	attributionPath := fs.String("attribution", "configs/attribution.json", "provider attribution JSON credited in meta.json and every JSON artifact (optional)")
+	previousDir := fs.String("previous-dir", "", "previous published data directory for publish-to-publish comparison (optional; diff.json falls back to -out)")
+	seriesYears := fs.Int("series-years", 10, "maximum number of annual periods per reporter")
+	rankingsTop := fs.Int("rankings-top", 20, "reporters kept per ranking in rankings.json (0 = all)")
+	moversTop := fs.Int("movers-top", 10, "reporters kept per list in movers.json (0 = all)")
+	diversionTop := fs.Int("diversion-top", 10, "reporters kept per list in diversion.json (0 = all)")
+	forecastHorizonValue := fs.Int("forecast-horizon", forecastMaxHorizon, "periods projected per series in forecast.json (1-4)")
+	consistencyTolerance := fs.Float64("consistency-tolerance", defaultConsistencyTolerance, "largest relative gap between an annual value and its summed months left unflagged in quality.json")
+	growthBasis := fs.String("growth-basis", "yoy", "latest growth basis: yoy, mom, qoq, or ytd")
+	schema := fs.String("schema", "v2", "meta.json and latest.json shape: v2, or v1 for older frontends")
+	align := fs.String("align", "latest", "USA/CHN period alignment for share_cn: latest, common, or period-type")
+	mixed := fs.String("mixed-periods", "allow", "USA/CHN blocks on different period types: allow, downgrade, or incomparable")
+	maxStalenessValue := fs.String("max-staleness", "", "flag or exclude reporters whose freshest period ended longer ago than this, e.g. 3y, 18m, 90d (optional)")
+	stalePolicyValue := fs.String("stale-policy", "flag", "reporters past -max-staleness: flag (stale: true) or exclude")
+	generatedAt := fs.String("generated-at", "", "RFC3339 publication time to use instead of SOURCE_DATE_EPOCH or now (optional)")
+	layoutFlag := fs.String("layout", "", "comma-separated output path templates using {artifact}, {iso3}, {period}, {name}, {ext} (optional)")
+	only := fs.String("only", "", "comma-separated reporters to rebuild, patching latest.json, meta.json, and countries/ of the build already in -out (optional)")
+	uploadTarget := fs.String("publish-to", "", "upload the built artifacts to s3://, gs://, or az://bucket/prefix, or sftp://user@host/path (optional)")
+	purgeTarget := fs.String("purge-cdn", "", "after -publish-to, purge the changed files from cloudfront:<distribution> or cloudflare:<zone> (optional)")
+	purgeBase := fs.String("cdn-base", "", "where -publish-to files are served: a URL, or for cloudfront a path (default: /<publish-to prefix>)")
+	releaseRepo := fs.String("publish-release", "", "attach a zip of the built artifacts and SHA256SUMS to a GitHub release in owner/name (optional)")
+	releaseTagValue := fs.String("release-tag", "", "tag of the -publish-release release (default: data-<generated_at>)")
+	sheetTarget := fs.String("publish-sheet", "", "write the latest and rankings tables into this Google Sheet ID or URL (optional)")
+	emailTo := fs.String("email-to", "", "email a digest of the publish to these comma-separated addresses over SMTP (optional)")
+	webhookTarget := fs.String("post-to", "", "POST latest.json and diff.json to this webhook URL after the build (optional)")
+	currenciesCSV := fs.String("currencies", "USD", "comma-separated currencies; codes other than USD are converted with the fx_rates table, e.g. USD,KRW")
+	localesCSV := fs.String("locales", "", "comma-separated locales for labelled latest.{locale}.json files: en, ko (optional)")
+	format := fs.String("format", "json", "comma-separated output formats (json always written; csv, parquet, xlsx add tabular exports; ndjson adds history.ndjson)")
+	chartsCSV := fs.String("charts", "", "comma-separated chart image formats to write under charts/: svg, png (optional)")
+	concurrency := fs.Int("concurrency", runtime.GOMAXPROCS(0), "partition files (countries/, products/, tariffs/, ...) encoded and written at once")
+	config.Parse(fs, args)
+	defer cli.LockStore(*dbPath, *lockWait, "publisher build")()
+
+	formats, err := parseExportFormats(*format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid format:", err)
+		os.Exit(1)
+	}
+	forecastHorizon, err := parseForecastHorizon(*forecastHorizonValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid forecast-horizon:", err)
+		os.Exit(1)
+	}
+	if *concurrency < 1 {
+		fmt.Fprintln(os.Stderr, "invalid concurrency: must be at least 1")
+		os.Exit(1)
+	}
+	writeConcurrency = *concurrency
+	if !(*consistencyTolerance >= 0) {
+		fmt.Fprintln(os.Stderr, "invalid consistency-tolerance: must be zero or more")
+		os.Exit(1)
+	}
+	chartFormats, err := parseChartFormats(*chartsCSV)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid charts:", err)
+		os.Exit(1)
+	}
+	threshold, err := parseMaxStaleness(*maxStalenessValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid max-staleness:", err)
+		os.Exit(1)
+	}
+	stalePolicy, err := parseStalePolicy(*stalePolicyValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid stale-policy:", err)
+		os.Exit(1)
+	}
+	partnerPolicy, err := model.ParsePartnerPolicy(*specialPartners)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid special-partners:", err)
+		os.Exit(1)
+	}
+	currencies, err := parseCurrencies(*currenciesCSV)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid currencies:", err)
+		os.Exit(1)
+	}
+	locales, err := parseLocales(*localesCSV)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid locales:", err)
+		os.Exit(1)
+	}
+	if *uploadTarget != "" {
+		if _, err := objectstore.ParseTarget(*uploadTarget); err != nil {
+			fmt.Fprintln(os.Stderr, "invalid publish-to:", err)
+			os.Exit(1)
+		}
+	}
+	targets := publishTargets{upload: *uploadTarget}
+	if *purgeTarget != "" {
+		if *uploadTarget == "" {
+			fmt.Fprintln(os.Stderr, "invalid purge-cdn: requires -publish-to")
+			os.Exit(1)
+		}
+		targets.purge, err = cdn.ParseTarget(*purgeTarget)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid purge-cdn:", err)
+			os.Exit(1)
+		}
+		targets.purgeBase = *purgeBase
+		if targets.purgeBase == "" {
+			if targets.purge.Provider == "cloudflare" {
+				fmt.Fprintln(os.Stderr, "invalid purge-cdn: cloudflare needs -cdn-base with the URL the files are served from")
+				os.Exit(1)
+			}
+			parsed, _ := objectstore.ParseTarget(*uploadTarget)
+			if parsed.Scheme == "sftp" {
+				fmt.Fprintln(os.Stderr, "invalid purge-cdn: an sftp upload needs -cdn-base with where the files are served")
+				os.Exit(1)
+			}
+			targets.purgeBase = "/" + parsed.Prefix
+		}
+	}
+	if *releaseRepo != "" {
+		targets.releaseRepo, err = parseReleaseRepo(*releaseRepo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid publish-release:", err)
+			os.Exit(1)
+		}
+	}
+	targets.releaseTag, err = parseReleaseTag(*releaseTagValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid release-tag:", err)
+		os.Exit(1)
+	}
+	if *sheetTarget != "" {
+		targets.spreadsheetID, err = parseSpreadsheetID(*sheetTarget)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid publish-sheet:", err)
+			os.Exit(1)
+		}
+	}
+	if *webhookTarget != "" {
+		targets.webhook, err = parseWebhookURL(*webhookTarget)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid post-to:", err)
+			os.Exit(1)
+		}
+	}
+	if *emailTo != "" {
+		targets.emailTo, err = mail.ParseRecipients(*emailTo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid email-to:", err)
+			os.Exit(1)
+		}
+	}
+	basis, err := parseGrowthBasis(*growthBasis)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid growth basis:", err)
+		os.Exit(1)
+	}
+	outputSchema, err := parseSchema(*schema)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid schema:", err)
+		os.Exit(1)
+	}
+	alignment, err := parseAlignment(*align)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid alignment:", err)
+		os.Exit(1)
+	}
+	mixedPeriods, err := parseMixedPeriods(*mixed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid mixed-periods:", err)
+		os.Exit(1)
+	}
+	providerMerge, err := parseProviderMerge(*providerMergeValue)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid provider-merge:", err)
+		os.Exit(1)
+	}
+	attribution, err := loadAttribution(*attributionPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid attribution:", err)
+		os.Exit(1)
+	}
+	totalsProviders := []string{strings.ToLower(strings.TrimSpace(*provider))}
+	if providerMerge.enabled() {
+		totalsProviders = providerMerge.providers()
+	}
+	sourceProvider := *provider
+	if providerMerge.enabled() {
+		// Totals are read from every provider and merged; outputs are labelled merged.
+		sourceProvider, *provider = "", mergedProvider
+	}
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create output dir:", err)
+		os.Exit(1)
+	}
+	artifacts, err = newArtifactWriter(*outDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load artifact checksums:", err)
+		os.Exit(1)
+	}
+	artifacts.layout, err = parseLayout(*layoutFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid layout:", err)
+		os.Exit(1)
+	}
+
+	partners, err := iso.ParseList(*partnersCSV)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid partners:", err)
+		os.Exit(1)
+	}
+	if err := ensureRequiredPartners(partners, []string{"USA", "CHN"}); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid partners:", err)
+		os.Exit(1)
+	}
+
+	onlyReporters, err := parseOnly(*only)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid only:", err)
+		os.Exit(1)
+	}
+	var published latestFile
+	if len(onlyReporters) > 0 {
+		if outputSchema != schemaV2 {
+			fmt.Fprintln(os.Stderr, "invalid only: partial rebuilds need -schema v2")
+			os.Exit(1)
+		}
+		if len(chartFormats) > 0 {
+			fmt.Fprintln(os.Stderr, "invalid only: -charts needs a full build")
+			os.Exit(1)
+		}
+		published, err = loadPublishedLatest(*outDir, *provider, partners)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load the build to patch:", err)
+			os.Exit(1)
+		}
+	}
+
+	world := newWorldTotals(providerMerge)
+	if err := eachObservationChunk(*dbPath, sourceProvider, []string{worldPartner}, onlyReporters, world.add); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load world observations:", err)
+		os.Exit(1)
+	}
+	// eachReporter streams the tracked-partner observations a reporter at a
+	// time, merged when -provider-merge is set. No step holds them all.
+	eachReporter := func(fn func([]observationRow)) error {
+		return eachReporterObservations(*dbPath, sourceProvider, partners, onlyReporters, func(group []observationRow) error {
+			if providerMerge.enabled() {
+				group = providerMerge.apply(group)
+			}
+			fn(group)
+			return nil
+		})
+	}
+
+	now, err := publicationTime(*generatedAt)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid generated-at:", err)
+		os.Exit(1)
+	}
+	if now == "" {
+		now = time.Now().UTC().Format(time.RFC3339)
+	}
+	if len(onlyReporters) > 0 {
+		// Patched rows keep the publication time of the files around them.
+		now = published.GeneratedAt
+	}
+	var latest []latestEntry
+	if latestWindowSupported(alignment, mixedPeriods) && !providerMerge.enabled() {
+		latestRows, err := loadLatestObservations(*dbPath, sourceProvider, partners, onlyReporters)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load latest observations:", err)
+			os.Exit(1)
+		}
+		latest = buildLatest(latestRows, partners, basis, alignment, mixedPeriods)
+	} else {
+		// Other alignments and merges need a reporter's whole history, which
+		// is built into its row and dropped before the next reporter's.
+		latest = []latestEntry{}
+		err := eachReporter(func(group []observationRow) {
+			latest = append(latest, buildLatest(group, partners, basis, alignment, mixedPeriods)...)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load observations:", err)
+			os.Exit(1)
+		}
+		sort.Slice(latest, func(i, j int) bool { return latest[i].ISO3 < latest[j].ISO3 })
+	}
+	world.apply(latest)
+	applyLatestConcentration(latest)
+	contextData, err := loadContext(*contextPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load country context:", err)
+		os.Exit(1)
+	}
+	enrichLatest(latest, contextData.Countries)
+	applyNormalization(latest)
+	reporterNames, err := loadReporterNames(*dbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load reporter names:", err)
+		os.Exit(1)
+	}
+	enrichReporterNames(latest, reporterNames)
+	stale, err := staleReporters(now, latest, threshold)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to check staleness:", err)
+		os.Exit(1)
+	}
+	latest, _ = applyStalePolicy(stalePolicy, stale, latest, nil)
+	applyPeerPercentiles(latest)
+	fxRates, err := loadFXRates(*dbPath, currencies)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load fx rates:", err)
+		os.Exit(1)
+	}
+	if err := applyCurrencies(latest, currencies, fxRates); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to convert currencies:", err)
+		os.Exit(1)
+	}
+	artifacts.attribution = attributionPolicy{
+		entries:   attribution,
+		totals:    totalsProviders,
+		products:  strings.ToLower(strings.TrimSpace(*productProvider)),
+		matrix:    strings.ToLower(strings.TrimSpace(*matrixProvider)),
+		tariffs:   "trains",
+		worldBank: contextData.Status != "missing" || len(currencies) > 0,
+	}
+	var productRows []observationRow
+	if len(onlyReporters) == 0 {
+		productRows, err = loadProductObservations(*dbPath, *productProvider, *productLevel, partners)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load product observations:", err)
+			os.Exit(1)
+		}
+	}
+	excluded := excludedReporters(stalePolicy, stale)
+	points := seriesPoints{}
+	qualityChecks := newQualityFold(*provider, productRows, *consistencyTolerance)
+	coverageFlows := newCoverageFold()
+	coverageFlows.addProviders(productRows)
+	observationCount := 0
+	err = eachReporter(func(group []observationRow) {
+		if excluded[strings.ToUpper(group[0].ReporterISO)] {
+			return
+		}
+		points.add(group)
+		if len(onlyReporters) == 0 {
+			qualityChecks.add(group)
+			coverageFlows.add(group)
+			observationCount += len(group)
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load observations:", err)
+		os.Exit(1)
+	}
+	seriesOutput := points.file(now, *provider, partners, *seriesYears)
+	historyOutput := points.file(now, *provider, partners, 0)
+	countryIndex, countryFiles := buildCountryFiles(now, *provider, partners, historyOutput, latest)
+	if len(onlyReporters) > 0 {
+		index, err := writePartialBuild(*outDir, onlyReporters, published, latest, stale, countryIndex, countryFiles, locales)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to patch the build:", err)
+			os.Exit(1)
+		}
+		finishBuild(*outDir, now, targets, index, "reporters="+strings.Join(onlyReporters, ","))
+		return
+	}
+	rankings := buildRankings(now, *provider, historyOutput, latest, *rankingsTop)
+	tilt := buildTiltFile(now, *provider, historyOutput, latest)
+	movers := buildMovers(now, *provider, historyOutput, latest, *moversTop)
+	forecast := buildForecast(now, *provider, historyOutput, latest, forecastHorizon)
+	volatility := buildVolatility(now, *provider, historyOutput, latest)
+	diversion := buildDiversion(now, *provider, historyOutput, latest, *diversionTop)
+	correlation := buildCorrelation(now, *provider, historyOutput, latest)
+	aggregates := buildAggregates(now, *provider, latest)
+	mapProperties := buildMapProperties(now, *provider, latest)
+	hs2Labels, err := loadProductLabels(*hs2Path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load product labels:", err)
+		os.Exit(1)
+	}
+	productIndex, productFiles := buildProductFiles(now, *productProvider, *productLevel, partners, productRows, hs2Labels)
+	worldProductRows, err := loadProductObservations(*dbPath, *productProvider, *productLevel, []string{worldPartner})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load world product observations:", err)
+		os.Exit(1)
+	}
+	rcaIndex, rcaFiles := buildRCAFiles(now, *productProvider, *productLevel, append(worldProductRows, productRows...), hs2Labels, latest)
+	strategicProducts, err := strategic.LoadCSV(*strategicRegistryPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load strategic HS6 registry:", err)
+		os.Exit(1)
+	}
+	strategicRows, err := loadProductObservations(*dbPath, *productProvider, 6, partners)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load strategic HS6 observations:", err)
+		os.Exit(1)
+	}
+	strategicIndex, strategicFiles := buildStrategicFiles(now, *productProvider, partners, strategicRows, strategicProducts)
+	semiconductorReference, err := semiconductor.Load(*semiconductorReferencePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load semiconductor reference:", err)
+		os.Exit(1)
+	}
+	if err := semiconductor.ValidateStrategicRegistry(semiconductorReference, strategicProducts); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to validate semiconductor reference:", err)
+		os.Exit(1)
+	}
+	semiconductorReference.GeneratedAt = now
+	semiconductorReference.Publication = buildSemiconductorPublication(semiconductorReference, strategicFiles)
+	semiconductorMonthlyIndex, semiconductorMonthlyFiles := buildSemiconductorMonthlyFiles(now, *productProvider, partners, strategicRows, strategicProducts, semiconductorReference)
+	publicationChanges, err := buildPublicationChanges(now, *previousDir, semiconductorMonthlyIndex, semiconductorMonthlyFiles)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to compare the previous semiconductor publication:", err)
+		os.Exit(1)
+	}
+	diffDir := *previousDir
+	if strings.TrimSpace(diffDir) == "" {
+		diffDir = *outDir
+	}
+	publishDiff, err := buildPublishDiff(now, diffDir, latest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to compare the previous publish:", err)
+		os.Exit(1)
+	}
+	tariffRows, err := loadTariffObservations(*dbPath, "trains")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load tariff observations:", err)
+		os.Exit(1)
+	}
+	tariffIndex, tariffFiles := buildTariffFiles(now, "trains", tariffRows, strategicProducts)
+	matrixRows, err := loadMatrixObservations(*dbPath, *matrixProvider)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load bilateral matrix observations:", err)
+		os.Exit(1)
+	}
+	matrixIndex, matrixFiles := buildMatrixFiles(now, *matrixProvider, matrixRows, partnerPolicy)
+	mirrorIndex, mirrorFiles := buildMirrorFiles(now, *matrixProvider, matrixFiles)
+	runs, err := loadIngestRuns(*dbPath, 20)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load ingest runs:", err)
+		os.Exit(1)
+	}
+	quality := qualityChecks.file(now, latest, runs)
+	seriesGrades, err := loadSeriesQuality(*dbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load series quality:", err)
+		os.Exit(1)
+	}
+	coverage := coverageFlows.file(now, *provider, partners, latest, seriesGrades)
+	discrepancyPairs, err := loadProviderDiscrepancies(*dbPath, partners)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load provider discrepancies:", err)
+		os.Exit(1)
+	}
+	discrepancies := buildDiscrepancies(now, discrepancyPairs, latest)
+	catalog := buildDataCatalog(now, *provider, contextData.Status, seriesOutput, historyOutput, countryIndex, rankings, tilt, movers, forecast, volatility, diversion, correlation, aggregates, mapProperties, coverage, discrepancies, publishDiff, productIndex, rcaIndex, strategicIndex, tariffIndex, matrixIndex, mirrorIndex, semiconductorMonthlyIndex, publicationChanges, semiconductorReference)
+	relinkIndexes(artifacts.layout, &catalog, &countryIndex, &strategicIndex, &semiconductorMonthlyIndex, &tariffIndex, &matrixIndex, &mirrorIndex)
+	metadata := buildMeta(now, *provider, partners, observationCount, latest)
+	build := buildinfo.Get()
+	metadata.Build = &build
+	metadata.ShareAlignment = alignment
+	metadata.MixedPeriods = mixedPeriods
+	metadata.ProviderMerge = providerMerge.spec
+	if len(currencies) > 0 {
+		metadata.Currencies = append([]string{"USD"}, currencies...)
+	}
+	if threshold.enabled() {
+		metadata.MaxStaleness, metadata.StalePolicy, metadata.StaleReporters = threshold.String(), stalePolicy, stale
+	}
+	augmentMeta(&metadata, latest, seriesOutput, productIndex, len(productRows), contextData.Status)
+	augmentHistoryMeta(&metadata, historyOutput)
+	augmentCountryMeta(&metadata, countryIndex)
+	augmentAggregateMeta(&metadata, aggregates)
+	augmentCoverageMeta(&metadata, coverage)
+	augmentStrategicMeta(&metadata, strategicIndex)
+	augmentTariffMeta(&metadata, tariffIndex)
+	augmentMatrixMeta(&metadata, matrixIndex)
+	augmentMirrorMeta(&metadata, mirrorIndex)
+	augmentSemiconductorMeta(&metadata, semiconductorReference)
+	augmentSemiconductorMonthlyMeta(&metadata, semiconductorMonthlyIndex)
+	output := latestFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   now,
+		Provider:      strings.ToLower(strings.TrimSpace(*provider)),
+		Partners:      partners,
+		Rows:          latest,
+	}
+	var metaOutput, latestOutput any = metadata, output
+	if outputSchema == schemaV1 {
+		outputV1 := latestV1(output)
+		metaOutput, latestOutput = metaV1(metadata, outputV1), outputV1
+	}
+	if err := writeJSON(filepath.Join(*outDir, "meta.json"), metaOutput); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write meta.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "latest.json"), latestOutput); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write latest.json:", err)
+		os.Exit(1)
+	}
+	for _, locale := range locales {
+		name := localizedLatestName(locale)
+		if err := writeJSON(filepath.Join(*outDir, name), buildLocalizedLatest(output, locale)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+	if err := writeJSON(filepath.Join(*outDir, "series.json"), seriesOutput); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write series.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "history.json"), historyOutput); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write history.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "rankings.json"), rankings); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write rankings.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "tilt.json"), tilt); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write tilt.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "movers.json"), movers); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write movers.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "forecast.json"), forecast); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write forecast.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "volatility.json"), volatility); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write volatility.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "diversion.json"), diversion); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write diversion.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "correlation.json"), correlation); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write correlation.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "map.json"), mapProperties); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write map.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "aggregates.json"), aggregates); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write aggregates.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "quality.json"), quality); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write quality.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "discrepancies.json"), discrepancies); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write discrepancies.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "coverage.json"), coverage); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write coverage.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "catalog.json"), catalog); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write catalog.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "changes.json"), publicationChanges); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write changes.json:", err)
+		os.Exit(1)
+	}
+	if err := writeJSON(filepath.Join(*outDir, "diff.json"), publishDiff); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write diff.json:", err)
+		os.Exit(1)
+	}
+	if err := writeTables(*outDir, formats, latestTable(latest), historyTable(historyOutput), rankingsTable(rankings)); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write tabular exports:", err)
+		os.Exit(1)
+	}
+	if formats["ndjson"] {
+		if err := writeHistoryNDJSON(filepath.Join(*outDir, "history.ndjson"), historyOutput); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write history.ndjson:", err)
+			os.Exit(1)
+		}
+	}
+	if len(chartFormats) > 0 {
+		if err := writeCharts(*outDir, chartFormats, historyOutput, rankings); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write charts:", err)
+			os.Exit(1)
+		}
+	}
+	countriesDir := filepath.Join(*outDir, "countries")
+	if err := writeJSON(filepath.Join(countriesDir, "index.json"), countryIndex); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write country index:", err)
+		os.Exit(1)
+	}
+	if err := writeJSONFiles(countriesDir, ".json", countryFiles); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write country detail for", err)
+		os.Exit(1)
+	}
+	productsDir := filepath.Join(*outDir, "products")
+	if err := writeJSON(filepath.Join(productsDir, "index.json"), productIndex); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write product index:", err)
+		os.Exit(1)
+	}
+	if err := writeJSONFiles(productsDir, ".json", productFiles); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write products for", err)
+		os.Exit(1)
+	}
+	rcaDir := filepath.Join(*outDir, "rca")
+	if err := writeJSON(filepath.Join(rcaDir, "index.json"), rcaIndex); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write RCA index:", err)
+		os.Exit(1)
+	}
+	if err := writeJSONFiles(rcaDir, ".json", rcaFiles); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write RCA for", err)
+		os.Exit(1)
+	}
+	strategicDir := filepath.Join(*outDir, "strategic-hs6")
+	if err := writeJSON(filepath.Join(strategicDir, "index.json"), strategicIndex); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write strategic HS6 index:", err)
+		os.Exit(1)
+	}
+	if err := writeJSONFiles(strategicDir, "", strategicFiles); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write strategic partition", err)
+		os.Exit(1)
+	}
+	semiconductorDir := filepath.Join(*outDir, "semiconductors")
+	if err := writeJSON(filepath.Join(semiconductorDir, "reference.json"), semiconductorReference); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write semiconductor reference:", err)
+		os.Exit(1)
+	}
+	semiconductorMonthlyDir := filepath.Join(semiconductorDir, "monthly")
+	if err := writeJSON(filepath.Join(semiconductorMonthlyDir, "index.json"), semiconductorMonthlyIndex); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write monthly semiconductor index:", err)
+		os.Exit(1)
+	}
+	if err := writeJSONFiles(semiconductorMonthlyDir, "", semiconductorMonthlyFiles); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write monthly semiconductor partition", err)
+		os.Exit(1)
+	}
+	tariffDir := filepath.Join(*outDir, "tariffs")
+	if err := writeJSON(filepath.Join(tariffDir, "index.json"), tariffIndex); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write tariff index:", err)
+		os.Exit(1)
+	}
+	if err := writeJSONFiles(tariffDir, "", tariffFiles); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write tariff partition", err)
+		os.Exit(1)
+	}
+	matrixDir := filepath.Join(*outDir, "bilateral-matrix")
+	if err := writeJSON(filepath.Join(matrixDir, "index.json"), matrixIndex); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write bilateral matrix index:", err)
+		os.Exit(1)
+	}
+	if err := writeJSONFiles(matrixDir, "", matrixFiles); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write bilateral matrix partition", err)
+		os.Exit(1)
+	}
+	mirrorDir := filepath.Join(*outDir, "mirror")
+	if err := writeJSON(filepath.Join(mirrorDir, "index.json"), mirrorIndex); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write mirror diagnostics index:", err)
+		os.Exit(1)
+	}
+	if err := writeJSONFiles(mirrorDir, "", mirrorFiles); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write mirror diagnostics partition", err)
+		os.Exit(1)
+	}
+
+	finishBuild(*outDir, now, targets, buildArtifactIndex(now, artifacts.records), "")
+}
+
+// publishTargets are the destinations a finished build is sent to: the
+// -publish-to upload target and the -purge-cdn cache in front of it, the
+// -publish-release GitHub release, the -publish-sheet spreadsheet, the
+// -post-to webhook, and the -email-to digest recipients. Empty fields are
+// skipped.
+type publishTargets struct {
+	upload        string
+	purge         cdn.Target
+	purgeBase     string
+	releaseRepo   string
+	releaseTag    string
+	spreadsheetID string
+	webhook       string
+	emailTo       []string
+}
+
+// publicationTime is the pinned generated_at: the RFC3339 -generated-at
+// value, else SOURCE_DATE_EPOCH, else "" for the caller's default. Pinning it
+// makes a rebuild of unchanged data byte-identical.
+func publicationTime(generatedAt string) (string, error) {
+	if generatedAt != "" {
+		pinned, err := time.Parse(time.RFC3339, generatedAt)
+		if err != nil {
+			return "", err
+		}
+		return pinned.UTC().Format(time.RFC3339), nil
+	}
+	pinned, ok, err := cli.SourceDate()
+	if err != nil || !ok {
+		return "", err
+	}
+	return pinned.Format(time.RFC3339), nil
+}
+
+// finishBuild writes index.json and the checksum manifest, then sends the
+// output to each of targets. scope, when set, is added to the summary line
+// of a partial build.
+func finishBuild(outDir, now string, targets publishTargets, index artifactIndexFile, scope string) {
+	if err := writeJSON(filepath.Join(outDir, artifactIndexName), index); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write index.json:", err)
+		os.Exit(1)
+	}
+	if err := artifacts.finish(now); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write artifact checksums:", err)
+		os.Exit(1)
+	}
+	if scope != "" {
+		scope += " "
+	}
+	fmt.Printf("publisher build complete (out=%s %swritten=%d unchanged=%d)\n", outDir, scope, len(artifacts.touched), artifacts.unchanged)
+
+	if targets.upload != "" {
+		uploaded, err := publishTo(context.Background(), outDir, targets.upload)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to publish artifacts:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("publisher upload complete (target=%s objects=%d)\n", targets.upload, uploaded)
+	}
+	if targets.purge.Provider != "" {
+		purged, err := purgeCDN(context.Background(), targets.purge, targets.purgeBase, artifacts.touched)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to purge cdn:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("publisher cdn purge complete (cdn=%s changed=%d purged=%d)\n", targets.purge, len(artifacts.touched), purged)
+	}
+	if targets.releaseRepo != "" {
+		tag, assets, err := publishRelease(context.Background(), outDir, targets.releaseRepo, targets.releaseTag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to publish release:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("publisher release complete (repo=%s tag=%s assets=%d)\n", targets.releaseRepo, tag, assets)
+	}
+	if targets.spreadsheetID != "" {
+		written, err := publishSheets(context.Background(), outDir, targets.spreadsheetID)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to publish sheets:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("publisher sheets complete (spreadsheet=%s sheets=%d)\n", targets.spreadsheetID, written)
+	}
+	if targets.webhook != "" {
+		if err := postTo(context.Background(), outDir, targets.webhook); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to post to webhook:", err)
+			os.Exit(1)
+		}
+		fmt.Println("publisher webhook complete")
+	}
+	if len(targets.emailTo) > 0 {
+		if err := emailDigest(outDir, targets.emailTo); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to email digest:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("publisher email complete (recipients=%d)\n", len(targets.emailTo))
+	}
+}
+
+func writeJSON(path string, value any) error {
+	body := encodeBuffers.Get().(*bytes.Buffer)
+	defer func() {
+		body.Reset()
+		encodeBuffers.Put(body)
+	}()
+	encoder := json.NewEncoder(body)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	if artifacts == nil || !artifacts.attribution.enabled() {
+		return writeArtifact(path, body.Bytes())
+	}
+	relative, err := filepath.Rel(artifacts.root, path)
+	if err != nil {
+		return err
+	}
+	attributed, err := withAttribution(body.Bytes(), artifacts.attribution.credits(relative))
+	if err != nil {
+		return err
+	}
+	return writeArtifact(path, attributed)
+}
+
+func usage(program string) {
+	fmt.Fprintf(os.Stderr, "usage: %s build [options]\n", program)
+	fmt.Fprintf(os.Stderr, "       %s validate [-dir site/data]\n", program)
+	fmt.Fprintf(os.Stderr, "       %s compare -db-a old.db -db-b new.db [-provider-b id] [-out report.json]\n", program)
+	fmt.Fprintf(os.Stderr, "       %s report [-dir site/data] [-format html|markdown] [-top 10] [-out report.html]\n", program)
+	fmt.Fprintf(os.Stderr, "       %s serve [-addr 127.0.0.1:8080] [-out site/data] [-skip-build] [-- build options]\n", program)
+	fmt.Fprintf(os.Stderr, "       %s analytics run [-out site/data] [-db tradegravity.db] [-rankings=false ...]\n", program)
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "options:")
+	fmt.Fprintln(os.Stderr, "  -out   output directory (default: site/data)")
+	fmt.Fprintln(os.Stderr, "  -db    sqlite database path (default: tradegravity.db)")
+	fmt.Fprintln(os.Stderr, "  -provider   provider id (default: wits)")
+	fmt.Fprintln(os.Stderr, "  -provider-merge   per-period-type provider preference instead of -provider, e.g. M=comtrade,Y=wits/comtrade (default: off)")
+	fmt.Fprintln(os.Stderr, "  -partners   comma-separated partner ISO3 list, must include USA,CHN (default: USA,CHN)")
+	fmt.Fprintln(os.Stderr, "  -context   country context JSON (default: site/data/context.json)")
+	fmt.Fprintln(os.Stderr, "  -product-provider   HS2 provider (default: comtrade)")
+	fmt.Fprintln(os.Stderr, "  -matrix-provider   bilateral matrix provider (default: comtrade)")
+	fmt.Fprintln(os.Stderr, "  -product-level   product level (default: 2)")
+	fmt.Fprintln(os.Stderr, "  -strategic-registry   strategic HS6 registry CSV")
+	fmt.Fprintln(os.Stderr, "  -semiconductor-reference   semiconductor value-chain reference JSON")
+	fmt.Fprintln(os.Stderr, "  -attribution   provider attribution JSON credited in every JSON artifact (default: configs/attribution.json; missing = none)")
+	fmt.Fprintln(os.Stderr, "  -series-years   annual history window (default: 10)")
+	fmt.Fprintln(os.Stderr, "  -rankings-top   reporters per ranking (default: 20)")
+	fmt.Fprintln(os.Stderr, "  -movers-top   reporters per movers list (default: 10)")
+	fmt.Fprintln(os.Stderr, "  -diversion-top   reporters per diversion list (default: 10)")
+	fmt.Fprintln(os.Stderr, "  -forecast-horizon   periods projected per series in forecast.json, 1-4 (default: 4)")
+	fmt.Fprintln(os.Stderr, "  -consistency-tolerance   annual vs summed monthly gap left unflagged in quality.json (default: 0.05)")
+	fmt.Fprintln(os.Stderr, "  -growth-basis   yoy, mom, qoq, or ytd (default: yoy)")
+	fmt.Fprintln(os.Stderr, "  -schema   meta.json/latest.json shape: v2, or v1 for older frontends (default: v2)")
+	fmt.Fprintln(os.Stderr, "  -align   USA/CHN share period alignment: latest, common, or period-type (default: latest)")
+	fmt.Fprintln(os.Stderr, "  -mixed-periods   USA/CHN blocks on different period types: allow, downgrade, or incomparable (default: allow)")
+	fmt.Fprintln(os.Stderr, "  -max-staleness   flag or exclude reporters whose freshest period is older, e.g. 3y, 18m, 90d (default: off)")
+	fmt.Fprintln(os.Stderr, "  -stale-policy   flag or exclude reporters past -max-staleness (default: flag)")
+	fmt.Fprintln(os.Stderr, "  -generated-at   pin the RFC3339 publication time (default: SOURCE_DATE_EPOCH, else now)")
+	fmt.Fprintln(os.Stderr, "  -layout   output path templates, e.g. data/{artifact}/{iso3}.json,data/{name}.{ext} (default: built-in paths)")
+	fmt.Fprintln(os.Stderr, "  -concurrency   partition files encoded and written at once (default: GOMAXPROCS)")
+	fmt.Fprintln(os.Stderr, "  -only   rebuild just these reporters, e.g. KOR,VNM,MEX, patching latest.json, meta.json, and countries/ in -out (default: all)")
+	fmt.Fprintln(os.Stderr, "  -publish-to   upload artifacts to s3://, gs://, or az://bucket/prefix, or sftp://user@host/path after the build")
+	fmt.Fprintln(os.Stderr, "  -purge-cdn   after -publish-to, purge the files this build changed from cloudfront:<distribution> or cloudflare:<zone>")
+	fmt.Fprintln(os.Stderr, "  -cdn-base   where the uploaded files are served: a URL, or for cloudfront a path (default: /<publish-to prefix>)")
+	fmt.Fprintln(os.Stderr, "  -publish-release   attach a zip of the artifacts and SHA256SUMS to a GitHub release in owner/name after the build (needs GITHUB_TOKEN)")
+	fmt.Fprintln(os.Stderr, "  -release-tag   tag of the -publish-release release (default: data-<generated_at>)")
+	fmt.Fprintln(os.Stderr, "  -publish-sheet   write the latest and rankings tables into a Google Sheet ID or URL after the build")
+	fmt.Fprintln(os.Stderr, "  -post-to   POST latest.json and diff.json to a webhook URL after the build")
+	fmt.Fprintln(os.Stderr, "  -email-to   email a digest of new periods, failed runs, and top movers to comma-separated addresses after the build (needs SMTP_HOST)")
+	fmt.Fprintln(os.Stderr, "  -currencies   currencies to publish; non-USD codes use fx_rates, e.g. USD,KRW (default: USD)")
+	fmt.Fprintln(os.Stderr, "  -locales   labelled latest.{locale}.json files to write: en, ko (default: none)")
+	fmt.Fprintln(os.Stderr, "  -format   extra output formats: csv, parquet, xlsx, ndjson (default: json)")
+	fmt.Fprintln(os.Stderr, "  -charts   chart images under charts/: svg, png (default: none)")
+}
+
+func loadObservations(dbPath, provider string, partners, reporters []string) ([]observationRow, error) {
+	results := make([]observationRow, 0)
+	err := eachObservationChunk(dbPath, provider, partners, reporters, func(chunk []observationRow) error {
+		results = append(results, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// eachObservationChunk scans the observations loadObservations would return
+// and hands them to fn a chunk at a time. The chunk is reused once fn
+// returns, so callers that aggregate as they go hold no rows at all.
+func eachObservationChunk(dbPath, provider string, partners, reporters []string, fn func([]observationRow) error) error {
+	return scanTotals(dbPath, provider, partners, reporters, "", fn)
+}
+
+// eachReporterObservations hands fn the observations loadObservations would
+// return one reporter at a time, in reporter order, so a caller folding them
+// holds a single reporter's history at most and sees every provider of that
+// reporter together. The slice is reused once fn returns.
+func eachReporterObservations(dbPath, provider string, partners, reporters []string, fn func([]observationRow) error) error {
+	var group []observationRow
+	err := scanTotals(dbPath, provider, partners, reporters, "reporter_iso3", func(chunk []observationRow) error {
+		for _, row := range chunk {
+			if len(group) > 0 && row.ReporterISO != group[0].ReporterISO {
+				if err := fn(group); err != nil {
+					return err
+				}
+				group = group[:0]
+			}
+			group = append(group, row)
+		}
+		return nil
+	})
+	if err == nil && len(group) > 0 {
+		err = fn(group)
+	}
+	return err
+}
+
+// scanTotals runs the totalsFilter query, in orderBy order when it is set,
+// and scans the result with scanObservationChunks.
+func scanTotals(dbPath, provider string, partners, reporters []string, orderBy string, fn func([]observationRow) error) error {
+	if strings.TrimSpace(dbPath) == "" {
+		return errors.New("db path is required")
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	quality, err := observationQualityColumns(db)
+	if err != nil {
+		return err
+	}
+	value, err := observationValueColumn(db)
+	if err != nil {
+		return err
+	}
+	filter, args := totalsFilter(provider, partners, reporters)
+	if orderBy != "" {
+		filter += " ORDER BY " + orderBy
+	}
+	rows, err := db.QueryContext(context.Background(), `
+		SELECT provider, reporter_iso3, partner_iso3, flow, period_type, period, `+value+`, `+quality+`
+		FROM trade_observations
+		WHERE `+filter, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return scanObservationChunks(rows, fn)
+}
+
+// totalsFilter is the WHERE clause selecting total-trade observations for
+// provider, or every provider when it is empty, partners, and reporters, or
+// every reporter when it is empty. The equality and IN terms let SQLite pick
+// idx_trade_observations_partner, or idx_trade_observations_totals when
+// reporters are named, instead of reading the whole bilateral matrix.
+func totalsFilter(provider string, partners, reporters []string) (string, []any) {
+	filter := "flow IN ('export','import','total') AND product_level = 0 AND product_code = 'TOTAL'"
+	args := []any{}
+	if strings.TrimSpace(provider) != "" {
+		filter += " AND provider = ?"
+		args = append(args, provider)
+	}
+	if len(partners) > 0 {
+		filter += " AND partner_iso3 IN (" + placeholders(len(partners)) + ")"
+		for _, partner := range partners {
+			args = append(args, partner)
+		}
+	}
+	if len(reporters) > 0 {
+		filter += " AND reporter_iso3 IN (" + placeholders(len(reporters)) + ")"
+		for _, reporter := range reporters {
+			args = append(args, reporter)
+		}
+	}
+	return filter, args
+}
+
+// observationChunkSize is how many rows scanObservationChunks reads before
+// handing them on.
+const observationChunkSize = 4096
+
+// scanObservations reads rows of provider, reporter_iso3, partner_iso3, flow,
+// period_type, period, value in cents, estimated, and quality_note.
+func scanObservations(rows *sql.Rows) ([]observationRow, error) {
+	results := make([]observationRow, 0)
+	err := scanObservationChunks(rows, func(chunk []observationRow) error {
+		results = append(results, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// scanObservationChunks reads rows as scanObservations does and calls fn
+// with every observationChunkSize of them, then with the rest. Codes,
+// periods, providers, and notes repeat across millions of rows, so each
+// distinct value is allocated once and shared by every row holding it.
+func scanObservationChunks(rows *sql.Rows, fn func([]observationRow) error) error {
+	var strs, flows, periodTypes interner = make(interner), make(interner), make(interner)
+	chunk := make([]observationRow, 0, observationChunkSize)
+	var provider, reporter, partner, flow, periodType, period, note sql.RawBytes
+	for rows.Next() {
+		var row observationRow
+		if err := rows.Scan(&provider, &reporter, &partner, &flow, &periodType, &period, (*int64)(&row.Value), &row.Estimated, &note); err != nil {
+			return err
+		}
+		row.Provider = strs.intern(provider, nil)
+		row.ReporterISO = strs.intern(reporter, nil)
+		row.PartnerISO = strs.intern(partner, nil)
+		row.Flow = model.Flow(flows.intern(flow, strings.ToLower))
+		row.PeriodType = model.PeriodType(periodTypes.intern(periodType, strings.ToUpper))
+		row.Period = strs.intern(period, nil)
+		row.QualityNote = strs.intern(note, nil)
+		chunk = append(chunk, row)
+		if len(chunk) == observationChunkSize {
+			if err := fn(chunk); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(chunk) > 0 {
+		return fn(chunk)
+	}
+	return nil
+}
+
+// interner maps the bytes of a scanned column to one shared string, passed
+// through normalize the first time it is seen.
+type interner map[string]string
+
+func (in interner) intern(raw []byte, normalize func(string) string) string {
+	if value, ok := in[string(raw)]; ok {
+		return value
+	}
+	value := string(raw)
+	if normalize != nil {
+		value = normalize(value)
+	}
+	in[string(raw)] = value
+	return value
+}
+
+// buildLatest summarizes each reporter's latest block for every tracked
+// partner, with growth on the given basis. Total, ShareCN, and SamePeriod keep
+// their USA+CHN definitions; alignment picks the periods the USA and CHN blocks
+// are compared on, and mixed decides what happens when their period types
+// still differ.
+func buildLatest(rows []observationRow, partners []string, basis, alignment, mixed string) []latestEntry {
+	latest := make(map[string]map[string]map[model.Flow]latestValue)
+	series := make(map[string]map[string]map[model.Flow]map[string]float64)
+	estimates := indexEstimates(rows)
+
+	for _, row := range rows {
+		reporter := strings.ToUpper(row.ReporterISO)
+		partner := strings.ToUpper(row.PartnerISO)
+		if reporter == "" || partner == "" {
+			continue
+		}
+
+		if _, ok := latest[reporter]; !ok {
+			latest[reporter] = make(map[string]map[model.Flow]latestValue)
+		}
+		if _, ok := series[reporter]; !ok {
+			series[reporter] = make(map[string]map[model.Flow]map[string]float64)
+		}
+		if _, ok := latest[reporter][partner]; !ok {
+			latest[reporter][partner] = make(map[model.Flow]latestValue)
+		}
+		if _, ok := series[reporter][partner]; !ok {
+			series[reporter][partner] = make(map[model.Flow]map[string]float64)
+		}
+		if _, ok := series[reporter][partner][row.Flow]; !ok {
+			series[reporter][partner][row.Flow] = make(map[string]float64)
+		}
+		series[reporter][partner][row.Flow][seriesKey(row.PeriodType, row.Period)] = row.Value.USD()
+
+		current := latest[reporter][partner][row.Flow]
+		if !current.Valid || comparePeriods(row.PeriodType, row.Period, current.PeriodType, current.Period) > 0 {
+			latest[reporter][partner][row.Flow] = latestValue{
+				PeriodType: row.PeriodType,
+				Period:     row.Period,
+				ValueUSD:   row.Value.USD(),
+				Valid:      true,
+			}
+		}
+	}
+
+	results := make([]latestEntry, 0, len(latest))
+	for reporter, values := range latest {
+		values = alignValues(values, series[reporter], alignment)
+		reporterSeries := series[reporter]
+		if mixed == mixedDowngrade {
+			values, reporterSeries = downgradePeriodTypes(values, reporterSeries)
+		}
+		blocks := make(map[string]partnerBlock, len(partners))
+		trackedTotal := 0.0
+		for _, partner := range partners {
+			summary := buildPartnerBlock(values[partner], reporterSeries[partner], basis)
+			if !summary.HasData() {
+				continue
+			}
+			markEstimated(&summary.partnerBlock, estimates, reporter, partner)
+			blocks[partner] = summary.partnerBlock
+			trackedTotal += summary.Trade
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		shares := make(map[string]float64, len(blocks))
+		for partner, block := range blocks {
+			share := 0.0
+			if trackedTotal > 0 {
+				share = block.Trade / trackedTotal
+			}
+			shares[partner] = share
+		}
+
+		usa := buildPartnerBlock(values["USA"], reporterSeries["USA"], basis)
+		chn := buildPartnerBlock(values["CHN"], reporterSeries["CHN"], basis)
+		markEstimated(&usa.partnerBlock, estimates, reporter, "USA")
+		markEstimated(&chn.partnerBlock, estimates, reporter, "CHN")
+
+		total := roundCents(usa.Trade + chn.Trade)
+		shareCN := 0.0
+		if total > 0 {
+			shareCN = chn.Trade / total
+		}
+
+		samePeriod := usa.HasData() && chn.HasData() && usa.PeriodType == chn.PeriodType && usa.Period == chn.Period
+		comparisonPeriod := ""
+		var comparisonPeriodType model.PeriodType
+		if samePeriod {
+			comparisonPeriod = usa.Period
+			comparisonPeriodType = usa.PeriodType
+		}
+		results = append(results, latestEntry{
+			ISO3:                 reporter,
+			USA:                  usa.partnerBlock,
+			CHN:                  chn.partnerBlock,
+			Total:                total,
+			ShareCN:              shareCN,
+			SamePeriod:           samePeriod,
+			ComparisonPeriod:     comparisonPeriod,
+			ComparisonPeriodType: comparisonPeriodType,
+			Partners:             blocks,
+			TrackedTotal:         roundCents(trackedTotal),
+			Shares:               shares,
+			ShareCNTTM:           trailingShare(usa.TTM, chn.TTM),
+		})
+	}
+
+	if mixed == mixedIncomparable {
+		markIncomparable(results)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ISO3 < results[j].ISO3
+	})
+	return results
+}
+
+func buildMeta(generatedAt, provider string, partners []string, observationCount int, latest []latestEntry) metaFile {
+	periodCounts := make(map[string]int)
+	availableBlocks := 0
+	for _, entry := range latest {
+		for _, block := range entry.Partners {
+			if strings.TrimSpace(block.Period) == "" {
+				continue
+			}
+			availableBlocks++
+			key := string(block.PeriodType) + ":" + block.Period
+			periodCounts[key]++
+		}
+	}
+
+	expectedBlocks := len(latest) * len(partners)
+	missingBlocks := expectedBlocks - availableBlocks
+	if missingBlocks < 0 {
+		missingBlocks = 0
+	}
+
+	return metaFile{
+		SchemaVersion:          schemaVersion,
+		GeneratedAt:            generatedAt,
+		Provider:               strings.ToLower(strings.TrimSpace(provider)),
+		Partners:               append([]string(nil), partners...),
+		ReporterCount:          len(latest),
+		ObservationCount:       observationCount,
+		ExpectedPartnerBlocks:  expectedBlocks,
+		AvailablePartnerBlocks: availableBlocks,
+		MissingPartnerBlocks:   missingBlocks,
+		PeriodCounts:           periodCounts,
+	}
+}
+
+type partnerSummary struct {
+	partnerBlock
+	hasData bool
+}
+
+func (p partnerSummary) HasData() bool {
+	return p.hasData
+}
+
+func buildPartnerBlock(values map[model.Flow]latestValue, series map[model.Flow]map[string]float64, basis string) partnerSummary {
+	if values == nil {
+		return partnerSummary{}
+	}
+	export := values[model.FlowExport]
+	imported := values[model.FlowImport]
+
+	periodType, period := selectLatestPeriod(export, imported)
+	if period == "" {
+		if total := values[model.FlowTotal]; total.Valid {
+			periodType, period = total.PeriodType, total.Period
+		}
+	}
+	exportValue, exportOk := seriesValue(series, model.FlowExport, periodType, period)
+	importValue, importOk := seriesValue(series, model.FlowImport, periodType, period)
+	if !exportOk && export.Valid {
+		exportValue = export.ValueUSD
+		exportOk = true
+	}
+	if !importOk && imported.Valid {
+		importValue = imported.ValueUSD
+		importOk = true
+	}
+	tradeValue := (model.CentsFromUSD(exportValue) + model.CentsFromUSD(importValue)).USD()
+	totalValue, totalOk := seriesValue(series, model.FlowTotal, periodType, period)
+	if totalOk && (!exportOk || !importOk) {
+		tradeValue = totalValue
+	}
+
+	prevPeriod, growth := buildGrowth(series, periodType, period, basis)
+
+	block := partnerBlock{
+		Period:      period,
+		PeriodType:  periodType,
+		PrevPeriod:  prevPeriod,
+		Export:      exportValue,
+		Import:      importValue,
+		Trade:       tradeValue,
+		Growth:      growth,
+		GrowthBasis: basis,
+		TTM:         trailingTotals(series, periodType, period),
+	}
+	if block.Period == "" || block.Growth == nil {
+		block.GrowthBasis = ""
+	}
+	hasData := exportOk || importOk || totalOk
+	return partnerSummary{partnerBlock: block, hasData: hasData}
+}
+
+func selectLatestPeriod(export, imported latestValue) (model.PeriodType, string) {
+	if export.Valid && !imported.Valid {
+		return export.PeriodType, export.Period
+	}
+	if imported.Valid && !export.Valid {
+		return imported.PeriodType, imported.Period
+	}
+	if export.Valid && imported.Valid {
+		if comparePeriods(export.PeriodType, export.Period, imported.PeriodType, imported.Period) >= 0 {
+			return export.PeriodType, export.Period
+		}
+		return imported.PeriodType, imported.Period
+	}
+	return "", ""
+}
+
+func comparePeriods(aType model.PeriodType, aPeriod string, bType model.PeriodType, bPeriod string) int {
+	priorityA := periodPriority(aType)
+	priorityB := periodPriority(bType)
+	if priorityA != priorityB {
+		if priorityA > priorityB {
+			return 1
+		}
+		return -1
+	}
+
+	keyA := periodKey(aType, aPeriod)
+	keyB := periodKey(bType, bPeriod)
+	switch {
+	case keyA > keyB:
+		return 1
+	case keyA < keyB:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func periodPriority(periodType model.PeriodType) int {
+	switch periodType {
+	case model.PeriodMonth:
+		return 3
+	case model.PeriodQuarter:
+		return 2
+	case model.PeriodYear:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func periodKey(periodType model.PeriodType, period string) int {
+	switch periodType {
+	case model.PeriodMonth:
+		year, month, ok := model.ParseYearMonth(period)
+		if !ok {
+			return 0
+		}
+		return year*100 + month
+	case model.PeriodQuarter:
+		year, quarter, ok := model.ParseYearQuarter(period)
+		if !ok {
+			return 0
+		}
+		return year*10 + quarter
+	case model.PeriodYear:
+		year, ok := model.ParseYear(period)
+		if !ok {
+			return 0
+		}
+		return year
+	default:
+		return 0
+	}
+}
+
+func seriesKey(periodType model.PeriodType, period string) string {
+	return string(periodType) + "|" + period
+}
+
+func seriesValue(series map[model.Flow]map[string]float64, flow model.Flow, periodType model.PeriodType, period string) (float64, bool) {
+	if series == nil {
+		return 0, false
+	}
+	flowSeries, ok := series[flow]
+	if !ok {
+		return 0, false
+	}
+	value, ok := flowSeries[seriesKey(periodType, period)]
+	if !ok {
+		return 0, false
+	}
+	return value, true
+}
+
+func buildGrowth(series map[model.Flow]map[string]float64, periodType model.PeriodType, period, basis string) (string, *growthBlock) {
+	prev := basePeriod(basis, periodType, period)
+	if prev == "" {
+		return "", nil
+	}
+
+	currentExport, exportOk := basisValue(series, model.FlowExport, periodType, period, basis)
+	prevExport, prevExportOk := basisValue(series, model.FlowExport, periodType, prev, basis)
+	currentImport, importOk := basisValue(series, model.FlowImport, periodType, period, basis)
+	prevImport, prevImportOk := basisValue(series, model.FlowImport, periodType, prev, basis)
+
+	exportGrowth := growthForValue(currentExport, prevExport, exportOk, prevExportOk)
+	importGrowth := growthForValue(currentImport, prevImport, importOk, prevImportOk)
+
+	currentTrade, tradeOk := tradeValues(series, periodType, period, basis)
+	prevTrade, prevTradeOk := tradeValues(series, periodType, prev, basis)
+	tradeGrowth := growthForValue(currentTrade, prevTrade, tradeOk, prevTradeOk)
+
+	if exportGrowth == nil && importGrowth == nil && tradeGrowth == nil {
+		return "", nil
+	}
+
+	return prev, &growthBlock{
+		Export: exportGrowth,
+		Import: importGrowth,
+		Trade:  tradeGrowth,
+	}
+}
+
+func tradeValues(series map[model.Flow]map[string]float64, periodType model.PeriodType, period, basis string) (float64, bool) {
+	exportValue, exportOk := basisValue(series, model.FlowExport, periodType, period, basis)
+	importValue, importOk := basisValue(series, model.FlowImport, periodType, period, basis)
+	if !exportOk || !importOk {
+		return basisValue(series, model.FlowTotal, periodType, period, basis)
+	}
+	return exportValue + importValue, true
+}
+
+// Growth bases accepted by -growth-basis.
+const (
+	growthYoY = "yoy"
+	growthMoM = "mom"
+	growthQoQ = "qoq"
+	growthYTD = "ytd"
+)
+
+func parseGrowthBasis(value string) (string, error) {
+	basis := strings.ToLower(strings.TrimSpace(value))
+	switch basis {
+	case growthYoY, growthMoM, growthQoQ, growthYTD:
+		return basis, nil
+	default:
+		return "", fmt.Errorf("unsupported growth basis %q (expected yoy, mom, qoq, or ytd)", value)
+	}
+}
+
+// basePeriod returns the period growth is measured against. Month-over-month
+// applies only to monthly periods and quarter-over-quarter only to quarterly
+// ones; year-to-date compares with the same point of the previous year.
+func basePeriod(basis string, periodType model.PeriodType, period string) string {
+	switch basis {
+	case growthMoM:
+		if periodType != model.PeriodMonth {
+			return ""
+		}
+		return model.PreviousPeriod(periodType, period)
+	case growthQoQ:
+		if periodType != model.PeriodQuarter {
+			return ""
+		}
+		return model.PreviousPeriod(periodType, period)
+	default:
+		return prevPeriod(periodType, period)
+	}
+}
+
+// basisValue is the flow value at period, or for year-to-date the sum of every
+// period of that year through period. A year-to-date value needs each of those
+// periods to be present.
+func basisValue(series map[model.Flow]map[string]float64, flow model.Flow, periodType model.PeriodType, period, basis string) (float64, bool) {
+	if basis != growthYTD {
+		return seriesValue(series, flow, periodType, period)
+	}
+	var first string
+	switch periodType {
+	case model.PeriodMonth:
+		year, _, ok := model.ParseYearMonth(period)
+		if !ok {
+			return 0, false
+		}
+		first = fmt.Sprintf("%04d-01", year)
+	case model.PeriodQuarter:
+		year, _, ok := model.ParseYearQuarter(period)
+		if !ok {
+			return 0, false
+		}
+		first = fmt.Sprintf("%04d-Q1", year)
+	default:
+		return seriesValue(series, flow, periodType, period)
+	}
+	yearToDate, err := model.NewPeriodRange(periodType, first, period)
+	if err != nil {
+		return 0, false
+	}
+	periods := yearToDate.Periods()
+	total := 0.0
+	for _, current := range periods {
+		value, ok := seriesValue(series, flow, periodType, current)
+		if !ok {
+			return 0, false
+		}
+		total += value
+	}
+	return total, true
+}
+
+func growthForValue(current, prev float64, currentOk, prevOk bool) *float64 {
+	if !currentOk || !prevOk {
+		return nil
+	}
+	if prev == 0 {
+		return nil
+	}
+	value := (current - prev) / prev
+	return &value
+}
+
+func prevPeriod(periodType model.PeriodType, period string) string {
+	switch periodType {
+	case model.PeriodMonth:
+		year, month, ok := model.ParseYearMonth(period)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("%04d-%02d", year-1, month)
+	case model.PeriodQuarter:
+		year, quarter, ok := model.ParseYearQuarter(period)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("%04d-Q%d", year-1, quarter)
+	case model.PeriodYear:
+		year, ok := model.ParseYear(period)
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("%04d", year-1)
+	default:
+		return ""
+	}
+}
+
+func ensureRequiredPartners(partners []string, required []string) error {
+	set := make(map[string]struct{}, len(partners))
+	for _, partner := range partners {
+		normalized := strings.ToUpper(partner)
+		if normalized == worldPartner {
+			return fmt.Errorf("%s is the share-of-total denominator, not a tracked partner", worldPartner)
+		}
+		if _, exists := set[normalized]; exists {
+			return fmt.Errorf("duplicate partner %s", normalized)
+		}
+		set[normalized] = struct{}{}
+	}
+	for _, req := range required {
+		normalized := strings.ToUpper(req)
+		if _, ok := set[normalized]; !ok {
+			return fmt.Errorf("missing partner %s", normalized)
+		}
+	}
+	return nil
+}
+
+func placeholders(count int) string {
+	if count <= 0 {
+		return ""
+	}
+	return strings.TrimRight(strings.Repeat("?,", count), ",")
+}
@@ -0,0 +1,318 @@
+package publisher
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/store/sqlite"
+	"tradegravity/internal/synthetic"
+)
+
+func TestBuildLatestCalculatesGrowthAndShare(t *testing.T) {
+	rows := []observationRow{
+		{ReporterISO: "kor", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 10000},
+		{ReporterISO: "kor", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 10000},
+		{ReporterISO: "kor", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 12000},
+		{ReporterISO: "kor", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2024", Value: 8000},
+		{ReporterISO: "kor", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 4000},
+		{ReporterISO: "kor", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 10000},
+		{ReporterISO: "kor", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 5000},
+		{ReporterISO: "kor", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2024", Value: 15000},
+	}
+
+	got := buildLatest(rows, []string{"USA", "CHN"}, growthYoY, alignLatest, mixedAllow)
+	if len(got) != 1 {
+		t.Fatalf("buildLatest() returned %d rows, want 1", len(got))
+	}
+
+	entry := got[0]
+	if entry.ISO3 != "KOR" {
+		t.Fatalf("ISO3 = %q, want KOR", entry.ISO3)
+	}
+	if entry.USA.Trade != 200 || entry.CHN.Trade != 200 || entry.Total != 400 {
+		t.Fatalf("unexpected trade totals: USA=%v CHN=%v total=%v", entry.USA.Trade, entry.CHN.Trade, entry.Total)
+	}
+	assertFloat(t, "share_cn", entry.ShareCN, 0.5)
+
+	if entry.USA.PrevPeriod != "2023" || entry.USA.Growth == nil {
+		t.Fatalf("USA growth metadata = %#v, prev=%q", entry.USA.Growth, entry.USA.PrevPeriod)
+	}
+	assertFloatPtr(t, "USA export growth", entry.USA.Growth.Export, 0.2)
+	assertFloatPtr(t, "USA import growth", entry.USA.Growth.Import, -0.2)
+	assertFloatPtr(t, "USA trade growth", entry.USA.Growth.Trade, 0)
+
+	if entry.CHN.Growth == nil {
+		t.Fatal("CHN growth is nil")
+	}
+	assertFloatPtr(t, "CHN trade growth", entry.CHN.Growth.Trade, 60.0/140.0)
+}
+
+func TestComparePeriodsUsesGranularityThenRecency(t *testing.T) {
+	tests := []struct {
+		name             string
+		aType, bType     model.PeriodType
+		aPeriod, bPeriod string
+		want             int
+	}{
+		{name: "newer year", aType: model.PeriodYear, aPeriod: "2024", bType: model.PeriodYear, bPeriod: "2023", want: 1},
+		{name: "older month", aType: model.PeriodMonth, aPeriod: "2024-01", bType: model.PeriodMonth, bPeriod: "2024-02", want: -1},
+		{name: "month preferred to year", aType: model.PeriodMonth, aPeriod: "2023-01", bType: model.PeriodYear, bPeriod: "2024", want: 1},
+		{name: "same quarter", aType: model.PeriodQuarter, aPeriod: "2024-Q2", bType: model.PeriodQuarter, bPeriod: "2024Q2", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := comparePeriods(tt.aType, tt.aPeriod, tt.bType, tt.bPeriod); got != tt.want {
+				t.Fatalf("comparePeriods() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrowthForValueRejectsMissingOrZeroBaseline(t *testing.T) {
+	if got := growthForValue(10, 0, true, true); got != nil {
+		t.Fatalf("zero baseline returned %v, want nil", *got)
+	}
+	if got := growthForValue(10, 5, false, true); got != nil {
+		t.Fatalf("missing current value returned %v, want nil", *got)
+	}
+}
+
+func TestBuildMetaSummarizesCoverageAndPeriods(t *testing.T) {
+	latest := []latestEntry{
+		{
+			ISO3: "JPN",
+			Partners: map[string]partnerBlock{
+				"USA": {PeriodType: model.PeriodYear, Period: "2023"},
+				"CHN": {PeriodType: model.PeriodYear, Period: "2023"},
+			},
+		},
+		{
+			ISO3:     "KOR",
+			Partners: map[string]partnerBlock{"USA": {PeriodType: model.PeriodYear, Period: "2021"}},
+		},
+	}
+	got := buildMeta("2026-07-15T00:00:00Z", " WITS ", []string{"USA", "CHN"}, 4, latest)
+	if got.SchemaVersion != schemaVersion || got.Provider != "wits" {
+		t.Fatalf("schema/provider = %q/%q", got.SchemaVersion, got.Provider)
+	}
+	if got.ReporterCount != 2 || got.ObservationCount != 4 {
+		t.Fatalf("reporter/observation counts = %d/%d", got.ReporterCount, got.ObservationCount)
+	}
+	if got.ExpectedPartnerBlocks != 4 || got.AvailablePartnerBlocks != 3 || got.MissingPartnerBlocks != 1 {
+		t.Fatalf("coverage = expected %d available %d missing %d", got.ExpectedPartnerBlocks, got.AvailablePartnerBlocks, got.MissingPartnerBlocks)
+	}
+	if got.PeriodCounts["Y:2023"] != 2 || got.PeriodCounts["Y:2021"] != 1 {
+		t.Fatalf("period counts = %#v", got.PeriodCounts)
+	}
+}
+
+func TestBuildLatestSortsReporters(t *testing.T) {
+	rows := []observationRow{
+		{ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 100},
+		{ReporterISO: "JPN", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 100},
+	}
+
+	got := buildLatest(rows, []string{"USA", "CHN"}, growthYoY, alignLatest, mixedAllow)
+	if len(got) != 2 || got[0].ISO3 != "JPN" || got[1].ISO3 != "KOR" {
+		t.Fatalf("reporter order = %#v, want JPN then KOR", got)
+	}
+}
+
+func TestEnsureRequiredPartnersRejectsDuplicatesAndMissingPartners(t *testing.T) {
+	for _, partners := range [][]string{{"USA", "CHN"}, {"USA", "CHN", "EUU", "JPN"}} {
+		if err := ensureRequiredPartners(partners, []string{"USA", "CHN"}); err != nil {
+			t.Fatalf("valid partners %v rejected: %v", partners, err)
+		}
+	}
+	for _, partners := range [][]string{
+		{"USA"},
+		{"CHN", "JPN"},
+		{"USA", "CHN", "USA"},
+	} {
+		if err := ensureRequiredPartners(partners, []string{"USA", "CHN"}); err == nil {
+			t.Fatalf("ensureRequiredPartners(%v) returned nil error", partners)
+		}
+	}
+}
+
+func TestBuildLatestTracksConfiguredPartners(t *testing.T) {
+	rows := []observationRow{
+		{ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 3000},
+		{ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 5000},
+		{ReporterISO: "KOR", PartnerISO: "JPN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 2000},
+		{ReporterISO: "KOR", PartnerISO: "DEU", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 9900},
+	}
+
+	got := buildLatest(rows, []string{"USA", "CHN", "JPN", "EUU"}, growthYoY, alignLatest, mixedAllow)
+	if len(got) != 1 {
+		t.Fatalf("buildLatest() returned %d rows, want 1", len(got))
+	}
+	entry := got[0]
+	if len(entry.Partners) != 3 || entry.Partners["JPN"].Trade != 20 {
+		t.Fatalf("partners = %#v, want USA, CHN, and JPN blocks", entry.Partners)
+	}
+	if entry.TrackedTotal != 100 || entry.Shares["CHN"] != 0.5 || entry.Shares["JPN"] != 0.2 {
+		t.Fatalf("tracked total/shares = %v/%#v", entry.TrackedTotal, entry.Shares)
+	}
+	if entry.Total != 80 || entry.ShareCN != 0.625 || entry.CHN.Trade != 50 {
+		t.Fatalf("US-China lens changed: total %v share_cn %v", entry.Total, entry.ShareCN)
+	}
+}
+
+func assertFloatPtr(t *testing.T, name string, got *float64, want float64) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("%s is nil, want %v", name, want)
+	}
+	assertFloat(t, name, *got, want)
+}
+
+func assertFloat(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestBuildGrowthSupportsPeriodOverPeriodAndYearToDate(t *testing.T) {
+	series := map[model.Flow]map[string]float64{
+		model.FlowExport: {"M|2023-01": 10, "M|2023-02": 10, "M|2024-01": 12, "M|2024-02": 18},
+		model.FlowImport: {"M|2023-01": 5, "M|2023-02": 5, "M|2024-01": 5, "M|2024-02": 10},
+	}
+
+	prev, growth := buildGrowth(series, model.PeriodMonth, "2024-02", growthMoM)
+	if prev != "2024-01" || growth == nil {
+		t.Fatalf("mom prev/growth = %q/%v", prev, growth)
+	}
+	assertFloatPtr(t, "mom export", growth.Export, 0.5)
+
+	prev, growth = buildGrowth(series, model.PeriodMonth, "2024-02", growthYTD)
+	if prev != "2023-02" || growth == nil {
+		t.Fatalf("ytd prev/growth = %q/%v", prev, growth)
+	}
+	assertFloatPtr(t, "ytd export", growth.Export, 0.5)
+	assertFloatPtr(t, "ytd trade", growth.Trade, 0.5)
+
+	if prev, growth := buildGrowth(series, model.PeriodMonth, "2024-02", growthQoQ); prev != "" || growth != nil {
+		t.Fatalf("qoq on monthly data = %q/%v, want none", prev, growth)
+	}
+	if got := basePeriod(growthQoQ, model.PeriodQuarter, "2024-Q1"); got != "2023-Q4" {
+		t.Fatalf("qoq base period = %q", got)
+	}
+	if got := basePeriod(growthMoM, model.PeriodMonth, "2024-01"); got != "2023-12" {
+		t.Fatalf("mom base period = %q", got)
+	}
+	if _, err := parseGrowthBasis("wow"); err == nil {
+		t.Fatal("expected unsupported growth basis to be rejected")
+	}
+}
+
+func TestPublicationTimePrefersFlagThenSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	if got, err := publicationTime(""); err != nil || got != "" {
+		t.Fatalf("publicationTime() without a pin = %q, %v", got, err)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "1767225600")
+	if got, err := publicationTime(""); err != nil || got != "2026-01-01T00:00:00Z" {
+		t.Fatalf("publicationTime() from SOURCE_DATE_EPOCH = %q, %v", got, err)
+	}
+	if got, err := publicationTime("2026-03-04T05:06:07+09:00"); err != nil || got != "2026-03-03T20:06:07Z" {
+		t.Fatalf("publicationTime() from the flag = %q, %v", got, err)
+	}
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err := publicationTime(""); err == nil {
+		t.Fatal("publicationTime() accepted an invalid SOURCE_DATE_EPOCH")
+	}
+}
+
+func TestEachObservationChunkScansInChunksOfSharedStrings(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tradegravity.db")
+	st, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := synthetic.Config{Observations: 10_000, Years: 3}
+	if _, err := synthetic.Fill(context.Background(), st, cfg); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	tracked := 0
+	synthetic.Each(cfg, func(observation model.Observation) error {
+		if observation.PartnerISO3 != worldPartner {
+			tracked++
+		}
+		return nil
+	})
+
+	var sizes []int
+	err = eachObservationChunk(dbPath, "wits", []string{"USA", "CHN"}, nil, func(chunk []observationRow) error {
+		sizes = append(sizes, len(chunk))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 2 || sizes[0] != observationChunkSize || sizes[1] != tracked-observationChunkSize {
+		t.Fatalf("chunk sizes = %v for %d rows", sizes, tracked)
+	}
+
+	rows, err := loadObservations(dbPath, "wits", []string{"USA", "CHN"}, nil)
+	if err != nil || len(rows) != tracked {
+		t.Fatalf("loaded %d rows, %v", len(rows), err)
+	}
+	first := rows[0]
+	for _, row := range rows {
+		if row.Flow != model.FlowExport && row.Flow != model.FlowImport || row.PeriodType != model.PeriodMonth && row.PeriodType != model.PeriodYear {
+			t.Fatalf("row = %+v", row)
+		}
+		if row.Period == first.Period && unsafe.StringData(row.Period) != unsafe.StringData(first.Period) ||
+			unsafe.StringData(row.Provider) != unsafe.StringData(first.Provider) {
+			t.Fatalf("rows hold separate copies of %s", first.Period)
+		}
+	}
+}
+
+func TestEachReporterObservationsGroupsWholeReporters(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tradegravity.db")
+	st, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := synthetic.Config{Observations: 10_000, Years: 3}
+	if _, err := synthetic.Fill(context.Background(), st, cfg); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	partners := []string{"USA", "CHN"}
+	full, err := loadObservations(dbPath, "wits", partners, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reporters []string
+	count := 0
+	err = eachReporterObservations(dbPath, "wits", partners, nil, func(group []observationRow) error {
+		for _, row := range group {
+			if row.ReporterISO != group[0].ReporterISO {
+				t.Fatalf("group of %s holds %s", group[0].ReporterISO, row.ReporterISO)
+			}
+		}
+		if len(reporters) > 0 && reporters[len(reporters)-1] >= group[0].ReporterISO {
+			t.Fatalf("%s after %v", group[0].ReporterISO, reporters)
+		}
+		reporters = append(reporters, group[0].ReporterISO)
+		count += len(group)
+		return nil
+	})
+	if err != nil || count != len(full) {
+		t.Fatalf("grouped %d of %d rows, %v", count, len(full), err)
+	}
+	if want, _ := synthetic.Reporters(cfg); len(reporters) != len(want) {
+		t.Fatalf("%d reporter groups, want %d", len(reporters), len(want))
+	}
+}
@@ -0,0 +1,152 @@
+package publisher
+
+import (
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+type rankingsFile struct {
+	SchemaVersion string    `json:"schema_version"`
+	GeneratedAt   string    `json:"generated_at"`
+	Provider      string    `json:"provider"`
+	PeriodType    string    `json:"period_type"`
+	Period        string    `json:"period"`
+	PrevPeriod    string    `json:"prev_period,omitempty"`
+	Limit         int       `json:"limit"`
+	Rankings      []ranking `json:"rankings"`
+}
+
+type ranking struct {
+	ID    string       `json:"id"`
+	Title string       `json:"title"`
+	Rows  []rankingRow `json:"rows"`
+}
+
+// rankingRow is one reporter's place in a ranking. PrevRank is the reporter's
+// rank on the same metric one period earlier; RankDelta is positive when the
+// reporter moved up.
+type rankingRow struct {
+	Rank      int     `json:"rank"`
+	ISO3      string  `json:"iso3"`
+	Name      string  `json:"name,omitempty"`
+	Value     float64 `json:"value"`
+	PrevRank  *int    `json:"prev_rank,omitempty"`
+	RankDelta *int    `json:"rank_delta,omitempty"`
+}
+
+type rankingMetric struct {
+	id, title string
+	value     func(current, previous *seriesPoint) (float64, bool)
+}
+
+var rankingMetrics = []rankingMetric{
+	{id: "share_cn", title: "Highest China share of USA+CHN trade", value: func(current, _ *seriesPoint) (float64, bool) {
+		return current.ShareCN, true
+	}},
+	{id: "share_cn_change", title: "Largest year-over-year rise in China share", value: func(current, previous *seriesPoint) (float64, bool) {
+		if previous == nil || !previous.Comparable || previous.Total <= 0 {
+			return 0, false
+		}
+		return current.ShareCN - previous.ShareCN, true
+	}},
+	{id: "total_trade", title: "Largest USA+CHN trade", value: func(current, _ *seriesPoint) (float64, bool) {
+		return current.Total, true
+	}},
+	{id: "trade_growth", title: "Fastest year-over-year USA+CHN trade growth", value: func(current, previous *seriesPoint) (float64, bool) {
+		if previous == nil || !previous.Comparable || previous.Total <= 0 {
+			return 0, false
+		}
+		return (current.Total - previous.Total) / previous.Total, true
+	}},
+}
+
+// buildRankings ranks reporters at the dominant latest period. Only points
+// with both partner blocks and a positive total are ranked, so shares are
+// comparable across reporters.
+func buildRankings(generatedAt, provider string, history seriesFile, latest []latestEntry, limit int) rankingsFile {
+	output := rankingsFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Limit:         limit,
+		Rankings:      []ranking{},
+	}
+	periodType, period, ok := strings.Cut(dominantLatestPeriod(latest), ":")
+	if !ok {
+		return output
+	}
+	output.PeriodType = periodType
+	output.Period = period
+	output.PrevPeriod = prevPeriod(model.PeriodType(periodType), period)
+
+	names := make(map[string]string, len(latest))
+	for _, row := range latest {
+		names[row.ISO3] = row.Name
+	}
+	points := make(map[string]map[string]*seriesPoint, len(history.Rows))
+	for rowIndex := range history.Rows {
+		row := &history.Rows[rowIndex]
+		byPeriod := make(map[string]*seriesPoint, len(row.Points))
+		for pointIndex := range row.Points {
+			point := &row.Points[pointIndex]
+			if point.Comparable && point.Total > 0 {
+				byPeriod[seriesKey(point.PeriodType, point.Period)] = point
+			}
+		}
+		points[row.ISO3] = byPeriod
+	}
+
+	for _, metric := range rankingMetrics {
+		current := rankAt(points, metric, model.PeriodType(periodType), period)
+		previous := rankAt(points, metric, model.PeriodType(periodType), output.PrevPeriod)
+		previousRank := make(map[string]int, len(previous))
+		for _, row := range previous {
+			previousRank[row.ISO3] = row.Rank
+		}
+		if limit > 0 && len(current) > limit {
+			current = current[:limit]
+		}
+		for index := range current {
+			current[index].Name = names[current[index].ISO3]
+			if rank, ok := previousRank[current[index].ISO3]; ok {
+				delta := rank - current[index].Rank
+				current[index].PrevRank = &rank
+				current[index].RankDelta = &delta
+			}
+		}
+		output.Rankings = append(output.Rankings, ranking{ID: metric.id, Title: metric.title, Rows: current})
+	}
+	return output
+}
+
+func rankAt(points map[string]map[string]*seriesPoint, metric rankingMetric, periodType model.PeriodType, period string) []rankingRow {
+	rows := []rankingRow{}
+	if period == "" {
+		return rows
+	}
+	key := seriesKey(periodType, period)
+	previousKey := seriesKey(periodType, prevPeriod(periodType, period))
+	for iso3, byPeriod := range points {
+		current := byPeriod[key]
+		if current == nil {
+			continue
+		}
+		value, ok := metric.value(current, byPeriod[previousKey])
+		if !ok {
+			continue
+		}
+		rows = append(rows, rankingRow{ISO3: iso3, Value: value})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Value != rows[j].Value {
+			return rows[i].Value > rows[j].Value
+		}
+		return rows[i].ISO3 < rows[j].ISO3
+	})
+	for index := range rows {
+		rows[index].Rank = index + 1
+	}
+	return rows
+}
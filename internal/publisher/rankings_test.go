@@ -0,0 +1,48 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildRankingsOrdersReportersAndTracksRankDeltas(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter, period string, usa, chn float64) {
+		rows = append(rows,
+			observationRow{ReporterISO: reporter, PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(usa)},
+			observationRow{ReporterISO: reporter, PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(chn)},
+		)
+	}
+	add("KOR", "2022", 80, 20)
+	add("KOR", "2023", 40, 60)
+	add("JPN", "2022", 50, 50)
+	add("JPN", "2023", 60, 40)
+	add("VNM", "2023", 10, 90)
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+	latest := []latestEntry{
+		{ISO3: "KOR", Name: "Korea", USA: partnerBlock{PeriodType: model.PeriodYear, Period: "2023"}, CHN: partnerBlock{PeriodType: model.PeriodYear, Period: "2023"}},
+		{ISO3: "JPN", USA: partnerBlock{PeriodType: model.PeriodYear, Period: "2023"}, CHN: partnerBlock{PeriodType: model.PeriodYear, Period: "2023"}},
+	}
+
+	output := buildRankings("2026-01-01T00:00:00Z", "wits", history, latest, 2)
+	if output.Period != "2023" || output.PrevPeriod != "2022" || len(output.Rankings) != 4 {
+		t.Fatalf("unexpected rankings header: %+v", output)
+	}
+	byID := make(map[string]ranking)
+	for _, entry := range output.Rankings {
+		byID[entry.ID] = entry
+	}
+	share := byID["share_cn"].Rows
+	if len(share) != 2 || share[0].ISO3 != "VNM" || share[1].ISO3 != "KOR" || share[1].Name != "Korea" {
+		t.Fatalf("unexpected share ranking: %+v", share)
+	}
+	if share[0].PrevRank != nil || share[1].PrevRank == nil || *share[1].PrevRank != 2 || *share[1].RankDelta != 0 {
+		t.Fatalf("unexpected share rank deltas: %+v", share)
+	}
+	change := byID["share_cn_change"].Rows
+	if len(change) != 2 || change[0].ISO3 != "KOR" || math.Abs(change[0].Value-0.4) > 1e-9 {
+		t.Fatalf("unexpected share change ranking: %+v", change)
+	}
+}
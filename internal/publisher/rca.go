@@ -0,0 +1,222 @@
+package publisher
+
+import (
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// rcaFormula documents how rca/ values are derived.
+const rcaFormula = "rca = (reporter sector exports / reporter exports) / (reference sector exports / reference exports) per market; lean = (rca_chn - rca_usa) / (rca_chn + rca_usa)"
+
+// rcaIndexFile is rca/index.json: the reporters with a revealed comparative
+// advantage file and the year each one uses.
+type rcaIndexFile struct {
+	SchemaVersion  string           `json:"schema_version"`
+	GeneratedAt    string           `json:"generated_at"`
+	Provider       string           `json:"provider"`
+	Classification string           `json:"classification"`
+	Level          int              `json:"level"`
+	Formula        string           `json:"formula"`
+	Reporters      []rcaIndexRecord `json:"reporters"`
+}
+
+type rcaIndexRecord struct {
+	ISO3   string `json:"iso3"`
+	Period string `json:"period"`
+}
+
+// rcaFile is rca/{ISO3}.json: the Balassa index of each sector's exports in
+// the USA and China markets, and in the world market when world product
+// exports are collected. The reference is every reporter with product
+// exports to that market in the same year, so an index above 1 means the
+// reporter sells relatively more of the sector there than its peers do.
+type rcaFile struct {
+	SchemaVersion      string           `json:"schema_version"`
+	GeneratedAt        string           `json:"generated_at"`
+	Provider           string           `json:"provider"`
+	Classification     string           `json:"classification"`
+	Level              int              `json:"level"`
+	ReporterISO3       string           `json:"reporter_iso3"`
+	Name               string           `json:"name,omitempty"`
+	PeriodType         model.PeriodType `json:"period_type"`
+	Period             string           `json:"period"`
+	Formula            string           `json:"formula"`
+	ReferenceReporters map[string]int   `json:"reference_reporters"`
+	Rows               []rcaEntry       `json:"rows"`
+}
+
+// rcaEntry is one sector. Lean runs from -1 (specialized only toward the
+// USA market) to 1 (only toward China) and is set when both indexes are.
+type rcaEntry struct {
+	Code  string    `json:"code"`
+	Name  string    `json:"name"`
+	USA   *rcaBlock `json:"usa,omitempty"`
+	CHN   *rcaBlock `json:"chn,omitempty"`
+	World *rcaBlock `json:"world,omitempty"`
+	Lean  *float64  `json:"lean,omitempty"`
+}
+
+type rcaBlock struct {
+	Export         float64 `json:"export"`
+	Share          float64 `json:"share"`
+	ReferenceShare float64 `json:"reference_share"`
+	RCA            float64 `json:"rca"`
+}
+
+// rcaMarkets are the partners an index is computed against, in output
+// order.
+var rcaMarkets = []string{"USA", "CHN", worldPartner}
+
+// buildRCAFiles computes each reporter's index for its latest year of
+// annual product exports. Monthly and quarterly sector exports are too
+// sparse for a stable reference, so they are not used.
+func buildRCAFiles(generatedAt, provider string, level int, observations []observationRow, labels map[string]string, latest []latestEntry) (rcaIndexFile, map[string]rcaFile) {
+	type exportKey struct{ reporter, market, period string }
+	sectors := make(map[exportKey]map[string]model.Cents)
+	totals := make(map[exportKey]model.Cents)
+	latestYear := make(map[string]string)
+	classification := "HS"
+	for _, row := range observations {
+		reporter, market := strings.ToUpper(row.ReporterISO), strings.ToUpper(row.PartnerISO)
+		if reporter == "" || row.ProductCode == "" || row.Flow != model.FlowExport || row.PeriodType != model.PeriodYear || row.Value <= 0 {
+			continue
+		}
+		known := false
+		for _, candidate := range rcaMarkets {
+			known = known || market == candidate
+		}
+		if !known {
+			continue
+		}
+		if row.Classification != "" {
+			classification = strings.ToUpper(row.Classification)
+		}
+		key := exportKey{reporter, market, row.Period}
+		if sectors[key] == nil {
+			sectors[key] = make(map[string]model.Cents)
+		}
+		sectors[key][row.ProductCode] += row.Value
+		totals[key] += row.Value
+		if market != worldPartner && row.Period > latestYear[reporter] {
+			latestYear[reporter] = row.Period
+		}
+	}
+
+	// The reference sums every reporter's exports per market, year, and
+	// sector, keyed with an empty reporter.
+	referenceSectors := make(map[exportKey]map[string]model.Cents)
+	referenceTotals := make(map[exportKey]model.Cents)
+	referenceCounts := make(map[exportKey]int)
+	for key, values := range sectors {
+		reference := exportKey{"", key.market, key.period}
+		if referenceSectors[reference] == nil {
+			referenceSectors[reference] = make(map[string]model.Cents)
+		}
+		for code, value := range values {
+			referenceSectors[reference][code] += value
+		}
+		referenceTotals[reference] += totals[key]
+		referenceCounts[reference]++
+	}
+
+	names := make(map[string]string, len(latest))
+	for _, row := range latest {
+		names[row.ISO3] = row.Name
+	}
+	index := rcaIndexFile{
+		SchemaVersion:  schemaVersion,
+		GeneratedAt:    generatedAt,
+		Provider:       strings.ToLower(strings.TrimSpace(provider)),
+		Classification: classification,
+		Level:          level,
+		Formula:        rcaFormula,
+		Reporters:      []rcaIndexRecord{},
+	}
+	files := make(map[string]rcaFile)
+	for reporter, period := range latestYear {
+		file := rcaFile{
+			SchemaVersion:      schemaVersion,
+			GeneratedAt:        generatedAt,
+			Provider:           index.Provider,
+			Classification:     classification,
+			Level:              level,
+			ReporterISO3:       reporter,
+			Name:               names[reporter],
+			PeriodType:         model.PeriodYear,
+			Period:             period,
+			Formula:            rcaFormula,
+			ReferenceReporters: make(map[string]int),
+			Rows:               []rcaEntry{},
+		}
+		entries := make(map[string]*rcaEntry)
+		for _, market := range rcaMarkets {
+			key, reference := exportKey{reporter, market, period}, exportKey{"", market, period}
+			total, referenceTotal := totals[key], referenceTotals[reference]
+			if total <= 0 || referenceTotal <= 0 {
+				continue
+			}
+			file.ReferenceReporters[rcaMarketKey(market)] = referenceCounts[reference]
+			for code, value := range sectors[key] {
+				share := float64(value) / float64(total)
+				referenceShare := float64(referenceSectors[reference][code]) / float64(referenceTotal)
+				block := &rcaBlock{Export: value.USD(), Share: share, ReferenceShare: referenceShare, RCA: share / referenceShare}
+				entry := entries[code]
+				if entry == nil {
+					entry = &rcaEntry{Code: code, Name: labels[code]}
+					if entry.Name == "" {
+						entry.Name = "HS " + code
+					}
+					entries[code] = entry
+				}
+				switch market {
+				case "USA":
+					entry.USA = block
+				case "CHN":
+					entry.CHN = block
+				default:
+					entry.World = block
+				}
+			}
+		}
+		for _, entry := range entries {
+			if entry.USA != nil && entry.CHN != nil {
+				lean := (entry.CHN.RCA - entry.USA.RCA) / (entry.CHN.RCA + entry.USA.RCA)
+				entry.Lean = &lean
+			}
+			file.Rows = append(file.Rows, *entry)
+		}
+		sort.Slice(file.Rows, func(i, j int) bool {
+			left, right := rcaPartnerExports(file.Rows[i]), rcaPartnerExports(file.Rows[j])
+			if left != right {
+				return left > right
+			}
+			return file.Rows[i].Code < file.Rows[j].Code
+		})
+		files[reporter] = file
+		index.Reporters = append(index.Reporters, rcaIndexRecord{ISO3: reporter, Period: period})
+	}
+	sort.Slice(index.Reporters, func(i, j int) bool { return index.Reporters[i].ISO3 < index.Reporters[j].ISO3 })
+	return index, files
+}
+
+// rcaMarketKey is the JSON name of a market's block.
+func rcaMarketKey(market string) string {
+	if market == worldPartner {
+		return "world"
+	}
+	return strings.ToLower(market)
+}
+
+// rcaPartnerExports is a sector's exports to the USA and China, the order
+// rows are listed in.
+func rcaPartnerExports(entry rcaEntry) float64 {
+	var total float64
+	for _, block := range []*rcaBlock{entry.USA, entry.CHN} {
+		if block != nil {
+			total += block.Export
+		}
+	}
+	return total
+}
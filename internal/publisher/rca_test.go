@@ -0,0 +1,58 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildRCAFilesComparesSectorsAcrossReporters(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter, partner string, flow model.Flow, periodType model.PeriodType, period, code string, value float64) {
+		rows = append(rows, observationRow{
+			Classification: "H6", ProductCode: code, ProductLevel: 2,
+			ReporterISO: reporter, PartnerISO: partner, Flow: flow, PeriodType: periodType, Period: period, Value: model.CentsFromUSD(value),
+		})
+	}
+	add("KOR", "USA", model.FlowExport, model.PeriodYear, "2023", "85", 80)
+	add("KOR", "USA", model.FlowExport, model.PeriodYear, "2023", "87", 20)
+	add("KOR", "CHN", model.FlowExport, model.PeriodYear, "2023", "85", 30)
+	add("KOR", "CHN", model.FlowExport, model.PeriodYear, "2023", "87", 70)
+	add("KOR", "WLD", model.FlowExport, model.PeriodYear, "2023", "85", 50)
+	add("KOR", "WLD", model.FlowExport, model.PeriodYear, "2023", "87", 50)
+	add("JPN", "USA", model.FlowExport, model.PeriodYear, "2023", "85", 20)
+	add("JPN", "USA", model.FlowExport, model.PeriodYear, "2023", "87", 80)
+	add("JPN", "CHN", model.FlowExport, model.PeriodYear, "2023", "85", 70)
+	add("JPN", "CHN", model.FlowExport, model.PeriodYear, "2023", "87", 30)
+	// Older years, imports, and monthly rows do not enter the index.
+	add("KOR", "USA", model.FlowExport, model.PeriodYear, "2022", "85", 1000)
+	add("KOR", "USA", model.FlowImport, model.PeriodYear, "2023", "85", 1000)
+	add("JPN", "USA", model.FlowExport, model.PeriodMonth, "2024-01", "85", 1000)
+
+	index, files := buildRCAFiles("2026-01-01T00:00:00Z", "Comtrade", 2, rows, map[string]string{"85": "Electrical machinery"}, []latestEntry{{ISO3: "KOR", Name: "Korea"}})
+	if index.Provider != "comtrade" || index.Classification != "H6" || index.Formula != rcaFormula {
+		t.Fatalf("unexpected index header: %+v", index)
+	}
+	if len(index.Reporters) != 2 || index.Reporters[0] != (rcaIndexRecord{ISO3: "JPN", Period: "2023"}) || index.Reporters[1] != (rcaIndexRecord{ISO3: "KOR", Period: "2023"}) {
+		t.Fatalf("reporters = %+v, want JPN and KOR for 2023", index.Reporters)
+	}
+	kor := files["KOR"]
+	if kor.Name != "Korea" || kor.PeriodType != model.PeriodYear || kor.ReferenceReporters["usa"] != 2 || kor.ReferenceReporters["chn"] != 2 || kor.ReferenceReporters["world"] != 1 {
+		t.Fatalf("unexpected KOR header: %+v", kor)
+	}
+	if len(kor.Rows) != 2 || kor.Rows[0].Code != "85" || kor.Rows[0].Name != "Electrical machinery" || kor.Rows[1].Name != "HS 87" {
+		t.Fatalf("rows = %+v, want 85 then 87 by USA+CHN exports", kor.Rows)
+	}
+	near := func(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+	machinery, vehicles := kor.Rows[0], kor.Rows[1]
+	if !near(machinery.USA.RCA, 1.6) || !near(machinery.CHN.RCA, 0.6) || !near(machinery.World.RCA, 1) || !near(*machinery.Lean, -1.0/2.2) {
+		t.Fatalf("machinery = usa %+v chn %+v world %+v lean %v", machinery.USA, machinery.CHN, machinery.World, *machinery.Lean)
+	}
+	if !near(vehicles.USA.RCA, 0.4) || !near(vehicles.CHN.RCA, 1.4) || !near(*vehicles.Lean, 1.0/1.8) || !near(vehicles.USA.ReferenceShare, 0.5) {
+		t.Fatalf("vehicles = usa %+v chn %+v lean %v", vehicles.USA, vehicles.CHN, *vehicles.Lean)
+	}
+	if jpn := files["JPN"]; jpn.Rows[0].World != nil || jpn.ReferenceReporters["world"] != 0 {
+		t.Fatalf("JPN has no world exports, got %+v", jpn)
+	}
+}
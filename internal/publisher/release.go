@@ -0,0 +1,156 @@
+package publisher
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"tradegravity/internal/ghrelease"
+)
+
+// releaseSumsName is the checksum asset uploaded beside the bundle, in the
+// format sha256sum -c reads.
+const releaseSumsName = "SHA256SUMS"
+
+var (
+	releaseRepoPattern = regexp.MustCompile(`^[A-Za-z0-9-]+/[A-Za-z0-9._-]+$`)
+	releaseTagPattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// parseReleaseRepo accepts owner/name or the repository's GitHub URL.
+func parseReleaseRepo(value string) (string, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "/")
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "https://github.com/"), ".git")
+	if !releaseRepoPattern.MatchString(value) {
+		return "", fmt.Errorf("%q is not an owner/name repository", value)
+	}
+	return value, nil
+}
+
+// parseReleaseTag accepts an empty tag, for the default, or a plain tag name.
+func parseReleaseTag(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value != "" && !releaseTagPattern.MatchString(value) {
+		return "", fmt.Errorf("%q is not a valid tag name", value)
+	}
+	return value, nil
+}
+
+// releaseTag is the tag of the snapshot published at generatedAt:
+// data-20260715T120000Z.
+func releaseTag(generatedAt time.Time) string {
+	return "data-" + generatedAt.UTC().Format("20060102T150405Z")
+}
+
+// publishRelease attaches the build in outDir to the GitHub release tagged
+// tag in repo, or by default to one named after its generated_at, and
+// returns the tag and the number of assets uploaded.
+func publishRelease(ctx context.Context, outDir, repo, tag string) (string, int, error) {
+	var meta struct {
+		SchemaVersion string `json:"schema_version"`
+		GeneratedAt   string `json:"generated_at"`
+	}
+	if err := readPublished(outDir, "meta.json", &meta); err != nil {
+		return "", 0, err
+	}
+	generatedAt, err := time.Parse(time.RFC3339, meta.GeneratedAt)
+	if err != nil {
+		return "", 0, fmt.Errorf("meta.json generated_at: %w", err)
+	}
+	if tag == "" {
+		tag = releaseTag(generatedAt)
+	}
+	bundle, sums, err := releaseBundle(outDir, tag, generatedAt)
+	if err != nil {
+		return "", 0, err
+	}
+
+	cfg, err := ghrelease.ConfigFromEnv()
+	if err != nil {
+		return "", 0, err
+	}
+	client, err := ghrelease.New(cfg)
+	if err != nil {
+		return "", 0, err
+	}
+	name := "TradeGravity data " + generatedAt.UTC().Format("2006-01-02 15:04Z")
+	body := fmt.Sprintf("Published dataset, schema %s, generated at %s.\n\nVerify the download with `sha256sum -c %s`; after unzipping, the same file checks every artifact.",
+		meta.SchemaVersion, meta.GeneratedAt, releaseSumsName)
+	release, err := client.EnsureRelease(ctx, repo, tag, name, body)
+	if err != nil {
+		return "", 0, err
+	}
+	// The checksums go last, so a release with SHA256SUMS has its bundle.
+	if err := client.UploadAsset(ctx, repo, release, releaseBundleName(tag), "application/zip", bundle); err != nil {
+		return "", 0, err
+	}
+	if err := client.UploadAsset(ctx, repo, release, releaseSumsName, "text/plain; charset=utf-8", sums); err != nil {
+		return "", 0, err
+	}
+	return tag, 2, nil
+}
+
+// releaseBundleName is the zip asset name for tag.
+func releaseBundleName(tag string) string {
+	return "tradegravity-" + tag + ".zip"
+}
+
+// releaseBundle zips every file under outDir except the checksum manifest
+// into a folder named after the bundle, dated generatedAt so the same build
+// always gives the same archive. The returned SHA256SUMS covers the zip and
+// each file as it unpacks.
+func releaseBundle(outDir, tag string, generatedAt time.Time) ([]byte, []byte, error) {
+	folder := strings.TrimSuffix(releaseBundleName(tag), ".zip")
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	var fileSums strings.Builder
+	err := filepath.WalkDir(outDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(outDir, path)
+		if err != nil {
+			return err
+		}
+		relative = filepath.ToSlash(relative)
+		if relative == checksumsName {
+			return nil
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		header := &zip.FileHeader{Name: folder + "/" + relative, Method: zip.Deflate, Modified: generatedAt.UTC()}
+		if strings.HasSuffix(relative, ".png") {
+			header.Method = zip.Store
+		}
+		file, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(body); err != nil {
+			return err
+		}
+		digest := sha256.Sum256(body)
+		fmt.Fprintf(&fileSums, "%s  %s\n", hex.EncodeToString(digest[:]), header.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, nil, err
+	}
+	digest := sha256.Sum256(archive.Bytes())
+	sums := fmt.Sprintf("%s  %s\n%s", hex.EncodeToString(digest[:]), releaseBundleName(tag), fileSums.String())
+	return archive.Bytes(), []byte(sums), nil
+}
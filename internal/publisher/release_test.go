@@ -0,0 +1,94 @@
+package publisher
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReleaseBundleZipsOutputWithChecksums(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"meta.json":          `{"generated_at":"2026-07-15T12:00:00Z"}`,
+		"countries/KOR.json": `{"iso3":"KOR"}`,
+		checksumsName:        `{}`,
+	}
+	for relative, body := range files {
+		path := filepath.Join(dir, filepath.FromSlash(relative))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	generatedAt := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+	tag := releaseTag(generatedAt)
+	if tag != "data-20260715T120000Z" {
+		t.Fatalf("tag = %q", tag)
+	}
+
+	bundle, sums, err := releaseBundle(dir, tag, generatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := releaseBundle(dir, tag, generatedAt)
+	if err != nil || !bytes.Equal(bundle, again) {
+		t.Fatalf("bundle is not reproducible: %v", err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+		if !file.Modified.Equal(generatedAt) {
+			t.Fatalf("%s modified = %v", file.Name, file.Modified)
+		}
+	}
+	want := "tradegravity-data-20260715T120000Z/countries/KOR.json,tradegravity-data-20260715T120000Z/meta.json"
+	if strings.Join(names, ",") != want {
+		t.Fatalf("entries = %q, want %s", names, want)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(sums)), "\n")
+	digest := sha256.Sum256(bundle)
+	if len(lines) != 3 || lines[0] != hex.EncodeToString(digest[:])+"  tradegravity-data-20260715T120000Z.zip" {
+		t.Fatalf("sums = %q", sums)
+	}
+	meta := sha256.Sum256([]byte(files["meta.json"]))
+	if lines[2] != hex.EncodeToString(meta[:])+"  tradegravity-data-20260715T120000Z/meta.json" {
+		t.Fatalf("meta line = %q", lines[2])
+	}
+}
+
+func TestParseReleaseRepoAndTag(t *testing.T) {
+	for input, want := range map[string]string{
+		"elecpapaya/TradeGravity":                     "elecpapaya/TradeGravity",
+		"https://github.com/elecpapaya/TradeGravity/": "elecpapaya/TradeGravity",
+		"https://github.com/owner/data.git":           "owner/data",
+	} {
+		got, err := parseReleaseRepo(input)
+		if err != nil || got != want {
+			t.Fatalf("parseReleaseRepo(%q) = %q, %v", input, got, err)
+		}
+	}
+	for _, input := range []string{"", "owner", "owner/name/extra", "https://gitlab.com/owner/name"} {
+		if _, err := parseReleaseRepo(input); err == nil {
+			t.Fatalf("parseReleaseRepo(%q) accepted", input)
+		}
+	}
+	if tag, err := parseReleaseTag(" v1.2.0 "); err != nil || tag != "v1.2.0" {
+		t.Fatalf("parseReleaseTag = %q, %v", tag, err)
+	}
+	if _, err := parseReleaseTag("data 1"); err == nil {
+		t.Fatal("parseReleaseTag accepted a space")
+	}
+}
@@ -0,0 +1,429 @@
+package publisher
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"tradegravity/internal/config"
+)
+
+//go:embed templates/report.html templates/report.md
+var reportTemplates embed.FS
+
+// reportData is the summary behind publisher report. Numbers are formatted
+// here so every output format shows the same text.
+type reportData struct {
+	GeneratedAt    string
+	Provider       string
+	ReporterCount  int
+	DominantPeriod string
+	Rankings       []reportRankingTable
+	Movers         []reportMoverTable
+	Coverage       reportCoverage
+	Regions        []reportRegion
+}
+
+type reportRankingTable struct {
+	Title  string
+	Period string
+	Rows   []reportRankingRow
+}
+
+type reportRankingRow struct {
+	Rank  int
+	ISO3  string
+	Name  string
+	Value string
+	// Move is the rank change since the previous period, such as ▲2, or
+	// "new" when the reporter was not ranked then.
+	Move string
+}
+
+type reportMoverTable struct {
+	Title  string
+	Window string
+	Rows   []reportMoverRow
+}
+
+type reportMoverRow struct {
+	Rank   int
+	ISO3   string
+	Name   string
+	Base   string
+	Value  string
+	Change string
+	// Direction is "up", "down", or empty for no change.
+	Direction string
+}
+
+// reportCoverage counts reporters by their data_as_of period and lists the
+// ones whose oldest flow is most out of date.
+type reportCoverage struct {
+	Reporters int
+	Periods   []reportPeriodCount
+	Stalest   []reportStaleReporter
+}
+
+type reportPeriodCount struct {
+	Period    string
+	Reporters int
+}
+
+type reportStaleReporter struct {
+	ISO3      string
+	Name      string
+	DataAsOf  string
+	Days      int
+	Providers string
+}
+
+// reportRegion is the China-tilt index, (CHN − USA)/(CHN + USA), over the
+// summed latest trade of a region's comparable reporters.
+type reportRegion struct {
+	Region    string
+	Reporters int
+	USATrade  string
+	CHNTrade  string
+	Tilt      string
+	TowardCHN bool
+}
+
+// Report renders a standalone summary of the published data in -dir for
+// readers who will not open JSON.
+func Report(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	dir := fs.String("dir", "site/data", "published data directory")
+	format := fs.String("format", "html", "report format: html or markdown")
+	top := fs.Int("top", 10, "rows per rankings, movers, and coverage table")
+	outPath := fs.String("out", "", "write the report here instead of stdout (optional)")
+	config.Parse(fs, args)
+
+	render, err := reportRenderer(*format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid format:", err)
+		os.Exit(1)
+	}
+	data, err := buildReport(*dir, *top)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read published data:", err)
+		os.Exit(1)
+	}
+	body, err := render(data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to render report:", err)
+		os.Exit(1)
+	}
+	if *outPath == "" {
+		os.Stdout.Write(body)
+		return
+	}
+	if err := os.WriteFile(*outPath, body, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write report:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("publisher report complete (out=%s format=%s)\n", *outPath, strings.ToLower(*format))
+}
+
+func reportRenderer(format string) (func(reportData) ([]byte, error), error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "html":
+		return renderHTMLReport, nil
+	case "markdown", "md":
+		return renderMarkdownReport, nil
+	default:
+		return nil, fmt.Errorf("unsupported report format %q (expected html or markdown)", format)
+	}
+}
+
+// buildReport reads meta.json, latest.json, and coverage.json from dir, and
+// rankings.json and movers.json when the build wrote them.
+func buildReport(dir string, top int) (reportData, error) {
+	var metadata metaFile
+	if err := readPublished(dir, "meta.json", &metadata); err != nil {
+		return reportData{}, err
+	}
+	var latest latestFile
+	if err := readPublished(dir, "latest.json", &latest); err != nil {
+		return reportData{}, err
+	}
+	var coverage coverageFile
+	if err := readPublished(dir, "coverage.json", &coverage); err != nil {
+		return reportData{}, err
+	}
+	var rankings rankingsFile
+	if err := readPublished(dir, "rankings.json", &rankings); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return reportData{}, err
+	}
+	var movers moversFile
+	if err := readPublished(dir, "movers.json", &movers); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return reportData{}, err
+	}
+	return reportData{
+		GeneratedAt:    metadata.GeneratedAt,
+		Provider:       metadata.Provider,
+		ReporterCount:  metadata.ReporterCount,
+		DominantPeriod: metadata.DominantPeriod,
+		Rankings:       reportRankings(rankings, top),
+		Movers:         reportMovers(movers, top),
+		Coverage:       reportCoverageSummary(coverage, top),
+		Regions:        reportRegions(latest.Rows),
+	}, nil
+}
+
+func reportRankings(rankings rankingsFile, top int) []reportRankingTable {
+	period := rankings.PeriodType + ":" + rankings.Period
+	if rankings.PrevPeriod != "" {
+		period += " vs " + rankings.PrevPeriod
+	}
+	var tables []reportRankingTable
+	for _, entry := range rankings.Rankings {
+		table := reportRankingTable{Title: entry.Title, Period: period}
+		for _, row := range entry.Rows {
+			if top > 0 && len(table.Rows) == top {
+				break
+			}
+			table.Rows = append(table.Rows, reportRankingRow{
+				Rank:  row.Rank,
+				ISO3:  row.ISO3,
+				Name:  row.Name,
+				Value: formatRankingValue(entry.ID, row.Value),
+				Move:  rankMove(row.PrevRank, row.RankDelta),
+			})
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+func formatRankingValue(id string, value float64) string {
+	switch id {
+	case "share_cn":
+		return formatPercent(value)
+	case "share_cn_change":
+		return signed(strconv.FormatFloat(math.Abs(value)*100, 'f', 1, 64)+" pp", value)
+	case "trade_growth":
+		return signed(formatPercent(math.Abs(value)), value)
+	default:
+		return formatUSD(value)
+	}
+}
+
+func rankMove(prevRank, delta *int) string {
+	switch {
+	case prevRank == nil || delta == nil:
+		return "new"
+	case *delta > 0:
+		return "▲" + strconv.Itoa(*delta)
+	case *delta < 0:
+		return "▼" + strconv.Itoa(-*delta)
+	default:
+		return "="
+	}
+}
+
+func reportMovers(movers moversFile, top int) []reportMoverTable {
+	var tables []reportMoverTable
+	for _, window := range movers.Windows {
+		for _, list := range window.Lists {
+			table := reportMoverTable{Title: list.Title, Window: movers.Period + " vs " + window.BasePeriod}
+			for _, row := range list.Rows {
+				if top > 0 && len(table.Rows) == top {
+					break
+				}
+				table.Rows = append(table.Rows, reportMoverRow{
+					Rank:      row.Rank,
+					ISO3:      row.ISO3,
+					Name:      row.Name,
+					Base:      formatMoverValue(list.ID, row.BaseValue),
+					Value:     formatMoverValue(list.ID, row.Value),
+					Change:    formatMoverChange(list.ID, row.Change),
+					Direction: direction(row.Change),
+				})
+			}
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+func formatMoverValue(listID string, value float64) string {
+	if strings.HasPrefix(listID, "share_") {
+		return formatPercent(value)
+	}
+	return formatUSD(value)
+}
+
+func formatMoverChange(listID string, change float64) string {
+	switch {
+	case strings.HasSuffix(listID, "_rel"):
+		return signed(formatPercent(change), change)
+	case strings.HasPrefix(listID, "share_"):
+		return signed(strconv.FormatFloat(math.Abs(change)*100, 'f', 1, 64)+" pp", change)
+	default:
+		return signed(formatUSD(math.Abs(change)), change)
+	}
+}
+
+func reportCoverageSummary(coverage coverageFile, top int) reportCoverage {
+	summary := reportCoverage{Reporters: len(coverage.Reporters)}
+	counts := make(map[string]int)
+	for _, reporter := range coverage.Reporters {
+		counts[reporter.DataAsOf]++
+		if reporter.MaxStalenessDays == nil {
+			continue
+		}
+		summary.Stalest = append(summary.Stalest, reportStaleReporter{
+			ISO3:      reporter.ISO3,
+			Name:      reporter.Name,
+			DataAsOf:  reporter.DataAsOf,
+			Days:      *reporter.MaxStalenessDays,
+			Providers: strings.Join(reporter.Providers, ", "),
+		})
+	}
+	for period, count := range counts {
+		if period == "" {
+			period = "none"
+		}
+		summary.Periods = append(summary.Periods, reportPeriodCount{Period: period, Reporters: count})
+	}
+	sort.Slice(summary.Periods, func(i, j int) bool {
+		if summary.Periods[i].Reporters != summary.Periods[j].Reporters {
+			return summary.Periods[i].Reporters > summary.Periods[j].Reporters
+		}
+		return summary.Periods[i].Period > summary.Periods[j].Period
+	})
+	sort.SliceStable(summary.Stalest, func(i, j int) bool {
+		if summary.Stalest[i].Days != summary.Stalest[j].Days {
+			return summary.Stalest[i].Days > summary.Stalest[j].Days
+		}
+		return summary.Stalest[i].ISO3 < summary.Stalest[j].ISO3
+	})
+	if top > 0 && len(summary.Stalest) > top {
+		summary.Stalest = summary.Stalest[:top]
+	}
+	return summary
+}
+
+// reportRegions sums the USA and CHN trade of comparable latest rows by
+// region. Rows without a region are grouped as "Unassigned".
+func reportRegions(rows []latestEntry) []reportRegion {
+	type totals struct {
+		reporters int
+		usa, chn  float64
+	}
+	byRegion := make(map[string]*totals)
+	for _, row := range rows {
+		if !row.SamePeriod || row.Incomparable || row.USA.Trade+row.CHN.Trade <= 0 {
+			continue
+		}
+		region := strings.TrimSpace(row.Region)
+		if region == "" {
+			region = "Unassigned"
+		}
+		if byRegion[region] == nil {
+			byRegion[region] = &totals{}
+		}
+		byRegion[region].reporters++
+		byRegion[region].usa += row.USA.Trade
+		byRegion[region].chn += row.CHN.Trade
+	}
+	regions := make([]reportRegion, 0, len(byRegion))
+	for region, sum := range byRegion {
+		tilt := (sum.chn - sum.usa) / (sum.chn + sum.usa)
+		regions = append(regions, reportRegion{
+			Region:    region,
+			Reporters: sum.reporters,
+			USATrade:  formatUSD(sum.usa),
+			CHNTrade:  formatUSD(sum.chn),
+			Tilt:      signed(strconv.FormatFloat(math.Abs(tilt), 'f', 3, 64), tilt),
+			TowardCHN: tilt > 0,
+		})
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Region < regions[j].Region })
+	return regions
+}
+
+func renderHTMLReport(data reportData) ([]byte, error) {
+	page, err := template.ParseFS(reportTemplates, "templates/report.html")
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	if err := page.Execute(&body, data); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// renderMarkdownReport renders GitHub-flavored Markdown tables for pasting
+// into issues and wikis.
+func renderMarkdownReport(data reportData) ([]byte, error) {
+	page, err := texttemplate.New("report.md").Funcs(texttemplate.FuncMap{"cell": markdownCell}).ParseFS(reportTemplates, "templates/report.md")
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	if err := page.Execute(&body, data); err != nil {
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// markdownCell keeps a value on one table row: pipes are escaped and line
+// breaks become spaces.
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// formatUSD abbreviates a USD amount to thousands, millions, billions, or
+// trillions.
+func formatUSD(value float64) string {
+	magnitude := math.Abs(value)
+	for _, unit := range []struct {
+		size   float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}} {
+		if magnitude >= unit.size {
+			return "$" + strconv.FormatFloat(value/unit.size, 'f', 1, 64) + unit.suffix
+		}
+	}
+	return "$" + strconv.FormatFloat(value, 'f', 0, 64)
+}
+
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value*100, 'f', 1, 64) + "%"
+}
+
+func direction(change float64) string {
+	switch {
+	case change > 0:
+		return "up"
+	case change < 0:
+		return "down"
+	default:
+		return ""
+	}
+}
+
+// signed prefixes an unsigned magnitude with the sign of value.
+func signed(magnitude string, value float64) string {
+	switch {
+	case value > 0:
+		return "+" + strings.TrimPrefix(magnitude, "-")
+	case value < 0:
+		return "−" + strings.TrimPrefix(magnitude, "-")
+	default:
+		return magnitude
+	}
+}
@@ -0,0 +1,156 @@
+package publisher
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func writeReportFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	write := func(name string, value any) {
+		t.Helper()
+		if err := writeJSON(filepath.Join(dir, name), value); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	block := func(trade float64) partnerBlock {
+		return partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Trade: trade}
+	}
+	write("meta.json", metaFile{GeneratedAt: "2026-01-01T00:00:00Z", Provider: "wits", ReporterCount: 3, DominantPeriod: "Y:2023"})
+	write("latest.json", latestFile{Rows: []latestEntry{
+		{ISO3: "KOR", Region: "East Asia & Pacific", USA: block(100), CHN: block(300), SamePeriod: true},
+		{ISO3: "VNM", Region: "East Asia & Pacific", USA: block(100), CHN: block(100), SamePeriod: true},
+		{ISO3: "MEX", Region: "Latin America & Caribbean", USA: block(900), CHN: block(100), SamePeriod: true},
+		{ISO3: "DEU", Region: "Europe & Central Asia", USA: block(1), CHN: partnerBlock{PeriodType: model.PeriodYear, Period: "2022", Trade: 1}},
+	}})
+	stale, fresh := 900, 100
+	write("coverage.json", coverageFile{Reporters: []reporterCoverage{
+		{ISO3: "KOR", DataAsOf: "Y:2023", MaxStalenessDays: &fresh, Providers: []string{"wits"}},
+		{ISO3: "MEX", DataAsOf: "Y:2023", MaxStalenessDays: &fresh, Providers: []string{"wits"}},
+		{ISO3: "DEU", Name: "Germany", DataAsOf: "Y:2022", MaxStalenessDays: &stale, Providers: []string{"comtrade", "wits"}},
+	}})
+	up, same := 2, 0
+	write("rankings.json", rankingsFile{PeriodType: "Y", Period: "2023", PrevPeriod: "2022", Rankings: []ranking{
+		{ID: "share_cn", Title: "Highest China share of USA+CHN trade", Rows: []rankingRow{
+			{Rank: 1, ISO3: "KOR", Name: "Korea | South", Value: 0.75, PrevRank: &up, RankDelta: &up},
+			{Rank: 2, ISO3: "VNM", Value: 0.5},
+		}},
+		{ID: "trade_growth", Title: "Fastest year-over-year USA+CHN trade growth", Rows: []rankingRow{
+			{Rank: 1, ISO3: "MEX", Value: -0.125, PrevRank: &same, RankDelta: &same},
+		}},
+	}})
+	write("movers.json", moversFile{Period: "2023", Windows: []moverWindow{{ID: "last_period", BasePeriod: "2022", Lists: []moverList{
+		{ID: "share_cn_abs", Title: "Largest swings in China share (percentage points)", Rows: []moverRow{
+			{Rank: 1, ISO3: "KOR", Name: "Korea <Rep.>", BaseValue: 0.6, Value: 0.75, Change: 0.15},
+			{Rank: 2, ISO3: "MEX", BaseValue: 0.15, Value: 0.1, Change: -0.05},
+		}},
+		{ID: "total_rel", Title: "Largest relative swings in USA+CHN trade", Rows: []moverRow{
+			{Rank: 1, ISO3: "VNM", BaseValue: 1.6e9, Value: 2e9, Change: 0.25},
+		}},
+	}}}})
+	return dir
+}
+
+func TestBuildReportSummarizesMoversCoverageAndRegions(t *testing.T) {
+	data, err := buildReport(writeReportFixture(t), 1)
+	if err != nil {
+		t.Fatalf("buildReport: %v", err)
+	}
+	if len(data.Movers) != 2 || data.Movers[0].Window != "2023 vs 2022" || len(data.Movers[0].Rows) != 1 {
+		t.Fatalf("movers = %+v, want two tables trimmed to one row", data.Movers)
+	}
+	if row := data.Movers[0].Rows[0]; row.Base != "60.0%" || row.Change != "+15.0 pp" || row.Direction != "up" {
+		t.Fatalf("share mover row = %+v", row)
+	}
+	if row := data.Movers[1].Rows[0]; row.Value != "$2.0B" || row.Change != "+25.0%" {
+		t.Fatalf("relative trade mover row = %+v", row)
+	}
+	if len(data.Coverage.Stalest) != 1 || data.Coverage.Stalest[0].ISO3 != "DEU" || data.Coverage.Stalest[0].Providers != "comtrade, wits" {
+		t.Fatalf("stalest = %+v, want DEU only", data.Coverage.Stalest)
+	}
+	if len(data.Coverage.Periods) != 2 || data.Coverage.Periods[0] != (reportPeriodCount{Period: "Y:2023", Reporters: 2}) {
+		t.Fatalf("periods = %+v", data.Coverage.Periods)
+	}
+	if len(data.Regions) != 2 {
+		t.Fatalf("regions = %+v, want DEU's mixed-period row left out", data.Regions)
+	}
+	asia, americas := data.Regions[0], data.Regions[1]
+	if asia.Region != "East Asia & Pacific" || asia.Reporters != 2 || asia.Tilt != "+0.333" || !asia.TowardCHN {
+		t.Fatalf("East Asia tilt = %+v, want (400-200)/600", asia)
+	}
+	if americas.Tilt != "−0.800" || americas.TowardCHN {
+		t.Fatalf("Latin America tilt = %+v", americas)
+	}
+}
+
+func TestBuildReportFormatsRankings(t *testing.T) {
+	data, err := buildReport(writeReportFixture(t), 10)
+	if err != nil {
+		t.Fatalf("buildReport: %v", err)
+	}
+	if len(data.Rankings) != 2 || data.Rankings[0].Period != "Y:2023 vs 2022" {
+		t.Fatalf("rankings = %+v", data.Rankings)
+	}
+	share := data.Rankings[0].Rows
+	if share[0].Value != "75.0%" || share[0].Move != "▲2" || share[1].Move != "new" {
+		t.Fatalf("share_cn rows = %+v", share)
+	}
+	if row := data.Rankings[1].Rows[0]; row.Value != "−12.5%" || row.Move != "=" {
+		t.Fatalf("trade_growth row = %+v", row)
+	}
+}
+
+func TestRenderMarkdownReportKeepsTablesIntact(t *testing.T) {
+	data, err := buildReport(writeReportFixture(t), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := renderMarkdownReport(data)
+	if err != nil {
+		t.Fatalf("renderMarkdownReport: %v", err)
+	}
+	page := string(body)
+	for _, want := range []string{
+		"## Rankings",
+		"| 1 | KOR · Korea \\| South | 75.0% | ▲2 |",
+		"| 1 | KOR · Korea <Rep.> | 60.0% | 75.0% | +15.0 pp |",
+		"| DEU · Germany | Y:2022 | 900 | comtrade, wits |",
+		"| East Asia & Pacific | 2 | $200 | $400 | +0.333 |",
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("markdown report is missing %q:\n%s", want, page)
+		}
+	}
+}
+
+func TestRenderHTMLReportEscapesNames(t *testing.T) {
+	data, err := buildReport(writeReportFixture(t), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := renderHTMLReport(data)
+	if err != nil {
+		t.Fatalf("renderHTMLReport: %v", err)
+	}
+	page := string(body)
+	for _, want := range []string{"<!doctype html>", "<style>", "Korea &lt;Rep.&gt;", "East Asia &amp; Pacific", "2023 vs 2022"} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("report is missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<Rep.>") {
+		t.Fatal("reporter names must be escaped")
+	}
+}
+
+func TestFormatUSDAbbreviates(t *testing.T) {
+	for value, want := range map[float64]string{1.5e12: "$1.5T", 2.25e9: "$2.2B", 3e6: "$3.0M", 999: "$999", -4e9: "$-4.0B"} {
+		if got := formatUSD(value); got != want {
+			t.Fatalf("formatUSD(%v) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package publisher
+
+import (
+	"database/sql"
+	"strings"
+
+	"tradegravity/internal/iso"
+	"tradegravity/internal/model"
+)
+
+// loadReporterNames reads the reporters table the collector maintains. A
+// database from before the table existed yields no names.
+func loadReporterNames(dbPath string) (map[string]model.Reporter, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	names := make(map[string]model.Reporter)
+	columns, err := sqliteTableColumns(db, "reporters")
+	if err != nil || len(columns) == 0 {
+		return names, err
+	}
+	classification := "subregion, income_group"
+	_, subregion := columns["subregion"]
+	_, incomeGroup := columns["income_group"]
+	if !subregion || !incomeGroup {
+		classification = "'' AS subregion, '' AS income_group"
+	}
+	rows, err := db.Query(`SELECT iso3, name_en, name_ko, region, ` + classification + ` FROM reporters`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var reporter model.Reporter
+		if err := rows.Scan(&reporter.ISO3, &reporter.NameEN, &reporter.NameKO, &reporter.Region, &reporter.Subregion, &reporter.IncomeGroup); err != nil {
+			return nil, err
+		}
+		names[strings.ToUpper(reporter.ISO3)] = reporter
+	}
+	return names, rows.Err()
+}
+
+// enrichReporterNames adds Korean names, and fills English names, regions,
+// subregions, and income groups that the country context did not supply.
+// Whatever the reporters table lacks comes from the bundled ISO 3166-1 names
+// and classification.
+func enrichReporterNames(rows []latestEntry, names map[string]model.Reporter) {
+	for index := range rows {
+		row := &rows[index]
+		if reporter, ok := names[row.ISO3]; ok {
+			if row.Name == "" && !strings.EqualFold(reporter.NameEN, reporter.ISO3) {
+				row.Name = reporter.NameEN
+			}
+			row.NameKO = reporter.NameKO
+			row.Region = firstNonEmpty(row.Region, reporter.Region)
+			row.Subregion = firstNonEmpty(row.Subregion, reporter.Subregion)
+			row.IncomeGroup = firstNonEmpty(row.IncomeGroup, reporter.IncomeGroup)
+		}
+		if country, ok := iso.Lookup(row.ISO3); ok {
+			row.Name = firstNonEmpty(row.Name, country.NameEN)
+			row.NameKO = firstNonEmpty(row.NameKO, country.NameKO)
+		}
+		if classification, ok := iso.Classify(row.ISO3); ok {
+			row.Region = firstNonEmpty(row.Region, classification.Region)
+			row.Subregion = firstNonEmpty(row.Subregion, classification.Subregion)
+			row.IncomeGroup = firstNonEmpty(row.IncomeGroup, classification.IncomeGroup)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestEnrichReporterNamesKeepsContextNames(t *testing.T) {
+	rows := []latestEntry{
+		{ISO3: "KOR", Name: "Korea", Region: "Asia"},
+		{ISO3: "JPN"},
+		{ISO3: "DEU"},
+		{ISO3: "XKX"},
+	}
+	enrichReporterNames(rows, map[string]model.Reporter{
+		"KOR": {ISO3: "KOR", NameEN: "Korea, Rep.", NameKO: "대한민국", Region: "East Asia & Pacific", Subregion: "Eastern Asia", IncomeGroup: "High income"},
+		"JPN": {ISO3: "JPN", NameEN: "Japan", NameKO: "일본", Region: "East Asia & Pacific"},
+	})
+
+	if rows[0].Name != "Korea" || rows[0].Region != "Asia" || rows[0].NameKO != "대한민국" {
+		t.Fatalf("KOR = %+v, want context name and region with Korean name", rows[0])
+	}
+	if rows[1].Name != "Japan" || rows[1].Region != "East Asia & Pacific" || rows[1].NameKO != "일본" {
+		t.Fatalf("JPN = %+v, want names and region from reporters", rows[1])
+	}
+	if rows[2].Name != "Germany" || rows[2].NameKO != "독일" {
+		t.Fatalf("DEU = %+v, want ISO 3166-1 names", rows[2])
+	}
+	if rows[0].Region != "Asia" || rows[0].Subregion != "Eastern Asia" || rows[0].IncomeGroup != "High income" {
+		t.Fatalf("KOR = %+v, want the context region with the stored subregion and income group", rows[0])
+	}
+	if rows[2].Region != "Europe & Central Asia" || rows[2].Subregion != "Western Europe" || rows[2].IncomeGroup != "High income" {
+		t.Fatalf("DEU = %+v, want the bundled classification", rows[2])
+	}
+	if rows[3].Name != "" || rows[3].NameKO != "" || rows[3].Region != "" {
+		t.Fatalf("XKX = %+v, want no names or classification outside ISO 3166-1", rows[3])
+	}
+}
@@ -0,0 +1,134 @@
+package publisher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output schemas accepted by -schema. schemaV2 is the current shape. schemaV1
+// writes meta.json and latest.json in the version 1 shape for deployments
+// whose frontend predates schema 2; every other artifact is unchanged.
+const (
+	schemaV1 = "v1"
+	schemaV2 = "v2"
+
+	schemaVersionV1 = "1.0"
+)
+
+func parseSchema(value string) (string, error) {
+	schema := strings.ToLower(strings.TrimSpace(value))
+	switch schema {
+	case schemaV1, schemaV2:
+		return schema, nil
+	default:
+		return "", fmt.Errorf("unsupported schema %q (expected v1 or v2)", value)
+	}
+}
+
+// metaFileV1 keeps the version 1 coverage fields. They have the same meaning
+// in schema 2.
+type metaFileV1 struct {
+	SchemaVersion          string         `json:"schema_version"`
+	GeneratedAt            string         `json:"generated_at"`
+	Provider               string         `json:"provider"`
+	Partners               []string       `json:"partners"`
+	ReporterCount          int            `json:"reporter_count"`
+	ObservationCount       int            `json:"observation_count"`
+	ExpectedPartnerBlocks  int            `json:"expected_partner_blocks"`
+	AvailablePartnerBlocks int            `json:"available_partner_blocks"`
+	MissingPartnerBlocks   int            `json:"missing_partner_blocks"`
+	PeriodCounts           map[string]int `json:"period_counts"`
+}
+
+type latestFileV1 struct {
+	SchemaVersion string          `json:"schema_version"`
+	GeneratedAt   string          `json:"generated_at"`
+	Provider      string          `json:"provider"`
+	Partners      []string        `json:"partners"`
+	Rows          []latestEntryV1 `json:"rows"`
+}
+
+// latestEntryV1 is a version 1 row: USA and CHN blocks with their total and
+// China share, without context, comparability, or tracked-partner fields.
+type latestEntryV1 struct {
+	ISO3    string         `json:"iso3"`
+	USA     partnerBlockV1 `json:"usa"`
+	CHN     partnerBlockV1 `json:"chn"`
+	Total   float64        `json:"total"`
+	ShareCN float64        `json:"share_cn"`
+}
+
+type partnerBlockV1 struct {
+	Period      string       `json:"period"`
+	PeriodType  string       `json:"period_type"`
+	Export      float64      `json:"export"`
+	Import      float64      `json:"import"`
+	Trade       float64      `json:"trade"`
+	Growth      *growthBlock `json:"growth,omitempty"`
+	GrowthBasis string       `json:"growth_basis,omitempty"`
+}
+
+// metaV1 projects schema 2 metadata onto the version 1 fields. Version 1
+// counted only the USA and CHN blocks, so coverage is recounted from the
+// projected rows.
+func metaV1(meta metaFile, latest latestFileV1) metaFileV1 {
+	output := metaFileV1{
+		SchemaVersion:         schemaVersionV1,
+		GeneratedAt:           meta.GeneratedAt,
+		Provider:              meta.Provider,
+		Partners:              append([]string(nil), latest.Partners...),
+		ReporterCount:         len(latest.Rows),
+		ObservationCount:      meta.ObservationCount,
+		ExpectedPartnerBlocks: len(latest.Rows) * len(latest.Partners),
+		PeriodCounts:          make(map[string]int),
+	}
+	for _, row := range latest.Rows {
+		for _, block := range []partnerBlockV1{row.USA, row.CHN} {
+			if block.Period == "" {
+				continue
+			}
+			output.AvailablePartnerBlocks++
+			output.PeriodCounts[block.PeriodType+":"+block.Period]++
+		}
+	}
+	output.MissingPartnerBlocks = output.ExpectedPartnerBlocks - output.AvailablePartnerBlocks
+	return output
+}
+
+func latestV1(latest latestFile) latestFileV1 {
+	output := latestFileV1{
+		SchemaVersion: schemaVersionV1,
+		GeneratedAt:   latest.GeneratedAt,
+		Provider:      latest.Provider,
+		Partners:      []string{"USA", "CHN"},
+		Rows:          make([]latestEntryV1, 0, len(latest.Rows)),
+	}
+	for _, row := range latest.Rows {
+		output.Rows = append(output.Rows, latestEntryV1{
+			ISO3:    row.ISO3,
+			USA:     blockV1(row.USA),
+			CHN:     blockV1(row.CHN),
+			Total:   row.Total,
+			ShareCN: row.ShareCN,
+		})
+	}
+	return output
+}
+
+// blockV1 keeps growth only when it is year over year, the only basis
+// version 1 had.
+func blockV1(block partnerBlock) partnerBlockV1 {
+	growth, basis := block.Growth, block.GrowthBasis
+	if basis != growthYoY {
+		growth, basis = nil, ""
+	}
+	return partnerBlockV1{
+		Period:      block.Period,
+		PeriodType:  string(block.PeriodType),
+		Export:      block.Export,
+		Import:      block.Import,
+		Trade:       block.Trade,
+		Growth:      growth,
+		GrowthBasis: basis,
+	}
+}
@@ -0,0 +1,54 @@
+package publisher
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestLatestV1DropsSchemaTwoFields(t *testing.T) {
+	growth := 0.1
+	latest := latestFile{
+		SchemaVersion: schemaVersion,
+		Provider:      "wits",
+		Partners:      []string{"USA", "CHN", "JPN"},
+		Rows: []latestEntry{{
+			ISO3: "KOR", Name: "Korea", SamePeriod: true, ComparisonPeriod: "2023",
+			USA:      partnerBlock{PeriodType: model.PeriodYear, Period: "2023", Export: 1, Trade: 1, Growth: &growthBlock{Trade: &growth}, GrowthBasis: growthYoY},
+			CHN:      partnerBlock{PeriodType: model.PeriodMonth, Period: "2024-05", Import: 3, Trade: 3, Growth: &growthBlock{Trade: &growth}, GrowthBasis: growthMoM},
+			Partners: map[string]partnerBlock{"JPN": {PeriodType: model.PeriodYear, Period: "2023", Trade: 2}},
+			Total:    4, ShareCN: 0.75,
+		}},
+	}
+
+	output := latestV1(latest)
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"name"`, `"same_period"`, `"partners":{`, `"shares"`} {
+		if strings.Contains(string(encoded), field) {
+			t.Fatalf("v1 latest contains %s: %s", field, encoded)
+		}
+	}
+	row := output.Rows[0]
+	if output.SchemaVersion != "1.0" || row.USA.Growth == nil || row.CHN.Growth != nil || row.ShareCN != 0.75 {
+		t.Fatalf("unexpected v1 row: %+v", row)
+	}
+
+	meta := metaV1(metaFile{ObservationCount: 9}, output)
+	if meta.ExpectedPartnerBlocks != 2 || meta.AvailablePartnerBlocks != 2 || meta.PeriodCounts["M:2024-05"] != 1 || len(meta.Partners) != 2 {
+		t.Fatalf("unexpected v1 meta: %+v", meta)
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	if schema, err := parseSchema(" V1 "); err != nil || schema != schemaV1 {
+		t.Fatalf("parseSchema() = %q, %v", schema, err)
+	}
+	if _, err := parseSchema("v3"); err == nil {
+		t.Fatal("parseSchema() accepted v3")
+	}
+}
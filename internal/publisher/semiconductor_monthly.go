@@ -1,4 +1,4 @@
-package main
+package publisher
 
 import (
 	"sort"
@@ -78,7 +78,7 @@ func buildSemiconductorMonthlyFiles(generatedAt, provider string, partners []str
 		}
 		reporter := strings.ToUpper(strings.TrimSpace(row.ReporterISO))
 		partner := strings.ToUpper(strings.TrimSpace(row.PartnerISO))
-		if !isPublishedISO3(reporter) || (partner != "USA" && partner != "CHN") || len(row.Period) != 7 || row.ValueUSD < 0 {
+		if !isPublishedISO3(reporter) || (partner != "USA" && partner != "CHN") || len(row.Period) != 7 || row.Value < 0 {
 			continue
 		}
 		classification := strings.ToUpper(strings.TrimSpace(row.Classification))
@@ -99,13 +99,10 @@ func buildSemiconductorMonthlyFiles(generatedAt, provider string, partners []str
 			block = &entry.CHN
 		}
 		block.Available = true
-		if row.Flow == model.FlowExport {
-			block.Export += row.ValueUSD
-		} else if row.Flow == model.FlowImport {
-			block.Import += row.ValueUSD
-		} else {
+		if row.Flow != model.FlowExport && row.Flow != model.FlowImport {
 			continue
 		}
+		block.add(row.Flow, row.Value)
 		periodSet[row.Period] = struct{}{}
 		index.ObservationCount++
 	}
@@ -120,9 +117,7 @@ func buildSemiconductorMonthlyFiles(generatedAt, provider string, partners []str
 		file := semiconductorMonthlyFile{SchemaVersion: schemaVersion, GeneratedAt: generatedAt, Provider: index.Provider, Level: 6, Partners: append([]string(nil), partners...), ReporterISO3: reporter, Periods: []string{}, Rows: []semiconductorMonthlyProductEntry{}}
 		filePeriods := make(map[string]struct{})
 		for _, entry := range grouped[reporter] {
-			entry.USA.Trade = entry.USA.Export + entry.USA.Import
-			entry.CHN.Trade = entry.CHN.Export + entry.CHN.Import
-			entry.Total = entry.USA.Trade + entry.CHN.Trade
+			entry.Total = settleBlocks(&entry.USA, &entry.CHN)
 			if entry.Total > 0 {
 				entry.ShareCN = entry.CHN.Trade / entry.Total
 			}
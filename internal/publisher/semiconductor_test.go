@@ -1,4 +1,4 @@
-package main
+package publisher
 
 import (
 	"fmt"
@@ -52,12 +52,12 @@ func TestBuildSemiconductorMonthlyFilesKeepsAnchorFlowsAndFiltersTheRegistry(t *
 	reference := semiconductor.Reference{Stages: []semiconductor.Stage{{ID: "memory", Codes: []string{"854232"}}}}
 	products := []strategic.Product{{Code: "854232", Label: "Memories"}}
 	rows := []observationRow{
-		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodMonth, Period: "2026-05", ValueUSD: 60},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodMonth, Period: "2026-05", ValueUSD: 40},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodMonth, Period: "2026-05", ValueUSD: 30},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodMonth, Period: "2026-05", ValueUSD: 70},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2026", ValueUSD: 999},
-		{Provider: "comtrade", Classification: "H6", ProductCode: "999999", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodMonth, Period: "2026-05", ValueUSD: 999},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodMonth, Period: "2026-05", Value: 6000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowImport, PeriodType: model.PeriodMonth, Period: "2026-05", Value: 4000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodMonth, Period: "2026-05", Value: 3000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodMonth, Period: "2026-05", Value: 7000},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "854232", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2026", Value: 99900},
+		{Provider: "comtrade", Classification: "H6", ProductCode: "999999", ProductLevel: 6, ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodMonth, Period: "2026-05", Value: 99900},
 	}
 
 	index, files := buildSemiconductorMonthlyFiles("2026-07-16T00:00:00Z", "comtrade", []string{"USA", "CHN"}, rows, products, reference)
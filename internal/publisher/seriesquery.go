@@ -0,0 +1,387 @@
+package publisher
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tradegravity/internal/model"
+)
+
+// SeriesPath is the filtered observation endpoint served by SeriesHandler.
+const SeriesPath = "/v1/series"
+
+const (
+	defaultSeriesLimit = 500
+	maxSeriesLimit     = 5000
+)
+
+var seriesCodePattern = regexp.MustCompile(`^[A-Z0-9]{3}$`)
+
+// ErrInvalidSeriesQuery wraps the errors QuerySeries returns for a
+// malformed query, as opposed to a store that could not be read.
+var ErrInvalidSeriesQuery = errors.New("invalid series query")
+
+// SeriesQuery selects stored total-trade observations. Empty fields do not
+// filter. From and To are inclusive bounds such as 2015, 2015-Q1, or
+// 2015-03, and select the periods that lie wholly between them. Limit is the
+// page size, defaultSeriesLimit when zero, and Cursor is the NextCursor of
+// the previous page.
+type SeriesQuery struct {
+	Provider   string
+	Reporters  []string
+	Partners   []string
+	Flow       model.Flow
+	PeriodType model.PeriodType
+	From       string
+	To         string
+	Limit      int
+	Cursor     string
+}
+
+// seriesBounds holds the months From and To cover, counted from year zero.
+// A bound that is not set leaves its side open.
+type seriesBounds struct {
+	first, last       int
+	hasFirst, hasLast bool
+}
+
+// boundMonths returns the first and last month a period bound covers.
+func boundMonths(bound string) (int, int, bool) {
+	if year, ok := model.ParseYear(bound); ok {
+		return year * 12, year*12 + 11, true
+	}
+	if year, quarter, ok := model.ParseYearQuarter(bound); ok {
+		first := year*12 + (quarter-1)*3
+		return first, first + 2, true
+	}
+	if year, month, ok := model.ParseYearMonth(bound); ok {
+		return year*12 + month - 1, year*12 + month - 1, true
+	}
+	return 0, 0, false
+}
+
+// periodLimits returns the first and last period of periodType that lie
+// wholly inside the bounds, as strings that sort like the stored periods.
+// An open side is returned empty.
+func (b seriesBounds) periodLimits(periodType model.PeriodType) (string, string) {
+	// Each period type counts from year zero in its own unit.
+	months := map[model.PeriodType]int{model.PeriodYear: 12, model.PeriodQuarter: 3, model.PeriodMonth: 1}[periodType]
+	format := func(index int) string {
+		switch periodType {
+		case model.PeriodYear:
+			return fmt.Sprintf("%04d", index)
+		case model.PeriodQuarter:
+			return fmt.Sprintf("%04d-Q%d", index/4, index%4+1)
+		default:
+			return fmt.Sprintf("%04d-%02d", index/12, index%12+1)
+		}
+	}
+	var from, to string
+	if b.hasFirst {
+		from = format((b.first + months - 1) / months)
+	}
+	if b.hasLast {
+		to = format((b.last+1)/months - 1)
+	}
+	return from, to
+}
+
+// seriesCursor is the key of the last row of a page. Rows are ordered by
+// it, so the next page starts after it however the store changes between
+// requests.
+type seriesCursor struct {
+	Reporter   string
+	Partner    string
+	PeriodType string
+	Period     string
+	Flow       string
+}
+
+func (c seriesCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join([]string{c.Reporter, c.Partner, c.PeriodType, c.Period, c.Flow}, "|")))
+}
+
+func parseSeriesCursor(value string) (*seriesCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	parts := strings.Split(string(decoded), "|")
+	if err != nil || len(parts) != 5 {
+		return nil, fmt.Errorf("%w: invalid cursor %q", ErrInvalidSeriesQuery, value)
+	}
+	return &seriesCursor{Reporter: parts[0], Partner: parts[1], PeriodType: parts[2], Period: parts[3], Flow: parts[4]}, nil
+}
+
+// SeriesPage is one page of stored observations, as /v1/series returns it.
+type SeriesPage struct {
+	GeneratedAt string              `json:"generated_at"`
+	Provider    string              `json:"provider"`
+	Rows        []SeriesObservation `json:"rows"`
+	NextCursor  string              `json:"next_cursor,omitempty"`
+}
+
+// SeriesObservation is one stored total-trade observation. Value is the
+// exact stored amount; JSON carries it as ValueUSD.
+type SeriesObservation struct {
+	ReporterISO3 string           `json:"reporter_iso3"`
+	PartnerISO3  string           `json:"partner_iso3"`
+	Flow         model.Flow       `json:"flow"`
+	PeriodType   model.PeriodType `json:"period_type"`
+	Period       string           `json:"period"`
+	Value        model.Cents      `json:"-"`
+	ValueUSD     float64          `json:"value_usd"`
+	Estimated    bool             `json:"estimated,omitempty"`
+	QualityNote  string           `json:"quality_note,omitempty"`
+}
+
+// ParseSeriesQuery reads the /v1/series parameters. reporter and partner
+// take comma-separated ISO3 codes; QuerySeries checks the values.
+func ParseSeriesQuery(values url.Values) (SeriesQuery, error) {
+	get := func(key string) string {
+		return strings.TrimSpace(values.Get(key))
+	}
+	list := func(key string) []string {
+		if value := get(key); value != "" {
+			return strings.Split(value, ",")
+		}
+		return nil
+	}
+	query := SeriesQuery{
+		Provider:   get("provider"),
+		Reporters:  list("reporter"),
+		Partners:   list("partner"),
+		Flow:       model.Flow(get("flow")),
+		PeriodType: model.PeriodType(get("period_type")),
+		From:       get("from"),
+		To:         get("to"),
+		Cursor:     get("cursor"),
+	}
+	if limit := get("limit"); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			return SeriesQuery{}, fmt.Errorf("%w: invalid limit %q (expected 1 to %d)", ErrInvalidSeriesQuery, limit, maxSeriesLimit)
+		}
+	}
+	return query, nil
+}
+
+// normalize upper-cases codes, fills defaults, and checks every field, so
+// callers can pass their parameters through unchanged.
+func (q SeriesQuery) normalize() (SeriesQuery, seriesBounds, *seriesCursor, error) {
+	codes := func(name string, values []string) ([]string, error) {
+		var result []string
+		for _, code := range values {
+			if code = strings.ToUpper(strings.TrimSpace(code)); code == "" {
+				continue
+			}
+			if !seriesCodePattern.MatchString(code) {
+				return nil, fmt.Errorf("%w: invalid %s %q (expected ISO3 codes such as USA,CHN)", ErrInvalidSeriesQuery, name, code)
+			}
+			result = append(result, code)
+		}
+		return result, nil
+	}
+	q.Provider = strings.ToLower(strings.TrimSpace(q.Provider))
+	if q.Provider == "" {
+		q.Provider = "wits"
+	}
+	var err error
+	if q.Reporters, err = codes("reporter", q.Reporters); err != nil {
+		return SeriesQuery{}, seriesBounds{}, nil, err
+	}
+	if q.Partners, err = codes("partner", q.Partners); err != nil {
+		return SeriesQuery{}, seriesBounds{}, nil, err
+	}
+	q.Flow = model.Flow(strings.ToLower(strings.TrimSpace(string(q.Flow))))
+	switch q.Flow {
+	case "", model.FlowExport, model.FlowImport, model.FlowTotal:
+	default:
+		return SeriesQuery{}, seriesBounds{}, nil, fmt.Errorf("%w: unsupported flow %q (expected export, import, or total)", ErrInvalidSeriesQuery, q.Flow)
+	}
+	q.PeriodType = model.PeriodType(strings.ToUpper(strings.TrimSpace(string(q.PeriodType))))
+	switch q.PeriodType {
+	case "", model.PeriodYear, model.PeriodQuarter, model.PeriodMonth:
+	default:
+		return SeriesQuery{}, seriesBounds{}, nil, fmt.Errorf("%w: unsupported period_type %q (expected Y, Q, or M)", ErrInvalidSeriesQuery, q.PeriodType)
+	}
+	var bounds seriesBounds
+	q.From, q.To = strings.ToUpper(strings.TrimSpace(q.From)), strings.ToUpper(strings.TrimSpace(q.To))
+	for _, bound := range []string{q.From, q.To} {
+		if _, _, ok := boundMonths(bound); bound != "" && !ok {
+			return SeriesQuery{}, seriesBounds{}, nil, fmt.Errorf("%w: invalid period bound %q (expected 2024, 2024-Q1, or 2024-03)", ErrInvalidSeriesQuery, bound)
+		}
+	}
+	if q.From != "" {
+		bounds.first, _, bounds.hasFirst = boundMonths(q.From)
+	}
+	if q.To != "" {
+		_, bounds.last, bounds.hasLast = boundMonths(q.To)
+	}
+	if bounds.hasFirst && bounds.hasLast && bounds.first > bounds.last {
+		return SeriesQuery{}, seriesBounds{}, nil, fmt.Errorf("%w: from %s is after to %s", ErrInvalidSeriesQuery, q.From, q.To)
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultSeriesLimit
+	}
+	if q.Limit < 0 || q.Limit > maxSeriesLimit {
+		return SeriesQuery{}, seriesBounds{}, nil, fmt.Errorf("%w: invalid limit %d (expected 1 to %d)", ErrInvalidSeriesQuery, q.Limit, maxSeriesLimit)
+	}
+	var after *seriesCursor
+	if q.Cursor = strings.TrimSpace(q.Cursor); q.Cursor != "" {
+		if after, err = parseSeriesCursor(q.Cursor); err != nil {
+			return SeriesQuery{}, seriesBounds{}, nil, err
+		}
+	}
+	return q, bounds, after, nil
+}
+
+// SeriesHandler returns stored total-trade observations one page at a time,
+// so clients can read the part of a history they need rather than
+// history.json in full. The parameters are those of SeriesQuery, with
+// limit and cursor for Limit and Cursor. observeQuery, when set, receives
+// how long the store query took.
+func SeriesHandler(dbPath string, observeQuery func(name string, elapsed time.Duration)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		query, err := ParseSeriesQuery(r.URL.Query())
+		var page SeriesPage
+		if err == nil {
+			page, err = QuerySeries(r.Context(), dbPath, query, observeQuery)
+		}
+		if errors.Is(err, ErrInvalidSeriesQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "failed to load observations", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(page)
+	})
+}
+
+// QuerySeries returns one page of the stored total-trade observations query
+// selects, ordered by reporter, partner, period type, period, and flow. A
+// page that is not the last carries NextCursor, which the next query passes
+// as Cursor with the same filters. Errors for a malformed query wrap
+// ErrInvalidSeriesQuery. observeQuery, when set, receives how long the store
+// query took.
+func QuerySeries(ctx context.Context, dbPath string, query SeriesQuery, observeQuery func(name string, elapsed time.Duration)) (SeriesPage, error) {
+	query, bounds, after, err := query.normalize()
+	if err != nil {
+		return SeriesPage{}, err
+	}
+	start := time.Now()
+	rows, err := loadSeriesPage(ctx, dbPath, query, bounds, after)
+	if observeQuery != nil {
+		observeQuery("series_observations", time.Since(start))
+	}
+	if err != nil {
+		return SeriesPage{}, err
+	}
+	page := SeriesPage{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Provider:    query.Provider,
+		Rows:        make([]SeriesObservation, 0, min(len(rows), query.Limit)),
+	}
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = seriesCursor{
+			Reporter: last.ReporterISO, Partner: last.PartnerISO,
+			PeriodType: string(last.PeriodType), Period: last.Period, Flow: string(last.Flow),
+		}.String()
+	}
+	for _, row := range rows {
+		page.Rows = append(page.Rows, SeriesObservation{
+			ReporterISO3: row.ReporterISO, PartnerISO3: row.PartnerISO, Flow: row.Flow,
+			PeriodType: row.PeriodType, Period: row.Period, Value: row.Value, ValueUSD: row.Value.USD(),
+			Estimated: row.Estimated, QualityNote: row.QualityNote,
+		})
+	}
+	return page, nil
+}
+
+// loadSeriesPage reads up to query.Limit+1 rows after after, so QuerySeries
+// can tell whether another page follows.
+func loadSeriesPage(ctx context.Context, dbPath string, query SeriesQuery, bounds seriesBounds, after *seriesCursor) ([]observationRow, error) {
+	if strings.TrimSpace(dbPath) == "" {
+		return nil, errors.New("db path is required")
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	quality, err := observationQualityColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	value, err := observationValueColumn(db)
+	if err != nil {
+		return nil, err
+	}
+	filter, args := totalsFilter(query.Provider, query.Partners, query.Reporters)
+	if query.Flow != "" {
+		filter += " AND flow = ?"
+		args = append(args, string(query.Flow))
+	}
+	if query.PeriodType != "" {
+		filter += " AND period_type = ?"
+		args = append(args, string(query.PeriodType))
+	}
+	if bounds.hasFirst || bounds.hasLast {
+		// Periods of each type sort as strings, so the bounds become a
+		// range per type: to=2024-Q1 keeps 2024-03 but not 2024 or 2024-06.
+		var ranges []string
+		for _, periodType := range []model.PeriodType{model.PeriodYear, model.PeriodQuarter, model.PeriodMonth} {
+			if query.PeriodType != "" && query.PeriodType != periodType {
+				continue
+			}
+			condition := "period_type = ?"
+			args = append(args, string(periodType))
+			from, to := bounds.periodLimits(periodType)
+			if from != "" {
+				condition += " AND period >= ?"
+				args = append(args, from)
+			}
+			if to != "" {
+				condition += " AND period <= ?"
+				args = append(args, to)
+			}
+			ranges = append(ranges, "("+condition+")")
+		}
+		filter += " AND (" + strings.Join(ranges, " OR ") + ")"
+	}
+	if after != nil {
+		filter += " AND (reporter_iso3, partner_iso3, period_type, period, flow) > (?, ?, ?, ?, ?)"
+		args = append(args, after.Reporter, after.Partner, after.PeriodType, after.Period, after.Flow)
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT provider, reporter_iso3, partner_iso3, flow, period_type, period, `+value+`, `+quality+`
+		FROM trade_observations
+		WHERE `+filter+`
+		ORDER BY reporter_iso3, partner_iso3, period_type, period, flow
+		LIMIT ?`, append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanObservations(rows)
+}
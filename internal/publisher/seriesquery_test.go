@@ -0,0 +1,142 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/store/sqlite"
+)
+
+func TestSeriesHandlerFiltersAndPages(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tradegravity.db")
+	st, err := sqlite.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var observations []model.Observation
+	add := func(reporter, partner string, flow model.Flow, periodType model.PeriodType, value float64, periods ...string) {
+		for _, period := range periods {
+			observations = append(observations, model.Observation{
+				Provider: "wits", ReporterISO3: reporter, PartnerISO3: partner, Flow: flow,
+				PeriodType: periodType, Period: period, ValueUSD: value,
+			})
+		}
+	}
+	for _, reporter := range []string{"KOR", "JPN"} {
+		for _, partner := range []string{"USA", "CHN"} {
+			for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+				add(reporter, partner, flow, model.PeriodYear, 10.25, "2014", "2015", "2016")
+				add(reporter, partner, flow, model.PeriodMonth, 1, "2016-01", "2016-02", "2017-01")
+			}
+		}
+	}
+	add("DEU", "USA", model.FlowExport, model.PeriodYear, 1, "2024")
+	add("DEU", "USA", model.FlowExport, model.PeriodQuarter, 1, "2024-Q1", "2024-Q2")
+	add("DEU", "USA", model.FlowExport, model.PeriodMonth, 1, "2024-02", "2024-03", "2024-06", "2024-12")
+	add("VNM", "USA", model.FlowTotal, model.PeriodYear, 1, "2024")
+	if err := st.UpsertObservations(context.Background(), observations); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	handler := SeriesHandler(dbPath, nil)
+	query := func(values url.Values) (int, SeriesPage) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, SeriesPath+"?"+values.Encode(), nil))
+		var response SeriesPage
+		if recorder.Code == http.StatusOK {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode %v: %v", values, err)
+			}
+		}
+		return recorder.Code, response
+	}
+	periods := func(page SeriesPage) []string {
+		var result []string
+		for _, row := range page.Rows {
+			result = append(result, row.Period)
+		}
+		return result
+	}
+
+	code, annual := query(url.Values{"from": {"2015"}, "to": {"2016"}, "flow": {"export"}, "partner": {"CHN"}, "period_type": {"Y"}})
+	if code != http.StatusOK || len(annual.Rows) != 4 || annual.NextCursor != "" {
+		t.Fatalf("annual CHN exports = %d %+v", code, annual)
+	}
+	if first := annual.Rows[0]; first.ReporterISO3 != "JPN" || first.PartnerISO3 != "CHN" || first.Period != "2015" || first.ValueUSD != 10.25 {
+		t.Fatalf("first row = %+v", first)
+	}
+
+	code, monthly := query(url.Values{"reporter": {"kor"}, "partner": {"USA"}, "flow": {"import"}, "to": {"2016"}, "period_type": {"M"}})
+	if code != http.StatusOK || len(monthly.Rows) != 2 || monthly.Rows[1].Period != "2016-02" {
+		t.Fatalf("a year bound must cover its months: %d %+v", code, monthly)
+	}
+
+	code, total := query(url.Values{"flow": {"total"}})
+	if code != http.StatusOK || len(total.Rows) != 1 || total.Rows[0].ReporterISO3 != "VNM" || total.Rows[0].Flow != model.FlowTotal {
+		t.Fatalf("total flow = %d %+v", code, total)
+	}
+
+	// Bounds of one granularity select the periods of every type that lie
+	// wholly inside them. Rows order Y after Q after M.
+	for _, test := range []struct {
+		from, to string
+		want     string
+	}{
+		{"", "2024-Q1", "[2024-02 2024-03 2024-Q1]"},
+		{"", "2024-03", "[2024-02 2024-03 2024-Q1]"},
+		{"2024-Q2", "", "[2024-06 2024-12 2024-Q2]"},
+		{"2024-03", "2024-06", "[2024-03 2024-06 2024-Q2]"},
+		{"2024", "2024", "[2024-02 2024-03 2024-06 2024-12 2024-Q1 2024-Q2 2024]"},
+		{"2024-02", "2024-Q4", "[2024-02 2024-03 2024-06 2024-12 2024-Q2]"},
+	} {
+		code, page := query(url.Values{"reporter": {"DEU"}, "from": {test.from}, "to": {test.to}})
+		if got := fmt.Sprint(periods(page)); code != http.StatusOK || got != test.want {
+			t.Fatalf("from=%s to=%s: %d %s, want %s", test.from, test.to, code, got, test.want)
+		}
+	}
+
+	var paged []SeriesObservation
+	values := url.Values{"reporter": {"KOR"}, "limit": {"5"}}
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("pagination did not end")
+		}
+		code, page := query(values)
+		if code != http.StatusOK || len(page.Rows) > 5 {
+			t.Fatalf("page %d = %d %+v", pages, code, page)
+		}
+		paged = append(paged, page.Rows...)
+		if page.NextCursor == "" {
+			break
+		}
+		values.Set("cursor", page.NextCursor)
+	}
+	if len(paged) != 24 {
+		t.Fatalf("paged %d rows, want all 24 of KOR's", len(paged))
+	}
+	seen := make(map[SeriesObservation]bool)
+	for _, row := range paged {
+		if seen[row] {
+			t.Fatalf("row %+v returned twice", row)
+		}
+		seen[row] = true
+	}
+
+	for _, bad := range []url.Values{
+		{"flow": {"reexport"}}, {"period_type": {"W"}}, {"from": {"2016"}, "to": {"2015"}},
+		{"from": {"2024-06"}, "to": {"2024-02"}}, {"from": {"2024-Q3"}, "to": {"2024-06"}}, {"from": {"2024-07"}, "to": {"2024-Q2"}},
+		{"from": {"2024-13"}}, {"limit": {"0"}}, {"limit": {"5001"}}, {"cursor": {"!"}}, {"partner": {"U.S."}},
+	} {
+		if code, _ := query(bad); code != http.StatusBadRequest {
+			t.Fatalf("%v: status = %d, want 400", bad, code)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package publisher
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"tradegravity/internal/config"
+	"tradegravity/internal/server"
+)
+
+// Serve builds the data into -out, then serves it for local frontend preview.
+// Arguments after "--" are passed to every build, and POST /_rebuild runs the
+// build again without restarting the server. With -viewer, the embedded
+// viewer is served on / and the data, with /_rebuild, moves under /data/.
+func Serve(program string, args []string) {
+	fs := flag.NewFlagSet("publish serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "listen address")
+	outDir := fs.String("out", "site/data", "output directory to build and serve")
+	skipBuild := fs.Bool("skip-build", false, "serve the existing -out without an initial build")
+	viewer := fs.Bool("viewer", false, "also serve the embedded viewer on /, with the data under /data/")
+	config.Parse(fs, args)
+
+	rebuild := buildCommand(program, append([]string{"-out", *outDir}, fs.Args()...))
+	if !*skipBuild {
+		output, err := rebuild()
+		os.Stderr.Write(output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "initial build failed:", err)
+			os.Exit(1)
+		}
+	}
+
+	handler, err := server.Preview(*outDir, func() ([]byte, error) {
+		output, err := rebuild()
+		os.Stderr.Write(output)
+		return output, err
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "serve failed:", err)
+		os.Exit(1)
+	}
+	rebuildPath := server.RebuildPath
+	if *viewer {
+		if handler, err = server.WithSite(handler, server.EmbeddedSite); err != nil {
+			fmt.Fprintln(os.Stderr, "serve failed:", err)
+			os.Exit(1)
+		}
+		rebuildPath = server.DataPrefix + strings.TrimPrefix(rebuildPath, "/")
+	}
+	srv := &http.Server{Addr: *addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	fmt.Fprintf(os.Stderr, "serving %s on http://%s (POST %s to rebuild)\n", *outDir, *addr, rebuildPath)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, "serve failed:", err)
+		os.Exit(1)
+	}
+}
+
+// buildCommand runs "build" in a child process of the current executable,
+// because Build exits the process on failure. program supplies any parent
+// subcommand, such as "publish" in "tradegravity publish".
+func buildCommand(program string, args []string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		executable, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		commandArgs := append(strings.Fields(program)[1:], "build")
+		commandArgs = append(commandArgs, args...)
+		return exec.Command(executable, commandArgs...).CombinedOutput()
+	}
+}
@@ -0,0 +1,103 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"tradegravity/internal/sheets"
+)
+
+var spreadsheetIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{20,}$`)
+
+// parseSpreadsheetID accepts a spreadsheet ID or the URL of the
+// spreadsheet, as copied from the browser.
+func parseSpreadsheetID(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if _, rest, ok := strings.Cut(value, "/spreadsheets/d/"); ok {
+		value, _, _ = strings.Cut(rest, "/")
+	}
+	if !spreadsheetIDPattern.MatchString(value) {
+		return "", fmt.Errorf("%q is not a spreadsheet ID or URL", value)
+	}
+	return value, nil
+}
+
+// publishSheets replaces the latest and rankings sheets of the spreadsheet
+// with the tables of the build in outDir, and returns how many sheets it
+// wrote.
+func publishSheets(ctx context.Context, outDir, spreadsheetID string) (int, error) {
+	cfg, err := sheets.ConfigFromEnv()
+	if err != nil {
+		return 0, err
+	}
+	client, err := sheets.New(cfg)
+	if err != nil {
+		return 0, err
+	}
+	tables, err := sheetTables(outDir)
+	if err != nil {
+		return 0, err
+	}
+	for _, table := range tables {
+		if err := client.ReplaceSheet(ctx, spreadsheetID, table.Name, sheetRows(table)); err != nil {
+			return 0, fmt.Errorf("write %s sheet: %w", table.Name, err)
+		}
+	}
+	return len(tables), nil
+}
+
+// sheetTables reads the latest and rankings tables from the published JSON.
+// rankings.json is optional, as it is in the build.
+func sheetTables(outDir string) ([]exportTable, error) {
+	var latest latestFile
+	if err := readPublished(outDir, "latest.json", &latest); err != nil {
+		return nil, err
+	}
+	tables := []exportTable{latestTable(latest.Rows)}
+	var rankings rankingsFile
+	if err := readPublished(outDir, "rankings.json", &rankings); err == nil {
+		tables = append(tables, rankingsTable(rankings))
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// sheetRows is the table as sheet rows, header first. Numbers and booleans
+// stay typed so the sheet can sort and sum them; missing values are empty
+// cells.
+func sheetRows(table exportTable) [][]any {
+	rows := make([][]any, 0, len(table.Rows)+1)
+	header := make([]any, len(table.Columns))
+	for index, column := range table.Columns {
+		header[index] = column
+	}
+	rows = append(rows, header)
+	for _, row := range table.Rows {
+		cells := make([]any, len(row))
+		for index, cell := range row {
+			switch value := cell.(type) {
+			case nil:
+				cells[index] = ""
+			case *float64:
+				cells[index] = ""
+				if value != nil {
+					cells[index] = *value
+				}
+			case *int:
+				cells[index] = ""
+				if value != nil {
+					cells[index] = *value
+				}
+			default:
+				cells[index] = value
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
@@ -0,0 +1,69 @@
+package publisher
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSheetTablesReadLatestAndRankings(t *testing.T) {
+	dir := t.TempDir()
+	growth := 0.25
+	latest := latestFile{SchemaVersion: schemaVersion, Rows: []latestEntry{{
+		ISO3: "KOR", Name: "Korea",
+		USA: partnerBlock{Period: "2024", PeriodType: "Y", Trade: 10.005, Growth: &growthBlock{Trade: &growth}},
+		CHN: partnerBlock{Period: "2024", PeriodType: "Y", Trade: 30},
+	}}}
+	if err := writeJSON(filepath.Join(dir, "latest.json"), latest); err != nil {
+		t.Fatal(err)
+	}
+
+	tables, err := sheetTables(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0].Name != "latest" {
+		t.Fatalf("tables without rankings.json = %+v", tables)
+	}
+	rows := sheetRows(tables[0])
+	if len(rows) != 2 || rows[0][0] != "iso3" || rows[1][0] != "KOR" {
+		t.Fatalf("latest rows = %v", rows)
+	}
+	columns := tables[0].Columns
+	for index, column := range columns {
+		switch column {
+		case "usa_trade":
+			if rows[1][index] != 10.01 {
+				t.Fatalf("usa_trade = %v, want whole cents as a number", rows[1][index])
+			}
+		case "usa_trade_growth":
+			if rows[1][index] != 0.25 {
+				t.Fatalf("usa_trade_growth = %v", rows[1][index])
+			}
+		case "chn_trade_growth":
+			if rows[1][index] != "" {
+				t.Fatalf("chn_trade_growth = %v, want an empty cell", rows[1][index])
+			}
+		}
+	}
+
+	rankings := rankingsFile{PeriodType: "Y", Period: "2024", Rankings: []ranking{{ID: "share_cn", Rows: []rankingRow{{Rank: 1, ISO3: "KOR", Value: 0.75}}}}}
+	if err := writeJSON(filepath.Join(dir, "rankings.json"), rankings); err != nil {
+		t.Fatal(err)
+	}
+	tables, err = sheetTables(dir)
+	if err != nil || len(tables) != 2 || tables[1].Name != "rankings" || len(sheetRows(tables[1])) != 2 {
+		t.Fatalf("tables = %+v, %v", tables, err)
+	}
+}
+
+func TestParseSpreadsheetIDAcceptsIDsAndURLs(t *testing.T) {
+	const id = "1AbCdEfGhIjKlMnOpQrStUvWxYz_0123456789-ab"
+	for _, value := range []string{id, " " + id + " ", "https://docs.google.com/spreadsheets/d/" + id + "/edit#gid=0"} {
+		if got, err := parseSpreadsheetID(value); err != nil || got != id {
+			t.Fatalf("parseSpreadsheetID(%q) = %q, %v", value, got, err)
+		}
+	}
+	if _, err := parseSpreadsheetID("not a sheet"); err == nil {
+		t.Fatal("parseSpreadsheetID accepted an invalid ID")
+	}
+}
@@ -0,0 +1,142 @@
+package publisher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stale reporter policies accepted by -stale-policy. stalePolicyFlag keeps
+// reporters past -max-staleness and marks their rows stale; stalePolicyExclude
+// drops them, and their observations, from every headline artifact.
+const (
+	stalePolicyFlag    = "flag"
+	stalePolicyExclude = "exclude"
+)
+
+func parseStalePolicy(value string) (string, error) {
+	policy := strings.ToLower(strings.TrimSpace(value))
+	switch policy {
+	case stalePolicyFlag, stalePolicyExclude:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported stale policy %q (expected flag or exclude)", value)
+	}
+}
+
+// maxStaleness is a calendar threshold such as 3y, 18m, or 90d. The zero
+// value disables the check.
+type maxStaleness struct {
+	years, months, days int
+}
+
+func parseMaxStaleness(value string) (maxStaleness, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return maxStaleness{}, nil
+	}
+	count, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || count <= 0 {
+		return maxStaleness{}, fmt.Errorf("invalid max staleness %q (expected a positive count with y, m, or d)", value)
+	}
+	switch value[len(value)-1] {
+	case 'y':
+		return maxStaleness{years: count}, nil
+	case 'm':
+		return maxStaleness{months: count}, nil
+	case 'd':
+		return maxStaleness{days: count}, nil
+	default:
+		return maxStaleness{}, fmt.Errorf("invalid max staleness %q (expected a positive count with y, m, or d)", value)
+	}
+}
+
+func (m maxStaleness) enabled() bool {
+	return m != maxStaleness{}
+}
+
+func (m maxStaleness) String() string {
+	switch {
+	case m.years > 0:
+		return strconv.Itoa(m.years) + "y"
+	case m.months > 0:
+		return strconv.Itoa(m.months) + "m"
+	case m.days > 0:
+		return strconv.Itoa(m.days) + "d"
+	default:
+		return ""
+	}
+}
+
+// staleReporters lists, in latest order, the reporters whose freshest partner
+// block ended before generatedAt minus threshold. Reporters without any
+// parseable period are stale too.
+func staleReporters(generatedAt string, latest []latestEntry, threshold maxStaleness) ([]string, error) {
+	if !threshold.enabled() {
+		return nil, nil
+	}
+	now, err := time.Parse(time.RFC3339, generatedAt)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.AddDate(-threshold.years, -threshold.months, -threshold.days)
+	var stale []string
+	for _, row := range latest {
+		var freshest time.Time
+		for _, block := range row.Partners {
+			if end, ok := periodEnd(block.PeriodType, block.Period); ok && end.After(freshest) {
+				freshest = end
+			}
+		}
+		if freshest.Before(cutoff) {
+			stale = append(stale, row.ISO3)
+		}
+	}
+	return stale, nil
+}
+
+// excludedReporters is the set of reporters whose observations
+// applyStalePolicy drops, for builds that stream observations rather than
+// hold them.
+func excludedReporters(policy string, stale []string) map[string]bool {
+	excluded := make(map[string]bool)
+	if policy == stalePolicyExclude {
+		for _, iso3 := range stale {
+			excluded[iso3] = true
+		}
+	}
+	return excluded
+}
+
+// applyStalePolicy marks or removes the stale reporters. With the exclude
+// policy the returned observations omit those reporters as well, so series,
+// history, and counts agree with latest.json.
+func applyStalePolicy(policy string, stale []string, latest []latestEntry, rows []observationRow) ([]latestEntry, []observationRow) {
+	if len(stale) == 0 {
+		return latest, rows
+	}
+	isStale := make(map[string]bool, len(stale))
+	for _, iso3 := range stale {
+		isStale[iso3] = true
+	}
+	if policy != stalePolicyExclude {
+		for index := range latest {
+			latest[index].Stale = isStale[latest[index].ISO3]
+		}
+		return latest, rows
+	}
+	keptLatest := make([]latestEntry, 0, len(latest))
+	for _, row := range latest {
+		if !isStale[row.ISO3] {
+			keptLatest = append(keptLatest, row)
+		}
+	}
+	keptRows := make([]observationRow, 0, len(rows))
+	for _, row := range rows {
+		if !isStale[strings.ToUpper(row.ReporterISO)] {
+			keptRows = append(keptRows, row)
+		}
+	}
+	return keptLatest, keptRows
+}
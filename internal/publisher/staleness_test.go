@@ -0,0 +1,61 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestStaleReportersUsesFreshestPartnerBlock(t *testing.T) {
+	threshold, err := parseMaxStaleness("3y")
+	if err != nil {
+		t.Fatalf("parseMaxStaleness() error = %v", err)
+	}
+	latest := []latestEntry{
+		{ISO3: "KOR", Partners: map[string]partnerBlock{
+			"USA": {PeriodType: model.PeriodYear, Period: "2019"},
+			"CHN": {PeriodType: model.PeriodMonth, Period: "2024-06"},
+		}},
+		{ISO3: "VEN", Partners: map[string]partnerBlock{
+			"USA": {PeriodType: model.PeriodYear, Period: "2021"},
+			"CHN": {PeriodType: model.PeriodYear, Period: "2020"},
+		}},
+		{ISO3: "XKX"},
+	}
+
+	stale, err := staleReporters("2025-03-01T00:00:00Z", latest, threshold)
+	if err != nil {
+		t.Fatalf("staleReporters() error = %v", err)
+	}
+	if len(stale) != 2 || stale[0] != "VEN" || stale[1] != "XKX" {
+		t.Fatalf("stale = %v, want [VEN XKX]", stale)
+	}
+
+	flagged, _ := applyStalePolicy(stalePolicyFlag, stale, append([]latestEntry(nil), latest...), nil)
+	if len(flagged) != 3 || flagged[0].Stale || !flagged[1].Stale {
+		t.Fatalf("flagged = %+v, want all rows with VEN stale", flagged)
+	}
+
+	rows := []observationRow{{ReporterISO: "KOR"}, {ReporterISO: "ven"}}
+	kept, keptRows := applyStalePolicy(stalePolicyExclude, stale, latest, rows)
+	if len(kept) != 1 || kept[0].ISO3 != "KOR" || kept[0].Stale {
+		t.Fatalf("kept = %+v, want only unflagged KOR", kept)
+	}
+	if len(keptRows) != 1 || keptRows[0].ReporterISO != "KOR" {
+		t.Fatalf("kept observations = %+v, want only KOR", keptRows)
+	}
+}
+
+func TestParseMaxStaleness(t *testing.T) {
+	for value, want := range map[string]string{"": "", "3Y": "3y", "18m": "18m", " 90d ": "90d"} {
+		threshold, err := parseMaxStaleness(value)
+		if err != nil || threshold.String() != want {
+			t.Fatalf("parseMaxStaleness(%q) = %v, %v, want %s", value, threshold, err, want)
+		}
+	}
+	for _, value := range []string{"y", "0y", "3w", "-1d"} {
+		if _, err := parseMaxStaleness(value); err == nil {
+			t.Fatalf("parseMaxStaleness(%q) error = nil", value)
+		}
+	}
+}
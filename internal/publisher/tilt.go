@@ -0,0 +1,72 @@
+package publisher
+
+import (
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// tiltFormula documents how tilt.json values are derived.
+const tiltFormula = "(chn_trade - usa_trade) / (chn_trade + usa_trade)"
+
+// tiltFile is tilt.json: each reporter's China-tilt index over every stored
+// period. The index runs from -1 (trade only with the USA) through 0 (equal
+// trade) to 1 (trade only with China), which equals 2 × share_cn − 1.
+type tiltFile struct {
+	SchemaVersion string       `json:"schema_version"`
+	GeneratedAt   string       `json:"generated_at"`
+	Provider      string       `json:"provider"`
+	Formula       string       `json:"formula"`
+	Rows          []tiltSeries `json:"rows"`
+}
+
+type tiltSeries struct {
+	ISO3   string      `json:"iso3"`
+	Name   string      `json:"name,omitempty"`
+	Points []tiltPoint `json:"points"`
+}
+
+type tiltPoint struct {
+	PeriodType model.PeriodType `json:"period_type"`
+	Period     string           `json:"period"`
+	USATrade   float64          `json:"usa_trade"`
+	CHNTrade   float64          `json:"chn_trade"`
+	Tilt       float64          `json:"tilt"`
+}
+
+// buildTiltFile derives the index from the full history. Only points with
+// both partner blocks and positive trade are kept, so a missing partner never
+// reads as complete dependence on the other. Reporters without such a point
+// are omitted.
+func buildTiltFile(generatedAt, provider string, history seriesFile, latest []latestEntry) tiltFile {
+	output := tiltFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Formula:       tiltFormula,
+		Rows:          []tiltSeries{},
+	}
+	names := make(map[string]string, len(latest))
+	for _, row := range latest {
+		names[row.ISO3] = row.Name
+	}
+	for _, row := range history.Rows {
+		points := make([]tiltPoint, 0, len(row.Points))
+		for _, point := range row.Points {
+			if !point.Comparable || point.Total <= 0 {
+				continue
+			}
+			points = append(points, tiltPoint{
+				PeriodType: point.PeriodType,
+				Period:     point.Period,
+				USATrade:   point.USA.Trade,
+				CHNTrade:   point.CHN.Trade,
+				Tilt:       (point.CHN.Trade - point.USA.Trade) / point.Total,
+			})
+		}
+		if len(points) > 0 {
+			output.Rows = append(output.Rows, tiltSeries{ISO3: row.ISO3, Name: names[row.ISO3], Points: points})
+		}
+	}
+	return output
+}
@@ -0,0 +1,33 @@
+package publisher
+
+import (
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildTiltFileKeepsComparablePoints(t *testing.T) {
+	var rows []observationRow
+	add := func(reporter, partner, period string, value float64) {
+		rows = append(rows, observationRow{ReporterISO: reporter, PartnerISO: partner, Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: period, Value: model.CentsFromUSD(value)})
+	}
+	add("KOR", "USA", "2022", 75)
+	add("KOR", "CHN", "2022", 25)
+	add("KOR", "USA", "2023", 40)
+	add("KOR", "CHN", "2023", 60)
+	add("VNM", "CHN", "2023", 90)
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+
+	output := buildTiltFile("2026-01-01T00:00:00Z", "WITS", history, []latestEntry{{ISO3: "KOR", Name: "Korea"}})
+	if output.Provider != "wits" || output.Formula != tiltFormula {
+		t.Fatalf("unexpected tilt header: %+v", output)
+	}
+	if len(output.Rows) != 1 || output.Rows[0].ISO3 != "KOR" || output.Rows[0].Name != "Korea" {
+		t.Fatalf("rows = %+v, want only KOR because VNM has no USA block", output.Rows)
+	}
+	points := output.Rows[0].Points
+	if len(points) != 2 || points[0].Period != "2022" || math.Abs(points[0].Tilt+0.5) > 1e-12 || math.Abs(points[1].Tilt-0.2) > 1e-12 {
+		t.Fatalf("points = %+v, want tilts -0.5 and 0.2", points)
+	}
+}
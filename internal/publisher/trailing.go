@@ -0,0 +1,107 @@
+package publisher
+
+import (
+	"tradegravity/internal/model"
+)
+
+// trailingBlock sums the twelve months ending at Through. It smooths single
+// month swings in monthly data; annual and quarterly blocks do not carry one.
+type trailingBlock struct {
+	Through string  `json:"through"`
+	Export  float64 `json:"export"`
+	Import  float64 `json:"import"`
+	Trade   float64 `json:"trade"`
+}
+
+// trailingMonths returns the twelve months ending at period, oldest first.
+func trailingMonths(period string) []string {
+	window, err := model.PeriodRangeEnding(model.PeriodMonth, period, 12)
+	if err != nil {
+		return nil
+	}
+	return window.Periods()
+}
+
+// trailingTotals sums each flow over the trailing twelve months. Every month
+// must be present for both flows, so a partial window never understates trade.
+func trailingTotals(series map[model.Flow]map[string]float64, periodType model.PeriodType, period string) *trailingBlock {
+	if periodType != model.PeriodMonth {
+		return nil
+	}
+	months := trailingMonths(period)
+	if months == nil {
+		return nil
+	}
+	block := &trailingBlock{Through: period}
+	for _, month := range months {
+		exportValue, exportOk := seriesValue(series, model.FlowExport, model.PeriodMonth, month)
+		importValue, importOk := seriesValue(series, model.FlowImport, model.PeriodMonth, month)
+		if !exportOk || !importOk {
+			return nil
+		}
+		block.Export += exportValue
+		block.Import += importValue
+	}
+	block.Trade = block.Export + block.Import
+	return block
+}
+
+func trailingShare(usa, chn *trailingBlock) *float64 {
+	if usa == nil || chn == nil || usa.Through != chn.Through {
+		return nil
+	}
+	total := usa.Trade + chn.Trade
+	if total <= 0 {
+		return nil
+	}
+	share := chn.Trade / total
+	return &share
+}
+
+// applyTrailingTotals adds trailing twelve-month totals to the monthly points
+// of one reporter's series. A partner block needs all twelve months available.
+func applyTrailingTotals(pointsByPeriod map[string]*seriesPoint) {
+	for _, point := range pointsByPeriod {
+		if point.PeriodType != model.PeriodMonth {
+			continue
+		}
+		months := trailingMonths(point.Period)
+		if months == nil {
+			continue
+		}
+		usa := &trailingBlock{Through: point.Period}
+		chn := &trailingBlock{Through: point.Period}
+		for _, month := range months {
+			window := pointsByPeriod[seriesKey(model.PeriodMonth, month)]
+			if window == nil {
+				usa, chn = nil, nil
+				break
+			}
+			if usa != nil {
+				if window.USA.Available {
+					usa.Export += window.USA.Export
+					usa.Import += window.USA.Import
+				} else {
+					usa = nil
+				}
+			}
+			if chn != nil {
+				if window.CHN.Available {
+					chn.Export += window.CHN.Export
+					chn.Import += window.CHN.Import
+				} else {
+					chn = nil
+				}
+			}
+		}
+		if usa != nil {
+			usa.Trade = usa.Export + usa.Import
+			point.USA.TTM = usa
+		}
+		if chn != nil {
+			chn.Trade = chn.Export + chn.Import
+			point.CHN.TTM = chn
+		}
+		point.ShareCNTTM = trailingShare(usa, chn)
+	}
+}
@@ -0,0 +1,54 @@
+package publisher
+
+import (
+	"fmt"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestTrailingMonthsCrossesYearBoundary(t *testing.T) {
+	months := trailingMonths("2024-03")
+	if len(months) != 12 || months[0] != "2023-04" || months[11] != "2024-03" {
+		t.Fatalf("trailing months = %v", months)
+	}
+}
+
+func TestTrailingTotalsRequireTwelveCompleteMonths(t *testing.T) {
+	var rows []observationRow
+	for month := 1; month <= 12; month++ {
+		period := fmt.Sprintf("2024-%02d", month)
+		for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+			rows = append(rows,
+				observationRow{ReporterISO: "KOR", PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodMonth, Period: period, Value: 1000},
+				observationRow{ReporterISO: "KOR", PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodMonth, Period: period, Value: model.CentsFromUSD(float64(month))},
+			)
+		}
+	}
+
+	latest := buildLatest(rows, []string{"USA", "CHN"}, growthYoY, alignLatest, mixedAllow)
+	if len(latest) != 1 || latest[0].USA.TTM == nil || latest[0].CHN.TTM == nil {
+		t.Fatalf("latest trailing totals missing: %+v", latest)
+	}
+	entry := latest[0]
+	if entry.USA.TTM.Through != "2024-12" || entry.USA.TTM.Trade != 240 || entry.CHN.TTM.Trade != 156 {
+		t.Fatalf("unexpected trailing totals: usa=%+v chn=%+v", entry.USA.TTM, entry.CHN.TTM)
+	}
+	if entry.ShareCNTTM == nil || *entry.ShareCNTTM != 156.0/396.0 {
+		t.Fatalf("share_cn_ttm = %v", entry.ShareCNTTM)
+	}
+
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+	points := history.Rows[0].Points
+	if points[10].USA.TTM != nil || points[10].ShareCNTTM != nil {
+		t.Fatalf("November has an incomplete window but got trailing totals: %+v", points[10])
+	}
+	if points[11].CHN.TTM == nil || points[11].CHN.TTM.Trade != 156 || points[11].ShareCNTTM == nil {
+		t.Fatalf("December trailing totals missing: %+v", points[11])
+	}
+
+	annual := map[model.Flow]map[string]float64{model.FlowExport: {"Y|2024": 1}, model.FlowImport: {"Y|2024": 1}}
+	if got := trailingTotals(annual, model.PeriodYear, "2024"); got != nil {
+		t.Fatalf("annual block got trailing totals: %+v", got)
+	}
+}
@@ -0,0 +1,128 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"tradegravity/internal/cdn"
+	"tradegravity/internal/objectstore"
+	"tradegravity/internal/server"
+)
+
+// Upload targets cannot rename a directory, so the swap is only ordered, not
+// atomic: every data object is uploaded first, and the entry artifacts that
+// point at them go last, with meta.json at the very end. Data objects keep
+// their keys between builds, so a reader holding the previous entry
+// artifacts can still fetch new data objects while an upload runs; the order
+// only ensures entry artifacts never point at objects not yet uploaded.
+// Entry artifacts are not cached so readers pick up the new publish as soon
+// as it is complete.
+var entryArtifacts = []string{"latest.json", "catalog.json", artifactIndexName, "meta.json"}
+
+const (
+	entryCacheControl = "no-cache"
+	dataCacheControl  = "public, max-age=300"
+	uploadConcurrency = 8
+)
+
+// publishTo uploads the built outDir to target.
+func publishTo(ctx context.Context, outDir, target string) (int, error) {
+	parsed, err := objectstore.ParseTarget(target)
+	if err != nil {
+		return 0, err
+	}
+	uploader, err := objectstore.Open(parsed)
+	if err != nil {
+		return 0, err
+	}
+	uploaded, err := uploadDir(ctx, outDir, parsed, uploader)
+	// Close reports a queued upload's failure, which explains a failed Put.
+	if closeErr := uploader.Close(); closeErr != nil {
+		return 0, closeErr
+	}
+	return uploaded, err
+}
+
+// purgeCDN drops the cached copies of paths, the files this build rewrote,
+// so readers see the new publish without waiting for the cache to expire.
+// Unchanged files keep their cached copies.
+func purgeCDN(ctx context.Context, target cdn.Target, base string, paths []string) (int, error) {
+	purger, err := cdn.FromEnv(target, base)
+	if err != nil {
+		return 0, err
+	}
+	return purger.Purge(ctx, paths)
+}
+
+// uploadDir uploads the files listed in dir's index.json, then index.json
+// itself, so files an earlier build left in dir are not published again.
+// Entry artifacts are uploaded only after all data objects succeeded, so a
+// failed upload leaves the previous publish's entry points in place.
+func uploadDir(ctx context.Context, dir string, target objectstore.Target, uploader objectstore.Uploader) (int, error) {
+	body, err := os.ReadFile(filepath.Join(dir, artifactIndexName))
+	if err != nil {
+		return 0, err
+	}
+	var index artifactIndexFile
+	if err := json.Unmarshal(body, &index); err != nil {
+		return 0, fmt.Errorf("decode %s: %w", artifactIndexName, err)
+	}
+	var data []string
+	entries := []string{artifactIndexName}
+	for _, file := range index.Files {
+		switch {
+		case file.Path == checksumsName || file.Path == artifactIndexName:
+		case slices.Contains(entryArtifacts, file.Path):
+			entries = append(entries, file.Path)
+		default:
+			data = append(data, file.Path)
+		}
+	}
+
+	upload := func(relative, cacheControl string) error {
+		body, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(relative)))
+		if err != nil {
+			return err
+		}
+		return uploader.Put(ctx, target.Key(relative), body, server.ContentType(relative), cacheControl)
+	}
+
+	jobs := make(chan string)
+	errs := make(chan error, len(data))
+	var workers sync.WaitGroup
+	for range max(1, min(uploadConcurrency, len(data))) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for relative := range jobs {
+				if err := upload(relative, dataCacheControl); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	for _, relative := range data {
+		jobs <- relative
+	}
+	close(jobs)
+	workers.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return 0, err
+	}
+
+	slices.SortFunc(entries, func(a, b string) int {
+		return slices.Index(entryArtifacts, a) - slices.Index(entryArtifacts, b)
+	})
+	for _, relative := range entries {
+		if err := upload(relative, entryCacheControl); err != nil {
+			return 0, err
+		}
+	}
+	return len(data) + len(entries), nil
+}
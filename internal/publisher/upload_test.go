@@ -0,0 +1,71 @@
+package publisher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"tradegravity/internal/objectstore"
+)
+
+func TestUploadDirPutsEntryArtifactsLast(t *testing.T) {
+	dir := t.TempDir()
+	var records []artifactRecord
+	for _, name := range []string{"meta.json", "latest.json", "catalog.json", "history.csv", "countries/DEU.json", "countries/OLD.json", checksumsName} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if name != "countries/OLD.json" && name != checksumsName {
+			records = append(records, artifactRecord{Path: name})
+		}
+	}
+	// countries/OLD.json is left over from an earlier build: it is in the
+	// directory but not in index.json.
+	if err := writeJSON(filepath.Join(dir, artifactIndexName), buildArtifactIndex("2026-01-01T00:00:00Z", records)); err != nil {
+		t.Fatal(err)
+	}
+
+	uploader := &recordingUploader{headers: map[string][2]string{}}
+	count, err := uploadDir(context.Background(), dir, objectstore.Target{Scheme: "s3", Bucket: "bucket", Prefix: "data"}, uploader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, headers := uploader.keys, uploader.headers
+	if count != 6 || len(keys) != 6 {
+		t.Fatalf("uploaded %d objects: %v", count, keys)
+	}
+	if got := keys[2:]; got[0] != "data/latest.json" || got[1] != "data/catalog.json" || got[2] != "data/index.json" || got[3] != "data/meta.json" {
+		t.Fatalf("entry artifacts not uploaded last in order: %v", keys)
+	}
+	if got := headers["data/history.csv"]; got != [2]string{"text/csv; charset=utf-8", dataCacheControl} {
+		t.Fatalf("history.csv headers = %v", got)
+	}
+	if got := headers["data/meta.json"]; got != [2]string{"application/json; charset=utf-8", entryCacheControl} {
+		t.Fatalf("meta.json headers = %v", got)
+	}
+}
+
+// recordingUploader records each Put's key and headers.
+type recordingUploader struct {
+	mu      sync.Mutex
+	keys    []string
+	headers map[string][2]string
+}
+
+func (u *recordingUploader) Put(_ context.Context, key string, _ []byte, contentType, cacheControl string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.keys = append(u.keys, key)
+	u.headers[key] = [2]string{contentType, cacheControl}
+	return nil
+}
+
+func (u *recordingUploader) Close() error {
+	return nil
+}
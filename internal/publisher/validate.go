@@ -0,0 +1,323 @@
+package publisher
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"tradegravity/internal/config"
+	"tradegravity/internal/model"
+)
+
+//go:embed schemas/*.schema.json
+var artifactSchemas embed.FS
+
+// validatedArtifacts pairs published files with their embedded schema.
+// Required files must exist; the rest are checked when present.
+var validatedArtifacts = []struct {
+	file, schema string
+	required     bool
+}{
+	{file: "meta.json", schema: "meta", required: true},
+	{file: "latest.json", schema: "latest", required: true},
+	{file: "catalog.json", schema: "catalog", required: true},
+	{file: "aggregates.json", schema: "aggregates"},
+	{file: "map.json", schema: "map"},
+	{file: "coverage.json", schema: "coverage"},
+	{file: "diff.json", schema: "diff"},
+	{file: "rankings.json", schema: "rankings"},
+	{file: "tilt.json", schema: "tilt"},
+	{file: "movers.json", schema: "movers"},
+	{file: "forecast.json", schema: "forecast"},
+	{file: "volatility.json", schema: "volatility"},
+	{file: "diversion.json", schema: "diversion"},
+	{file: "correlation.json", schema: "correlation"},
+	{file: "discrepancies.json", schema: "discrepancies"},
+}
+
+// Validate parses the validate flags in args and checks the artifacts in
+// -dir against the embedded JSON Schemas. It exits non-zero on any
+// violation, so it can gate a publish.
+func Validate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	dir := fs.String("dir", "site/data", "published data directory")
+	config.Parse(fs, args)
+
+	violations, checked, err := validateDir(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "validate failed:", err)
+		os.Exit(1)
+	}
+	for _, violation := range violations {
+		fmt.Fprintln(os.Stderr, violation)
+	}
+	if len(violations) > 0 {
+		fmt.Fprintf(os.Stderr, "publisher validate found %d violation(s) in %s\n", len(violations), *dir)
+		os.Exit(1)
+	}
+	fmt.Printf("publisher validate complete (dir=%s files=%d)\n", *dir, checked)
+}
+
+// validateDir returns one "file: /pointer: message" line per violation and
+// the number of files checked.
+func validateDir(dir string) ([]string, int, error) {
+	var violations []string
+	checked := 0
+	for _, artifact := range validatedArtifacts {
+		schema, err := loadArtifactSchema(artifact.schema)
+		if err != nil {
+			return nil, 0, err
+		}
+		body, err := os.ReadFile(filepath.Join(dir, artifact.file))
+		if errors.Is(err, os.ErrNotExist) {
+			if artifact.required {
+				violations = append(violations, artifact.file+": file is missing")
+			}
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		checked++
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var document any
+		if err := decoder.Decode(&document); err != nil {
+			violations = append(violations, fmt.Sprintf("%s: invalid JSON: %v", artifact.file, err))
+			continue
+		}
+		checker := schemaChecker{root: schema}
+		checker.check(schema, document, "")
+		for _, violation := range checker.violations {
+			violations = append(violations, artifact.file+": "+violation)
+		}
+	}
+	return violations, checked, nil
+}
+
+func loadArtifactSchema(name string) (map[string]any, error) {
+	body, err := artifactSchemas.ReadFile("schemas/" + name + ".schema.json")
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(body, &schema); err != nil {
+		return nil, fmt.Errorf("decode %s schema: %w", name, err)
+	}
+	return schema, nil
+}
+
+// schemaChecker evaluates the JSON Schema keywords the embedded schemas use:
+// type, enum, required, properties, additionalProperties (as a schema),
+// items, minimum, maximum, minLength, pattern, format, and local $ref.
+// Unknown properties are allowed, matching the additive schema 2.x policy.
+type schemaChecker struct {
+	root       map[string]any
+	violations []string
+}
+
+func (c *schemaChecker) fail(pointer, format string, args ...any) {
+	if pointer == "" {
+		pointer = "/"
+	}
+	c.violations = append(c.violations, pointer+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *schemaChecker) check(schema map[string]any, value any, pointer string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, found := c.resolve(ref)
+		if !found {
+			c.fail(pointer, "unresolved schema reference %s", ref)
+			return
+		}
+		schema = target
+	}
+	if expected, ok := schema["type"].(string); ok && !schemaTypeMatches(expected, value) {
+		c.fail(pointer, "expected %s, got %s", expected, jsonTypeName(value))
+		return
+	}
+	if options, ok := schema["enum"].([]any); ok && !enumContains(options, value) {
+		c.fail(pointer, "%v is not one of %v", value, options)
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, present := typed[name.(string)]; !present {
+					c.fail(pointer, "missing required field %q", name)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := pointer + "/" + key
+			if property, ok := properties[key].(map[string]any); ok {
+				c.check(property, typed[key], child)
+			} else if additional != nil {
+				c.check(additional, typed[key], child)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for index, item := range typed {
+				c.check(items, item, fmt.Sprintf("%s/%d", pointer, index))
+			}
+		}
+	case json.Number:
+		number, err := typed.Float64()
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			c.fail(pointer, "%s is not a finite number", typed)
+			return
+		}
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			c.fail(pointer, "%s is less than minimum %v", typed, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && number > maximum {
+			c.fail(pointer, "%s is greater than maximum %v", typed, maximum)
+		}
+	case string:
+		if minLength, ok := schema["minLength"].(float64); ok && float64(len(typed)) < minLength {
+			c.fail(pointer, "%q is shorter than %v", typed, minLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			expression, err := regexp.Compile(pattern)
+			if err != nil {
+				c.fail(pointer, "invalid schema pattern %q", pattern)
+			} else if !expression.MatchString(typed) {
+				c.fail(pointer, "%q does not match %s", typed, pattern)
+			}
+		}
+		if format, ok := schema["format"].(string); ok && !formatMatches(format, typed) {
+			c.fail(pointer, "%q is not a valid %s", typed, format)
+		}
+	}
+}
+
+// resolve follows "#/$defs/name" style references within the root schema.
+func (c *schemaChecker) resolve(ref string) (map[string]any, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	var node any = c.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		node = object[part]
+	}
+	target, ok := node.(map[string]any)
+	return target, ok
+}
+
+func schemaTypeMatches(expected string, value any) bool {
+	switch expected {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := number.Int64()
+		return err == nil
+	case "null":
+		return value == nil
+	default:
+		return false
+	}
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func enumContains(options []any, value any) bool {
+	for _, option := range options {
+		if number, ok := value.(json.Number); ok {
+			if float, ok := option.(float64); ok {
+				parsed, err := number.Float64()
+				if err == nil && parsed == float {
+					return true
+				}
+			}
+			continue
+		}
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// formatMatches checks the string formats the schemas use. "period" accepts
+// an empty string, which marks a missing partner block, or any year, quarter,
+// or month period the publisher writes.
+func formatMatches(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "iso3":
+		return len(value) == 3 && strings.ToUpper(value) == value && strings.Trim(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == ""
+	case "period":
+		if value == "" {
+			return true
+		}
+		if _, ok := model.ParseYear(value); ok {
+			return true
+		}
+		if _, _, ok := model.ParseYearQuarter(value); ok {
+			return true
+		}
+		_, _, ok := model.ParseYearMonth(value)
+		return ok
+	default:
+		return true
+	}
+}
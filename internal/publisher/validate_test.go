@@ -0,0 +1,54 @@
+package publisher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateDirReportsSchemaViolations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"meta.json": `{"schema_version":"2.0","generated_at":"2026-01-01T00:00:00Z","provider":"wits","partners":["USA","CHN"],
+			"reporter_count":1,"observation_count":4,"expected_partner_blocks":2,"available_partner_blocks":2,"missing_partner_blocks":0,"period_counts":{"Y:2023":2}}`,
+		"latest.json": `{"schema_version":"2.0","generated_at":"2026-01-01T00:00:00Z","provider":"wits","partners":["USA","CHN"],"rows":[
+			{"iso3":"DEU","usa":{"period":"2023","period_type":"Y","export":1,"import":1,"trade":2},"chn":{"period":"2023-Q5","period_type":"Q","export":1,"import":1,"trade":2},"total":4,"share_cn":1.5}
+		]}`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	violations, checked, err := validateDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checked != 2 {
+		t.Fatalf("expected 2 checked files, got %d", checked)
+	}
+	want := []string{
+		"catalog.json: file is missing",
+		`latest.json: /rows/0/chn/period: "2023-Q5" is not a valid period`,
+		"latest.json: /rows/0/share_cn: 1.5 is greater than maximum 1",
+	}
+	got := strings.Join(violations, "\n")
+	for _, violation := range want {
+		if !strings.Contains(got, violation) {
+			t.Fatalf("missing violation %q in:\n%s", violation, got)
+		}
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("unexpected violations:\n%s", got)
+	}
+}
+
+func TestEmbeddedSchemasLoad(t *testing.T) {
+	for _, artifact := range validatedArtifacts {
+		if _, err := loadArtifactSchema(artifact.schema); err != nil {
+			t.Fatalf("%s: %v", artifact.schema, err)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package publisher
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// volatilityFormula documents how volatility.json values are derived.
+const volatilityFormula = "cv = population standard deviation / mean of monthly partner trade over the twelve months ending at period"
+
+// Volatility classes. A coefficient of variation below volatilityStable is
+// a steady monthly relationship; at or above volatilityNoisy, single
+// shipments or reporting gaps dominate the series.
+const (
+	volatilityStable = 0.15
+	volatilityNoisy  = 0.35
+)
+
+// volatilityFile is volatility.json: the rolling twelve-month coefficient of
+// variation of each reporter's monthly trade with the USA and China, so a
+// stable relationship can be told apart from a noisy small-sample series.
+type volatilityFile struct {
+	SchemaVersion string             `json:"schema_version"`
+	GeneratedAt   string             `json:"generated_at"`
+	Provider      string             `json:"provider"`
+	Formula       string             `json:"formula"`
+	WindowMonths  int                `json:"window_months"`
+	Thresholds    map[string]float64 `json:"thresholds"`
+	Rows          []volatilitySeries `json:"rows"`
+}
+
+// volatilitySeries is one reporter. USA and CHN summarize the latest window
+// each partner has; Points lists every window end with either value.
+type volatilitySeries struct {
+	ISO3   string            `json:"iso3"`
+	Name   string            `json:"name,omitempty"`
+	USA    *volatilityBlock  `json:"usa,omitempty"`
+	CHN    *volatilityBlock  `json:"chn,omitempty"`
+	Points []volatilityPoint `json:"points"`
+}
+
+type volatilityBlock struct {
+	Through string  `json:"through"`
+	Mean    float64 `json:"mean"`
+	StdDev  float64 `json:"std_dev"`
+	CV      float64 `json:"cv"`
+	Class   string  `json:"class"`
+}
+
+type volatilityPoint struct {
+	Period string   `json:"period"`
+	USACV  *float64 `json:"usa_cv,omitempty"`
+	CHNCV  *float64 `json:"chn_cv,omitempty"`
+}
+
+// buildVolatility computes the coefficient of variation at each month that
+// ends twelve consecutive months with the partner block available. Windows
+// with a gap or a zero mean are skipped, like trailing totals, and reporters
+// without monthly data are omitted.
+func buildVolatility(generatedAt, provider string, history seriesFile, latest []latestEntry) volatilityFile {
+	output := volatilityFile{
+		SchemaVersion: schemaVersion,
+		GeneratedAt:   generatedAt,
+		Provider:      strings.ToLower(strings.TrimSpace(provider)),
+		Formula:       volatilityFormula,
+		WindowMonths:  12,
+		Thresholds:    map[string]float64{"stable": volatilityStable, "noisy": volatilityNoisy},
+		Rows:          []volatilitySeries{},
+	}
+	names := make(map[string]string, len(latest))
+	for _, row := range latest {
+		names[row.ISO3] = row.Name
+	}
+	for _, row := range history.Rows {
+		months := make(map[string]seriesPoint)
+		var periods []string
+		for _, point := range row.Points {
+			if point.PeriodType == model.PeriodMonth {
+				months[point.Period] = point
+				periods = append(periods, point.Period)
+			}
+		}
+		sort.Strings(periods)
+		series := volatilitySeries{ISO3: row.ISO3, Name: names[row.ISO3], Points: []volatilityPoint{}}
+		for _, period := range periods {
+			usa := volatilityWindow(months, period, func(point seriesPoint) seriesBlock { return point.USA })
+			chn := volatilityWindow(months, period, func(point seriesPoint) seriesBlock { return point.CHN })
+			if usa == nil && chn == nil {
+				continue
+			}
+			point := volatilityPoint{Period: period}
+			if usa != nil {
+				point.USACV, series.USA = &usa.CV, usa
+			}
+			if chn != nil {
+				point.CHNCV, series.CHN = &chn.CV, chn
+			}
+			series.Points = append(series.Points, point)
+		}
+		if len(series.Points) > 0 {
+			output.Rows = append(output.Rows, series)
+		}
+	}
+	return output
+}
+
+// volatilityWindow summarizes one partner's trade over the twelve months
+// ending at period, or returns nil when the window is incomplete.
+func volatilityWindow(months map[string]seriesPoint, period string, partner func(seriesPoint) seriesBlock) *volatilityBlock {
+	window := trailingMonths(period)
+	if window == nil {
+		return nil
+	}
+	values := make([]float64, 0, len(window))
+	for _, month := range window {
+		point, ok := months[month]
+		if !ok || !partner(point).Available {
+			return nil
+		}
+		values = append(values, partner(point).Trade)
+	}
+	average := mean(values)
+	if average <= 0 {
+		return nil
+	}
+	var squares float64
+	for _, value := range values {
+		squares += (value - average) * (value - average)
+	}
+	deviation := math.Sqrt(squares / float64(len(values)))
+	cv := deviation / average
+	return &volatilityBlock{Through: period, Mean: average, StdDev: deviation, CV: cv, Class: volatilityClass(cv)}
+}
+
+func volatilityClass(cv float64) string {
+	switch {
+	case cv < volatilityStable:
+		return "stable"
+	case cv < volatilityNoisy:
+		return "moderate"
+	default:
+		return "noisy"
+	}
+}
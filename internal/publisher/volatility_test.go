@@ -0,0 +1,51 @@
+package publisher
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestBuildVolatilityUsesCompleteTwelveMonthWindows(t *testing.T) {
+	var rows []observationRow
+	add := func(partner, period string, value float64) {
+		for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+			rows = append(rows, observationRow{ReporterISO: "KOR", PartnerISO: partner, Flow: flow, PeriodType: model.PeriodMonth, Period: period, Value: model.CentsFromUSD(value / 2)})
+		}
+	}
+	// Thirteen months of steady USA trade. China alternates 50 and 150 but
+	// misses 2023-12, so only its window ending 2024-12 is complete.
+	add("USA", "2023-12", 100)
+	for month := 1; month <= 12; month++ {
+		period := fmt.Sprintf("2024-%02d", month)
+		add("USA", period, 100)
+		add("CHN", period, 100+50*float64(2*(month%2)-1))
+	}
+	rows = append(rows, observationRow{ReporterISO: "JPN", PartnerISO: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", Value: 1000})
+	history := buildSeriesFile("2026-01-01T00:00:00Z", "wits", []string{"USA", "CHN"}, rows, 0)
+
+	output := buildVolatility("2026-01-01T00:00:00Z", "WITS", history, []latestEntry{{ISO3: "KOR", Name: "Korea"}})
+	if output.Provider != "wits" || output.WindowMonths != 12 || output.Formula != volatilityFormula {
+		t.Fatalf("unexpected volatility header: %+v", output)
+	}
+	if len(output.Rows) != 1 {
+		t.Fatalf("rows = %+v, want only KOR because JPN has no monthly data", output.Rows)
+	}
+	row := output.Rows[0]
+	if row.Name != "Korea" || len(row.Points) != 2 || row.Points[0].Period != "2024-11" || row.Points[1].Period != "2024-12" {
+		t.Fatalf("unexpected points %+v", row.Points)
+	}
+	if row.Points[0].CHNCV != nil || row.USA == nil || row.USA.Through != "2024-12" || row.USA.CV != 0 || row.USA.Class != "stable" {
+		t.Fatalf("unexpected USA summary %+v", row.USA)
+	}
+	if row.CHN == nil || row.CHN.Through != "2024-12" || math.Abs(row.CHN.Mean-100) > 1e-9 || math.Abs(row.CHN.CV-0.5) > 1e-9 || row.CHN.Class != "noisy" {
+		t.Fatalf("unexpected CHN summary %+v", row.CHN)
+	}
+	for cv, want := range map[float64]string{0.1: "stable", 0.2: "moderate", volatilityNoisy: "noisy"} {
+		if got := volatilityClass(cv); got != want {
+			t.Fatalf("volatilityClass(%v) = %s, want %s", cv, got, want)
+		}
+	}
+}
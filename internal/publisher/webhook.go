@@ -0,0 +1,116 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"tradegravity/internal/secrets"
+)
+
+const (
+	webhookTimeout = 30 * time.Second
+	// webhookSignatureHeader carries the hex HMAC-SHA256 of the body, keyed
+	// with POST_TO_SECRET, as sha256=<hex>.
+	webhookSignatureHeader = "X-TradeGravity-Signature"
+)
+
+// webhookPayload is the body -post-to sends after a build: latest.json and,
+// when the build wrote one, diff.json, embedded as published.
+type webhookPayload struct {
+	Event       string          `json:"event"`
+	GeneratedAt string          `json:"generated_at"`
+	Latest      json.RawMessage `json:"latest"`
+	Diff        json.RawMessage `json:"diff,omitempty"`
+}
+
+// parseWebhookURL accepts an absolute http or https URL.
+func parseWebhookURL(value string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return "", err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("unsupported webhook %q (expected an http:// or https:// URL)", value)
+	}
+	return parsed.String(), nil
+}
+
+// postTo sends the build in outDir to the webhook at target. The body is
+// signed when POST_TO_SECRET is set, so the receiver can check it came from
+// this publisher.
+func postTo(ctx context.Context, outDir, target string) error {
+	payload, err := buildWebhookPayload(outDir)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	secret, err := secrets.Lookup("POST_TO_SECRET")
+	if err != nil {
+		return err
+	}
+	if secret != "" {
+		req.Header.Set(webhookSignatureHeader, webhookSignature(secret, body))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("post %s: status %d: %s", req.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+func buildWebhookPayload(outDir string) (webhookPayload, error) {
+	payload := webhookPayload{Event: "publish"}
+	latest, err := os.ReadFile(layoutPath(outDir, "latest.json"))
+	if err != nil {
+		return webhookPayload{}, fmt.Errorf("read published latest.json: %w", err)
+	}
+	var header struct {
+		GeneratedAt string `json:"generated_at"`
+	}
+	if err := json.Unmarshal(latest, &header); err != nil {
+		return webhookPayload{}, fmt.Errorf("decode published latest.json: %w", err)
+	}
+	payload.GeneratedAt, payload.Latest = header.GeneratedAt, latest
+	diff, err := os.ReadFile(layoutPath(outDir, "diff.json"))
+	switch {
+	case err == nil:
+		payload.Diff = diff
+	case !errors.Is(err, fs.ErrNotExist):
+		return webhookPayload{}, fmt.Errorf("read published diff.json: %w", err)
+	}
+	return payload, nil
+}
+
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,68 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPostToSendsSignedLatestAndDiff(t *testing.T) {
+	dir := t.TempDir()
+	latest := latestFile{SchemaVersion: schemaVersion, GeneratedAt: "2026-01-02T03:04:05Z", Provider: "wits", Rows: []latestEntry{{ISO3: "KOR"}}}
+	if err := writeJSON(filepath.Join(dir, "latest.json"), latest); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("POST_TO_SECRET", "shared")
+
+	var body []byte
+	var signature string
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(webhookSignatureHeader)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	if err := postTo(context.Background(), dir, srv.URL+"/hook"); err != nil {
+		t.Fatal(err)
+	}
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Event != "publish" || payload.GeneratedAt != latest.GeneratedAt || !strings.Contains(string(payload.Latest), `"KOR"`) || payload.Diff != nil {
+		t.Fatalf("payload = %s", body)
+	}
+	if signature != webhookSignature("shared", body) {
+		t.Fatalf("signature = %q", signature)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "diff.json"), []byte(`{"added":["VNM"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	status = http.StatusBadGateway
+	if err := postTo(context.Background(), dir, srv.URL+"/hook"); err == nil || !strings.Contains(err.Error(), "status 502") {
+		t.Fatalf("postTo error = %v, want the failed status", err)
+	}
+	if !strings.Contains(string(body), `"diff":{"added":["VNM"]}`) {
+		t.Fatalf("payload without the diff: %s", body)
+	}
+}
+
+func TestParseWebhookURL(t *testing.T) {
+	if _, err := parseWebhookURL("https://hooks.example.com/tradegravity"); err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"hooks.example.com/tradegravity", "ftp://hooks.example.com", "https://"} {
+		if _, err := parseWebhookURL(value); err == nil {
+			t.Fatalf("parseWebhookURL(%q) accepted an invalid URL", value)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package publisher
+
+import (
+	"strings"
+
+	"tradegravity/internal/model"
+)
+
+// worldPartner is the partner code for a reporter's trade with the world. It
+// is loaded only as a denominator and never published as a tracked partner.
+const worldPartner = "WLD"
+
+// applyWorldShares sets share_of_total on every partner block whose reporter
+// has world trade for both flows, or a reported total, in the block's period.
+func applyWorldShares(latest []latestEntry, worldRows []observationRow) {
+	world := newWorldTotals(providerMerge{})
+	world.add(worldRows)
+	world.apply(latest)
+}
+
+// worldTotals folds world observations into each reporter's world series as
+// they are scanned, so Build never holds the rows themselves. Under a
+// provider merge every value keeps its provider's rank, and apply only
+// reads the values providerMerge.apply would have kept.
+type worldTotals struct {
+	merge  providerMerge
+	values map[string]map[model.Flow]map[string]rankedValue
+	// best is the most preferred rank seen for each reporter and period.
+	best map[string]int
+}
+
+type rankedValue struct {
+	value float64
+	rank  int
+}
+
+func newWorldTotals(merge providerMerge) *worldTotals {
+	return &worldTotals{
+		merge:  merge,
+		values: make(map[string]map[model.Flow]map[string]rankedValue),
+		best:   make(map[string]int),
+	}
+}
+
+// add folds a chunk of rows in. It always returns nil, so it can be handed
+// to eachObservationChunk.
+func (w *worldTotals) add(chunk []observationRow) error {
+	for _, row := range chunk {
+		reporter := strings.ToUpper(row.ReporterISO)
+		if !strings.EqualFold(row.PartnerISO, worldPartner) || reporter == "" {
+			continue
+		}
+		rank := 0
+		if w.merge.enabled() {
+			if rank = w.merge.rank(row); rank < 0 {
+				continue
+			}
+		}
+		key := seriesKey(row.PeriodType, row.Period)
+		if best, ok := w.best[reporter+"|"+key]; !ok || rank < best {
+			w.best[reporter+"|"+key] = rank
+		}
+		if w.values[reporter] == nil {
+			w.values[reporter] = make(map[model.Flow]map[string]rankedValue)
+		}
+		if w.values[reporter][row.Flow] == nil {
+			w.values[reporter][row.Flow] = make(map[string]rankedValue)
+		}
+		if current, ok := w.values[reporter][row.Flow][key]; !ok || rank <= current.rank {
+			w.values[reporter][row.Flow][key] = rankedValue{value: row.Value.USD(), rank: rank}
+		}
+	}
+	return nil
+}
+
+// series returns reporter's world trade by flow and period.
+func (w *worldTotals) series(reporter string) map[model.Flow]map[string]float64 {
+	flows := w.values[reporter]
+	if flows == nil {
+		return nil
+	}
+	series := make(map[model.Flow]map[string]float64, len(flows))
+	for flow, values := range flows {
+		series[flow] = make(map[string]float64, len(values))
+		for key, value := range values {
+			if value.rank == w.best[reporter+"|"+key] {
+				series[flow][key] = value.value
+			}
+		}
+	}
+	return series
+}
+
+// apply sets share_of_total on the blocks of latest, as applyWorldShares
+// does.
+func (w *worldTotals) apply(latest []latestEntry) {
+	for index := range latest {
+		series := w.series(latest[index].ISO3)
+		if series == nil {
+			continue
+		}
+		latest[index].USA.ShareOfTotal = worldShare(latest[index].USA, series)
+		latest[index].CHN.ShareOfTotal = worldShare(latest[index].CHN, series)
+		for partner, block := range latest[index].Partners {
+			block.ShareOfTotal = worldShare(block, series)
+			latest[index].Partners[partner] = block
+		}
+	}
+}
+
+func worldShare(block partnerBlock, world map[model.Flow]map[string]float64) *float64 {
+	if block.Period == "" {
+		return nil
+	}
+	exportValue, exportOk := seriesValue(world, model.FlowExport, block.PeriodType, block.Period)
+	importValue, importOk := seriesValue(world, model.FlowImport, block.PeriodType, block.Period)
+	total, totalOk := exportValue+importValue, exportOk && importOk
+	if !totalOk {
+		total, totalOk = seriesValue(world, model.FlowTotal, block.PeriodType, block.Period)
+	}
+	if !totalOk || total <= 0 {
+		return nil
+	}
+	share := block.Trade / total
+	return &share
+}
@@ -0,0 +1,108 @@
+package publisher
+
+import (
+	"testing"
+
+	"tradegravity/internal/model"
+)
+
+func TestApplyWorldSharesDividesByWorldTradeInBlockPeriod(t *testing.T) {
+	var rows, worldRows []observationRow
+	for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+		rows = append(rows,
+			observationRow{ReporterISO: "KOR", PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodYear, Period: "2023", Value: 2000},
+			observationRow{ReporterISO: "KOR", PartnerISO: "CHN", Flow: flow, PeriodType: model.PeriodYear, Period: "2023", Value: 3000},
+			observationRow{ReporterISO: "JPN", PartnerISO: "USA", Flow: flow, PeriodType: model.PeriodYear, Period: "2023", Value: 1000},
+		)
+		worldRows = append(worldRows,
+			observationRow{ReporterISO: "KOR", PartnerISO: "WLD", Flow: flow, PeriodType: model.PeriodYear, Period: "2023", Value: 10000},
+			observationRow{ReporterISO: "JPN", PartnerISO: "WLD", Flow: flow, PeriodType: model.PeriodYear, Period: "2022", Value: 10000},
+		)
+	}
+
+	latest := buildLatest(rows, []string{"USA", "CHN"}, growthYoY, alignLatest, mixedAllow)
+	applyWorldShares(latest, worldRows)
+	jpn, kor := latest[0], latest[1]
+	if kor.USA.ShareOfTotal == nil || *kor.USA.ShareOfTotal != 0.2 || kor.CHN.ShareOfTotal == nil || *kor.CHN.ShareOfTotal != 0.3 {
+		t.Fatalf("KOR shares of total = %v %v", kor.USA.ShareOfTotal, kor.CHN.ShareOfTotal)
+	}
+	if got := kor.Partners["CHN"].ShareOfTotal; got == nil || *got != 0.3 {
+		t.Fatalf("KOR partners CHN share_of_total = %v", got)
+	}
+	if jpn.USA.ShareOfTotal != nil {
+		t.Fatalf("JPN share_of_total set without world trade in 2023: %v", *jpn.USA.ShareOfTotal)
+	}
+}
+
+func TestReportedTotalStandsInForMissingFlows(t *testing.T) {
+	rows := []observationRow{
+		{ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowTotal, PeriodType: model.PeriodYear, Period: "2022", Value: 4000},
+		{ReporterISO: "KOR", PartnerISO: "USA", Flow: model.FlowTotal, PeriodType: model.PeriodYear, Period: "2023", Value: 5000},
+		{ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", Value: 2000},
+		{ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowImport, PeriodType: model.PeriodYear, Period: "2023", Value: 1000},
+		{ReporterISO: "KOR", PartnerISO: "CHN", Flow: model.FlowTotal, PeriodType: model.PeriodYear, Period: "2023", Value: 9900},
+	}
+	worldRows := []observationRow{
+		{ReporterISO: "KOR", PartnerISO: "WLD", Flow: model.FlowTotal, PeriodType: model.PeriodYear, Period: "2023", Value: 20000},
+	}
+
+	latest := buildLatest(rows, []string{"USA", "CHN"}, growthYoY, alignLatest, mixedAllow)
+	applyWorldShares(latest, worldRows)
+	kor := latest[0]
+	if kor.USA.Period != "2023" || kor.USA.Trade != 50 || kor.USA.Export != 0 || kor.USA.Growth == nil || kor.USA.Growth.Trade == nil || *kor.USA.Growth.Trade != 0.25 {
+		t.Fatalf("USA block from reported totals = %+v", kor.USA)
+	}
+	if kor.CHN.Trade != 30 {
+		t.Fatalf("CHN trade = %v, want export plus import over the reported total", kor.CHN.Trade)
+	}
+	if kor.USA.ShareOfTotal == nil || *kor.USA.ShareOfTotal != 0.25 {
+		t.Fatalf("USA share_of_total = %v", kor.USA.ShareOfTotal)
+	}
+}
+
+func TestWorldTotalsKeepTheValuesProviderMergeKeeps(t *testing.T) {
+	row := func(provider string, periodType model.PeriodType, period string, flow model.Flow, value float64) observationRow {
+		return observationRow{Provider: provider, ReporterISO: "KOR", PartnerISO: worldPartner, Flow: flow, PeriodType: periodType, Period: period, Value: model.CentsFromUSD(value)}
+	}
+	rows := []observationRow{
+		row("comtrade", model.PeriodYear, "2023", model.FlowExport, 10),
+		row("wits", model.PeriodYear, "2023", model.FlowExport, 1),
+		row("comtrade", model.PeriodYear, "2023", model.FlowImport, 20),
+		row("comtrade", model.PeriodYear, "2022", model.FlowImport, 30),
+		row("wits", model.PeriodMonth, "2024-01", model.FlowExport, 40),
+	}
+	merge, err := parseProviderMerge("Y=wits/comtrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	world := newWorldTotals(merge)
+	world.add(rows[:2])
+	world.add(rows[2:])
+
+	want := map[model.Flow]map[string]float64{}
+	for _, row := range merge.apply(rows) {
+		if want[row.Flow] == nil {
+			want[row.Flow] = map[string]float64{}
+		}
+		want[row.Flow][seriesKey(row.PeriodType, row.Period)] = row.Value.USD()
+	}
+	got := world.series("KOR")
+	// wits wins 2023, so its missing import drops comtrade's rather than
+	// mixing providers, and months are not merged at all.
+	if len(got[model.FlowExport]) != 1 || got[model.FlowExport][seriesKey(model.PeriodYear, "2023")] != 1 || len(got[model.FlowImport]) != 1 || got[model.FlowImport][seriesKey(model.PeriodYear, "2022")] != 30 {
+		t.Fatalf("world series = %v", got)
+	}
+	for flow, values := range want {
+		for key, value := range values {
+			if got[flow][key] != value {
+				t.Fatalf("world series = %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestEnsureRequiredPartnersRejectsWorld(t *testing.T) {
+	if err := ensureRequiredPartners([]string{"USA", "CHN", "WLD"}, []string{"USA", "CHN"}); err == nil {
+		t.Fatal("ensureRequiredPartners() accepted WLD as a tracked partner")
+	}
+}
@@ -0,0 +1,169 @@
+package publisher
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cell style indexes in xlsxStyles.
+const (
+	xlsxStyleDefault = 0
+	xlsxStyleHeader  = 1
+	xlsxStyleAmount  = 2
+	xlsxStylePercent = 3
+)
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="0.0%"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+	`<fill><patternFill patternType="solid"><fgColor rgb="FFD9E1F2"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>` +
+	`<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// writeXLSX writes one worksheet per table. Headers are bold, shaded, and
+// frozen; USD amounts use a thousands format and shares and growth rates a
+// percent format.
+func writeXLSX(path string, tables []exportTable) error {
+	var body bytes.Buffer
+	archive := zip.NewWriter(&body)
+	var sheets, rels, overrides strings.Builder
+	for index, table := range tables {
+		sheetNumber := index + 1
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheetName(table.Name)), sheetNumber, sheetNumber)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, sheetNumber, sheetNumber)
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, sheetNumber)
+	}
+	stylesID := len(tables) + 1
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, stylesID)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			overrides.String() + `</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for index, table := range tables {
+		parts = append(parts, struct{ name, content string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", index+1), worksheetXML(table)})
+	}
+	for _, part := range parts {
+		writer, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return writeArtifact(path, body.Bytes())
+}
+
+func worksheetXML(table exportTable) string {
+	var out strings.Builder
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	out.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	if len(table.Columns) > 0 {
+		out.WriteString(`<cols>`)
+		for index, column := range table.Columns {
+			fmt.Fprintf(&out, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, index+1, index+1, max(10, len(column)+2))
+		}
+		out.WriteString(`</cols>`)
+	}
+	out.WriteString(`<sheetData><row r="1">`)
+	for index, column := range table.Columns {
+		fmt.Fprintf(&out, `<c r="%s1" t="inlineStr" s="%d"><is><t>%s</t></is></c>`, columnLetter(index), xlsxStyleHeader, xmlEscape(column))
+	}
+	out.WriteString(`</row>`)
+	for rowIndex, row := range table.Rows {
+		rowNumber := rowIndex + 2
+		fmt.Fprintf(&out, `<row r="%d">`, rowNumber)
+		for index, cell := range row {
+			ref := columnLetter(index) + strconv.Itoa(rowNumber)
+			switch value := cell.(type) {
+			case string:
+				if value != "" {
+					fmt.Fprintf(&out, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, xmlEscape(value))
+				}
+			case bool:
+				bit := 0
+				if value {
+					bit = 1
+				}
+				fmt.Fprintf(&out, `<c r="%s" t="b"><v>%d</v></c>`, ref, bit)
+			case float64, *float64, int, *int:
+				if text := formatCell(value); text != "" {
+					fmt.Fprintf(&out, `<c r="%s" s="%d"><v>%s</v></c>`, ref, numberStyle(table.Columns[index], value), text)
+				}
+			}
+		}
+		out.WriteString(`</row>`)
+	}
+	out.WriteString(`</sheetData></worksheet>`)
+	return out.String()
+}
+
+func numberStyle(column string, value any) int {
+	switch value.(type) {
+	case int, *int:
+		return xlsxStyleDefault
+	}
+	if strings.Contains(column, "share") || strings.Contains(column, "growth") {
+		return xlsxStylePercent
+	}
+	if column == "value" {
+		return xlsxStyleDefault
+	}
+	return xlsxStyleAmount
+}
+
+func sheetName(name string) string {
+	if name == "" {
+		return "Sheet"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func columnLetter(index int) string {
+	letters := ""
+	for index >= 0 {
+		letters = string(rune('A'+index%26)) + letters
+		index = index/26 - 1
+	}
+	return letters
+}
+
+func xmlEscape(value string) string {
+	var out strings.Builder
+	_ = xml.EscapeText(&out, []byte(value))
+	return out.String()
+}
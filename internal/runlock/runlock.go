@@ -0,0 +1,171 @@
+// Package runlock keeps overlapping runs of the collector and publisher off
+// the same SQLite store. A run holds a lockfile next to the database that
+// records who holds it; a later run waits for it or gives up, and a lockfile
+// left by a process that no longer exists is taken over.
+package runlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// pollInterval is how often a waiting run checks the lockfile again.
+var pollInterval = time.Second
+
+// Holder is the run recorded in a lockfile.
+type Holder struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (h Holder) String() string {
+	return fmt.Sprintf("pid %d on %s (%s, started %s)", h.PID, h.Host, h.Command, h.StartedAt.Format(time.RFC3339))
+}
+
+// HeldError reports a lock still held by another run when the wait ran out.
+type HeldError struct {
+	Path   string
+	Holder Holder
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("%s is held by %s", e.Path, e.Holder)
+}
+
+// Lock is a held lockfile.
+type Lock struct {
+	path string
+}
+
+var (
+	mu sync.Mutex
+	// held counts the acquisitions of each path by this process, so a
+	// command that runs another in-process, as the pipeline runs the
+	// publish step, does not wait on itself.
+	held = map[string]int{}
+)
+
+// Acquire takes the lockfile at path for command, retrying until wait has
+// passed. With a zero wait it tries once. It returns a *HeldError when
+// another live run keeps the lock.
+func Acquire(path string, wait time.Duration, command string) (*Lock, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	deadline := time.Now().Add(wait)
+	for {
+		// Checked on every pass, since another goroutine may have taken the
+		// lock while this one slept.
+		if held[path] > 0 {
+			held[path]++
+			return &Lock{path: path}, nil
+		}
+		err := create(path, command)
+		if err == nil {
+			held[path] = 1
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		holder, err := read(path)
+		if err != nil {
+			return nil, err
+		}
+		if stale(holder) {
+			// Move the lockfile aside before removing it, and put it back
+			// if another run took the lock over in the meantime.
+			aside := fmt.Sprintf("%s.stale-%d", path, os.Getpid())
+			if os.Rename(path, aside) == nil {
+				if moved, _ := read(aside); moved != holder {
+					os.Link(aside, path)
+				}
+				os.Remove(aside)
+			}
+			continue
+		}
+		if !time.Now().Before(deadline) {
+			return nil, &HeldError{Path: path, Holder: holder}
+		}
+		// mu is released while waiting so that the process's other locks
+		// can be taken and released meanwhile.
+		mu.Unlock()
+		time.Sleep(min(pollInterval, time.Until(deadline)))
+		mu.Lock()
+	}
+}
+
+// Release gives up the lock. Once every acquisition by this process is
+// released, the lockfile is removed.
+func (l *Lock) Release() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if held[l.path] == 0 {
+		return nil
+	}
+	held[l.path]--
+	if held[l.path] > 0 {
+		return nil
+	}
+	delete(held, l.path)
+	return os.Remove(l.path)
+}
+
+// create writes a lockfile for this process unless one exists. The holder
+// is written to a private file first and linked into place, so no run ever
+// reads a lockfile before its holder is in it.
+func create(path, command string) error {
+	host, _ := os.Hostname()
+	holder := Holder{PID: os.Getpid(), Host: host, Command: command, StartedAt: time.Now().UTC().Truncate(time.Second)}
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return err
+	}
+	private := fmt.Sprintf("%s.%d", path, os.Getpid())
+	if err := os.WriteFile(private, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	defer os.Remove(private)
+	return os.Link(private, path)
+}
+
+// read returns the holder recorded at path. A lockfile that is gone or
+// cannot be decoded reads as a zero holder, which is stale.
+func read(path string) (Holder, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Holder{}, nil
+	}
+	if err != nil {
+		return Holder{}, err
+	}
+	var holder Holder
+	if json.Unmarshal(data, &holder) != nil {
+		return Holder{}, nil
+	}
+	return holder, nil
+}
+
+// stale reports whether holder is a run on this host that has exited. A
+// holder on another host, as on a shared volume, is never stale, since its
+// process cannot be checked from here.
+func stale(holder Holder) bool {
+	if holder.PID <= 0 {
+		return true
+	}
+	if host, _ := os.Hostname(); holder.Host != host {
+		return false
+	}
+	process, err := os.FindProcess(holder.PID)
+	if err != nil {
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return errors.Is(err, os.ErrProcessDone) || errors.Is(err, syscall.ESRCH)
+}
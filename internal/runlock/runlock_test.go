@@ -0,0 +1,115 @@
+package runlock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireRefusesALiveHolderUntilReleased(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tradegravity.db.lock")
+	host, _ := os.Hostname()
+	// The test binary's parent is alive for the whole test.
+	writeHolder(t, path, Holder{PID: os.Getppid(), Host: host, Command: "collect run"})
+
+	pollInterval = 10 * time.Millisecond
+	started := time.Now()
+	_, err := Acquire(path, 50*time.Millisecond, "publish build")
+	var held *HeldError
+	if !errors.As(err, &held) || held.Holder.Command != "collect run" {
+		t.Fatalf("err = %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 50*time.Millisecond {
+		t.Fatalf("gave up after %s", elapsed)
+	}
+
+	os.Remove(path)
+	lock, err := Acquire(path, 0, "publish build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder, err := read(path)
+	if err != nil || holder.PID != os.Getpid() || holder.Command != "publish build" {
+		t.Fatalf("holder = %+v, %v", holder, err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("lockfile left after release: %v", err)
+	}
+}
+
+func TestAcquireTakesOverStaleLocksAndNestsInProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tradegravity.db.lock")
+	host, _ := os.Hostname()
+	writeHolder(t, path, Holder{PID: 1 << 30, Host: host, Command: "collect run"})
+
+	outer, err := Acquire(path, 0, "all")
+	if err != nil {
+		t.Fatalf("stale lock not taken over: %v", err)
+	}
+	inner, err := Acquire(path, 0, "publish build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner.Release()
+	if holder, _ := read(path); holder.Command != "all" {
+		t.Fatalf("inner release dropped the lock: %+v", holder)
+	}
+	outer.Release()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("lockfile left after release: %v", err)
+	}
+
+	writeHolder(t, path, Holder{PID: 1 << 30, Host: "other-host", Command: "collect run"})
+	if _, err := Acquire(path, 0, "all"); err == nil {
+		t.Fatal("took over a lock held on another host")
+	}
+}
+
+func TestAcquireDoesNotBlockOtherLocksWhileWaiting(t *testing.T) {
+	dir := t.TempDir()
+	busy := filepath.Join(dir, "busy.db.lock")
+	host, _ := os.Hostname()
+	writeHolder(t, busy, Holder{PID: os.Getppid(), Host: host, Command: "collect run"})
+
+	pollInterval = 10 * time.Millisecond
+	waited := make(chan error, 1)
+	go func() {
+		_, err := Acquire(busy, 500*time.Millisecond, "publish build")
+		waited <- err
+	}()
+	time.Sleep(30 * time.Millisecond)
+
+	lock, err := Acquire(filepath.Join(dir, "free.db.lock"), 0, "collect run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-waited:
+		t.Fatalf("another lock waited for the busy one to give up: %v", err)
+	default:
+	}
+	var held *HeldError
+	if err := <-waited; !errors.As(err, &held) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func writeHolder(t *testing.T, path string, holder Holder) {
+	t.Helper()
+	data, err := json.Marshal(holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
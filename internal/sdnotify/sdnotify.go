@@ -0,0 +1,82 @@
+// Package sdnotify implements the systemd service notification protocol
+// without libsystemd: a service started with Type=notify reports readiness
+// over the datagram socket in NOTIFY_SOCKET, and one with WatchdogSec= pings
+// it often enough that systemd can restart the service when it hangs.
+// Outside systemd every call is a no-op.
+package sdnotify
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notification states understood by systemd.
+const (
+	Ready    = "READY=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+// Notify sends state to the socket in NOTIFY_SOCKET and reports whether
+// one was set.
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	if strings.HasPrefix(socket, "@") {
+		// A leading @ names a socket in the abstract namespace.
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return true, err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return true, err
+}
+
+// WatchdogInterval returns the WatchdogSec= systemd set for this process
+// through WATCHDOG_USEC, or zero when the watchdog is off or meant for
+// another process.
+func WatchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// RunWatchdog pings the watchdog at half its interval until ctx is done,
+// skipping any ping for which healthy fails, so a service that stops
+// working stops pinging and systemd restarts it. healthy gets a context
+// that expires before the next ping is due. It returns at once when the
+// watchdog is off.
+func RunWatchdog(ctx context.Context, healthy func(ctx context.Context) error) {
+	interval := WatchdogInterval() / 2
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		check, cancel := context.WithTimeout(ctx, interval)
+		err := healthy(check)
+		cancel()
+		if err == nil {
+			Notify(Watchdog)
+		}
+	}
+}
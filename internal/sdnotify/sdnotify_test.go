@@ -0,0 +1,97 @@
+package sdnotify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// listen binds a notify socket and points NOTIFY_SOCKET at it.
+func listen(t *testing.T) *net.UnixConn {
+	t.Helper()
+	// Socket paths are limited to about 100 bytes, which t.TempDir can pass.
+	dir, err := os.MkdirTemp("", "sd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func receive(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 256)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotifySendsStateOnlyUnderSystemd(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := Notify(Ready); sent || err != nil {
+		t.Fatalf("sent = %v, %v without NOTIFY_SOCKET", sent, err)
+	}
+	conn := listen(t)
+	if sent, err := Notify(Ready); !sent || err != nil {
+		t.Fatalf("sent = %v, %v", sent, err)
+	}
+	if got := receive(t, conn); got != Ready {
+		t.Fatalf("state = %q", got)
+	}
+}
+
+func TestRunWatchdogPingsOnlyWhileHealthy(t *testing.T) {
+	conn := listen(t)
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("WATCHDOG_USEC", "20000")
+	if interval := WatchdogInterval(); interval != 0 {
+		t.Fatalf("interval = %s for another process", interval)
+	}
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	if interval := WatchdogInterval(); interval != 20*time.Millisecond {
+		t.Fatalf("interval = %s", interval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	checks := 0
+	done := make(chan struct{})
+	go func() {
+		RunWatchdog(ctx, func(context.Context) error {
+			checks++
+			if checks == 1 {
+				return errors.New("stalled")
+			}
+			if checks == 2 {
+				cancel()
+			}
+			return nil
+		})
+		close(done)
+	}()
+	if got := receive(t, conn); got != Watchdog {
+		t.Fatalf("state = %q", got)
+	}
+	<-done
+	if checks != 2 {
+		t.Fatalf("checks = %d", checks)
+	}
+	conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if n, err := conn.Read(make([]byte, 256)); err == nil {
+		t.Fatalf("unhealthy check still pinged (%d bytes)", n)
+	}
+}
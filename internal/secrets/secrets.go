@@ -0,0 +1,173 @@
+// Package secrets reads API keys and other credentials so they need not sit
+// in plain environment variables. A secret named KEY comes from, in order:
+//
+//   - the KEY environment variable;
+//   - the file named by KEY_FILE, such as a mounted Docker or Kubernetes
+//     secret, with surrounding whitespace trimmed.
+//
+// Either value may instead be a reference to AWS, resolved on first use:
+//
+//   - aws-sm:NAME or aws-sm:NAME#FIELD reads a Secrets Manager secret, and
+//     FIELD picks one string field of a JSON secret;
+//   - aws-ssm:/PATH reads a Systems Manager Parameter Store parameter,
+//     decrypting SecureString values.
+//
+// AWS requests are signed with AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and
+// AWS_SESSION_TOKEN (each also accepted as a _FILE) in AWS_REGION.
+// AWS_ENDPOINT_URL overrides the service endpoints.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"tradegravity/internal/awssig"
+)
+
+const (
+	defaultRegion  = "us-east-1"
+	lookupTimeout  = 30 * time.Second
+	secretsManager = "aws-sm:"
+	parameterStore = "aws-ssm:"
+)
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]string)
+)
+
+// Lookup returns the secret named key, or "" when neither key nor key_FILE
+// is set. Setting both is an error, so it is always clear which one is used.
+func Lookup(key string) (string, error) {
+	value, err := plain(key)
+	if err != nil || value == "" {
+		return value, err
+	}
+	reference := value
+	if !strings.HasPrefix(reference, secretsManager) && !strings.HasPrefix(reference, parameterStore) {
+		return value, nil
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if cached, ok := cache[reference]; ok {
+		return cached, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	value, err = resolve(ctx, reference)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	cache[reference] = value
+	return value, nil
+}
+
+// plain reads key or key_FILE without resolving references.
+func plain(key string) (string, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	path := strings.TrimSpace(os.Getenv(key + "_FILE"))
+	switch {
+	case value != "" && path != "":
+		return "", fmt.Errorf("both %s and %s_FILE are set", key, key)
+	case path != "":
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%s_FILE: %w", key, err)
+		}
+		return strings.TrimSpace(string(body)), nil
+	}
+	return value, nil
+}
+
+func resolve(ctx context.Context, reference string) (string, error) {
+	if name, ok := strings.CutPrefix(reference, parameterStore); ok {
+		var response struct {
+			Parameter struct {
+				Value string `json:"Value"`
+			} `json:"Parameter"`
+		}
+		if err := call(ctx, "ssm", "AmazonSSM.GetParameter", map[string]any{"Name": name, "WithDecryption": true}, &response); err != nil {
+			return "", fmt.Errorf("read parameter %s: %w", name, err)
+		}
+		return strings.TrimSpace(response.Parameter.Value), nil
+	}
+	name, field, _ := strings.Cut(strings.TrimPrefix(reference, secretsManager), "#")
+	var response struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := call(ctx, "secretsmanager", "secretsmanager.GetSecretValue", map[string]any{"SecretId": name}, &response); err != nil {
+		return "", fmt.Errorf("read secret %s: %w", name, err)
+	}
+	if field == "" {
+		return strings.TrimSpace(response.SecretString), nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(response.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object, so it has no field %q", name, field)
+	}
+	value, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s has no string field %q", name, field)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// call sends one JSON request to an AWS service and decodes the response.
+func call(ctx context.Context, service, target string, input, output any) error {
+	region := strings.TrimSpace(os.Getenv("AWS_REGION"))
+	if region == "" {
+		region = defaultRegion
+	}
+	var creds awssig.Credentials
+	var err error
+	if creds.AccessKey, err = plain("AWS_ACCESS_KEY_ID"); err != nil {
+		return err
+	}
+	if creds.SecretKey, err = plain("AWS_SECRET_ACCESS_KEY"); err != nil {
+		return err
+	}
+	if creds.SessionToken, err = plain("AWS_SESSION_TOKEN"); err != nil {
+		return err
+	}
+	if creds.AccessKey == "" || creds.SecretKey == "" {
+		return errors.New("missing AWS credentials (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	endpoint := strings.TrimRight(strings.TrimSpace(os.Getenv("AWS_ENDPOINT_URL")), "/")
+	if endpoint == "" {
+		endpoint = "https://" + service + "." + region + ".amazonaws.com"
+	}
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	awssig.Sign(req, service, region, awssig.PayloadHash(body), creds, time.Now())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	detail, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return json.Unmarshal(detail, output)
+}
@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLookupReadsEnvironmentAndFiles(t *testing.T) {
+	t.Setenv("TEST_KEY", " from-env ")
+	if value, err := Lookup("TEST_KEY"); err != nil || value != "from-env" {
+		t.Fatalf("Lookup(env) = %q, %v", value, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_KEY_FILE", path)
+	if _, err := Lookup("TEST_KEY"); err == nil || !strings.Contains(err.Error(), "both") {
+		t.Fatalf("Lookup with both set error = %v", err)
+	}
+	t.Setenv("TEST_KEY", "")
+	if value, err := Lookup("TEST_KEY"); err != nil || value != "from-file" {
+		t.Fatalf("Lookup(file) = %q, %v", value, err)
+	}
+	if value, err := Lookup("TEST_UNSET_KEY"); err != nil || value != "" {
+		t.Fatalf("Lookup(unset) = %q, %v", value, err)
+	}
+}
+
+func TestLookupResolvesAWSReferences(t *testing.T) {
+	var targets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targets = append(targets, r.Header.Get("X-Amz-Target"))
+		if !strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/") {
+			http.Error(w, "unsigned", http.StatusForbidden)
+			return
+		}
+		var input map[string]any
+		json.NewDecoder(r.Body).Decode(&input)
+		switch r.Header.Get("X-Amz-Target") {
+		case "secretsmanager.GetSecretValue":
+			if input["SecretId"] != "tradegravity/comtrade" {
+				http.Error(w, `{"__type":"ResourceNotFoundException"}`, http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"primary":"sm-primary"}`})
+		case "AmazonSSM.GetParameter":
+			if input["WithDecryption"] != true {
+				http.Error(w, "not decrypted", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"Parameter": map[string]string{"Value": "ssm-" + input["Name"].(string)}})
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ENDPOINT_URL", srv.URL)
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	t.Setenv("TEST_SM_KEY", "aws-sm:tradegravity/comtrade#primary")
+	for range 2 {
+		if value, err := Lookup("TEST_SM_KEY"); err != nil || value != "sm-primary" {
+			t.Fatalf("Lookup(aws-sm) = %q, %v", value, err)
+		}
+	}
+	t.Setenv("TEST_SSM_KEY", "aws-ssm:/tradegravity/wits")
+	if value, err := Lookup("TEST_SSM_KEY"); err != nil || value != "ssm-/tradegravity/wits" {
+		t.Fatalf("Lookup(aws-ssm) = %q, %v", value, err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("requests = %v, want the secret fetched once", targets)
+	}
+	t.Setenv("TEST_SM_KEY", "aws-sm:tradegravity/comtrade#missing")
+	if _, err := Lookup("TEST_SM_KEY"); err == nil || !strings.Contains(err.Error(), `no string field "missing"`) {
+		t.Fatalf("Lookup(missing field) error = %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// CORS is the cross-origin policy applied by WithCORS. AllowedOrigins holds
+// exact origins such as https://example.org, or "*" for any origin.
+type CORS struct {
+	AllowedOrigins []string
+	AllowedMethods []string
+}
+
+// DefaultCORSMethods are the methods New answers.
+const DefaultCORSMethods = "GET,HEAD"
+
+// exposedHeaders are the response headers cross-origin scripts may read
+// besides the CORS-safelisted ones.
+const exposedHeaders = "ETag, Last-Modified, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After"
+
+// ParseCORS builds a policy from comma-separated origin and method lists. An
+// empty origin list disables CORS.
+func ParseCORS(origins, methods string) (CORS, error) {
+	var policy CORS
+	for _, item := range strings.Split(origins, ",") {
+		origin := strings.TrimRight(strings.TrimSpace(item), "/")
+		if origin == "" {
+			continue
+		}
+		if origin != "*" {
+			parsed, err := url.Parse(origin)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || parsed.Path != "" || parsed.RawQuery != "" {
+				return CORS{}, fmt.Errorf("invalid origin %q (expected * or scheme://host[:port])", item)
+			}
+			origin = strings.ToLower(origin)
+		}
+		if !slices.Contains(policy.AllowedOrigins, origin) {
+			policy.AllowedOrigins = append(policy.AllowedOrigins, origin)
+		}
+	}
+	for _, item := range strings.Split(methods, ",") {
+		method := strings.ToUpper(strings.TrimSpace(item))
+		if method == "" {
+			continue
+		}
+		if strings.ContainsAny(method, " \t/:;") {
+			return CORS{}, fmt.Errorf("invalid method %q", item)
+		}
+		if !slices.Contains(policy.AllowedMethods, method) {
+			policy.AllowedMethods = append(policy.AllowedMethods, method)
+		}
+	}
+	if len(policy.AllowedOrigins) > 0 && len(policy.AllowedMethods) == 0 {
+		return CORS{}, fmt.Errorf("allowed origins need at least one method")
+	}
+	return policy, nil
+}
+
+func (c CORS) enabled() bool {
+	return len(c.AllowedOrigins) > 0
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for a request
+// origin, or "" when the origin is not allowed.
+func (c CORS) allowOrigin(origin string) string {
+	if slices.Contains(c.AllowedOrigins, "*") {
+		return "*"
+	}
+	if origin != "" && slices.Contains(c.AllowedOrigins, strings.ToLower(origin)) {
+		return origin
+	}
+	return ""
+}
+
+// WithCORS adds the policy's CORS headers to next. Preflight requests from
+// an allowed origin are answered with 204 No Content; requests from other
+// origins get no CORS headers, so browsers block the response. ETag and
+// Last-Modified are exposed so cross-origin clients can revalidate, and the
+// RateLimit headers and Retry-After so they can pace themselves.
+func WithCORS(next http.Handler, policy CORS) http.Handler {
+	if !policy.enabled() {
+		return next
+	}
+	methods := strings.Join(policy.AllowedMethods, ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := policy.allowOrigin(origin)
+		if allowed != "*" {
+			w.Header().Add("Vary", "Origin")
+		}
+		if allowed == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", allowed)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCORSNormalizesLists(t *testing.T) {
+	policy, err := ParseCORS(" https://Example.org/ ,http://localhost:5173,https://example.org", "get, head,GET")
+	if err != nil {
+		t.Fatalf("ParseCORS: %v", err)
+	}
+	want := CORS{AllowedOrigins: []string{"https://example.org", "http://localhost:5173"}, AllowedMethods: []string{"GET", "HEAD"}}
+	if !reflect.DeepEqual(policy, want) {
+		t.Fatalf("policy = %+v, want %+v", policy, want)
+	}
+	for _, origins := range []string{"example.org", "https://example.org/data", "ftp://example.org"} {
+		if _, err := ParseCORS(origins, DefaultCORSMethods); err == nil {
+			t.Fatalf("expected %q to be rejected", origins)
+		}
+	}
+	if _, err := ParseCORS("*", ""); err == nil {
+		t.Fatal("expected an error for origins without methods")
+	}
+	if policy, err := ParseCORS("", DefaultCORSMethods); err != nil || policy.enabled() {
+		t.Fatalf("empty origins = %+v, %v; want CORS disabled", policy, err)
+	}
+}
+
+func TestWithCORSAllowsConfiguredOrigins(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "latest.json"), []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := WithCORS(files, CORS{AllowedOrigins: []string{"https://viewer.example"}, AllowedMethods: []string{"GET", "HEAD"}})
+
+	request := httptest.NewRequest(http.MethodGet, "/latest.json", nil)
+	request.Header.Set("Origin", "https://viewer.example")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Access-Control-Allow-Origin") != "https://viewer.example" || recorder.Header().Get("Vary") != "Origin" {
+		t.Fatalf("allowed GET: status = %d headers = %v", recorder.Code, recorder.Header())
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/latest.json", nil)
+	request.Header.Set("Origin", "https://other.example")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("other origin: status = %d allow origin = %q", recorder.Code, recorder.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	request = httptest.NewRequest(http.MethodOptions, "/latest.json", nil)
+	request.Header.Set("Origin", "https://viewer.example")
+	request.Header.Set("Access-Control-Request-Method", "GET")
+	request.Header.Set("Access-Control-Request-Headers", "if-none-match")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNoContent || recorder.Header().Get("Access-Control-Allow-Methods") != "GET, HEAD" || recorder.Header().Get("Access-Control-Allow-Headers") != "if-none-match" {
+		t.Fatalf("preflight: status = %d headers = %v", recorder.Code, recorder.Header())
+	}
+
+	request = httptest.NewRequest(http.MethodOptions, "/latest.json", nil)
+	request.Header.Set("Origin", "https://other.example")
+	request.Header.Set("Access-Control-Request-Method", "GET")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("preflight from another origin: status = %d, want the server's 405", recorder.Code)
+	}
+
+	wildcard := WithCORS(files, CORS{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+	recorder = httptest.NewRecorder()
+	wildcard.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/latest.json", nil))
+	if recorder.Header().Get("Access-Control-Allow-Origin") != "*" || recorder.Header().Get("Vary") != "" {
+		t.Fatalf("wildcard headers = %v", recorder.Header())
+	}
+
+	limited := WithCORS(WithRateLimit(files, RateLimit{Requests: 1, Window: time.Minute}, nil, nil), CORS{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+	limited.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/latest.json", nil))
+	recorder = httptest.NewRecorder()
+	limited.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/latest.json", nil))
+	exposed := recorder.Header().Get("Access-Control-Expose-Headers")
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Access-Control-Allow-Origin") != "*" || !strings.Contains(exposed, "RateLimit-Remaining") || !strings.Contains(exposed, "Retry-After") {
+		t.Fatalf("rate-limited response: status = %d headers = %v", recorder.Code, recorder.Header())
+	}
+}
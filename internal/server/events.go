@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EventsPath is the server-sent events stream answered by WithEvents.
+const EventsPath = "/events"
+
+// Events configures WithEvents. The stream checks DataDir's meta.json, and
+// LatestIngest when set, every Interval; LatestIngest returns the newest
+// ingested_at in the store.
+type Events struct {
+	DataDir      string
+	Interval     time.Duration
+	LatestIngest func(ctx context.Context) (string, error)
+}
+
+// eventState is what the stream compares between checks.
+type eventState struct {
+	GeneratedAt string `json:"generated_at,omitempty"`
+	IngestedAt  string `json:"ingested_at,omitempty"`
+}
+
+// WithEvents serves a text/event-stream on EventsPath so dashboards can
+// refresh when data changes instead of polling meta.json. Each stream opens
+// with a "state" event carrying the current generated_at and ingested_at,
+// then sends "publish" when meta.json gets a new generated_at and
+// "observations" when the store's newest ingested_at moves. A comment line
+// is sent every 30 seconds to keep proxies from closing an idle stream.
+func WithEvents(next http.Handler, events Events) http.Handler {
+	if events.Interval <= 0 {
+		events.Interval = 5 * time.Second
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != EventsPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		ctx := r.Context()
+		state := events.state(ctx)
+		fmt.Fprintf(w, "retry: %d\n\n", events.Interval.Milliseconds())
+		writeEvent(w, "state", state)
+		flusher.Flush()
+
+		check := time.NewTicker(events.Interval)
+		defer check.Stop()
+		keepAlive := time.NewTicker(30 * time.Second)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			case <-check.C:
+				current := events.state(ctx)
+				if current.GeneratedAt != "" && current.GeneratedAt != state.GeneratedAt {
+					writeEvent(w, "publish", current)
+				}
+				if current.IngestedAt != "" && current.IngestedAt != state.IngestedAt {
+					writeEvent(w, "observations", current)
+				}
+				state = current
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+// state reads the current publication and ingest times. A value that cannot
+// be read is left empty and does not produce an event.
+func (e Events) state(ctx context.Context) eventState {
+	var state eventState
+	if generatedAt, err := publishedAt(e.DataDir); err == nil {
+		state.GeneratedAt = generatedAt.UTC().Format(time.RFC3339)
+	}
+	if e.LatestIngest != nil {
+		if ingestedAt, err := e.LatestIngest(ctx); err == nil {
+			state.IngestedAt = ingestedAt
+		}
+	}
+	return state
+}
+
+func writeEvent(w http.ResponseWriter, name string, state eventState) {
+	data, _ := json.Marshal(state)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+}
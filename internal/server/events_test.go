@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithEventsStreamsPublishesAndIngests(t *testing.T) {
+	dir := t.TempDir()
+	writeMeta := func(generatedAt string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "meta.json"), []byte(`{"generated_at":"`+generatedAt+`"}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeMeta("2026-01-01T00:00:00Z")
+	var mu sync.Mutex
+	ingestedAt := "2026-01-01T00:00:00Z"
+	latestIngest := func(context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return ingestedAt, nil
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	srv := httptest.NewServer(WithEvents(next, Events{DataDir: dir, Interval: 10 * time.Millisecond, LatestIngest: latestIngest}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+EventsPath, nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if got := response.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("content type = %q", got)
+	}
+	lines := bufio.NewScanner(response.Body)
+	nextEvent := func() string {
+		t.Helper()
+		var event []string
+		for lines.Scan() {
+			line := lines.Text()
+			if line == "" {
+				if len(event) > 0 && !strings.HasPrefix(event[0], "retry:") {
+					return strings.Join(event, "\n")
+				}
+				event = nil
+				continue
+			}
+			event = append(event, line)
+		}
+		t.Fatalf("stream ended: %v", lines.Err())
+		return ""
+	}
+
+	if got, want := nextEvent(), "event: state\ndata: {\"generated_at\":\"2026-01-01T00:00:00Z\",\"ingested_at\":\"2026-01-01T00:00:00Z\"}"; got != want {
+		t.Fatalf("first event = %q, want %q", got, want)
+	}
+	mu.Lock()
+	ingestedAt = "2026-01-02T00:00:00Z"
+	mu.Unlock()
+	if got := nextEvent(); !strings.HasPrefix(got, "event: observations\n") || !strings.Contains(got, `"ingested_at":"2026-01-02T00:00:00Z"`) {
+		t.Fatalf("ingest event = %q", got)
+	}
+	writeMeta("2026-01-03T00:00:00Z")
+	if got := nextEvent(); !strings.HasPrefix(got, "event: publish\n") || !strings.Contains(got, `"generated_at":"2026-01-03T00:00:00Z"`) {
+		t.Fatalf("publish event = %q", got)
+	}
+
+	other, err := http.Get(srv.URL + "/meta.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Body.Close()
+	if other.StatusCode != http.StatusTeapot {
+		t.Fatalf("other paths must reach the wrapped handler, status = %d", other.StatusCode)
+	}
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsPath is where Metrics.Wrap serves the Prometheus text format.
+const MetricsPath = "/metrics"
+
+// MetricsBackend receives request and store query timings. Metrics keeps
+// them for Prometheus to scrape and StatsD pushes them to an agent.
+type MetricsBackend interface {
+	ObserveRequest(handler, method, code string, elapsed time.Duration)
+	ObserveQuery(query string, elapsed time.Duration)
+}
+
+// MultiMetrics sends every observation to each backend.
+type MultiMetrics []MetricsBackend
+
+func (m MultiMetrics) ObserveRequest(handler, method, code string, elapsed time.Duration) {
+	for _, backend := range m {
+		backend.ObserveRequest(handler, method, code, elapsed)
+	}
+}
+
+func (m MultiMetrics) ObserveQuery(query string, elapsed time.Duration) {
+	for _, backend := range m {
+		backend.ObserveQuery(query, elapsed)
+	}
+}
+
+// metricBuckets are the histogram upper bounds in seconds.
+var metricBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics counts requests and times requests and store queries for
+// Prometheus. Request metrics are labelled by handler rather than path, so
+// per-country artifacts do not create a series each.
+type Metrics struct {
+	started time.Time
+
+	mu       sync.Mutex
+	requests map[requestKey]uint64
+	latency  map[string]*histogram
+	queries  map[string]*histogram
+}
+
+type requestKey struct {
+	handler, method, code string
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(seconds float64) {
+	for index, bound := range metricBuckets {
+		if seconds <= bound {
+			h.counts[index]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// NewMetrics returns an empty registry.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		started:  time.Now(),
+		requests: make(map[requestKey]uint64),
+		latency:  make(map[string]*histogram),
+		queries:  make(map[string]*histogram),
+	}
+}
+
+// ObserveQuery records how long the named store query took.
+func (m *Metrics) ObserveQuery(name string, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	observe(m.queries, name, elapsed)
+}
+
+// ObserveRequest records one request to the given handler label.
+func (m *Metrics) ObserveRequest(handler, method, code string, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{handler: handler, method: method, code: code}]++
+	observe(m.latency, handler, elapsed)
+}
+
+func observe(histograms map[string]*histogram, name string, elapsed time.Duration) {
+	h := histograms[name]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(metricBuckets))}
+		histograms[name] = h
+	}
+	h.observe(elapsed.Seconds())
+}
+
+// Wrap serves MetricsPath and records every other request to next.
+func (m *Metrics) Wrap(next http.Handler) http.Handler {
+	instrumented := Instrument(next, m)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == MetricsPath {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			m.write(w)
+			return
+		}
+		instrumented.ServeHTTP(w, r)
+	})
+}
+
+// Instrument records every request to next with backend, labelled by
+// handler as Metrics labels them.
+func Instrument(next http.Handler, backend MetricsBackend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		backend.ObserveRequest(metricHandler(r.URL.Path), r.Method, strconv.Itoa(recorder.status), time.Since(start))
+	})
+}
+
+// apiVersionPattern matches the /v1, /v2, ... API prefixes. Versions are
+// single digits so a client cannot create unbounded label values.
+var apiVersionPattern = regexp.MustCompile(`^v[1-9]$`)
+
+// metricHandler groups a request path into a bounded handler label.
+func metricHandler(path string) string {
+	switch path {
+	case HealthPath, ReadyPath, VersionPath, EventsPath, RebuildPath:
+		return path
+	}
+	if version, _, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/"); ok && apiVersionPattern.MatchString(version) {
+		return "/" + version
+	}
+	return "static"
+}
+
+func (m *Metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP tradegravity_http_requests_total HTTP requests by handler, method, and status code.")
+	fmt.Fprintln(w, "# TYPE tradegravity_http_requests_total counter")
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].handler != keys[j].handler {
+			return keys[i].handler < keys[j].handler
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "tradegravity_http_requests_total{handler=%q,method=%q,code=%q} %d\n", key.handler, key.method, key.code, m.requests[key])
+	}
+	writeHistograms(w, "tradegravity_http_request_duration_seconds", "HTTP request latency by handler.", "handler", m.latency)
+	writeHistograms(w, "tradegravity_db_query_duration_seconds", "Store query latency by query.", "query", m.queries)
+	fmt.Fprintln(w, "# HELP tradegravity_server_start_time_seconds Unix time the server started.")
+	fmt.Fprintln(w, "# TYPE tradegravity_server_start_time_seconds gauge")
+	fmt.Fprintf(w, "tradegravity_server_start_time_seconds %d\n", m.started.Unix())
+}
+
+func writeHistograms(w io.Writer, name, help, label string, histograms map[string]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	values := make([]string, 0, len(histograms))
+	for value := range histograms {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		h := histograms[value]
+		for index, bound := range metricBuckets {
+			fmt.Fprintf(w, "%s_bucket{%s=%q,le=%q} %d\n", name, label, value, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[index])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s=%q,le=\"+Inf\"} %d\n", name, label, value, h.count)
+		fmt.Fprintf(w, "%s_sum{%s=%q} %s\n", name, label, value, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count{%s=%q} %d\n", name, label, value, h.count)
+	}
+}
+
+// statusRecorder remembers the status code written through it. It forwards
+// Flush so server-sent events still stream.
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.written {
+		r.status, r.written = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(body []byte) (int, error) {
+	r.written = true
+	return r.ResponseWriter.Write(body)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		r.written = true
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsWrapCountsRequests(t *testing.T) {
+	metrics := NewMetrics()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest.json":
+			w.Write([]byte("{}"))
+		case EventsPath:
+			w.(http.Flusher).Flush()
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	handler := metrics.Wrap(next)
+	for _, path := range []string{"/latest.json", "/countries/USA.json", "/missing.json", HealthPath, EventsPath, "/v1/aggregate?group=world", "/v2/latest", "/v10/latest"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	metrics.ObserveQuery("ping", 3*time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	body := recorder.Body.String()
+	for _, want := range []string{
+		`tradegravity_http_requests_total{handler="static",method="GET",code="200"} 1`,
+		`tradegravity_http_requests_total{handler="static",method="GET",code="404"} 3`,
+		`tradegravity_http_requests_total{handler="/healthz",method="GET",code="404"} 1`,
+		`tradegravity_http_requests_total{handler="/events",method="GET",code="200"} 1`,
+		`tradegravity_http_requests_total{handler="/v1",method="GET",code="404"} 1`,
+		`tradegravity_http_requests_total{handler="/v2",method="GET",code="404"} 1`,
+		`tradegravity_http_request_duration_seconds_bucket{handler="static",le="+Inf"} 4`,
+		`tradegravity_http_request_duration_seconds_count{handler="static"} 4`,
+		`tradegravity_db_query_duration_seconds_bucket{query="ping",le="0.001"} 0`,
+		`tradegravity_db_query_duration_seconds_bucket{query="ping",le="0.005"} 1`,
+		`tradegravity_db_query_duration_seconds_sum{query="ping"} 0.003`,
+		"tradegravity_server_start_time_seconds ",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("metrics missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "USA") || strings.Contains(body, MetricsPath) {
+		t.Fatalf("metrics should not label by path:\n%s", body)
+	}
+}
+
+func TestStatusRecorderForwardsFlush(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	status := &statusRecorder{ResponseWriter: recorder, status: http.StatusOK}
+	var writer http.ResponseWriter = status
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		t.Fatalf("statusRecorder should implement http.Flusher")
+	}
+	flusher.Flush()
+	if !recorder.Flushed {
+		t.Fatalf("expected Flush to reach the underlying writer")
+	}
+	if status.status != http.StatusOK {
+		t.Fatalf("expected a flushed response to count as 200, got %d", status.status)
+	}
+
+	status = &statusRecorder{ResponseWriter: httptest.NewRecorder(), status: http.StatusOK}
+	status.WriteHeader(http.StatusTeapot)
+	status.WriteHeader(http.StatusOK)
+	if status.status != http.StatusTeapot {
+		t.Fatalf("expected first status to be kept, got %d", status.status)
+	}
+}
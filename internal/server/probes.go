@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tradegravity/internal/buildinfo"
+)
+
+// Probe endpoints answered by WithProbes.
+const (
+	HealthPath  = "/healthz"
+	ReadyPath   = "/readyz"
+	VersionPath = "/version"
+)
+
+// Probes configures WithProbes. MaxAge, when positive, makes the server
+// unready once the published meta.json is older than that. Store, when set,
+// must succeed for the server to be ready.
+type Probes struct {
+	DataDir string
+	MaxAge  time.Duration
+	Store   func(ctx context.Context) error
+	now     func() time.Time
+}
+
+// readyReport is the /readyz response body.
+type readyReport struct {
+	Status      string            `json:"status"`
+	GeneratedAt string            `json:"generated_at,omitempty"`
+	Checks      map[string]string `json:"checks"`
+}
+
+// versionReport is the /version response body, from buildinfo.
+type versionReport struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"revision_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// WithProbes answers the health, readiness, and version endpoints ahead of
+// next, so load balancers and orchestrators are never rate limited. /healthz
+// reports that the process is up; /readyz also checks the published data and
+// the store and returns 503 Service Unavailable when either fails.
+func WithProbes(next http.Handler, probes Probes) http.Handler {
+	if probes.now == nil {
+		probes.now = time.Now
+	}
+	version := buildVersion()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case HealthPath:
+			w.Header().Set("Cache-Control", "no-store")
+			writeProbeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		case ReadyPath:
+			report := probes.ready(r.Context())
+			status := http.StatusOK
+			if report.Status != "ready" {
+				status = http.StatusServiceUnavailable
+			}
+			w.Header().Set("Cache-Control", "no-store")
+			writeProbeJSON(w, status, report)
+		case VersionPath:
+			writeProbeJSON(w, http.StatusOK, version)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func (p Probes) ready(ctx context.Context) readyReport {
+	report := readyReport{Status: "ready", Checks: map[string]string{}}
+	generatedAt, err := publishedAt(p.DataDir)
+	switch {
+	case err != nil:
+		report.Checks["data"] = err.Error()
+	case p.MaxAge > 0 && p.now().Sub(generatedAt) > p.MaxAge:
+		report.Checks["data"] = fmt.Sprintf("published %s ago, over the %s limit", p.now().Sub(generatedAt).Round(time.Second), p.MaxAge)
+	default:
+		report.Checks["data"] = "ok"
+	}
+	if err == nil {
+		report.GeneratedAt = generatedAt.UTC().Format(time.RFC3339)
+	}
+	if p.Store != nil {
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		if err := p.Store(ctx); err != nil {
+			report.Checks["store"] = err.Error()
+		} else {
+			report.Checks["store"] = "ok"
+		}
+	}
+	for _, check := range report.Checks {
+		if check != "ok" {
+			report.Status = "unavailable"
+		}
+	}
+	return report
+}
+
+// publishedAt reads generated_at from the meta.json of the build in dataDir.
+func publishedAt(dataDir string) (time.Time, error) {
+	body, err := os.ReadFile(filepath.Join(dataDir, "meta.json"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("meta.json is not readable")
+	}
+	var meta struct {
+		GeneratedAt string `json:"generated_at"`
+	}
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return time.Time{}, fmt.Errorf("meta.json is not valid JSON")
+	}
+	generatedAt, err := time.Parse(time.RFC3339, meta.GeneratedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("meta.json has no valid generated_at")
+	}
+	return generatedAt, nil
+}
+
+func buildVersion() versionReport {
+	info := buildinfo.Get()
+	return versionReport{
+		Version:   info.Version,
+		Revision:  info.Commit,
+		Time:      info.CommitTime,
+		Modified:  info.Modified,
+		BuildDate: info.Date,
+		GoVersion: info.GoVersion,
+	}
+}
+
+func writeProbeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithProbesReportsReadiness(t *testing.T) {
+	dir := t.TempDir()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	now := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	var storeErr error
+	handler := WithProbes(next, Probes{
+		DataDir: dir,
+		MaxAge:  48 * time.Hour,
+		Store:   func(context.Context) error { return storeErr },
+		now:     func() time.Time { return now },
+	})
+	ready := func() (int, readyReport) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadyPath, nil))
+		var report readyReport
+		if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+			t.Fatalf("decode /readyz: %v", err)
+		}
+		return recorder.Code, report
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("/healthz status = %d", recorder.Code)
+	}
+	if code, report := ready(); code != http.StatusServiceUnavailable || report.Checks["data"] != "meta.json is not readable" || report.Checks["store"] != "ok" {
+		t.Fatalf("/readyz without data = %d %+v", code, report)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), []byte(`{"generated_at":"2026-01-02T00:00:00Z"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, report := ready(); code != http.StatusOK || report.Status != "ready" || report.GeneratedAt != "2026-01-02T00:00:00Z" {
+		t.Fatalf("/readyz with fresh data = %d %+v", code, report)
+	}
+
+	now = now.Add(48 * time.Hour)
+	if code, report := ready(); code != http.StatusServiceUnavailable || report.Checks["data"] != "published 72h0m0s ago, over the 48h0m0s limit" {
+		t.Fatalf("/readyz with stale data = %d %+v", code, report)
+	}
+
+	now, storeErr = now.Add(-48*time.Hour), errors.New("database is locked")
+	if code, report := ready(); code != http.StatusServiceUnavailable || report.Checks["store"] != "database is locked" {
+		t.Fatalf("/readyz with a failing store = %d %+v", code, report)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, VersionPath, nil))
+	var version versionReport
+	if err := json.Unmarshal(recorder.Body.Bytes(), &version); err != nil || recorder.Code != http.StatusOK || version.GoVersion == "" {
+		t.Fatalf("/version = %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/meta.json", nil))
+	if recorder.Code != http.StatusTeapot {
+		t.Fatalf("other paths must reach the wrapped handler, status = %d", recorder.Code)
+	}
+}
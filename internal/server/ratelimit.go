@@ -0,0 +1,211 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit allows each client Requests requests per Window. A client is the
+// remote IP, or the bearer token of the Authorization header when that token
+// is on the limiter's allowlist. Behind a trusted proxy the remote IP is read
+// from X-Forwarded-For. The zero value does not limit.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseRateLimit parses a limit such as 120/m, 10/s, or 5000/h. An empty
+// value disables rate limiting.
+func ParseRateLimit(value string) (RateLimit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return RateLimit{}, nil
+	}
+	count, unit, ok := strings.Cut(value, "/")
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if !ok || err != nil || requests <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q (expected requests/unit, e.g. 120/m)", value)
+	}
+	windows := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}
+	window, ok := windows[strings.ToLower(strings.TrimSpace(unit))]
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit unit %q (expected s, m, or h)", unit)
+	}
+	return RateLimit{Requests: requests, Window: window}, nil
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Requests > 0 && l.Window > 0
+}
+
+// rateWindow is one client's fixed window.
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// rateLimiter counts requests per client in fixed windows. Windows that have
+// ended are swept once per window, so idle clients do not accumulate.
+type rateLimiter struct {
+	limit   RateLimit
+	now     func() time.Time
+	tokens  map[string]bool
+	proxies []netip.Prefix
+
+	mu      sync.Mutex
+	clients map[string]*rateWindow
+	swept   time.Time
+}
+
+// take records a request from client and returns how many requests remain in
+// its window, when the window resets, and whether the request is allowed.
+func (l *rateLimiter) take(client string) (remaining int, reset time.Time, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if now.Sub(l.swept) >= l.limit.Window {
+		for key, window := range l.clients {
+			if now.Sub(window.start) >= l.limit.Window {
+				delete(l.clients, key)
+			}
+		}
+		l.swept = now
+	}
+	window := l.clients[client]
+	if window == nil || now.Sub(window.start) >= l.limit.Window {
+		window = &rateWindow{start: now}
+		l.clients[client] = window
+	}
+	reset = window.start.Add(l.limit.Window)
+	if window.count >= l.limit.Requests {
+		return 0, reset, false
+	}
+	window.count++
+	return l.limit.Requests - window.count, reset, true
+}
+
+// WithRateLimit limits requests per client and reports the client's budget
+// in the RateLimit-Limit, RateLimit-Remaining, and RateLimit-Reset headers.
+// Requests over the limit get 429 Too Many Requests with Retry-After. A
+// bearer token in tokens gets a budget of its own wherever it connects from;
+// any other token counts against the remote IP, since tokens are not
+// verified and a client could otherwise mint fresh budgets. X-Forwarded-For
+// is honored only on connections from proxies, which ParseTrustedProxies
+// reads.
+func WithRateLimit(next http.Handler, limit RateLimit, tokens []string, proxies []netip.Prefix) http.Handler {
+	if !limit.enabled() {
+		return next
+	}
+	limiter := &rateLimiter{limit: limit, now: time.Now, clients: make(map[string]*rateWindow), tokens: make(map[string]bool, len(tokens)), proxies: proxies}
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			limiter.tokens[token] = true
+		}
+	}
+	return rateLimited(next, limiter)
+}
+
+// ParseRateLimitTokens splits a comma-separated list of bearer tokens.
+func ParseRateLimitTokens(value string) []string {
+	var tokens []string
+	for _, token := range strings.Split(value, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// ParseTrustedProxies parses a comma-separated list of CIDRs, such as
+// 10.0.0.0/8, or single addresses, which stand for themselves.
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(item); err == nil {
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q (expected a CIDR such as 10.0.0.0/8 or an address)", item)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+func rateLimited(next http.Handler, limiter *rateLimiter) http.Handler {
+	policy := fmt.Sprintf("%d;w=%d", limiter.limit.Requests, int(limiter.limit.Window/time.Second))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remaining, reset, ok := limiter.take(limiter.client(r))
+		seconds := int(reset.Sub(limiter.now()).Round(time.Second) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(limiter.limit.Requests))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds))
+		w.Header().Set("RateLimit-Policy", policy)
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// client identifies the client of r: its bearer token when the token is
+// allowlisted, or its IP. The IP is the remote address unless that is a
+// trusted proxy; then X-Forwarded-For is read from the right, past any other
+// trusted proxies, to the first address a trusted proxy saw connect.
+// Entries to the left of it are ignored because any client can set them.
+func (l *rateLimiter) client(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && l.tokens[strings.TrimSpace(token)] {
+		return "token:" + strings.TrimSpace(token)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !l.trusted(host) {
+		return "ip:" + host
+	}
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for index := len(forwarded) - 1; index >= 0; index-- {
+		hop := strings.TrimSpace(forwarded[index])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+		host = hop
+		if !l.trusted(hop) {
+			break
+		}
+	}
+	return "ip:" + host
+}
+
+// trusted reports whether host is the address of a trusted proxy.
+func (l *rateLimiter) trusted(host string) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, proxy := range l.proxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	limit, err := ParseRateLimit(" 120/M ")
+	if err != nil || limit != (RateLimit{Requests: 120, Window: time.Minute}) {
+		t.Fatalf("ParseRateLimit = %+v, %v", limit, err)
+	}
+	if limit, err := ParseRateLimit(""); err != nil || limit.enabled() {
+		t.Fatalf("empty limit = %+v, %v; want disabled", limit, err)
+	}
+	for _, value := range []string{"120", "0/m", "10/d", "x/s"} {
+		if _, err := ParseRateLimit(value); err == nil {
+			t.Fatalf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestRateLimitedCountsPerClient(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := &rateLimiter{limit: RateLimit{Requests: 2, Window: time.Minute}, now: func() time.Time { return now }, clients: make(map[string]*rateWindow), tokens: map[string]bool{"research-key": true}}
+	handler := rateLimited(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), limiter)
+	serve := func(remoteAddr, token string) *httptest.ResponseRecorder {
+		t.Helper()
+		request := httptest.NewRequest(http.MethodGet, "/latest.json", nil)
+		request.RemoteAddr = remoteAddr
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := serve("192.0.2.1:1000", "")
+	if first.Code != http.StatusOK || first.Header().Get("RateLimit-Limit") != "2" || first.Header().Get("RateLimit-Remaining") != "1" || first.Header().Get("RateLimit-Reset") != "60" {
+		t.Fatalf("first request: status = %d headers = %v", first.Code, first.Header())
+	}
+	now = now.Add(15 * time.Second)
+	if second := serve("192.0.2.1:2000", ""); second.Code != http.StatusOK || second.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("second request from another port: status = %d headers = %v", second.Code, second.Header())
+	}
+	third := serve("192.0.2.1:1000", "")
+	if third.Code != http.StatusTooManyRequests || third.Header().Get("Retry-After") != "45" {
+		t.Fatalf("third request: status = %d headers = %v", third.Code, third.Header())
+	}
+	if other := serve("192.0.2.1:1000", "research-key"); other.Code != http.StatusOK {
+		t.Fatalf("allowlisted token shares the IP's budget: status = %d", other.Code)
+	}
+	if minted := serve("192.0.2.1:1000", "minted-key"); minted.Code != http.StatusTooManyRequests {
+		t.Fatalf("unlisted token got a budget of its own: status = %d", minted.Code)
+	}
+	if other := serve("198.51.100.7:1000", ""); other.Code != http.StatusOK {
+		t.Fatalf("another IP was limited: status = %d", other.Code)
+	}
+
+	now = now.Add(45 * time.Second)
+	if reset := serve("192.0.2.1:1000", ""); reset.Code != http.StatusOK || reset.Header().Get("RateLimit-Remaining") != "1" {
+		t.Fatalf("after the window: status = %d headers = %v", reset.Code, reset.Header())
+	}
+	now = now.Add(time.Minute)
+	serve("192.0.2.1:1000", "")
+	if len(limiter.clients) != 1 {
+		t.Fatalf("clients = %d, want ended windows swept", len(limiter.clients))
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies(" 10.1.2.3/8, 192.0.2.7 ,,2001:db8::/32")
+	if err != nil || len(proxies) != 3 || proxies[0].String() != "10.0.0.0/8" || proxies[1].String() != "192.0.2.7/32" || proxies[2].String() != "2001:db8::/32" {
+		t.Fatalf("ParseTrustedProxies = %v, %v", proxies, err)
+	}
+	if proxies, err := ParseTrustedProxies(""); err != nil || len(proxies) != 0 {
+		t.Fatalf("empty list = %v, %v", proxies, err)
+	}
+	if _, err := ParseTrustedProxies("10.0.0.0/8,proxy.internal"); err == nil {
+		t.Fatal("expected a hostname to be rejected")
+	}
+}
+
+func TestRateLimiterReadsForwardedForFromTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := &rateLimiter{proxies: proxies}
+	client := func(remoteAddr string, forwarded ...string) string {
+		request := httptest.NewRequest(http.MethodGet, "/latest.json", nil)
+		request.RemoteAddr = remoteAddr
+		for _, value := range forwarded {
+			request.Header.Add("X-Forwarded-For", value)
+		}
+		return limiter.client(request)
+	}
+
+	if got := client("192.0.2.1:1000", "198.51.100.7"); got != "ip:192.0.2.1" {
+		t.Fatalf("untrusted peer: client = %q, want its own address", got)
+	}
+	if got := client("10.0.0.5:1000", "198.51.100.7"); got != "ip:198.51.100.7" {
+		t.Fatalf("trusted proxy: client = %q", got)
+	}
+	if got := client("10.0.0.5:1000", "203.0.113.9, 198.51.100.7", "10.0.0.6"); got != "ip:198.51.100.7" {
+		t.Fatalf("spoofed entry and proxy chain: client = %q, want the address the proxy saw", got)
+	}
+	if got := client("10.0.0.5:1000", "unknown"); got != "ip:10.0.0.5" {
+		t.Fatalf("malformed header: client = %q, want the proxy's address", got)
+	}
+	if got := client("10.0.0.5:1000"); got != "ip:10.0.0.5" {
+		t.Fatalf("no header: client = %q", got)
+	}
+}
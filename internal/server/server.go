@@ -0,0 +1,201 @@
+// Package server serves a published data directory over HTTP.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// New returns a handler that serves the published artifacts in dataDir. JSON
+// artifacts are served with an explicit content type so clients do not depend
+// on the host's MIME table. Artifacts listed in the build's index.json get an
+// ETag from their sha256 and a Last-Modified from their generated_at, so CDNs
+// and browsers can revalidate with If-None-Match or If-Modified-Since and
+// get 304 Not Modified until a build changes the file.
+func New(dataDir string) (http.Handler, error) {
+	info, err := os.Stat(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &os.PathError{Op: "serve", Path: dataDir, Err: os.ErrInvalid}
+	}
+	files := http.FileServer(http.Dir(dataDir))
+	index := &artifactIndex{path: filepath.Join(dataDir, indexName)}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if contentType, ok := contentTypes[path.Ext(r.URL.Path)]; ok {
+			w.Header().Set("Content-Type", contentType)
+		}
+		if serveIndexed(w, r, dataDir, index) {
+			return
+		}
+		files.ServeHTTP(w, r)
+	}), nil
+}
+
+// indexName is the artifact manifest the publisher writes into every build.
+const indexName = "index.json"
+
+// artifactValidator is the cache validator pair for one published artifact.
+type artifactValidator struct {
+	etag     string
+	modified time.Time
+	size     int64
+}
+
+// artifactIndex caches the validators from index.json and reloads them when
+// a rebuild replaces the file.
+type artifactIndex struct {
+	path string
+
+	mu         sync.Mutex
+	stamp      time.Time
+	stampSize  int64
+	validators map[string]artifactValidator
+}
+
+func (a *artifactIndex) lookup(name string) (artifactValidator, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	info, err := os.Stat(a.path)
+	if err != nil {
+		a.validators, a.stamp, a.stampSize = nil, time.Time{}, 0
+		return artifactValidator{}, false
+	}
+	if a.validators == nil || !info.ModTime().Equal(a.stamp) || info.Size() != a.stampSize {
+		a.validators = loadValidators(a.path)
+		a.stamp, a.stampSize = info.ModTime(), info.Size()
+	}
+	validator, ok := a.validators[name]
+	return validator, ok
+}
+
+// loadValidators reads index.json. A file that is missing or cannot be
+// decoded yields no validators, and artifacts are served without them.
+func loadValidators(indexPath string) map[string]artifactValidator {
+	validators := make(map[string]artifactValidator)
+	body, err := os.ReadFile(indexPath)
+	if err != nil {
+		return validators
+	}
+	var index struct {
+		GeneratedAt string `json:"generated_at"`
+		Files       []struct {
+			Path        string `json:"path"`
+			Size        int64  `json:"size"`
+			SHA256      string `json:"sha256"`
+			GeneratedAt string `json:"generated_at"`
+		} `json:"files"`
+	}
+	if json.Unmarshal(body, &index) != nil {
+		return validators
+	}
+	for _, file := range index.Files {
+		if file.SHA256 == "" {
+			continue
+		}
+		generatedAt := file.GeneratedAt
+		if generatedAt == "" {
+			generatedAt = index.GeneratedAt
+		}
+		modified, _ := time.Parse(time.RFC3339, generatedAt)
+		validators[path.Clean(file.Path)] = artifactValidator{etag: `"` + file.SHA256 + `"`, modified: modified, size: file.Size}
+	}
+	return validators
+}
+
+// serveIndexed serves an artifact listed in index.json with its validators
+// and reports whether it did. A file whose size no longer matches the index,
+// such as one a running build is rewriting, falls back to the plain file
+// server so a stale ETag is never sent.
+func serveIndexed(w http.ResponseWriter, r *http.Request, dataDir string, index *artifactIndex) bool {
+	name := path.Clean("/" + r.URL.Path)[1:]
+	validator, ok := index.lookup(name)
+	if !ok {
+		return false
+	}
+	file, err := os.Open(filepath.Join(dataDir, filepath.FromSlash(name)))
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Size() != validator.size {
+		return false
+	}
+	w.Header().Set("ETag", validator.etag)
+	http.ServeContent(w, r, name, validator.modified, file)
+	return true
+}
+
+// ContentType returns the content type served for an artifact name, falling
+// back to application/octet-stream.
+func ContentType(name string) string {
+	if contentType, ok := contentTypes[path.Ext(name)]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// contentTypes covers every artifact extension the publisher writes.
+var contentTypes = map[string]string{
+	".json":    "application/json; charset=utf-8",
+	".csv":     "text/csv; charset=utf-8",
+	".ndjson":  "application/x-ndjson",
+	".parquet": "application/vnd.apache.parquet",
+	".png":     "image/png",
+	".svg":     "image/svg+xml",
+	".xlsx":    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// RebuildPath is the preview endpoint that regenerates the data directory.
+const RebuildPath = "/_rebuild"
+
+// Preview wraps New for local frontend work. Every response allows
+// cross-origin reads and is not cached, and POST RebuildPath runs rebuild and
+// returns its output. Rebuilds are serialized.
+func Preview(dataDir string, rebuild func() ([]byte, error)) (http.Handler, error) {
+	files, err := New(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, OPTIONS")
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path != RebuildPath {
+			files.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		mu.Lock()
+		output, err := rebuild()
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "rebuild failed: %v\n", err)
+		}
+		w.Write(output)
+	}), nil
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewServesPublishedJSON(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), []byte(`{"schema_version":"2.0"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	handler, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/meta.json", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Fatalf("content type = %q", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/meta.json", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d", recorder.Code)
+	}
+}
+
+func TestNewRevalidatesIndexedArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	body := []byte(`{"schema_version":"2.0"}`)
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), body, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(body)
+	index := fmt.Sprintf(`{"generated_at":"2026-01-02T03:04:05Z","files":[{"path":"meta.json","size":%d,"sha256":"%x","generated_at":"2026-01-01T00:00:00Z"}]}`, len(body), sum)
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	handler, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/meta.json", nil))
+	etag := recorder.Header().Get("ETag")
+	if recorder.Code != http.StatusOK || etag != fmt.Sprintf(`"%x"`, sum) {
+		t.Fatalf("status = %d etag = %q", recorder.Code, etag)
+	}
+	if got := recorder.Header().Get("Last-Modified"); got != "Thu, 01 Jan 2026 00:00:00 GMT" {
+		t.Fatalf("last modified = %q, want the artifact's generated_at", got)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Fatalf("content type = %q", got)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/meta.json", nil)
+	request.Header.Set("If-None-Match", etag)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNotModified || recorder.Body.Len() != 0 {
+		t.Fatalf("If-None-Match status = %d body = %q", recorder.Code, recorder.Body.String())
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/meta.json", nil)
+	request.Header.Set("If-Modified-Since", "Fri, 02 Jan 2026 00:00:00 GMT")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since status = %d", recorder.Code)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "meta.json"), []byte(`{"schema_version":"2.1","x":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	request = httptest.NewRequest(http.MethodGet, "/meta.json", nil)
+	request.Header.Set("If-None-Match", etag)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("ETag") != "" {
+		t.Fatalf("rewritten file status = %d etag = %q, want a full response without the stale ETag", recorder.Code, recorder.Header().Get("ETag"))
+	}
+}
+
+func TestNewRejectsMissingDirectory(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("expected missing data directory to be rejected")
+	}
+}
+
+func TestPreviewAllowsCORSAndRebuilds(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "latest.csv"), []byte("iso3\nDEU\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rebuilds := 0
+	handler, err := Preview(dir, func() ([]byte, error) {
+		rebuilds++
+		return []byte("built\n"), nil
+	})
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/latest.csv", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+		t.Fatalf("content type = %q", got)
+	}
+	if got := recorder.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("allow origin = %q", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodOptions, "/latest.csv", nil))
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("OPTIONS status = %d", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, RebuildPath, nil))
+	if recorder.Code != http.StatusMethodNotAllowed || rebuilds != 0 {
+		t.Fatalf("GET rebuild status = %d rebuilds = %d", recorder.Code, rebuilds)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, RebuildPath, nil))
+	if recorder.Code != http.StatusOK || rebuilds != 1 || recorder.Body.String() != "built\n" {
+		t.Fatalf("POST rebuild status = %d rebuilds = %d body = %q", recorder.Code, rebuilds, recorder.Body.String())
+	}
+}
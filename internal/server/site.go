@@ -0,0 +1,60 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+//go:embed ui
+var embeddedUI embed.FS
+
+// EmbeddedSite is the Site value that serves the built-in minimal viewer
+// instead of a site directory.
+const EmbeddedSite = "embedded"
+
+// DataPrefix is where WithSite mounts the published data, matching the
+// ./data/ paths the site fetches.
+const DataPrefix = "/data/"
+
+// WithSite serves the static site at siteDir on / and data, the handler for
+// the published artifacts, under DataPrefix, so one server can host the
+// whole dashboard. siteDir may be EmbeddedSite for a minimal built-in page
+// that lists latest.json.
+func WithSite(data http.Handler, siteDir string) (http.Handler, error) {
+	var site fs.FS
+	if siteDir == EmbeddedSite {
+		ui, err := fs.Sub(embeddedUI, "ui")
+		if err != nil {
+			return nil, err
+		}
+		site = ui
+	} else {
+		info, err := os.Stat(siteDir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, &os.PathError{Op: "serve", Path: siteDir, Err: os.ErrInvalid}
+		}
+		site = os.DirFS(siteDir)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", allowRead(http.FileServerFS(site)))
+	mux.Handle(DataPrefix, http.StripPrefix(DataPrefix[:len(DataPrefix)-1], data))
+	return mux, nil
+}
+
+// allowRead limits the site's file server to GET and HEAD, as New does for
+// the data.
+func allowRead(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithSiteServesSiteAndData(t *testing.T) {
+	siteDir, dataDir := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(siteDir, "index.html"), []byte("<!doctype html><title>site</title>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "meta.json"), []byte(`{"schema_version":"2.0"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := New(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := WithSite(data, siteDir)
+	if err != nil {
+		t.Fatalf("WithSite() error = %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "<title>site</title>") {
+		t.Fatalf("site index: status = %d body = %q", recorder.Code, recorder.Body.String())
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/data/meta.json", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("data: status = %d headers = %v", recorder.Code, recorder.Header())
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/index.html", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST site status = %d", recorder.Code)
+	}
+
+	if _, err := WithSite(data, filepath.Join(siteDir, "missing")); err == nil {
+		t.Fatal("expected a missing site directory to be rejected")
+	}
+}
+
+func TestWithSiteServesEmbeddedViewer(t *testing.T) {
+	data, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := WithSite(data, EmbeddedSite)
+	if err != nil {
+		t.Fatalf("WithSite() error = %v", err)
+	}
+	for _, check := range [][2]string{
+		{"/", `<script src="./ui.js"></script>`},
+		{"/", `<svg id="top-chart"`},
+		{"/ui.js", `fetch("./data/latest.json")`},
+	} {
+		path, want := check[0], check[1]
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), want) {
+			t.Fatalf("%s: status = %d, missing %q", path, recorder.Code, want)
+		}
+	}
+}
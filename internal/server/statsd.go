@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsD pushes request and store query metrics to a StatsD or Datadog agent
+// over UDP. Labels are sent as DogStatsD tags (|#key:value), which the
+// Datadog agent, Telegraf, and the Prometheus statsd_exporter read. Sends
+// are fire-and-forget: a missing agent never slows or fails a request.
+type StatsD struct {
+	conn net.Conn
+	tags []string
+}
+
+// NewStatsD sends to the agent at address, such as 127.0.0.1:8125, adding
+// tags, such as env:prod, to every metric.
+func NewStatsD(address string, tags []string) (*StatsD, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid statsd address %q (expected host:port)", address)
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsD{conn: conn, tags: tags}, nil
+}
+
+// ParseStatsDTags parses comma-separated key:value tags. An empty value
+// adds no tags.
+func ParseStatsDTags(value string) ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if strings.ContainsAny(tag, "|#@ ") {
+			return nil, fmt.Errorf("invalid statsd tag %q", tag)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// ObserveRequest sends tradegravity.http.requests and
+// tradegravity.http.request_duration for one request.
+func (s *StatsD) ObserveRequest(handler, method, code string, elapsed time.Duration) {
+	tags := s.withTags("handler:"+handler, "method:"+method, "code:"+code)
+	s.send("tradegravity.http.requests:1|c" + tags + "\n" +
+		"tradegravity.http.request_duration:" + milliseconds(elapsed) + "|ms" + tags)
+}
+
+// ObserveQuery sends tradegravity.db.query_duration for one store query.
+func (s *StatsD) ObserveQuery(query string, elapsed time.Duration) {
+	s.send("tradegravity.db.query_duration:" + milliseconds(elapsed) + "|ms" + s.withTags("query:"+query))
+}
+
+// Close closes the UDP socket.
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+func (s *StatsD) withTags(tags ...string) string {
+	all := append(append([]string(nil), s.tags...), tags...)
+	for index, tag := range all {
+		all[index] = strings.NewReplacer(",", "_", "|", "_", "\n", "_").Replace(tag)
+	}
+	return "|#" + strings.Join(all, ",")
+}
+
+func (s *StatsD) send(packet string) {
+	s.conn.Write([]byte(packet))
+}
+
+func milliseconds(elapsed time.Duration) string {
+	return strconv.FormatFloat(float64(elapsed)/float64(time.Millisecond), 'f', -1, 64)
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsDSendsTaggedRequestAndQueryMetrics(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer agent.Close()
+	tags, err := ParseStatsDTags(" env:prod, ,service:tradegravity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statsd, err := NewStatsD(agent.LocalAddr().String(), tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer statsd.Close()
+
+	prometheus := NewMetrics()
+	handler := Instrument(http.NotFoundHandler(), MultiMetrics{prometheus, statsd})
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/countries/USA.json", nil))
+	statsd.ObserveQuery("ping", 1500*time.Microsecond)
+
+	agent.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buffer := make([]byte, 1024)
+	var packets []string
+	for range 2 {
+		n, _, err := agent.ReadFrom(buffer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets = append(packets, string(buffer[:n]))
+	}
+	lines := strings.Split(packets[0], "\n")
+	if len(lines) != 2 || lines[0] != "tradegravity.http.requests:1|c|#env:prod,service:tradegravity,handler:static,method:GET,code:404" || !strings.HasPrefix(lines[1], "tradegravity.http.request_duration:") {
+		t.Fatalf("request packet = %q", packets[0])
+	}
+	if packets[1] != "tradegravity.db.query_duration:1.5|ms|#env:prod,service:tradegravity,query:ping" {
+		t.Fatalf("query packet = %q", packets[1])
+	}
+	if prometheus.requests[requestKey{handler: "static", method: "GET", code: "404"}] != 1 {
+		t.Fatalf("prometheus requests = %v, want the request counted by both backends", prometheus.requests)
+	}
+}
+
+func TestParseStatsDTagsRejectsProtocolCharacters(t *testing.T) {
+	for _, value := range []string{"env:prod|x", "team:a b", "#env"} {
+		if _, err := ParseStatsDTags(value); err == nil {
+			t.Fatalf("ParseStatsDTags(%q) accepted an invalid tag", value)
+		}
+	}
+	if _, err := NewStatsD("localhost", nil); err == nil {
+		t.Fatal("NewStatsD accepted an address without a port")
+	}
+}
@@ -0,0 +1,137 @@
+"use strict";
+
+// A minimal viewer for a published build: two summary charts and one
+// sortable row per reporter in latest.json. The full dashboard lives in site/.
+(async function () {
+  const meta = document.getElementById("meta");
+  const body = document.getElementById("rows");
+  const usd = new Intl.NumberFormat("en", { style: "currency", currency: "USD", notation: "compact", maximumFractionDigits: 1 });
+  const svgNS = "http://www.w3.org/2000/svg";
+  const topCount = 15;
+  let rows = [];
+  let sortKey = "share_cn";
+  let descending = true;
+
+  function value(row, key) {
+    if (key === "period") return row.comparison_period || row.usa?.period || "";
+    if (key === "usa" || key === "chn") return row[key]?.trade ?? 0;
+    return row[key] ?? "";
+  }
+
+  function svgElement(name, attributes, text) {
+    const element = document.createElementNS(svgNS, name);
+    for (const [key, attribute] of Object.entries(attributes)) element.setAttribute(key, attribute);
+    if (text !== undefined) element.textContent = text;
+    return element;
+  }
+
+  // drawTop stacks USA and China trade for the reporters with the largest
+  // combined trade.
+  function drawTop() {
+    const svg = document.getElementById("top-chart");
+    const top = rows.filter(row => row.total > 0).sort((a, b) => b.total - a.total).slice(0, topCount);
+    const label = 48, width = 360, barHeight = 14, gap = 4;
+    const scale = (width - label - 56) / Math.max(1, ...top.map(row => row.total));
+    svg.setAttribute("viewBox", `0 0 ${width} ${Math.max(1, top.length) * (barHeight + gap)}`);
+    svg.replaceChildren(...top.flatMap((row, index) => {
+      const y = index * (barHeight + gap);
+      const usaWidth = value(row, "usa") * scale;
+      const chnWidth = value(row, "chn") * scale;
+      const bar = (className, x, barWidth, partner) => {
+        const rect = svgElement("rect", { class: className, x, y, width: barWidth, height: barHeight });
+        rect.append(svgElement("title", {}, `${row.iso3} ${partner}: ${usd.format(value(row, className))}`));
+        return rect;
+      };
+      return [
+        svgElement("text", { class: "axis", x: 0, y: y + barHeight - 3 }, row.iso3),
+        bar("usa", label, usaWidth, "USA"),
+        bar("chn", label + usaWidth, chnWidth, "China"),
+        svgElement("text", { class: "axis", x: label + usaWidth + chnWidth + 4, y: y + barHeight - 3 }, usd.format(row.total)),
+      ];
+    }));
+  }
+
+  // drawShares counts reporters in each 10-point band of China share.
+  function drawShares() {
+    const svg = document.getElementById("share-chart");
+    const bins = new Array(10).fill(0);
+    for (const row of rows) {
+      if (typeof row.share_cn === "number") bins[Math.min(9, Math.floor(row.share_cn * 10))]++;
+    }
+    const width = 360, height = 180, axis = 16, slot = width / bins.length;
+    const scale = (height - axis - 14) / Math.max(1, ...bins);
+    svg.setAttribute("viewBox", `0 0 ${width} ${height}`);
+    svg.replaceChildren(...bins.flatMap((count, index) => {
+      const barHeight = count * scale;
+      const x = index * slot;
+      const y = height - axis - barHeight;
+      const rect = svgElement("rect", { class: "bin", x: x + 2, y, width: slot - 4, height: barHeight });
+      rect.append(svgElement("title", {}, `${index * 10}–${index * 10 + 10}%: ${count} reporters`));
+      return [
+        rect,
+        svgElement("text", { class: "axis", x: x + slot / 2, y: y - 3, "text-anchor": "middle" }, count ? String(count) : ""),
+        svgElement("text", { class: "axis", x: x + slot / 2, y: height - 3, "text-anchor": "middle" }, `${index * 10}%`),
+      ];
+    }));
+  }
+
+  function render() {
+    const sorted = rows.slice().sort((a, b) => {
+      const left = value(a, sortKey);
+      const right = value(b, sortKey);
+      const order = typeof left === "number" && typeof right === "number" ? left - right : String(left).localeCompare(String(right));
+      return descending ? -order : order;
+    });
+    for (const th of document.querySelectorAll("th[data-key]")) {
+      if (th.dataset.key === sortKey) th.setAttribute("aria-sort", descending ? "descending" : "ascending");
+      else th.removeAttribute("aria-sort");
+    }
+    body.replaceChildren(...sorted.map(row => {
+      const tr = document.createElement("tr");
+      const cells = [
+        `${row.iso3}${row.name ? " · " + row.name : ""}`,
+        row.region || "",
+        value(row, "period"),
+        usd.format(value(row, "usa")),
+        usd.format(value(row, "chn")),
+        usd.format(row.total || 0),
+      ];
+      for (const [index, text] of cells.entries()) {
+        const td = document.createElement("td");
+        td.textContent = text;
+        if (index >= 3) td.className = "num";
+        tr.append(td);
+      }
+      const share = document.createElement("td");
+      share.className = "num";
+      const bar = document.createElement("span");
+      bar.className = "bar";
+      bar.style.width = `${Math.round((row.share_cn || 0) * 80)}px`;
+      share.append(bar, `${((row.share_cn || 0) * 100).toFixed(1)}%`);
+      tr.append(share);
+      return tr;
+    }));
+  }
+
+  for (const th of document.querySelectorAll("th[data-key]")) {
+    th.addEventListener("click", () => {
+      descending = th.dataset.key === sortKey ? !descending : true;
+      sortKey = th.dataset.key;
+      render();
+    });
+  }
+
+  try {
+    const [metaResponse, latestResponse] = await Promise.all([fetch("./data/meta.json"), fetch("./data/latest.json")]);
+    if (!metaResponse.ok || !latestResponse.ok) throw new Error("meta.json or latest.json is not published");
+    const info = await metaResponse.json();
+    rows = (await latestResponse.json()).rows || [];
+    meta.textContent = `Provider ${info.provider} · ${info.reporter_count} reporters · dominant period ${info.dominant_period || "n/a"} · generated ${info.generated_at}`;
+    drawTop();
+    drawShares();
+    render();
+  } catch (error) {
+    meta.textContent = `Could not load published data: ${error.message}`;
+    meta.className = "error";
+  }
+})();
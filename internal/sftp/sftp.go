@@ -0,0 +1,173 @@
+// Package sftp uploads files over SFTP by driving the OpenSSH sftp client in
+// batch mode, so keys, agents, known hosts, and ~/.ssh/config work as they
+// do for the user, and no SSH library is needed.
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const defaultCommand = "sftp"
+
+// Config selects the sftp binary and the key to log in with. An empty
+// IdentityFile leaves the choice to ssh.
+type Config struct {
+	Command      string
+	IdentityFile string
+}
+
+// ConfigFromEnv reads SFTP_COMMAND, the sftp binary (default sftp), and
+// SFTP_IDENTITY_FILE, the private key.
+func ConfigFromEnv() Config {
+	return Config{
+		Command:      getenv("SFTP_COMMAND", defaultCommand),
+		IdentityFile: strings.TrimSpace(os.Getenv("SFTP_IDENTITY_FILE")),
+	}
+}
+
+// Client streams uploads to one sftp session. Put stages a file and queues
+// its upload; the session runs the queue in order and stops at the first
+// failure, so nothing queued after a failed upload is sent. Close waits for
+// the queue and reports that failure.
+type Client struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  bytes.Buffer
+	staging string
+
+	mu     sync.Mutex
+	files  int
+	dirs   map[string]bool
+	closed bool
+}
+
+// New starts an sftp session to authority, as user@host or user@host:port.
+func New(cfg Config, authority string) (*Client, error) {
+	if strings.TrimSpace(authority) == "" {
+		return nil, errors.New("sftp host is required")
+	}
+	if cfg.Command == "" {
+		cfg.Command = defaultCommand
+	}
+	// BatchMode makes ssh fail instead of prompting for a password or an
+	// unknown host key.
+	args := []string{"-b", "-", "-o", "BatchMode=yes"}
+	destination := authority
+	if host, port, err := net.SplitHostPort(authority); err == nil {
+		destination = host
+		args = append(args, "-P", port)
+	}
+	if cfg.IdentityFile != "" {
+		args = append(args, "-i", cfg.IdentityFile)
+	}
+	args = append(args, destination)
+	staging, err := os.MkdirTemp("", "tradegravity-sftp-")
+	if err != nil {
+		return nil, err
+	}
+	client := &Client{cmd: exec.Command(cfg.Command, args...), staging: staging, dirs: map[string]bool{}}
+	client.cmd.Stderr = &client.stderr
+	if client.stdin, err = client.cmd.StdinPipe(); err != nil {
+		os.RemoveAll(staging)
+		return nil, err
+	}
+	if err := client.cmd.Start(); err != nil {
+		os.RemoveAll(staging)
+		return nil, fmt.Errorf("start %s: %w", cfg.Command, err)
+	}
+	return client, nil
+}
+
+// Put queues body for upload to key. A key starting with ~/ is relative to
+// the login directory; any other key is absolute. The content type and
+// cache control are left to the server that serves the files.
+func (c *Client) Put(ctx context.Context, key string, body []byte, contentType, cacheControl string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	remote := remotePath(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errors.New("sftp session is closed")
+	}
+	c.files++
+	local := filepath.Join(c.staging, strconv.Itoa(c.files))
+	if err := os.WriteFile(local, body, 0o600); err != nil {
+		return err
+	}
+	var batch strings.Builder
+	for _, dir := range parents(remote) {
+		if !c.dirs[dir] {
+			c.dirs[dir] = true
+			// A leading - lets the batch go on when the directory exists.
+			fmt.Fprintf(&batch, "-mkdir %s\n", quote(dir))
+		}
+	}
+	fmt.Fprintf(&batch, "put %s %s\n", quote(local), quote(remote))
+	if _, err := io.WriteString(c.stdin, batch.String()); err != nil {
+		return fmt.Errorf("queue %s: %w", key, err)
+	}
+	return nil
+}
+
+// Close ends the session once the queued uploads ran, and removes the
+// staged files.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+	defer os.RemoveAll(c.staging)
+	c.stdin.Close()
+	if err := c.cmd.Wait(); err != nil {
+		if detail := strings.TrimSpace(c.stderr.String()); detail != "" {
+			return fmt.Errorf("sftp upload: %w: %s", err, detail)
+		}
+		return fmt.Errorf("sftp upload: %w", err)
+	}
+	return nil
+}
+
+func remotePath(key string) string {
+	if rest, ok := strings.CutPrefix(key, "~/"); ok {
+		return rest
+	}
+	return "/" + strings.TrimPrefix(key, "/")
+}
+
+// parents lists the directories above remote, outermost first.
+func parents(remote string) []string {
+	var dirs []string
+	for dir := path.Dir(remote); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// quote makes value one sftp batch argument.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func getenv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
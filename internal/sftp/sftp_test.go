@@ -0,0 +1,101 @@
+package sftp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeSFTP writes a script that records its arguments and batch, and runs
+// the batch's put commands as copies into root.
+func fakeSFTP(t *testing.T, root string, exitCode int) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "batch.log")
+	script := filepath.Join(dir, "sftp")
+	body := `#!/bin/sh
+echo "$@" > "` + log + `.args"
+while IFS= read -r line; do
+	echo "$line" >> "` + log + `"
+	case "$line" in
+	put*) eval "set -- $line"; mkdir -p "` + root + `$(dirname "$3")"; cp "$2" "` + root + `$3" ;;
+	esac
+done
+exit ` + strconv.Itoa(exitCode) + `
+`
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return script, log
+}
+
+func TestClientQueuesDirectoriesAndPutsInOrder(t *testing.T) {
+	root := t.TempDir()
+	script, log := fakeSFTP(t, root, 0)
+	client, err := New(Config{Command: script, IdentityFile: "/keys/deploy"}, "deploy@web.example.com:2222")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"srv/data/countries/KOR.json", "srv/data/meta.json"} {
+		if err := client.Put(context.Background(), key, []byte(key), "application/json", "no-cache"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	args, _ := os.ReadFile(log + ".args")
+	if strings.TrimSpace(string(args)) != "-b - -o BatchMode=yes -P 2222 -i /keys/deploy deploy@web.example.com" {
+		t.Fatalf("args = %q", args)
+	}
+	batch, _ := os.ReadFile(log)
+	lines := strings.Split(strings.TrimSpace(string(batch)), "\n")
+	want := []string{`-mkdir "/srv"`, `-mkdir "/srv/data"`, `-mkdir "/srv/data/countries"`, "put", "put"}
+	if len(lines) != len(want) {
+		t.Fatalf("batch = %q", lines)
+	}
+	for index, prefix := range want {
+		if !strings.HasPrefix(lines[index], prefix) {
+			t.Fatalf("batch = %q, want line %d to start with %q", lines, index, prefix)
+		}
+	}
+	if !strings.HasSuffix(lines[4], `"/srv/data/meta.json"`) {
+		t.Fatalf("last put = %q", lines[4])
+	}
+	copied, err := os.ReadFile(filepath.Join(root, "srv/data/countries/KOR.json"))
+	if err != nil || string(copied) != "srv/data/countries/KOR.json" {
+		t.Fatalf("uploaded body = %q, %v", copied, err)
+	}
+	if _, err := os.Stat(client.staging); !os.IsNotExist(err) {
+		t.Fatalf("staging dir left behind: %v", err)
+	}
+}
+
+func TestCloseReportsFailedSession(t *testing.T) {
+	script, _ := fakeSFTP(t, t.TempDir(), 1)
+	client, err := New(Config{Command: script}, "deploy@web.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Put(context.Background(), "~/data/meta.json", []byte("{}"), "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(); err == nil {
+		t.Fatal("want the session's failure from Close")
+	}
+}
+
+func TestRemotePath(t *testing.T) {
+	if got := remotePath("~/public/meta.json"); got != "public/meta.json" {
+		t.Fatalf("home-relative = %q", got)
+	}
+	if got := remotePath("var/www/meta.json"); got != "/var/www/meta.json" {
+		t.Fatalf("absolute = %q", got)
+	}
+	if got := quote(`a "b"\c`); got != `"a \"b\"\\c"` {
+		t.Fatalf("quote = %s", got)
+	}
+}
@@ -0,0 +1,298 @@
+// Package sheets writes tables into a Google Sheet through the Sheets API v4.
+// It authenticates as a service account with a signed JWT, or with an access
+// token obtained elsewhere, so it needs no Google client library.
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"tradegravity/internal/secrets"
+)
+
+const (
+	defaultEndpoint       = "https://sheets.googleapis.com/v4/spreadsheets"
+	defaultTokenURL       = "https://oauth2.googleapis.com/token"
+	defaultTimeoutSeconds = 60
+	scope                 = "https://www.googleapis.com/auth/spreadsheets"
+)
+
+// Config holds the API endpoint and credentials. AccessToken, when set, is
+// used as is; otherwise ClientEmail and PrivateKey sign a token request to
+// TokenURL.
+type Config struct {
+	Endpoint    string
+	TokenURL    string
+	ClientEmail string
+	PrivateKey  *rsa.PrivateKey
+	AccessToken string
+	Timeout     time.Duration
+}
+
+// ConfigFromEnv reads SHEETS_ACCESS_TOKEN, or else the service account key
+// file named by GOOGLE_APPLICATION_CREDENTIALS. SHEETS_ENDPOINT overrides the
+// API base URL.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Endpoint: getenv("SHEETS_ENDPOINT", defaultEndpoint),
+		TokenURL: defaultTokenURL,
+		Timeout:  defaultTimeoutSeconds * time.Second,
+	}
+	var err error
+	if cfg.AccessToken, err = secrets.Lookup("SHEETS_ACCESS_TOKEN"); err != nil {
+		return Config{}, err
+	}
+	if cfg.AccessToken != "" {
+		return cfg, nil
+	}
+	path := strings.TrimSpace(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+	if path == "" {
+		return Config{}, errors.New("missing credentials for sheets publishing (set GOOGLE_APPLICATION_CREDENTIALS or SHEETS_ACCESS_TOKEN)")
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var key struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(body, &key); err != nil {
+		return Config{}, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if key.Type != "service_account" || key.ClientEmail == "" {
+		return Config{}, fmt.Errorf("%s is not a service account key", path)
+	}
+	cfg.ClientEmail = key.ClientEmail
+	if cfg.PrivateKey, err = ParsePrivateKey([]byte(key.PrivateKey)); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if key.TokenURI != "" {
+		cfg.TokenURL = key.TokenURI
+	}
+	return cfg, nil
+}
+
+// ParsePrivateKey reads a PEM RSA key in PKCS #8 form, as service account
+// key files hold it, or PKCS #1.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Client writes into spreadsheets the credentials can edit.
+type Client struct {
+	config Config
+	client *http.Client
+	now    func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func New(cfg Config) (*Client, error) {
+	if strings.TrimSpace(cfg.Endpoint) == "" {
+		cfg.Endpoint = defaultEndpoint
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = defaultTokenURL
+	}
+	if cfg.AccessToken == "" && (cfg.ClientEmail == "" || cfg.PrivateKey == nil) {
+		return nil, errors.New("sheets client needs an access token or a service account key")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeoutSeconds * time.Second
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	return &Client{config: cfg, client: &http.Client{Timeout: cfg.Timeout}, now: time.Now}, nil
+}
+
+// ReplaceSheet makes rows, header first, the whole content of the sheet
+// titled title, adding the sheet when the spreadsheet lacks it. Cells are
+// written as raw values, so strings are not parsed as numbers or formulas.
+func (c *Client) ReplaceSheet(ctx context.Context, spreadsheetID, title string, rows [][]any) error {
+	base := c.config.Endpoint + "/" + url.PathEscape(spreadsheetID)
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := c.call(ctx, http.MethodGet, base+"?fields=sheets.properties.title", nil, &spreadsheet); err != nil {
+		return err
+	}
+	exists := false
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties.Title == title {
+			exists = true
+		}
+	}
+	if !exists {
+		add := map[string]any{"requests": []any{map[string]any{"addSheet": map[string]any{"properties": map[string]any{"title": title}}}}}
+		if err := c.call(ctx, http.MethodPost, base+":batchUpdate", add, nil); err != nil {
+			return err
+		}
+	}
+
+	quoted := "'" + strings.ReplaceAll(title, "'", "''") + "'"
+	if err := c.call(ctx, http.MethodPost, base+"/values/"+url.PathEscape(quoted)+":clear", map[string]any{}, nil); err != nil {
+		return err
+	}
+	update := map[string]any{"range": quoted + "!A1", "majorDimension": "ROWS", "values": rows}
+	return c.call(ctx, http.MethodPut, base+"/values/"+url.PathEscape(quoted+"!A1")+"?valueInputOption=RAW", update, nil)
+}
+
+// call sends body as JSON and decodes the response into result when it is
+// not nil.
+func (c *Client) call(ctx context.Context, method, uri string, body, result any) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	var payload io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, uri, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: status %d: %s", method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// accessToken returns the configured token, or exchanges a signed JWT for
+// one and reuses it until a minute before it expires.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	if c.config.AccessToken != "" {
+		return c.config.AccessToken, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.token != "" && now.Before(c.expires) {
+		return c.token, nil
+	}
+	assertion, err := c.assertion(now)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"}, "assertion": {assertion}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("token request: status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("decode token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token response has no access_token")
+	}
+	c.token, c.expires = token.AccessToken, now.Add(time.Duration(token.ExpiresIn)*time.Second-time.Minute)
+	return c.token, nil
+}
+
+// assertion is the RS256-signed JWT a service account exchanges for an
+// access token.
+func (c *Client) assertion(now time.Time) (string, error) {
+	encode := func(value any) (string, error) {
+		body, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(body), nil
+	}
+	header, err := encode(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := encode(map[string]any{
+		"iss": c.config.ClientEmail, "scope": scope, "aud": c.config.TokenURL,
+		"iat": now.Unix(), "exp": now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + claims
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.config.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func getenv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
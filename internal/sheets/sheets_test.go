@@ -0,0 +1,105 @@
+package sheets
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplaceSheetAddsClearsAndWritesWithServiceAccountToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	var written map[string]any
+	tokenRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			tokenRequests++
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			parts := strings.Split(r.Form.Get("assertion"), ".")
+			signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+				t.Fatalf("assertion signature: %v", err)
+			}
+			claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			if !strings.Contains(string(claims), `"iss":"publisher@example.iam.gserviceaccount.com"`) || !strings.Contains(string(claims), scope) {
+				t.Fatalf("claims = %s", claims)
+			}
+			w.Write([]byte(`{"access_token":"token-1","expires_in":3600}`))
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token-1" {
+			t.Fatalf("Authorization = %q", got)
+		}
+		calls = append(calls, r.Method+" "+r.URL.EscapedPath())
+		switch {
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"sheets":[{"properties":{"title":"latest"}}]}`))
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &written); err != nil {
+				t.Fatal(err)
+			}
+			if r.URL.Query().Get("valueInputOption") != "RAW" {
+				t.Fatalf("query = %s", r.URL.RawQuery)
+			}
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client, err := New(Config{Endpoint: srv.URL + "/v4/spreadsheets", TokenURL: srv.URL + "/token", ClientEmail: "publisher@example.iam.gserviceaccount.com", PrivateKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+	if err := client.ReplaceSheet(ctx, "sheet-id", "latest", [][]any{{"iso3", "total"}, {"KOR", 12.5}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ReplaceSheet(ctx, "sheet-id", "rankings", [][]any{{"rank"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"GET /v4/spreadsheets/sheet-id",
+		"POST /v4/spreadsheets/sheet-id/values/%27latest%27:clear",
+		"PUT /v4/spreadsheets/sheet-id/values/%27latest%27%21A1",
+		"GET /v4/spreadsheets/sheet-id",
+		"POST /v4/spreadsheets/sheet-id:batchUpdate",
+		"POST /v4/spreadsheets/sheet-id/values/%27rankings%27:clear",
+		"PUT /v4/spreadsheets/sheet-id/values/%27rankings%27%21A1",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("calls:\n%s\nwant:\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+	if tokenRequests != 1 {
+		t.Fatalf("token requests = %d, want the token reused", tokenRequests)
+	}
+	if written["range"] != "'rankings'!A1" || len(written["values"].([]any)) != 1 {
+		t.Fatalf("last update = %v", written)
+	}
+}
+
+func TestNewRequiresCredentials(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Fatal("New accepted a config without credentials")
+	}
+	if _, err := New(Config{AccessToken: "token"}); err != nil {
+		t.Fatal(err)
+	}
+}
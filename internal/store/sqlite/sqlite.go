@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,6 +17,9 @@ import (
 	"tradegravity/internal/store"
 )
 
+// managedTables lists the tables created by migrate.
+var managedTables = []string{"trade_observations", "tariff_observations", "ingest_runs", "reporters", "fx_rates", "provider_discrepancies", "series_quality"}
+
 type Store struct {
 	db *sql.DB
 }
@@ -48,13 +52,24 @@ func (s *Store) Close() error {
 }
 
 func (s *Store) UpsertObservations(ctx context.Context, observations []model.Observation) error {
+	_, err := s.upsertObservations(ctx, observations, false)
+	return err
+}
+
+// UpsertObservationChanges stores observations like UpsertObservations and
+// returns the ones that were new or changed value, in input order.
+func (s *Store) UpsertObservationChanges(ctx context.Context, observations []model.Observation) ([]model.ObservationChange, error) {
+	return s.upsertObservations(ctx, observations, true)
+}
+
+func (s *Store) upsertObservations(ctx context.Context, observations []model.Observation, track bool) (changes []model.ObservationChange, err error) {
 	if len(observations) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		if err != nil {
@@ -66,19 +81,40 @@ func (s *Store) UpsertObservations(ctx context.Context, observations []model.Obs
 		INSERT INTO trade_observations (
 			provider, classification, product_code, product_level,
 			reporter_iso3, partner_iso3, flow, period_type, period,
-			value_usd, ingested_at, source_updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			value_usd, value_cents, ingested_at, source_updated_at, estimated, quality_note,
+			dataset_id, indicator, source_note
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(provider, classification, product_code, reporter_iso3, partner_iso3, flow, period_type, period)
 		DO UPDATE SET
+			revision_count = trade_observations.revision_count + (trade_observations.value_cents != excluded.value_cents),
 			value_usd = excluded.value_usd,
+			value_cents = excluded.value_cents,
 			ingested_at = excluded.ingested_at,
-			source_updated_at = excluded.source_updated_at
+			source_updated_at = excluded.source_updated_at,
+			estimated = excluded.estimated,
+			quality_note = excluded.quality_note,
+			dataset_id = excluded.dataset_id,
+			indicator = excluded.indicator,
+			source_note = excluded.source_note
 	`)
 	if err != nil {
 		_ = tx.Rollback()
-		return err
+		return nil, err
 	}
 	defer stmt.Close()
+	var previous *sql.Stmt
+	if track {
+		previous, err = tx.PrepareContext(ctx, `
+			SELECT value_cents FROM trade_observations
+			WHERE provider = ? AND classification = ? AND product_code = ? AND reporter_iso3 = ?
+			  AND partner_iso3 = ? AND flow = ? AND period_type = ? AND period = ?
+		`)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		defer previous.Close()
+	}
 
 	now := time.Now().UTC()
 	for i := range observations {
@@ -95,6 +131,24 @@ func (s *Store) UpsertObservations(ctx context.Context, observations []model.Obs
 		if observation.IngestedAt.IsZero() {
 			observation.IngestedAt = now
 		}
+		observation.QualityNote = strings.TrimSpace(observation.QualityNote)
+		cents := model.CentsFromUSD(observation.ValueUSD)
+		if track {
+			recorded := observation
+			recorded.ValueUSD = cents.USD()
+			var stored int64
+			switch scanErr := previous.QueryRowContext(ctx, observation.Provider, observation.Classification, observation.ProductCode,
+				observation.ReporterISO3, observation.PartnerISO3, string(observation.Flow), string(observation.PeriodType), observation.Period).Scan(&stored); {
+			case errors.Is(scanErr, sql.ErrNoRows):
+				changes = append(changes, model.ObservationChange{Observation: recorded})
+			case scanErr != nil:
+				err = scanErr
+				_ = tx.Rollback()
+				return nil, err
+			case model.Cents(stored) != cents:
+				changes = append(changes, model.ObservationChange{Observation: recorded, Revised: true, PreviousUSD: model.Cents(stored).USD()})
+			}
+		}
 		var sourceUpdatedAt any
 		if !observation.SourceUpdatedAt.IsZero() {
 			sourceUpdatedAt = observation.SourceUpdatedAt.UTC()
@@ -110,20 +164,26 @@ func (s *Store) UpsertObservations(ctx context.Context, observations []model.Obs
 			string(observation.Flow),
 			string(observation.PeriodType),
 			observation.Period,
-			observation.ValueUSD,
+			cents.USD(),
+			int64(cents),
 			observation.IngestedAt.UTC(),
 			sourceUpdatedAt,
+			observation.Estimated,
+			observation.QualityNote,
+			strings.TrimSpace(observation.DatasetID),
+			strings.TrimSpace(observation.Indicator),
+			strings.TrimSpace(observation.SourceNote),
 		)
 		if err != nil {
 			_ = tx.Rollback()
-			return err
+			return nil, err
 		}
 	}
 
 	if err = tx.Commit(); err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return changes, nil
 }
 
 func (s *Store) UpsertTariffObservations(ctx context.Context, observations []model.TariffObservation) error {
@@ -267,8 +327,8 @@ func (s *Store) RecordIngestRun(ctx context.Context, run model.IngestRun) error
 		INSERT INTO ingest_runs (
 			run_id, provider, mode, started_at, finished_at, status,
 			reporter_count, request_count, success_count, failure_count,
-			skipped_count, stored_count, errors_json
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			skipped_count, stored_count, errors_json, code_version, code_commit
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(run_id) DO UPDATE SET
 			finished_at = excluded.finished_at,
 			status = excluded.status,
@@ -278,11 +338,13 @@ func (s *Store) RecordIngestRun(ctx context.Context, run model.IngestRun) error
 			failure_count = excluded.failure_count,
 			skipped_count = excluded.skipped_count,
 			stored_count = excluded.stored_count,
-			errors_json = excluded.errors_json
+			errors_json = excluded.errors_json,
+			code_version = excluded.code_version,
+			code_commit = excluded.code_commit
 	`, run.RunID, strings.ToLower(strings.TrimSpace(run.Provider)), run.Mode,
 		run.StartedAt.UTC().Format(time.RFC3339Nano), run.FinishedAt.UTC().Format(time.RFC3339Nano), run.Status,
 		run.ReporterCount, run.RequestCount, run.SuccessCount, run.FailureCount,
-		run.SkippedCount, run.StoredCount, string(errorsJSON))
+		run.SkippedCount, run.StoredCount, string(errorsJSON), run.CodeVersion, run.CodeCommit)
 	if err != nil {
 		return fmt.Errorf("record ingest run: %w", err)
 	}
@@ -312,13 +374,342 @@ func (s *Store) DominantAnnualPeriod(ctx context.Context, provider string) (stri
 	return period, nil
 }
 
+// TableCounts returns the row count of each table the store manages.
+func (s *Store) TableCounts(ctx context.Context) (map[string]int64, error) {
+	if s == nil || s.db == nil {
+		return nil, fmt.Errorf("sqlite store is not open")
+	}
+	counts := make(map[string]int64, len(managedTables))
+	for _, table := range managedTables {
+		var count int64
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&count); err != nil {
+			return nil, fmt.Errorf("count %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
+
+// Ping checks that the database answers a query against its observations.
+func (s *Store) Ping(ctx context.Context) error {
+	if s == nil || s.db == nil {
+		return fmt.Errorf("sqlite store is not open")
+	}
+	var rows int
+	return s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM (SELECT 1 FROM trade_observations LIMIT 1)`).Scan(&rows)
+}
+
+// LatestIngestedAt returns the newest ingested_at among stored observations,
+// or "" when there are none.
+func (s *Store) LatestIngestedAt(ctx context.Context) (string, error) {
+	if s == nil || s.db == nil {
+		return "", fmt.Errorf("sqlite store is not open")
+	}
+	var latest string
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(ingested_at), '') FROM trade_observations`).Scan(&latest)
+	return latest, err
+}
+
+// UpsertReporters stores reporter names and classifications. An empty
+// NameKO, Region, Subregion, or IncomeGroup keeps the stored value, so a
+// provider listing without Korean labels does not erase ones loaded from
+// configs/countries.csv.
+func (s *Store) UpsertReporters(ctx context.Context, reporters []model.Reporter) error {
+	if len(reporters) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO reporters (iso3, name_en, name_ko, region, subregion, income_group, is_active, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(iso3) DO UPDATE SET
+			name_en = CASE WHEN excluded.name_en = '' THEN reporters.name_en ELSE excluded.name_en END,
+			name_ko = CASE WHEN excluded.name_ko = '' THEN reporters.name_ko ELSE excluded.name_ko END,
+			region = CASE WHEN excluded.region = '' THEN reporters.region ELSE excluded.region END,
+			subregion = CASE WHEN excluded.subregion = '' THEN reporters.subregion ELSE excluded.subregion END,
+			income_group = CASE WHEN excluded.income_group = '' THEN reporters.income_group ELSE excluded.income_group END,
+			is_active = excluded.is_active,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, reporter := range reporters {
+		iso3 := strings.ToUpper(strings.TrimSpace(reporter.ISO3))
+		if iso3 == "" {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx,
+			iso3,
+			strings.TrimSpace(reporter.NameEN),
+			strings.TrimSpace(reporter.NameKO),
+			strings.TrimSpace(reporter.Region),
+			strings.TrimSpace(reporter.Subregion),
+			strings.TrimSpace(reporter.IncomeGroup),
+			reporter.IsActive,
+			now,
+		); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// UpsertFXRates stores currency-per-USD rates. Non-positive rates are
+// skipped rather than stored as a conversion that would zero every value.
+func (s *Store) UpsertFXRates(ctx context.Context, rates []model.FXRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO fx_rates (currency, period_type, period, rate_per_usd, source, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(currency, period_type, period) DO UPDATE SET
+			rate_per_usd = excluded.rate_per_usd,
+			source = excluded.source,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, rate := range rates {
+		currency := strings.ToUpper(strings.TrimSpace(rate.Currency))
+		if currency == "" || rate.RatePerUSD <= 0 {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, currency, string(rate.PeriodType), rate.Period, rate.RatePerUSD, rate.Source, now); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RefreshProviderDiscrepancies rebuilds provider_discrepancies from every
+// total-trade observation WITS and Comtrade both report for the same
+// reporter, partner, flow, and period, and returns the number of pairs.
+// discrepancy_pct is (comtrade - wits) / wits × 100; pairs with no positive
+// WITS value are left out.
+func (s *Store) RefreshProviderDiscrepancies(ctx context.Context) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("sqlite store is not open")
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM provider_discrepancies`); err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("clear provider discrepancies: %w", err)
+	}
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO provider_discrepancies (
+			reporter_iso3, partner_iso3, flow, period_type, period,
+			wits_value_usd, comtrade_value_usd, discrepancy_pct, computed_at
+		)
+		SELECT w.reporter_iso3, w.partner_iso3, w.flow, w.period_type, w.period,
+			w.value_cents / 100.0, c.value_cents / 100.0, (c.value_cents - w.value_cents) * 100.0 / w.value_cents, ?
+		FROM trade_observations w
+		JOIN trade_observations c
+		  ON c.provider = 'comtrade' AND c.product_level = 0 AND c.product_code = 'TOTAL'
+		 AND c.reporter_iso3 = w.reporter_iso3 AND c.partner_iso3 = w.partner_iso3
+		 AND c.flow = w.flow AND c.period_type = w.period_type AND c.period = w.period
+		WHERE w.provider = 'wits' AND w.product_level = 0 AND w.product_code = 'TOTAL' AND w.value_cents > 0
+		GROUP BY w.reporter_iso3, w.partner_iso3, w.flow, w.period_type, w.period
+	`, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("compute provider discrepancies: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// RefreshSeriesQuality rebuilds series_quality from the stored total-trade
+// observations and provider_discrepancies, so it should run after
+// RefreshProviderDiscrepancies. A series is one reporter, partner, flow, and
+// period type across providers; a period counts as revised when any
+// provider's value for it changed after it was first stored. It returns the
+// number of series graded.
+func (s *Store) RefreshSeriesQuality(ctx context.Context) (int, error) {
+	if s == nil || s.db == nil {
+		return 0, fmt.Errorf("sqlite store is not open")
+	}
+	type seriesKey struct {
+		reporter, partner, flow, periodType string
+	}
+	type seriesPeriods struct {
+		first, last   string
+		periods       int
+		revised       int
+		discrepancies []float64
+	}
+	series := make(map[seriesKey]*seriesPeriods)
+	var keys []seriesKey
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT reporter_iso3, partner_iso3, flow, period_type, period, MAX(revision_count)
+		FROM trade_observations
+		WHERE product_level = 0 AND product_code = 'TOTAL'
+		GROUP BY reporter_iso3, partner_iso3, flow, period_type, period
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("read series periods: %w", err)
+	}
+	for rows.Next() {
+		var key seriesKey
+		var period string
+		var revisions int
+		if err := rows.Scan(&key.reporter, &key.partner, &key.flow, &key.periodType, &period, &revisions); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		item, ok := series[key]
+		if !ok {
+			item = &seriesPeriods{first: period, last: period}
+			series[key] = item
+			keys = append(keys, key)
+		}
+		item.first, item.last = min(item.first, period), max(item.last, period)
+		item.periods++
+		if revisions > 0 {
+			item.revised++
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+
+	discrepancies, err := s.db.QueryContext(ctx, `SELECT reporter_iso3, partner_iso3, flow, period_type, ABS(discrepancy_pct) FROM provider_discrepancies`)
+	if err != nil {
+		return 0, fmt.Errorf("read provider discrepancies: %w", err)
+	}
+	for discrepancies.Next() {
+		var key seriesKey
+		var pct float64
+		if err := discrepancies.Scan(&key.reporter, &key.partner, &key.flow, &key.periodType, &pct); err != nil {
+			_ = discrepancies.Close()
+			return 0, err
+		}
+		if item, ok := series[key]; ok {
+			item.discrepancies = append(item.discrepancies, pct)
+		}
+	}
+	if err := discrepancies.Close(); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM series_quality`); err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("clear series quality: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO series_quality (
+			reporter_iso3, partner_iso3, flow, period_type, periods, span,
+			revised_periods, discrepancy_pct, grade, computed_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	computedAt := time.Now().UTC().Format(time.RFC3339)
+	count := 0
+	for _, key := range keys {
+		item := series[key]
+		window, err := model.NewPeriodRange(model.PeriodType(key.periodType), item.first, item.last)
+		if err != nil {
+			continue
+		}
+		quality := model.SeriesQuality{
+			Pair: model.NewPair(key.reporter, key.partner), Flow: model.Flow(key.flow), PeriodType: window.Type,
+			Periods: item.periods, Span: window.Len(), RevisedPeriods: item.revised,
+		}
+		var discrepancy any
+		if len(item.discrepancies) > 0 {
+			median := medianOf(item.discrepancies)
+			quality.DiscrepancyPct = &median
+			discrepancy = median
+		}
+		if _, err := stmt.ExecContext(ctx, key.reporter, key.partner, key.flow, key.periodType, quality.Periods, quality.Span,
+			quality.RevisedPeriods, discrepancy, quality.Grade(), computedAt); err != nil {
+			_ = tx.Rollback()
+			return 0, fmt.Errorf("store series quality: %w", err)
+		}
+		count++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// medianOf returns the median of values, which must not be empty. It sorts
+// values in place.
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[middle]
+	}
+	return (values[middle-1] + values[middle]) / 2
+}
+
 func (s *Store) ListReporters(ctx context.Context, onlyActive bool) ([]model.Reporter, error) {
-	_ = ctx
-	_ = onlyActive
-	return nil, nil
+	if s == nil || s.db == nil {
+		return nil, nil
+	}
+	query := `SELECT iso3, name_en, name_ko, region, subregion, income_group, is_active FROM reporters`
+	if onlyActive {
+		query += ` WHERE is_active = 1`
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY iso3`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reporters := make([]model.Reporter, 0)
+	for rows.Next() {
+		var reporter model.Reporter
+		if err := rows.Scan(&reporter.ISO3, &reporter.NameEN, &reporter.NameKO, &reporter.Region, &reporter.Subregion, &reporter.IncomeGroup, &reporter.IsActive); err != nil {
+			return nil, err
+		}
+		reporters = append(reporters, reporter)
+	}
+	return reporters, rows.Err()
 }
 
-func (s *Store) ListObservationKeys(ctx context.Context, provider, reporterISO3, partnerISO3 string, flow model.Flow) ([]store.ObservationKey, error) {
+func (s *Store) ListObservationKeys(ctx context.Context, provider string, pair model.Pair, flow model.Flow) ([]store.ObservationKey, error) {
 	if s == nil || s.db == nil {
 		return nil, nil
 	}
@@ -328,7 +719,7 @@ func (s *Store) ListObservationKeys(ctx context.Context, provider, reporterISO3,
 		FROM trade_observations
 		WHERE provider = ? AND product_level = 0 AND product_code = 'TOTAL'
 		  AND reporter_iso3 = ? AND partner_iso3 = ? AND flow = ?
-	`, provider, reporterISO3, partnerISO3, string(flow))
+	`, provider, pair.Reporter, pair.Partner, string(flow))
 	if err != nil {
 		return nil, err
 	}
@@ -391,12 +782,27 @@ func (s *Store) migrate() error {
 			period_type TEXT NOT NULL,
 			period TEXT NOT NULL,
 			value_usd REAL NOT NULL,
+			value_cents INTEGER NOT NULL DEFAULT 0,
+			revision_count INTEGER NOT NULL DEFAULT 0,
 			ingested_at TEXT NOT NULL,
 			source_updated_at TEXT,
+			estimated INTEGER NOT NULL DEFAULT 0,
+			quality_note TEXT NOT NULL DEFAULT '',
+			dataset_id TEXT NOT NULL DEFAULT '',
+			indicator TEXT NOT NULL DEFAULT '',
+			source_note TEXT NOT NULL DEFAULT '',
 			PRIMARY KEY (provider, classification, product_code, reporter_iso3, partner_iso3, flow, period_type, period)
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_trade_observations_totals
 		 ON trade_observations(provider, product_level, reporter_iso3, partner_iso3, period_type, period);`,
+		// The publisher reads a few partners (USA, CHN, WLD) across every
+		// reporter, and the aggregate API one period across every provider;
+		// without these, both scan every partner of the bilateral matrix.
+		// Lookups by provider and reporter use idx_trade_observations_totals.
+		`CREATE INDEX IF NOT EXISTS idx_trade_observations_partner
+		 ON trade_observations(partner_iso3, product_level, provider, period_type, period);`,
+		`CREATE INDEX IF NOT EXISTS idx_trade_observations_period
+		 ON trade_observations(period_type, period, product_level, provider, partner_iso3);`,
 		`CREATE TABLE IF NOT EXISTS tariff_observations (
 			provider TEXT NOT NULL,
 			classification TEXT NOT NULL,
@@ -425,6 +831,25 @@ func (s *Store) migrate() error {
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_tariff_observations_lookup
 		 ON tariff_observations(importer_iso3, exporter_iso3, year, product_code, data_type, rate_type);`,
+		`CREATE TABLE IF NOT EXISTS reporters (
+			iso3 TEXT PRIMARY KEY,
+			name_en TEXT NOT NULL DEFAULT '',
+			name_ko TEXT NOT NULL DEFAULT '',
+			region TEXT NOT NULL DEFAULT '',
+			subregion TEXT NOT NULL DEFAULT '',
+			income_group TEXT NOT NULL DEFAULT '',
+			is_active INTEGER NOT NULL DEFAULT 1,
+			updated_at TEXT NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS fx_rates (
+			currency TEXT NOT NULL,
+			period_type TEXT NOT NULL,
+			period TEXT NOT NULL,
+			rate_per_usd REAL NOT NULL,
+			source TEXT NOT NULL DEFAULT '',
+			updated_at TEXT NOT NULL,
+			PRIMARY KEY (currency, period_type, period)
+		);`,
 		`CREATE TABLE IF NOT EXISTS ingest_runs (
 			run_id TEXT PRIMARY KEY,
 			provider TEXT NOT NULL,
@@ -438,7 +863,34 @@ func (s *Store) migrate() error {
 			failure_count INTEGER NOT NULL,
 			skipped_count INTEGER NOT NULL,
 			stored_count INTEGER NOT NULL,
-			errors_json TEXT NOT NULL DEFAULT '[]'
+			errors_json TEXT NOT NULL DEFAULT '[]',
+			code_version TEXT NOT NULL DEFAULT '',
+			code_commit TEXT NOT NULL DEFAULT ''
+		);`,
+		`CREATE TABLE IF NOT EXISTS provider_discrepancies (
+			reporter_iso3 TEXT NOT NULL,
+			partner_iso3 TEXT NOT NULL,
+			flow TEXT NOT NULL,
+			period_type TEXT NOT NULL,
+			period TEXT NOT NULL,
+			wits_value_usd REAL NOT NULL,
+			comtrade_value_usd REAL NOT NULL,
+			discrepancy_pct REAL NOT NULL,
+			computed_at TEXT NOT NULL,
+			PRIMARY KEY (reporter_iso3, partner_iso3, flow, period_type, period)
+		);`,
+		`CREATE TABLE IF NOT EXISTS series_quality (
+			reporter_iso3 TEXT NOT NULL,
+			partner_iso3 TEXT NOT NULL,
+			flow TEXT NOT NULL,
+			period_type TEXT NOT NULL,
+			periods INTEGER NOT NULL,
+			span INTEGER NOT NULL,
+			revised_periods INTEGER NOT NULL,
+			discrepancy_pct REAL,
+			grade TEXT NOT NULL,
+			computed_at TEXT NOT NULL,
+			PRIMARY KEY (reporter_iso3, partner_iso3, flow, period_type)
 		);`,
 	}
 
@@ -448,6 +900,38 @@ func (s *Store) migrate() error {
 		}
 	}
 
+	// Columns added after a table was first released, for stores created
+	// before them. Stored trade rows read as official with unknown sources,
+	// and stored reporters as unclassified, until the next collection.
+	// value_cents is derived from value_usd, which is rounded to match.
+	// Revisions are counted from the first upsert after revision_count is
+	// added.
+	additions := []struct{ table, column, statement string }{
+		{"trade_observations", "estimated", `ALTER TABLE trade_observations ADD COLUMN estimated INTEGER NOT NULL DEFAULT 0;`},
+		{"trade_observations", "quality_note", `ALTER TABLE trade_observations ADD COLUMN quality_note TEXT NOT NULL DEFAULT '';`},
+		{"trade_observations", "value_cents", `ALTER TABLE trade_observations ADD COLUMN value_cents INTEGER NOT NULL DEFAULT 0;
+			UPDATE trade_observations SET value_cents = CAST(ROUND(value_usd * 100) AS INTEGER), value_usd = ROUND(value_usd * 100) / 100.0;`},
+		{"trade_observations", "revision_count", `ALTER TABLE trade_observations ADD COLUMN revision_count INTEGER NOT NULL DEFAULT 0;`},
+		{"ingest_runs", "code_version", `ALTER TABLE ingest_runs ADD COLUMN code_version TEXT NOT NULL DEFAULT '';`},
+		{"ingest_runs", "code_commit", `ALTER TABLE ingest_runs ADD COLUMN code_commit TEXT NOT NULL DEFAULT '';`},
+		{"trade_observations", "dataset_id", `ALTER TABLE trade_observations ADD COLUMN dataset_id TEXT NOT NULL DEFAULT '';`},
+		{"trade_observations", "indicator", `ALTER TABLE trade_observations ADD COLUMN indicator TEXT NOT NULL DEFAULT '';`},
+		{"trade_observations", "source_note", `ALTER TABLE trade_observations ADD COLUMN source_note TEXT NOT NULL DEFAULT '';`},
+		{"reporters", "subregion", `ALTER TABLE reporters ADD COLUMN subregion TEXT NOT NULL DEFAULT '';`},
+		{"reporters", "income_group", `ALTER TABLE reporters ADD COLUMN income_group TEXT NOT NULL DEFAULT '';`},
+	}
+	for _, addition := range additions {
+		columns, err := s.tableColumns(addition.table)
+		if err != nil {
+			return err
+		}
+		if _, ok := columns[addition.column]; ok {
+			continue
+		}
+		if _, err := s.db.Exec(addition.statement); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -2,8 +2,13 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"math"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"tradegravity/internal/model"
 )
@@ -35,7 +40,7 @@ func TestUpsertObservationsAndListKeys(t *testing.T) {
 		t.Fatalf("second UpsertObservations() error = %v", err)
 	}
 
-	keys, err := store.ListObservationKeys(ctx, "wits", "KOR", "USA", model.FlowExport)
+	keys, err := store.ListObservationKeys(ctx, "wits", model.NewPair("kor", "USA"), model.FlowExport)
 	if err != nil {
 		t.Fatalf("ListObservationKeys() error = %v", err)
 	}
@@ -174,3 +179,404 @@ func TestMigrateTariffObservationsAddsDataTypeWithoutDroppingRows(t *testing.T)
 		t.Fatalf("migrated count/data_type = %d/%q", count, dataType)
 	}
 }
+
+func TestMigrateObservationsAddsQualityAndSourceColumns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.db.Exec(`DROP TABLE trade_observations;
+		CREATE TABLE trade_observations (
+			provider TEXT NOT NULL, classification TEXT NOT NULL DEFAULT '', product_code TEXT NOT NULL DEFAULT 'TOTAL',
+			product_level INTEGER NOT NULL DEFAULT 0, reporter_iso3 TEXT NOT NULL, partner_iso3 TEXT NOT NULL,
+			flow TEXT NOT NULL, period_type TEXT NOT NULL, period TEXT NOT NULL, value_usd REAL NOT NULL,
+			ingested_at TEXT NOT NULL, source_updated_at TEXT,
+			PRIMARY KEY (provider, classification, product_code, reporter_iso3, partner_iso3, flow, period_type, period)
+		);
+		INSERT INTO trade_observations VALUES ('wits','','TOTAL',0,'KOR','USA','export','Y','2023',100,'2026-01-01T00:00:00Z',NULL);`); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = migrated.Close() })
+	estimate := model.Observation{
+		Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport,
+		PeriodType: model.PeriodYear, Period: "2024", ValueUSD: 110,
+		Estimated: true, QualityNote: model.QualityProviderEstimate,
+		DatasetID: "tradestats-trade", Indicator: "XPRT-TRD-VL",
+	}
+	if err := migrated.UpsertObservations(context.Background(), []model.Observation{estimate}); err != nil {
+		t.Fatalf("UpsertObservations() error = %v", err)
+	}
+	rows, err := migrated.db.Query(`SELECT period, estimated, quality_note, dataset_id, indicator, source_note FROM trade_observations ORDER BY period`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var period, note, dataset, indicator, sourceNote string
+		var estimated bool
+		if err := rows.Scan(&period, &estimated, &note, &dataset, &indicator, &sourceNote); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s:%t:%s:%s:%s:%s", period, estimated, note, dataset, indicator, sourceNote))
+	}
+	if want := "2023:false::::,2024:true:provider_estimate:tradestats-trade:XPRT-TRD-VL:"; strings.Join(got, ",") != want {
+		t.Fatalf("stored quality and source = %v, want %s", got, want)
+	}
+}
+
+func TestUpsertObservationsStoresWholeCents(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.db.Exec(`ALTER TABLE trade_observations DROP COLUMN value_cents;
+		INSERT INTO trade_observations (provider, reporter_iso3, partner_iso3, flow, period_type, period, value_usd, ingested_at)
+		VALUES ('wits','KOR','USA','export','Y','2022',1234.5678,'2026-01-01T00:00:00Z');`); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = migrated.Close() })
+	// 0.1 + 0.2 and a WITS thousands multiplier both leave binary residue.
+	observations := []model.Observation{
+		{Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 0.1 + 0.2},
+		{Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2024", ValueUSD: 1.005 * 1000},
+	}
+	if err := migrated.UpsertObservations(context.Background(), observations); err != nil {
+		t.Fatalf("UpsertObservations() error = %v", err)
+	}
+	rows, err := migrated.db.Query(`SELECT period, value_usd, value_cents FROM trade_observations ORDER BY period`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var period string
+		var usd float64
+		var cents int64
+		if err := rows.Scan(&period, &usd, &cents); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%s:%v:%d", period, usd, cents))
+	}
+	if want := "2022:1234.57:123457,2023:0.3:30,2024:1005:100500"; strings.Join(got, ",") != want {
+		t.Fatalf("stored values = %v, want %s", got, want)
+	}
+}
+
+func TestMigrateReportersAddsClassificationColumns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.db.Exec(`DROP TABLE reporters;
+		CREATE TABLE reporters (
+			iso3 TEXT PRIMARY KEY, name_en TEXT NOT NULL DEFAULT '', name_ko TEXT NOT NULL DEFAULT '',
+			region TEXT NOT NULL DEFAULT '', is_active INTEGER NOT NULL DEFAULT 1, updated_at TEXT NOT NULL
+		);
+		INSERT INTO reporters VALUES ('KOR','Korea, Rep.','대한민국','East Asia & Pacific',1,'2026-01-01T00:00:00Z');`); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = migrated.Close() })
+	ctx := context.Background()
+	if err := migrated.UpsertReporters(ctx, []model.Reporter{{ISO3: "VNM", Subregion: "South-eastern Asia", IncomeGroup: "Lower middle income", IsActive: true}}); err != nil {
+		t.Fatalf("UpsertReporters() error = %v", err)
+	}
+	reporters, err := migrated.ListReporters(ctx, false)
+	if err != nil {
+		t.Fatalf("ListReporters() error = %v", err)
+	}
+	if len(reporters) != 2 || reporters[0].Region != "East Asia & Pacific" || reporters[0].IncomeGroup != "" || reporters[1].IncomeGroup != "Lower middle income" {
+		t.Fatalf("reporters = %+v, want the stored KOR row unclassified and VNM classified", reporters)
+	}
+}
+
+func TestTableCountsReportsManagedTables(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "tradegravity.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	observation := model.Observation{
+		Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport,
+		PeriodType: model.PeriodYear, Period: "2024", ValueUSD: 100,
+	}
+	if err := store.UpsertObservations(ctx, []model.Observation{observation}); err != nil {
+		t.Fatalf("UpsertObservations() error = %v", err)
+	}
+	counts, err := store.TableCounts(ctx)
+	if err != nil {
+		t.Fatalf("TableCounts() error = %v", err)
+	}
+	if counts["trade_observations"] != 1 || counts["tariff_observations"] != 0 || counts["ingest_runs"] != 0 {
+		t.Fatalf("counts = %#v", counts)
+	}
+}
+
+func TestUpsertReportersKeepsStoredNamesWhenEmpty(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "tradegravity.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	if err := store.UpsertReporters(ctx, []model.Reporter{
+		{ISO3: "KOR", NameEN: "Korea, Rep.", NameKO: "대한민국", Region: "East Asia & Pacific", Subregion: "Eastern Asia", IncomeGroup: "High income", IsActive: true},
+	}); err != nil {
+		t.Fatalf("first UpsertReporters() error = %v", err)
+	}
+	if err := store.UpsertReporters(ctx, []model.Reporter{{ISO3: "KOR", NameEN: "Republic of Korea", IsActive: true}}); err != nil {
+		t.Fatalf("second UpsertReporters() error = %v", err)
+	}
+
+	reporters, err := store.ListReporters(ctx, true)
+	if err != nil {
+		t.Fatalf("ListReporters() error = %v", err)
+	}
+	if len(reporters) != 1 {
+		t.Fatalf("reporters = %+v, want one", reporters)
+	}
+	got := reporters[0]
+	if got.NameEN != "Republic of Korea" || got.NameKO != "대한민국" || got.Region != "East Asia & Pacific" || got.Subregion != "Eastern Asia" || got.IncomeGroup != "High income" {
+		t.Fatalf("reporter = %+v, want updated English name with stored Korean name and classification", got)
+	}
+}
+
+func TestPingChecksTheObservationsTable(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "ping.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := store.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() on an empty store error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Ping(context.Background()); err == nil {
+		t.Fatal("expected Ping() on a closed store to fail")
+	}
+}
+
+func TestLatestIngestedAtReturnsNewestObservation(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "ingest.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	if latest, err := store.LatestIngestedAt(ctx); err != nil || latest != "" {
+		t.Fatalf("LatestIngestedAt() on an empty store = %q, %v", latest, err)
+	}
+	observations := []model.Observation{
+		{Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 1, IngestedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 1, IngestedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if err := store.UpsertObservations(ctx, observations); err != nil {
+		t.Fatal(err)
+	}
+	latest, err := store.LatestIngestedAt(ctx)
+	if err != nil || !strings.HasPrefix(latest, "2026-01-02") {
+		t.Fatalf("LatestIngestedAt() = %q, %v", latest, err)
+	}
+}
+
+func TestRefreshProviderDiscrepanciesPairsWITSAndComtradeTotals(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "tradegravity.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	total := func(provider, partner, period string, value float64) model.Observation {
+		return model.Observation{
+			Provider: provider, ReporterISO3: "KOR", PartnerISO3: partner, Flow: model.FlowExport,
+			PeriodType: model.PeriodYear, Period: period, ValueUSD: value,
+		}
+	}
+	if err := store.UpsertObservations(ctx, []model.Observation{
+		total("wits", "USA", "2023", 100),
+		total("comtrade", "USA", "2023", 104),
+		total("wits", "CHN", "2023", 200),
+		total("comtrade", "CHN", "2022", 190),
+		{Provider: "comtrade", Classification: "H6", ProductCode: "85", ProductLevel: 2, ReporterISO3: "KOR", PartnerISO3: "CHN", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 50},
+	}); err != nil {
+		t.Fatalf("UpsertObservations() error = %v", err)
+	}
+	for range 2 {
+		count, err := store.RefreshProviderDiscrepancies(ctx)
+		if err != nil || count != 1 {
+			t.Fatalf("RefreshProviderDiscrepancies() = %d, %v; want only the USA 2023 pair", count, err)
+		}
+	}
+	var partner string
+	var pct float64
+	if err := store.db.QueryRowContext(ctx, `SELECT partner_iso3, discrepancy_pct FROM provider_discrepancies`).Scan(&partner, &pct); err != nil {
+		t.Fatalf("read provider_discrepancies: %v", err)
+	}
+	if partner != "USA" || math.Abs(pct-4) > 1e-9 {
+		t.Fatalf("discrepancy = %s %v, want USA 4%%", partner, pct)
+	}
+}
+
+func TestRefreshSeriesQualityGradesCompletenessRevisionsAndDiscrepancy(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "tradegravity.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	total := func(provider, partner, period string, value float64) model.Observation {
+		return model.Observation{
+			Provider: provider, ReporterISO3: "KOR", PartnerISO3: partner, Flow: model.FlowExport,
+			PeriodType: model.PeriodYear, Period: period, ValueUSD: value,
+		}
+	}
+	if err := store.UpsertObservations(ctx, []model.Observation{
+		total("wits", "USA", "2021", 90),
+		total("wits", "USA", "2022", 95),
+		total("wits", "USA", "2023", 100),
+		total("comtrade", "USA", "2023", 104),
+		total("wits", "CHN", "2020", 180),
+		total("wits", "CHN", "2023", 200),
+	}); err != nil {
+		t.Fatalf("UpsertObservations() error = %v", err)
+	}
+	// Re-storing the same value is not a revision; changing it is.
+	if err := store.UpsertObservations(ctx, []model.Observation{total("wits", "USA", "2022", 95), total("wits", "CHN", "2023", 210)}); err != nil {
+		t.Fatalf("UpsertObservations() error = %v", err)
+	}
+	if _, err := store.RefreshProviderDiscrepancies(ctx); err != nil {
+		t.Fatalf("RefreshProviderDiscrepancies() error = %v", err)
+	}
+	count, err := store.RefreshSeriesQuality(ctx)
+	if err != nil || count != 2 {
+		t.Fatalf("RefreshSeriesQuality() = %d, %v; want the USA and CHN export series", count, err)
+	}
+
+	read := func(partner string) (periods, span, revised int, pct sql.NullFloat64, grade string) {
+		t.Helper()
+		if err := store.db.QueryRowContext(ctx, `SELECT periods, span, revised_periods, discrepancy_pct, grade FROM series_quality WHERE partner_iso3 = ?`, partner).
+			Scan(&periods, &span, &revised, &pct, &grade); err != nil {
+			t.Fatalf("read series_quality %s: %v", partner, err)
+		}
+		return
+	}
+	periods, span, revised, pct, grade := read("USA")
+	if periods != 3 || span != 3 || revised != 0 || !pct.Valid || math.Abs(pct.Float64-4) > 1e-9 || grade != model.GradeB {
+		t.Fatalf("USA quality = %d/%d revised=%d pct=%v grade=%s, want complete, 4%% apart, grade B", periods, span, revised, pct, grade)
+	}
+	periods, span, revised, pct, grade = read("CHN")
+	if periods != 2 || span != 4 || revised != 1 || pct.Valid || grade != model.GradeC {
+		t.Fatalf("CHN quality = %d/%d revised=%d pct=%v grade=%s, want half complete, one revision, grade C", periods, span, revised, pct, grade)
+	}
+}
+
+func TestUpsertObservationChangesReportsInsertsAndRevisions(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "tradegravity.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	ctx := context.Background()
+	first := model.Observation{Provider: "wits", ReporterISO3: "KOR", PartnerISO3: "USA", Flow: model.FlowExport, PeriodType: model.PeriodYear, Period: "2023", ValueUSD: 100}
+	second := first
+	second.Period = "2024"
+	changes, err := store.UpsertObservationChanges(ctx, []model.Observation{first, second})
+	if err != nil {
+		t.Fatalf("first UpsertObservationChanges() error = %v", err)
+	}
+	if len(changes) != 2 || changes[0].Revised || changes[1].Revised {
+		t.Fatalf("first changes = %+v, want two inserts", changes)
+	}
+
+	second.ValueUSD = 100.004
+	first.ValueUSD = 125
+	changes, err = store.UpsertObservationChanges(ctx, []model.Observation{first, second})
+	if err != nil {
+		t.Fatalf("second UpsertObservationChanges() error = %v", err)
+	}
+	if len(changes) != 1 || !changes[0].Revised || changes[0].Observation.Period != "2023" || changes[0].PreviousUSD != 100 || changes[0].Observation.ValueUSD != 125 {
+		t.Fatalf("second changes = %+v, want only the 2023 revision from 100 to 125", changes)
+	}
+}
+
+func TestMigrateAddsPublisherQueryIndexes(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.db.Exec(`DROP INDEX idx_trade_observations_partner; DROP INDEX idx_trade_observations_period;`); err != nil {
+		t.Fatal(err)
+	}
+	if err := legacy.Close(); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = migrated.Close() })
+
+	plan := func(query string) string {
+		t.Helper()
+		rows, err := migrated.db.Query(`EXPLAIN QUERY PLAN ` + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var steps []string
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+				t.Fatal(err)
+			}
+			steps = append(steps, detail)
+		}
+		return strings.Join(steps, "; ")
+	}
+	totals := `SELECT value_usd FROM trade_observations
+		WHERE flow IN ('export','import','total') AND product_level = 0 AND product_code = 'TOTAL'`
+	for query, index := range map[string]string{
+		totals + ` AND provider = 'wits' AND partner_iso3 IN ('USA','CHN')`:                              "idx_trade_observations_partner",
+		totals + ` AND partner_iso3 IN ('USA','CHN')`:                                                    "idx_trade_observations_partner",
+		totals + ` AND partner_iso3 IN ('USA','CHN') AND period_type = 'Y' AND period = '2023'`:          "idx_trade_observations_period",
+		totals + ` AND provider = 'wits' AND partner_iso3 IN ('USA','CHN') AND reporter_iso3 IN ('KOR')`: "idx_trade_observations_totals",
+	} {
+		if got := plan(query); !strings.Contains(got, "USING INDEX "+index) {
+			t.Fatalf("plan for %s = %s, want %s", query, got, index)
+		}
+	}
+}
@@ -12,11 +12,22 @@ type Store interface {
 	UpsertTariffObservations(ctx context.Context, observations []model.TariffObservation) error
 	RecordIngestRun(ctx context.Context, run model.IngestRun) error
 	DominantAnnualPeriod(ctx context.Context, provider string) (string, error)
+	UpsertReporters(ctx context.Context, reporters []model.Reporter) error
 	ListReporters(ctx context.Context, onlyActive bool) ([]model.Reporter, error)
-	ListObservationKeys(ctx context.Context, provider, reporterISO3, partnerISO3 string, flow model.Flow) ([]ObservationKey, error)
+	UpsertFXRates(ctx context.Context, rates []model.FXRate) error
+	ListObservationKeys(ctx context.Context, provider string, pair model.Pair, flow model.Flow) ([]ObservationKey, error)
+	RefreshProviderDiscrepancies(ctx context.Context) (int, error)
+	RefreshSeriesQuality(ctx context.Context) (int, error)
 	Close() error
 }
 
+// ChangeRecorder is implemented by stores that can tell which observations
+// an upsert inserted or revised. Observations stored again with the same
+// value are not changes.
+type ChangeRecorder interface {
+	UpsertObservationChanges(ctx context.Context, observations []model.Observation) ([]model.ObservationChange, error)
+}
+
 type NopStore struct{}
 
 func (s *NopStore) UpsertObservations(ctx context.Context, observations []model.Observation) error {
@@ -43,20 +54,41 @@ func (s *NopStore) DominantAnnualPeriod(ctx context.Context, provider string) (s
 	return "", errors.New("dominant period requires persistent storage")
 }
 
+func (s *NopStore) UpsertReporters(ctx context.Context, reporters []model.Reporter) error {
+	_ = ctx
+	_ = reporters
+	return nil
+}
+
 func (s *NopStore) ListReporters(ctx context.Context, onlyActive bool) ([]model.Reporter, error) {
 	_ = onlyActive
 	return nil, nil
 }
 
-func (s *NopStore) ListObservationKeys(ctx context.Context, provider, reporterISO3, partnerISO3 string, flow model.Flow) ([]ObservationKey, error) {
+func (s *NopStore) UpsertFXRates(ctx context.Context, rates []model.FXRate) error {
+	_ = ctx
+	_ = rates
+	return nil
+}
+
+func (s *NopStore) ListObservationKeys(ctx context.Context, provider string, pair model.Pair, flow model.Flow) ([]ObservationKey, error) {
 	_ = ctx
 	_ = provider
-	_ = reporterISO3
-	_ = partnerISO3
+	_ = pair
 	_ = flow
 	return nil, nil
 }
 
+func (s *NopStore) RefreshProviderDiscrepancies(ctx context.Context) (int, error) {
+	_ = ctx
+	return 0, nil
+}
+
+func (s *NopStore) RefreshSeriesQuality(ctx context.Context) (int, error) {
+	_ = ctx
+	return 0, nil
+}
+
 func (s *NopStore) Close() error {
 	return nil
 }
@@ -0,0 +1,232 @@
+// Package synthetic generates deterministic synthetic total-trade
+// observations at any scale, so publisher performance can be measured
+// against databases far larger than the sample fixture.
+package synthetic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"tradegravity/internal/iso"
+	"tradegravity/internal/model"
+	"tradegravity/internal/store"
+)
+
+const (
+	defaultProvider = "wits"
+	defaultPartners = 3
+	defaultLastYear = 2024
+	defaultYears    = 10
+	fillBatchSize   = 5000
+	// pairYearLength is the observations one reporter and partner pair
+	// holds for a year: both flows, each with twelve months and the annual
+	// total.
+	pairYearLength = 2 * 13
+)
+
+// Config sizes and seeds a synthetic dataset. The zero value of every field
+// but Observations picks a default.
+type Config struct {
+	// Observations is how many observations to generate.
+	Observations int
+	// Provider is stored on every observation, "wits" when empty.
+	Provider string
+	// Seed varies the values. The same Config always yields the same data.
+	Seed uint64
+	// Partners is how many partners every reporter trades with: USA, CHN,
+	// and WLD, then other countries in alpha-3 order, which the publisher
+	// reads past like the bilateral matrix. 3 when zero.
+	Partners int
+	// LastYear is the latest year of every series, 2024 when zero.
+	LastYear int
+	// Years is how many years every series covers. When zero it is 10, or
+	// as many more as it takes once every country reports to every partner.
+	Years int
+}
+
+// shape is the reporters, partners, and years a dataset spans.
+type shape struct {
+	reporters []model.Reporter
+	partners  []string
+	years     int
+	firstYear int
+}
+
+// plan works out the shape of cfg. Reporters are the first ISO countries in
+// alpha-3 order, as many as it takes to hold the observations over the
+// given or default years, so small datasets stay complete for the reporters
+// they cover and large ones grow their history.
+func plan(cfg Config) (shape, error) {
+	if cfg.Observations < 0 {
+		return shape{}, errors.New("synthetic: observations must not be negative")
+	}
+	countries := iso.Countries()
+	partners := []string{"USA", "CHN", iso.World}
+	for _, country := range countries {
+		if country.Alpha3 != "USA" && country.Alpha3 != "CHN" {
+			partners = append(partners, country.Alpha3)
+		}
+	}
+	partnerCount := cfg.Partners
+	if partnerCount == 0 {
+		partnerCount = defaultPartners
+	}
+	if partnerCount < 1 || partnerCount > len(partners) {
+		return shape{}, fmt.Errorf("synthetic: partners must be between 1 and %d", len(partners))
+	}
+	partners = partners[:partnerCount]
+
+	years := cfg.Years
+	if years == 0 {
+		years = defaultYears
+	}
+	var reporters []model.Reporter
+	pairs := 0
+	for _, country := range countries {
+		if len(reporters) > 0 && pairs*pairYearLength*years >= cfg.Observations {
+			break
+		}
+		reporters = append(reporters, model.Reporter{ISO3: country.Alpha3, NameEN: country.NameEN, NameKO: country.NameKO, IsActive: true})
+		for _, partner := range partners {
+			if partner != country.Alpha3 {
+				pairs++
+			}
+		}
+	}
+	if cfg.Years == 0 {
+		years = max(years, (cfg.Observations+pairs*pairYearLength-1)/(pairs*pairYearLength))
+	} else if capacity := pairs * pairYearLength * years; cfg.Observations > capacity {
+		return shape{}, fmt.Errorf("synthetic: at most %d observations fit %d years of %d partners", capacity, years, partnerCount)
+	}
+	lastYear := cfg.LastYear
+	if lastYear == 0 {
+		lastYear = defaultLastYear
+	}
+	if years < 1 || lastYear-years+1 < 1 {
+		return shape{}, fmt.Errorf("synthetic: invalid %d years ending %d", years, lastYear)
+	}
+	return shape{reporters: reporters, partners: partners, years: years, firstYear: lastYear - years + 1}, nil
+}
+
+// Reporters returns the reporters a dataset of cfg's size covers.
+func Reporters(cfg Config) ([]model.Reporter, error) {
+	planned, err := plan(cfg)
+	return planned.reporters, err
+}
+
+// Each calls fn with cfg.Observations observations, stopping at the first
+// error fn returns. Every reporter trades with USA, then CHN, then WLD, and
+// then any further partners, so the partners the publisher tracks fill
+// first. Months grow from a random base with noise, and each annual total
+// is the sum of its months.
+func Each(cfg Config, fn func(model.Observation) error) error {
+	planned, err := plan(cfg)
+	if err != nil {
+		return err
+	}
+	provider := cfg.Provider
+	if provider == "" {
+		provider = defaultProvider
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.Seed, 0x7472616465))
+	remaining := cfg.Observations
+	for _, partner := range planned.partners {
+		for _, reporter := range planned.reporters {
+			if reporter.ISO3 == partner {
+				continue
+			}
+			for _, flow := range []model.Flow{model.FlowExport, model.FlowImport} {
+				if remaining == 0 {
+					return nil
+				}
+				observations := series(provider, planned, rng, reporter.ISO3, partner, flow)
+				if len(observations) > remaining {
+					observations = observations[:remaining]
+				}
+				for _, observation := range observations {
+					if err := fn(observation); err != nil {
+						return err
+					}
+				}
+				remaining -= len(observations)
+			}
+		}
+	}
+	return nil
+}
+
+// series returns one flow of trade between reporter and partner, year by
+// year with the annual total after its months.
+func series(provider string, planned shape, rng *rand.Rand, reporter, partner string, flow model.Flow) []model.Observation {
+	// Bases run log-uniformly from 1 million to 10 billion USD a month.
+	base := math.Pow(10, 6+4*rng.Float64())
+	growth := 0.002 + 0.008*rng.NormFloat64()
+	indicator := "XPRT-TRD-VL"
+	if flow == model.FlowImport {
+		indicator = "MPRT-TRD-VL"
+	}
+	observation := func(periodType model.PeriodType, period string, value float64) model.Observation {
+		estimated := rng.IntN(100) == 0
+		note := ""
+		if estimated {
+			note = model.QualityProviderEstimate
+		}
+		return model.Observation{
+			Provider: provider, ReporterISO3: reporter, PartnerISO3: partner, Flow: flow,
+			PeriodType: periodType, Period: period, ValueUSD: math.Round(value),
+			Estimated: estimated, QualityNote: note, DatasetID: "synthetic", Indicator: indicator,
+		}
+	}
+
+	observations := make([]model.Observation, 0, planned.years*13)
+	month := 0
+	for year := planned.firstYear; year < planned.firstYear+planned.years; year++ {
+		annual := 0.0
+		for m := 1; m <= 12; m++ {
+			value := math.Round(base * math.Pow(1+growth, float64(month)) * (0.9 + 0.2*rng.Float64()))
+			month++
+			annual += value
+			observations = append(observations, observation(model.PeriodMonth, fmt.Sprintf("%04d-%02d", year, m), value))
+		}
+		observations = append(observations, observation(model.PeriodYear, fmt.Sprintf("%04d", year), annual))
+	}
+	return observations
+}
+
+// Fill stores the reporters and observations of cfg in st, in batches so
+// memory stays flat however many observations there are, and returns how
+// many observations it stored.
+func Fill(ctx context.Context, st store.Store, cfg Config) (int, error) {
+	reporters, err := Reporters(cfg)
+	if err != nil {
+		return 0, err
+	}
+	if err := st.UpsertReporters(ctx, reporters); err != nil {
+		return 0, err
+	}
+	stored := 0
+	batch := make([]model.Observation, 0, fillBatchSize)
+	flush := func() error {
+		if err := st.UpsertObservations(ctx, batch); err != nil {
+			return err
+		}
+		stored += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	err = Each(cfg, func(observation model.Observation) error {
+		batch = append(batch, observation)
+		if len(batch) == fillBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil && len(batch) > 0 {
+		err = flush()
+	}
+	return stored, err
+}
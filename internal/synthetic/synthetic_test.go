@@ -0,0 +1,118 @@
+package synthetic
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"tradegravity/internal/model"
+	"tradegravity/internal/store/sqlite"
+)
+
+func collect(t *testing.T, cfg Config) []model.Observation {
+	t.Helper()
+	var observations []model.Observation
+	if err := Each(cfg, func(observation model.Observation) error {
+		observations = append(observations, observation)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return observations
+}
+
+func TestEachGeneratesValidDeterministicSeries(t *testing.T) {
+	cfg := Config{Observations: 5000, Years: 2, Seed: 7}
+	observations := collect(t, cfg)
+	if len(observations) != 5000 {
+		t.Fatalf("generated %d observations, want 5000", len(observations))
+	}
+	again := collect(t, cfg)
+	other := collect(t, Config{Observations: 5000, Years: 2, Seed: 8})
+	if again[4999] != observations[4999] || other[0].ValueUSD == observations[0].ValueUSD {
+		t.Fatal("want the same data for a seed and different data for another")
+	}
+
+	partners := map[string]int{}
+	months := map[string]float64{}
+	for _, observation := range observations {
+		if err := observation.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		partners[observation.PartnerISO3]++
+		key := fmt.Sprintf("%s/%s/%s/%s", observation.ReporterISO3, observation.PartnerISO3, observation.Flow, observation.Period[:4])
+		if observation.PeriodType == model.PeriodMonth {
+			months[key] += observation.ValueUSD
+		} else if observation.ValueUSD != months[key] {
+			t.Fatalf("%s annual %v, months sum to %v", key, observation.ValueUSD, months[key])
+		}
+	}
+	// 5000 observations of 52 per pair and year cover 33 reporters, all
+	// with USA and CHN and most with WLD.
+	reporters, err := Reporters(cfg)
+	if err != nil || len(reporters) != 33 || partners["USA"] != 33*52 || partners["CHN"] != 33*52 || partners["WLD"] < 30*52 || len(partners) != 3 {
+		t.Fatalf("reporters %d (%v) partners %v", len(reporters), err, partners)
+	}
+}
+
+func TestEachGrowsHistoryOnceEveryPairIsCovered(t *testing.T) {
+	observations := collect(t, Config{Observations: 300_000})
+	first, last := "9999", "0000"
+	for _, observation := range observations {
+		if observation.PeriodType == model.PeriodYear {
+			first, last = min(first, observation.Period), max(last, observation.Period)
+		}
+	}
+	// 249 reporters with 3 partners, less USA and CHN trading with
+	// themselves, need 16 years for 300,000 observations.
+	if len(observations) != 300_000 || first != "2009" || last != "2024" {
+		t.Fatalf("%d observations from %s to %s", len(observations), first, last)
+	}
+	matrix := collect(t, Config{Observations: 1000, Partners: 5, Years: 1})
+	if got := matrix[len(matrix)-1].PartnerISO3; got != "AFG" {
+		t.Fatalf("last partner = %s, want the second matrix partner", got)
+	}
+}
+
+func TestEachRejectsDatasetsItCannotShape(t *testing.T) {
+	for _, cfg := range []Config{
+		{Observations: -1},
+		{Observations: 1 << 30, Years: 1},
+		{Observations: 10, Partners: 1000},
+		{Observations: 10_000_000, LastYear: 100},
+	} {
+		if err := Each(cfg, func(model.Observation) error { return nil }); err == nil {
+			t.Fatalf("%+v: want an error", cfg)
+		}
+	}
+}
+
+func TestFillStoresEveryObservation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synthetic.db")
+	st, err := sqlite.New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+	cfg := Config{Observations: 12345, Years: 3}
+	stored, err := Fill(ctx, st, cfg)
+	if err != nil || stored != 12345 {
+		t.Fatalf("Fill() = %d, %v", stored, err)
+	}
+	reporters, err := st.ListReporters(ctx, true)
+	if want, _ := Reporters(cfg); err != nil || len(reporters) != len(want) {
+		t.Fatalf("reporters = %d, want %d, %v", len(reporters), len(want), err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM trade_observations`).Scan(&count); err != nil || count != 12345 {
+		t.Fatalf("stored rows = %d, %v", count, err)
+	}
+}